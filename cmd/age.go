@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/fatih/color"
+)
+
+// ageTag is the age bucket a branch's tip commit falls in
+type ageTag struct {
+	Name  string
+	Index int // Position of the bucket, youngest first
+}
+
+// branchAgeTags returns the age bucket of every branch, keyed by reference.
+// Branches whose commit date is unknown are missing; failing to read the
+// dates only loses the tags.
+func branchAgeTags(g *git.Git, now time.Time) map[string]ageTag {
+	details, err := g.BranchDetails()
+	if err != nil {
+		log.Debug("Failed to read branch dates: %v", err)
+		return nil
+	}
+
+	c := cfg
+	if c == nil {
+		c = config.DefaultConfig()
+	}
+	tags := make(map[string]ageTag, len(details))
+	for ref, d := range details {
+		name, index := c.AgeBucket(now.Sub(d.CommitDate))
+		tags[ref] = ageTag{Name: name, Index: index}
+	}
+	return tags
+}
+
+// formatAge returns the " [<1w]" style age suffix of a branch label
+func formatAge(b git.GitBranch, ages map[string]ageTag) string {
+	tag, ok := ages[b.Reference]
+	if !ok {
+		return ""
+	}
+	return color.MagentaString(" [" + tag.Name + "]")
+}
+
+// sortByAge groups choices by the age bucket of their branch, oldest first,
+// keeping the order within each bucket. Branches of unknown age come last.
+func sortByAge(choices []string, branchMap map[string]git.GitBranch, ages map[string]ageTag) {
+	index := func(label string) int {
+		tag, ok := ages[branchMap[label].Reference]
+		if !ok {
+			return -1
+		}
+		return tag.Index
+	}
+	sort.SliceStable(choices, func(i, j int) bool {
+		return index(choices[i]) > index(choices[j])
+	})
+}
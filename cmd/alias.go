@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/spf13/pflag"
+)
+
+// reservedCommands are added by cobra when executing, so they aren't among
+// rootCmd's commands beforehand
+var reservedCommands = []string{"help", "completion", "__complete", "__completeNoDesc"}
+
+// expandAlias replaces the first command-line argument naming a configured
+// alias with its expansion; the arguments after it are kept, so "nuke
+// --dry-run" runs "prune --force --dry-run" for nuke = "prune --force".
+// Built-in commands can't be overridden, and expansions aren't expanded
+// again. It reports whether args changed.
+func expandAlias(c *config.Config, args []string) ([]string, bool) {
+	if c == nil || len(c.Aliases) == 0 {
+		return args, false
+	}
+
+	i := commandIndex(rootCmd.PersistentFlags(), args)
+	if i < 0 || isCommand(args[i]) {
+		return args, false
+	}
+	expansion, ok := c.Alias(args[i])
+	if !ok {
+		return args, false
+	}
+
+	expanded := slices.Clone(args[:i])
+	expanded = append(expanded, expansion...)
+	return append(expanded, args[i+1:]...), true
+}
+
+// commandIndex returns the position of the command name in args, skipping
+// the flags of flags before it and their values, or -1 without one. Grouped
+// short flags, like "-qC dir" or "-Cdir", are read as pflag reads them: a
+// shorthand taking a value ends the group, with the rest of it or the next
+// argument as the value.
+func commandIndex(flags *pflag.FlagSet, args []string) int {
+	takesValue := func(f *pflag.Flag) bool {
+		return f != nil && f.NoOptDefVal == ""
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return -1
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return i
+		case strings.Contains(arg, "="):
+			continue
+		}
+
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			if takesValue(flags.Lookup(name)) {
+				i++
+			}
+			continue
+		}
+		shorthands := arg[1:]
+		for j := range shorthands {
+			if !takesValue(flags.ShorthandLookup(shorthands[j : j+1])) {
+				continue
+			}
+			if j == len(shorthands)-1 {
+				i++
+			}
+			break
+		}
+	}
+	return -1
+}
+
+// isCommand reports whether name is a built-in command or one of its
+// aliases
+func isCommand(name string) bool {
+	if slices.Contains(reservedCommands, name) {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAlias(t *testing.T) {
+	c := &config.Config{Aliases: map[string]string{
+		"nuke": "prune --force",
+		"list": "prune --dry-run", // Shadows a built-in, so never used
+	}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		changed bool
+	}{
+		{name: "alias", args: []string{"nuke"}, want: []string{"prune", "--force"}, changed: true},
+		{name: "bool flag", args: []string{"--debug", "nuke"}, want: []string{"--debug", "prune", "--force"}, changed: true},
+		{name: "value flag", args: []string{"--config", "x", "nuke"}, want: []string{"--config", "x", "prune", "--force"}, changed: true},
+		{name: "value flag with =", args: []string{"--config=x", "nuke"}, want: []string{"--config=x", "prune", "--force"}, changed: true},
+		{name: "short value flag", args: []string{"-C", "nuke", "nuke"}, want: []string{"-C", "nuke", "prune", "--force"}, changed: true},
+		{name: "short value flag attached", args: []string{"-Cdir", "nuke"}, want: []string{"-Cdir", "prune", "--force"}, changed: true},
+		{name: "trailing args kept", args: []string{"nuke", "--dry-run", "nuke"}, want: []string{"prune", "--force", "--dry-run", "nuke"}, changed: true},
+		{name: "after --", args: []string{"--", "nuke"}, want: []string{"--", "nuke"}},
+		{name: "built-in not shadowed", args: []string{"list"}, want: []string{"list"}},
+		{name: "not an alias", args: []string{"unknown"}, want: []string{"unknown"}},
+		{name: "no command", args: []string{"--debug"}, want: []string{"--debug"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := expandAlias(c, tt.args)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.changed, changed)
+		})
+	}
+
+	got, changed := expandAlias(nil, []string{"nuke"})
+	assert.Equal(t, []string{"nuke"}, got)
+	assert.False(t, changed)
+}
+
+func TestCommandIndex(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.BoolP("debug", "d", false, "")
+	flags.BoolP("verbose", "v", false, "")
+	flags.StringP("config", "c", "", "")
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"nuke"}, 0},
+		{[]string{"--debug", "nuke"}, 1},
+		{[]string{"--config", "x", "nuke"}, 2},
+		{[]string{"--config=x", "nuke"}, 1},
+		{[]string{"-d", "nuke"}, 1},
+		{[]string{"-dv", "nuke"}, 1},
+		{[]string{"-dc", "x", "nuke"}, 2},
+		{[]string{"-dcx", "nuke"}, 1},
+		{[]string{"-cdv", "nuke"}, 1}, // "dv" is the value of -c
+		{[]string{"-", "nuke"}, 0},
+		{[]string{"--unknown", "nuke"}, 1},
+		{[]string{"--", "nuke"}, -1},
+		{[]string{"--config"}, -1},
+		{nil, -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, commandIndex(flags, tt.args), tt.args)
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeOlderThan int
+	purgeDryRun    bool
+)
+
+// PurgeOptions controls the behavior of PurgeArchived
+type PurgeOptions struct {
+	OlderThan time.Duration // Purge archives archived longer ago than this
+	DryRun    bool          // Report what would be purged without deleting
+}
+
+func init() {
+	archiveCmd := newArchiveCmd()
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func newArchiveCmd() *cobra.Command {
+	archiveCmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Manage soft-deleted remote branches",
+		Long: `Manage remote branches that were soft-deleted with 'delete --soft'.
+Soft-deleted branches live under refs/heads/` + git.ArchivePrefix + ` on the remote.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List archived remote branches",
+		RunE:  runArchiveList,
+	}
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete old archived branches",
+		Long: `Permanently delete archived remote branches that were archived longer ago
+than the retention period. The archive time is when this clone archived the
+branch or first fetched the archive; archives made elsewhere and not
+fetched yet are kept, so fetch first to include them.`,
+		Example: `  git-branch-delete archive purge --older-than 30
+  git-branch-delete archive purge --older-than 90 --dry-run`,
+		RunE: runArchivePurge,
+	}
+	purgeCmd.Flags().IntVar(&purgeOlderThan, "older-than", 30, "Retention period in days")
+	purgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "Show what would be purged without deleting")
+
+	archiveCmd.AddCommand(listCmd, purgeCmd)
+	return archiveCmd
+}
+
+func runArchiveList(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	archived, err := g.ListArchivedBranches()
+	if err != nil {
+		return err
+	}
+
+	return newPresenter(os.Stdout).archived(&ArchiveResult{Branches: archived})
+}
+
+func runArchivePurge(cmd *cobra.Command, args []string) error {
+	if purgeOlderThan < 0 {
+		return fmt.Errorf("--older-than must not be negative")
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	dryRun := safeDryRun(g, purgeDryRun)
+	if !dryRun {
+		ui.ShowBanner(cfg.Confirmation)
+	}
+
+	res, err := PurgeArchived(g, PurgeOptions{
+		OlderThan: time.Duration(purgeOlderThan) * 24 * time.Hour,
+		DryRun:    dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	newPresenter(os.Stdout).purge(res)
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to purge %d branch(es)", len(res.Failed))
+	}
+	return nil
+}
+
+// PurgeArchived permanently deletes archived branches past the retention period
+func PurgeArchived(g *git.Git, opts PurgeOptions) (*PurgeResult, error) {
+	archived, err := g.ListArchivedBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	res := &PurgeResult{DryRun: opts.DryRun}
+	for _, b := range archived {
+		entry := BranchResult{Name: git.ArchivePrefix + b.Name, Commit: b.CommitHash, Remote: true}
+
+		if b.ArchivedAt.IsZero() {
+			log.Debug("Keeping %s: archive time unknown", entry.Name)
+			res.Kept = append(res.Kept, entry)
+			continue
+		}
+		if b.ArchivedAt.After(cutoff) {
+			res.Kept = append(res.Kept, entry)
+			continue
+		}
+
+		if !opts.DryRun {
+			if err := g.DeleteArchivedBranch(b.Name); err != nil {
+				entry.setError(err)
+				res.Failed = append(res.Failed, entry)
+				continue
+			}
+		}
+		res.Purged = append(res.Purged, entry)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeArchived(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/old")
+	require.NoError(t, g.ArchiveRemoteBranch("feature/old"))
+
+	// The commit is old, but the archive isn't
+	res, err := PurgeArchived(g, PurgeOptions{OlderThan: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Empty(t, res.Purged)
+	assert.Equal(t, []string{"archived/feature/old"}, resultNames(res.Kept))
+
+	res, err = PurgeArchived(g, PurgeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archived/feature/old"}, resultNames(res.Purged))
+	assert.Equal(t, []string{"main"}, r.RemoteBranches())
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// AuditEntry records a branch deletion in the repository's audit log
+type AuditEntry struct {
+	Time    time.Time  `json:"time"`
+	Branch  string     `json:"branch"`
+	Remote  bool       `json:"remote"`
+	Commit  string     `json:"commit,omitempty"`
+	Created *time.Time `json:"created,omitempty"` // From the reflog, which is deleted with the branch
+	Reason  string     `json:"reason,omitempty"`  // Why the branch could be deleted
+
+	// Recovery is the command restoring a branch deleted with force
+	Recovery string `json:"recovery,omitempty"`
+}
+
+// auditLogPath returns the location of the audit log for the repository
+func auditLogPath(g *git.Git) (string, error) {
+	dir, err := g.GitPath(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// branchCreations returns the creation times of branches from their
+// reflogs, to be passed to recordDeletions after they are deleted
+func branchCreations(g *git.Git) map[string]time.Time {
+	created, err := g.BranchCreations()
+	if err != nil {
+		log.Debug("Failed to read branch creation times: %v", err)
+	}
+	return created
+}
+
+// journalRun groups the deletions of this run in the journal, so undo
+// --last takes back all of them even when they are recorded one at a time
+var journalRun = journal.NewRun()
+
+// recordDeletions appends the deleted branches of a run to the audit log
+// and to the journal undo reads. created holds the creation times read
+// before the branches were deleted.
+func recordDeletions(g *git.Git, deleted []BranchResult, created map[string]time.Time) {
+	recordRunDeletions(g, journalRun, deleted, created)
+}
+
+// recordRunDeletions is recordDeletions for one of the runs of a long
+// running command, such as a request to serve
+func recordRunDeletions(g *git.Git, run string, deleted []BranchResult, created map[string]time.Time) {
+	if len(deleted) == 0 {
+		return
+	}
+	if err := appendAuditLog(g, deleted, created); err != nil {
+		log.Warn("Failed to update audit log: %v", err)
+	}
+	entries := make([]journal.Entry, len(deleted))
+	for i, b := range deleted {
+		entries[i] = journal.Entry{Branch: b.Name, Remote: b.Remote, Commit: b.Commit}
+	}
+	if err := journal.Append(g, run, entries); err != nil {
+		log.Warn("Failed to update deletion journal: %v", err)
+	}
+}
+
+// appendAuditLog writes one JSON line per deleted branch to the audit log
+func appendAuditLog(g *git.Git, deleted []BranchResult, created map[string]time.Time) error {
+	path, err := auditLogPath(g)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	enc := json.NewEncoder(f)
+	for _, b := range deleted {
+		entry := AuditEntry{Time: now, Branch: b.Name, Remote: b.Remote, Commit: b.Commit, Reason: b.DeleteReason, Recovery: b.RecoveryHint}
+		if t, ok := created[branchRef(git.GitBranch{Name: b.Name, IsRemote: b.Remote})]; ok {
+			entry.Created = &t
+		}
+		if err := enc.Encode(entry); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadAuditLog reads the audit log, returning nil if there is none.
+// Unreadable lines are skipped.
+func loadAuditLog(g *git.Git) ([]AuditEntry, error) {
+	path, err := auditLogPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Debug("Skipping unreadable audit log line: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/secrets"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// knownProviders are the hosts auth status always reports on
+var knownProviders = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+var authHost string
+
+func init() {
+	rootCmd.AddCommand(newAuthCmd())
+}
+
+func newAuthCmd() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage hosting provider API tokens",
+		Long: `Manage the API tokens used to talk to hosting providers such as GitHub.
+Tokens are stored in the OS keychain (macOS Keychain, the Secret Service via
+secret-tool on Linux, or Windows Credential Manager), never in the config
+file. The host defaults to the one origin is served from.`,
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Store an API token in the OS keychain",
+		Long: `Store an API token for a host in the OS keychain, replacing any previous
+one. The token is read from stdin when it is piped, or prompted for without
+echoing it.`,
+		Example: `  git-branch-delete auth login
+  echo "$GITHUB_TOKEN" | git-branch-delete auth login --host github.com`,
+		RunE: runAuthLogin,
+	}
+
+	logoutCmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove an API token from the OS keychain",
+		RunE:  runAuthLogout,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show which hosts have a stored API token",
+		RunE:  runAuthStatus,
+	}
+
+	authCmd.PersistentFlags().StringVar(&authHost, "host", "", "Provider host, e.g. github.com (default: origin's host)")
+	authCmd.AddCommand(loginCmd, logoutCmd, statusCmd)
+	return authCmd
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	host, err := resolveAuthHost()
+	if err != nil {
+		return err
+	}
+
+	token, err := readToken(host)
+	if err != nil {
+		return err
+	}
+	if err := secrets.Set(host, token); err != nil {
+		return fmt.Errorf("failed to store token for %s: %w", host, err)
+	}
+	log.Info("Stored token for %s in the OS keychain", host)
+	return nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	host, err := resolveAuthHost()
+	if err != nil {
+		return err
+	}
+
+	err = secrets.Delete(host)
+	if errors.Is(err, secrets.ErrNotFound) {
+		log.Info("No token stored for %s", host)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove token for %s: %w", host, err)
+	}
+	log.Info("Removed token for %s", host)
+	return nil
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	hosts := knownProviders
+	if authHost != "" {
+		hosts = []string{authHost}
+	} else if host, err := resolveAuthHost(); err == nil && !slices.Contains(hosts, host) {
+		hosts = append([]string{host}, hosts...)
+	}
+
+	for _, host := range hosts {
+		token, err := secrets.Get(host)
+		switch {
+		case errors.Is(err, secrets.ErrNotFound):
+			log.Info("%s: not logged in", host)
+		case err != nil:
+			return fmt.Errorf("failed to read token for %s: %w", host, err)
+		default:
+			log.Info("%s: logged in (token %s)", host, secrets.Mask(token))
+		}
+	}
+	return nil
+}
+
+// resolveAuthHost returns --host, or the host origin is served from
+func resolveAuthHost() (string, error) {
+	if authHost != "" {
+		return authHost, nil
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return "", fmt.Errorf("--host is required outside a repository: %w", err)
+	}
+	host, err := g.RemoteHost()
+	if err != nil {
+		return "", fmt.Errorf("--host is required: %w", err)
+	}
+	return host, nil
+}
+
+// readToken reads a token from piped stdin, or prompts for it without echo
+func readToken(host string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Token for %s: ", host)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
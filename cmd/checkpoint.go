@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/progress"
+)
+
+const (
+	// checkpointThreshold is the batch size from which deletions are
+	// checkpointed, so a killed run can resume
+	checkpointThreshold = 500
+	// checkpointChunk is how many branches are deleted between checkpoints
+	checkpointChunk = 100
+)
+
+// deleteCheckpoint records the progress of a large deletion run
+type deleteCheckpoint struct {
+	Branches  []string       `json:"branches"` // Every branch the run deletes
+	Force     bool           `json:"force"`
+	Remote    bool           `json:"remote"`
+	All       bool           `json:"all"`
+	Soft      bool           `json:"soft"`
+	Done      int            `json:"done"` // Branches processed so far
+	Deleted   []BranchResult `json:"deleted"`
+	Failed    []BranchResult `json:"failed"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+
+	// Tips holds the commit each ref the run deletes pointed at when it
+	// started, by ref, or "" for refs that didn't exist. A resumed run
+	// leaves branches alone that moved or were recreated since.
+	Tips map[string]string `json:"tips,omitempty"`
+}
+
+// matches reports whether the checkpoint belongs to a run of opts
+func (c *deleteCheckpoint) matches(opts DeleteOptions) bool {
+	return c.Force == opts.Force && c.Remote == opts.Remote && c.All == opts.All &&
+		c.Soft == opts.Soft && slices.Equal(c.Branches, opts.Branches)
+}
+
+// deleteCheckpointed deletes the branches of opts in chunks, saving the
+// progress after each one. A run of the same deletion after an interrupted
+// one resumes after the last chunk saved instead of starting over.
+func deleteCheckpointed(g *git.Git, opts DeleteOptions, rep *progress.Reporter[BranchResult]) (*DeleteResult, error) {
+	cp, err := loadCheckpoint(g)
+	if err != nil {
+		log.Debug("Discarding unreadable checkpoint: %v", err)
+	}
+	resuming := cp != nil && cp.matches(opts)
+	if resuming {
+		log.Info("Resuming deletion: %d of %d branches already processed", cp.Done, len(opts.Branches))
+		reportResults(rep, cp.Deleted...)
+		reportResults(rep, cp.Failed...)
+	} else {
+		cp = &deleteCheckpoint{
+			Branches: opts.Branches,
+			Force:    opts.Force,
+			Remote:   opts.Remote,
+			All:      opts.All,
+			Soft:     opts.Soft,
+			Tips:     branchTips(g, opts),
+		}
+	}
+
+	interrupted := resuming
+	for cp.Done < len(cp.Branches) {
+		end := min(cp.Done+checkpointChunk, len(cp.Branches))
+		chunk := opts
+		chunk.Branches = cp.Branches[cp.Done:end]
+
+		if resuming {
+			chunk.Branches = nil
+			for _, name := range cp.Branches[cp.Done:end] {
+				// The interrupted chunk may have been partly deleted already
+				if interrupted && deletedBefore(g, name, opts) {
+					gone := BranchResult{Name: name, Remote: opts.Remote, Reason: "deleted before the interruption"}
+					cp.Deleted = append(cp.Deleted, gone)
+					reportResults(rep, gone)
+					continue
+				}
+				if err := cp.moved(g, name, opts); err != nil {
+					moved := newBranchResult(git.GitBranch{Name: name, IsRemote: opts.Remote}, err)
+					cp.Failed = append(cp.Failed, moved)
+					reportResults(rep, moved)
+					continue
+				}
+				chunk.Branches = append(chunk.Branches, name)
+			}
+			interrupted = false
+		}
+
+		res, err := deleteBatch(g, chunk, rep)
+		if err != nil {
+			return nil, err
+		}
+		cp.Deleted = append(cp.Deleted, res.Deleted...)
+		cp.Failed = append(cp.Failed, res.Failed...)
+		cp.Done = end
+
+		if err := saveCheckpoint(g, cp); err != nil {
+			log.Warn("Failed to save deletion checkpoint: %v", err)
+		}
+		log.Info("Processed %d of %d branches", cp.Done, len(cp.Branches))
+	}
+
+	if err := saveCheckpoint(g, nil); err != nil {
+		log.Warn("Failed to remove deletion checkpoint: %v", err)
+	}
+	return &DeleteResult{Deleted: cp.Deleted, Failed: cp.Failed}, nil
+}
+
+// deletedBefore reports whether every ref opts deletes for a branch is gone
+func deletedBefore(g *git.Git, name string, opts DeleteOptions) bool {
+	for _, b := range checkpointRefs(name, opts) {
+		if branchCommit(g, b) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkpointRefs returns the refs opts deletes for a branch
+func checkpointRefs(name string, opts DeleteOptions) []git.GitBranch {
+	local := git.GitBranch{Name: name}
+	remote := git.GitBranch{Name: name, IsRemote: true}
+	switch {
+	case opts.Remote:
+		return []git.GitBranch{remote}
+	case opts.All:
+		return []git.GitBranch{local, remote}
+	default:
+		return []git.GitBranch{local}
+	}
+}
+
+// branchTips returns the commit of each ref opts deletes, by ref
+func branchTips(g *git.Git, opts DeleteOptions) map[string]string {
+	tips := make(map[string]string)
+	for _, name := range opts.Branches {
+		for _, b := range checkpointRefs(name, opts) {
+			tips[branchRef(b)] = branchCommit(g, b)
+		}
+	}
+	return tips
+}
+
+// moved returns an error when a ref opts deletes for a branch points at
+// another commit than when the checkpointed run started. Refs deleted in
+// the meantime haven't moved; deleting them fails on its own. Checkpoints
+// written before tips were recorded can't tell.
+func (c *deleteCheckpoint) moved(g *git.Git, name string, opts DeleteOptions) error {
+	if c.Tips == nil {
+		return nil
+	}
+	for _, b := range checkpointRefs(name, opts) {
+		ref := branchRef(b)
+		tip, found := c.Tips[ref]
+		current := branchCommit(g, b)
+		if !found || current == "" || current == tip {
+			continue
+		}
+		if tip == "" {
+			return fmt.Errorf("%s was created since the interrupted run; not deleting it", ref)
+		}
+		return fmt.Errorf("%s moved since the interrupted run; not deleting it", ref)
+	}
+	return nil
+}
+
+// checkpointPath returns the location of the deletion checkpoint
+func checkpointPath(g *git.Git) (string, error) {
+	dir, err := g.GitPath(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "checkpoint.json"), nil
+}
+
+// loadCheckpoint reads the deletion checkpoint, returning nil if there is none
+func loadCheckpoint(g *git.Git) (*deleteCheckpoint, error) {
+	path, err := checkpointPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp deleteCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// saveCheckpoint writes the deletion checkpoint, removing the file when cp
+// is nil. The file is replaced atomically so a kill mid-write can't leave a
+// truncated checkpoint behind.
+func saveCheckpoint(g *git.Git, cp *deleteCheckpoint) error {
+	path, err := checkpointPath(g)
+	if err != nil {
+		return err
+	}
+
+	if cp == nil {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove checkpoint: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	cp.UpdatedAt = time.Now()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteCheckpointedResume(t *testing.T) {
+	r, g := newTestRepo(t)
+	names := []string{"feature/a", "feature/b", "feature/c", "feature/d"}
+	for _, name := range names {
+		r.Local(name)
+	}
+	opts := DeleteOptions{Branches: names, Force: true}
+
+	// The run was killed after deleting feature/a
+	require.NoError(t, saveCheckpoint(g, &deleteCheckpoint{
+		Branches: names,
+		Force:    true,
+		Tips:     branchTips(g, opts),
+	}))
+	r.Git("branch", "-D", "feature/a")
+
+	// Meanwhile feature/b got a new commit and feature/c was recreated
+	r.Git("checkout", "--quiet", "feature/b")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "More work")
+	r.Git("checkout", "--quiet", "main")
+	r.Git("branch", "-D", "feature/c")
+	r.Git("branch", "feature/c", "main")
+
+	res, err := deleteCheckpointed(g, opts, progress.Start[BranchResult](nil, len(names)))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"feature/a", "feature/d"}, resultNames(res.Deleted))
+	assert.ElementsMatch(t, []string{"feature/b", "feature/c"}, resultNames(res.Failed))
+	for _, f := range res.Failed {
+		assert.Contains(t, f.Error, "moved since the interrupted run")
+	}
+	assert.True(t, r.HasBranch("feature/b"))
+	assert.True(t, r.HasBranch("feature/c"))
+	assert.False(t, r.HasBranch("feature/d"))
+
+	// The finished run removes its checkpoint
+	cp, err := loadCheckpoint(g)
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestDeleteCheckpointedResumeWithoutTips(t *testing.T) {
+	r, g := newTestRepo(t)
+	names := []string{"feature/a", "feature/b"}
+	for _, name := range names {
+		r.Local(name)
+	}
+	opts := DeleteOptions{Branches: names, Force: true}
+
+	// Checkpoints from before tips were recorded resume as they did
+	require.NoError(t, saveCheckpoint(g, &deleteCheckpoint{Branches: names, Force: true}))
+	r.Git("checkout", "--quiet", "feature/b")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "More work")
+	r.Git("checkout", "--quiet", "main")
+
+	res, err := deleteCheckpointed(g, opts, progress.Start[BranchResult](nil, len(names)))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, names, resultNames(res.Deleted))
+	assert.Empty(t, res.Failed)
+}
+
+func TestCheckpointMoved(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/x")
+	opts := DeleteOptions{Branches: []string{"feature/x", "feature/new"}, All: true}
+	cp := &deleteCheckpoint{Tips: branchTips(g, opts)}
+
+	assert.NoError(t, cp.moved(g, "feature/x", opts))
+	assert.NoError(t, cp.moved(g, "feature/new", opts))
+
+	// A teammate pushed to the remote branch and this clone fetched it
+	r.Git("--git-dir", r.Origin, "update-ref", "refs/heads/feature/x", "main")
+	r.Git("fetch", "--quiet", "origin")
+	assert.ErrorContains(t, cp.moved(g, "feature/x", opts), "refs/remotes/origin/feature/x moved")
+
+	r.Local("feature/new")
+	assert.ErrorContains(t, cp.moved(g, "feature/new", opts), "was created")
+
+	// Deleted refs haven't moved
+	r.Git("branch", "-D", "feature/new")
+	assert.NoError(t, cp.moved(g, "feature/new", opts))
+}
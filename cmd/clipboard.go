@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// copySummaryFlag is shared by the commands that delete branches
+var copySummaryFlag bool
+
+// addCopySummaryFlag adds --copy-summary to a deleting command
+func addCopySummaryFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&copySummaryFlag, "copy-summary", false, "Copy a plain-text summary of deleted branches to the clipboard")
+}
+
+// deletionSummary renders deleted branches as plain text, one "name sha"
+// line per branch
+func deletionSummary(deleted []BranchResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deleted %d branch(es):\n", len(deleted))
+	for _, r := range deleted {
+		name := r.Name
+		if r.Remote {
+			name = "origin/" + name
+		}
+		fmt.Fprintf(&b, "%s %s\n", name, r.Commit)
+	}
+	return b.String()
+}
+
+// copySummary places the summary of deleted branches on the clipboard when
+// --copy-summary is set. A missing clipboard is not an error.
+func copySummary(deleted []BranchResult) {
+	if !copySummaryFlag || len(deleted) == 0 {
+		return
+	}
+
+	err := utils.CopyToClipboard(deletionSummary(deleted))
+	switch {
+	case errors.Is(err, utils.ErrNoClipboard):
+		log.Info("No clipboard available, summary not copied")
+	case err != nil:
+		log.Warn("Failed to copy summary: %v", err)
+	default:
+		log.Info("Copied summary of %d deleted branch(es) to the clipboard", len(deleted))
+	}
+}
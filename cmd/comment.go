@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/github"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/secrets"
+)
+
+// pruneCommentMarker identifies the comment prune updates on later runs
+const pruneCommentMarker = "<!-- git-branch-delete:prune -->"
+
+// commentFromEvent is the --comment-pr value that takes the pull request
+// from the GitHub Actions event
+const commentFromEvent = "event"
+
+// commentTimeout bounds posting a comment
+const commentTimeout = time.Minute
+
+// commentPrune posts or updates a comment summarizing res on the pull
+// request or issue target names, or the one of the triggering event
+func commentPrune(g *git.Git, target string, res *PruneResult) error {
+	env, err := github.ActionsEnvFromOS(os.Getenv)
+	if err != nil {
+		return err
+	}
+	if env.Repository == "" {
+		return fmt.Errorf("--comment-pr needs GITHUB_REPOSITORY; run it in GitHub Actions")
+	}
+
+	number, err := commentNumber(target)
+	if err != nil {
+		return err
+	}
+	if number == 0 {
+		number = env.Number
+	}
+	if number == 0 {
+		return fmt.Errorf("--comment-pr: the triggering event has no pull request or issue; pass its number")
+	}
+
+	token := env.Token
+	if token == "" {
+		if host, err := g.RemoteHost(); err == nil {
+			token, _ = secrets.Get(host)
+		}
+	}
+	if token == "" {
+		return fmt.Errorf("--comment-pr needs a token in GITHUB_TOKEN or GH_TOKEN")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commentTimeout)
+	defer cancel()
+
+	comment, err := github.NewClient(env.APIURL, token).UpsertComment(ctx, env.Repository, number, pruneCommentMarker, pruneMarkdown(res))
+	if err != nil {
+		return fmt.Errorf("failed to comment on #%d: %w", number, err)
+	}
+	log.Info("Posted prune summary to %s", comment.HTMLURL)
+	return nil
+}
+
+// commentNumber parses a --comment-pr value; 0 means the event's number
+func commentNumber(target string) (int, error) {
+	if target == commentFromEvent {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(target)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid --comment-pr %q: expected a pull request number", target)
+	}
+	return number, nil
+}
+
+// pruneMarkdown renders res as a Markdown comment
+func pruneMarkdown(res *PruneResult) string {
+	var b strings.Builder
+	b.WriteString(pruneCommentMarker + "\n")
+	b.WriteString("### Stale branches\n\n")
+
+	if len(res.Candidates)+len(res.Skipped) == 0 {
+		b.WriteString("No stale branches found.\n")
+		return b.String()
+	}
+
+	if res.DryRun {
+		fmt.Fprintf(&b, "**%d** branch(es) would be pruned.\n", len(res.Candidates))
+		markdownBranches(&b, "Would delete", res.Candidates)
+	} else {
+		fmt.Fprintf(&b, "**%d** deleted, **%d** skipped, **%d** failed.\n",
+			len(res.Deleted), len(res.Skipped), len(res.Failed))
+		markdownBranches(&b, "Deleted", res.Deleted)
+		markdownBranches(&b, "Failed", res.Failed)
+	}
+	markdownBranches(&b, "Skipped", res.Skipped)
+
+	fmt.Fprintf(&b, "\n<sub>Updated %s by git-branch-delete prune</sub>\n", res.Time.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// markdownBranches writes a table of branches under a heading, skipping
+// empty lists
+func markdownBranches(b *strings.Builder, heading string, branches []BranchResult) {
+	if len(branches) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n#### %s\n\n| Branch | Commit | Note |\n| --- | --- | --- |\n", heading)
+	for _, br := range branches {
+		name := br.Name
+		if br.Remote {
+			name = "origin/" + name
+		}
+		note := br.Reason
+		if note == "" {
+			note = br.DeleteReason
+		}
+		if br.Error != "" {
+			note = br.Error
+		}
+		cell := "`" + name + "`"
+		if br.URL != "" {
+			cell = "[" + cell + "](" + br.URL + ")"
+		}
+		fmt.Fprintf(b, "| %s | `%s` | %s |\n", cell, br.Commit, markdownCell(note))
+	}
+}
+
+// markdownCell keeps text on one table row
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareAgainst string
+	compareWeight  bool
+)
+
+// CompareOptions controls the behavior of Compare
+type CompareOptions struct {
+	// Branches are the local branches to compare; all but the base when empty
+	Branches []string
+	// Against is the base branch, resolved like a merge target; the default
+	// branch when empty
+	Against string
+	// Weight computes the history unique to each branch
+	Weight bool
+}
+
+func init() {
+	compareCmd := newCompareCmd()
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringVar(&compareAgainst, "against", "", "Branch to compare with, e.g. develop or upstream/main (default: the default branch)")
+	compareCmd.Flags().BoolVarP(&compareWeight, "weight", "w", false, "Show commits and object size unique to each branch")
+}
+
+func newCompareCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare [branches...]",
+		Short: "Compare local branches with a base branch",
+		Long: `Show how many commits each local branch is ahead of and behind a base
+branch, and whether the base has all of its commits, followed by the full
+message of each branch's tip commit.
+
+The base is the default branch unless --against names another one. It is
+resolved like a merged target: a plain name is the local branch or else
+origin's, and a remote-qualified name such as upstream/main is that
+remote's branch. Gitflow users can check feature branches against develop.
+
+With --weight, the commits and objects only each branch holds are counted
+too, i.e. what deleting it and running git gc would reclaim.`,
+		Example: `  git-branch-delete compare
+  git-branch-delete compare --against develop
+  git-branch-delete compare feature/x --against release/2.3
+  git-branch-delete compare --weight`,
+		RunE: runCompare,
+	}
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	gitClient, err := openRepo()
+	if err != nil {
+		log.Error("Failed to initialize git client: %v", err)
+		return err
+	}
+
+	res, err := Compare(gitClient, CompareOptions{Branches: args, Against: compareAgainst, Weight: compareWeight})
+	if err != nil {
+		log.Error("Failed to compare branches: %v", err)
+		return err
+	}
+	return newPresenter(os.Stdout).compare(res)
+}
+
+// Compare counts the commits each local branch is ahead of and behind the
+// base branch
+func Compare(g *git.Git, opts CompareOptions) (*CompareResult, error) {
+	base := opts.Against
+	if base == "" {
+		base = defaultBranchOrConfig(g)
+	}
+	baseRef, err := g.ResolveBase(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base: %w", err)
+	}
+	res := &CompareResult{Base: base, BaseRef: baseRef}
+
+	names := opts.Branches
+	if len(names) == 0 {
+		branches, err := g.ListLocalBranches()
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range branches {
+			if b.Reference != baseRef {
+				names = append(names, b.Name)
+			}
+		}
+	}
+
+	// Every tip's message comes from one listing; missing ones stay empty
+	details, err := g.BranchDetails()
+	if err != nil {
+		log.Debug("Failed to read commit messages: %v", err)
+	}
+
+	for _, name := range names {
+		if err := pkggit.ValidateBranchName(name); err != nil {
+			return nil, err
+		}
+		c, err := g.CompareBranch("refs/heads/"+name, baseRef)
+		if err != nil {
+			return nil, err
+		}
+		d := details["refs/heads/"+name]
+		c.Subject, c.Body = d.Subject, d.Body
+		if opts.Weight {
+			if w, err := g.BranchWeight("refs/heads/"+name, baseRef); err == nil {
+				c.Weight = &w
+			} else {
+				log.Debug("Failed to compute weight of %s: %v", name, err)
+			}
+		}
+		res.Branches = append(res.Branches, c)
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareWeight(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Git("checkout", "--quiet", "-b", "feature/heavy", "main")
+	for i := 0; i < 3; i++ {
+		r.Git("commit", "--quiet", "--allow-empty", "-m", "More work")
+	}
+	r.Git("checkout", "--quiet", "main")
+	r.Local("feature/light")
+
+	res, err := Compare(g, CompareOptions{Against: "main", Weight: true})
+	require.NoError(t, err)
+	require.Len(t, res.Branches, 2)
+	for _, c := range res.Branches {
+		require.NotNil(t, c.Weight, c.Branch)
+		assert.Equal(t, c.Ahead, c.Weight.Commits, c.Branch)
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, newPresenter(&out).compare(res))
+	assert.Contains(t, out.String(), "Weight")
+	assert.Contains(t, out.String(), "3 commits, 3 objects")
+
+	// Weights are only computed when asked for
+	res, err = Compare(g, CompareOptions{Against: "main"})
+	require.NoError(t, err)
+	assert.Nil(t, res.Branches[0].Weight)
+}
+
+func TestCompareInvalidName(t *testing.T) {
+	_, g := newTestRepo(t)
+	for _, name := range []string{"-x", "feature..x", "feature/x.lock", "HEAD"} {
+		_, err := Compare(g, CompareOptions{Branches: []string{name}, Against: "main"})
+		var invalid *pkggit.ErrInvalidBranchName
+		assert.ErrorAs(t, err, &invalid, name)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"runtime"
 
 	"github.com/spf13/cobra"
 )
@@ -11,11 +12,31 @@ func init() {
 	rootCmd.AddCommand(completionCmd)
 }
 
-func newCompletionCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "completion [bash|zsh|fish|powershell]",
-		Short: "Generate completion script",
-		Long: `To load completions:
+// completionHelp returns the instructions for loading completions on goos.
+// Windows gets PowerShell first and Git Bash paths.
+func completionHelp(goos string) string {
+	if goos == "windows" {
+		return `To load completions:
+
+PowerShell:
+  PS> git-branch-delete completion powershell | Out-String | Invoke-Expression
+
+  # To load completions for every new session, run once:
+  PS> New-Item -ItemType Directory -Force (Split-Path $PROFILE)
+  PS> git-branch-delete completion powershell >> $PROFILE
+
+  # You will need to start a new PowerShell for this setup to take effect.
+
+Git Bash:
+  $ source <(git-branch-delete completion bash)
+
+  # To load completions for each session, execute once:
+  $ git-branch-delete completion bash > ~/.git-branch-delete-completion.bash
+  $ echo "source ~/.git-branch-delete-completion.bash" >> ~/.bashrc
+`
+	}
+
+	return `To load completions:
 
 Bash:
   $ source <(git-branch-delete completion bash)
@@ -47,9 +68,15 @@ PowerShell:
   PS> git-branch-delete completion powershell | Out-String | Invoke-Expression
 
   # To load completions for every new session, run:
-  PS> git-branch-delete completion powershell > git-branch-delete.ps1
-  # and source this file from your PowerShell profile.
-`,
+  PS> git-branch-delete completion powershell >> $PROFILE
+`
+}
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:                   "completion [bash|zsh|fish|powershell]",
+		Short:                 "Generate completion script",
+		Long:                  completionHelp(runtime.GOOS),
 		DisableFlagsInUseLine: true,
 		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 		Args:                  cobra.ExactValidArgs(1),
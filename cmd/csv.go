@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+)
+
+// listOutputs are the values accepted by list --output
+var listOutputs = []string{"table", "csv"}
+
+// defaultCSVColumns are the columns list --output csv writes by default
+var defaultCSVColumns = []string{"name", "remote", "commit", "date", "author", "upstream", "ahead", "behind", "merged", "stale", "subject"}
+
+// branchRow is a branch with the metadata shown in exported inventories
+type branchRow struct {
+	Branch   git.GitBranch
+	Detail   git.BranchDetail
+	Tracking git.TrackingStatus // Zero for remote branches
+}
+
+// csvColumns maps the columns accepted by list --columns to their values
+var csvColumns = map[string]func(r branchRow) string{
+	"name":   func(r branchRow) string { return r.Branch.Name },
+	"remote": func(r branchRow) string { return strconv.FormatBool(r.Branch.IsRemote) },
+	"commit": func(r branchRow) string { return r.Branch.CommitHash },
+	"date": func(r branchRow) string {
+		if r.Detail.CommitDate.IsZero() {
+			return ""
+		}
+		return r.Detail.CommitDate.Format(time.RFC3339)
+	},
+	"used": func(r branchRow) string {
+		if r.Branch.LastUsed.IsZero() {
+			return ""
+		}
+		return r.Branch.LastUsed.Format(time.RFC3339)
+	},
+	"author":   func(r branchRow) string { return r.Detail.Author },
+	"upstream": func(r branchRow) string { return r.Tracking.Upstream },
+	"ahead":    func(r branchRow) string { return trackingCount(r, r.Tracking.Ahead) },
+	"behind":   func(r branchRow) string { return trackingCount(r, r.Tracking.Behind) },
+	"merged":   func(r branchRow) string { return strconv.FormatBool(r.Branch.IsMerged) },
+	"stale":    func(r branchRow) string { return strconv.FormatBool(r.Branch.IsStale) },
+	"subject":  func(r branchRow) string { return r.Detail.Subject },
+	"tickets":  func(r branchRow) string { return strings.Join(r.Branch.TicketIDs(), " ") },
+}
+
+// trackingCount formats an ahead/behind count, leaving it empty when the
+// branch has no upstream to compare with
+func trackingCount(r branchRow, n int) string {
+	if r.Tracking.Upstream == "" || r.Tracking.Gone {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// validateListOutput checks the --output format and the --columns names
+func validateListOutput(output string, columns []string) error {
+	valid := false
+	for _, o := range listOutputs {
+		if output == o {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid output format %q (expected one of: %s)", output, strings.Join(listOutputs, ", "))
+	}
+
+	for _, c := range columns {
+		if _, ok := csvColumns[c]; !ok {
+			names := make([]string, 0, len(csvColumns))
+			for name := range csvColumns {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown column %q (expected any of: %s)", c, strings.Join(names, ", "))
+		}
+	}
+	if len(columns) > 0 && output != "csv" {
+		return fmt.Errorf("--columns requires --output csv")
+	}
+	return nil
+}
+
+// branchRows combines the listed branches with their tip commit metadata
+// and tracking status
+func branchRows(g *git.Git, branches []git.GitBranch) ([]branchRow, error) {
+	details, err := g.BranchDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := g.ListTracking()
+	if err != nil {
+		return nil, err
+	}
+	tracking := make(map[string]git.TrackingStatus, len(statuses))
+	for _, s := range statuses {
+		tracking[s.Branch] = s
+	}
+
+	// Tickets are only read here when no --ticket-state filter did
+	if len(cfg.TicketTrailers) > 0 && !slices.ContainsFunc(branches, func(b git.GitBranch) bool { return b.Tickets != nil }) {
+		if err := g.WithTickets(branches, cfg.TicketTrailers); err != nil {
+			return nil, err
+		}
+	}
+
+	rows := make([]branchRow, len(branches))
+	for i, b := range branches {
+		rows[i] = branchRow{Branch: b, Detail: details[b.Reference]}
+		if !b.IsRemote {
+			rows[i].Tracking = tracking[b.Name]
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var updateDefaultBranch bool
+
+func init() {
+	rootCmd.AddCommand(newDefaultBranchCmd())
+}
+
+func newDefaultBranchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "default-branch",
+		Short: "Show the default branch and sync origin/HEAD with the remote",
+		Long: `Show the default branch recorded locally in origin/HEAD and the one origin
+currently reports. When they differ (for example after a master → main
+migration), --update points origin/HEAD at the remote's default branch.`,
+		Example: `  git-branch-delete default-branch
+  git-branch-delete default-branch --update`,
+		RunE: runDefaultBranch,
+	}
+
+	cmd.Flags().BoolVar(&updateDefaultBranch, "update", false, "Update the local origin/HEAD to match the remote")
+
+	return cmd
+}
+
+func runDefaultBranch(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	local, err := g.LocalDefaultBranch()
+	if err != nil {
+		log.Debug("Failed to read origin/HEAD: %v", err)
+		local = ""
+	}
+
+	remote, err := g.RemoteDefaultBranch()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Local (origin/HEAD):  %s", orUnset(local))
+	log.Info("Remote (origin HEAD): %s", remote)
+
+	if local == remote {
+		log.Info("Default branch is up to date")
+		return nil
+	}
+
+	if !updateDefaultBranch {
+		log.Warn("Default branch changed on origin; run with --update to update origin/HEAD")
+		return nil
+	}
+
+	if err := g.SetDefaultBranch(remote); err != nil {
+		return err
+	}
+	log.Info("Updated origin/HEAD to origin/%s", remote)
+	return nil
+}
+
+// refreshDefaultBranch re-resolves the default branch from origin before a
+// destructive run so a renamed default branch is still protected
+func refreshDefaultBranch(g *git.Git) {
+	current, previous, err := g.ResolveDefaultBranch()
+	if err != nil {
+		log.Debug("Failed to resolve default branch from origin: %v", err)
+		return
+	}
+	if previous != "" && previous != current {
+		log.Warn("Default branch on origin changed from %s to %s; run 'git-branch-delete default-branch --update' to update origin/HEAD", previous, current)
+	}
+}
+
+// defaultBranchOrConfig returns the resolved default branch, falling back to
+// the configured one. It is empty when neither is known.
+func defaultBranchOrConfig(g *git.Git) string {
+	if name, err := g.DefaultBranch(); err == nil {
+		return name
+	}
+	if cfg == nil {
+		return ""
+	}
+	return cfg.DefaultBranch
+}
+
+// orUnset returns s, or a placeholder when it is empty
+func orUnset(s string) string {
+	if s == "" {
+		return "(not set)"
+	}
+	return s
+}
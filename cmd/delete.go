@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/bral/git-branch-delete-go/internal/config"
 	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/progress"
+	"github.com/bral/git-branch-delete-go/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +21,28 @@ var (
 	force  bool
 	remote bool
 	all    bool
+	soft   bool
+
+	withRemote     bool
+	deleteScript   bool
+	deleteKeepLast int
 )
 
+// DeleteOptions controls the behavior of Delete
+type DeleteOptions struct {
+	Branches []string // Branch names to delete
+	Force    bool     // Delete even if not merged
+	Remote   bool     // Delete the remote branch instead of the local one
+	All      bool     // Delete both the local and the remote branch
+	Soft     bool     // Archive remote branches under git.ArchivePrefix instead of deleting them
+
+	// Events, when set, receives the progress of the run: the outcome of
+	// each branch as soon as it is known, between Started and Finished.
+	// Total counts both sides of All, though remote branches are only
+	// deleted when their local branch was, so it is an upper bound.
+	Events chan<- progress.Event[BranchResult]
+}
+
 func init() {
 	deleteCmd := newDeleteCmd()
 	rootCmd.AddCommand(deleteCmd)
@@ -22,18 +50,34 @@ func init() {
 	deleteCmd.Flags().BoolVarP(&force, "force", "f", false, "Force delete branches even if not merged")
 	deleteCmd.Flags().BoolVarP(&remote, "remote", "r", false, "Delete remote branches")
 	deleteCmd.Flags().BoolVarP(&all, "all", "a", false, "Delete both local and remote branches")
+	deleteCmd.Flags().BoolVar(&withRemote, "with-remote", false, "Also delete the remote branch of each deleted local branch without asking")
+	deleteCmd.Flags().IntVar(&deleteKeepLast, "keep-last", 0, "Keep the N most recent branches matching each pattern, by committer date")
+	deleteCmd.Flags().BoolVar(&deleteScript, "update-ref-script", false, "Print the ref changes as a 'git update-ref --stdin' script instead of deleting")
+	addCopySummaryFlag(deleteCmd)
+	addTimingsFlag(deleteCmd)
+	deleteCmd.Flags().BoolVar(&soft, "soft", false, "Move remote branches to refs/heads/"+git.ArchivePrefix+" instead of deleting them")
 }
 
 func newDeleteCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "delete [branches...]",
+		Use:   "delete [branches or patterns...]",
 		Short: "Delete git branches",
 		Long: `Delete one or more git branches locally and/or remotely.
-Safely handles branch deletion with checks for unmerged changes.`,
+Safely handles branch deletion with checks for unmerged changes.
+
+Arguments may be patterns such as 'backup/*' or 'release/*-rc*', matching
+branch names like path globs, where * doesn't match a slash. Protected
+branches and the default branch never match. With --keep-last N, the N
+most recent branches matching each pattern by committer date are kept and
+the rest deleted.`,
 		Example: `  git-branch-delete delete feature/123
   git-branch-delete delete -f old-branch
   git-branch-delete delete -r origin/feature/123
-  git-branch-delete delete -a feature/123`,
+  git-branch-delete delete -a feature/123
+  git-branch-delete delete --with-remote feature/123
+  git-branch-delete delete -r --soft feature/123
+  git-branch-delete delete --keep-last 3 'backup/*'
+  git-branch-delete delete --update-ref-script feature/123 > delete.txt`,
 		RunE: runDelete,
 	}
 }
@@ -42,45 +86,595 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("branch name required")
 	}
+	if soft && !remote && !all {
+		return fmt.Errorf("--soft requires --remote or --all")
+	}
+	if withRemote && (remote || all) {
+		return fmt.Errorf("--with-remote can't be combined with --remote or --all")
+	}
+	timings, err := timingsOrder(cmd)
+	if err != nil {
+		return err
+	}
+	if deleteKeepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+	if deleteKeepLast > 0 && !slices.ContainsFunc(args, git.IsBranchPattern) {
+		return fmt.Errorf("--keep-last needs a branch pattern, such as 'backup/*'")
+	}
 
-	branchName := args[0]
+	// Initialize git client
+	gitClient, err := openRepo()
+	if err != nil {
+		log.Error("Failed to initialize git client: %v", err)
+		return err
+	}
 
-	// Get current directory
-	dir, err := os.Getwd()
+	branches, err := expandBranchPatterns(gitClient, args, remote, deleteKeepLast)
 	if err != nil {
-		log.Error("Failed to get current directory", "error", err)
 		return err
 	}
+	if len(branches) == 0 {
+		log.Info("No branches left to delete")
+		return nil
+	}
 
-	// Initialize git client
-	gitClient, err := git.New(dir)
+	opts := DeleteOptions{
+		Branches: branches,
+		Force:    force,
+		Remote:   remote,
+		All:      all,
+		Soft:     soft,
+	}
+
+	if deleteScript {
+		refreshDefaultBranch(gitClient)
+		plan, err := PlanDelete(gitClient, opts)
+		if err != nil {
+			return err
+		}
+		return newPresenter(os.Stdout).updateRefScript(plan)
+	}
+	if safeDryRun(gitClient, false) {
+		plan, err := PlanDelete(gitClient, opts)
+		if err != nil {
+			return err
+		}
+		newPresenter(os.Stdout).deletePlan(plan)
+		return nil
+	}
+
+	ui.ShowBanner(cfg.Confirmation)
+	refreshDefaultBranch(gitClient)
+
+	if opts.Force && len(opts.Branches) > 1 {
+		if opts.Branches, err = confirmForcedDelete(gitClient, opts); err != nil {
+			return err
+		}
+		if len(opts.Branches) == 0 {
+			log.Info("No branches left to delete")
+			return nil
+		}
+	}
+
+	created := branchCreations(gitClient)
+	res, err := Delete(gitClient, opts)
 	if err != nil {
-		log.Error("Failed to initialize git client", "error", err)
 		return err
 	}
 
-	// Check if branch is protected
-	for _, protected := range cfg.ProtectedBranches {
-		if branchName == protected {
-			return fmt.Errorf("cannot delete protected branch: %s", branchName)
+	p := newPresenter(os.Stdout)
+	p.delete(res)
+	if !opts.Remote && !opts.All {
+		counterparts := deleteRemoteCounterparts(gitClient, res.Deleted, opts.Force, withRemote || cfg.WithRemote)
+		p.delete(counterparts)
+		res.Deleted = append(res.Deleted, counterparts.Deleted...)
+		res.Failed = append(res.Failed, counterparts.Failed...)
+	}
+	queueFailures(gitClient, res.Failed, opts)
+	recordDeletions(gitClient, res.Deleted, created)
+	copySummary(res.Deleted)
+	showTimings(res, timings)
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to delete %d branch(es)", len(res.Failed))
+	}
+	return nil
+}
+
+// expandBranchPatterns replaces the patterns among args by the branches
+// they match, leaving out protected branches, the default branch and, for
+// each pattern, the keepLast most recent matches. Names are kept as given.
+func expandBranchPatterns(g *git.Git, args []string, remote bool, keepLast int) ([]string, error) {
+	defaultBranch, _ := g.DefaultBranch()
+
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
 		}
 	}
+	for _, arg := range args {
+		if !git.IsBranchPattern(arg) {
+			add(arg)
+			continue
+		}
 
-	// Delete the branch
-	if err := gitClient.DeleteBranch(branchName, force, remote); err != nil {
-		return fmt.Errorf("failed to delete branch: %w", err)
+		matches, err := g.MatchBranches(arg, remote)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			log.Warn("No branches match %s", arg)
+			continue
+		}
+		// Protected branches are never deleted, so they don't count
+		// towards the ones --keep-last keeps
+		matches = slices.DeleteFunc(matches, func(name string) bool {
+			if name == defaultBranch || g.IsProtected(name, remote) {
+				log.Debug("Skipping protected branch %s matching %s", name, arg)
+				return true
+			}
+			return false
+		})
+		if keepLast > 0 && len(matches) > 0 {
+			kept := matches[:min(keepLast, len(matches))]
+			log.Info("Keeping the %d most recent branch(es) matching %s: %s", len(kept), arg, strings.Join(kept, ", "))
+			matches = matches[len(kept):]
+		}
+		for _, name := range matches {
+			add(name)
+		}
 	}
+	return names, nil
+}
 
-	log.Info("Successfully deleted branch:", branchName)
+// confirmForcedDelete applies force_confirmation to the branches a forced
+// bulk delete names and returns the ones to delete
+func confirmForcedDelete(g *git.Git, opts DeleteOptions) ([]string, error) {
+	if forceConfirmation() == config.ForceConfirmNone {
+		return opts.Branches, nil
+	}
 
-	// If --all flag is set, also delete remote branch
-	if all && !remote {
-		log.Info("Deleting remote branch:", branchName)
-		if err := gitClient.DeleteBranch(branchName, force, true); err != nil {
-			return fmt.Errorf("failed to delete remote branch: %w", err)
+	merged, err := g.MergedIntoTargets(opts.Remote)
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]git.GitBranch, len(opts.Branches))
+	for i, name := range opts.Branches {
+		b := git.GitBranch{Name: name, IsRemote: opts.Remote}
+		b.Reference = branchRef(b)
+		if opts.Remote {
+			b.IsMerged = merged["origin/"+name]
+		} else {
+			b.IsMerged = merged[name]
 		}
-		log.Info("Successfully deleted remote branch:", branchName)
+		branches[i] = b
 	}
 
+	confirmed, err := confirmForced(g, branches)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(confirmed))
+	for i, b := range confirmed {
+		names[i] = b.Name
+	}
+	return names, nil
+}
+
+// checkProtected fails if any branch in opts is the default branch or is
+// protected on a side (local or remote) opts would delete it from
+func checkProtected(g *git.Git, opts DeleteOptions) error {
+	defaultBranch, _ := g.DefaultBranch()
+	for _, branchName := range opts.Branches {
+		if branchName == defaultBranch {
+			return fmt.Errorf("cannot delete default branch: %s", branchName)
+		}
+		if !opts.Remote && g.IsProtected(branchName, false) {
+			return protectedError(g, branchName, false)
+		}
+		if (opts.Remote || opts.All) && g.IsProtected(branchName, true) {
+			return protectedError(g, branchName, true)
+		}
+	}
 	return nil
 }
+
+// protectedError explains that the local or remote branch name is protected,
+// with the reason when it's pinned rather than matching a pattern
+func protectedError(g *git.Git, name string, remote bool) error {
+	kind := "branch"
+	if remote {
+		kind = "remote branch"
+	}
+	if reason := g.ProtectionReason(name, remote); reason != "protected" {
+		return fmt.Errorf("cannot delete %s %s: %s", kind, name, reason)
+	}
+	return fmt.Errorf("cannot delete protected %s: %s", kind, name)
+}
+
+// Delete deletes the branches named in opts and reports the outcome of each.
+// Runs of checkpointThreshold branches or more are checkpointed, so an
+// interrupted run resumes where it stopped when repeated.
+func Delete(g *git.Git, opts DeleteOptions) (*DeleteResult, error) {
+	total := len(opts.Branches)
+	if opts.All && !opts.Remote {
+		total *= 2
+	}
+	rep := progress.Start(opts.Events, total)
+	defer rep.Finish()
+
+	if len(opts.Branches) >= checkpointThreshold {
+		return deleteCheckpointed(g, opts, rep)
+	}
+	return deleteBatch(g, opts, rep)
+}
+
+// reportResults sends the outcome of branches to rep
+func reportResults(rep *progress.Reporter[BranchResult], results ...BranchResult) {
+	for _, r := range results {
+		if r.Error != "" {
+			rep.Failed(r)
+		} else {
+			rep.Completed(r)
+		}
+	}
+}
+
+// deleteBatch deletes the branches named in opts, reporting each outcome
+// to rep
+func deleteBatch(g *git.Git, opts DeleteOptions, rep *progress.Reporter[BranchResult]) (*DeleteResult, error) {
+	// Check if any branch is protected before touching anything
+	if err := checkProtected(g, opts); err != nil {
+		return nil, err
+	}
+
+	// Branches involved in an ongoing operation can't be deleted locally
+	inUse, err := g.ScanBranchReferences()
+	if err != nil {
+		log.Debug("Failed to scan branch references: %v", err)
+	}
+
+	// For bulk remote deletions, check permissions for every target first so
+	// failures are reported upfront instead of midway through the batch
+	var blocked map[string]error
+	if (opts.Remote || opts.All) && len(opts.Branches) > 1 {
+		blocked = g.CheckRemoteDeletes(opts.Branches)
+	}
+
+	res := &DeleteResult{}
+	fail := func(r BranchResult) {
+		res.Failed = append(res.Failed, r)
+		reportResults(rep, r)
+	}
+
+	var targets []BranchResult
+	seen := make(map[string]bool)
+	for _, branchName := range opts.Branches {
+		// A branch named twice is deleted once
+		if seen[branchName] {
+			continue
+		}
+		seen[branchName] = true
+
+		// A blocked remote branch fails alone; with --all, its local branch
+		// is still deleted
+		if err, ok := blocked[branchName]; ok {
+			fail(newBranchResult(git.GitBranch{Name: branchName, IsRemote: true}, err))
+			if opts.Remote {
+				continue
+			}
+		}
+
+		branch := git.GitBranch{Name: branchName, IsRemote: opts.Remote}
+		if err := git.CheckNotInUse(inUse, branchName); err != nil && !opts.Remote {
+			fail(newBranchResult(branch, err))
+			continue
+		}
+
+		branch.CommitHash = branchCommit(g, branch)
+		targets = append(targets, withRisk(newBranchResult(branch, nil), deletionRisk(g, branch, opts.Soft)))
+	}
+
+	// Back up local branches first so they can be restored from the trash
+	if !opts.Remote {
+		local := make([]git.GitBranch, len(targets))
+		for i, t := range targets {
+			local[i] = git.GitBranch{Name: t.Name}
+		}
+		if err := trashBranches(g, local); err != nil {
+			return nil, err
+		}
+	}
+
+	// Delete the branches, locally in one transaction when possible
+	var deleted []BranchResult
+	if !opts.Remote && len(targets) > 1 && g.SupportsRefTransactions() {
+		var failed []BranchResult
+		deleted, failed = deleteLocalAtomic(g, targets, opts.Force)
+		res.Failed = append(res.Failed, failed...)
+		reportResults(rep, failed...)
+		reportResults(rep, deleted...)
+	} else {
+		var failed []BranchResult
+		deleted, failed = deleteEach(g, targets, opts, rep)
+		res.Failed = append(res.Failed, failed...)
+	}
+	res.Deleted = append(res.Deleted, deleted...)
+
+	// If --all flag is set, also delete remote branches
+	if opts.All && !opts.Remote {
+		var remotes []BranchResult
+		for _, local := range deleted {
+			if _, ok := blocked[local.Name]; ok {
+				continue
+			}
+			log.Info("Deleting remote branch: %s", local.Name)
+			branch := git.GitBranch{Name: local.Name, IsRemote: true}
+			branch.CommitHash = branchCommit(g, branch)
+			remotes = append(remotes, withRisk(newBranchResult(branch, nil), deletionRisk(g, branch, opts.Soft)))
+		}
+		remoteOpts := opts
+		remoteOpts.Remote = true
+		deleted, failed := deleteEach(g, remotes, remoteOpts, rep)
+		res.Deleted = append(res.Deleted, deleted...)
+		res.Failed = append(res.Failed, failed...)
+	}
+
+	return res, nil
+}
+
+// deleteEach deletes targets one by one with deleteOne, reporting each
+// outcome to rep as it is known. Remote branches are deleted by
+// git.DefaultBatchWorkers at once, since each is a round trip to origin;
+// local ones one at a time, as each deletion locks the refs. Results keep
+// the order of targets.
+func deleteEach(g *git.Git, targets []BranchResult, opts DeleteOptions, rep *progress.Reporter[BranchResult]) (deleted, failed []BranchResult) {
+	workers := 1
+	if opts.Remote {
+		workers = git.DefaultBatchWorkers
+	}
+
+	// Targets have unique names, which map the batch's branches back to them
+	branches := make([]git.GitBranch, len(targets))
+	index := make(map[string]int, len(targets))
+	for i, t := range targets {
+		branches[i] = git.GitBranch{Name: t.Name, IsRemote: opts.Remote}
+		index[t.Name] = i
+	}
+	started := make([]time.Time, len(targets))
+	results := make([]BranchResult, len(targets))
+
+	bp := git.NewBatchProcessor(g, git.BatchOptions{
+		Workers: workers,
+		Progress: func(p git.BatchProgress) {
+			i := index[p.Last.Branch.Name]
+			t := targets[i].timed(started[i])
+			if p.Last.Err != nil {
+				t.setError(p.Last.Err)
+			} else {
+				// Archived remote branches keep their commits
+				t = t.forced(opts.Force && !(opts.Remote && opts.Soft))
+			}
+			results[i] = t
+			reportResults(rep, t)
+		},
+	})
+	_, _ = bp.ProcessBranches(context.Background(), branches, func(b git.GitBranch) error {
+		started[index[b.Name]] = time.Now()
+		return deleteOne(g, b.Name, opts.Force, opts.Remote, opts.Soft)
+	})
+
+	for _, t := range results {
+		if t.Error != "" {
+			failed = append(failed, t)
+		} else {
+			deleted = append(deleted, t)
+		}
+	}
+	return deleted, failed
+}
+
+// deleteRemoteCounterparts deletes the remote branches sharing a name with
+// deleted local branches, when they still exist. Unless always is set, the
+// user is asked first; a declined or unanswered question deletes nothing.
+func deleteRemoteCounterparts(g *git.Git, deleted []BranchResult, force, always bool) *DeleteResult {
+	res := &DeleteResult{}
+
+	gone := make(map[string]bool)
+	for _, b := range deleted {
+		if b.Remote {
+			gone[b.Name] = true
+		}
+	}
+	var targets []git.GitBranch
+	for _, b := range deleted {
+		if b.Remote || gone[b.Name] || g.IsProtected(b.Name, true) {
+			continue
+		}
+		remote := git.GitBranch{Name: b.Name, IsRemote: true}
+		if remote.CommitHash = branchCommit(g, remote); remote.CommitHash != "" {
+			targets = append(targets, remote)
+		}
+	}
+	if len(targets) == 0 {
+		return res
+	}
+
+	if !always {
+		names := make([]string, len(targets))
+		for i, b := range targets {
+			names[i] = "origin/" + b.Name
+		}
+		ok, err := prompter.Confirm(fmt.Sprintf("Also delete the remote branch(es) %s?", strings.Join(names, ", ")), false)
+		if err != nil && err != ui.ErrInterrupted {
+			log.Debug("Failed to ask about remote branches: %v", err)
+		}
+		if !ok {
+			return res
+		}
+	}
+
+	for _, b := range targets {
+		log.Info("Deleting remote branch: %s", b.Name)
+		risk := deletionRisk(g, b, false)
+		started := time.Now()
+		// Only delete what the user was asked about: the remote-tracking
+		// tip. Commits pushed since are never lost.
+		if err := g.DeleteRemoteBranchAt(b.Name, b.CommitHash); err != nil {
+			res.Failed = append(res.Failed, withRisk(newBranchResult(b, err).timed(started), risk))
+			continue
+		}
+		res.Deleted = append(res.Deleted, withRisk(newBranchResult(b, nil).timed(started), risk).forced(force))
+	}
+	return res
+}
+
+// deleteLocalAtomic deletes local branches in a single ref transaction, so
+// either all of them are deleted or all of them fail
+func deleteLocalAtomic(g *git.Git, targets []BranchResult, force bool) (deleted, failed []BranchResult) {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.Name
+	}
+
+	log.Debug("Deleting %d local branches in one transaction", len(names))
+	started := time.Now()
+	err := g.DeleteBranchesAtomic(names, force)
+	for i := range targets {
+		targets[i] = targets[i].timed(started)
+	}
+	var leftover *git.ErrBranchConfig
+	if errors.As(err, &leftover) {
+		// The branches are gone; only their configuration stayed
+		log.Warn("%v", err)
+		err = nil
+	}
+	if err != nil {
+		for _, t := range targets {
+			t.setError(err)
+			failed = append(failed, t)
+		}
+		return nil, failed
+	}
+	for i := range targets {
+		targets[i] = targets[i].forced(force)
+	}
+	return targets, nil
+}
+
+// PlanDelete returns the exact ref changes Delete would make, without
+// deleting anything
+func PlanDelete(g *git.Git, opts DeleteOptions) (*ScriptResult, error) {
+	if err := checkProtected(g, opts); err != nil {
+		return nil, err
+	}
+
+	inUse, err := g.ScanBranchReferences()
+	if err != nil {
+		log.Debug("Failed to scan branch references: %v", err)
+	}
+
+	var targets, skipped []BranchResult
+	for _, branchName := range opts.Branches {
+		if reason, ok := inUse[branchName]; ok && !opts.Remote {
+			skipped = append(skipped, BranchResult{Name: branchName, Reason: reason})
+			continue
+		}
+		if !opts.Remote {
+			targets = append(targets, BranchResult{Name: branchName})
+		}
+		if opts.Remote || opts.All {
+			targets = append(targets, BranchResult{Name: branchName, Remote: true})
+		}
+	}
+
+	res := planRefChanges(g, targets, opts.Soft)
+	res.Skipped = append(skipped, res.Skipped...)
+	return res, nil
+}
+
+// branchRef returns the local ref of a branch, which is the remote-tracking
+// ref for remote branches
+func branchRef(b git.GitBranch) string {
+	if b.IsRemote {
+		return "refs/remotes/origin/" + b.Name
+	}
+	return "refs/heads/" + b.Name
+}
+
+// branchCommit returns the commit a branch points at, or "" when it can't
+// be resolved (e.g. a remote branch that was never fetched)
+func branchCommit(g *git.Git, b git.GitBranch) string {
+	ref := branchRef(b)
+	hash, err := g.ResolveRef(ref)
+	if err != nil {
+		log.Debug("Failed to resolve %s: %v", ref, err)
+		return ""
+	}
+	return hash
+}
+
+// deletionRisk warns about tags and git notes referring to commits only the
+// branch reaches and returns the risk of deleting it. Soft-deleted remote
+// branches keep their commits, so they carry no risk.
+func deletionRisk(g *git.Git, b git.GitBranch, soft bool) string {
+	risk, warnings := deletionWarnings(g, b, soft)
+	for _, w := range warnings {
+		log.Warn("%s", w)
+	}
+	return risk
+}
+
+// deletionWarnings returns the risk of deleting a branch and the warnings
+// deletionRisk logs, for callers showing them elsewhere
+func deletionWarnings(g *git.Git, b git.GitBranch, soft bool) (string, []string) {
+	if b.IsRemote && soft {
+		return git.RiskNone, nil
+	}
+
+	ref := branchRef(b)
+	impact, err := g.DeletionImpact(ref)
+	if err != nil {
+		log.Debug("Failed to check deletion impact of %s: %v", b.Name, err)
+		return "", nil
+	}
+
+	var warnings []string
+	if len(impact.Tags) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Branch %s has commits tagged %s; they stay reachable through the tags",
+			b.Name, strings.Join(impact.Tags, ", ")))
+	}
+	if impact.OrphanedNotes > 0 {
+		warnings = append(warnings, fmt.Sprintf("Branch %s has git notes on %d commit(s) that become unreachable; the notes will be orphaned",
+			b.Name, impact.OrphanedNotes))
+	}
+	return impact.Risk(), warnings
+}
+
+// withRisk sets the risk of a branch result
+func withRisk(res BranchResult, risk string) BranchResult {
+	res.Risk = risk
+	return res
+}
+
+// deleteOne deletes a single branch, archiving it instead when soft is set
+// and the branch is remote
+func deleteOne(g *git.Git, name string, force, remote, soft bool) error {
+	if remote && soft {
+		return g.ArchiveRemoteBranch(name)
+	}
+	return g.DeleteBranch(name, force, remote)
+}
+
+// deleteListed deletes a branch as it was listed. Remote branches are only
+// deleted while origin still has them at the listed commit.
+func deleteListed(g *git.Git, b git.GitBranch, force bool) error {
+	if b.IsRemote && b.CommitHash != "" {
+		return g.DeleteRemoteBranchAt(b.Name, b.CommitHash)
+	}
+	return g.DeleteBranch(b.Name, force, b.IsRemote)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRemoteCounterparts(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/done")
+	r.Tracked("feature/moved")
+	r.Tracked("feature/local-only")
+	r.DeleteOnOrigin("feature/local-only")
+	r.Git("fetch", "--quiet", "--prune", "origin")
+
+	// A teammate pushes to feature/moved; this clone hasn't fetched it
+	r.Git("--git-dir", r.Origin, "update-ref", "refs/heads/feature/moved", "main")
+
+	deleted := []BranchResult{{Name: "feature/done"}, {Name: "feature/moved"}, {Name: "feature/local-only"}}
+	res := deleteRemoteCounterparts(g, deleted, false, true)
+
+	assert.Equal(t, []string{"feature/done"}, resultNames(res.Deleted))
+	require.Len(t, res.Failed, 1)
+	assert.Equal(t, "feature/moved", res.Failed[0].Name)
+	assert.Contains(t, res.Failed[0].Error, "moved")
+	assert.False(t, r.HasRemoteBranch("feature/done"))
+	assert.True(t, r.HasRemoteBranch("feature/moved"))
+}
+
+func TestDeleteRemoteCounterpartsSkipsDeletedRemotes(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/done")
+
+	deleted := []BranchResult{{Name: "feature/done"}, {Name: "feature/done", Remote: true}}
+	res := deleteRemoteCounterparts(g, deleted, false, true)
+	assert.Empty(t, res.Deleted)
+	assert.Empty(t, res.Failed)
+	assert.True(t, r.HasRemoteBranch("feature/done"))
+}
+
+func TestExpandBranchPatternsKeepLast(t *testing.T) {
+	r, g := newTestRepo(t)
+	// Each commit is newer than the one before, so backup/a is the most recent
+	for _, name := range []string{"backup/b", "backup/c", "backup/d", "backup/a"} {
+		r.Local(name)
+	}
+	g.SetProtection(git.Protection{Local: []string{"backup/a"}})
+
+	// The protected branch doesn't use up one of the kept ones
+	names, err := expandBranchPatterns(g, []string{"backup/*"}, false, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup/c", "backup/b"}, names)
+
+	names, err = expandBranchPatterns(g, []string{"backup/*"}, false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup/d", "backup/c", "backup/b"}, names)
+}
+
+func TestDeleteRemoteBatch(t *testing.T) {
+	r, g := newTestRepo(t)
+	names := []string{"feature/a", "feature/b", "feature/c", "feature/d", "feature/e"}
+	for _, name := range names {
+		r.Tracked(name)
+	}
+
+	events := make(chan progress.Event[BranchResult], 64)
+	res, err := Delete(g, DeleteOptions{Branches: append(names, "feature/a"), Remote: true, Force: true, Events: events})
+	require.NoError(t, err)
+
+	// Deleted in parallel, reported in the order asked for, each once
+	assert.Equal(t, names, resultNames(res.Deleted))
+	assert.Empty(t, res.Failed)
+	assert.Equal(t, []string{"main"}, r.RemoteBranches())
+	for _, name := range names {
+		assert.True(t, r.HasBranch(name), "local branch %s is kept", name)
+	}
+
+	close(events)
+	completed := 0
+	for e := range events {
+		if e.Kind == progress.Completed {
+			completed++
+		}
+	}
+	assert.Equal(t, len(names), completed)
+}
+
+func TestDeleteInUse(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Merged("feature/done")
+	r.Merged("feature/wt")
+	r.Git("worktree", "add", "--quiet", t.TempDir()+"/wt", "feature/wt")
+
+	res, err := Delete(g, DeleteOptions{Branches: []string{"feature/done", "feature/wt"}, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature/done"}, resultNames(res.Deleted))
+	require.Len(t, res.Failed, 1)
+	assert.Equal(t, "feature/wt", res.Failed[0].Name)
+	assert.Contains(t, res.Failed[0].Error, "is in use: checked out in worktree")
+	assert.True(t, r.HasBranch("feature/wt"))
+}
+
+func TestDeleteBlockedRemote(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        DeleteOptions
+		deleted     []string
+		failed      []string
+		keptLocally []string
+	}{
+		{
+			name:    "all",
+			opts:    DeleteOptions{All: true, Force: true},
+			deleted: []string{"feature/done", "feature/gone", "feature/done"},
+			failed:  []string{"feature/gone"},
+		},
+		{
+			name:        "remote",
+			opts:        DeleteOptions{Remote: true, Force: true},
+			deleted:     []string{"feature/done"},
+			failed:      []string{"feature/gone"},
+			keptLocally: []string{"feature/done", "feature/gone"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g := newTestRepo(t)
+			r.Tracked("feature/done")
+			r.Gone("feature/gone") // Its remote deletion is blocked
+
+			tt.opts.Branches = []string{"feature/done", "feature/gone"}
+			res, err := Delete(g, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.deleted, resultNames(res.Deleted))
+			assert.Equal(t, tt.failed, resultNames(res.Failed))
+			for _, f := range res.Failed {
+				assert.True(t, f.Remote, "only the remote side of %s fails", f.Name)
+			}
+			assert.Equal(t, []string{"main"}, r.RemoteBranches())
+			for _, name := range tt.opts.Branches {
+				assert.Equal(t, slices.Contains(tt.keptLocally, name), r.HasBranch(name), name)
+			}
+		})
+	}
+}
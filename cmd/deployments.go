@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/github"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// deploymentsTimeout bounds reading the deployments of a repository
+const deploymentsTimeout = 30 * time.Second
+
+// deployedBranches returns the branches of origin's GitHub repository that
+// are deployed to one of the configured environments, with why they are
+// pinned, e.g. "deployed to production". Without a GitHub origin there is
+// nothing to look up. Failing to read the deployments fails, rather than
+// leaving deployed branches unprotected; only deployments too old to be
+// read, past the API limits, are left out with a warning.
+func deployedBranches(g *git.Git) (map[string]string, error) {
+	host, err := g.RemoteHost()
+	if err != nil {
+		log.Debug("Not checking deployments: %v", err)
+		return nil, nil
+	}
+	apiURL, err := githubAPIURL(host)
+	if err != nil {
+		log.Debug("Not checking deployments: %v", err)
+		return nil, nil
+	}
+	repo, err := g.RemoteRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check deployments: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deploymentsTimeout)
+	defer cancel()
+	deployed, err := newGitHubClient(apiURL, host).DeployedRefs(ctx, repo, cfg.Deployments.Environments)
+	if errors.Is(err, github.ErrIncomplete) {
+		log.Warn("%v; branches deployed only by older ones aren't protected", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to check deployments of %s (set deployments.protect to false to skip): %w", repo, err)
+	}
+
+	pinned := make(map[string]string, len(deployed))
+	for ref, envs := range deployed {
+		// Deployments of tags and commits aren't about branches; names that
+		// match no branch pin nothing
+		pinned[strings.TrimPrefix(ref, "refs/heads/")] = "deployed to " + strings.Join(envs, ", ")
+	}
+	log.Debug("%d branches of %s are deployed", len(pinned), repo)
+	return pinned, nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/github"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/fatih/color"
+)
+
+// enrichPRState names the enricher looking up pull request states
+const enrichPRState = "pr-state"
+
+// prCacheFile keeps the pull requests of branch tips between runs, in the
+// user cache directory
+const prCacheFile = stateDir + "/pull-requests.json"
+
+// branchDetails collects expensive branch metadata computed in the
+// background, so the selector can show branches right away and fill in
+// their details as they arrive
+type branchDetails struct {
+	mu      sync.Mutex
+	pending map[string]bool                      // Refs still being enriched
+	got     map[string]map[string]git.Enrichment // Ref to enricher to result
+
+	// refresh receives after each new result, coalescing bursts
+	refresh chan struct{}
+
+	// prCache keeps the pull request states looked up, when they are
+	prCache *github.Cache
+}
+
+// enrichBranches starts computing the distance from upstream, the unique
+// history and the pull request state of branches, in order, until ctx is
+// done
+func enrichBranches(ctx context.Context, g *git.Git, branches []git.GitBranch, base string) *branchDetails {
+	d := &branchDetails{
+		pending: make(map[string]bool, len(branches)),
+		got:     make(map[string]map[string]git.Enrichment, len(branches)),
+		refresh: make(chan struct{}, 1),
+	}
+	enrichers := []git.Enricher{g.AheadBehindEnricher()}
+	if base != "" {
+		enrichers = append(enrichers, g.WeightEnricher(base))
+	}
+	if pr, cache := prStateEnricher(g); pr != nil {
+		enrichers = append(enrichers, *pr)
+		d.prCache = cache
+	}
+	for _, b := range branches {
+		d.pending[b.Reference] = true
+	}
+
+	results := git.Enrich(ctx, branches, enrichers, git.EnrichOptions{})
+	go func() {
+		for e := range results {
+			d.mu.Lock()
+			if d.got[e.Ref] == nil {
+				d.got[e.Ref] = make(map[string]git.Enrichment, len(enrichers))
+			}
+			d.got[e.Ref][e.Enricher] = e
+			if len(d.got[e.Ref]) == len(enrichers) {
+				delete(d.pending, e.Ref)
+			}
+			d.mu.Unlock()
+
+			select {
+			case d.refresh <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return d
+}
+
+// prStateEnricher looks up the pull request of each local branch on
+// origin's GitHub repository by the branch's tip, through a cache kept
+// between runs. It returns nil when origin isn't on GitHub, or without a
+// token: a long listing would use up the anonymous hourly limit.
+func prStateEnricher(g *git.Git) (*git.Enricher, *github.Cache) {
+	host, err := g.RemoteHost()
+	if err != nil {
+		return nil, nil
+	}
+	apiURL, err := githubAPIURL(host)
+	if err != nil {
+		return nil, nil
+	}
+	repo, err := g.RemoteRepoPath()
+	if err != nil {
+		return nil, nil
+	}
+	client := newGitHubClient(apiURL, host)
+	if client.Token == "" {
+		return nil, nil
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		client.Cache = github.OpenCache(filepath.Join(dir, prCacheFile))
+	}
+
+	return &git.Enricher{Name: enrichPRState, Fn: func(ctx context.Context, b git.GitBranch) (interface{}, error) {
+		if b.IsRemote {
+			return nil, nil
+		}
+		sha, err := g.ResolveRef(b.Reference)
+		if err != nil {
+			return nil, err
+		}
+		return client.BranchPRState(ctx, repo, b.Name, sha)
+	}}, client.Cache
+}
+
+// save keeps the pull request states looked up for the next run
+func (d *branchDetails) save() {
+	if d.prCache == nil {
+		return
+	}
+	if err := d.prCache.Save(); err != nil {
+		log.Debug("Failed to save pull request cache: %v", err)
+	}
+}
+
+// describe returns the details known so far about b
+func (d *branchDetails) describe(b git.GitBranch) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var parts []string
+	if e, ok := d.got[b.Reference][git.EnrichAheadBehind]; ok && e.Err == nil && e.Value != nil {
+		status := e.Value.(git.TrackingStatus)
+		parts = append(parts, fmt.Sprintf("↑%d ↓%d %s", status.Ahead, status.Behind, status.Upstream))
+	}
+	if e, ok := d.got[b.Reference][git.EnrichWeight]; ok && e.Err == nil {
+		w := e.Value.(git.BranchWeight)
+		parts = append(parts, "unique: "+formatWeight(&w))
+	}
+	if e, ok := d.got[b.Reference][enrichPRState]; ok && e.Err == nil && e.Value != nil {
+		parts = append(parts, "PR: "+e.Value.(string))
+	}
+	if d.pending[b.Reference] {
+		parts = append(parts, "loading details…")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return color.HiBlackString(strings.Join(parts, " · "))
+}
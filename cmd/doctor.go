@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maintenanceTimeout bounds git gc and the other remediations, which take
+// much longer than the commands used for branch cleanup
+const maintenanceTimeout = 30 * time.Minute
+
+var (
+	doctorFix          bool
+	doctorExpireReflog bool
+)
+
+// DoctorOptions controls what Doctor does
+type DoctorOptions struct {
+	Fix bool // Apply the recommended remediations
+
+	// ExpireReflog lets Fix expire reflogs, whose entries are what deleted
+	// branches are recovered from; without it that remediation is skipped
+	ExpireReflog bool
+}
+
+func init() {
+	rootCmd.AddCommand(newDoctorCmd())
+}
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check repository health and recommend maintenance",
+		Long: `Report loose objects, packs, refs and reflog size, and recommend maintenance
+when they grow past git's usual limits: running git gc, expiring old reflog
+entries or packing loose refs. Also check that the terminal supports what
+interactive mode needs, such as escape sequences and colors.
+
+With --fix, the recommended remediations are applied, except expiring reflogs,
+which also needs --expire-reflog. It only drops entries older than
+gc.reflogExpire (90 days by default), but those entries can no longer be
+used to recover deleted branches.`,
+		Example: `  git-branch-delete doctor
+  git-branch-delete doctor --fix
+  git-branch-delete doctor --fix --expire-reflog`,
+		RunE: runDoctor,
+	}
+
+	cmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply the recommended remediations")
+	cmd.Flags().BoolVar(&doctorExpireReflog, "expire-reflog", false, "With --fix, also expire old reflog entries, which can no longer recover deleted branches")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	if doctorExpireReflog && !doctorFix {
+		return fmt.Errorf("--expire-reflog needs --fix")
+	}
+
+	res, err := Doctor(g, DoctorOptions{Fix: doctorFix, ExpireReflog: doctorExpireReflog})
+	if res != nil {
+		terminal := ui.DetectTerminal(os.Stdin, os.Stdout)
+		res.Terminal = &terminal
+		newPresenter(os.Stdout).doctor(res)
+	}
+	return err
+}
+
+// Doctor measures repository health and, with opts.Fix, applies the
+// recommended remediations and measures again
+func Doctor(g *git.Git, opts DoctorOptions) (*DoctorResult, error) {
+	health, err := g.Health()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &DoctorResult{Health: health}
+	for _, rec := range health.Recommendations() {
+		if rec.Remedy == git.RemedyGC && g.IsShared() {
+			log.Warn("Not recommending gc for %s: this is a shared clone, where it can prune objects other clones borrow", rec.Reason)
+			continue
+		}
+		res.Recommendations = append(res.Recommendations, rec)
+	}
+	if !opts.Fix || len(res.Recommendations) == 0 {
+		return res, nil
+	}
+
+	g.SetTimeout(maintenanceTimeout)
+	for _, rec := range res.Recommendations {
+		if rec.Remedy == git.RemedyExpireReflog && !opts.ExpireReflog {
+			log.Warn("Not expiring reflogs (%s): deleted branches are recovered from them; pass --expire-reflog to expire them anyway", rec.Reason)
+			continue
+		}
+		log.Info("Applying %s: %s", rec.Remedy, rec.Reason)
+		if err := g.Remediate(rec.Remedy); err != nil {
+			return res, err
+		}
+		res.Fixed = append(res.Fixed, rec.Remedy)
+	}
+
+	after, err := g.Health()
+	if err != nil {
+		return res, err
+	}
+	res.After = &after
+	return res, nil
+}
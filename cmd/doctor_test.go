@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorKeepsReflogs(t *testing.T) {
+	r, g := newTestRepo(t)
+
+	// Years-old entries, more than doctor lets pass
+	head := r.Git("rev-parse", "main")
+	entry := head + " " + head + " Test <test@example.com> 1000000000 +0000\tcommit: old\n"
+	reflog := filepath.Join(r.Dir, ".git", "logs", "refs", "heads", "main")
+	f, err := os.OpenFile(reflog, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(strings.Repeat(entry, 10001))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	res, err := Doctor(g, DoctorOptions{Fix: true})
+	require.NoError(t, err)
+	require.Contains(t, remedies(res.Recommendations), git.RemedyExpireReflog)
+	assert.NotContains(t, res.Fixed, git.RemedyExpireReflog)
+	assert.Greater(t, res.After.ReflogEntries, 10000)
+
+	res, err = Doctor(g, DoctorOptions{Fix: true, ExpireReflog: true})
+	require.NoError(t, err)
+	assert.Contains(t, res.Fixed, git.RemedyExpireReflog)
+	assert.Less(t, res.After.ReflogEntries, 10000)
+}
+
+// remedies returns the remediations recommended
+func remedies(recs []git.Recommendation) []string {
+	names := make([]string, len(recs))
+	for i, rec := range recs {
+		names[i] = rec.Remedy
+	}
+	return names
+}
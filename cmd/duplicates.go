@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	duplicatesRemote bool
+	duplicatesAll    bool
+	duplicatesDelete bool
+	duplicatesForce  bool
+)
+
+// DuplicatesOptions controls the behavior of Duplicates
+type DuplicatesOptions struct {
+	Remote bool // Only remote branches
+	All    bool // Both local and remote branches
+	// Confirm is asked before deleting the extra branches of groups. When
+	// nil, nothing is deleted.
+	Confirm func(groups []git.DuplicateGroup, extra int) (bool, error)
+}
+
+func init() {
+	duplicatesCmd := newDuplicatesCmd()
+	rootCmd.AddCommand(duplicatesCmd)
+
+	duplicatesCmd.Flags().BoolVarP(&duplicatesRemote, "remote", "r", false, "Check remote branches")
+	duplicatesCmd.Flags().BoolVarP(&duplicatesAll, "all", "a", false, "Check both local and remote branches")
+	duplicatesCmd.Flags().BoolVar(&duplicatesDelete, "delete", false, "Delete all but the canonical branch of each group")
+	duplicatesCmd.Flags().BoolVarP(&duplicatesForce, "force", "f", false, "Delete without confirmation")
+	addCopySummaryFlag(duplicatesCmd)
+	addTimingsFlag(duplicatesCmd)
+}
+
+func newDuplicatesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "duplicates",
+		Short: "Find branches pointing at the same commit",
+		Long: `Group branches whose tips are the same commit, which is common after renames.
+
+The first branch of each group is the canonical one: the default branch, the
+current branch, a protected branch or a branch with an upstream, in that order,
+then the first by name. With --delete, the other branches are deleted in one
+action; no commits are lost since the canonical branch still points at them.
+Protected branches and branches in use are always kept.`,
+		Example: `  git-branch-delete duplicates
+  git-branch-delete duplicates --all
+  git-branch-delete duplicates --delete`,
+		RunE: runDuplicates,
+	}
+}
+
+func runDuplicates(cmd *cobra.Command, args []string) error {
+	timings, err := timingsOrder(cmd)
+	if err != nil {
+		return err
+	}
+
+	gitClient, err := openRepo()
+	if err != nil {
+		log.Error("Failed to initialize git client: %v", err)
+		return err
+	}
+
+	p := newPresenter(os.Stdout)
+	opts := DuplicatesOptions{Remote: duplicatesRemote, All: duplicatesAll}
+	shown := false
+	if duplicatesDelete {
+		// Show the groups before asking, so the user sees what goes
+		opts.Confirm = func(groups []git.DuplicateGroup, extra int) (bool, error) {
+			p.duplicateGroups(groups)
+			shown = true
+			return confirmDuplicates(extra)
+		}
+	}
+
+	created := branchCreations(gitClient)
+	res, err := Duplicates(gitClient, opts)
+	if err != nil {
+		log.Error("Failed to find duplicate branches: %v", err)
+		return err
+	}
+
+	if !shown {
+		p.duplicateGroups(res.Groups)
+	}
+	p.duplicates(res, duplicatesDelete)
+	queueFailures(gitClient, res.Failed, DeleteOptions{Force: true})
+	recordDeletions(gitClient, res.Deleted, created)
+	copySummary(res.Deleted)
+	showTimings(&DeleteResult{Deleted: res.Deleted, Failed: res.Failed}, timings)
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to delete %d branch(es)", len(res.Failed))
+	}
+	return nil
+}
+
+// confirmDuplicates asks before deleting extra branches, unless --force
+func confirmDuplicates(extra int) (bool, error) {
+	if duplicatesForce {
+		return true, nil
+	}
+	ok, err := ui.ConfirmDestructive(prompter, cfg.Confirmation,
+		fmt.Sprintf("Delete %d duplicate branch(es)?", extra), extra)
+	if err == ui.ErrInterrupted {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	return ok, nil
+}
+
+// Duplicates groups branches pointing at the same commit and, when
+// opts.Confirm agrees, deletes all but the branches each group keeps
+func Duplicates(g *git.Git, opts DuplicatesOptions) (*DuplicatesResult, error) {
+	branches, err := g.ListBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	inUse, err := g.ScanBranchReferences()
+	if err != nil {
+		log.Debug("Failed to scan branch references: %v", err)
+	}
+
+	var candidates []git.GitBranch
+	for _, b := range branches {
+		if opts.All || b.IsRemote == opts.Remote {
+			if !b.IsRemote {
+				b.InUse = inUse[b.Name]
+			}
+			candidates = append(candidates, b)
+		}
+	}
+
+	res := &DuplicatesResult{Groups: g.DuplicateBranches(candidates)}
+
+	var extra []git.GitBranch
+	for _, group := range res.Groups {
+		extra = append(extra, group.Extra...)
+	}
+	if len(extra) == 0 || opts.Confirm == nil {
+		return res, nil
+	}
+	ok, err := opts.Confirm(res.Groups, len(extra))
+	if err != nil || !ok {
+		return res, err
+	}
+
+	if err := trashBranches(g, extra); err != nil {
+		return res, err
+	}
+
+	// Every extra branch has a kept twin, so forcing loses no commits
+	var local []BranchResult
+	for _, b := range extra {
+		if !b.IsRemote {
+			local = append(local, newBranchResult(b, nil))
+			continue
+		}
+		started := time.Now()
+		if err := deleteListed(g, b, true); err != nil {
+			res.Failed = append(res.Failed, newBranchResult(b, err).timed(started))
+			continue
+		}
+		res.Deleted = append(res.Deleted, newBranchResult(b, nil).timed(started).forced(true))
+	}
+
+	if len(local) > 1 && g.SupportsRefTransactions() {
+		deleted, failed := deleteLocalAtomic(g, local, true)
+		res.Deleted = append(res.Deleted, deleted...)
+		res.Failed = append(res.Failed, failed...)
+		return res, nil
+	}
+	for _, b := range local {
+		started := time.Now()
+		err := g.DeleteBranch(b.Name, true, false)
+		b = b.timed(started)
+		if err != nil {
+			b.setError(err)
+			res.Failed = append(res.Failed, b)
+			continue
+		}
+		res.Deleted = append(res.Deleted, b.forced(true))
+	}
+	return res, nil
+}
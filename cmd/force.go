@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+)
+
+// maxUniqueCommitsShown bounds the commits listed per branch when asking
+// about an unmerged branch
+const maxUniqueCommitsShown = 10
+
+// forceConfirmation returns the configured force confirmation mode
+func forceConfirmation() string {
+	if cfg == nil || cfg.ForceConfirmation == "" {
+		return config.ForceConfirmNone
+	}
+	return cfg.ForceConfirmation
+}
+
+// unmergedWork describes the work on a branch that the default branch
+// doesn't have, e.g. "3 commits, +120/-4 lines not in main", or returns ""
+// when it can't be computed
+func unmergedWork(g *git.Git, b git.GitBranch) string {
+	base := defaultBranchOrConfig(g)
+	stat, err := g.BranchDiffStat(branchRef(b), base)
+	if err != nil {
+		log.Debug("Failed to compute unmerged work of %s: %v", b.Name, err)
+		return ""
+	}
+	return fmt.Sprintf("%s not in %s", stat, base)
+}
+
+// confirmForced asks about the unmerged branches a forced bulk deletion
+// would delete, as configured by force_confirmation, and returns the
+// branches to go ahead with
+func confirmForced(g *git.Git, branches []git.GitBranch) ([]git.GitBranch, error) {
+	mode := forceConfirmation()
+	if mode == config.ForceConfirmNone {
+		return branches, nil
+	}
+
+	var unmerged []git.GitBranch
+	for _, b := range branches {
+		if !b.IsMerged {
+			unmerged = append(unmerged, b)
+		}
+	}
+	if len(unmerged) == 0 {
+		return branches, nil
+	}
+
+	p := newPresenter(os.Stdout)
+	declined := make(map[string]bool)
+	if mode == config.ForceConfirmOnce {
+		for _, b := range unmerged {
+			p.uniqueCommits(b, unmergedWork(g, b), nil, 0)
+		}
+		ok, err := prompter.Confirm(fmt.Sprintf("Force delete %d unmerged branch(es)?", len(unmerged)), false)
+		if err != nil && err != ui.ErrInterrupted {
+			return nil, fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !ok {
+			for _, b := range unmerged {
+				declined[b.Reference] = true
+			}
+		}
+	} else {
+		all := false
+		for i, b := range unmerged {
+			if all {
+				break
+			}
+			commits, err := g.UniqueCommits(branchRef(b))
+			if err != nil {
+				log.Debug("Failed to list unique commits of %s: %v", b.Name, err)
+			}
+			p.uniqueCommits(b, unmergedWork(g, b), commits, maxUniqueCommitsShown)
+
+			answer, err := prompter.Input(fmt.Sprintf("Delete unmerged branch %s? [y/N/all]", b.Name))
+			if err == ui.ErrInterrupted {
+				for _, rest := range unmerged[i:] {
+					declined[rest.Reference] = true
+				}
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get confirmation: %w", err)
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "y", "yes":
+			case "a", "all":
+				all = true
+			default:
+				declined[b.Reference] = true
+			}
+		}
+	}
+
+	var confirmed []git.GitBranch
+	for _, b := range branches {
+		if declined[b.Reference] {
+			log.Info("Skipping unmerged branch %s: not confirmed", b.Name)
+			continue
+		}
+		confirmed = append(confirmed, b)
+	}
+	return confirmed, nil
+}
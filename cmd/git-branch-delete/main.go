@@ -2,32 +2,18 @@ package main
 
 import (
 	"os"
-	"path/filepath"
 
+	"github.com/bral/git-branch-delete-go/internal/config"
 	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/ui"
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
 
 	"github.com/fatih/color"
 )
 
-func findGitRoot() bool {
-	dir, err := os.Getwd()
-	if err != nil {
-		return false
-	}
-
-	for dir != "/" {
-		if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
-			return true
-		}
-		dir = filepath.Dir(dir)
-	}
-	return false
-}
-
 func main() {
-	if !findGitRoot() {
-		color.Blue("Not a Git repository. Please navigate to a directory with a .git folder.")
+	if _, _, err := pkggit.DiscoverRepo("."); err != nil {
+		color.Blue("Not a Git repository. Please navigate to a directory inside one.")
 		os.Exit(1)
 	}
 
@@ -48,8 +34,16 @@ func main() {
 		os.Exit(0)
 	}
 
+	conf, err := config.Load()
+	if err != nil {
+		color.Yellow("Ignoring config: %v", err)
+		conf = config.DefaultConfig()
+	}
+
+	prompter := ui.NewPrompter(os.Stdin, os.Stdout)
+
 	// Select branches to delete
-	selectedBranches, err := ui.SelectBranches(branches)
+	selectedBranches, err := ui.SelectBranches(prompter, branches)
 	if err != nil {
 		color.Red("Error selecting branches: %v", err)
 		os.Exit(1)
@@ -61,7 +55,7 @@ func main() {
 	}
 
 	// Confirm deletion
-	confirmed, err := ui.ConfirmDeletion(selectedBranches)
+	confirmed, err := ui.ConfirmDeletion(prompter, selectedBranches, conf.Confirmation)
 	if err != nil {
 		color.Red("Error during confirmation: %v", err)
 		os.Exit(1)
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/spf13/cobra"
+)
+
+// recentUseWindow is how long after its last recorded use a branch counts
+// as recently used and is deprioritized for deletion
+const recentUseWindow = 14 * 24 * time.Hour
+
+func init() {
+	rootCmd.AddCommand(newHooksCmd())
+}
+
+func newHooksCmd() *cobra.Command {
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Manage the git hooks that record branch usage",
+		Long: `Manage post-checkout and post-merge hooks that record when each branch is
+checked out or merged into, in .git/` + git.UsageLog + `.
+These "last used" dates are a better signal than commit dates: recently used
+branches are marked and listed last when choosing branches to delete. Once
+the log passes 256 KiB, it is cut down to the latest record of each branch.
+
+Existing POSIX shell hooks (sh, bash, dash, zsh, ...) are kept; the recording is added to them as a marked
+block that uninstall removes again.`,
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the usage hooks in the repository",
+		Args:  cobra.NoArgs,
+		RunE:  runHooksInstall,
+	}
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the usage hooks from the repository",
+		Args:  cobra.NoArgs,
+		RunE:  runHooksUninstall,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the usage hooks are installed",
+		Args:  cobra.NoArgs,
+		RunE:  runHooksStatus,
+	}
+
+	hooksCmd.AddCommand(installCmd, uninstallCmd, statusCmd)
+	return hooksCmd
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	if err := g.InstallUsageHooks(); err != nil {
+		return err
+	}
+	log.Info("Installed the branch usage hooks; checkouts and merges are recorded from now on")
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	if err := g.UninstallUsageHooks(); err != nil {
+		return err
+	}
+	log.Info("Removed the branch usage hooks")
+	return nil
+}
+
+func runHooksStatus(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	res, err := HooksStatus(g)
+	if err != nil {
+		return err
+	}
+	newPresenter(os.Stdout).hooksStatus(res)
+	return nil
+}
+
+// HooksStatus reports whether each usage hook is installed
+func HooksStatus(g *git.Git) (*HooksStatusResult, error) {
+	installed, err := g.UsageHooksInstalled()
+	if err != nil {
+		return nil, err
+	}
+	return &HooksStatusResult{Installed: installed}, nil
+}
+
+// withUsage sets the last used dates of local branches, when recorded
+func withUsage(g *git.Git, branches []git.GitBranch) {
+	if err := g.WithUsage(branches); err != nil {
+		log.Debug("Failed to read branch usage: %v", err)
+	}
+}
+
+// recentlyUsed reports whether the branch was used within recentUseWindow
+func recentlyUsed(b git.GitBranch) bool {
+	return !b.LastUsed.IsZero() && time.Since(b.LastUsed) < recentUseWindow
+}
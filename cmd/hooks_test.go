@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooksStatus(t *testing.T) {
+	_, g := newTestRepo(t)
+
+	res, err := HooksStatus(g)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"post-checkout": false, "post-merge": false}, res.Installed)
+
+	require.NoError(t, g.InstallUsageHooks())
+	res, err = HooksStatus(g)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"post-checkout": true, "post-merge": true}, res.Installed)
+}
+
+func TestPresenterHooksStatus(t *testing.T) {
+	var out bytes.Buffer
+	newPresenter(&out).hooksStatus(&HooksStatusResult{Installed: map[string]bool{"post-checkout": true}})
+	assert.Equal(t, "post-checkout: installed\npost-merge: not installed\n", out.String())
+}
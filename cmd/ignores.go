@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var ignoresClear bool
+
+// learnedIgnores are the branches the interactive selector learned to hide
+// because the user kept leaving them unselected
+type learnedIgnores struct {
+	// Declines counts the runs each deletable branch was left unselected
+	Declines map[string]int `json:"declines"`
+
+	// Ignored are the hidden branches, with when they were learned
+	Ignored map[string]time.Time `json:"ignored"`
+}
+
+func init() {
+	rootCmd.AddCommand(newIgnoresCmd())
+}
+
+func newIgnoresCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ignores [branch...]",
+		Short: "Review or clear the branches the selector learned to hide",
+		Long: `Review or clear the branches the interactive selector learned to hide.
+
+With learnIgnoresAfter set in the config, a merged or stale branch left
+unselected in that many interactive runs is hidden from the selector from
+then on, since you clearly want to keep it. Nothing else is affected: prune
+and delete still see the branch. Clearing a branch shows it again and
+restarts its count; without branch names, everything learned is cleared.`,
+		Example: `  git-branch-delete ignores
+  git-branch-delete ignores --clear spike/parser
+  git-branch-delete ignores --clear`,
+		RunE: runIgnores,
+	}
+
+	cmd.Flags().BoolVar(&ignoresClear, "clear", false, "Show the given branches, or all learned ones, in the selector again")
+
+	return cmd
+}
+
+func runIgnores(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 && !ignoresClear {
+		return fmt.Errorf("branch names are only accepted with --clear")
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+	ignores, err := loadLearnedIgnores(g)
+	if err != nil {
+		return err
+	}
+
+	if ignoresClear {
+		if len(args) == 0 {
+			ignores = nil
+		}
+		for _, name := range args {
+			if _, ok := ignores.Ignored[name]; !ok {
+				log.Warn("%s is not a learned ignore", name)
+			}
+			delete(ignores.Ignored, name)
+			delete(ignores.Declines, name)
+		}
+		if err := saveLearnedIgnores(g, ignores); err != nil {
+			return err
+		}
+		log.Info("Learned ignores cleared")
+		return nil
+	}
+
+	if len(ignores.Ignored) == 0 {
+		log.Info("No learned ignores")
+		return nil
+	}
+	names := make([]string, 0, len(ignores.Ignored))
+	for name := range ignores.Ignored {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		log.Info("%s (learned %s)", name, ignores.Ignored[name].Format("2006-01-02"))
+	}
+	return nil
+}
+
+// hideLearnedIgnores removes the local branches learned as ignores from
+// branches, returning the rest and the number hidden
+func hideLearnedIgnores(ignores *learnedIgnores, branches []git.GitBranch) ([]git.GitBranch, int) {
+	if len(ignores.Ignored) == 0 {
+		return branches, 0
+	}
+	kept := branches[:0:0]
+	for _, b := range branches {
+		if _, ok := ignores.Ignored[b.Name]; ok && !b.IsRemote {
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept, len(branches) - len(kept)
+}
+
+// learnDeclines counts a decline for each deletable local branch offered
+// but not selected, and hides the branches declined after times runs. The
+// count of selected branches starts over. It returns the newly hidden
+// branches.
+func learnDeclines(ignores *learnedIgnores, offered, selected []git.GitBranch, times int, now time.Time) []string {
+	if ignores.Declines == nil {
+		ignores.Declines = make(map[string]int)
+	}
+	if ignores.Ignored == nil {
+		ignores.Ignored = make(map[string]time.Time)
+	}
+
+	chosen := make(map[string]bool, len(selected))
+	for _, b := range selected {
+		if !b.IsRemote {
+			chosen[b.Name] = true
+		}
+	}
+
+	var learned []string
+	for _, b := range offered {
+		offeredInSelector := !b.IsCurrent && !b.IsDefault && b.InUse == ""
+		if b.IsRemote || !offeredInSelector || !(b.IsMerged || b.IsStale) {
+			continue
+		}
+		if chosen[b.Name] {
+			delete(ignores.Declines, b.Name)
+			continue
+		}
+		ignores.Declines[b.Name]++
+		if ignores.Declines[b.Name] >= times {
+			delete(ignores.Declines, b.Name)
+			ignores.Ignored[b.Name] = now
+			learned = append(learned, b.Name)
+		}
+	}
+	slices.Sort(learned)
+	return learned
+}
+
+// recordDeclines learns from an interactive selection, when enabled with
+// learnIgnoresAfter, and tells the user about branches hidden from now on.
+// It is only called once the selection is final: confirmed, or empty.
+func recordDeclines(g *git.Git, offered, selected []git.GitBranch) {
+	if cfg.LearnIgnoresAfter <= 0 {
+		return
+	}
+	ignores, err := loadLearnedIgnores(g)
+	if err != nil {
+		log.Debug("Discarding unreadable learned ignores: %v", err)
+		ignores = &learnedIgnores{}
+	}
+
+	learned := learnDeclines(ignores, offered, selected, cfg.LearnIgnoresAfter, time.Now())
+	if err := saveLearnedIgnores(g, ignores); err != nil {
+		log.Warn("Failed to save learned ignores: %v", err)
+		return
+	}
+	for _, name := range learned {
+		log.Info("Hiding %s from the selector after you kept it %d times; 'git-branch-delete ignores --clear %s' shows it again", name, cfg.LearnIgnoresAfter, name)
+	}
+}
+
+// learnedIgnoresPath returns the location of the learned ignores for the
+// repository
+func learnedIgnoresPath(g *git.Git) (string, error) {
+	dir, err := g.GitPath(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ignores.json"), nil
+}
+
+// loadLearnedIgnores reads the learned ignores, returning an empty set if
+// there are none
+func loadLearnedIgnores(g *git.Git) (*learnedIgnores, error) {
+	path, err := learnedIgnoresPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	ignores := &learnedIgnores{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ignores, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read learned ignores: %w", err)
+	}
+	if err := json.Unmarshal(data, ignores); err != nil {
+		return nil, fmt.Errorf("failed to decode learned ignores: %w", err)
+	}
+	return ignores, nil
+}
+
+// saveLearnedIgnores writes the learned ignores, removing the file once
+// nothing is counted or hidden
+func saveLearnedIgnores(g *git.Git, ignores *learnedIgnores) error {
+	path, err := learnedIgnoresPath(g)
+	if err != nil {
+		return err
+	}
+
+	if ignores == nil || len(ignores.Declines)+len(ignores.Ignored) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove learned ignores: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(ignores, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode learned ignores: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write learned ignores: %w", err)
+	}
+	return nil
+}
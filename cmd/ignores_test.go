@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLearnDeclines(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	offered := []git.GitBranch{
+		{Name: "feature/kept", IsMerged: true},
+		{Name: "feature/old", IsStale: true},
+		{Name: "feature/chosen", IsMerged: true},
+		{Name: "feature/active"},                               // Neither merged nor stale
+		{Name: "feature/kept", IsRemote: true, IsMerged: true}, // Remote branches aren't learned
+		{Name: "main", IsDefault: true, IsMerged: true},
+		{Name: "feature/here", IsCurrent: true, IsMerged: true},
+		{Name: "feature/tree", InUse: "worktree", IsMerged: true},
+	}
+	selected := []git.GitBranch{{Name: "feature/chosen", IsMerged: true}}
+
+	tests := []struct {
+		name     string
+		declines map[string]int
+		times    int
+		learned  []string
+		after    map[string]int
+	}{
+		{
+			name:  "first decline",
+			times: 3,
+			after: map[string]int{"feature/kept": 1, "feature/old": 1},
+		},
+		{
+			name:     "selected branches start over",
+			declines: map[string]int{"feature/chosen": 2, "feature/kept": 1},
+			times:    3,
+			after:    map[string]int{"feature/kept": 2, "feature/old": 1},
+		},
+		{
+			name:     "learned after enough declines",
+			declines: map[string]int{"feature/kept": 2, "feature/old": 2},
+			times:    3,
+			learned:  []string{"feature/kept", "feature/old"},
+			after:    map[string]int{},
+		},
+		{
+			name:    "learned at once",
+			times:   1,
+			learned: []string{"feature/kept", "feature/old"},
+			after:   map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignores := &learnedIgnores{Declines: tt.declines}
+			learned := learnDeclines(ignores, offered, selected, tt.times, now)
+			assert.Equal(t, tt.learned, learned)
+			assert.Equal(t, tt.after, ignores.Declines)
+			for _, name := range tt.learned {
+				assert.Equal(t, now, ignores.Ignored[name])
+			}
+			assert.Len(t, ignores.Ignored, len(tt.learned))
+		})
+	}
+}
+
+func TestHideLearnedIgnores(t *testing.T) {
+	branches := []git.GitBranch{
+		{Name: "feature/a"},
+		{Name: "feature/b"},
+		{Name: "feature/b", IsRemote: true},
+	}
+
+	kept, hidden := hideLearnedIgnores(&learnedIgnores{}, branches)
+	assert.Equal(t, branches, kept)
+	assert.Zero(t, hidden)
+
+	// Only local branches are hidden, and the input is left alone
+	ignores := &learnedIgnores{Ignored: map[string]time.Time{"feature/b": time.Now()}}
+	kept, hidden = hideLearnedIgnores(ignores, branches)
+	assert.Equal(t, []git.GitBranch{{Name: "feature/a"}, {Name: "feature/b", IsRemote: true}}, kept)
+	assert.Equal(t, 1, hidden)
+	assert.Len(t, branches, 3)
+	assert.Equal(t, "feature/b", branches[1].Name)
+}
+
+func TestRecordDeclines(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	_, g := newTestRepo(t)
+	offered := []git.GitBranch{{Name: "feature/kept", IsMerged: true}}
+
+	// Nothing is recorded unless enabled
+	cfg.LearnIgnoresAfter = 0
+	recordDeclines(g, offered, nil)
+	ignores, err := loadLearnedIgnores(g)
+	require.NoError(t, err)
+	assert.Empty(t, ignores.Declines)
+
+	cfg.LearnIgnoresAfter = 2
+	recordDeclines(g, offered, nil)
+	ignores, err = loadLearnedIgnores(g)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"feature/kept": 1}, ignores.Declines)
+	assert.Empty(t, ignores.Ignored)
+
+	recordDeclines(g, offered, nil)
+	ignores, err = loadLearnedIgnores(g)
+	require.NoError(t, err)
+	assert.Empty(t, ignores.Declines)
+	assert.Contains(t, ignores.Ignored, "feature/kept")
+
+	kept, hidden := hideLearnedIgnores(ignores, offered)
+	assert.Empty(t, kept)
+	assert.Equal(t, 1, hidden)
+}
@@ -4,31 +4,35 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
-	"github.com/AlecAivazis/survey/v2"
-	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/bral/git-branch-delete-go/internal/config"
 	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/bral/git-branch-delete-go/internal/utils"
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	interactiveForce bool
-	interactiveAll   bool
+	interactiveForce      bool
+	interactiveAll        bool
+	interactiveWithRemote bool
 )
 
 // Add constants for better maintainability
 const (
-	maxDisplayBranches = 5
-	timePerBranchDelete = 30 * time.Second
+	maxDisplayBranches          = 5
+	timePerBranchDelete         = 30 * time.Second
 	maxBranchesWarningThreshold = 10
-	spinnerUpdateInterval = 100 * time.Millisecond
+	spinnerUpdateInterval       = 100 * time.Millisecond
 )
 
 func init() {
@@ -37,6 +41,9 @@ func init() {
 
 	interactiveCmd.Flags().BoolVarP(&interactiveForce, "force", "f", false, "Force delete branches without merge check")
 	interactiveCmd.Flags().BoolVarP(&interactiveAll, "all", "a", false, "Include remote branches (use with caution)")
+	interactiveCmd.Flags().BoolVar(&interactiveWithRemote, "with-remote", false, "Also delete the remote branch of each deleted local branch without asking")
+	addCopySummaryFlag(interactiveCmd)
+	addTimingsFlag(interactiveCmd)
 }
 
 func newInteractiveCmd() *cobra.Command {
@@ -50,7 +57,10 @@ Use arrow keys to navigate, space to select, and enter to confirm.
 
 Note:
 - Branches marked as [unmerged] require --force to delete
-- Remote branches (marked as [remote]) require --all to be visible
+- Remote branches (marked as [remote]) are shown with --all, or by pressing R
+  in the selector
+- Branches are tagged with the age of their last commit; press A to group them
+  by age
 - Current branch and protected branches (main, master, etc.) cannot be deleted`,
 		Example: `  git-branch-delete interactive        # Delete local branches
   git-branch-delete i --force         # Force delete unmerged branches
@@ -64,6 +74,10 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		return fmt.Errorf("unexpected arguments: %v", args)
 	}
+	timings, err := timingsOrder(cmd)
+	if err != nil {
+		return err
+	}
 
 	// Show loading spinner
 	s := spinner.New(spinner.CharSets[14], spinnerUpdateInterval)
@@ -71,118 +85,71 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	s.Start()
 	defer s.Stop() // Ensure spinner stops even on error
 
-	// Get working directory
-	wd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
-	}
-
 	// Initialize git with cleanup
-	g, err := git.New(wd)
+	g, err := openRepo()
 	if err != nil {
-		return fmt.Errorf("failed to initialize git in %s: %w", wd, err)
+		return err
 	}
 
-	// List branches with proper error context
-	branches, err := g.ListBranches()
+	refreshDefaultBranch(g)
+
+	// List branches with proper error context. Remote branches are only
+	// loaded upfront with --all; otherwise the R key loads them on demand.
+	list := g.ListLocalBranches
+	if interactiveAll {
+		list = g.ListBranches
+	}
+	branches, err := list()
 	if err != nil {
 		return fmt.Errorf("failed to list branches: %w", err)
 	}
+	withUsage(g, branches)
+	withMessages(g, branches)
+	ages := branchAgeTags(g, time.Now())
+
+	// Hide the branches the user kept leaving unselected
+	hidden := 0
+	if cfg.LearnIgnoresAfter > 0 {
+		if ignores, err := loadLearnedIgnores(g); err != nil {
+			log.Debug("Not hiding learned ignores: %v", err)
+		} else {
+			branches, hidden = hideLearnedIgnores(ignores, branches)
+		}
+	}
 
 	s.Stop()
 
-	// Pre-allocate slices with expected capacity
-	choices := make([]string, 0, len(branches))
 	branchMap := make(map[string]git.GitBranch, len(branches))
 
 	// First find and display current branch
 	var currentBranch string
 	for _, b := range branches {
-		if b.IsCurrent {
-			var indicators []string
-			if b.IsStale {
-				indicators = append(indicators, color.RedString("stale"))
-			}
-			if !b.IsMerged {
-				indicators = append(indicators, color.YellowString("unmerged"))
-			}
-			if b.IsMerged {
-				indicators = append(indicators, color.GreenString("merged"))
-			}
-
+		if b.IsCurrent && !b.IsRemote {
 			currentBranch = fmt.Sprintf("%s %s%s",
 				color.CyanString("*"),
 				color.HiWhiteString(b.Name),
-				func() string {
-					if len(indicators) > 0 {
-						return " (" + strings.Join(indicators, ", ") + ")"
-					}
-					return ""
-				}(),
+				formatIndicators(b),
 			)
 			break
 		}
 	}
 
 	// Then process other branches
+	var inUse []git.GitBranch
 	for _, b := range branches {
-		// Skip current and protected branches
-		if b.IsCurrent || b.IsDefault {
-			continue
-		}
-
-		// Create rich label with status indicators
-		var indicators []string
-
-		if b.IsStale {
-			indicators = append(indicators, color.RedString("stale"))
+		if b.InUse != "" && !b.IsCurrent && !b.IsDefault {
+			inUse = append(inUse, b)
 		}
-		if !b.IsMerged {
-			indicators = append(indicators, color.YellowString("unmerged"))
-		}
-		if b.IsMerged {
-			indicators = append(indicators, color.GreenString("merged"))
-		}
-
-		// Format branch display
-		var label string
-		if b.IsRemote {
-			if !interactiveAll {
-				continue
-			}
-			label = color.BlueString("[remote] ")
-		} else {
-			label = color.GreenString("[local]  ")
-		}
-
-		label += b.Name
-		if len(indicators) > 0 {
-			label += " (" + strings.Join(indicators, ", ") + ")"
-		}
-		if b.CommitHash != "" {
-			shortHash := b.CommitHash
-			if len(shortHash) > 7 {
-				shortHash = shortHash[:7]
-			}
-			label += color.HiBlackString(" " + shortHash)
-		}
-
-		choices = append(choices, label)
-		branchMap[label] = b
 	}
+	showRemote := interactiveAll
+	byAge := false
+	choices := branchChoices(branches, showRemote, byAge, ages, branchMap)
 
-	if len(choices) == 0 {
-		if interactiveAll {
-			log.Info("No branches available for deletion")
-		} else {
-			log.Info("No local branches available for deletion (use --all to include remote branches)")
-		}
+	if len(choices) == 0 && interactiveAll {
+		log.Info("No branches available for deletion")
 		return nil
 	}
 
-	// Sort choices for better UX
-	sortBranchChoices(choices)
-
 	// Show branch type counts and current branch
 	totalLocalCount := 0
 	totalRemoteCount := 0
@@ -197,68 +164,178 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  %s\n", currentBranch)
 	fmt.Printf("\n")
 	fmt.Printf("%s\n", color.HiBlackString("─── Available Branches ────────────────────"))
-	fmt.Printf("Found %d local and %d remote branches\n", totalLocalCount, totalRemoteCount)
+	if interactiveAll {
+		fmt.Printf("Found %d local and %d remote branches\n", totalLocalCount, totalRemoteCount)
+	} else {
+		fmt.Printf("Found %d local branches (press R to show remote branches)\n", totalLocalCount)
+	}
+	for _, b := range inUse {
+		fmt.Printf("  %s %s excluded: %s\n", color.YellowString("!"), b.Name, b.InUse)
+	}
+	if hidden > 0 {
+		fmt.Printf("  %s %d kept branch(es) hidden; review them with 'git-branch-delete ignores'\n", color.HiBlackString("~"), hidden)
+	}
 	fmt.Printf("\n")
 
-	// Configure survey templates
-	survey.SelectQuestionTemplate = `
-{{- color "default+hb"}}{{ .Message }}{{color "reset"}}
-{{- if .Help }} {{color "cyan"}}[{{ .Help }}]{{color "reset"}}{{end}}
-{{- color "reset"}}
-`
-
-	survey.MultiSelectQuestionTemplate = `
-{{- color "default+hb"}}{{ .Message }}{{color "reset"}}
-{{- if .Help }} {{color "cyan"}}[{{ .Help }}]{{color "reset"}}{{end}}
-{{- "\n"}}
-{{- range $ix, $option := .PageEntries}}
-  {{- if eq $ix $.SelectedIndex }}{{color "cyan"}}❯{{color "reset"}}{{else}} {{end}}
-  {{- if index $.Checked $option.Index }}{{color "green"}}✓{{color "reset"}}{{else}}{{color "default"}}○{{color "reset"}}{{end}}
-  {{- " "}}{{ $option.Value }}
-{{- "\n"}}
-{{- end}}`
-
-	var selected []string
-	prompt := &survey.MultiSelect{
-		Message: "Select branches to delete:",
-		Options: choices,
-		Description: func(value string, index int) string {
-			// Skip descriptions for headers
-			if strings.HasPrefix(value, "──") {
-				return ""
+	// toggleRemote shows or hides remote branches, listing them the first
+	// time they are shown
+	remoteLoaded := interactiveAll
+	toggleRemote := func() ([]string, error) {
+		if !remoteLoaded {
+			remote, err := g.ListRemoteBranches()
+			if err != nil {
+				return nil, fmt.Errorf("failed to list remote branches: %w", err)
 			}
-			branch := branchMap[value]
-			if branch.Message != "" {
-				return color.HiBlackString(branch.Message)
+			withMessages(g, remote)
+			branches = append(branches, remote...)
+			remoteLoaded = true
+		}
+		showRemote = !showRemote
+		return branchChoices(branches, showRemote, byAge, ages, branchMap), nil
+	}
+
+	// toggleAge switches between grouping branches by age bucket and the
+	// usual status order
+	toggleAge := func() ([]string, error) {
+		byAge = !byAge
+		return branchChoices(branches, showRemote, byAge, ages, branchMap), nil
+	}
+
+	reasons := newDeleteReasons(g)
+
+	// deleteNow deletes the highlighted branch without leaving the selector,
+	// checking, backing it up and recording it like a regular deletion.
+	// The question carries the risk warnings, which are looked up once.
+	var deletedNow []BranchResult
+	warnings := make(map[string][]string)
+	deleteNow := ui.Edit{
+		Prompt: func(label string) string {
+			b := branchMap[label]
+			if _, ok := warnings[label]; !ok {
+				_, warnings[label] = deletionWarnings(g, b, false)
+			}
+			question := fmt.Sprintf("Delete %s now?", b.Name)
+			if b.IsRemote {
+				question = fmt.Sprintf("Delete origin/%s now?", b.Name)
+			}
+			if len(warnings[label]) == 0 {
+				return question
 			}
-			return ""
+			return strings.Join(warnings[label], ". ") + ". " + question
 		},
-		Help: "↑/↓: navigate • space: select • enter: confirm",
-		PageSize: 15,
-		// The survey package has built-in filtering that can't be fully disabled.
-		// This is a workaround that preserves all options by always returning true,
-		// effectively neutralizing the filtering behavior while maintaining the
-		// selection state. This prevents the issue where typing would cause
-		// selections to disappear.
-		Filter: func(filter string, value string, index int) bool {
-			return true
+		Run: func(label string) ([]string, error) {
+			b := branchMap[label]
+			if err := checkDeleteNow(g, b, len(branches)); err != nil {
+				return nil, err
+			}
+			created := branchCreations(g)
+			if err := trashBranches(g, []git.GitBranch{b}); err != nil {
+				return nil, err
+			}
+			if err := deleteListed(g, b, interactiveForce); err != nil {
+				if !interactiveForce && git.ClassifyError(err) == git.FailureNotMerged {
+					return nil, fmt.Errorf("%s is not fully merged; run with --force to delete it", b.Name)
+				}
+				return nil, err
+			}
+			deleted := []BranchResult{newBranchResult(b, nil).forced(interactiveForce)}
+			reasons.annotate(deleted, []git.GitBranch{b}, chosenReason)
+			recordDeletions(g, deleted, created)
+			deletedNow = append(deletedNow, deleted...)
+			branches = slices.DeleteFunc(branches, func(other git.GitBranch) bool {
+				return other.Name == b.Name && other.IsRemote == b.IsRemote
+			})
+			return branchChoices(branches, showRemote, byAge, ages, branchMap), nil
 		},
 	}
 
-	err = survey.AskOne(prompt, &selected, survey.WithPageSize(15))
+	// Fill in ahead/behind, unique history and pull request states in the
+	// background, starting with the branches listed first
+	enrichCtx, stopEnrich := context.WithCancel(context.Background())
+	ordered := make([]git.GitBranch, len(choices))
+	for i, label := range choices {
+		ordered[i] = branchMap[label]
+	}
+	details := enrichBranches(enrichCtx, g, ordered, defaultBranchOrConfig(g))
+	previews := newMessagePreviews(g)
+
+	selected, err := prompter.MultiSelect("Select branches to delete:", choices, ui.SelectConfig{
+		Help:     "↑/↓: navigate • space: select • R: toggle remote • A: group by age • o: open in browser • d: delete now • enter: confirm",
+		PageSize: 15,
+		Description: func(value string, index int) string {
+			branch := branchMap[value]
+			desc := details.describe(branch)
+			var line string
+			switch {
+			case branch.Message == "":
+				line = desc
+			case desc == "":
+				line = color.HiBlackString(branch.Message)
+			default:
+				line = color.HiBlackString(branch.Message) + "  " + desc
+			}
+
+			if reason := reasons.of(branch); reason != "" {
+				line += "\n" + color.GreenString("  deletable: "+reason)
+			}
+
+			// Preview the whole message, which full already shows
+			if messageDisplay() == config.MessageFull {
+				return line
+			}
+			for _, body := range strings.Split(previews.bodyOf(branch), "\n") {
+				if body != "" {
+					line += "\n" + color.HiBlackString("  "+body)
+				}
+			}
+			return line
+		},
+		Summary: selectionSummary(g, branchMap),
+		Refresh: details.refresh,
+		Keys:    map[rune]func() ([]string, error){'R': toggleRemote, 'A': toggleAge},
+		Actions: map[rune]func(string) error{
+			'o': func(label string) error { return openBranch(g, branchMap[label]) },
+		},
+		Edits: map[rune]ui.Edit{'d': deleteNow},
+	})
+	stopEnrich()
+	details.save()
+	if len(deletedNow) > 0 {
+		names := make([]string, len(deletedNow))
+		for i, b := range deletedNow {
+			names[i] = b.Name
+			if b.Remote {
+				names[i] = "origin/" + b.Name
+			}
+		}
+		log.Info("Deleted while reviewing: %s", strings.Join(names, ", "))
+	}
 	if err != nil {
-		if err == terminal.InterruptErr {
+		if err == ui.ErrInterrupted {
 			log.Info("Operation cancelled by user")
 			return nil
 		}
 		return fmt.Errorf("failed to get branch selection: %w", err)
 	}
+	picked := make([]git.GitBranch, len(selected))
+	for i, label := range selected {
+		picked[i] = branchMap[label]
+	}
 
 	if len(selected) == 0 {
+		// Submitting an empty selection keeps every branch on purpose
+		recordDeclines(g, branches, nil)
 		log.Info("No branches selected for deletion")
 		return nil
 	}
 
+	// Check remote permissions upfront so failures don't surface midway
+	selected = excludeUndeletableRemotes(g, selected, branchMap)
+	if len(selected) == 0 {
+		log.Info("No deletable branches selected")
+		return nil
+	}
+
 	// Show summary before confirmation
 	var unmergedBranches []string
 	var localCount, remoteCount int
@@ -285,32 +362,38 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	// Show selection summary
 	fmt.Printf("\nSelected branches:\n\n")
 	maxDisplay := 5
-	if len(selectedNames) > maxDisplay {
-		// Display first 5 branches
-		for i, name := range selectedNames[:maxDisplay] {
-			branch := selectedBranches[i]
-			indicator := color.GreenString("[local]")
-			if branch.IsRemote {
-				indicator = color.BlueString("[remote]")
-			}
-			fmt.Printf("  %s %s %s%s\n", color.GreenString("✓"), indicator, name, formatCommitHash(branch.CommitHash))
+	shown := selectedNames
+	if len(shown) > maxDisplay {
+		shown = shown[:maxDisplay]
+	}
+	for i, name := range shown {
+		branch := selectedBranches[i]
+		indicator := ui.Indicator(ui.IndicatorLocal)
+		if branch.IsRemote {
+			indicator = ui.Indicator(ui.IndicatorRemote)
 		}
-		fmt.Printf("  ... and %d more\n", len(selectedNames)-maxDisplay)
-	} else {
-		// Display all branches
-		for i, name := range selectedNames {
-			branch := selectedBranches[i]
-			indicator := color.GreenString("[local]")
-			if branch.IsRemote {
-				indicator = color.BlueString("[remote]")
+
+		// Show how much work an unmerged branch would discard
+		work := ""
+		if !branch.IsMerged {
+			if w := unmergedWork(g, branch); w != "" {
+				work = " " + color.YellowString("("+w+")")
 			}
-			fmt.Printf("  %s %s %s%s\n", color.GreenString("✓"), indicator, name, formatCommitHash(branch.CommitHash))
 		}
+		fmt.Printf("  %s %s %s%s%s\n", ui.Check(), indicator, name, formatCommitHash(branch.CommitHash), work)
+	}
+	if len(selectedNames) > maxDisplay {
+		fmt.Printf("  ... and %d more\n", len(selectedNames)-maxDisplay)
 	}
 	fmt.Printf("\nTotal: %s, %s\n",
 		color.GreenString("%d local", localCount),
 		color.BlueString("%d remote", remoteCount))
 
+	// Warn about tags and notes on commits only the selected branches reach
+	for _, b := range selectedBranches {
+		deletionRisk(g, b, false)
+	}
+
 	// Handle unmerged branches
 	if len(unmergedBranches) > 0 && !interactiveForce {
 		log.Info("\n%s Unmerged branches require --force to delete", color.YellowString("!"))
@@ -326,12 +409,8 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	// Safety check: warn about large deletions
 	if len(selectedBranches) > 10 {
 		log.Warn("You are about to delete %d branches. This is a large operation.", len(selectedBranches))
-		var proceed bool
-		proceedPrompt := &survey.Confirm{
-			Message: "Are you sure you want to proceed?",
-			Default: false,
-		}
-		if err := survey.AskOne(proceedPrompt, &proceed); err != nil || !proceed {
+		proceed, err := prompter.Confirm("Are you sure you want to proceed?", false)
+		if err != nil || !proceed {
 			log.Info("Operation cancelled")
 			return nil
 		}
@@ -343,15 +422,9 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 		confirmMsg = fmt.Sprintf("Force delete %d branches (%d local, %d remote)?", len(selected), localCount, remoteCount)
 	}
 
-	var confirm bool
-	confirmPrompt := &survey.Confirm{
-		Message: confirmMsg,
-		Default: false,
-	}
-
-	err = survey.AskOne(confirmPrompt, &confirm)
+	confirm, err := ui.ConfirmDestructive(prompter, cfg.Confirmation, confirmMsg, len(selected))
 	if err != nil {
-		if err == terminal.InterruptErr {
+		if err == ui.ErrInterrupted {
 			log.Info("Operation cancelled by user")
 			return nil
 		}
@@ -362,41 +435,220 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 		log.Info("Operation cancelled")
 		return nil
 	}
+	// A cancelled run says nothing about the branches left out
+	recordDeclines(g, branches, picked)
+
+	// Unmerged branches may need to be confirmed one by one
+	if interactiveForce {
+		if selectedBranches, err = confirmForced(g, selectedBranches); err != nil {
+			return err
+		}
+		if len(selectedBranches) == 0 {
+			log.Info("No branches left to delete")
+			return nil
+		}
+	}
+
+	if err := trashBranches(g, selectedBranches); err != nil {
+		return err
+	}
 
 	// Show progress spinner during deletion
-	successCount := 0
-	failCount := 0
 	spinner := spinner.New(spinner.CharSets[9], 100*time.Millisecond)
 	spinner.Suffix = fmt.Sprintf(" Deleting branches (0/%d)", len(selectedBranches))
 	spinner.Start()
 
+	created := branchCreations(g)
+	res, err := deleteBranches(g, selectedBranches, interactiveForce, func(done int) {
+		spinner.Suffix = fmt.Sprintf(" Deleting branches (%d/%d)", done, len(selectedBranches))
+	})
+	spinner.Stop()
+	if err != nil {
+		log.Error("Operation timed out after 30 seconds")
+		return err
+	}
+	reasons.annotate(res.Deleted, selectedBranches, chosenReason)
+
+	// Offer to delete the remote branches of deleted local ones
+	counterparts := deleteRemoteCounterparts(g, res.Deleted, interactiveForce, interactiveWithRemote || cfg.WithRemote)
+	res.Deleted = append(res.Deleted, counterparts.Deleted...)
+	res.Failed = append(res.Failed, counterparts.Failed...)
+
+	// Show final summary with detailed errors if any
+	newPresenter(os.Stdout).summary(res)
+	queueFailures(g, res.Failed, DeleteOptions{Force: interactiveForce})
+	recordDeletions(g, res.Deleted, created)
+	copySummary(res.Deleted)
+	showTimings(res, timings)
+
+	return nil
+}
+
+// openBranch opens the page of a branch on origin's hosting service
+func openBranch(g *git.Git, b git.GitBranch) error {
+	url, err := g.BranchWebURL(b.Name)
+	if err != nil {
+		return err
+	}
+	return utils.OpenBrowser(url)
+}
+
+// branchChoices returns the sorted selector labels for the deletable
+// branches, including remote ones when showRemote is set and grouped by age
+// bucket when byAge is set, and records the branch behind each label in
+// branchMap
+func branchChoices(branches []git.GitBranch, showRemote, byAge bool, ages map[string]ageTag, branchMap map[string]git.GitBranch) []string {
+	choices := make([]string, 0, len(branches))
+	for _, b := range branches {
+		// Skip current and protected branches, and branches involved in an
+		// ongoing operation
+		if b.IsCurrent || b.IsDefault || b.InUse != "" {
+			continue
+		}
+		if b.IsRemote && !showRemote {
+			continue
+		}
+
+		// Format branch display
+		label := branchSide(b) + " "
+
+		label += b.Name + formatIndicators(b) + formatAge(b, ages)
+		if b.CommitHash != "" {
+			shortHash := b.CommitHash
+			if len(shortHash) > 7 {
+				shortHash = shortHash[:7]
+			}
+			label += color.HiBlackString(" " + shortHash)
+		}
+
+		choices = append(choices, label)
+		branchMap[label] = b
+	}
+
+	// Sort choices for better UX
+	sortBranchChoices(choices, branchMap)
+	if byAge {
+		sortByAge(choices, branchMap, ages)
+	}
+	return choices
+}
+
+// selectionSummary returns a selector summary counting the selected
+// branches by kind and estimating the risk of deleting them. Merged
+// branches carry no risk; the impact of unmerged ones is checked once each.
+func selectionSummary(g *git.Git, branchMap map[string]git.GitBranch) func([]string) string {
+	risks := make(map[string]string)
+	return func(selected []string) string {
+		var local, remote, merged, unmerged int
+		risk := git.RiskNone
+		for _, label := range selected {
+			b := branchMap[label]
+			if b.IsRemote {
+				remote++
+			} else {
+				local++
+			}
+			if b.IsMerged {
+				merged++
+				continue
+			}
+			unmerged++
+
+			r, ok := risks[label]
+			if !ok {
+				r = git.RiskHigh // Assume the worst when the impact is unknown
+				if impact, err := g.DeletionImpact(b.Reference); err == nil {
+					r = impact.Risk()
+				}
+				risks[label] = r
+			}
+			if r == git.RiskHigh || r == git.RiskLow && risk == git.RiskNone {
+				risk = r
+			}
+		}
+
+		riskColor := color.GreenString
+		switch risk {
+		case git.RiskLow:
+			riskColor = color.YellowString
+		case git.RiskHigh:
+			riskColor = color.RedString
+		}
+		return fmt.Sprintf("Selected: %d (%s, %s · %s, %s) · risk: %s",
+			len(selected),
+			color.GreenString("%d local", local),
+			color.BlueString("%d remote", remote),
+			color.GreenString("%d merged", merged),
+			color.YellowString("%d unmerged", unmerged),
+			riskColor(risk))
+	}
+}
+
+// branchSide returns the [local] or [remote] marker of a selector label,
+// padded so branch names line up
+func branchSide(b git.GitBranch) string {
+	local, remote := ui.IndicatorLabel(ui.IndicatorLocal), ui.IndicatorLabel(ui.IndicatorRemote)
+	width := max(utf8.RuneCountInString(local), utf8.RuneCountInString(remote))
+	if b.IsRemote {
+		return ui.Indicator(ui.IndicatorRemote) + strings.Repeat(" ", width-utf8.RuneCountInString(remote))
+	}
+	return ui.Indicator(ui.IndicatorLocal) + strings.Repeat(" ", width-utf8.RuneCountInString(local))
+}
+
+// formatIndicators returns the " (stale, merged)" style status suffix of a
+// branch label
+func formatIndicators(b git.GitBranch) string {
+	var indicators []string
+	if b.IsStale {
+		indicators = append(indicators, ui.Indicator(ui.IndicatorStale))
+	}
+	if !b.IsMerged {
+		indicators = append(indicators, ui.Indicator(ui.IndicatorUnmerged))
+	}
+	if b.IsMerged {
+		indicators = append(indicators, ui.Indicator(ui.IndicatorMerged))
+	}
+	if recentlyUsed(b) {
+		indicators = append(indicators, ui.Indicator(ui.IndicatorRecentlyUsed))
+	}
+	if len(indicators) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(indicators, ", ") + ")"
+}
+
+// deleteBranches deletes branches in parallel with a worker pool and reports
+// the outcome of each. progress is called after every completed deletion.
+func deleteBranches(g *git.Git, branches []git.GitBranch, force bool, progress func(done int)) (*DeleteResult, error) {
 	// Use a buffered channel for parallel branch deletion with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	type deleteResult struct {
-		branch string
-		err    error
+		branch  git.GitBranch
+		err     error
+		started time.Time
 	}
-	results := make(chan deleteResult, len(selectedBranches))
+	results := make(chan deleteResult, len(branches))
 
 	// Process branches in parallel with a worker pool
 	const maxWorkers = 4
 	sem := make(chan struct{}, maxWorkers)
 	var wg sync.WaitGroup
 
-	for _, branch := range selectedBranches {
+	for _, branch := range branches {
 		wg.Add(1)
 		go func(b git.GitBranch) {
 			defer wg.Done()
-			defer func() { <-sem }() // Release semaphore
 
 			select {
 			case sem <- struct{}{}: // Acquire semaphore
-				err := g.DeleteBranch(b.Name, interactiveForce, b.IsRemote)
-				results <- deleteResult{branch: b.Name, err: err}
+				defer func() { <-sem }() // Release semaphore
+				started := time.Now()
+				err := deleteListed(g, b, force)
+				results <- deleteResult{branch: b, err: err, started: started}
 			case <-ctx.Done():
-				results <- deleteResult{branch: b.Name, err: ctx.Err()}
+				results <- deleteResult{branch: b, err: ctx.Err(), started: time.Now()}
 			}
 		}(branch)
 	}
@@ -408,54 +660,80 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 	}()
 
 	// Collect results with timeout
-	var errs []string
-loop:
+	res := &DeleteResult{}
 	for {
 		select {
 		case result, ok := <-results:
 			if !ok {
-				break loop
+				return res, nil
 			}
 			if result.err != nil {
-				failCount++
-				errs = append(errs, fmt.Sprintf("%s: %s", result.branch, result.err))
+				res.Failed = append(res.Failed, newBranchResult(result.branch, result.err).timed(result.started))
 			} else {
-				successCount++
+				res.Deleted = append(res.Deleted, newBranchResult(result.branch, nil).timed(result.started).forced(force))
+			}
+			if progress != nil {
+				progress(len(res.Deleted) + len(res.Failed))
 			}
-			spinner.Suffix = fmt.Sprintf(" Deleting branches (%d/%d)", successCount+failCount, len(selectedBranches))
 		case <-ctx.Done():
-			log.Error("Operation timed out after 30 seconds")
-			return ctx.Err()
+			return res, ctx.Err()
 		}
 	}
+}
 
-	spinner.Stop()
+// checkDeleteNow applies the checks of a confirmed selection to deleting
+// one of total listed branches from within the selector
+func checkDeleteNow(g *git.Git, b git.GitBranch, total int) error {
+	// Only a y/N question fits in the selector
+	if mode := cfg.Confirmation.Mode; mode != "" && mode != config.ConfirmYesNo {
+		return fmt.Errorf("the %s confirmation can't be given here; select the branch and press enter instead", mode)
+	}
+	if total-1 <= 1 {
+		return fmt.Errorf("refusing to delete all branches")
+	}
+	// Like excludeUndeletableRemotes, without printing over the selector
+	if b.IsRemote {
+		if err, ok := g.CheckRemoteDeletes([]string{b.Name})[b.Name]; ok {
+			return fmt.Errorf("origin/%s cannot be deleted: %w", b.Name, err)
+		}
+	}
+	return nil
+}
 
-	// Show final summary with detailed errors if any
-	fmt.Printf("\nDeleted %d branches successfully", successCount)
-	if failCount > 0 {
-		fmt.Printf(", %d failed", failCount)
-		fmt.Println("\nFailed branches:")
-		for _, err := range errs {
-			fmt.Printf("  - %s\n", err)
+// excludeUndeletableRemotes dry-runs the deletion of each selected remote
+// branch and drops those that would fail, reporting why
+func excludeUndeletableRemotes(g *git.Git, selected []string, branchMap map[string]git.GitBranch) []string {
+	var names []string
+	for _, label := range selected {
+		if b := branchMap[label]; b.IsRemote {
+			names = append(names, b.Name)
 		}
 	}
-	fmt.Println()
+	if len(names) == 0 {
+		return selected
+	}
 
-	// Calculate and show time saved
-	if successCount > 0 {
-		timeSaved := time.Duration(successCount) * timePerBranchDelete
-		minutes := int(timeSaved.Minutes())
-		seconds := int(timeSaved.Seconds()) % 60
+	s := spinner.New(spinner.CharSets[14], spinnerUpdateInterval)
+	s.Suffix = fmt.Sprintf(" Checking permissions for %d remote branches", len(names))
+	s.Start()
+	failures := g.CheckRemoteDeletes(names)
+	s.Stop()
 
-		if minutes > 0 {
-			fmt.Printf("Saved you ~%d minutes and %d seconds of manual work! 🚀\n", minutes, seconds)
-		} else {
-			fmt.Printf("Saved you ~%d seconds of manual work! 🚀\n", seconds)
-		}
+	if len(failures) == 0 {
+		return selected
 	}
 
-	return nil
+	fmt.Printf("\n%s\n", color.YellowString("The following remote branches cannot be deleted and were deselected:"))
+	kept := make([]string, 0, len(selected))
+	for _, label := range selected {
+		b := branchMap[label]
+		if err, ok := failures[b.Name]; ok && b.IsRemote {
+			fmt.Printf("  %s %s: %v\n", ui.Cross(), b.Name, err)
+			continue
+		}
+		kept = append(kept, label)
+	}
+	return kept
 }
 
 // sortBranchChoices sorts branch choices for better UX:
@@ -463,53 +741,37 @@ loop:
 // - Then unmerged branches
 // - Then merged branches
 // - Remote branches last in each category
-func sortBranchChoices(choices []string) {
-	type branchScore struct {
-		index int
-		score int
-		value string
-	}
-
-	scores := make([]branchScore, len(choices))
-	for i, choice := range choices {
+func sortBranchChoices(choices []string, branchMap map[string]git.GitBranch) {
+	score := func(label string) int {
+		b := branchMap[label]
 		score := 0
 
 		// Priority scoring (higher is more important)
 		switch {
-		case strings.Contains(choice, color.RedString("stale")):
+		case b.IsStale:
 			score += 8000
-		case strings.Contains(choice, color.YellowString("unmerged")):
+		case !b.IsMerged:
 			score += 4000
-		case strings.Contains(choice, color.GreenString("merged")):
+		default:
 			score += 2000
 		}
 
 		// Deprioritize remote branches within their categories
-		if strings.Contains(choice, color.BlueString("[remote]")) {
+		if b.IsRemote {
 			score -= 1000
 		}
 
-		// Use original index as tiebreaker for stable sort
-		score = score*10000 + (10000 - i)
-
-		scores[i] = branchScore{
-			index: i,
-			score: score,
-			value: choice,
+		// Branches in recent use are the least likely to be done with
+		if recentlyUsed(b) {
+			score -= 500
 		}
+		return score
 	}
 
-	// Sort by score descending
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].score > scores[j].score
+	// Stable, so the original order breaks ties
+	sort.SliceStable(choices, func(i, j int) bool {
+		return score(choices[i]) > score(choices[j])
 	})
-
-	// Update choices array in place
-	sorted := make([]string, len(choices))
-	for i, s := range scores {
-		sorted[i] = s.value
-	}
-	copy(choices, sorted)
 }
 
 // Add helper function at the end of the file
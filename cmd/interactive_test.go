@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDeleteNow(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/x")
+	r.Local("feature/local")
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = &config.Config{Confirmation: config.Confirmation{Mode: config.ConfirmYesNo}}
+
+	local := git.GitBranch{Name: "feature/x"}
+	assert.NoError(t, checkDeleteNow(g, local, 5))
+	assert.NoError(t, checkDeleteNow(g, git.GitBranch{Name: "feature/x", IsRemote: true}, 5))
+
+	// The last branches are never deleted
+	assert.ErrorContains(t, checkDeleteNow(g, local, 2), "all branches")
+
+	// origin doesn't have it
+	assert.ErrorContains(t, checkDeleteNow(g, git.GitBranch{Name: "feature/local", IsRemote: true}, 5), "cannot be deleted")
+
+	// Typed confirmations need the regular flow
+	cfg = &config.Config{Confirmation: config.Confirmation{Mode: config.ConfirmPhrase, Phrase: "delete"}}
+	assert.ErrorContains(t, checkDeleteNow(g, local, 5), "phrase confirmation")
+}
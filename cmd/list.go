@@ -1,28 +1,94 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"path"
+	"slices"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
-	"github.com/fatih/color"
+	"github.com/bral/git-branch-delete-go/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	showRemote bool
 	showAll    bool
+	showWeight bool
+	showTrack  string
+	showTouch  string
+
+	showMissingLocal bool
+
+	listOutput  string
+	listColumns []string
+
+	listAbsoluteDates bool
+	listPRStates      []string
+	listTicketStates  []string
+	listNul           bool
+
+	listLimit   int
+	listPage    int
+	listNoPager bool
+
+	listByTarget bool
 )
 
+// trackingFilters are the values accepted by list --tracking
+var trackingFilters = []string{"all", "tracked", "untracked", "gone", "ahead", "behind", "diverged", "synced"}
+
+// ListOptions controls which branches List returns
+type ListOptions struct {
+	Remote bool // Only remote branches
+	All    bool // Both local and remote branches
+	Weight bool // Compute the history unique to each branch
+	// Base is the branch weights are measured against
+	Base string
+	// Touches keeps only branches that modify a path, when set
+	Touches *TouchFilter
+	// PRStates keeps only branches whose pull request is in given states,
+	// when set
+	PRStates *PRFilter
+	// Tickets keeps only branches whose tickets are in given states, when
+	// set
+	Tickets *TicketFilter
+	// MissingLocal keeps only remote branches without a local branch of
+	// the same name or tracking them
+	MissingLocal bool
+	// ByTarget groups the branches by the merge targets they are merged
+	// into
+	ByTarget bool
+	// Limit keeps at most this many of the matching branches, the Page'th
+	// (from 1) run of them; 0 keeps all
+	Limit int
+	Page  int
+}
+
 func init() {
 	listCmd := newListCmd()
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().BoolVarP(&showRemote, "remote", "r", false, "Show remote branches")
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "Show both local and remote branches")
+	listCmd.Flags().BoolVarP(&showWeight, "weight", "w", false, "Show commits and object size unique to each branch")
+	listCmd.Flags().StringVar(&showTrack, "tracking", "", "Show upstream tracking status, optionally filtered ("+strings.Join(trackingFilters, "|")+")")
+	listCmd.Flags().Lookup("tracking").NoOptDefVal = "all"
+	listCmd.Flags().BoolVar(&showMissingLocal, "remote-only-missing-local", false, "Only show remote branches without a local branch")
+	listCmd.Flags().StringVar(&showTouch, "touches", "", "Only show branches whose unique commits modify this path")
+	listCmd.Flags().StringSliceVar(&listPRStates, "pr-state", nil, "Only show branches whose GitHub pull request is in these states (draft|ready|open|merged|closed|none, ! to exclude)")
+	listCmd.Flags().StringSliceVar(&listTicketStates, "ticket-state", nil, "Only show branches whose trailer-named tickets are in these states ("+strings.Join(ticketStates, "|")+")")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format ("+strings.Join(listOutputs, "|")+")")
+	listCmd.Flags().BoolVar(&listAbsoluteDates, "absolute-dates", false, "Show commit dates in the locale's date format instead of relative times")
+	listCmd.Flags().BoolVarP(&listNul, "null", "z", false, "Print only branch names, each terminated by NUL, for xargs -0")
+	listCmd.Flags().StringSliceVar(&listColumns, "columns", nil, "Columns of the csv output (default "+strings.Join(defaultCSVColumns, ",")+")")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "Show at most N branches")
+	listCmd.Flags().IntVar(&listPage, "page", 1, "With --limit, show the Nth page of branches")
+	listCmd.Flags().BoolVar(&listByTarget, "by-target", false, "Group branches by the merge targets they are merged into")
+	listCmd.Flags().BoolVar(&listNoPager, "no-pager", false, "Don't pipe the output to a pager (GIT_PAGER or PAGER, default less)")
 }
 
 func newListCmd() *cobra.Command {
@@ -30,10 +96,33 @@ func newListCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List git branches",
 		Long: `List git branches with their current status.
-Shows local branches by default.`,
+Shows local branches by default.
+
+On a terminal, the output goes through a pager, picked like git does: GIT_PAGER,
+then PAGER, then less. Set either to "cat" or use --no-pager to turn it off.
+--limit and --page show a slice of the branches instead of all of them.
+
+With several merge targets configured (merged_targets), --by-target groups the
+branches by the targets they are merged into, e.g. main, develop, or none, to
+see where work has landed before deleting anything.`,
 		Example: `  git-branch-delete list
   git-branch-delete list --remote
-  git-branch-delete list --all`,
+  git-branch-delete list --all
+  git-branch-delete list --remote-only-missing-local
+  git-branch-delete list --weight
+  git-branch-delete list --tracking
+  git-branch-delete list --tracking=gone
+  git-branch-delete list --absolute-dates
+  git-branch-delete list --all --pr-state draft
+  git-branch-delete list --ticket-state done
+  git-branch-delete list --all --output csv > branches.csv
+  git-branch-delete list --output csv --columns name,date,ahead,behind
+  git-branch-delete list --all --limit 50 --page 2
+  git-branch-delete list --all --by-target
+
+  # Branch names can contain any character git allows; -z keeps them intact
+  git-branch-delete list -z | xargs -0 git-branch-delete delete
+  git-branch-delete list -r -z --pr-state merged | xargs -0 git-branch-delete delete --remote`,
 		RunE: runList,
 	}
 }
@@ -41,84 +130,334 @@ Shows local branches by default.`,
 func runList(cmd *cobra.Command, args []string) error {
 	log.Debug("Starting branch listing")
 
-	// Get current directory
-	dir, err := os.Getwd()
-	if err != nil {
-		log.Error("Failed to get current directory", "error", err)
+	if err := validateListOutput(listOutput, listColumns); err != nil {
+		return err
+	}
+	if listOutput == "csv" && showTrack != "" {
+		return fmt.Errorf("--output csv can't be combined with --tracking; use the upstream, ahead and behind columns")
+	}
+	if showMissingLocal && (showAll || showTrack != "") {
+		return fmt.Errorf("--remote-only-missing-local can't be combined with --all or --tracking")
+	}
+	if listNul && (showAll || showTrack != "" || listOutput != "table") {
+		return fmt.Errorf("-z can't be combined with --all, --tracking or --output; list local or --remote branches")
+	}
+	if listNul {
+		// Names go to stdout, so nothing else may; a warning there would
+		// become a branch name for xargs
+		log.SetConsole(os.Stderr)
+	}
+	if err := checkPageFlags(listLimit, listPage, cmd.Flags().Changed("page")); err != nil {
 		return err
 	}
+	if listByTarget {
+		if showTrack != "" || listNul || listOutput != "table" {
+			return fmt.Errorf("--by-target can't be combined with --tracking, -z or --output")
+		}
+		if len(cfg.MergedTargets) == 0 {
+			return fmt.Errorf("--by-target needs merge targets; set merged_targets in the config")
+		}
+	}
 
 	// Initialize git client
-	gitClient, err := git.New(dir)
+	gitClient, err := openRepo()
 	if err != nil {
-		log.Error("Failed to initialize git client", "error", err)
+		log.Error("Failed to initialize git client: %v", err)
 		return err
 	}
 
-	// Get branches
-	branches, err := gitClient.ListBranches()
+	if showTrack != "" {
+		res, err := Tracking(gitClient, showTrack)
+		if err != nil {
+			log.Error("Failed to list tracking branches: %v", err)
+			return err
+		}
+		total := len(res.Branches)
+		if res.Branches, err = paginate(res.Branches, listLimit, listPage); err != nil {
+			return err
+		}
+		p, done := newListPresenter()
+		defer done()
+		if err := p.tracking(res); err != nil {
+			return err
+		}
+		p.pageNote(len(res.Branches), total, listLimit, listPage)
+		return nil
+	}
+
+	opts := ListOptions{
+		Remote: showRemote,
+		All:    showAll,
+		Weight: showWeight,
+		Base:   defaultBranchOrConfig(gitClient),
+
+		MissingLocal: showMissingLocal,
+		ByTarget:     listByTarget,
+		Limit:        listLimit,
+		Page:         listPage,
+	}
+	if showTouch != "" {
+		if opts.Touches, err = newTouchFilter(gitClient, showTouch); err != nil {
+			return err
+		}
+	}
+	if len(listPRStates) > 0 {
+		if opts.PRStates, err = newPRFilter(gitClient, listPRStates); err != nil {
+			return err
+		}
+	}
+
+	if len(listTicketStates) > 0 {
+		if opts.Tickets, err = newTicketFilter(listTicketStates); err != nil {
+			return err
+		}
+	}
+
+	res, err := List(gitClient, opts)
 	if err != nil {
-		log.Error("Failed to list branches", "error", err)
+		log.Error("Failed to list branches: %v", err)
 		return err
 	}
 
-	log.Debug("Retrieved branches", "count", len(branches))
+	if listNul {
+		return newPresenter(os.Stdout).names(res)
+	}
+
+	p, done := newListPresenter()
+	defer done()
 
-	// Filter branches based on flags
-	var filteredBranches []git.GitBranch
-	for _, branch := range branches {
-		if showAll ||
-		   (showRemote && branch.IsRemote) ||
-		   (!showRemote && !branch.IsRemote) {
-			filteredBranches = append(filteredBranches, branch)
+	if listOutput == "csv" {
+		rows, err := branchRows(gitClient, res.Branches)
+		if err != nil {
+			log.Error("Failed to collect branch metadata: %v", err)
+			return err
 		}
+		columns := listColumns
+		if len(columns) == 0 {
+			columns = defaultCSVColumns
+		}
+		if err := p.csv(rows, columns); err != nil {
+			return err
+		}
+		p.pageNote(len(res.Branches), res.Total, opts.Limit, opts.Page)
+		return nil
+	}
+
+	p.absoluteDates = listAbsoluteDates
+	render := p.list
+	if opts.ByTarget {
+		render = p.listByTarget
+	}
+	if err := render(res); err != nil {
+		log.Error("Failed to flush output: %v", err)
+		return err
+	}
+	p.pageNote(len(res.Branches), res.Total, opts.Limit, opts.Page)
+	if opts.MissingLocal {
+		p.missingLocal(res)
 	}
 
-	log.Debug("Filtered branches", "count", len(filteredBranches))
+	log.Debug("Successfully listed branches")
+	return nil
+}
 
-	if len(filteredBranches) == 0 {
-		log.Info("No branches found matching criteria")
-		return nil
+// List returns the branches matching opts
+func List(g *git.Git, opts ListOptions) (*ListResult, error) {
+	branches, err := g.ListBranches()
+	if err != nil {
+		return nil, err
 	}
 
-	// Create tabwriter for aligned output
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Branch\tCommit\tStatus\tMessage")
-	fmt.Fprintln(w, "------\t------\t------\t-------")
+	log.Debug("Retrieved %d branches", len(branches))
 
-	for _, branch := range filteredBranches {
-		status := []string{}
-		if branch.IsCurrent {
-			status = append(status, color.GreenString("current"))
+	// Filter branches based on options
+	res := &ListResult{}
+	if opts.MissingLocal {
+		res.Branches = git.RemoteWithoutLocal(branches)
+	} else {
+		for _, branch := range branches {
+			if opts.All ||
+				(opts.Remote && branch.IsRemote) ||
+				(!opts.Remote && !branch.IsRemote) {
+				res.Branches = append(res.Branches, branch)
+			}
 		}
-		if branch.IsDefault {
-			status = append(status, color.BlueString("default"))
+	}
+
+	if opts.Touches != nil {
+		res.Branches = opts.Touches.Filter(g, res.Branches)
+	}
+	if opts.PRStates != nil {
+		res.Branches = opts.PRStates.Filter(res.Branches)
+	}
+	if opts.Tickets != nil {
+		res.Branches = opts.Tickets.Filter(g, res.Branches)
+	}
+
+	// Only the page shown needs the slower details below
+	res.Total = len(res.Branches)
+	if res.Branches, err = paginate(res.Branches, opts.Limit, opts.Page); err != nil {
+		return nil, err
+	}
+	withUsage(g, res.Branches)
+	withMessages(g, res.Branches)
+	if opts.ByTarget {
+		if res.Groups, err = groupByTarget(g, res.Branches); err != nil {
+			return nil, err
 		}
-		if branch.IsMerged {
-			status = append(status, color.YellowString("merged"))
+	}
+
+	log.Debug("Filtered to %d branches", len(res.Branches))
+
+	if opts.Weight {
+		for i := range res.Branches {
+			w, err := g.BranchWeight(res.Branches[i].Reference, opts.Base)
+			if err != nil {
+				log.Debug("Failed to compute weight of %s: %v", res.Branches[i].Name, err)
+				continue
+			}
+			res.Branches[i].Weight = &w
 		}
-		if branch.IsStale {
-			status = append(status, color.RedString("stale"))
+	}
+
+	return res, nil
+}
+
+// groupByTarget groups branches by the merge targets they are merged into,
+// in the order of the configured targets, with unmerged branches last
+func groupByTarget(g *git.Git, branches []git.GitBranch) ([]TargetGroup, error) {
+	targets, err := g.MergeTargetsOf(branches)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []TargetGroup
+	index := make(map[string]int)
+	for _, b := range branches {
+		key := strings.Join(targets[b.Reference], "\x00")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, TargetGroup{Targets: targets[b.Reference]})
 		}
+		groups[i].Branches = append(groups[i].Branches, b)
+	}
 
-		statusStr := strings.Join(status, ", ")
-		if statusStr == "" {
-			statusStr = "-"
+	slices.SortStableFunc(groups, func(a, b TargetGroup) int {
+		if len(a.Targets) == 0 || len(b.Targets) == 0 {
+			return len(b.Targets) - len(a.Targets)
 		}
+		return slices.CompareFunc(a.Targets, b.Targets, compareTargets)
+	})
+	return groups, nil
+}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			branch.Name,
-			branch.CommitHash,
-			statusStr,
-			branch.Message,
-		)
+// compareTargets orders merge target names by the configured pattern they
+// match first, then by name
+func compareTargets(a, b string) int {
+	if c := cmp.Compare(targetRank(a), targetRank(b)); c != 0 {
+		return c
 	}
+	return strings.Compare(a, b)
+}
 
-	if err := w.Flush(); err != nil {
-		log.Error("Failed to flush output", "error", err)
-		return err
+// targetRank returns the position of the first configured merge target
+// pattern matching name
+func targetRank(name string) int {
+	for i, pattern := range cfg.MergedTargets {
+		if ok, _ := path.Match(pattern, name); ok {
+			return i
+		}
 	}
+	return len(cfg.MergedTargets)
+}
 
-	log.Debug("Successfully listed branches")
+// Tracking returns the upstream relationship of local branches whose state
+// matches filter (see trackingFilters)
+func Tracking(g *git.Git, filter string) (*TrackingResult, error) {
+	valid := false
+	for _, f := range trackingFilters {
+		if filter == f {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid tracking filter %q (expected one of: %s)", filter, strings.Join(trackingFilters, ", "))
+	}
+
+	statuses, err := g.ListTracking()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TrackingResult{}
+	for _, s := range statuses {
+		state := s.State()
+		if filter == "all" ||
+			filter == state ||
+			(filter == "tracked" && state != "untracked") {
+			res.Branches = append(res.Branches, s)
+		}
+	}
+	return res, nil
+}
+
+// checkPageFlags validates --limit and --page before anything is listed
+func checkPageFlags(limit, page int, pageSet bool) error {
+	switch {
+	case limit < 0:
+		return fmt.Errorf("--limit must not be negative")
+	case page < 1:
+		return fmt.Errorf("--page must be at least 1")
+	case pageSet && limit == 0:
+		return fmt.Errorf("--page needs --limit")
+	}
 	return nil
 }
+
+// paginate returns the page'th run of limit items, counting from 1, or all
+// items when limit is 0
+func paginate[T any](items []T, limit, page int) ([]T, error) {
+	if limit <= 0 {
+		return items, nil
+	}
+	page = max(page, 1)
+	pages := max(1, (len(items)+limit-1)/limit)
+	if page > pages {
+		return nil, fmt.Errorf("--page %d is past the last page, %d", page, pages)
+	}
+	start := (page - 1) * limit
+	return items[start:min(start+limit, len(items))], nil
+}
+
+// newListPresenter returns the presenter of list output, which goes
+// through the pager on a terminal unless --no-pager, and the function to
+// call once done with it. Notes and log messages go to the pager too, so
+// they don't land on top of it.
+func newListPresenter() (*presenter, func()) {
+	if listNoPager {
+		return newPresenter(os.Stdout), func() {}
+	}
+	out, pager := ui.StartPager(os.Stdout)
+	p := newPresenter(out)
+	if pager == nil {
+		return p, func() {}
+	}
+
+	p.notes = out
+	console := log.Console()
+	log.SetConsole(out)
+	return p, func() {
+		log.SetConsole(console)
+		closePager(pager)
+	}
+}
+
+// closePager waits for the user to quit the pager, if one was started
+func closePager(p *ui.Pager) {
+	if err := p.Close(); err != nil {
+		log.Debug("Pager failed: %v", err)
+	}
+}
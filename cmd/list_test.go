@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	tests := []struct {
+		name    string
+		items   []int
+		limit   int
+		page    int
+		want    []int
+		wantErr string
+	}{
+		{name: "no limit", items: items, limit: 0, page: 1, want: items},
+		{name: "first page", items: items, limit: 2, page: 1, want: []int{1, 2}},
+		{name: "middle page", items: items, limit: 2, page: 2, want: []int{3, 4}},
+		{name: "short last page", items: items, limit: 2, page: 3, want: []int{5}},
+		{name: "limit beyond items", items: items, limit: 10, page: 1, want: items},
+		{name: "page defaults to first", items: items, limit: 2, page: 0, want: []int{1, 2}},
+		{name: "past the last page", items: items, limit: 2, page: 4, wantErr: "--page 4 is past the last page, 3"},
+		{name: "nothing to page", items: nil, limit: 2, page: 1, want: nil},
+		{name: "nothing has no second page", items: nil, limit: 2, page: 2, wantErr: "past the last page, 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := paginate(tt.items, tt.limit, tt.page)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckPageFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		page    int
+		pageSet bool
+		wantErr string
+	}{
+		{name: "defaults", limit: 0, page: 1},
+		{name: "limit only", limit: 20, page: 1},
+		{name: "limit and page", limit: 20, page: 3, pageSet: true},
+		{name: "negative limit", limit: -1, page: 1, wantErr: "--limit must not be negative"},
+		{name: "page zero", limit: 20, page: 0, pageSet: true, wantErr: "--page must be at least 1"},
+		{name: "page without limit", limit: 0, page: 2, pageSet: true, wantErr: "--page needs --limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPageFlags(tt.limit, tt.page, tt.pageSet)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// truncatedSubjectLength is where subject-truncated cuts subjects, the
+// subject length git recommends
+const truncatedSubjectLength = 50
+
+// maxPreviewBodyLines bounds the message body lines shown in previews
+const maxPreviewBodyLines = 6
+
+// messageDisplay returns the configured message_display mode
+func messageDisplay() string {
+	if cfg == nil || cfg.MessageDisplay == "" {
+		return config.MessageSubject
+	}
+	return cfg.MessageDisplay
+}
+
+// withMessages sets the commit text listings show for branches, as set by
+// message_display, reading every branch's message at once. Failing to read
+// them only leaves the messages empty.
+func withMessages(g *git.Git, branches []git.GitBranch) {
+	details, err := g.BranchDetails()
+	if err != nil {
+		log.Debug("Failed to read commit messages: %v", err)
+		return
+	}
+
+	mode := messageDisplay()
+	for i := range branches {
+		d, ok := details[branches[i].Reference]
+		if !ok {
+			continue
+		}
+		branches[i].CommitDate = d.CommitDate
+		switch mode {
+		case config.MessageSubjectTruncated:
+			branches[i].Message = truncateText(d.Subject, truncatedSubjectLength)
+		case config.MessageFull:
+			branches[i].Message = strings.Join(strings.Fields(d.Subject+" "+d.Body), " ")
+		default:
+			branches[i].Message = d.Subject
+		}
+	}
+}
+
+// truncateText shortens s to at most n runes, ending in "…" when cut
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// messagePreviews reads full commit messages for previews, once per ref
+type messagePreviews struct {
+	g      *git.Git
+	mu     sync.Mutex
+	bodies map[string]string
+}
+
+func newMessagePreviews(g *git.Git) *messagePreviews {
+	return &messagePreviews{g: g, bodies: make(map[string]string)}
+}
+
+// bodyOf returns the message body of b's tip commit, without the subject
+// and limited to maxPreviewBodyLines lines
+func (m *messagePreviews) bodyOf(b git.GitBranch) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if body, ok := m.bodies[b.Reference]; ok {
+		return body
+	}
+	msg, err := m.g.CommitMessage(b.Reference)
+	if err != nil {
+		log.Debug("Failed to read commit message of %s: %v", b.Name, err)
+	}
+	_, body, _ := strings.Cut(msg, "\n")
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) > maxPreviewBodyLines {
+		lines = append(lines[:maxPreviewBodyLines], "…")
+	}
+	m.bodies[b.Reference] = strings.TrimSpace(strings.Join(lines, "\n"))
+	return m.bodies[b.Reference]
+}
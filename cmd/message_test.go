@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMessages(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+
+	r, g := newTestRepo(t)
+	subject := "Teach the parser about " + strings.Repeat("nested ", 8) + "blocks"
+	r.Git("checkout", "--quiet", "-b", "feature/parser", "main")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", subject, "-m", "It used to give up.\nNow it doesn't.")
+	r.Git("checkout", "--quiet", "main")
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{config.MessageSubject, subject},
+		{config.MessageSubjectTruncated, "Teach the parser about nested nested nested neste…"},
+		{config.MessageFull, subject + " It used to give up. Now it doesn't."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg = config.DefaultConfig()
+			cfg.MessageDisplay = tt.mode
+			branches := []git.GitBranch{
+				{Name: "feature/parser", Reference: "refs/heads/feature/parser"},
+				{Name: "gone", Reference: "refs/heads/gone"},
+			}
+			withMessages(g, branches)
+			assert.Equal(t, tt.want, branches[0].Message)
+			assert.False(t, branches[0].CommitDate.IsZero())
+			assert.Empty(t, branches[1].Message)
+		})
+	}
+}
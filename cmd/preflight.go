@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// logPreflight logs, with --debug, the environment and policy a command is
+// about to act with, so a wrong repository or a missing protection pattern
+// shows before anything is deleted
+func logPreflight(g *git.Git) {
+	if !debugFlag || cfg == nil {
+		return
+	}
+
+	version := "unknown"
+	if v, err := g.Version(); err == nil {
+		version = fmt.Sprintf("%d.%d", v[0], v[1])
+	}
+	defaultBranch, err := g.DefaultBranch()
+	if err != nil {
+		defaultBranch = orUnset(cfg.DefaultBranch) + " (from config, origin/HEAD is not set)"
+	}
+	remote := cfg.DefaultRemote
+	if url, err := g.RemoteURL(); err == nil {
+		remote += " (" + url + ")"
+	}
+	configFile := defaultConfigPath()
+	if _, err := os.Stat(configFile); err != nil {
+		configFile += " (not found, using defaults)"
+	}
+	protection := g.Protection()
+
+	mergeStrategies := []string{config.MergeStrategyAncestry}
+	if cfg.UsesMergeStrategy(config.MergeStrategyCherry) {
+		mergeStrategies = append(mergeStrategies, config.MergeStrategyCherry)
+	}
+	trash := "off"
+	if retention := cfg.TrashRetention(); retention > 0 {
+		trash = fmt.Sprintf("%d days", int(retention.Hours()/24))
+	}
+
+	log.Debug("Pre-flight:")
+	for _, line := range [][2]string{
+		{"git version", version},
+		{"repository", g.WorkDir()},
+		{"config file", configFile},
+		{"default branch", defaultBranch},
+		{"remote", remote},
+		{"protected", patternList(protection.Local)},
+		{"protected remote", patternList(protection.Remote)},
+		{"merge targets", orDefault(strings.Join(cfg.MergedTargets, ", "), "HEAD")},
+		{"merge strategies", strings.Join(mergeStrategies, ", ")},
+		{"include refs", orDefault(strings.Join(cfg.IncludeRefs, ", "), "all branches")},
+		{"exclude refs", patternList(cfg.ExcludeRefs)},
+		{"confirmation", orDefault(cfg.Confirmation.Mode, config.ConfirmYesNo)},
+		{"force confirmation", orDefault(cfg.ForceConfirmation, config.ForceConfirmNone)},
+		{"with remote", fmt.Sprint(cfg.WithRemote)},
+		{"auto confirm", fmt.Sprint(cfg.AutoConfirm)},
+		{"trash", trash},
+		{"shared clone", fmt.Sprint(g.IsShared())},
+	} {
+		log.Debug("  %-19s %s", line[0]+":", line[1])
+	}
+}
+
+// patternList joins patterns for display, or says there are none
+func patternList(patterns []string) string {
+	return orDefault(strings.Join(patterns, ", "), "(none)")
+}
+
+// orDefault returns s, or def when s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,1045 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/fatih/color"
+)
+
+// presenter renders structured command results for the terminal
+type presenter struct {
+	out io.Writer
+	// notes receives remarks that must stay out of machine-readable output
+	notes io.Writer
+	// absoluteDates shows commit dates as locale dates rather than "3
+	// weeks ago"
+	absoluteDates bool
+}
+
+func newPresenter(out io.Writer) *presenter {
+	return &presenter{out: out, notes: os.Stderr}
+}
+
+// list renders branches as an aligned table
+func (p *presenter) list(res *ListResult) error {
+	if len(res.Branches) == 0 {
+		log.Info("No branches found matching criteria")
+		return nil
+	}
+
+	showWeight := false
+	for _, branch := range res.Branches {
+		if branch.Weight != nil {
+			showWeight = true
+			break
+		}
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if showWeight {
+		fmt.Fprintln(w, "Branch\tCommit\tUpdated\tStatus\tWeight\tMessage")
+		fmt.Fprintln(w, "------\t------\t-------\t------\t------\t-------")
+	} else {
+		fmt.Fprintln(w, "Branch\tCommit\tUpdated\tStatus\tMessage")
+		fmt.Fprintln(w, "------\t------\t-------\t------\t-------")
+	}
+
+	for _, branch := range res.Branches {
+		status := []string{}
+		if branch.IsCurrent {
+			status = append(status, ui.Indicator(ui.IndicatorCurrent))
+		}
+		if branch.IsDefault {
+			status = append(status, ui.Indicator(ui.IndicatorDefault))
+		}
+		if branch.IsMerged {
+			status = append(status, ui.Indicator(ui.IndicatorMerged))
+		}
+		if branch.IsStale {
+			status = append(status, ui.Indicator(ui.IndicatorStale))
+		}
+		if recentlyUsed(branch) {
+			status = append(status, ui.Indicator(ui.IndicatorRecentlyUsed))
+		}
+
+		statusStr := strings.Join(status, ", ")
+		if statusStr == "" {
+			statusStr = "-"
+		}
+
+		if showWeight {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				branch.Name,
+				branch.CommitHash,
+				p.date(branch.CommitDate),
+				statusStr,
+				formatWeight(branch.Weight),
+				branch.Message,
+			)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			branch.Name,
+			branch.CommitHash,
+			p.date(branch.CommitDate),
+			statusStr,
+			branch.Message,
+		)
+	}
+
+	return w.Flush()
+}
+
+// listByTarget renders list --by-target: a table for each group of
+// branches merged into the same targets, headed by where they landed
+func (p *presenter) listByTarget(res *ListResult) error {
+	if len(res.Branches) == 0 {
+		log.Info("No branches found matching criteria")
+		return nil
+	}
+
+	bold := color.New(color.Bold)
+	for i, group := range res.Groups {
+		if i > 0 {
+			fmt.Fprintln(p.out)
+		}
+		heading := "Not merged into any target"
+		if len(group.Targets) > 0 {
+			heading = "Merged into " + strings.Join(group.Targets, ", ")
+		}
+		fmt.Fprintf(p.out, "%s (%d)\n", bold.Sprint(heading), len(group.Branches))
+		if err := p.list(&ListResult{Branches: group.Branches}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// date formats a commit date relative to now, e.g. "3 weeks ago", or as a
+// date in the user's locale with absoluteDates
+func (p *presenter) date(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if p.absoluteDates {
+		return t.Local().Format(ui.DateLayout(ui.Locale()))
+	}
+	return ui.RelativeTime(t, time.Now())
+}
+
+// pageNote tells what a --limit left out and how to see the rest
+func (p *presenter) pageNote(shown, total, limit, page int) {
+	if limit <= 0 || shown == total {
+		return
+	}
+	first := (page-1)*limit + 1
+	note := fmt.Sprintf("Showing %d-%d of %d branches", first, first+shown-1, total)
+	if first+shown-1 < total {
+		note += fmt.Sprintf("; next: --page %d", page+1)
+	}
+	fmt.Fprintf(p.notes, "%s %s\n", color.BlueString("i"), note)
+}
+
+// missingLocal explains how to clean up the remote branches listed by
+// list --remote-only-missing-local, which involves no local branch
+func (p *presenter) missingLocal(res *ListResult) {
+	if len(res.Branches) == 0 {
+		return
+	}
+	fmt.Fprintf(p.notes, "%s %d remote branch(es) have no local branch; deleting them needs push access to the remote: git-branch-delete delete --remote <branch>\n",
+		color.BlueString("i"), len(res.Branches))
+}
+
+// names writes just the branch names, each terminated by NUL, for xargs -0
+// and other pipelines that must survive any character in a name
+func (p *presenter) names(res *ListResult) error {
+	w := bufio.NewWriter(p.out)
+	for _, b := range res.Branches {
+		w.WriteString(b.Name)
+		w.WriteByte(0)
+	}
+	return w.Flush()
+}
+
+// csv renders branches as CSV with a header row. Fields are quoted as
+// needed, so commit subjects with commas or quotes stay in one cell.
+func (p *presenter) csv(rows []branchRow, columns []string) error {
+	w := csv.NewWriter(p.out)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = csvColumns[c](r)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// tracking renders local branches with their upstream and ahead/behind counts
+func (p *presenter) tracking(res *TrackingResult) error {
+	if len(res.Branches) == 0 {
+		log.Info("No branches found matching criteria")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Branch\tUpstream\tAhead\tBehind\tStatus")
+	fmt.Fprintln(w, "------\t--------\t-----\t------\t------")
+
+	for _, t := range res.Branches {
+		upstream := t.Upstream
+		if upstream == "" {
+			upstream = "-"
+		}
+
+		state := t.State()
+		switch state {
+		case "gone":
+			state = color.RedString(state)
+		case "diverged", "ahead", "behind":
+			state = color.YellowString(state)
+		case "synced":
+			state = color.GreenString(state)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", t.Branch, upstream, t.Ahead, t.Behind, state)
+	}
+
+	return w.Flush()
+}
+
+// compare renders local branches with their distance from the base branch
+func (p *presenter) compare(res *CompareResult) error {
+	if len(res.Branches) == 0 {
+		log.Info("No branches to compare with %s", res.Base)
+		return nil
+	}
+
+	showWeight := false
+	for _, c := range res.Branches {
+		if c.Weight != nil {
+			showWeight = true
+			break
+		}
+	}
+
+	fmt.Fprintf(p.out, "Compared with %s (%s)\n\n", res.Base, res.BaseRef)
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	if showWeight {
+		fmt.Fprintln(w, "Branch\tAhead\tBehind\tStatus\tWeight")
+		fmt.Fprintln(w, "------\t-----\t------\t------\t------")
+	} else {
+		fmt.Fprintln(w, "Branch\tAhead\tBehind\tStatus")
+		fmt.Fprintln(w, "------\t-----\t------\t------")
+	}
+
+	for _, c := range res.Branches {
+		state := color.YellowString("unmerged")
+		if c.Merged() {
+			state = color.GreenString("merged")
+		}
+		if showWeight {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", c.Branch, c.Ahead, c.Behind, state, formatWeight(c.Weight))
+		} else {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", c.Branch, c.Ahead, c.Behind, state)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// The whole tip message, which listings cut to their subject
+	for _, c := range res.Branches {
+		if c.Subject == "" {
+			continue
+		}
+		fmt.Fprintf(p.out, "\n%s\n    %s\n", color.New(color.Bold).Sprint(c.Branch), c.Subject)
+		if c.Body != "" {
+			fmt.Fprintln(p.out)
+			for _, line := range strings.Split(c.Body, "\n") {
+				fmt.Fprintln(p.out, strings.TrimRight("    "+line, " "))
+			}
+		}
+	}
+	return nil
+}
+
+// delete reports each deleted and failed branch
+func (p *presenter) delete(res *DeleteResult) {
+	for _, b := range res.Deleted {
+		if b.Remote {
+			log.Info("Successfully deleted remote branch: %s", b.Name)
+		} else {
+			log.Info("Successfully deleted branch: %s", b.Name)
+		}
+		p.recovery(b)
+	}
+	p.failures(res.Failed)
+}
+
+// recovery shows the commit a branch deleted with force pointed at and how
+// to restore it
+func (p *presenter) recovery(b BranchResult) {
+	if b.RecoveryHint == "" || b.Error != "" {
+		return
+	}
+	commit := b.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	log.Info("  was %s; restore with: %s", commit, b.RecoveryHint)
+}
+
+// failureHints are the remediation hints shown once per failure class
+var failureHints = map[string]string{
+	git.FailureAuth:      "check your credentials with `git-branch-delete auth status`",
+	git.FailureProtected: "protected by your configuration or the server; unprotect them there if they should go",
+	git.FailureNotMerged: "merge or push their work first, or delete them anyway with --force",
+	git.FailureNetwork:   "check your connection to the remote",
+	git.FailureNotFound:  "already gone; run `git fetch --prune` to drop stale remote-tracking branches",
+	git.FailureMoved:     "someone pushed to them since they were listed; fetch and review them before deleting",
+}
+
+// failureOrder is the order failure classes are reported in
+var failureOrder = []string{git.FailureAuth, git.FailureProtected, git.FailureNotMerged, git.FailureNetwork, git.FailureNotFound, git.FailureMoved, git.FailureOther}
+
+// failures reports failed deletions grouped by cause, with one hint per
+// cause. Only failures of no known class show their raw errors.
+func (p *presenter) failures(failed []BranchResult) {
+	if len(failed) == 0 {
+		return
+	}
+
+	byClass := make(map[string][]BranchResult)
+	for _, b := range failed {
+		class := b.Class
+		if failureHints[class] == "" {
+			class = git.FailureOther
+		}
+		byClass[class] = append(byClass[class], b)
+	}
+
+	log.Error("Failed to delete %d branch(es):", len(failed))
+	for _, class := range failureOrder {
+		branches := byClass[class]
+		if len(branches) == 0 {
+			continue
+		}
+		if class == git.FailureOther {
+			for _, b := range branches {
+				log.Error("  %s: %s", b.Name, b.Error)
+				if b.RecoveryHint != "" {
+					log.Info("    hint: %s", b.RecoveryHint)
+				}
+			}
+			continue
+		}
+
+		names := make([]string, len(branches))
+		for i, b := range branches {
+			names[i] = b.Name
+			log.Debug("Failed to delete branch %s: %s", b.Name, b.Error)
+		}
+		log.Error("  %s (%d): %s", class, len(branches), strings.Join(names, ", "))
+		log.Info("    hint: %s", failureHints[class])
+	}
+}
+
+// prune reports the outcome of a prune run
+func (p *presenter) prune(res *PruneResult) {
+	if len(res.Candidates)+len(res.Skipped) == 0 {
+		log.Info("No stale branches found")
+		return
+	}
+	for _, b := range res.Skipped {
+		if b.Reason != "" {
+			log.Info("Skipping branch %s: %s", b.Name, b.Reason)
+		}
+	}
+	if res.DryRun {
+		for _, b := range res.Candidates {
+			log.Info("Would delete branch: %s%s", b.Name, formatDeleteReason(b))
+		}
+		log.Info("Dry run: %d branch(es) would be pruned", len(res.Candidates))
+		return
+	}
+	if len(res.Deleted)+len(res.Failed) == 0 {
+		log.Info("No branches selected for deletion")
+		return
+	}
+
+	for _, b := range res.Deleted {
+		log.Info("Successfully deleted branch: %s%s", b.Name, formatDeleteReason(b))
+		p.recovery(b)
+	}
+	p.failures(res.Failed)
+
+	log.Info("Branch pruning completed: %d deleted, %d skipped, %d failed",
+		len(res.Deleted), len(res.Skipped), len(res.Failed))
+}
+
+// sweep renders the prune result of every swept repository under a
+// heading naming its group
+func (p *presenter) sweep(res *SweepResult) {
+	for _, r := range res.Repos {
+		fmt.Fprintf(p.out, "\n%s %s\n", color.New(color.Bold).Sprint(r.Repo), color.HiBlackString("("+r.Group+")"))
+		if r.Error != "" {
+			log.Error("Failed to sweep %s: %s", r.Repo, r.Error)
+			continue
+		}
+		p.prune(r.Prune)
+	}
+}
+
+// updateRefScript prints the planned ref changes as a transaction for
+// `git update-ref --stdin`. The script can't hold comments, so skipped
+// branches and the pushes remote changes need go to the notes writer.
+func (p *presenter) updateRefScript(res *ScriptResult) error {
+	for _, b := range res.Skipped {
+		fmt.Fprintf(p.notes, "%s skipping %s: %s\n", color.YellowString("!"), b.Name, b.Reason)
+	}
+	for _, c := range res.Changes {
+		if c.Push != "" {
+			fmt.Fprintf(p.notes, "%s %s is a remote branch, also run: %s\n", color.BlueString("i"), c.Ref, c.Push)
+		}
+	}
+
+	fmt.Fprintln(p.out, "start")
+	for _, c := range res.Changes {
+		fmt.Fprintf(p.out, "delete %s %s\n", c.Ref, c.OldValue)
+	}
+	_, err := fmt.Fprintln(p.out, "commit")
+	return err
+}
+
+// deletePlan prints the ref changes a delete would make, for dry runs
+func (p *presenter) deletePlan(res *ScriptResult) {
+	for _, b := range res.Skipped {
+		log.Info("Skipping branch %s: %s", b.Name, b.Reason)
+	}
+	for _, c := range res.Changes {
+		commit := c.OldValue
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		log.Info("Would delete %s (was %s)", c.Ref, commit)
+	}
+	log.Info("Dry run: %d ref(s) would be deleted", len(res.Changes))
+}
+
+// stats prints branch counts and, when computed, the author leaderboard
+func (p *presenter) stats(res *StatsResult) error {
+	fmt.Fprintf(p.out, "Local branches:  %d (%d merged, %d stale)\n", res.Local, res.Merged, res.Stale)
+	fmt.Fprintf(p.out, "Remote branches: %d\n", res.Remote)
+	p.activity(res.Activity)
+	if err := p.heaviest(res.Heaviest); err != nil {
+		return err
+	}
+
+	if len(res.Leaderboard) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprint("Cleanup leaderboard (branches ready to delete)"))
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tAuthor\tBranches\tMerged\tStale")
+	for i, e := range res.Leaderboard {
+		author := e.Author
+		if e.Email != "" {
+			author = fmt.Sprintf("%s <%s>", e.Author, e.Email)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\n", i+1, author, len(e.Branches), e.Merged, e.Stale)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.GreenString("Run 'git-branch-delete prune' or 'git-branch-delete interactive' to tidy up."))
+	return nil
+}
+
+// heaviest lists the cleanup-ready branches deleting which reclaims the most
+func (p *presenter) heaviest(branches []WeightedBranch) error {
+	if len(branches) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprint("Heaviest branches ready to delete (unique history)"))
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	for _, b := range branches {
+		fmt.Fprintf(w, "  %s\t%s\n", b.Name, formatWeight(&b.Weight))
+	}
+	return w.Flush()
+}
+
+// activity prints sparklines of the branches created and deleted per week,
+// scaled alike so the two rows can be compared
+func (p *presenter) activity(weeks []ActivityWeek) {
+	if len(weeks) == 0 {
+		return
+	}
+
+	created := make([]int, len(weeks))
+	deleted := make([]int, len(weeks))
+	var totalCreated, totalDeleted int
+	for i, w := range weeks {
+		created[i], deleted[i] = w.Created, w.Deleted
+		totalCreated += w.Created
+		totalDeleted += w.Deleted
+	}
+	peak := max(slices.Max(created), slices.Max(deleted))
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprintf("Branch activity (last %d weeks, oldest first)", len(weeks)))
+	fmt.Fprintf(p.out, "  Created  %s  %d\n", color.YellowString(sparkline(created, peak)), totalCreated)
+	fmt.Fprintf(p.out, "  Deleted  %s  %d\n", color.GreenString(sparkline(deleted, peak)), totalDeleted)
+	if totalCreated > totalDeleted {
+		fmt.Fprintf(p.out, "  %d more branches created than deleted\n", totalCreated-totalDeleted)
+	} else {
+		fmt.Fprintln(p.out, "  Cleanup is keeping pace with new branches")
+	}
+}
+
+// sparkBlocks are the sparkline levels, from none to peak
+var sparkBlocks = []rune("·▁▂▃▄▅▆▇█")
+
+// sparkline renders counts as block characters relative to peak. Any
+// non-zero count gets at least the lowest block.
+func sparkline(counts []int, peak int) string {
+	var b strings.Builder
+	for _, n := range counts {
+		level := 0
+		if n > 0 && peak > 0 {
+			level = (n*(len(sparkBlocks)-1) + peak - 1) / peak
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// rename reports planned, applied and rolled back renames
+func (p *presenter) rename(res *RenameResult) {
+	if len(res.Planned) == 0 {
+		log.Info("No branches match")
+		return
+	}
+
+	describe := func(r git.BranchRename) string {
+		if r.Remote {
+			return fmt.Sprintf("origin/%s → origin/%s", r.From, r.To)
+		}
+		return fmt.Sprintf("%s → %s", r.From, r.To)
+	}
+
+	switch {
+	case res.DryRun:
+		for _, r := range res.Planned {
+			log.Info("Would rename %s", describe(r))
+		}
+		log.Info("Dry run: %d branch(es) would be renamed", len(res.Planned))
+	case res.Failed != nil:
+		log.Error("Failed to rename %s: %s", describe(*res.Failed), res.Error)
+		for _, r := range res.RolledBack {
+			log.Info("Rolled back %s", describe(r))
+		}
+	default:
+		for _, r := range res.Renamed {
+			log.Info("Renamed %s", describe(r))
+		}
+	}
+}
+
+// duplicateGroups prints branches grouped by the commit they point at
+func (p *presenter) duplicateGroups(groups []git.DuplicateGroup) {
+	for _, group := range groups {
+		side := ""
+		if group.Remote {
+			side = " (origin)"
+		}
+		fmt.Fprintf(p.out, "%s%s\n", color.YellowString(group.Commit), side)
+		for i, b := range group.Keep {
+			label := color.GreenString("keep")
+			if i == 0 {
+				label = color.GreenString("keep (canonical)")
+			}
+			fmt.Fprintf(p.out, "  %s %s\n", b.Name, label)
+		}
+		for _, b := range group.Extra {
+			fmt.Fprintf(p.out, "  %s %s\n", b.Name, color.RedString("duplicate"))
+		}
+	}
+	if len(groups) > 0 {
+		fmt.Fprintln(p.out)
+	}
+}
+
+// duplicates summarizes the duplicate groups, and what was deleted when
+// deleting was requested
+func (p *presenter) duplicates(res *DuplicatesResult, deleting bool) {
+	if len(res.Groups) == 0 {
+		log.Info("No duplicate branches found")
+		return
+	}
+
+	extra := 0
+	for _, group := range res.Groups {
+		extra += len(group.Extra)
+	}
+	switch {
+	case extra == 0:
+		log.Info("Every duplicate branch is protected or in use; nothing to delete")
+	case !deleting:
+		log.Info("%d duplicate branch(es) in %d group(s); run with --delete to delete them", extra, len(res.Groups))
+	case len(res.Deleted)+len(res.Failed) == 0:
+		log.Info("No branches deleted")
+	default:
+		p.delete(&DeleteResult{Deleted: res.Deleted, Failed: res.Failed})
+	}
+}
+
+// uniqueCommits shows an unmerged branch about to be force deleted with up
+// to limit of the commits only it reaches
+func (p *presenter) uniqueCommits(b git.GitBranch, work string, commits []git.CommitSummary, limit int) {
+	name := b.Name
+	if b.IsRemote {
+		name = "origin/" + name
+	}
+	if work != "" {
+		work = " " + color.YellowString("("+work+")")
+	}
+	fmt.Fprintf(p.out, "%s %s%s\n", color.YellowString("!"), color.New(color.Bold).Sprint(name), work)
+	for i, c := range commits {
+		if i == limit {
+			fmt.Fprintf(p.out, "    ... and %d more\n", len(commits)-limit)
+			break
+		}
+		fmt.Fprintf(p.out, "    %s %s\n", color.YellowString(c.Hash), c.Subject)
+	}
+}
+
+// remedyCommands are the git commands shown for each remediation
+var remedyCommands = map[string]string{
+	git.RemedyGC:           "git gc",
+	git.RemedyExpireReflog: "git reflog expire --all",
+	git.RemedyPackRefs:     "git pack-refs --all",
+}
+
+// doctor prints repository health metrics and the recommended maintenance
+func (p *presenter) doctor(res *DoctorResult) {
+	p.health(res.Health)
+	if res.Terminal != nil {
+		p.terminal(*res.Terminal)
+	}
+
+	if len(res.Recommendations) == 0 {
+		fmt.Fprintf(p.out, "\n%s Repository is healthy\n", ui.Check())
+		return
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprint("Recommendations"))
+	fixed := make(map[string]bool, len(res.Fixed))
+	for _, remedy := range res.Fixed {
+		fixed[remedy] = true
+	}
+	for _, rec := range res.Recommendations {
+		mark := color.YellowString("!")
+		if fixed[rec.Remedy] {
+			mark = ui.Check()
+		}
+		fmt.Fprintf(p.out, "  %s %s: run %s\n", mark, rec.Reason, remedyCommands[rec.Remedy])
+	}
+
+	if res.After != nil {
+		fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprint("After maintenance"))
+		p.health(*res.After)
+	} else if len(res.Fixed) == 0 {
+		fmt.Fprintln(p.out, "\nRun with --fix to apply them")
+	}
+}
+
+// terminal prints the terminal's capabilities and what doesn't work in it
+func (p *presenter) terminal(t ui.Terminal) {
+	yesNo := func(ok bool) string {
+		if ok {
+			return "yes"
+		}
+		return "no"
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprint("Terminal"))
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Interactive\t%s\n", yesNo(t.Interactive))
+	if t.Interactive {
+		fmt.Fprintf(w, "Escape sequences\t%s\n", yesNo(t.VirtualTerminal))
+		if t.Width > 0 {
+			fmt.Fprintf(w, "Width\t%d columns\n", t.Width)
+		}
+	}
+	fmt.Fprintf(w, "Colors\t%s\n", yesNo(t.Color))
+	if t.Term != "" {
+		fmt.Fprintf(w, "TERM\t%s\n", t.Term)
+	}
+	w.Flush()
+
+	for _, problem := range t.Problems() {
+		fmt.Fprintf(p.out, "  %s %s\n", color.YellowString("!"), problem)
+	}
+}
+
+// health prints repository health metrics
+func (p *presenter) health(h git.RepoHealth) {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Loose objects\t%d (%s)\n", h.LooseObjects, formatBytes(h.LooseSize))
+	fmt.Fprintf(w, "Packs\t%d (%s)\n", h.Packs, formatBytes(h.PackSize))
+	if h.Garbage > 0 {
+		fmt.Fprintf(w, "Garbage files\t%d\n", h.Garbage)
+	}
+	fmt.Fprintf(w, "Refs\t%d (%d loose)\n", h.Refs, h.LooseRefs)
+	fmt.Fprintf(w, "Reflog entries\t%d (%s)\n", h.ReflogEntries, formatBytes(h.ReflogSize))
+	w.Flush()
+}
+
+// graph draws the commits a deletion makes unreachable, labelling branch
+// tips from tips (full hash to branch names) and dimming boundary commits,
+// which stay reachable
+func (p *presenter) graph(lines []git.GraphLine, tips map[string][]string) {
+	lost := 0
+	for _, l := range lines {
+		if l.Commit != "" && !l.Boundary {
+			lost++
+		}
+	}
+	if lost == 0 {
+		fmt.Fprintf(p.out, "\n%s No commits become unreachable; only the branch refs are removed\n", ui.Check())
+		return
+	}
+
+	fmt.Fprintf(p.out, "\n%s\n", color.New(color.Bold).Sprintf("%d commit(s) become unreachable (o: stays reachable)", lost))
+	for _, l := range lines {
+		if l.Commit == "" {
+			fmt.Fprintf(p.out, "  %s\n", color.HiBlackString(l.Graph))
+			continue
+		}
+
+		var labels []string
+		for hash, names := range tips {
+			if strings.HasPrefix(hash, l.Commit) {
+				labels = append(labels, names...)
+			}
+		}
+		sort.Strings(labels)
+		label := ""
+		if len(labels) > 0 {
+			label = " " + color.CyanString("(%s)", strings.Join(labels, ", "))
+		}
+
+		if l.Boundary {
+			fmt.Fprintf(p.out, "  %s\n", color.HiBlackString("%s %s %s", l.Graph, l.Commit, l.Subject))
+			continue
+		}
+		fmt.Fprintf(p.out, "  %s %s%s %s\n", color.RedString(l.Graph), color.YellowString(l.Commit), label, l.Subject)
+	}
+}
+
+// pruneDiff prints how the prune candidates changed since an earlier report
+func (p *presenter) pruneDiff(diff *PruneDiff) {
+	since := "the earlier report"
+	if !diff.Since.IsZero() {
+		since = diff.Since.Format("2006-01-02 15:04")
+	}
+
+	if len(diff.Added)+len(diff.Removed) == 0 {
+		log.Info("No changes since %s (%d candidate(s))", since, len(diff.Unchanged))
+		return
+	}
+
+	for _, b := range diff.Added {
+		fmt.Fprintf(p.out, "%s %s\n", color.GreenString("+"), b.Name)
+	}
+	for _, b := range diff.Removed {
+		fmt.Fprintf(p.out, "%s %s\n", color.RedString("-"), b.Name)
+	}
+
+	log.Info("Since %s: %d newly prunable, %d no longer candidates, %d unchanged",
+		since, len(diff.Added), len(diff.Removed), len(diff.Unchanged))
+}
+
+// archived renders soft-deleted remote branches
+func (p *presenter) archived(res *ArchiveResult) error {
+	if len(res.Branches) == 0 {
+		log.Info("No archived branches found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Branch\tCommit\tLast Commit\tArchived")
+	fmt.Fprintln(w, "------\t------\t-----------\t--------")
+	for _, b := range res.Branches {
+		date := "unknown (not fetched)"
+		if !b.CommitDate.IsZero() {
+			date = b.CommitDate.Format("2006-01-02")
+		}
+		archivedAt := "unknown"
+		if !b.ArchivedAt.IsZero() {
+			archivedAt = b.ArchivedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", b.Name, b.CommitHash, date, archivedAt)
+	}
+	return w.Flush()
+}
+
+// purge reports the outcome of an archive purge
+func (p *presenter) purge(res *PurgeResult) {
+	verb := "Purged"
+	if res.DryRun {
+		verb = "Would purge"
+	}
+	for _, b := range res.Purged {
+		log.Info("%s archived branch: %s", verb, b.Name)
+	}
+	for _, b := range res.Failed {
+		log.Error("Failed to purge %s: %s", b.Name, b.Error)
+	}
+	log.Info("%s %d archived branches, kept %d", verb, len(res.Purged), len(res.Kept))
+}
+
+// orphanedRefs renders tracking refs whose branches are gone upstream
+func (p *presenter) orphanedRefs(orphans []git.OrphanedRef) error {
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Remote\tBranch\tCommit")
+	fmt.Fprintln(w, "------\t------\t------")
+	for _, o := range orphans {
+		hash := o.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", o.Remote, o.Name, hash)
+	}
+	return w.Flush()
+}
+
+// remoteRefs reports the outcome of a remote-refs audit
+func (p *presenter) remoteRefs(res *RemoteRefsResult, deleting bool) {
+	for _, b := range res.Failed {
+		log.Error("Failed to delete %s: %s", b.Name, b.Error)
+	}
+	switch {
+	case len(res.Orphaned) == 0:
+		log.Info("No orphaned tracking refs found")
+	case !deleting:
+		log.Info("Found %d tracking ref(s) of branches gone upstream; run with --delete to remove them", len(res.Orphaned))
+	case len(res.Deleted) > 0 || len(res.Failed) > 0:
+		log.Info("Deleted %d tracking ref(s); local branches were not touched", len(res.Deleted))
+	}
+}
+
+// replay walks through a recorded transcript without running anything
+func (p *presenter) replay(t *git.Transcript, output bool) error {
+	fmt.Fprintf(p.out, "git-branch-delete %s (%s), recorded %s: %d git command(s)\n\n",
+		strings.Join(t.Args, " "), t.Tool, t.Started.Local().Format("2006-01-02 15:04:05"), len(t.Commands))
+
+	dir := ""
+	for i, c := range t.Commands {
+		if c.Dir != dir {
+			fmt.Fprintf(p.out, "In %s\n", c.Dir)
+			dir = c.Dir
+		}
+
+		result := color.GreenString("ok")
+		if c.ExitCode != 0 {
+			result = color.RedString("exit %d", c.ExitCode)
+		}
+		note := ""
+		if git.ChangesRepository(c.Args) {
+			note = color.YellowString("  [changed the repository; not run]")
+		}
+		fmt.Fprintf(p.out, "%4d  git %s  (%dms, %s)%s\n", i+1, strings.Join(c.Args, " "), c.DurationMS, result, note)
+
+		if c.Error != "" {
+			fmt.Fprintf(p.out, "      %s\n", c.Error)
+		}
+		if !output {
+			continue
+		}
+		for _, stream := range []string{c.Stdout, c.Stderr} {
+			if stream = strings.TrimRight(stream, "\n"); stream != "" {
+				fmt.Fprintf(p.out, "      | %s\n", strings.ReplaceAll(stream, "\n", "\n      | "))
+			}
+		}
+	}
+	return nil
+}
+
+// summary prints the final tally of an interactive deletion run
+func (p *presenter) trash(entries []TrashEntry) error {
+	if len(entries) == 0 {
+		log.Info("The trash is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Run\tDeleted\tBranches")
+	fmt.Fprintln(w, "---\t-------\t--------")
+	for _, e := range entries {
+		names := make([]string, len(e.Branches))
+		for i, b := range e.Branches {
+			names[i] = b.Name
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", e.ID, e.Time.Local().Format("2006-01-02 15:04"), strings.Join(names, ", "))
+	}
+	return w.Flush()
+}
+
+func (p *presenter) restore(res *RestoreResult) {
+	verb := "Restored"
+	if res.DryRun {
+		verb = "Would restore"
+	}
+	for _, b := range res.Restored {
+		commit := b.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		log.Info("%s branch %s at %s", verb, restoredName(b), commit)
+	}
+	for _, b := range res.Failed {
+		log.Error("Failed to restore %s: %s", restoredName(b), b.Error)
+	}
+}
+
+// restoredName names a restored branch, qualified with origin when it was
+// restored there
+func restoredName(b BranchResult) string {
+	if b.Remote {
+		return "origin/" + b.Name
+	}
+	return b.Name
+}
+
+func (p *presenter) summary(res *DeleteResult) {
+	fmt.Fprintf(p.out, "\nDeleted %d branches successfully", len(res.Deleted))
+	if len(res.Failed) > 0 {
+		fmt.Fprintf(p.out, ", %d failed", len(res.Failed))
+	}
+	fmt.Fprintln(p.out)
+	for _, b := range res.Deleted {
+		if b.DeleteReason != "" {
+			fmt.Fprintf(p.out, "  %s %s: %s\n", ui.Check(), b.Name, b.DeleteReason)
+		}
+		if b.RecoveryHint != "" {
+			fmt.Fprintf(p.out, "    restore %s with: %s\n", b.Name, b.RecoveryHint)
+		}
+	}
+	p.failures(res.Failed)
+
+	// Calculate and show time saved
+	if len(res.Deleted) > 0 {
+		timeSaved := time.Duration(len(res.Deleted)) * timePerBranchDelete
+		minutes := int(timeSaved.Minutes())
+		seconds := int(timeSaved.Seconds()) % 60
+
+		if minutes > 0 {
+			fmt.Fprintf(p.out, "Saved you ~%d minutes and %d seconds of manual work!%s\n", minutes, seconds, ui.Emoji(" 🚀"))
+		} else {
+			fmt.Fprintf(p.out, "Saved you ~%d seconds of manual work!%s\n", seconds, ui.Emoji(" 🚀"))
+		}
+	}
+}
+
+// timings renders the action, result and elapsed time of each branch of a
+// run as an aligned table
+func (p *presenter) timings(rows []BranchTiming) {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nBranch\tAction\tResult\tTime")
+	fmt.Fprintln(w, "------\t------\t------\t----")
+
+	for _, r := range rows {
+		action := "local"
+		if r.Remote {
+			action = "remote"
+		}
+		result := color.GreenString("deleted")
+		if r.Failed {
+			result = color.RedString("failed")
+			if r.Class != "" {
+				result += " (" + r.Class + ")"
+			}
+		}
+		elapsed := time.Duration(r.DurationMS) * time.Millisecond
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, action, result, formatElapsed(elapsed))
+	}
+	w.Flush()
+}
+
+// hooksStatus renders whether each usage hook is installed, one per line
+func (p *presenter) hooksStatus(res *HooksStatusResult) {
+	for _, hook := range git.UsageHooks {
+		state := "not installed"
+		if res.Installed[hook] {
+			state = "installed"
+		}
+		fmt.Fprintf(p.out, "%s: %s\n", hook, state)
+	}
+}
+
+// formatElapsed renders a duration for the timings table, e.g. "850ms" or
+// "2.4s"
+func formatElapsed(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// formatDeleteReason returns the " (merged into main 42 days ago)" suffix
+// naming why a branch can be deleted, if known
+func formatDeleteReason(b BranchResult) string {
+	if b.DeleteReason == "" {
+		return ""
+	}
+	return " (" + b.DeleteReason + ")"
+}
+
+// formatWeight renders a branch weight as "N commits, M objects, SIZE"
+func formatWeight(w *git.BranchWeight) string {
+	if w == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d commits, %d objects, %s", w.Commits, w.Objects, formatBytes(w.DiskSize))
+}
+
+// formatBytes renders a byte count using binary units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
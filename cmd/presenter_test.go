@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenterNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches []string
+		want     string
+	}{
+		{name: "none", want: ""},
+		{name: "one", branches: []string{"feature/x"}, want: "feature/x\x00"},
+		{
+			name:     "names a line-based pipeline would split",
+			branches: []string{"feature/with space", "fix/\"quoted\"", "émoji-✓"},
+			want:     "feature/with space\x00fix/\"quoted\"\x00émoji-✓\x00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &ListResult{}
+			for _, name := range tt.branches {
+				res.Branches = append(res.Branches, git.GitBranch{Name: name, Message: "not printed"})
+			}
+			var out bytes.Buffer
+			require.NoError(t, newPresenter(&out).names(res))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPresenterCompare(t *testing.T) {
+	res := &CompareResult{Base: "main", BaseRef: "refs/heads/main", Branches: []git.Comparison{
+		{Branch: "feature/a", Ahead: 2, Subject: "Add a", Body: "Because.\n\nSigned-off-by: Someone"},
+		{Branch: "feature/b", Behind: 1, Subject: "Add b"},
+		{Branch: "feature/c"},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, newPresenter(&out).compare(res))
+	assert.Contains(t, out.String(), "\nfeature/a\n    Add a\n\n    Because.\n\n    Signed-off-by: Someone\n")
+	assert.Contains(t, out.String(), "\nfeature/b\n    Add b\n")
+	assert.NotContains(t, out.String(), "\nfeature/c\n")
+}
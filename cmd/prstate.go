@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/github"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/secrets"
+)
+
+// prOpen is the --pr-state value standing for both draft and ready
+const prOpen = "open"
+
+// prTimeout bounds reading the pull requests of a repository
+const prTimeout = time.Minute
+
+// PRFilter selects branches by the state of their pull request on GitHub
+type PRFilter struct {
+	States   []string          // Accepted states, from github.PRStates
+	Branches map[string]string // Branch name to the state of its pull request
+
+	// Incomplete is set when not every pull request could be read. Branches
+	// without one among those read then have an unknown state.
+	Incomplete bool
+}
+
+// parsePRStates parses --pr-state values. "open" stands for draft and
+// ready, and a "!" prefix excludes a state, e.g. "!draft" accepts
+// everything but draft pull requests.
+func parsePRStates(values []string) ([]string, error) {
+	var include, exclude []string
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		negated := strings.HasPrefix(v, "!")
+		v = strings.TrimPrefix(v, "!")
+
+		states := []string{v}
+		if v == prOpen {
+			states = []string{github.PRDraft, github.PRReady}
+		} else if !slices.Contains(github.PRStates, v) {
+			return nil, fmt.Errorf("invalid --pr-state %q (valid: %s, %s, optionally prefixed with !)", v, strings.Join(github.PRStates, ", "), prOpen)
+		}
+		if negated {
+			exclude = append(exclude, states...)
+		} else {
+			include = append(include, states...)
+		}
+	}
+
+	if len(include) == 0 {
+		include = github.PRStates
+	}
+	var accepted []string
+	for _, s := range github.PRStates {
+		if slices.Contains(include, s) && !slices.Contains(exclude, s) {
+			accepted = append(accepted, s)
+		}
+	}
+	if len(accepted) == 0 {
+		return nil, fmt.Errorf("--pr-state %s excludes every state", strings.Join(values, ","))
+	}
+	return accepted, nil
+}
+
+// newPRFilter reads the pull requests of origin's GitHub repository for
+// --pr-state, with the token from GITHUB_TOKEN, GH_TOKEN or auth login
+func newPRFilter(g *git.Git, values []string) (*PRFilter, error) {
+	states, err := parsePRStates(values)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := g.RemoteHost()
+	if err != nil {
+		return nil, fmt.Errorf("--pr-state needs origin's pull requests: %w", err)
+	}
+	repo, err := g.RemoteRepoPath()
+	if err != nil {
+		return nil, fmt.Errorf("--pr-state needs origin's pull requests: %w", err)
+	}
+	apiURL, err := githubAPIURL(host)
+	if err != nil {
+		return nil, fmt.Errorf("--pr-state: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prTimeout)
+	defer cancel()
+	prs, err := newGitHubClient(apiURL, host).ListPullRequests(ctx, repo)
+	incomplete := errors.Is(err, github.ErrIncomplete)
+	if incomplete {
+		log.Warn("%v; branches without one among them are left out", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read pull requests of %s: %w", repo, err)
+	}
+	log.Debug("Read %d pull requests of %s", len(prs), repo)
+
+	return &PRFilter{States: states, Branches: github.BranchPRStates(prs, repo), Incomplete: incomplete}, nil
+}
+
+// newGitHubClient returns a client for the GitHub API of host at apiURL,
+// keeping to the configured rate limit
+func newGitHubClient(apiURL, host string) *github.Client {
+	c := github.NewClient(apiURL, githubToken(host))
+	if cfg != nil {
+		c.RateLimit = cfg.APIRateLimit
+	}
+	return c
+}
+
+// githubToken returns the token for the GitHub API of host, from
+// GITHUB_TOKEN, GH_TOKEN or auth login, or "" to go anonymously
+func githubToken(host string) string {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		token, _ = secrets.Get(host)
+	}
+	if token == "" {
+		log.Debug("No token for %s, calling the GitHub API anonymously", host)
+	}
+	return token
+}
+
+// githubAPIURL returns the REST API of a GitHub host: api.github.com, or
+// GITHUB_API_URL or /api/v3 for GitHub Enterprise. Other providers have no
+// GitHub API to ask.
+func githubAPIURL(host string) (string, error) {
+	switch {
+	case host == "github.com":
+		return github.DefaultBaseURL, nil
+	case slices.Contains(knownProviders, host):
+		return "", fmt.Errorf("only supported for GitHub remotes, not %s", host)
+	case os.Getenv("GITHUB_API_URL") != "":
+		return os.Getenv("GITHUB_API_URL"), nil
+	default:
+		return "https://" + host + "/api/v3", nil
+	}
+}
+
+// State returns the state of the pull request of b, github.PRNone, or ""
+// when it's unknown
+func (f *PRFilter) State(b git.GitBranch) string {
+	if state, ok := f.Branches[b.Name]; ok {
+		return state
+	}
+	if f.Incomplete {
+		// Its pull request may be among those not read
+		return ""
+	}
+	return github.PRNone
+}
+
+// Filter returns the branches whose pull request is in an accepted state.
+// Branches whose state is unknown never are.
+func (f *PRFilter) Filter(branches []git.GitBranch) []git.GitBranch {
+	var matched []git.GitBranch
+	for _, b := range branches {
+		if slices.Contains(f.States, f.State(b)) {
+			matched = append(matched, b)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRFilter(t *testing.T) {
+	branches := []git.GitBranch{{Name: "merged"}, {Name: "wip"}, {Name: "old"}}
+	prs := map[string]string{"merged": github.PRMerged, "wip": github.PRDraft}
+
+	f := &PRFilter{States: []string{github.PRMerged, github.PRNone}, Branches: prs}
+	assert.Equal(t, []git.GitBranch{{Name: "merged"}, {Name: "old"}}, f.Filter(branches))
+
+	// Without every pull request, "old" may have one that wasn't read
+	f.Incomplete = true
+	assert.Equal(t, "", f.State(git.GitBranch{Name: "old"}))
+	assert.Equal(t, []git.GitBranch{{Name: "merged"}}, f.Filter(branches))
+}
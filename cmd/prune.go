@@ -1,130 +1,548 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/AlecAivazis/survey/v2"
 	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	pruneForce bool
+	pruneForce     bool
+	pruneDryRun    bool
+	pruneReport    string
+	pruneDiffSince string
+	pruneNotes     bool
+	pruneDiffNotes bool
+	pruneTouches   string
+	pruneScript    bool
+	pruneGraph     bool
+	pruneComment   string
+	prunePRStates  []string
+	pruneTickets   bool
 )
 
+// PruneOptions controls the behavior of Prune
+type PruneOptions struct {
+	// Select chooses which stale branches to delete. When nil, every
+	// stale branch is deleted.
+	Select func(candidates []git.GitBranch) ([]git.GitBranch, error)
+
+	// DryRun reports the candidates without deleting anything
+	DryRun bool
+
+	// Force deletes branches that aren't merged into the default branch.
+	// Otherwise they're deleted like `git branch -d`, which refuses to lose
+	// commits that aren't in their upstream or HEAD.
+	Force bool
+
+	// Touches keeps only branches that modify a path, when set
+	Touches *TouchFilter
+
+	// PRStates keeps only branches whose pull request is in given states,
+	// when set
+	PRStates *PRFilter
+
+	// TicketsDone also prunes local branches whose trailers name tickets
+	// that are all done in the configured tracker, once they're merged or
+	// pushed to their upstream
+	TicketsDone bool
+
+	// MinAge skips branches whose tip commit is younger, when set
+	MinAge time.Duration
+}
+
 func init() {
 	pruneCmd := newPruneCmd()
 	rootCmd.AddCommand(pruneCmd)
 
 	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "Force delete branches without confirmation")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be pruned without deleting")
+	pruneCmd.Flags().StringVar(&pruneReport, "report", "", "Save the result as a JSON report to this file")
+	pruneCmd.Flags().BoolVar(&pruneScript, "update-ref-script", false, "Print the ref changes as a 'git update-ref --stdin' script instead of deleting")
+	pruneCmd.Flags().StringVar(&pruneTouches, "touches", "", "Only prune branches whose unique commits modify this path")
+	pruneCmd.Flags().StringSliceVar(&prunePRStates, "pr-state", nil, "Only prune branches whose GitHub pull request is in these states (draft|ready|open|merged|closed|none, ! to exclude)")
+	pruneCmd.Flags().BoolVar(&pruneTickets, "tickets-done", false, "Also prune branches whose trailer-named tickets are all done in the tracker")
+	pruneCmd.Flags().BoolVar(&pruneGraph, "graph", false, "Draw the commits that deleting the branches would make unreachable")
+	pruneCmd.Flags().StringVar(&pruneComment, "comment-pr", "", "Post or update a summary comment on a GitHub pull request (default: the one of the Actions event)")
+	pruneCmd.Flags().Lookup("comment-pr").NoOptDefVal = commentFromEvent
+	addCopySummaryFlag(pruneCmd)
+	addTimingsFlag(pruneCmd)
+	pruneCmd.Flags().StringVar(&pruneDiffSince, "diff-since", "", "Compare candidates with an earlier report (implies --dry-run)")
+	pruneCmd.Flags().BoolVar(&pruneNotes, "notes", false, "Record the result as a git note in "+git.RunNotesRef+", to share with teammates")
+	pruneCmd.Flags().BoolVar(&pruneDiffNotes, "diff-since-notes", false, "Compare candidates with the last run recorded with --notes (implies --dry-run)")
 }
 
 func newPruneCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "prune",
 		Short: "Delete stale branches",
-		Long: `Delete branches that have been merged or deleted from remote.
-By default, asks for confirmation before deleting.`,
+		Long: `Delete merged branches that have been deleted from remote. Branches
+whose upstream is gone but which aren't merged are skipped, since their
+commits may exist nowhere else. By default, asks for confirmation before
+deleting.
+
+Branches can also opt into cleanup with a time to live, as a "GBD-TTL: 30d"
+line in their description (git branch --edit-description) or a trailer of
+their tip commit. Once that long has passed since the last commit, the
+branch is pruned like a stale one. Units are h, d and w.
+
+Only branches merged into the default branch are force deleted. Others are
+deleted like 'git branch -d' does, refusing those with commits not in their
+upstream or HEAD, unless --force is given.
+
+With --tickets-done, branches whose tip commit names tickets in trailers
+such as "Closes: PROJ-123" (see ticketTrailers) are pruned once every one
+of those tickets is done in the configured ticketTracker, provided the
+branch is merged or has nothing its upstream lacks.`,
 		Example: `  git-branch-delete prune
-  git-branch-delete prune --force`,
+  git-branch-delete prune --force
+  git-branch-delete prune --dry-run --graph
+  git-branch-delete prune --pr-state '!draft'
+  git-branch-delete prune --dry-run --tickets-done
+  git-branch-delete prune --dry-run --report last-week.json
+  git-branch-delete prune --diff-since last-week.json
+  git-branch-delete prune --dry-run --notes
+  git-branch-delete prune --diff-since-notes
+  git-branch-delete prune --dry-run --comment-pr`,
 		RunE: runPrune,
 	}
 }
 
 func runPrune(cmd *cobra.Command, args []string) error {
 	log.Debug("Starting branch pruning")
-
-	// Get current directory
-	dir, err := os.Getwd()
+	timings, err := timingsOrder(cmd)
 	if err != nil {
-		log.Error("Failed to get current directory", "error", err)
 		return err
 	}
 
 	// Initialize git client
-	gitClient, err := git.New(dir)
+	gitClient, err := openRepo()
 	if err != nil {
-		log.Error("Failed to initialize git client", "error", err)
+		log.Error("Failed to initialize git client: %v", err)
 		return err
 	}
 
-	// Get branches
-	branches, err := gitClient.ListBranches()
+	// A run that can't be recorded fails before it deletes anything
+	if reason := gitClient.ReadOnly(); pruneNotes && reason != "" {
+		return fmt.Errorf("--notes can't record the run: %s", reason)
+	}
+
+	// Load the earlier report first so a bad path fails before any work
+	var previous *PruneResult
+	if pruneDiffSince != "" {
+		if previous, err = loadPruneReport(pruneDiffSince); err != nil {
+			return err
+		}
+	}
+	if pruneDiffNotes {
+		if pruneDiffSince != "" {
+			return fmt.Errorf("--diff-since-notes can't be combined with --diff-since")
+		}
+		if previous, err = lastRunNote(gitClient); err != nil {
+			return err
+		}
+	}
+
+	if pruneComment != "" {
+		if _, err := commentNumber(pruneComment); err != nil {
+			return err
+		}
+	}
+
+	if pruneTickets {
+		if err := requireTicketTracker("--tickets-done"); err != nil {
+			return err
+		}
+	}
+
+	opts := PruneOptions{DryRun: safeDryRun(gitClient, pruneDryRun || pruneScript || previous != nil), TicketsDone: pruneTickets, Force: pruneForce}
+	// If not force mode, confirm deletion
+	if !pruneForce && !opts.DryRun {
+		opts.Select = selectPruneBranches
+	}
+
+	if pruneGraph && !opts.DryRun {
+		opts.Select = withGraphPreview(gitClient, opts.Select)
+	}
+	if pruneForce && !opts.DryRun {
+		opts.Select = withForceConfirmation(gitClient, opts.Select)
+	}
+
+	if !opts.DryRun {
+		ui.ShowBanner(cfg.Confirmation)
+	}
+	refreshDefaultBranch(gitClient)
+
+	if pruneTouches != "" {
+		if opts.Touches, err = newTouchFilter(gitClient, pruneTouches); err != nil {
+			return err
+		}
+	}
+	if len(prunePRStates) > 0 {
+		if opts.PRStates, err = newPRFilter(gitClient, prunePRStates); err != nil {
+			return err
+		}
+	}
+
+	created := branchCreations(gitClient)
+	res, err := Prune(gitClient, opts)
 	if err != nil {
-		log.Error("Failed to list branches", "error", err)
+		log.Error("Failed to prune branches: %v", err)
 		return err
 	}
+	linkBranches(gitClient, res.Candidates, res.Deleted, res.Skipped, res.Failed)
+
+	if pruneReport != "" {
+		if err := savePruneReport(pruneReport, res); err != nil {
+			return err
+		}
+	}
+	// The deletions are still shown and recorded when the note fails
+	var noteErr error
+	if pruneNotes {
+		noteErr = recordRunNote(gitClient, res)
+	}
 
-	log.Debug("Retrieved branches", "count", len(branches))
+	if pruneScript {
+		if err := newPresenter(os.Stdout).updateRefScript(planRefChanges(gitClient, res.Candidates, false)); err != nil {
+			return err
+		}
+		return noteErr
+	}
+
+	if previous != nil {
+		newPresenter(os.Stdout).pruneDiff(DiffPrune(previous, res))
+	} else {
+		newPresenter(os.Stdout).prune(res)
+	}
+	if pruneGraph && res.DryRun {
+		branches := make([]git.GitBranch, len(res.Candidates))
+		for i, b := range res.Candidates {
+			branches[i] = git.GitBranch{Name: b.Name, IsRemote: b.Remote}
+		}
+		if err := showUnreachableGraph(gitClient, branches); err != nil {
+			return err
+		}
+	}
+	queueFailures(gitClient, res.Failed, DeleteOptions{Force: pruneForce})
+	recordDeletions(gitClient, res.Deleted, created)
+	copySummary(res.Deleted)
+	showTimings(&DeleteResult{Deleted: res.Deleted, Failed: res.Failed}, timings)
 
-	// Filter stale branches
-	var staleBranches []git.GitBranch
+	if pruneComment != "" {
+		if err := commentPrune(gitClient, pruneComment, res); err != nil {
+			return err
+		}
+	}
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to delete %d branch(es)", len(res.Failed))
+	}
+	return noteErr
+}
+
+// Prune deletes stale branches and reports what was deleted, skipped, or failed
+func Prune(g *git.Git, opts PruneOptions) (*PruneResult, error) {
+	branches, err := g.ListBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	log.Debug("Retrieved %d branches", len(branches))
+	if err := g.WithTTLs(branches); err != nil {
+		log.Debug("Failed to read branch TTLs: %v", err)
+	}
+
+	var pushed map[string]bool
+	if opts.TicketsDone {
+		withTicketStatuses(g, branches)
+		pushed = pushedBranches(g)
+	}
+
+	// Filter merged branches whose upstream is gone, those whose TTL
+	// expired, and merged or pushed ones whose tickets are done
+	now := time.Now()
+	var staleBranches, unmergedGone []git.GitBranch
 	for _, branch := range branches {
-		if branch.IsStale && !branch.IsDefault && !branch.IsCurrent {
+		if branch.IsDefault || branch.IsCurrent {
+			continue
+		}
+		ticketsDone := branch.TicketsDone() && (branch.IsMerged || pushed[branch.Name])
+		switch {
+		case branch.IsStale && branch.IsMerged, branch.TTL.Expired(now), ticketsDone:
 			staleBranches = append(staleBranches, branch)
+		case branch.IsStale:
+			// Gone from origin, but its commits may exist nowhere else
+			unmergedGone = append(unmergedGone, branch)
 		}
 	}
 
-	log.Debug("Found stale branches", "count", len(staleBranches))
+	if opts.Touches != nil {
+		staleBranches = opts.Touches.Filter(g, staleBranches)
+	}
+	if opts.PRStates != nil {
+		staleBranches = opts.PRStates.Filter(staleBranches)
+	}
+
+	log.Debug("Found %d stale branches", len(staleBranches))
 
-	if len(staleBranches) == 0 {
-		log.Info("No stale branches found")
-		return nil
+	res := &PruneResult{DryRun: opts.DryRun, Time: time.Now()}
+	for _, b := range unmergedGone {
+		skipped := newBranchResult(b, nil)
+		skipped.Reason = "upstream is gone, but it isn't merged"
+		res.Skipped = append(res.Skipped, skipped)
 	}
 
-	// If not force mode, confirm deletion
-	if !pruneForce {
-		var selectedBranches []string
-		prompt := &survey.MultiSelect{
-			Message: "Select branches to delete:",
-			Options: func() []string {
-				options := make([]string, len(staleBranches))
-				for i, b := range staleBranches {
-					options[i] = fmt.Sprintf("%s (%s)", b.Name, b.CommitHash)
-				}
-				return options
-			}(),
-		}
-
-		if err := survey.AskOne(prompt, &selectedBranches); err != nil {
-			log.Error("Failed to get user input", "error", err)
-			return err
+	// Branches involved in an ongoing operation or tracked by a
+	// stacked-diff tool are never candidates
+	stacks, err := g.StackedBranches()
+	if err != nil {
+		log.Debug("Failed to read stacked-diff tool metadata: %v", err)
+	}
+	var details map[string]git.BranchDetail
+	if opts.MinAge > 0 {
+		if details, err = g.BranchDetails(); err != nil {
+			return nil, fmt.Errorf("failed to read branch dates: %w", err)
+		}
+	}
+	candidates := staleBranches[:0:0]
+	for _, b := range staleBranches {
+		reason := b.InUse
+		if tool := stacks.Tool(b.Name); tool != "" && reason == "" {
+			reason = "tracked by " + tool
+		}
+		if reason == "" {
+			reason = g.ProtectionReason(b.Name, false)
+		}
+		if d, ok := details[b.Reference]; ok && reason == "" && now.Sub(d.CommitDate) < opts.MinAge {
+			reason = fmt.Sprintf("last commit is younger than %d days", int(opts.MinAge.Hours()/24))
+		}
+		if reason != "" {
+			skipped := newBranchResult(b, nil)
+			skipped.Reason = reason
+			res.Skipped = append(res.Skipped, skipped)
+			continue
+		}
+		candidates = append(candidates, b)
+	}
+	staleBranches = candidates
+
+	for _, b := range staleBranches {
+		res.Candidates = append(res.Candidates, newBranchResult(b, nil))
+	}
+	reasons := newDeleteReasons(g)
+	reasons.annotate(res.Candidates, staleBranches, "")
+	if len(staleBranches) == 0 || opts.DryRun {
+		return res, nil
+	}
+
+	selected := staleBranches
+	if opts.Select != nil {
+		selected, err = opts.Select(staleBranches)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		if len(selectedBranches) == 0 {
-			log.Info("No branches selected for deletion")
-			return nil
+	chosen := make(map[string]bool, len(selected))
+	for _, b := range selected {
+		chosen[b.Reference] = true
+	}
+	for _, b := range staleBranches {
+		if !chosen[b.Reference] {
+			res.Skipped = append(res.Skipped, newBranchResult(b, nil))
 		}
+	}
 
-		// Map selected options back to branch names
-		staleBranches = func() []git.GitBranch {
-			selected := make([]git.GitBranch, 0, len(selectedBranches))
-			for _, opt := range selectedBranches {
-				for _, b := range staleBranches {
-					if fmt.Sprintf("%s (%s)", b.Name, b.CommitHash) == opt {
-						selected = append(selected, b)
-						break
-					}
-				}
-			}
-			return selected
-		}()
+	if err := trashBranches(g, selected); err != nil {
+		return nil, err
 	}
 
-	// Delete selected branches
-	for _, branch := range staleBranches {
-		log.Info("Deleting branch", "branch", branch.Name)
+	// Only branches known to be merged are force deleted, unless forced;
+	// git checks the others one by one
+	var forced, checked []git.GitBranch
+	for _, b := range selected {
+		if opts.Force || b.IsMerged {
+			forced = append(forced, b)
+		} else {
+			checked = append(checked, b)
+		}
+	}
 
-		if err := gitClient.DeleteBranch(branch.Name, true, false); err != nil {
-			log.Error("Failed to delete branch", "branch", branch.Name, "error", err)
-			return err
+	// Delete forced branches in one transaction when possible
+	if len(forced) > 1 && g.SupportsRefTransactions() {
+		targets := make([]BranchResult, len(forced))
+		for i, b := range forced {
+			targets[i] = newBranchResult(b, nil)
+		}
+		res.Deleted, res.Failed = deleteLocalAtomic(g, targets, true)
+		forced = nil
+	}
+	for _, branch := range append(forced, checked...) {
+		log.Debug("Deleting branch %s", branch.Name)
+
+		force := opts.Force || branch.IsMerged
+		started := time.Now()
+		if err := g.DeleteBranch(branch.Name, force, false); err != nil {
+			res.Failed = append(res.Failed, newBranchResult(branch, err).timed(started))
+			continue
+		}
+		res.Deleted = append(res.Deleted, newBranchResult(branch, nil).timed(started).forced(force))
+	}
+	reasons.annotate(res.Deleted, selected, "")
+
+	return res, nil
+}
+
+// DiffPrune compares the candidates of an earlier prune report with the
+// current ones
+func DiffPrune(previous, current *PruneResult) *PruneDiff {
+	diff := &PruneDiff{Since: previous.Time}
+
+	before := make(map[string]bool, len(previous.Candidates))
+	for _, b := range previous.Candidates {
+		before[b.Name] = true
+	}
+	now := make(map[string]bool, len(current.Candidates))
+	for _, b := range current.Candidates {
+		now[b.Name] = true
+		if before[b.Name] {
+			diff.Unchanged = append(diff.Unchanged, b)
+		} else {
+			diff.Added = append(diff.Added, b)
+		}
+	}
+	for _, b := range previous.Candidates {
+		if !now[b.Name] {
+			diff.Removed = append(diff.Removed, b)
+		}
+	}
+
+	return diff
+}
+
+// loadPruneReport reads a report saved with --report
+func loadPruneReport(path string) (*PruneResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prune report: %w", err)
+	}
+
+	var report PruneResult
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode prune report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// savePruneReport writes res as a JSON report
+func savePruneReport(path string, res *PruneResult) error {
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prune report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write prune report: %w", err)
+	}
+	return nil
+}
+
+// withGraphPreview wraps a prune selection so the commits the selected
+// branches would take with them are drawn before anything is deleted. When
+// branches were picked interactively, deleting them is confirmed again.
+func withGraphPreview(g *git.Git, choose func([]git.GitBranch) ([]git.GitBranch, error)) func([]git.GitBranch) ([]git.GitBranch, error) {
+	return func(candidates []git.GitBranch) ([]git.GitBranch, error) {
+		selected := candidates
+		if choose != nil {
+			var err error
+			if selected, err = choose(candidates); err != nil || len(selected) == 0 {
+				return selected, err
+			}
 		}
 
-		log.Info("Successfully deleted branch", "branch", branch.Name)
+		if err := showUnreachableGraph(g, selected); err != nil {
+			return nil, err
+		}
+		if choose == nil {
+			return selected, nil
+		}
+
+		ok, err := prompter.Confirm(fmt.Sprintf("Delete %d branches?", len(selected)), false)
+		if err == ui.ErrInterrupted || (err == nil && !ok) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		return selected, nil
 	}
+}
 
-	log.Info("Branch pruning completed", "deleted", len(staleBranches))
+// withForceConfirmation wraps a prune selection so unmerged branches are
+// confirmed as configured by force_confirmation
+func withForceConfirmation(g *git.Git, choose func([]git.GitBranch) ([]git.GitBranch, error)) func([]git.GitBranch) ([]git.GitBranch, error) {
+	return func(candidates []git.GitBranch) ([]git.GitBranch, error) {
+		selected := candidates
+		if choose != nil {
+			var err error
+			if selected, err = choose(candidates); err != nil || len(selected) == 0 {
+				return selected, err
+			}
+		}
+		return confirmForced(g, selected)
+	}
+}
+
+// showUnreachableGraph draws the commits deleting branches would make
+// unreachable
+func showUnreachableGraph(g *git.Git, branches []git.GitBranch) error {
+	refs := make([]string, len(branches))
+	tips := make(map[string][]string)
+	for i, b := range branches {
+		refs[i] = branchRef(b)
+		if hash := branchCommit(g, b); hash != "" {
+			tips[hash] = append(tips[hash], b.Name)
+		}
+	}
+
+	lines, err := g.UnreachableGraph(refs)
+	if err != nil {
+		return err
+	}
+	newPresenter(os.Stdout).graph(lines, tips)
 	return nil
 }
+
+// selectPruneBranches asks the user which stale branches to delete
+func selectPruneBranches(staleBranches []git.GitBranch) ([]git.GitBranch, error) {
+	options := make([]string, len(staleBranches))
+	for i, b := range staleBranches {
+		options[i] = fmt.Sprintf("%s (%s)", b.Name, b.CommitHash)
+	}
+
+	selectedBranches, err := prompter.MultiSelect("Select branches to delete:", options, ui.SelectConfig{})
+	if err == ui.ErrInterrupted {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user input: %w", err)
+	}
+
+	// Map selected options back to branches
+	selected := make([]git.GitBranch, 0, len(selectedBranches))
+	for _, opt := range selectedBranches {
+		for _, b := range staleBranches {
+			if fmt.Sprintf("%s (%s)", b.Name, b.CommitHash) == opt {
+				selected = append(selected, b)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
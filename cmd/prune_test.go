@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepo returns a clone with the given prebuilt branch states, and
+// keeps the user's cache directory out of the test
+func newTestRepo(t *testing.T, states ...string) (*testutil.Remote, *git.Git) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	r := testutil.NewRemote(t, states...)
+	g, err := git.New(r.Dir)
+	require.NoError(t, err)
+	return r, g
+}
+
+func TestPruneForce(t *testing.T) {
+	tests := []struct {
+		name    string
+		force   bool
+		deleted []string
+		failed  []string
+	}{
+		{"merged only", false, []string{"feature/done"}, []string{"feature/ttl"}},
+		{"forced", true, []string{"feature/done", "feature/ttl"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g := newTestRepo(t)
+			r.Merged("feature/done")
+			r.DeleteOnOrigin("feature/done")
+			r.Git("fetch", "--quiet", "--prune", "origin")
+			// An expired TTL on a branch that was never pushed
+			r.Git("checkout", "--quiet", "-b", "feature/ttl", "main")
+			r.Git("commit", "--quiet", "--allow-empty", "-m", "Spike\n\nGBD-TTL: 1h")
+			r.Git("checkout", "--quiet", "main")
+
+			res, err := Prune(g, PruneOptions{Force: tt.force})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.deleted, resultNames(res.Deleted))
+			assert.ElementsMatch(t, tt.failed, resultNames(res.Failed))
+			assert.Equal(t, !tt.force, r.HasBranch("feature/ttl"))
+		})
+	}
+}
+
+func TestPruneUnmergedGone(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Gone("feature/gone")
+
+	res, err := Prune(g, PruneOptions{Force: true})
+	require.NoError(t, err)
+	assert.Empty(t, res.Candidates)
+	require.Len(t, res.Skipped, 1)
+	assert.Equal(t, "feature/gone", res.Skipped[0].Name)
+	assert.Contains(t, res.Skipped[0].Reason, "isn't merged")
+	assert.True(t, r.HasBranch("feature/gone"))
+}
+
+func TestDiffPrune(t *testing.T) {
+	since := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	previous := &PruneResult{Time: since, Candidates: []BranchResult{{Name: "a"}, {Name: "b"}}}
+	current := &PruneResult{Candidates: []BranchResult{{Name: "b"}, {Name: "c"}}}
+
+	diff := DiffPrune(previous, current)
+	assert.Equal(t, since, diff.Since)
+	assert.Equal(t, []string{"c"}, resultNames(diff.Added))
+	assert.Equal(t, []string{"a"}, resultNames(diff.Removed))
+	assert.Equal(t, []string{"b"}, resultNames(diff.Unchanged))
+
+	diff = DiffPrune(&PruneResult{}, current)
+	assert.Equal(t, []string{"b", "c"}, resultNames(diff.Added))
+	assert.Empty(t, diff.Removed)
+}
+
+func resultNames(results []BranchResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	return names
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+)
+
+// chosenReason is the deletion reason of branches that are neither merged
+// nor stale, which the user picked themselves
+const chosenReason = "chosen interactively"
+
+// deleteReasons explains why branches can be deleted, computed once per
+// branch since it takes a few git calls
+type deleteReasons struct {
+	g       *git.Git
+	now     time.Time
+	mu      sync.Mutex
+	reasons map[string]string // By branchRef
+}
+
+func newDeleteReasons(g *git.Git) *deleteReasons {
+	return &deleteReasons{g: g, now: time.Now(), reasons: make(map[string]string)}
+}
+
+// of returns why b can be deleted, e.g. "merged into main 42 days ago"
+func (d *deleteReasons) of(b git.GitBranch) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ref := branchRef(b)
+	if reason, ok := d.reasons[ref]; ok {
+		return reason
+	}
+	d.reasons[ref] = d.g.DeletableReason(b, d.now)
+	return d.reasons[ref]
+}
+
+// annotate sets the DeleteReason of results from the branches they are
+// about, falling back to fallback for branches without one
+func (d *deleteReasons) annotate(results []BranchResult, branches []git.GitBranch, fallback string) {
+	byRef := make(map[string]git.GitBranch, len(branches))
+	for _, b := range branches {
+		byRef[branchRef(b)] = b
+	}
+	for i := range results {
+		b, ok := byRef[branchRef(git.GitBranch{Name: results[i].Name, IsRemote: results[i].Remote})]
+		if !ok {
+			continue
+		}
+		if results[i].DeleteReason = d.of(b); results[i].DeleteReason == "" {
+			results[i].DeleteReason = fallback
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+)
+
+// remoteAuth returns how git authenticates to each remote, from the
+// configuration. A token variable that isn't set is skipped with a warning,
+// leaving git's own credentials to try.
+func remoteAuth() map[string]git.RemoteAuth {
+	if len(cfg.RemoteAuth) == 0 {
+		return nil
+	}
+	auth := make(map[string]git.RemoteAuth, len(cfg.RemoteAuth))
+	for remote, a := range cfg.RemoteAuth {
+		ra := git.RemoteAuth{
+			TokenUser:        a.TokenUser,
+			CredentialHelper: a.CredentialHelper,
+			URLRewrites:      a.URLRewrites,
+		}
+		if a.SSHKey != "" {
+			ra.SSHCommand = "ssh -i " + shellQuote(utils.ExpandPath(a.SSHKey)) + " -o IdentitiesOnly=yes"
+		}
+		if a.TokenEnv != "" {
+			ra.Token = os.Getenv(a.TokenEnv)
+			if ra.Token == "" {
+				log.Warn("%s isn't set; %s uses git's own credentials", a.TokenEnv, remote)
+			}
+		}
+		auth[remote] = ra
+	}
+	return auth
+}
+
+// shellQuote quotes s for the shell git runs GIT_SSH_COMMAND with
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	refsRemotes []string
+	refsDelete  bool
+	refsForce   bool
+)
+
+// RemoteRefsOptions controls the behavior of AuditRemoteRefs
+type RemoteRefsOptions struct {
+	Remotes []string // Remotes to audit; empty audits all of them
+	// Confirm is asked before deleting the orphaned refs found. When nil,
+	// nothing is deleted.
+	Confirm func(orphans []git.OrphanedRef) (bool, error)
+}
+
+func init() {
+	remoteRefsCmd := newRemoteRefsCmd()
+	rootCmd.AddCommand(remoteRefsCmd)
+}
+
+func newRemoteRefsCmd() *cobra.Command {
+	remoteRefsCmd := &cobra.Command{
+		Use:   "remote-refs",
+		Short: "Inspect remote-tracking refs",
+	}
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Find tracking refs of branches that no longer exist upstream",
+		Long: `Compare refs/remotes/<remote>/* with the branches each remote advertises
+right now (git ls-remote), and list the tracking refs whose branch was
+deleted upstream. These linger until the next 'git fetch --prune' and make
+deleted branches show up in listings.
+
+With --delete, just those tracking refs are deleted, each only while it
+still points at the audited commit. Local branches are never touched;
+branches tracking a deleted ref show their upstream as gone afterwards, so
+'prune' picks them up.
+
+Remotes that can't be reached, or that are fetched with a custom refspec,
+are skipped and reported.`,
+		Example: `  git-branch-delete remote-refs audit
+  git-branch-delete remote-refs audit --remote upstream --delete`,
+		Args: cobra.NoArgs,
+		RunE: runRemoteRefsAudit,
+	}
+	auditCmd.Flags().StringSliceVar(&refsRemotes, "remote", nil, "Remotes to audit (default all)")
+	auditCmd.Flags().BoolVar(&refsDelete, "delete", false, "Delete the orphaned tracking refs")
+	auditCmd.Flags().BoolVarP(&refsForce, "force", "f", false, "Delete without confirmation")
+
+	remoteRefsCmd.AddCommand(auditCmd)
+	return remoteRefsCmd
+}
+
+func runRemoteRefsAudit(cmd *cobra.Command, args []string) error {
+	if refsForce && !refsDelete {
+		return fmt.Errorf("--force needs --delete")
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	p := newPresenter(os.Stdout)
+	opts := RemoteRefsOptions{Remotes: refsRemotes}
+	if refsDelete {
+		opts.Confirm = func(orphans []git.OrphanedRef) (bool, error) {
+			if err := p.orphanedRefs(orphans); err != nil {
+				return false, err
+			}
+			return confirmRemoteRefs(len(orphans))
+		}
+	}
+
+	res, err := AuditRemoteRefs(g, opts)
+	if err != nil {
+		return err
+	}
+	if !refsDelete {
+		if err := p.orphanedRefs(res.Orphaned); err != nil {
+			return err
+		}
+	}
+	p.remoteRefs(res, refsDelete)
+
+	switch {
+	case len(res.Failed) > 0:
+		return fmt.Errorf("failed to delete %d tracking ref(s)", len(res.Failed))
+	case len(res.Skipped) > 0:
+		return fmt.Errorf("failed to audit %d remote(s)", len(res.Skipped))
+	}
+	return nil
+}
+
+// confirmRemoteRefs asks before deleting tracking refs, unless --force
+func confirmRemoteRefs(count int) (bool, error) {
+	if refsForce {
+		return true, nil
+	}
+	ok, err := ui.ConfirmDestructive(prompter, cfg.Confirmation,
+		fmt.Sprintf("Delete %d orphaned tracking ref(s)?", count), count)
+	if err == ui.ErrInterrupted {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get confirmation: %w", err)
+	}
+	return ok, nil
+}
+
+// AuditRemoteRefs finds the tracking refs of opts.Remotes whose branches
+// are gone upstream and, when opts.Confirm agrees, deletes them. A remote
+// that can't be audited is skipped, never treated as having no branches.
+func AuditRemoteRefs(g *git.Git, opts RemoteRefsOptions) (*RemoteRefsResult, error) {
+	remotes, err := g.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range opts.Remotes {
+		if !slices.Contains(remotes, r) {
+			return nil, fmt.Errorf("no such remote: %s", r)
+		}
+	}
+	if len(opts.Remotes) > 0 {
+		remotes = opts.Remotes
+	}
+
+	res := &RemoteRefsResult{}
+	for _, remote := range remotes {
+		orphans, err := g.OrphanedTrackingRefs(remote)
+		if err != nil {
+			log.Warn("Skipping %s: %v", remote, err)
+			res.Skipped = append(res.Skipped, RemoteError{Remote: remote, Error: err.Error()})
+			continue
+		}
+		res.Orphaned = append(res.Orphaned, orphans...)
+	}
+
+	if len(res.Orphaned) == 0 || opts.Confirm == nil {
+		return res, nil
+	}
+	ok, err := opts.Confirm(res.Orphaned)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		log.Info("Operation cancelled")
+		return res, nil
+	}
+
+	for _, o := range res.Orphaned {
+		if err := g.DeleteTrackingRef(o); err != nil {
+			entry := BranchResult{Name: o.Remote + "/" + o.Name, Commit: o.Hash, Remote: true}
+			entry.setError(err)
+			res.Failed = append(res.Failed, entry)
+			continue
+		}
+		res.Deleted = append(res.Deleted, o)
+	}
+	return res, nil
+}
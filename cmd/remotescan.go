@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanPrefixes []string
+	scanPageSize int
+	scanInterval time.Duration
+	scanLimit    int
+	scanResume   bool
+)
+
+// RemoteScanOptions controls the behavior of RemoteScan
+type RemoteScanOptions struct {
+	Prefixes []string      // Branch name prefixes to scan; empty scans everything
+	PageSize int           // Branches per page; 0 means git.DefaultRemoteScanPage
+	Interval time.Duration // Least time between ls-remote requests
+	// Limit stops the scan after the page reaching this many branches,
+	// keeping the cursor for Resume; 0 scans everything
+	Limit int
+	// Resume continues the last interrupted or limited scan of the same
+	// prefixes instead of starting over
+	Resume bool
+	// Page receives each page of branches as it's read
+	Page func(refs []git.RemoteRef)
+}
+
+// remoteScanCursor records how far a remote scan got, so it can resume
+type remoteScanCursor struct {
+	Prefixes  []string  `json:"prefixes"`
+	After     string    `json:"after"`   // Last branch passed on
+	Scanned   int       `json:"scanned"` // Branches passed on so far
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func init() {
+	scanCmd := newRemoteScanCmd()
+	rootCmd.AddCommand(scanCmd)
+
+	scanCmd.Flags().StringSliceVar(&scanPrefixes, "prefix", nil, "Only scan branches starting with these prefixes, e.g. feature/")
+	scanCmd.Flags().IntVar(&scanPageSize, "page-size", git.DefaultRemoteScanPage, "Branches read and printed per page")
+	scanCmd.Flags().DurationVar(&scanInterval, "interval", 0, "Least time between requests to the remote, e.g. 2s")
+	scanCmd.Flags().IntVar(&scanLimit, "limit", 0, "Stop after about this many branches; continue later with --resume")
+	scanCmd.Flags().BoolVar(&scanResume, "resume", false, "Continue the last interrupted or limited scan")
+}
+
+func newRemoteScanCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remote-scan",
+		Short: "List origin's branches straight from the remote, page by page",
+		Long: `List the branches origin advertises with ls-remote, without fetching them,
+for remotes with tens of thousands of branches where fetching everything or
+holding the list in memory is too slow.
+
+Branches are printed in name order as they arrive, a page at a time, with
+progress on stderr. --prefix limits the scan to parts of the namespace, each
+asked for separately; --interval spaces those requests out for rate-limited
+hosts. The position is saved after every page, so an interrupted or
+--limit'ed scan continues where it stopped with --resume.`,
+		Example: `  git-branch-delete remote-scan --prefix bot/ --prefix renovate/
+  git-branch-delete remote-scan --limit 20000
+  git-branch-delete remote-scan --limit 20000 --resume`,
+		Args: cobra.NoArgs,
+		RunE: runRemoteScan,
+	}
+}
+
+func runRemoteScan(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	// Branches go to stdout, progress to stderr
+	log.SetConsole(os.Stderr)
+	opts := RemoteScanOptions{
+		Prefixes: scanPrefixes,
+		PageSize: scanPageSize,
+		Interval: scanInterval,
+		Limit:    scanLimit,
+		Resume:   scanResume,
+		Page: func(refs []git.RemoteRef) {
+			for _, r := range refs {
+				fmt.Printf("%s\t%s\n", r.Hash, r.Name)
+			}
+		},
+	}
+	res, err := RemoteScan(g, opts)
+	if err != nil {
+		return err
+	}
+	if !res.Complete {
+		log.Info("Stopped after %d branches at %s; run again with --resume to continue", res.Scanned, res.Last)
+	}
+	return nil
+}
+
+// RemoteScan passes origin's branches to opts.Page a page at a time,
+// saving the position after each page until every branch was scanned
+func RemoteScan(g *git.Git, opts RemoteScanOptions) (*RemoteScanResult, error) {
+	prefixes := slices.Clone(opts.Prefixes)
+	slices.Sort(prefixes)
+	cursor := &remoteScanCursor{Prefixes: prefixes}
+
+	if opts.Resume {
+		saved, err := loadScanCursor(g)
+		switch {
+		case err != nil:
+			log.Warn("Starting over: %v", err)
+		case saved == nil:
+			log.Warn("No scan to resume, starting over")
+		case !slices.Equal(saved.Prefixes, prefixes):
+			log.Warn("The last scan had other prefixes, starting over")
+		default:
+			cursor = saved
+			log.Info("Resuming after %s, %d branches already scanned", cursor.After, cursor.Scanned)
+		}
+	}
+
+	start := cursor.Scanned
+	limited := false
+	err := g.ScanRemoteBranches(git.RemoteScanOptions{
+		Prefixes: prefixes,
+		After:    cursor.After,
+		PageSize: opts.PageSize,
+		Interval: opts.Interval,
+	}, func(refs []git.RemoteRef) error {
+		opts.Page(refs)
+		cursor.After = refs[len(refs)-1].Name
+		cursor.Scanned += len(refs)
+		if err := saveScanCursor(g, cursor); err != nil {
+			log.Warn("Failed to save scan position: %v", err)
+		}
+		log.Info("Scanned %d branches (up to %s)", cursor.Scanned, cursor.After)
+
+		if opts.Limit > 0 && cursor.Scanned-start >= opts.Limit {
+			limited = true
+			return git.ErrStopScan
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan remote branches: %w", err)
+	}
+	res := &RemoteScanResult{Scanned: cursor.Scanned, Last: cursor.After, Complete: !limited}
+	if limited {
+		return res, nil
+	}
+
+	log.Info("Scanned %d branches", cursor.Scanned)
+	if err := saveScanCursor(g, nil); err != nil {
+		log.Warn("Failed to remove scan position: %v", err)
+	}
+	return res, nil
+}
+
+// scanCursorPath returns the location of the remote scan position
+func scanCursorPath(g *git.Git) (string, error) {
+	dir, err := g.GitPath(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "remote-scan.json"), nil
+}
+
+// loadScanCursor reads the saved remote scan position, returning nil if
+// there is none
+func loadScanCursor(g *git.Git) (*remoteScanCursor, error) {
+	path, err := scanCursorPath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan position: %w", err)
+	}
+
+	var c remoteScanCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode scan position: %w", err)
+	}
+	return &c, nil
+}
+
+// saveScanCursor writes the remote scan position atomically, removing the
+// file when c is nil
+func saveScanCursor(g *git.Git, c *remoteScanCursor) error {
+	path, err := scanCursorPath(g)
+	if err != nil {
+		return err
+	}
+
+	if c == nil {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove scan position: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	c.UpdatedAt = time.Now()
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan position: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write scan position: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write scan position: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameFrom   string
+	renameTo     string
+	renameRemote bool
+	renameDryRun bool
+)
+
+// RenameOptions controls the behavior of Rename
+type RenameOptions struct {
+	From   string // Branch name pattern with at most one "*", e.g. "feature/*"
+	To     string // New name pattern, e.g. "feat/*"
+	Remote bool   // Also rename matching branches on origin
+	DryRun bool   // Only report the planned renames
+}
+
+func init() {
+	rootCmd.AddCommand(newRenameCmd())
+}
+
+func newRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename --from <pattern> --to <pattern>",
+		Short: "Rename branches in bulk",
+		Long: `Rename the local branches matching --from, e.g. to migrate to a new naming
+convention. The "*" in --from matches any text, slashes included, and
+replaces the "*" in --to. With --remote, matching branches on origin are
+renamed too, and local branches tracking them follow the new names.
+
+Nothing is renamed when a new name is invalid or taken, or a matching
+branch is protected. If a rename fails midway, the renames already made are
+rolled back.`,
+		Example: `  git-branch-delete rename --from 'feature/*' --to 'feat/*' --dry-run
+  git-branch-delete rename --from 'feature/*' --to 'feat/*' --remote
+  git-branch-delete rename --from old-name --to new-name`,
+		RunE: runRename,
+	}
+
+	cmd.Flags().StringVar(&renameFrom, "from", "", "Pattern of the branches to rename, e.g. 'feature/*'")
+	cmd.Flags().StringVar(&renameTo, "to", "", "Pattern of the new names, e.g. 'feat/*'")
+	cmd.Flags().BoolVarP(&renameRemote, "remote", "r", false, "Also rename matching branches on origin")
+	cmd.Flags().BoolVar(&renameDryRun, "dry-run", false, "Show the renames without applying them")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	opts := RenameOptions{From: renameFrom, To: renameTo, Remote: renameRemote, DryRun: safeDryRun(g, renameDryRun)}
+	if !opts.DryRun {
+		ui.ShowBanner(cfg.Confirmation)
+	}
+
+	res, err := Rename(g, opts)
+	if res != nil {
+		newPresenter(os.Stdout).rename(res)
+	}
+	return err
+}
+
+// Rename renames the branches matching opts.From. The renames are all
+// applied or, if one fails, the ones already applied are undone.
+func Rename(g *git.Git, opts RenameOptions) (*RenameResult, error) {
+	renames, err := g.PlanRenames(opts.From, opts.To, opts.Remote)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RenameResult{DryRun: opts.DryRun, Planned: renames}
+	if opts.DryRun || len(renames) == 0 {
+		return res, nil
+	}
+
+	// Remember upstreams so local branches can follow renamed remote ones
+	upstreams := make(map[string]string)
+	if statuses, err := g.ListTracking(); err == nil {
+		for _, s := range statuses {
+			upstreams[s.Branch] = s.Upstream
+		}
+	}
+
+	// Local renames go first, they are cheap to undo
+	for _, r := range renames {
+		log.Debug("Renaming %s to %s (remote: %v)", r.From, r.To, r.Remote)
+		if err := g.RenameBranch(r); err != nil {
+			res.Failed = &r
+			res.Error = err.Error()
+			res.RolledBack = rollbackRenames(g, res.Renamed)
+			res.Renamed = nil
+			return res, fmt.Errorf("failed to rename %s, rolled back %d rename(s)", r.From, len(res.RolledBack))
+		}
+		res.Renamed = append(res.Renamed, r)
+	}
+
+	remoteTarget := make(map[string]string)
+	for _, r := range renames {
+		if r.Remote {
+			remoteTarget["origin/"+r.From] = r.To
+		}
+	}
+	for _, r := range renames {
+		target, ok := remoteTarget[upstreams[r.From]]
+		if r.Remote || !ok {
+			continue
+		}
+		if err := g.SetUpstream(r.To, target); err != nil {
+			log.Warn("%v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// rollbackRenames undoes applied renames in reverse order and returns the
+// ones that were undone
+func rollbackRenames(g *git.Git, applied []git.BranchRename) []git.BranchRename {
+	var undone []git.BranchRename
+	for i := len(applied) - 1; i >= 0; i-- {
+		r := applied[i]
+		inverse := git.BranchRename{From: r.To, To: r.From, Remote: r.Remote}
+		if err := g.RenameBranch(inverse); err != nil {
+			log.Error("Failed to roll back rename of %s to %s: %v", r.From, r.To, err)
+			continue
+		}
+		undone = append(undone, r)
+	}
+	return undone
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var replayOutput bool
+
+func init() {
+	replayCmd := newReplayCmd()
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().BoolVar(&replayOutput, "output", false, "Also show what each command printed")
+}
+
+func newReplayCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay <transcript.json>",
+		Short: "Step through a transcript recorded with --record",
+		Long: `Step through a transcript recorded with --record, as a dry run: each git
+command of the run is shown in order with how long it took and how it
+ended. Nothing is run, and commands that changed the repository at the
+time are marked, so a transcript from someone else's bug report can be
+read safely in any directory.`,
+		Example: `  git-branch-delete prune --record transcript.json
+  git-branch-delete replay transcript.json
+  git-branch-delete replay --output transcript.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, err := git.LoadTranscript(args[0])
+			if err != nil {
+				return err
+			}
+			return newPresenter(os.Stdout).replay(t, replayOutput)
+		},
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/reserved"
+	"github.com/bral/git-branch-delete-go/internal/secrets"
+)
+
+// reservedDir keeps the last copy of shared protected branch lists, under
+// the user's cache directory
+const reservedDir = "git-branch-delete/protected"
+
+// reservedTimeout bounds fetching the shared protected branch list
+const reservedTimeout = 10 * time.Second
+
+// sharedProtection returns the patterns of the protected branch lists the
+// team shares: the file on the default remote's default branch, as of the
+// last fetch, and the list at the configured URL. A broken file fails, as
+// does an unreadable list when it is required; otherwise the configured
+// protection is used alone, with a warning.
+func sharedProtection(g *git.Git) ([]string, error) {
+	shared := cfg.SharedProtection
+	var patterns []string
+
+	if file := cfg.SharedProtectionFile(); file != "" {
+		content, ok, err := sharedProtectionFile(g, file)
+		switch {
+		case err != nil && shared.Required:
+			return nil, fmt.Errorf("the shared protected branch list is required: %w", err)
+		case err != nil:
+			log.Warn("Couldn't look for a shared protected branch list: %v", err)
+		case ok:
+			list, err := reserved.Parse(content)
+			if err != nil {
+				return nil, fmt.Errorf("%s on %s's default branch: %w", file, cfg.DefaultRemote, err)
+			}
+			patterns = append(patterns, list...)
+		}
+	}
+
+	if shared.URL == "" {
+		return patterns, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	// A token only goes over https; the client refuses to send it otherwise
+	token := ""
+	if u, err := url.Parse(shared.URL); err == nil && u.Scheme == "https" {
+		token, _ = secrets.Get(u.Hostname())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), reservedTimeout)
+	defer cancel()
+
+	list, err := reserved.NewClient(filepath.Join(cache, reservedDir), token).Fetch(ctx, shared.URL)
+	if err != nil {
+		if shared.Required {
+			return nil, fmt.Errorf("the shared protected branch list is required: %w", err)
+		}
+		log.Warn("Going on without the shared protected branch list: %v", err)
+		return patterns, nil
+	}
+	if list.Stale != nil {
+		log.Warn("Shared protected branch list may be out of date: %v", list.Stale)
+	}
+	return append(patterns, list.Patterns...), nil
+}
+
+// sharedProtectionFile reads file from the default remote's default
+// branch. Without the remote's HEAD, which clones made with some tools
+// lack, the default branch is the one detected or configured instead.
+func sharedProtectionFile(g *git.Git, file string) (string, bool, error) {
+	content, ok, err := g.RemoteFile(cfg.DefaultRemote, file)
+	if err == nil {
+		return content, ok, nil
+	}
+	branch := defaultBranchOrConfig(g)
+	if branch == "" {
+		return "", false, err
+	}
+	log.Debug("Reading %s from %s/%s: %v", file, cfg.DefaultRemote, branch, err)
+	return g.RemoteBranchFile(cfg.DefaultRemote, branch, file)
+}
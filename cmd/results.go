@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/bral/git-branch-delete-go/internal/webhook"
+)
+
+// BranchResult records the outcome of an operation on a single branch
+type BranchResult struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit,omitempty"`
+	Remote bool   `json:"remote"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Class  string `json:"class,omitempty"` // One of the git.Failure* classes of Error
+	Risk   string `json:"risk,omitempty"`  // One of the git.Risk* levels, when checked
+
+	// DeleteReason is why the branch can be deleted, e.g. "merged into
+	// main 42 days ago"
+	DeleteReason string `json:"deleteReason,omitempty"`
+
+	// URL is the branch's web page, set when prUrlTemplate is configured
+	URL string `json:"url,omitempty"`
+
+	// DurationMS is how long the operation on the branch took. Branches
+	// deleted in one transaction share its duration.
+	DurationMS int64 `json:"durationMs,omitempty"`
+
+	// RecoveryHint is, for a failure, its likely cause and the next step.
+	// For a branch deleted with force, it is the command restoring it.
+	RecoveryHint string `json:"recoveryHint,omitempty"`
+}
+
+// ListResult is the structured result of the list command
+type ListResult struct {
+	Branches []git.GitBranch `json:"branches"`
+	// Total counts the matching branches, including those a limit left
+	// out of Branches
+	Total int `json:"total"`
+	// Groups are Branches grouped by the merge targets they are merged
+	// into, with list --by-target
+	Groups []TargetGroup `json:"groups,omitempty"`
+}
+
+// TargetGroup is the branches merged into the same merge targets; none
+// for unmerged branches
+type TargetGroup struct {
+	Targets  []string        `json:"targets"`
+	Branches []git.GitBranch `json:"branches"`
+}
+
+// TrackingResult is the structured result of the list --tracking view
+type TrackingResult struct {
+	Branches []git.TrackingStatus `json:"branches"`
+}
+
+// CompareResult is the structured result of the compare command
+type CompareResult struct {
+	Base     string           `json:"base"`    // The base branch as named
+	BaseRef  string           `json:"baseRef"` // The ref it resolved to
+	Branches []git.Comparison `json:"branches"`
+}
+
+// RemoteScanResult is how far a remote-scan run got
+type RemoteScanResult struct {
+	Scanned  int    `json:"scanned"`  // Branches scanned, including resumed runs
+	Last     string `json:"last"`     // The last branch scanned
+	Complete bool   `json:"complete"` // Every branch was scanned
+}
+
+// DeleteResult is the structured result of a deletion run
+type DeleteResult struct {
+	Deleted []BranchResult `json:"deleted"`
+	Failed  []BranchResult `json:"failed"`
+}
+
+// PruneResult is the structured result of the prune command
+type PruneResult struct {
+	DryRun     bool           `json:"dryRun"`
+	Time       time.Time      `json:"time"`
+	Candidates []BranchResult `json:"candidates"` // Every branch eligible for pruning
+	Deleted    []BranchResult `json:"deleted"`
+	Skipped    []BranchResult `json:"skipped"`
+	Failed     []BranchResult `json:"failed"`
+}
+
+// SweepResult is the outcome of pruning groups of repositories
+type SweepResult struct {
+	DryRun bool              `json:"dryRun"`
+	Repos  []SweepRepoResult `json:"repos"`
+}
+
+// SweepRepoResult is the outcome of pruning one repository of a group
+type SweepRepoResult struct {
+	Group string       `json:"group"`
+	Repo  string       `json:"repo"` // As configured, before expansion
+	Prune *PruneResult `json:"prune,omitempty"`
+	Error string       `json:"error,omitempty"` // Why the repository couldn't be swept
+}
+
+// PruneDiff compares the current prune candidates with an earlier report
+type PruneDiff struct {
+	Since     time.Time      `json:"since"`
+	Added     []BranchResult `json:"added"`     // Newly prunable
+	Removed   []BranchResult `json:"removed"`   // No longer candidates
+	Unchanged []BranchResult `json:"unchanged"` // Candidates in both
+}
+
+// ArchiveResult is the structured result of the archive list command
+type ArchiveResult struct {
+	Branches []git.ArchivedBranch `json:"branches"`
+}
+
+// PurgeResult is the structured result of the archive purge command
+type PurgeResult struct {
+	DryRun bool           `json:"dryRun"`
+	Purged []BranchResult `json:"purged"`
+	Kept   []BranchResult `json:"kept"`
+	Failed []BranchResult `json:"failed"`
+}
+
+// RemoteRefsResult is the structured result of the remote-refs audit
+// command
+type RemoteRefsResult struct {
+	Orphaned []git.OrphanedRef `json:"orphaned"`
+	Deleted  []git.OrphanedRef `json:"deleted"`
+	Failed   []BranchResult    `json:"failed"`
+	Skipped  []RemoteError     `json:"skipped"` // Remotes that couldn't be audited
+}
+
+// RemoteError is a remote an operation failed on
+type RemoteError struct {
+	Remote string `json:"remote"`
+	Error  string `json:"error"`
+}
+
+// StatsResult is the structured result of the stats command
+type StatsResult struct {
+	Local  int `json:"local"`
+	Remote int `json:"remote"`
+	Merged int `json:"merged"` // Local branches merged into HEAD
+	Stale  int `json:"stale"`  // Local branches whose upstream is gone
+
+	Leaderboard []LeaderboardEntry `json:"leaderboard,omitempty"`
+	Activity    []ActivityWeek     `json:"activity,omitempty"` // Oldest week first
+	Heaviest    []WeightedBranch   `json:"heaviest,omitempty"` // Cleanup-ready branches, heaviest first
+}
+
+// WeightedBranch is a branch with the history unique to it
+type WeightedBranch struct {
+	Name   string           `json:"name"`
+	Weight git.BranchWeight `json:"weight"`
+}
+
+// ActivityWeek counts the branches created and deleted in one week
+type ActivityWeek struct {
+	Start   time.Time `json:"start"`
+	Created int       `json:"created"`
+	Deleted int       `json:"deleted"`
+}
+
+// LeaderboardEntry counts the cleanup-ready branches one author left behind
+type LeaderboardEntry struct {
+	Author   string   `json:"author"`
+	Email    string   `json:"email,omitempty"` // Empty when anonymized
+	Stale    int      `json:"stale"`
+	Merged   int      `json:"merged"`
+	Branches []string `json:"branches"`
+}
+
+// RenameResult is the structured result of the rename command
+type RenameResult struct {
+	DryRun     bool               `json:"dryRun"`
+	Planned    []git.BranchRename `json:"planned"`
+	Renamed    []git.BranchRename `json:"renamed"`
+	Failed     *git.BranchRename  `json:"failed,omitempty"`     // The rename that failed, if any
+	Error      string             `json:"error,omitempty"`      // Why Failed failed
+	RolledBack []git.BranchRename `json:"rolledBack,omitempty"` // Renames undone after the failure
+}
+
+// DoctorResult is the structured result of the doctor command
+type DoctorResult struct {
+	Health          git.RepoHealth       `json:"health"`
+	Recommendations []git.Recommendation `json:"recommendations"`
+	Fixed           []string             `json:"fixed,omitempty"` // Remedies applied with --fix
+	After           *git.RepoHealth      `json:"after,omitempty"` // Health once the fixes are applied
+	Terminal        *ui.Terminal         `json:"terminal,omitempty"`
+}
+
+// HooksStatusResult is the structured result of the hooks status command
+type HooksStatusResult struct {
+	Installed map[string]bool `json:"installed"` // Per hook in git.UsageHooks
+}
+
+// DuplicatesResult is the structured result of the duplicates command
+type DuplicatesResult struct {
+	Groups  []git.DuplicateGroup `json:"groups"`
+	Deleted []BranchResult       `json:"deleted,omitempty"`
+	Failed  []BranchResult       `json:"failed,omitempty"`
+}
+
+// RestoreResult is the structured result of the trash restore and undo
+// commands
+type RestoreResult struct {
+	Restored []BranchResult `json:"restored"`
+	Failed   []BranchResult `json:"failed"`
+	DryRun   bool           `json:"dryRun,omitempty"` // Restored lists what would be restored
+}
+
+// newBranchResult creates a result entry for the given branch
+func newBranchResult(b git.GitBranch, err error) BranchResult {
+	res := BranchResult{
+		Name:   b.Name,
+		Commit: b.CommitHash,
+		Remote: b.IsRemote,
+	}
+	res.setError(err)
+	return res
+}
+
+// timed records the time since started as the duration of r
+func (r BranchResult) timed(started time.Time) BranchResult {
+	r.DurationMS = time.Since(started).Milliseconds()
+	return r
+}
+
+// linkBranches sets the URL of results from the configured prUrlTemplate.
+// Without one nothing is set, since a guessed page may not exist.
+func linkBranches(g *git.Git, results ...[]BranchResult) {
+	if cfg == nil || cfg.PRURLTemplate == "" {
+		return
+	}
+	for _, list := range results {
+		for i := range list {
+			if url, err := g.BranchWebURL(list[i].Name); err == nil {
+				list[i].URL = url
+			}
+		}
+	}
+}
+
+// setError records err, and its failure class, as why the branch failed
+func (r *BranchResult) setError(err error) {
+	if err != nil {
+		r.Error = err.Error()
+		r.Class = git.ClassifyError(err)
+		r.RecoveryHint = git.RecoveryHint(err)
+	}
+}
+
+// forced adds the command restoring r to a branch deleted with force, when
+// force is set, since its commits may be reachable from nowhere else
+func (r BranchResult) forced(force bool) BranchResult {
+	if !force || r.Error != "" || r.Commit == "" {
+		return r
+	}
+	if r.Remote {
+		r.RecoveryHint = fmt.Sprintf("git push origin %s:refs/heads/%s", r.Commit, r.Name)
+	} else {
+		r.RecoveryHint = fmt.Sprintf("git branch %s %s", r.Name, r.Commit)
+	}
+	return r
+}
+
+// WebhookResult is the structured answer to a webhook delivery
+type WebhookResult struct {
+	Event   *webhook.Event `json:"event,omitempty"`
+	Ignored string         `json:"ignored,omitempty"` // Why the delivery was ignored
+	Deleted []BranchResult `json:"deleted"`
+	Skipped []BranchResult `json:"skipped"`
+	Failed  []BranchResult `json:"failed"`
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// stateDir is the directory inside .git holding per-repository state
+const stateDir = "git-branch-delete"
+
+var (
+	retryList  bool
+	retryClear bool
+)
+
+// RetryOperation is a failed deletion waiting to be re-attempted
+type RetryOperation struct {
+	Branch   string    `json:"branch"`
+	Remote   bool      `json:"remote"`
+	Force    bool      `json:"force"`
+	Soft     bool      `json:"soft"`
+	All      bool      `json:"all,omitempty"` // Also delete the remote branch once the local one is gone
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+func init() {
+	rootCmd.AddCommand(newRetryCmd())
+}
+
+func newRetryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retry",
+		Short: "Re-attempt deletions that failed in an earlier run",
+		Long: `Re-attempt only the deletions that failed in an earlier run, for example
+because of missing credentials or a network outage. Failed deletions are
+queued per repository; deletions that succeed are removed from the queue.`,
+		Example: `  git-branch-delete retry
+  git-branch-delete retry --list
+  git-branch-delete retry --clear`,
+		RunE: runRetry,
+	}
+
+	cmd.Flags().BoolVar(&retryList, "list", false, "Show the queued deletions without retrying")
+	cmd.Flags().BoolVar(&retryClear, "clear", false, "Discard the queued deletions")
+
+	return cmd
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	if retryClear {
+		if err := saveRetryQueue(g, nil); err != nil {
+			return err
+		}
+		log.Info("Retry queue cleared")
+		return nil
+	}
+
+	if retryList {
+		queue, err := loadRetryQueue(g)
+		if err != nil {
+			return err
+		}
+		if len(queue) == 0 {
+			log.Info("No failed deletions queued")
+			return nil
+		}
+		for _, op := range queue {
+			kind := "local"
+			if op.Remote {
+				kind = "remote"
+			}
+			log.Info("%s (%s): %s", op.Branch, kind, op.Error)
+		}
+		return nil
+	}
+
+	ui.ShowBanner(cfg.Confirmation)
+	refreshDefaultBranch(g)
+
+	created := branchCreations(g)
+	res, err := Retry(g)
+	if err != nil {
+		return err
+	}
+
+	newPresenter(os.Stdout).delete(res)
+	recordDeletions(g, res.Deleted, created)
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to delete %d branch(es), still queued for retry", len(res.Failed))
+	}
+	return nil
+}
+
+// Retry re-attempts the queued deletions through Delete, so they're checked
+// like the first attempt was, and keeps only those that fail again
+func Retry(g *git.Git) (*DeleteResult, error) {
+	queue, err := loadRetryQueue(g)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &DeleteResult{}
+	var remaining []RetryOperation
+	for _, op := range queue {
+		opts := DeleteOptions{Branches: []string{op.Branch}, Force: op.Force, Remote: op.Remote, All: op.All, Soft: op.Soft}
+		attempt, err := Delete(g, opts)
+		if err != nil {
+			// Refused as a whole, e.g. the branch is protected now
+			branch := git.GitBranch{Name: op.Branch, IsRemote: op.Remote}
+			attempt = &DeleteResult{Failed: []BranchResult{newBranchResult(branch, err)}}
+		}
+		res.Deleted = append(res.Deleted, attempt.Deleted...)
+		res.Failed = append(res.Failed, attempt.Failed...)
+
+		for _, f := range attempt.Failed {
+			again := op
+			again.Remote = f.Remote
+			again.All = op.All && !f.Remote
+			again.Error = f.Error
+			again.FailedAt = time.Now()
+			remaining = append(remaining, again)
+		}
+	}
+
+	if err := saveRetryQueue(g, remaining); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// queueFailures adds the failed entries of a run deleting with opts to the
+// retry queue so they can be re-attempted with `retry`. With opts.All, a
+// local branch that failed is queued along with its remote branch.
+func queueFailures(g *git.Git, failed []BranchResult, opts DeleteOptions) {
+	if len(failed) == 0 {
+		return
+	}
+
+	queue, err := loadRetryQueue(g)
+	if err != nil {
+		log.Debug("Discarding unreadable retry queue: %v", err)
+		queue = nil
+	}
+
+	now := time.Now()
+	for _, f := range failed {
+		op := RetryOperation{
+			Branch:   f.Name,
+			Remote:   f.Remote,
+			Force:    opts.Force,
+			Soft:     opts.Soft,
+			All:      opts.All && !opts.Remote && !f.Remote,
+			Error:    f.Error,
+			FailedAt: now,
+		}
+
+		replaced := false
+		for i := range queue {
+			if queue[i].Branch == op.Branch && queue[i].Remote == op.Remote {
+				queue[i] = op
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			queue = append(queue, op)
+		}
+	}
+
+	if err := saveRetryQueue(g, queue); err != nil {
+		log.Warn("Failed to save retry queue: %v", err)
+		return
+	}
+	log.Info("Run 'git-branch-delete retry' to re-attempt the %d failed deletion(s)", len(failed))
+}
+
+// retryQueuePath returns the location of the retry queue for the repository
+func retryQueuePath(g *git.Git) (string, error) {
+	dir, err := g.GitPath(stateDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "retry.json"), nil
+}
+
+// loadRetryQueue reads the retry queue, returning nil if there is none
+func loadRetryQueue(g *git.Git) ([]RetryOperation, error) {
+	path, err := retryQueuePath(g)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retry queue: %w", err)
+	}
+
+	var queue []RetryOperation
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("failed to decode retry queue: %w", err)
+	}
+	return queue, nil
+}
+
+// saveRetryQueue writes the retry queue, removing the file once it is empty
+func saveRetryQueue(g *git.Git, queue []RetryOperation) error {
+	path, err := retryQueuePath(g)
+	if err != nil {
+		return err
+	}
+
+	if len(queue) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove retry queue: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode retry queue: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write retry queue: %w", err)
+	}
+	return nil
+}
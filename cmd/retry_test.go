@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryChecksLikeDelete(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/done")
+	r.Git("worktree", "add", "--quiet", t.TempDir()+"/wt", "feature/done")
+
+	queueFailures(g, []BranchResult{{Name: "main"}, {Name: "feature/done"}}, DeleteOptions{Force: true})
+
+	res, err := Retry(g)
+	require.NoError(t, err)
+	assert.Empty(t, res.Deleted)
+	assert.ElementsMatch(t, []string{"main", "feature/done"}, resultNames(res.Failed))
+	assert.True(t, r.HasBranch("main"))
+	assert.True(t, r.HasBranch("feature/done"))
+
+	// Both stay queued
+	queue, err := loadRetryQueue(g)
+	require.NoError(t, err)
+	assert.Len(t, queue, 2)
+}
+
+func TestRetryAll(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Tracked("feature/done")
+
+	// The local deletion failed, so the remote one never ran
+	queueFailures(g, []BranchResult{{Name: "feature/done", Error: "network down"}}, DeleteOptions{All: true})
+	queue, err := loadRetryQueue(g)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.True(t, queue[0].All)
+
+	res, err := Retry(g)
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed)
+	assert.Len(t, res.Deleted, 2)
+	assert.False(t, r.HasBranch("feature/done"))
+	assert.False(t, r.HasRemoteBranch("feature/done"))
+
+	queue, err = loadRetryQueue(g)
+	require.NoError(t, err)
+	assert.Empty(t, queue)
+}
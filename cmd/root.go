@@ -1,16 +1,47 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile   string
-	cfg       *config.Config
-	quietFlag bool
-	debugFlag bool
+	cfgFile    string
+	cfg        *config.Config
+	quietFlag  bool
+	debugFlag  bool
+	repoDir    string
+	logPath    string
+	recordPath string
+
+	// transcript records the git commands of the run with --record
+	transcript *git.Transcript
+
+	// expandedArgs are the arguments an alias on the command line expanded
+	// to, for the debug log
+	expandedArgs []string
+
+	// prompter asks the user questions; set before Execute to override
+	prompter ui.Prompter
+
+	// sharedLocks release the shared clone locks held until the run ends
+	sharedLocks []func()
+
+	// lockWholeRun holds the lock of shared clones from opening them to the
+	// end of the run. Long-running commands turn it off, to take it around
+	// each command changing the repository instead of locking other runs
+	// out for as long as they run.
+	lockWholeRun = true
 )
 
 var rootCmd = &cobra.Command{
@@ -18,30 +49,219 @@ var rootCmd = &cobra.Command{
 	Short: "A tool for managing Git branches",
 	Long: `git-branch-delete is a CLI tool for managing Git branches.
 It provides features for listing, deleting, and pruning branches.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if quietFlag {
 			log.SetQuiet(true)
 		} else if debugFlag {
 			log.SetDebug(true)
 		}
+		enableColors()
+		if cfg != nil {
+			ui.SetDisplay(cfg.Display)
+		}
+		if expandedArgs != nil {
+			log.Debug("Expanded alias to: %s", strings.Join(expandedArgs, " "))
+		}
+		if prompter == nil {
+			prompter = ui.NewPrompter(os.Stdin, os.Stdout)
+		}
+		if recordPath != "" {
+			transcript = git.NewTranscript(Version, os.Args[1:])
+		}
+
+		path := logPath
+		if path == "" && cfg != nil {
+			path = cfg.LogFile
+		}
+		if path != "" {
+			if err := log.Init(utils.ExpandPath(path)); err != nil {
+				return err
+			}
+			// Interrupts exit without returning through Execute
+			utils.HandleSignals(func() { log.Close() })
+		}
+		return nil
 	},
 }
 
+// enableColors turns on escape sequence processing of Windows consoles,
+// and turns colors off where the console can't show them
+func enableColors() {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if err := ui.EnableVirtualTerminal(f); err != nil {
+			log.Debug("Console doesn't support escape sequences, turning colors off: %v", err)
+			color.NoColor = true
+			log.SetNoColor(true)
+			return
+		}
+	}
+}
+
 func Execute() error {
-	return rootCmd.Execute()
+	defer log.Close()
+
+	// Aliases are expanded before cobra looks the command up, which needs
+	// the config ahead of the usual initialization
+	if commandIndex(rootCmd.PersistentFlags(), os.Args[1:]) >= 0 {
+		initConfig()
+		if args, ok := expandAlias(cfg, os.Args[1:]); ok {
+			expandedArgs = args
+			rootCmd.SetArgs(args)
+		}
+	}
+	err := rootCmd.Execute()
+	releaseSharedLocks()
+	saveTranscript()
+	return err
+}
+
+// saveTranscript writes the transcript of the run, if recording. Failed
+// runs are the ones worth reporting, so it's written regardless.
+func saveTranscript() {
+	if transcript == nil {
+		return
+	}
+	if err := transcript.Save(recordPath); err != nil {
+		log.Warn("%v", err)
+		return
+	}
+	log.Info("Recorded %d git command(s) in %s; look it over before attaching it to a bug report", len(transcript.Commands), recordPath)
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/git-branch-delete.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is "+defaultConfigPath()+")")
 	rootCmd.PersistentFlags().BoolVar(&quietFlag, "quiet", false, "suppress all output except errors")
 	rootCmd.PersistentFlags().BoolVar(&debugFlag, "debug", false, "enable debug output")
+	rootCmd.PersistentFlags().StringVarP(&repoDir, "repo", "C", "", "run as if started in this repository instead of the current directory")
+	rootCmd.PersistentFlags().StringVar(&logPath, "log-file", "", "also write logs to this file (rotated when it grows past 10 MiB)")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "write every git command run, with redacted output, to this JSON file for bug reports")
+	rootCmd.PersistentFlags().BoolVar(&iKnowWhatIAmDoing, "i-know-what-i-am-doing", false, "make changes even when running as root or in a repository owned by another user, which are dry runs otherwise")
 }
 
+// openRepo opens the repository selected with -C/--repo, defaulting to the
+// current directory
+func openRepo() (*git.Git, error) {
+	dir := repoDir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		dir = wd
+	}
+
+	g, err := openRepoIn(dir)
+	if err != nil {
+		return nil, err
+	}
+	logPreflight(g)
+	return g, nil
+}
+
+// openRepoIn opens the repository in dir with the configured settings
+func openRepoIn(dir string) (*git.Git, error) {
+	g, err := git.New(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize git in %s: %w", dir, err)
+	}
+	g.SetTranscript(transcript)
+	if cfg != nil {
+		shared, err := sharedProtection(g)
+		if err != nil {
+			return nil, err
+		}
+		var pinned map[string]string
+		if cfg.Deployments.Protect {
+			if pinned, err = deployedBranches(g); err != nil {
+				return nil, err
+			}
+		}
+		g.SetProtection(git.Protection{
+			Local:  append(slices.Clone(cfg.ProtectedBranches), shared...),
+			Remote: append(slices.Clone(cfg.RemoteProtectedBranches()), shared...),
+			Pinned: pinned,
+		})
+		g.SetRefFilter(git.RefFilter{
+			Include: cfg.IncludeRefs,
+			Exclude: cfg.ExcludeRefs,
+		})
+		g.SetMergedTargets(cfg.MergedTargets)
+		g.SetCherryMerged(cfg.UsesMergeStrategy(config.MergeStrategyCherry))
+		g.SetExtraEnv(cfg.ExtraEnvAllowlist)
+		g.SetSSHCommands(cfg.SSHCommands)
+		g.SetRemoteAuth(remoteAuth())
+		g.SetWebURLTemplate(cfg.PRURLTemplate)
+	}
+	if err := protectSharedClone(g); err != nil {
+		return nil, err
+	}
+	enterSafeMode(g)
+
+	// Runs in a shared clone take turns from start to end, so one never
+	// acts on branches another has changed since it listed them
+	if lockWholeRun && g.IsShared() && g.ReadOnly() == "" {
+		release, err := g.HoldSharedLock()
+		if err != nil {
+			return nil, err
+		}
+		sharedLocks = append(sharedLocks, release)
+	}
+	return g, nil
+}
+
+// releaseSharedLocks releases the shared clone locks the run holds
+func releaseSharedLocks() {
+	for _, release := range sharedLocks {
+		release()
+	}
+	sharedLocks = nil
+}
+
+// protectSharedClone turns the shared clone safeguards on as the sharedClone
+// mode says, warning when they were turned on by detection
+func protectSharedClone(g *git.Git) error {
+	mode := config.SharedCloneAuto
+	if cfg != nil && cfg.SharedClone != "" {
+		mode = cfg.SharedClone
+	}
+
+	switch mode {
+	case config.SharedCloneNever:
+		return nil
+	case config.SharedCloneAlways:
+		return g.SetShared(true)
+	}
+
+	sharing, err := g.DetectSharing()
+	if err != nil {
+		log.Debug("Couldn't check whether the repository is shared: %v", err)
+		return nil
+	}
+	if !sharing.Shared() {
+		return nil
+	}
+	log.Warn("Shared clone (%s): garbage collection is off and changes wait for %s", sharing, git.SharedLockFile)
+	return g.SetShared(true)
+}
+
+// defaultConfigPath returns the config file path shown in help
+func defaultConfigPath() string {
+	path, err := config.Path()
+	if err != nil {
+		return "the user config directory"
+	}
+	return path
+}
+
+// initConfig loads the config, unless Execute already did for aliases
 func initConfig() {
+	if cfg != nil {
+		return
+	}
 	var err error
 	cfg, err = config.Load()
 	if err != nil {
-		log.Fatal("Error loading config:", err)
+		log.Fatal("Error loading config: %v", err)
 	}
 }
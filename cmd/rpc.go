@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/progress"
+	"github.com/bral/git-branch-delete-go/internal/rpc"
+	"github.com/spf13/cobra"
+)
+
+// listBranchesParams are the parameters of the listBranches method
+type listBranchesParams struct {
+	Remote bool `json:"remote"`
+	All    bool `json:"all"`
+	Weight bool `json:"weight"`
+
+	MissingLocal bool `json:"missingLocal"`
+}
+
+// deleteBranchesParams are the parameters of the deleteBranches method
+type deleteBranchesParams struct {
+	Branches []string `json:"branches"`
+	Force    bool     `json:"force"`
+	Remote   bool     `json:"remote"`
+	All      bool     `json:"all"`
+	Soft     bool     `json:"soft"`
+}
+
+// rpcProgress is the params of the progress notifications sent while a
+// method runs
+type rpcProgress struct {
+	Method string        `json:"method"`
+	Event  progress.Kind `json:"event"`
+	Done   int           `json:"done"`
+	Failed int           `json:"failed"`
+	Total  int           `json:"total"`
+	Branch *BranchResult `json:"branch,omitempty"` // For completed and failed
+}
+
+func init() {
+	rootCmd.AddCommand(newRPCCmd())
+}
+
+func newRPCCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rpc",
+		Short: "Serve JSON-RPC over stdio for editor integrations",
+		Long: `Serve JSON-RPC 2.0 over stdin and stdout, framed with Content-Length headers
+like the Language Server Protocol, so editor extensions can use the tool's
+logic and get structured results. Logs go to stderr.
+
+Methods:
+  listBranches   {remote, all, weight, missingLocal}   -> list result
+  previewPrune   {}                                    -> prune dry-run result
+  deleteBranches {branches, force, remote, all, soft}  -> delete result
+
+deleteBranches sends "progress" notifications: "started" with the number of
+branches, "completed" or "failed" with the outcome of each branch, and
+"finished" before the result. It doesn't ask for confirmation; that is up to
+the editor.`,
+		Args: cobra.NoArgs,
+		RunE: runRPC,
+	}
+}
+
+func runRPC(cmd *cobra.Command, args []string) error {
+	// Stdout carries the protocol
+	log.SetConsole(os.Stderr)
+
+	lockWholeRun = false
+	// Fail early when not started in a repository
+	if _, err := openRepo(); err != nil {
+		return err
+	}
+
+	s := rpc.NewServer()
+	s.Handle("listBranches", rpcListBranches)
+	s.Handle("previewPrune", rpcPreviewPrune)
+	s.Handle("deleteBranches", rpcDeleteBranches)
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return s.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// rpcListBranches answers listBranches. Like the other methods, it opens the
+// repository anew so branches changed between requests (e.g. from the
+// editor's terminal) aren't served from a cache.
+func rpcListBranches(ctx context.Context, conn *rpc.Conn, params json.RawMessage) (interface{}, error) {
+	var p listBranchesParams
+	if err := rpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return nil, err
+	}
+	return List(g, ListOptions{
+		Remote:       p.Remote,
+		All:          p.All,
+		Weight:       p.Weight,
+		Base:         defaultBranchOrConfig(g),
+		MissingLocal: p.MissingLocal,
+	})
+}
+
+// rpcPreviewPrune answers previewPrune with the branches prune would delete
+func rpcPreviewPrune(ctx context.Context, conn *rpc.Conn, params json.RawMessage) (interface{}, error) {
+	g, err := openRepo()
+	if err != nil {
+		return nil, err
+	}
+	return Prune(g, PruneOptions{DryRun: true})
+}
+
+// rpcDeleteBranches answers deleteBranches, forwarding the progress events
+// of the deletion to the client as notifications
+func rpcDeleteBranches(ctx context.Context, conn *rpc.Conn, params json.RawMessage) (interface{}, error) {
+	var p deleteBranchesParams
+	if err := rpc.DecodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if len(p.Branches) == 0 {
+		return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: "branches required"}
+	}
+	if p.Soft && !p.Remote && !p.All {
+		return nil, &rpc.Error{Code: rpc.CodeInvalidParams, Message: "soft requires remote or all"}
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return nil, err
+	}
+	refreshDefaultBranch(g)
+
+	events := make(chan progress.Event[BranchResult])
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for ev := range events {
+			n := rpcProgress{Method: "deleteBranches", Event: ev.Kind, Done: ev.Done, Failed: ev.Failed, Total: ev.Total, Branch: ev.Item}
+			if err := conn.Notify("progress", n); err != nil {
+				log.Debug("Failed to send progress: %v", err)
+			}
+		}
+	}()
+	opts := DeleteOptions{
+		Branches: p.Branches,
+		Force:    p.Force,
+		Remote:   p.Remote,
+		All:      p.All,
+		Soft:     p.Soft,
+		Events:   events,
+	}
+
+	created := branchCreations(g)
+	res, err := Delete(g, opts)
+	// Every notification goes out before the response
+	close(events)
+	<-sent
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete branches: %w", err)
+	}
+	queueFailures(g, res.Failed, opts)
+	recordRunDeletions(g, journal.NewRun(), res.Deleted, created)
+	return res, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+)
+
+// runNoteTarget returns the commit prune runs are recorded on: origin's
+// default branch, which every clone has, or else the local one or HEAD
+func runNoteTarget(g *git.Git) (string, error) {
+	name := defaultBranchOrConfig(g)
+	for _, ref := range []string{"refs/remotes/origin/" + name, "refs/heads/" + name, "HEAD"} {
+		if hash, err := g.ResolveRef(ref); err == nil {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("no commit to record the run on")
+}
+
+// recordRunNote stores res as a note in git.RunNotesRef, for a later
+// --diff-since-notes here or in another clone
+func recordRunNote(g *git.Git, res *PruneResult) error {
+	target, err := runNoteTarget(g)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prune run: %w", err)
+	}
+	return g.AddRunNote(target, string(data))
+}
+
+// lastRunNote returns the newest prune run recorded in git.RunNotesRef.
+// Notes that aren't prune runs are skipped.
+func lastRunNote(g *git.Git) (*PruneResult, error) {
+	notes, err := g.RunNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var last *PruneResult
+	for _, note := range notes {
+		var res PruneResult
+		if err := json.Unmarshal([]byte(note), &res); err != nil || res.Time.IsZero() {
+			continue
+		}
+		if last == nil || res.Time.After(last.Time) {
+			last = &res
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("no prune run recorded in %s; record one with prune --notes, or fetch it with 'git fetch origin %s:%s'", git.RunNotesRef, git.RunNotesRef, git.RunNotesRef)
+	}
+	return last, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+)
+
+// iKnowWhatIAmDoing allows changes where safe mode would make the run a
+// dry run
+var iKnowWhatIAmDoing bool
+
+// unsafeReason returns why changing the repository of g is risky enough to
+// only do on request: running as root, or in a repository another user or
+// the system owns, as on shared servers. It's "" otherwise.
+func unsafeReason(g *git.Git) string {
+	euid := os.Geteuid()
+	if euid == 0 {
+		return "running as root"
+	}
+	if euid < 0 {
+		return "" // No user IDs, e.g. on Windows
+	}
+
+	dir, err := g.CommonDir()
+	if err != nil {
+		log.Debug("Couldn't find the git directory to check its owner: %v", err)
+		return ""
+	}
+	owner, ok := utils.FileOwner(dir)
+	if !ok || owner == euid {
+		return ""
+	}
+	if owner == 0 {
+		return "the repository is owned by root"
+	}
+	name := strconv.Itoa(owner)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	return fmt.Sprintf("the repository is owned by %s", name)
+}
+
+// enterSafeMode makes the run a dry run of g when changing it is risky and
+// --i-know-what-i-am-doing wasn't passed. Git refuses every change from then
+// on, so commands without a dry run fail before touching anything.
+func enterSafeMode(g *git.Git) {
+	reason := unsafeReason(g)
+	if reason == "" {
+		return
+	}
+	if iKnowWhatIAmDoing {
+		log.Warn("Making changes although %s (--i-know-what-i-am-doing)", reason)
+		return
+	}
+	log.Warn("DRY RUN: %s, so nothing in %s will be changed. Pass --i-know-what-i-am-doing to make changes anyway.", reason, g.WorkDir())
+	g.SetReadOnly(reason + "; pass --i-know-what-i-am-doing to make changes")
+}
+
+// safeDryRun reports whether a command on g runs as a dry run, because
+// dryRun was asked for or safe mode is on
+func safeDryRun(g *git.Git, dryRun bool) bool {
+	return dryRun || g.ReadOnly() != ""
+}
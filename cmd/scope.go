@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// TouchFilter selects branches that modify a path in the repository
+type TouchFilter struct {
+	Path string // Repository-relative path the branches must modify
+	Base string // Branch unique commits are measured against
+
+	// BranchPaths maps branch name patterns to the subpath they own, so
+	// branches without unique commits (e.g. merged ones) still match
+	BranchPaths map[string]string
+}
+
+// newTouchFilter creates a filter for --touches using the configured
+// branch path templates
+func newTouchFilter(g *git.Git, p string) (*TouchFilter, error) {
+	p = strings.Trim(path.Clean(p), "/")
+	if err := git.ValidatePathspec(p); err != nil {
+		return nil, err
+	}
+	return &TouchFilter{Path: p, Base: defaultBranchOrConfig(g), BranchPaths: cfg.BranchPaths}, nil
+}
+
+// Match reports whether b owns or modifies the filter's path
+func (f *TouchFilter) Match(g *git.Git, b git.GitBranch) (bool, error) {
+	if owned, ok := f.ownedPath(b.Name); ok && f.contains(owned) {
+		return true, nil
+	}
+	return g.BranchTouches(b.Reference, f.Base, f.Path)
+}
+
+// Filter returns the branches matching f
+func (f *TouchFilter) Filter(g *git.Git, branches []git.GitBranch) []git.GitBranch {
+	var matched []git.GitBranch
+	for _, b := range branches {
+		ok, err := f.Match(g, b)
+		if err != nil {
+			log.Debug("Failed to check whether %s touches %s: %v", b.Name, f.Path, err)
+			continue
+		}
+		if ok {
+			matched = append(matched, b)
+		}
+	}
+	return matched
+}
+
+// ownedPath returns the subpath a branch owns according to BranchPaths.
+// When several patterns match, the first in sorted order wins, so the
+// answer doesn't change from one run to the next.
+func (f *TouchFilter) ownedPath(branch string) (string, bool) {
+	patterns := make([]string, 0, len(f.BranchPaths))
+	for pattern := range f.BranchPaths {
+		patterns = append(patterns, pattern)
+	}
+	slices.Sort(patterns)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return strings.Trim(path.Clean(f.BranchPaths[pattern]), "/"), true
+		}
+	}
+	return "", false
+}
+
+// contains reports whether p lies within the filter's path
+func (f *TouchFilter) contains(p string) bool {
+	return f.Path == "." || p == f.Path || strings.HasPrefix(p, f.Path+"/")
+}
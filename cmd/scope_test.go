@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnedPath(t *testing.T) {
+	f := &TouchFilter{BranchPaths: map[string]string{
+		"payments/*":   "/services/payments/",
+		"payments/ui*": "web/payments",
+		"*/docs":       "docs",
+		"search/*":     "services/search",
+	}}
+
+	tests := []struct {
+		branch string
+		want   string
+		wantOK bool
+	}{
+		{branch: "search/ranking", want: "services/search", wantOK: true},
+		{branch: "payments/refunds", want: "services/payments", wantOK: true},
+		// Matched by both payments patterns; the first in sorted order wins
+		{branch: "payments/ui-fixes", want: "services/payments", wantOK: true},
+		// "*/docs" sorts before "payments/*"
+		{branch: "payments/docs", want: "docs", wantOK: true},
+		{branch: "feature/other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			// Map order varies; the answer must not
+			for i := 0; i < 20; i++ {
+				got, ok := f.ownedPath(tt.branch)
+				assert.Equal(t, tt.wantOK, ok)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTouchFilterContains(t *testing.T) {
+	f := &TouchFilter{Path: "services/payments"}
+	assert.True(t, f.contains("services/payments"))
+	assert.True(t, f.contains("services/payments/api"))
+	assert.False(t, f.contains("services/payments-v2"))
+	assert.False(t, f.contains("services"))
+
+	assert.True(t, (&TouchFilter{Path: "."}).contains("anything"))
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// RefChange is a ref deletion a run would make, in the form used by
+// `git update-ref --stdin`
+type RefChange struct {
+	Ref      string `json:"ref"`
+	OldValue string `json:"oldValue"`
+	// Push is the command that changes the remote; update-ref only
+	// updates the local remote-tracking ref
+	Push string `json:"push,omitempty"`
+}
+
+// ScriptResult is the structured result of an --update-ref-script run
+type ScriptResult struct {
+	Changes []RefChange    `json:"changes"`
+	Skipped []BranchResult `json:"skipped"`
+}
+
+// planRefChanges resolves the exact ref changes deleting branches would make.
+// Branches that can't be resolved are skipped with the reason.
+func planRefChanges(g *git.Git, branches []BranchResult, soft bool) *ScriptResult {
+	res := &ScriptResult{}
+	for _, b := range branches {
+		change := RefChange{Ref: "refs/heads/" + b.Name}
+		if b.Remote {
+			change.Ref = "refs/remotes/origin/" + b.Name
+		}
+
+		oid, err := g.ResolveRef(change.Ref)
+		if err != nil {
+			log.Debug("Failed to resolve %s: %v", change.Ref, err)
+			b.Reason = change.Ref + " does not exist"
+			res.Skipped = append(res.Skipped, b)
+			continue
+		}
+		change.OldValue = oid
+
+		if b.Remote {
+			change.Push = fmt.Sprintf("git push origin --delete %s", b.Name)
+			if soft {
+				change.Push = fmt.Sprintf("git push origin %s:refs/heads/%s%s && %s", oid, git.ArchivePrefix, b.Name, change.Push)
+			}
+		}
+		res.Changes = append(res.Changes, change)
+	}
+	return res
+}
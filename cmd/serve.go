@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+// webhookSecretEnv holds the webhook secret; a flag would show it in ps
+const webhookSecretEnv = "GBD_WEBHOOK_SECRET"
+
+// commitHashPattern matches full or abbreviated commit hashes
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+var serveListen string
+
+func init() {
+	serveCmd := newServeCmd()
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "Address to listen on")
+}
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Delete local branches when their pull request merges",
+		Long: `Serve a webhook at /webhook that receives pull request events from GitHub
+and merge request events from GitLab, and deletes the local branches of
+merged requests in this clone. Keeps long-lived checkout machines, such as
+CI runners and review boxes, clean.
+
+Deliveries are authenticated with the secret in ` + webhookSecretEnv + `: set it as
+the GitHub webhook secret or the GitLab secret token. A delivery already
+handled is rejected as a replay, even under another delivery ID. Events
+for other repositories than origin's are ignored.
+
+A local branch named like the request's source branch, or tracking it, is
+only deleted when every commit on it was part of the merged request, so
+local work made after the merge is never lost. It goes to the trash first
+and the deletion is recorded in the audit log.`,
+		Example: `  GBD_WEBHOOK_SECRET=... git-branch-delete serve --listen :8080`,
+		Args:    cobra.NoArgs,
+		RunE:    runServe,
+	}
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		return fmt.Errorf("%s must be set to authenticate webhook deliveries", webhookSecretEnv)
+	}
+
+	lockWholeRun = false
+	// Fail early when not started in a repository
+	if _, err := openRepo(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", webhookHandler(secret))
+	server := &http.Server{
+		Addr:              serveListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Payloads can be large, and deleting branches takes a while
+		ReadTimeout:  time.Minute,
+		WriteTimeout: 5 * time.Minute,
+		IdleTimeout:  2 * time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdown); err != nil {
+			log.Debug("Failed to shut down: %v", err)
+		}
+	}()
+
+	log.Info("Listening for webhooks on http://%s/webhook", serveListen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// webhookHandler answers webhook deliveries with a WebhookResult. Deliveries
+// are handled one at a time so deletions don't race. A payload handled
+// before is rejected as a replay, whatever its delivery ID; failed ones can
+// be redelivered.
+func webhookHandler(secret string) http.Handler {
+	var mu sync.Mutex
+	var handled webhook.Deliveries
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		ev, err := webhook.Parse(r, secret)
+		if errors.Is(err, webhook.ErrUnauthorized) {
+			log.Warn("Rejected webhook delivery from %s: %v", r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if ev == nil {
+			writeJSON(w, &WebhookResult{Ignored: "not a merged pull request"})
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// The delivery ID isn't signed, so replays are recognized by their
+		// payload
+		if handled.Seen(ev.Payload) {
+			log.Warn("Rejected replayed webhook delivery %s from %s", ev.Delivery, r.RemoteAddr)
+			http.Error(w, "delivery was already handled", http.StatusConflict)
+			return
+		}
+
+		g, err := openRepo()
+		if err == nil {
+			var res *WebhookResult
+			if res, err = HandleMerged(g, ev); err == nil {
+				handled.Add(ev.Payload)
+				writeJSON(w, res)
+				return
+			}
+		}
+		log.Error("Failed to handle %s request #%d: %v", ev.Provider, ev.Number, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	})
+}
+
+// writeJSON sends v as the JSON body of a successful response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debug("Failed to write response: %v", err)
+	}
+}
+
+// HandleMerged deletes the local branches of a merged request: those named
+// like its source branch or tracking it on origin. Branches with commits the
+// request didn't merge are skipped, as are the current and protected ones.
+func HandleMerged(g *git.Git, ev *webhook.Event) (*WebhookResult, error) {
+	res := &WebhookResult{Event: ev}
+
+	repo, err := g.RemoteRepoPath()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(repo, ev.Repository) {
+		res.Ignored = fmt.Sprintf("event is for %s, origin is %s", ev.Repository, repo)
+		return res, nil
+	}
+	if !commitHashPattern.MatchString(ev.Head) {
+		return nil, fmt.Errorf("invalid head commit %q", ev.Head)
+	}
+
+	branches, err := g.ListLocalBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, b := range branches {
+		if b.Name != ev.Branch && b.TrackingBranch != "origin/"+ev.Branch {
+			continue
+		}
+		skip := func(reason string) {
+			res.Skipped = append(res.Skipped, BranchResult{Name: b.Name, Commit: b.CommitHash, Reason: reason})
+		}
+
+		switch contained, err := g.ContainedIn(b.Reference, ev.Head); {
+		case b.IsCurrent:
+			skip("checked out")
+		case g.IsProtected(b.Name, false):
+			skip(g.ProtectionReason(b.Name, false))
+		case err != nil:
+			skip("merged commit not in this clone; fetch to clean it up")
+		case !contained:
+			skip("has commits that were not merged")
+		default:
+			names = append(names, b.Name)
+		}
+	}
+	if len(names) == 0 {
+		return res, nil
+	}
+
+	// Every commit is in the merged request, which squash and rebase merges
+	// leave unmerged as far as git can tell
+	created := branchCreations(g)
+	deleted, err := Delete(g, DeleteOptions{Branches: names, Force: true})
+	if err != nil {
+		return nil, err
+	}
+	recordRunDeletions(g, journal.NewRun(), deleted.Deleted, created)
+
+	for _, b := range deleted.Deleted {
+		log.Info("Deleted %s, merged in %s request #%d", b.Name, ev.Provider, ev.Number)
+	}
+	res.Deleted, res.Failed = deleted.Deleted, deleted.Failed
+	return res, nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleMerged(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Merged("feature/done")
+	r.Merged("feature/current")
+	r.Tracked("feature/more")
+	done := r.Git("rev-parse", "feature/done")
+	current := r.Git("rev-parse", "feature/current")
+	more := r.Git("rev-parse", "feature/more")
+	// Work added after the request merged
+	r.Git("checkout", "--quiet", "feature/more")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "After the merge")
+	r.Git("remote", "set-url", "origin", "git@github.com:acme/app.git")
+	r.Git("checkout", "--quiet", "feature/current")
+
+	merged := func(branch, head string) *webhook.Event {
+		return &webhook.Event{Provider: webhook.GitHub, Repository: "acme/app", Number: 1, Branch: branch, Head: head}
+	}
+
+	t.Run("other repository", func(t *testing.T) {
+		ev := merged("feature/done", done)
+		ev.Repository = "acme/other"
+		res, err := HandleMerged(g, ev)
+		require.NoError(t, err)
+		assert.Contains(t, res.Ignored, "acme/other")
+		assert.True(t, r.HasBranch("feature/done"))
+	})
+
+	t.Run("invalid head", func(t *testing.T) {
+		_, err := HandleMerged(g, merged("feature/done", "HEAD~1"))
+		assert.Error(t, err)
+		assert.True(t, r.HasBranch("feature/done"))
+	})
+
+	t.Run("unmerged commits", func(t *testing.T) {
+		res, err := HandleMerged(g, merged("feature/more", more))
+		require.NoError(t, err)
+		assert.Empty(t, res.Deleted)
+		require.Len(t, res.Skipped, 1)
+		assert.Equal(t, "has commits that were not merged", res.Skipped[0].Reason)
+		assert.True(t, r.HasBranch("feature/more"))
+	})
+
+	t.Run("checked out", func(t *testing.T) {
+		res, err := HandleMerged(g, merged("feature/current", current))
+		require.NoError(t, err)
+		assert.Empty(t, res.Deleted)
+		require.Len(t, res.Skipped, 1)
+		assert.Equal(t, "checked out", res.Skipped[0].Reason)
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		res, err := HandleMerged(g, merged("feature/done", done))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature/done"}, resultNames(res.Deleted))
+		assert.False(t, r.HasBranch("feature/done"))
+	})
+}
+
+func TestWebhookHandlerReplay(t *testing.T) {
+	r, _ := newTestRepo(t)
+	r.Merged("feature/done")
+	head := r.Git("rev-parse", "feature/done")
+	r.Git("remote", "set-url", "origin", "git@gitlab.com:acme/app.git")
+
+	savedDir, savedOverride := repoDir, iKnowWhatIAmDoing
+	// Tests may run as root, whose runs are otherwise dry
+	repoDir, iKnowWhatIAmDoing = r.Dir, true
+	defer func() { repoDir, iKnowWhatIAmDoing = savedDir, savedOverride }()
+
+	handler := webhookHandler("s3cret")
+	body := fmt.Sprintf(`{"project":{"path_with_namespace":"acme/app"},"object_attributes":{"iid":3,"action":"merge","source_branch":"feature/done","last_commit":{"id":%q}}}`, head)
+	deliver := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+		req.Header.Set("X-Gitlab-Token", "s3cret")
+		if id != "" {
+			req.Header.Set("X-Gitlab-Event-UUID", id)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Deliveries without an ID are refused, and don't stand in the way of
+	// later ones
+	assert.Equal(t, http.StatusBadRequest, deliver("", body).Code)
+	assert.Equal(t, http.StatusBadRequest, deliver("", body).Code)
+	assert.True(t, r.HasBranch("feature/done"))
+
+	first := deliver("u-1", body)
+	assert.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Contains(t, first.Body.String(), `"name":"feature/done"`)
+	assert.False(t, r.HasBranch("feature/done"))
+
+	assert.Equal(t, http.StatusConflict, deliver("u-1", body).Code)
+	// The ID isn't covered by the secret; a fresh one doesn't make a replay new
+	assert.Equal(t, http.StatusConflict, deliver("u-2", body).Code)
+	// Another delivery about the same merge is handled; nothing is left to delete
+	other := strings.Replace(body, `"iid":3`, `"iid":3,"note":"resent"`, 1)
+	assert.Equal(t, http.StatusOK, deliver("u-3", other).Code)
+}
+
+func TestWebhookHandlerGitHubReplay(t *testing.T) {
+	r, _ := newTestRepo(t)
+	r.Merged("feature/done")
+	head := r.Git("rev-parse", "feature/done")
+	r.Git("remote", "set-url", "origin", "git@github.com:acme/app.git")
+
+	savedDir, savedOverride := repoDir, iKnowWhatIAmDoing
+	repoDir, iKnowWhatIAmDoing = r.Dir, true
+	defer func() { repoDir, iKnowWhatIAmDoing = savedDir, savedOverride }()
+
+	handler := webhookHandler("s3cret")
+	body := fmt.Sprintf(`{"action":"closed","pull_request":{"number":7,"merged":true,"head":{"ref":"feature/done","sha":%q}},"repository":{"full_name":"acme/app"}}`, head)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	deliver := func(id string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		req.Header.Set("X-Hub-Signature-256", signature)
+		if id != "" {
+			req.Header.Set("X-GitHub-Delivery", id)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, deliver(""))
+	assert.Equal(t, http.StatusOK, deliver("d-1"))
+	assert.False(t, r.HasBranch("feature/done"))
+
+	// A captured signed body resent under a made-up ID is still a replay
+	assert.Equal(t, http.StatusConflict, deliver("d-2"))
+	assert.Equal(t, http.StatusBadRequest, deliver(""))
+}
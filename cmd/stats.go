@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/spf13/cobra"
+)
+
+// activityWeeks is how many weeks of branch activity stats shows
+const activityWeeks = 12
+
+var (
+	statsLeaderboard bool
+	statsAnonymize   bool
+	statsWeight      bool
+)
+
+// heaviestBranches is how many cleanup-ready branches stats --weight lists
+const heaviestBranches = 10
+
+// StatsOptions controls what Stats computes
+type StatsOptions struct {
+	// Leaderboard ranks authors by the cleanup-ready branches they left behind
+	Leaderboard bool
+	// Anonymize replaces author names and emails with pseudonyms keyed by
+	// a secret kept with the config, stable across runs
+	Anonymize bool
+	// Weight ranks cleanup-ready branches by the history unique to them
+	Weight bool
+	// Base is the branch weights are measured against
+	Base string
+}
+
+func init() {
+	rootCmd.AddCommand(newStatsCmd())
+}
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show branch statistics",
+		Long: `Show how many branches exist and how many are ready for cleanup.
+
+A sparkline compares the branches created (from the reflogs) with the
+branches deleted (from the audit log of this tool) per week over the last
+12 weeks, to show whether cleanup keeps pace with new branches.
+
+With --leaderboard, local branches that are stale or merged but not yet
+deleted are grouped by the author of their latest commit.
+
+With --weight, the cleanup-ready branches holding the most history the
+default branch doesn't have are listed, heaviest first: deleting those and
+running git gc reclaims the most space.`,
+		Example: `  git-branch-delete stats
+  git-branch-delete stats --leaderboard
+  git-branch-delete stats --leaderboard --anonymize
+  git-branch-delete stats --weight`,
+		RunE: runStats,
+	}
+
+	cmd.Flags().BoolVar(&statsLeaderboard, "leaderboard", false, "Rank authors by stale and merged branches left behind")
+	cmd.Flags().BoolVar(&statsAnonymize, "anonymize", false, "Show pseudonyms instead of author names in the leaderboard")
+	cmd.Flags().BoolVarP(&statsWeight, "weight", "w", false, "List the cleanup-ready branches with the most unique history")
+
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	res, err := Stats(g, StatsOptions{
+		Leaderboard: statsLeaderboard,
+		Anonymize:   statsAnonymize,
+		Weight:      statsWeight,
+		Base:        defaultBranchOrConfig(g),
+	})
+	if err != nil {
+		return err
+	}
+
+	return newPresenter(os.Stdout).stats(res)
+}
+
+// Stats counts branches by state and optionally builds the author leaderboard
+func Stats(g *git.Git, opts StatsOptions) (*StatsResult, error) {
+	branches, err := g.ListBranches()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &StatsResult{}
+	var cleanup []git.GitBranch
+	for _, b := range branches {
+		if b.IsRemote {
+			res.Remote++
+			continue
+		}
+		res.Local++
+		if b.IsMerged {
+			res.Merged++
+		}
+		if b.IsStale {
+			res.Stale++
+		}
+		if (b.IsMerged || b.IsStale) && !b.IsDefault && !b.IsCurrent {
+			cleanup = append(cleanup, b)
+		}
+	}
+
+	res.Activity = branchActivity(g, time.Now(), activityWeeks)
+	if opts.Weight {
+		res.Heaviest = weighBranches(g, cleanup, opts.Base, heaviestBranches)
+	}
+
+	if !opts.Leaderboard || len(cleanup) == 0 {
+		return res, nil
+	}
+
+	authors, err := g.BranchAuthors()
+	if err != nil {
+		return nil, err
+	}
+
+	var anonKey []byte
+	if opts.Anonymize {
+		if anonKey, err = anonymizeKey(); err != nil {
+			return nil, err
+		}
+	}
+	entries := make(map[string]*LeaderboardEntry)
+	for _, b := range cleanup {
+		author := authors[b.Name]
+		key := strings.ToLower(author.Email)
+
+		entry, ok := entries[key]
+		if !ok {
+			entry = &LeaderboardEntry{Author: author.Name, Email: author.Email}
+			if opts.Anonymize {
+				entry.Author = anonymizeEmail(anonKey, key)
+				entry.Email = ""
+			}
+			entries[key] = entry
+		}
+
+		entry.Branches = append(entry.Branches, b.Name)
+		if b.IsStale {
+			entry.Stale++
+		}
+		if b.IsMerged {
+			entry.Merged++
+		}
+	}
+
+	for _, entry := range entries {
+		res.Leaderboard = append(res.Leaderboard, *entry)
+	}
+	sort.Slice(res.Leaderboard, func(i, j int) bool {
+		a, b := res.Leaderboard[i], res.Leaderboard[j]
+		if len(a.Branches) != len(b.Branches) {
+			return len(a.Branches) > len(b.Branches)
+		}
+		return a.Author < b.Author
+	})
+
+	log.Debug("Built leaderboard of %d authors from %d branches", len(res.Leaderboard), len(cleanup))
+	return res, nil
+}
+
+// branchActivity counts the branches created and deleted in each of the
+// last weeks before now. Creations come from the reflogs of existing
+// branches and, for deleted ones, from the audit log.
+func branchActivity(g *git.Git, now time.Time, weeks int) []ActivityWeek {
+	var created, deleted []time.Time
+	creations, err := g.BranchCreations()
+	if err != nil {
+		log.Debug("Failed to read branch creation times: %v", err)
+	}
+	for _, t := range creations {
+		created = append(created, t)
+	}
+
+	entries, err := loadAuditLog(g)
+	if err != nil {
+		log.Debug("Failed to read audit log: %v", err)
+	}
+	for _, e := range entries {
+		deleted = append(deleted, e.Time)
+		if e.Created != nil {
+			created = append(created, *e.Created)
+		}
+	}
+
+	activity := make([]ActivityWeek, weeks)
+	start := now.AddDate(0, 0, -7*weeks)
+	for i := range activity {
+		activity[i].Start = start.AddDate(0, 0, 7*i)
+	}
+	week := func(t time.Time) int {
+		if t.Before(start) || t.After(now) {
+			return -1
+		}
+		return min(int(t.Sub(start)/(7*24*time.Hour)), weeks-1)
+	}
+	for _, t := range created {
+		if i := week(t); i >= 0 {
+			activity[i].Created++
+		}
+	}
+	for _, t := range deleted {
+		if i := week(t); i >= 0 {
+			activity[i].Deleted++
+		}
+	}
+	return activity
+}
+
+// anonymizeKeyFile holds the secret key of --anonymize pseudonyms, next to
+// the config file
+const anonymizeKeyFile = "anonymize.key"
+
+// anonymizeKeySize is the length of the anonymization key in bytes
+const anonymizeKeySize = 32
+
+// anonymizeKey returns the secret key pseudonyms are derived from, created
+// on first use. Without it, a pseudonym could be traced back by hashing
+// candidate emails. When it can't be kept, a key for this run only is
+// returned, so pseudonyms differ from one run to the next.
+func anonymizeKey() ([]byte, error) {
+	var path string
+	if configPath, err := config.Path(); err == nil {
+		path = filepath.Join(filepath.Dir(configPath), anonymizeKeyFile)
+		if stored, err := os.ReadFile(path); err == nil && len(stored) == anonymizeKeySize {
+			return stored, nil
+		}
+	}
+
+	key := make([]byte, anonymizeKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to create anonymization key: %w", err)
+	}
+	if path == "" {
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Debug("Using a one-off anonymization key: %v", err)
+		return key, nil
+	}
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		log.Debug("Using a one-off anonymization key: %v", err)
+	}
+	return key, nil
+}
+
+// anonymizeEmail returns a pseudonym for an email address, stable for the
+// same key
+func anonymizeEmail(key []byte, email string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(email))
+	return "author-" + hex.EncodeToString(mac.Sum(nil))[:8]
+}
+
+// weighBranches returns the n branches with the most history base doesn't
+// have, heaviest first. Branches whose weight can't be computed are left out.
+func weighBranches(g *git.Git, branches []git.GitBranch, base string, n int) []WeightedBranch {
+	var weighed []WeightedBranch
+	for _, b := range branches {
+		w, err := g.BranchWeight(b.Reference, base)
+		if err != nil {
+			log.Debug("Failed to compute weight of %s: %v", b.Name, err)
+			continue
+		}
+		weighed = append(weighed, WeightedBranch{Name: b.Name, Weight: w})
+	}
+
+	sort.SliceStable(weighed, func(i, j int) bool {
+		if weighed[i].Weight.DiskSize != weighed[j].Weight.DiskSize {
+			return weighed[i].Weight.DiskSize > weighed[j].Weight.DiskSize
+		}
+		return weighed[i].Weight.Commits > weighed[j].Weight.Commits
+	})
+	if len(weighed) > n {
+		weighed = weighed[:n]
+	}
+	return weighed
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeighBranches(t *testing.T) {
+	r, g := newTestRepo(t)
+	r.Local("feature/light")
+	r.Git("checkout", "--quiet", "-b", "feature/heavy", "main")
+	for i := 0; i < 3; i++ {
+		r.Git("commit", "--quiet", "--allow-empty", "-m", "More work")
+	}
+	r.Git("checkout", "--quiet", "main")
+
+	branches := []git.GitBranch{
+		{Name: "feature/light", Reference: "refs/heads/feature/light"},
+		{Name: "feature/heavy", Reference: "refs/heads/feature/heavy"},
+		{Name: "feature/missing", Reference: "refs/heads/feature/missing"},
+	}
+	weighed := weighBranches(g, branches, "refs/heads/main", 10)
+	require.Len(t, weighed, 2)
+	assert.Equal(t, "feature/heavy", weighed[0].Name)
+	assert.Equal(t, 3, weighed[0].Weight.Commits)
+	assert.Equal(t, "feature/light", weighed[1].Name)
+
+	assert.Len(t, weighBranches(g, branches, "refs/heads/main", 1), 1)
+
+}
+
+func TestAnonymizeKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	key, err := anonymizeKey()
+	require.NoError(t, err)
+	assert.Len(t, key, anonymizeKeySize)
+
+	// Kept for the next runs, readable only by the user
+	again, err := anonymizeKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+	configPath, err := config.Path()
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(filepath.Dir(configPath), anonymizeKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestAnonymizeEmail(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	other := []byte("fedcba9876543210fedcba9876543210")
+
+	name := anonymizeEmail(key, "dev@example.com")
+	assert.Regexp(t, `^author-[0-9a-f]{8}$`, name)
+	assert.Equal(t, name, anonymizeEmail(key, "dev@example.com"))
+	assert.NotEqual(t, name, anonymizeEmail(key, "ops@example.com"))
+
+	// Another install's key gives other pseudonyms, and none is the plain
+	// hash of the email
+	assert.NotEqual(t, name, anonymizeEmail(other, "dev@example.com"))
+	sum := sha256.Sum256([]byte("dev@example.com"))
+	assert.NotEqual(t, "author-"+hex.EncodeToString(sum[:])[:8], name)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sweepGroup  string
+	sweepDryRun bool
+	sweepForce  bool
+)
+
+// SweepOptions controls the behavior of Sweep
+type SweepOptions struct {
+	// Group is the repository group to sweep; empty sweeps every group
+	Group string
+
+	// DryRun reports the candidates without deleting anything
+	DryRun bool
+
+	// Select chooses which stale branches of a repository to delete. When
+	// nil, every stale branch is deleted.
+	Select func(candidates []git.GitBranch) ([]git.GitBranch, error)
+}
+
+func init() {
+	sweepCmd := newSweepCmd()
+	rootCmd.AddCommand(sweepCmd)
+
+	sweepCmd.Flags().StringVarP(&sweepGroup, "group", "g", "", "Only sweep this repository group (default: every group)")
+	sweepCmd.Flags().BoolVar(&sweepDryRun, "dry-run", false, "Show what would be pruned without deleting")
+	sweepCmd.Flags().BoolVarP(&sweepForce, "force", "f", false, "Delete stale branches without asking for each repository")
+}
+
+func newSweepCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sweep",
+		Short: "Prune stale branches across groups of repositories",
+		Long: `Prune stale branches in every repository of the groups configured under
+repoGroups. Each group applies its own protected branch patterns on top of
+the global ones, and can keep branches younger than minAgeDays.`,
+		Example: `  git-branch-delete sweep --dry-run
+  git-branch-delete sweep --group work
+  git-branch-delete sweep --group oss --force`,
+		Args: cobra.NoArgs,
+		RunE: runSweep,
+	}
+}
+
+func runSweep(cmd *cobra.Command, args []string) error {
+	opts := SweepOptions{Group: sweepGroup, DryRun: sweepDryRun}
+	if !sweepForce && !sweepDryRun {
+		opts.Select = selectPruneBranches
+	}
+	if !sweepDryRun {
+		ui.ShowBanner(cfg.Confirmation)
+	}
+
+	res, err := Sweep(cfg.RepoGroups, opts)
+	if err != nil {
+		return err
+	}
+	newPresenter(os.Stdout).sweep(res)
+
+	failed := 0
+	for _, r := range res.Repos {
+		if r.Error != "" || (r.Prune != nil && len(r.Prune.Failed) > 0) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("sweep failed in %d repositor(ies)", failed)
+	}
+	return nil
+}
+
+// Sweep prunes the stale branches of every repository in groups, or only
+// in opts.Group. A repository that can't be swept doesn't stop the others.
+func Sweep(groups map[string]config.RepoGroup, opts SweepOptions) (*SweepResult, error) {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no repository groups configured; add repoGroups to %s", defaultConfigPath())
+	}
+	if opts.Group != "" {
+		if _, ok := groups[opts.Group]; !ok {
+			return nil, fmt.Errorf("unknown repository group %q (configured: %s)", opts.Group, strings.Join(names, ", "))
+		}
+		names = []string{opts.Group}
+	}
+
+	res := &SweepResult{DryRun: opts.DryRun}
+	for _, name := range names {
+		group := groups[name]
+		for _, repo := range group.Repos {
+			r := SweepRepoResult{Group: name, Repo: repo}
+			prune, err := sweepRepo(utils.ExpandPath(repo), group, opts)
+			if err != nil {
+				log.Debug("Failed to sweep %s: %v", repo, err)
+				r.Error = err.Error()
+			}
+			r.Prune = prune
+			res.Repos = append(res.Repos, r)
+		}
+	}
+	return res, nil
+}
+
+// sweepRepo prunes one repository of group, with the group's protected
+// patterns added to the configured ones
+func sweepRepo(dir string, group config.RepoGroup, opts SweepOptions) (*PruneResult, error) {
+	g, err := openRepoIn(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.ProtectedBranches) > 0 {
+		protection := g.Protection()
+		g.SetProtection(git.Protection{
+			Local:  append(slices.Clone(protection.Local), group.ProtectedBranches...),
+			Remote: append(slices.Clone(protection.Remote), group.ProtectedBranches...),
+			Pinned: protection.Pinned,
+		})
+	}
+	logPreflight(g)
+	refreshDefaultBranch(g)
+
+	pruneOpts := PruneOptions{
+		DryRun: safeDryRun(g, opts.DryRun),
+		MinAge: time.Duration(group.MinAgeDays) * 24 * time.Hour,
+	}
+	if opts.Select != nil {
+		pruneOpts.Select = func(candidates []git.GitBranch) ([]git.GitBranch, error) {
+			log.Info("%s:", dir)
+			return opts.Select(candidates)
+		}
+	} else if !pruneOpts.DryRun {
+		pruneOpts.Select = withForceConfirmation(g, nil)
+	}
+
+	created := branchCreations(g)
+	res, err := Prune(g, pruneOpts)
+	if err != nil {
+		return nil, err
+	}
+	queueFailures(g, res.Failed, DeleteOptions{})
+	recordDeletions(g, res.Deleted, created)
+	return res, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSweepRepo returns a repository with a merged branch deleted from
+// origin for each name, ready to be pruned
+func newSweepRepo(t *testing.T, names ...string) *testutil.Remote {
+	t.Helper()
+	r := testutil.NewRemote(t)
+	for _, name := range names {
+		r.Merged(name)
+		r.DeleteOnOrigin(name)
+	}
+	r.Git("fetch", "--quiet", "--prune", "origin")
+	return r
+}
+
+func TestSweep(t *testing.T) {
+	saved, savedOverride := cfg, iKnowWhatIAmDoing
+	defer func() { cfg, iKnowWhatIAmDoing = saved, savedOverride }()
+	cfg, iKnowWhatIAmDoing = config.DefaultConfig(), true
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	work := newSweepRepo(t, "feature/done", "release/1.0")
+	oss := newSweepRepo(t, "feature/other")
+	missing := filepath.Join(t.TempDir(), "missing")
+	groups := map[string]config.RepoGroup{
+		"work": {Repos: []string{work.Dir}, ProtectedBranches: []string{"release/*"}},
+		"oss":  {Repos: []string{missing, oss.Dir}},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		res, err := Sweep(groups, SweepOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, res.DryRun)
+
+		// Groups in name order; a broken repository doesn't stop the others
+		require.Len(t, res.Repos, 3)
+		assert.Equal(t, []string{missing, oss.Dir, work.Dir}, []string{res.Repos[0].Repo, res.Repos[1].Repo, res.Repos[2].Repo})
+		assert.NotEmpty(t, res.Repos[0].Error)
+		assert.Nil(t, res.Repos[0].Prune)
+		assert.Equal(t, []string{"feature/other"}, resultNames(res.Repos[1].Prune.Candidates))
+
+		// The group's protected patterns apply on top of the global ones
+		assert.Equal(t, []string{"feature/done"}, resultNames(res.Repos[2].Prune.Candidates))
+		assert.True(t, work.HasBranch("feature/done"))
+		assert.True(t, oss.HasBranch("feature/other"))
+	})
+
+	t.Run("selected", func(t *testing.T) {
+		var offered [][]string
+		res, err := Sweep(groups, SweepOptions{
+			Group: "work",
+			Select: func(candidates []git.GitBranch) ([]git.GitBranch, error) {
+				var names []string
+				for _, b := range candidates {
+					names = append(names, b.Name)
+				}
+				offered = append(offered, names)
+				return nil, nil
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Repos, 1)
+		assert.Equal(t, [][]string{{"feature/done"}}, offered)
+		assert.Empty(t, res.Repos[0].Prune.Deleted)
+		assert.True(t, work.HasBranch("feature/done"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		res, err := Sweep(groups, SweepOptions{})
+		require.NoError(t, err)
+		require.Len(t, res.Repos, 3)
+		assert.Equal(t, []string{"feature/other"}, resultNames(res.Repos[1].Prune.Deleted))
+		assert.Equal(t, []string{"feature/done"}, resultNames(res.Repos[2].Prune.Deleted))
+		assert.False(t, oss.HasBranch("feature/other"))
+		assert.False(t, work.HasBranch("feature/done"))
+		assert.True(t, work.HasBranch("release/1.0"))
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		_, err := Sweep(groups, SweepOptions{Group: "home"})
+		assert.ErrorContains(t, err, `unknown repository group "home" (configured: oss, work)`)
+
+		_, err = Sweep(nil, SweepOptions{})
+		assert.ErrorContains(t, err, "no repository groups configured")
+	})
+}
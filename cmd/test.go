@@ -5,14 +5,17 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
+	"strings"
 
-	"github.com/bral/git-branch-delete-go/internal/git"
 	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/scenario"
 	"github.com/spf13/cobra"
 )
 
 var (
-	testCount int
+	testCount     int
+	testScenarios []string
+	testDir       string
 )
 
 func init() {
@@ -20,6 +23,8 @@ func init() {
 	rootCmd.AddCommand(testCmd)
 
 	testCmd.Flags().IntVarP(&testCount, "count", "n", 5, "Number of test branches to create")
+	testCmd.Flags().StringSliceVar(&testScenarios, "scenario", nil, "Build a new repository with branches in these states ("+strings.Join(scenario.All, "|")+"|all)")
+	testCmd.Flags().StringVar(&testDir, "dir", "", "Empty directory to build --scenario repositories in (default: a new temporary directory)")
 }
 
 func newTestCmd() *cobra.Command {
@@ -27,9 +32,24 @@ func newTestCmd() *cobra.Command {
 		Use:   "test",
 		Short: "Create random test branches",
 		Long: `Create random test branches for testing purposes.
-This will create both local and remote branches.`,
+This will create both local and remote branches.
+
+With --scenario, build a new repository instead, with a bare origin next to
+it, whose branches are in known states. Each scenario adds a branch named
+after it:
+  merged                merged into main and pushed
+  stale                 its upstream was deleted from origin
+  unmerged              has a commit main lacks
+  remote-only           only exists on origin
+  diverged              ahead of and behind its upstream
+  worktree-checked-out  checked out in a linked worktree
+
+Names, emails and dates are fixed, so the same scenarios always give the
+same commits, for demos and reproducible bug reports.`,
 		Example: `  git-branch-delete test      # Create 5 test branches
-  git-branch-delete test -n 10  # Create 10 test branches`,
+  git-branch-delete test -n 10  # Create 10 test branches
+  git-branch-delete test --scenario all --dir /tmp/demo
+  git-branch-delete test --scenario merged,stale`,
 		RunE: runTest,
 	}
 }
@@ -43,16 +63,17 @@ func generateRandomName() (string, error) {
 }
 
 func runTest(cmd *cobra.Command, args []string) error {
-	// Get working directory
-	wd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+	if len(testScenarios) > 0 {
+		return runTestScenarios()
+	}
+	if testDir != "" {
+		return fmt.Errorf("--dir requires --scenario")
 	}
 
 	// Initialize git
-	g, err := git.New(wd)
+	g, err := openRepo()
 	if err != nil {
-		return fmt.Errorf("failed to initialize git in %s: %w", wd, err)
+		return err
 	}
 
 	log.Info("Creating %d test branches...", testCount)
@@ -87,3 +108,38 @@ func runTest(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runTestScenarios builds a scenario repository in testDir
+func runTestScenarios() error {
+	scenarios := testScenarios
+	for _, s := range testScenarios {
+		if s == "all" {
+			scenarios = scenario.All
+			break
+		}
+	}
+
+	dir := testDir
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "git-branch-delete-scenario-*"); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	repo, err := scenario.Build(dir, scenarios...)
+	if err != nil {
+		if testDir == "" {
+			os.RemoveAll(dir)
+		}
+		return err
+	}
+
+	log.Info("Built %s in %s", strings.Join(scenarios, ", "), repo.Dir)
+	log.Info("Origin is %s", repo.Origin)
+	if repo.Worktree != "" {
+		log.Info("Worktree is %s", repo.Worktree)
+	}
+	log.Info("Run 'git-branch-delete -C %s list --all' to see them", repo.Dir)
+	return nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/secrets"
+	"github.com/bral/git-branch-delete-go/internal/tracker"
+)
+
+// Ticket states of a branch, for --ticket-state
+const (
+	ticketDone = "done" // Names tickets, all of them done
+	ticketOpen = "open" // Names a ticket that isn't done, or couldn't be looked up
+	ticketNone = "none" // Names no tickets
+)
+
+// ticketStates are the values accepted by --ticket-state
+var ticketStates = []string{ticketDone, ticketOpen, ticketNone}
+
+// trackerTimeout bounds looking up the tickets of a repository
+const trackerTimeout = time.Minute
+
+// TicketFilter selects branches by the tracker status of the tickets their
+// tip commit's trailers name
+type TicketFilter struct {
+	States []string // Accepted states, from ticketStates
+}
+
+// newTicketFilter parses --ticket-state values
+func newTicketFilter(values []string) (*TicketFilter, error) {
+	if err := requireTicketTracker("--ticket-state"); err != nil {
+		return nil, err
+	}
+	f := &TicketFilter{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if !slices.Contains(ticketStates, v) {
+			return nil, fmt.Errorf("invalid --ticket-state %q (valid: %s)", v, strings.Join(ticketStates, ", "))
+		}
+		f.States = append(f.States, v)
+	}
+	return f, nil
+}
+
+// Filter returns the branches whose ticket state is accepted
+func (f *TicketFilter) Filter(g *git.Git, branches []git.GitBranch) []git.GitBranch {
+	withTicketStatuses(g, branches)
+	var matched []git.GitBranch
+	for _, b := range branches {
+		if slices.Contains(f.States, ticketState(b)) {
+			matched = append(matched, b)
+		}
+	}
+	return matched
+}
+
+// ticketState returns the state of b's tickets, once looked up
+func ticketState(b git.GitBranch) string {
+	switch {
+	case len(b.Tickets) == 0:
+		return ticketNone
+	case b.TicketsDone():
+		return ticketDone
+	default:
+		return ticketOpen
+	}
+}
+
+// requireTicketTracker fails unless trailers and a tracker are configured
+// for flag
+func requireTicketTracker(flag string) error {
+	if len(cfg.TicketTrailers) == 0 || cfg.TicketTracker.URL == "" {
+		return fmt.Errorf("%s needs ticketTrailers and ticketTracker in the config", flag)
+	}
+	return nil
+}
+
+// withTicketStatuses reads the tickets of the local branches from their
+// trailers and looks their statuses up in the configured tracker. Tickets
+// that can't be looked up aren't done, so they never make a branch
+// deletable.
+func withTicketStatuses(g *git.Git, branches []git.GitBranch) {
+	if err := g.WithTickets(branches, cfg.TicketTrailers); err != nil {
+		log.Warn("%v", err)
+		return
+	}
+
+	var ids []string
+	for _, b := range branches {
+		for _, id := range b.TicketIDs() {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	t := cfg.TicketTracker
+	token := ""
+	if u, err := url.Parse(t.URL); err == nil {
+		token, _ = secrets.Get(u.Hostname())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), trackerTimeout)
+	defer cancel()
+	statuses, err := tracker.NewClient(t.URL, t.StatusField, token).Statuses(ctx, ids)
+	if err != nil {
+		log.Warn("Some tickets couldn't be looked up and count as open: %v", err)
+	}
+	log.Debug("Looked up %d of %d tickets", len(statuses), len(ids))
+
+	for i := range branches {
+		for j := range branches[i].Tickets {
+			ticket := &branches[i].Tickets[j]
+			ticket.Status = statuses[ticket.ID]
+			ticket.Done = slices.ContainsFunc(t.DoneStatuses, func(done string) bool {
+				return ticket.Status != "" && strings.EqualFold(done, ticket.Status)
+			})
+		}
+	}
+}
+
+// pushedBranches returns the local branches whose upstream exists and has
+// every commit they have. Tickets being done says nothing about where the
+// work went, so a branch is only pruned for them once it's merged or
+// pushed.
+func pushedBranches(g *git.Git) map[string]bool {
+	tracking, err := g.ListTracking()
+	if err != nil {
+		log.Debug("Failed to read tracking status: %v", err)
+		return nil
+	}
+	pushed := make(map[string]bool, len(tracking))
+	for _, t := range tracking {
+		pushed[t.Branch] = t.Upstream != "" && !t.Gone && t.Ahead == 0
+	}
+	return pushed
+}
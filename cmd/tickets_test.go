@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneTicketsDone(t *testing.T) {
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "Done"
+		if r.URL.Path == "/OPEN-1" {
+			status = "In Progress"
+		}
+		_, _ = w.Write([]byte(`{"status": "` + status + `"}`))
+	}))
+	defer tracker.Close()
+
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+	cfg.TicketTrailers = []string{"Closes"}
+	cfg.TicketTracker = config.TicketTracker{URL: tracker.URL + "/{ticket}", StatusField: "status", DoneStatuses: []string{"done"}}
+
+	r, g := newTestRepo(t)
+	commit := func(branch, ticket string, push bool) {
+		r.Git("checkout", "--quiet", "-b", branch, "main")
+		r.Git("commit", "--quiet", "--allow-empty", "-m", "Work\n\nCloses: "+ticket)
+		if push {
+			r.Git("push", "--quiet", "--set-upstream", "origin", branch)
+		}
+		r.Git("checkout", "--quiet", "main")
+	}
+	commit("feature/pushed", "DONE-1", true)
+	commit("feature/local", "DONE-2", false)
+	commit("feature/open", "OPEN-1", true)
+	commit("feature/ahead", "DONE-3", true)
+	r.Git("checkout", "--quiet", "feature/ahead")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "More work\n\nCloses: DONE-3")
+	r.Git("checkout", "--quiet", "main")
+
+	res, err := Prune(g, PruneOptions{DryRun: true, TicketsDone: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature/pushed"}, resultNames(res.Candidates))
+
+	res, err = Prune(g, PruneOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Empty(t, res.Candidates, "tickets only count with --tickets-done")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Orders of the --timings table
+const (
+	timingsByTime   = "time"   // Slowest first
+	timingsByName   = "name"   // Alphabetical
+	timingsByAction = "action" // Local deletions before remote ones
+	timingsByResult = "result" // Failures first
+)
+
+// timingsOrders are the values accepted by --timings
+var timingsOrders = []string{timingsByTime, timingsByName, timingsByAction, timingsByResult}
+
+// addTimingsFlag adds --timings to a deleting command. Each command keeps
+// its own value, read back with timingsOrder.
+func addTimingsFlag(cmd *cobra.Command) {
+	cmd.Flags().String("timings", "", "After deleting, print each branch's action, result and elapsed time, sorted by "+strings.Join(timingsOrders, "|"))
+	cmd.Flags().Lookup("timings").NoOptDefVal = timingsByTime
+}
+
+// timingsOrder returns the validated --timings of cmd, checked before
+// anything is deleted; empty leaves the table out
+func timingsOrder(cmd *cobra.Command) (string, error) {
+	order, err := cmd.Flags().GetString("timings")
+	if err != nil {
+		return "", err
+	}
+	if order != "" && !slices.Contains(timingsOrders, order) {
+		return "", fmt.Errorf("invalid --timings %q (valid: %s)", order, strings.Join(timingsOrders, ", "))
+	}
+	return order, nil
+}
+
+// BranchTiming is a row of the --timings table
+type BranchTiming struct {
+	BranchResult
+	Failed bool
+}
+
+// showTimings prints the --timings table of a run in order, when asked for
+func showTimings(res *DeleteResult, order string) {
+	if order == "" || len(res.Deleted)+len(res.Failed) == 0 {
+		return
+	}
+	newPresenter(os.Stdout).timings(sortTimings(res, order))
+}
+
+// sortTimings returns the deleted and failed branches of res in order
+func sortTimings(res *DeleteResult, order string) []BranchTiming {
+	var rows []BranchTiming
+	for _, b := range res.Deleted {
+		rows = append(rows, BranchTiming{BranchResult: b})
+	}
+	for _, b := range res.Failed {
+		rows = append(rows, BranchTiming{BranchResult: b, Failed: true})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch order {
+		case timingsByTime:
+			if a.DurationMS != b.DurationMS {
+				return a.DurationMS > b.DurationMS
+			}
+		case timingsByAction:
+			if a.Remote != b.Remote {
+				return !a.Remote
+			}
+		case timingsByResult:
+			if a.Failed != b.Failed {
+				return a.Failed
+			}
+		}
+		return a.Name < b.Name
+	})
+	return rows
+}
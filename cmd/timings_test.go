@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortTimings(t *testing.T) {
+	res := &DeleteResult{
+		Deleted: []BranchResult{
+			{Name: "feature/b", DurationMS: 20},
+			{Name: "feature/a", Remote: true, DurationMS: 20},
+			{Name: "feature/c", DurationMS: 5},
+		},
+		Failed: []BranchResult{
+			{Name: "feature/d", Remote: true, DurationMS: 40},
+		},
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{timingsByTime, []string{"feature/d", "feature/a", "feature/b", "feature/c"}},
+		{timingsByName, []string{"feature/a", "feature/b", "feature/c", "feature/d"}},
+		{timingsByAction, []string{"feature/b", "feature/c", "feature/a", "feature/d"}},
+		{timingsByResult, []string{"feature/d", "feature/a", "feature/b", "feature/c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			rows := sortTimings(res, tt.order)
+			var names []string
+			for _, r := range rows {
+				names = append(names, r.Name)
+				assert.Equal(t, r.Name == "feature/d", r.Failed)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0ms"},
+		{850 * time.Millisecond, "850ms"},
+		{999 * time.Millisecond, "999ms"},
+		{time.Second, "1s"},
+		{2440 * time.Millisecond, "2.4s"},
+		{2460 * time.Millisecond, "2.5s"},
+		{90 * time.Second, "1m30s"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatElapsed(tt.d), tt.d)
+	}
+}
+
+func TestTimingsOrder(t *testing.T) {
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		addTimingsFlag(cmd)
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd
+	}
+
+	order, err := timingsOrder(newCmd())
+	require.NoError(t, err)
+	assert.Empty(t, order)
+
+	order, err = timingsOrder(newCmd("--timings"))
+	require.NoError(t, err)
+	assert.Equal(t, timingsByTime, order)
+
+	order, err = timingsOrder(newCmd("--timings=result"))
+	require.NoError(t, err)
+	assert.Equal(t, timingsByResult, order)
+
+	// Commands don't share the value
+	assert.Empty(t, newCmd().Flags().Lookup("timings").Value.String())
+
+	_, err = timingsOrder(newCmd("--timings=size"))
+	assert.ErrorContains(t, err, `invalid --timings "size"`)
+}
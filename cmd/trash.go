@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// trashDir is the directory under the user cache directory holding the
+// bundles of deleted branches, one subdirectory per repository
+const trashDir = "git-branch-delete/trash"
+
+// trashTimeFormat names bundles after the time of their deletion run
+const trashTimeFormat = "20060102-150405"
+
+var (
+	trashFrom  string
+	trashForce bool
+)
+
+// TrashEntry backs up the local branches of one deletion run: their tips,
+// listed in a file, and a bundle of the commits the default branch doesn't
+// have. Runs deleting only merged branches have no bundle.
+type TrashEntry struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Path     string          `json:"path,omitempty"` // The bundle
+	Tips     string          `json:"tips,omitempty"` // The list of tips
+	Branches []git.BundleRef `json:"branches"`
+}
+
+func init() {
+	rootCmd.AddCommand(newTrashCmd())
+}
+
+func newTrashCmd() *cobra.Command {
+	trashCmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Restore deleted local branches",
+		Long: `Before local branches are deleted, their tips and the commits the default
+branch doesn't have are saved to a git bundle in the user cache directory (e.g. ~/.cache/git-branch-delete/trash,
+or %LocalAppData%\git-branch-delete\trash on Windows). Bundles expire after
+trashDays days (default 30).`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the deletion runs in the trash",
+		RunE:  runTrashList,
+	}
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [branch...]",
+		Short: "Recreate deleted branches from the trash",
+		Long: `Recreate deleted branches from the trash. Without arguments, every branch of
+the latest deletion run (or the one named with --from) is restored; named
+branches are restored from the latest run that deleted them. Branches that
+exist again are never overwritten.`,
+		Example: `  git-branch-delete trash restore
+  git-branch-delete trash restore feature/123
+  git-branch-delete trash restore --from 20240102-150405`,
+		RunE: runTrashRestore,
+	}
+	restoreCmd.Flags().StringVar(&trashFrom, "from", "", "Restore from this deletion run (see 'trash list')")
+
+	emptyCmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete the trash of the repository",
+		RunE:  runTrashEmpty,
+	}
+	emptyCmd.Flags().BoolVarP(&trashForce, "force", "f", false, "Don't ask for confirmation")
+
+	trashCmd.AddCommand(listCmd, restoreCmd, emptyCmd)
+	return trashCmd
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	entries, err := loadTrash(g)
+	if err != nil {
+		return err
+	}
+	return newPresenter(os.Stdout).trash(entries)
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	res, err := RestoreTrash(g, trashFrom, args)
+	if err != nil {
+		return err
+	}
+
+	newPresenter(os.Stdout).restore(res)
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to restore %d branch(es)", len(res.Failed))
+	}
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	entries, err := loadTrash(g)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		log.Info("The trash is empty")
+		return nil
+	}
+
+	if !trashForce {
+		ok, err := ui.ConfirmDestructive(prompter, cfg.Confirmation,
+			fmt.Sprintf("Permanently delete %d deletion run(s) from the trash?", len(entries)), len(entries))
+		if err != nil && err != ui.ErrInterrupted {
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !ok {
+			log.Info("Operation cancelled")
+			return nil
+		}
+	}
+
+	for _, e := range entries {
+		for _, path := range []string{e.Path, e.Tips} {
+			if path == "" {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to empty the trash: %w", err)
+			}
+		}
+	}
+	log.Info("Removed %d deletion run(s) from the trash", len(entries))
+	return nil
+}
+
+// RestoreTrash recreates deleted branches from the trash. With no names, all
+// branches of the run from (or the latest run) are restored; otherwise each
+// name comes from the latest run holding it, limited to from when set.
+func RestoreTrash(g *git.Git, from string, names []string) (*RestoreResult, error) {
+	entries, err := loadTrash(g)
+	if err != nil {
+		return nil, err
+	}
+	if from != "" {
+		var match []TrashEntry
+		for _, e := range entries {
+			if e.ID == from {
+				match = append(match, e)
+			}
+		}
+		if len(match) == 0 {
+			return nil, fmt.Errorf("no deletion run %s in the trash", from)
+		}
+		entries = match
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the trash is empty")
+	}
+
+	// Pick the run each branch is restored from
+	plan := make(map[int][]string) // Entry index to branch names
+	var order []int
+	add := func(i int, name string) {
+		if _, ok := plan[i]; !ok {
+			order = append(order, i)
+		}
+		plan[i] = append(plan[i], name)
+	}
+	res := &RestoreResult{}
+	if len(names) == 0 {
+		for _, b := range entries[0].Branches {
+			add(0, b.Name)
+		}
+	}
+	for _, name := range names {
+		if i := latestEntryWith(entries, name); i >= 0 {
+			add(i, name)
+			continue
+		}
+		res.Failed = append(res.Failed, BranchResult{Name: name, Error: "not in the trash", Class: git.FailureNotFound})
+	}
+
+	for _, i := range order {
+		e := entries[i]
+		// Merged branches' commits are still in the default branch
+		if e.Path != "" {
+			if _, err := g.Unbundle(e.Path); err != nil {
+				for _, name := range plan[i] {
+					res.Failed = append(res.Failed, BranchResult{Name: name, Error: err.Error(), Class: git.ClassifyError(err)})
+				}
+				continue
+			}
+		}
+
+		wanted := make(map[string]bool)
+		for _, name := range plan[i] {
+			wanted[name] = true
+		}
+		for _, ref := range e.Branches {
+			if !wanted[ref.Name] {
+				continue
+			}
+			entry := BranchResult{Name: ref.Name, Commit: ref.CommitHash}
+			if err := g.RestoreBranch(ref); err != nil {
+				entry.setError(err)
+				res.Failed = append(res.Failed, entry)
+				continue
+			}
+			res.Restored = append(res.Restored, entry)
+		}
+	}
+	return res, nil
+}
+
+// latestEntryWith returns the index of the newest of entries holding the
+// branch, or -1
+func latestEntryWith(entries []TrashEntry, name string) int {
+	for i, e := range entries {
+		for _, b := range e.Branches {
+			if b.Name == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// trashBranches backs up the local branches among those about to be deleted
+// to a new entry in the trash, and expires old ones. Only the commits the
+// default branch doesn't have are bundled. The deletion must not go ahead
+// when it fails.
+func trashBranches(g *git.Git, branches []git.GitBranch) error {
+	retention := trashRetention()
+	var names []string
+	for _, b := range branches {
+		if !b.IsRemote {
+			names = append(names, b.Name)
+		}
+	}
+	if retention == 0 || len(names) == 0 {
+		return nil
+	}
+
+	dir, err := repoTrashDir(g)
+	if err != nil {
+		return err
+	}
+	expireTrash(dir, retention)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	stamp := time.Now().UTC().Format(trashTimeFormat)
+	id := stamp
+	for i := 2; fileExists(filepath.Join(dir, id+".bundle")) || fileExists(filepath.Join(dir, id+".json")); i++ {
+		id = fmt.Sprintf("%s-%d", stamp, i)
+	}
+	path := filepath.Join(dir, id+".bundle")
+
+	// The default branch has nothing to back up and can't thin its own bundle
+	base := defaultBranchOrConfig(g)
+	if slices.Contains(names, base) {
+		base = ""
+	}
+	tips, err := g.CreateBundle(path, names, base)
+	if err == nil {
+		err = writeTrashTips(filepath.Join(dir, id+".json"), tips)
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to back up branches before deleting them (set trashDays to -1 to skip backups): %w", err)
+	}
+	log.Debug("Backed up %d branch(es) to %s", len(names), path)
+	return nil
+}
+
+// writeTrashTips writes the list of backed up branch tips to path
+func writeTrashTips(path string, tips []git.BundleRef) error {
+	data, err := json.MarshalIndent(tips, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+	return nil
+}
+
+// trashRetention returns how long deleted branches are kept in the trash, or
+// 0 when they aren't backed up
+func trashRetention() time.Duration {
+	if cfg == nil {
+		return config.DefaultConfig().TrashRetention()
+	}
+	return cfg.TrashRetention()
+}
+
+// repoTrashDir returns the trash directory of the repository. It is named
+// after the repository and keyed by its git directory, so worktrees share it.
+func repoTrashDir(g *git.Git) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	common, err := g.CommonDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(common)
+	if name == ".git" {
+		name = filepath.Base(filepath.Dir(common))
+	}
+	name = strings.TrimSuffix(name, ".git")
+	sum := sha256.Sum256([]byte(common))
+	return filepath.Join(cache, trashDir, fmt.Sprintf("%s-%x", name, sum[:4])), nil
+}
+
+// loadTrash returns the deletion runs in the repository's trash, newest
+// first, after expiring old ones
+func loadTrash(g *git.Git) ([]TrashEntry, error) {
+	dir, err := repoTrashDir(g)
+	if err != nil {
+		return nil, err
+	}
+	if retention := trashRetention(); retention > 0 {
+		expireTrash(dir, retention)
+	}
+
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var entries []TrashEntry
+	seen := make(map[string]bool)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		id := strings.TrimSuffix(f.Name(), ext)
+		if f.IsDir() || !isTrashFile(f.Name()) || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, err := readTrashEntry(g, dir, id)
+		if err != nil {
+			log.Warn("Skipping unreadable trash entry %s: %v", filepath.Join(dir, id), err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// readTrashEntry reads the deletion run id from dir. A bundle whose list of
+// tips is missing still names the branches it stores.
+func readTrashEntry(g *git.Git, dir, id string) (TrashEntry, error) {
+	e := TrashEntry{ID: id}
+	if path := filepath.Join(dir, id+".bundle"); fileExists(path) {
+		e.Path = path
+	}
+
+	tips := filepath.Join(dir, id+".json")
+	data, err := os.ReadFile(tips)
+	switch {
+	case err == nil:
+		e.Tips = tips
+		if err := json.Unmarshal(data, &e.Branches); err != nil {
+			return e, err
+		}
+	case errors.Is(err, os.ErrNotExist) && e.Path != "":
+		if e.Branches, err = g.BundleBranches(e.Path); err != nil {
+			return e, err
+		}
+	default:
+		return e, err
+	}
+
+	stamped := e.Tips
+	if stamped == "" {
+		stamped = e.Path
+	}
+	info, err := os.Stat(stamped)
+	if err != nil {
+		return e, err
+	}
+	e.Time = info.ModTime()
+	return e, nil
+}
+
+// isTrashFile reports whether name is a bundle or list of tips in the trash
+func isTrashFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".bundle" || ext == ".json"
+}
+
+// expireTrash removes the trash entries older than the retention period
+func expireTrash(dir string, retention time.Duration) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-retention)
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || f.IsDir() || !isTrashFile(f.Name()) || info.ModTime().After(cutoff) {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		if err := os.Remove(path); err != nil {
+			log.Debug("Failed to expire trash file %s: %v", path, err)
+			continue
+		}
+		log.Debug("Expired trash file %s", path)
+	}
+}
+
+// fileExists reports whether something exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trashAndDelete backs up the local branches, then deletes them and prunes
+// their unreachable commits, as a deletion run would
+func trashAndDelete(t *testing.T, r *testutil.Remote, g *git.Git, names ...string) {
+	t.Helper()
+	branches := make([]git.GitBranch, len(names))
+	for i, name := range names {
+		branches[i] = git.GitBranch{Name: name}
+	}
+	require.NoError(t, trashBranches(g, branches))
+	r.Git(append([]string{"branch", "--quiet", "-D"}, names...)...)
+	r.Git("reflog", "expire", "--expire=now", "--all")
+	r.Git("gc", "--quiet", "--prune=now")
+}
+
+func TestRestoreTrash(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	r, g := newTestRepo(t)
+	r.Local("feature/wip")
+	r.Merged("feature/done")
+	wip := r.Git("rev-parse", "feature/wip")
+	done := r.Git("rev-parse", "feature/done")
+	trashAndDelete(t, r, g, "feature/wip", "feature/done")
+
+	entries, err := loadTrash(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.NotEmpty(t, entries[0].Path)
+	assert.NotEmpty(t, entries[0].Tips)
+
+	// Only the unmerged branch needs the bundle
+	bundled, err := g.BundleBranches(entries[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, []git.BundleRef{{Name: "feature/wip", CommitHash: wip}}, bundled)
+
+	res, err := RestoreTrash(g, "", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"feature/wip", "feature/done"}, resultNames(res.Restored))
+	assert.Empty(t, res.Failed)
+	assert.Equal(t, wip, r.Git("rev-parse", "feature/wip"))
+	assert.Equal(t, done, r.Git("rev-parse", "feature/done"))
+
+	// Branches that exist again are never overwritten
+	r.Git("branch", "--quiet", "-D", "feature/done")
+	r.Git("branch", "feature/done", "main")
+	res, err = RestoreTrash(g, "", []string{"feature/done", "feature/unknown"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Restored)
+	assert.ElementsMatch(t, []string{"feature/done", "feature/unknown"}, resultNames(res.Failed))
+	assert.Equal(t, r.Git("rev-parse", "main"), r.Git("rev-parse", "feature/done"))
+
+	_, err = RestoreTrash(g, "19700101-000000", nil)
+	assert.ErrorContains(t, err, "no deletion run")
+}
+
+func TestRestoreTrashMergedOnly(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	r, g := newTestRepo(t)
+	r.Merged("feature/done")
+	done := r.Git("rev-parse", "feature/done")
+	trashAndDelete(t, r, g, "feature/done")
+
+	// Nothing to bundle: the default branch holds every commit
+	entries, err := loadTrash(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].Path)
+	assert.Equal(t, []git.BundleRef{{Name: "feature/done", CommitHash: done}}, entries[0].Branches)
+
+	res, err := RestoreTrash(g, "", []string{"feature/done"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature/done"}, resultNames(res.Restored))
+	assert.Equal(t, done, r.Git("rev-parse", "feature/done"))
+}
+
+func TestLoadTrash(t *testing.T) {
+	saved := cfg
+	defer func() { cfg = saved }()
+	cfg = config.DefaultConfig()
+
+	r, g := newTestRepo(t)
+	r.Local("feature/a")
+	r.Local("feature/b")
+	trashAndDelete(t, r, g, "feature/a")
+	trashAndDelete(t, r, g, "feature/b")
+
+	// Runs are ordered by time, not by name
+	entries, err := loadTrash(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	older := time.Now().Add(-time.Hour)
+	for _, path := range []string{entries[1].Path, entries[1].Tips} {
+		require.NoError(t, os.Chtimes(path, time.Now(), time.Now()))
+	}
+	for _, path := range []string{entries[0].Path, entries[0].Tips} {
+		require.NoError(t, os.Chtimes(path, older, older))
+	}
+	first, second := entries[1], entries[0]
+
+	entries, err = loadTrash(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, first.ID, entries[0].ID)
+	assert.Equal(t, second.ID, entries[1].ID)
+
+	// A bundle without its list of tips still names its branches
+	require.NoError(t, os.Remove(second.Tips))
+	// Unreadable entries are skipped
+	require.NoError(t, os.WriteFile(first.Tips, []byte("not json"), 0600))
+	entries, err = loadTrash(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, second.ID, entries[0].ID)
+	assert.Empty(t, entries[0].Tips)
+	assert.Equal(t, second.Branches, entries[0].Branches)
+}
+
+func TestExpireTrash(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-31 * 24 * time.Hour)
+	files := map[string]bool{ // Whether each file is old
+		"20240101-000000.bundle": true,
+		"20240101-000000.json":   true,
+		"20240301-000000.bundle": false,
+		"20240301-000000.json":   false,
+		"notes.txt":              true,
+	}
+	for name, isOld := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+		if isOld {
+			require.NoError(t, os.Chtimes(path, old, old))
+		}
+	}
+
+	expireTrash(dir, 30*24*time.Hour)
+
+	left, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range left {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"20240301-000000.bundle", "20240301-000000.json", "notes.txt"}, names)
+}
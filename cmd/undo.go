@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoLast   bool
+	undoAll    bool
+	undoDryRun bool
+)
+
+// UndoOptions picks the deletions Undo takes back
+type UndoOptions struct {
+	Branches []string // The latest deletion of each of these branches
+	Last     bool     // Every deletion of the latest run
+	All      bool     // Every recorded deletion, each branch at its latest
+	DryRun   bool     // Only report what would be restored
+}
+
+func init() {
+	rootCmd.AddCommand(newUndoCmd())
+}
+
+func newUndoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undo [branch...] | --last | --all",
+		Short: "Recreate deleted branches from the deletion journal",
+		Long: `Every deletion, local or on origin, is recorded in the deletion journal
+(.git/gbd-journal) with the branch's last commit. undo recreates the branches
+at those commits: the named ones as they were last deleted, every branch of
+the latest run with --last, or every recorded branch with --all.
+
+Branches that exist again are never overwritten, and restored branches leave
+the journal. Commits are only kept until git's garbage collection prunes
+them; local branches are also in the trash (see 'trash restore') for a while
+after that.`,
+		Example: `  git-branch-delete undo feature/123
+  git-branch-delete undo --last
+  git-branch-delete undo --all --dry-run`,
+		RunE: runUndo,
+	}
+
+	cmd.Flags().BoolVar(&undoLast, "last", false, "Restore every branch deleted by the latest run")
+	cmd.Flags().BoolVar(&undoAll, "all", false, "Restore every branch in the journal")
+	cmd.Flags().BoolVar(&undoDryRun, "dry-run", false, "Show what would be restored without changing anything")
+	return cmd
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	picked := 0
+	for _, set := range []bool{len(args) > 0, undoLast, undoAll} {
+		if set {
+			picked++
+		}
+	}
+	if picked != 1 {
+		return fmt.Errorf("name the branches to restore, or pass either --last or --all")
+	}
+
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	opts := UndoOptions{Branches: args, Last: undoLast, All: undoAll, DryRun: safeDryRun(g, undoDryRun)}
+	res, err := Undo(g, opts)
+	if err != nil {
+		return err
+	}
+
+	newPresenter(os.Stdout).restore(res)
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("failed to restore %d branch(es)", len(res.Failed))
+	}
+	return nil
+}
+
+// Undo recreates the branches of the deletions opts picks from the
+// journal, newest first, and drops the restored ones from the journal
+func Undo(g *git.Git, opts UndoOptions) (*RestoreResult, error) {
+	entries, err := journal.Load(g)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no deletions recorded in the journal")
+	}
+
+	res := &RestoreResult{DryRun: opts.DryRun}
+	picked, missing := pickUndo(entries, opts)
+	for _, name := range missing {
+		res.Failed = append(res.Failed, BranchResult{Name: name, Error: "not in the deletion journal", Class: git.FailureNotFound})
+	}
+
+	var restored []journal.Entry
+	for _, e := range picked {
+		entry := BranchResult{Name: e.Branch, Commit: e.Commit, Remote: e.Remote}
+		if opts.DryRun {
+			res.Restored = append(res.Restored, entry)
+			continue
+		}
+		if err := restoreJournalEntry(g, e); err != nil {
+			entry.setError(err)
+			res.Failed = append(res.Failed, entry)
+			continue
+		}
+		res.Restored = append(res.Restored, entry)
+		restored = append(restored, e)
+	}
+
+	if len(restored) > 0 {
+		// Earlier deletions of a restored branch are superseded too
+		kept := slices.DeleteFunc(entries, func(e journal.Entry) bool {
+			return slices.ContainsFunc(restored, func(r journal.Entry) bool {
+				return r.Branch == e.Branch && r.Remote == e.Remote && !e.Time.After(r.Time)
+			})
+		})
+		if err := journal.Save(g, kept); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// pickUndo returns the entries opts picks, newest first, and the named
+// branches the journal doesn't have
+func pickUndo(entries []journal.Entry, opts UndoOptions) ([]journal.Entry, []string) {
+	type key struct {
+		branch string
+		remote bool
+	}
+	seen := make(map[key]bool)
+	var picked []journal.Entry
+	var missing []string
+
+	latest := make(map[string]journal.Entry) // Latest deletion of each name
+	for _, e := range entries {
+		if l, ok := latest[e.Branch]; !ok || !e.Time.Before(l.Time) {
+			latest[e.Branch] = e
+		}
+	}
+	for _, name := range opts.Branches {
+		if _, ok := latest[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		k := key{e.Branch, e.Remote}
+		if seen[k] {
+			continue
+		}
+		switch {
+		case opts.Last && !e.SameRun(entries[len(entries)-1]):
+			continue
+		case len(opts.Branches) > 0:
+			// A branch deleted locally and on origin in one run comes back
+			// in both places
+			if !slices.Contains(opts.Branches, e.Branch) || !e.SameRun(latest[e.Branch]) {
+				continue
+			}
+		}
+		seen[k] = true
+		picked = append(picked, e)
+	}
+	return picked, missing
+}
+
+// restoreJournalEntry recreates the branch of a journaled deletion
+func restoreJournalEntry(g *git.Git, e journal.Entry) error {
+	if e.Remote {
+		return g.RestoreRemoteBranch(e.Branch, e.Commit)
+	}
+	if !g.HasCommit(e.Commit) {
+		commit := e.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		return fmt.Errorf("commit %s is no longer in the repository; try 'trash restore %s'", commit, e.Branch)
+	}
+	return g.RestoreBranch(git.BundleRef{Name: e.Branch, CommitHash: e.Commit})
+}
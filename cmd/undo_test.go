@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickUndo(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := func(minute int, run, branch string, remote bool) journal.Entry {
+		return journal.Entry{Time: t0.Add(time.Duration(minute) * time.Minute), Run: run, Branch: branch, Remote: remote, Commit: branch + "-commit"}
+	}
+	// Oldest first, like the journal
+	entries := []journal.Entry{
+		{Time: t0, Branch: "old", Commit: "c0"}, // Recorded before runs
+		{Time: t0, Branch: "old-too", Commit: "c1"},
+		entry(1, "a", "feature/x", false),
+		entry(2, "b", "feature/x", false), // Recreated and deleted again
+		entry(2, "b", "feature/x", true),
+		// Interactive deletions are recorded one at a time
+		entry(3, "c", "feature/y", false),
+		entry(4, "c", "feature/z", false),
+	}
+	key := func(picked []journal.Entry) []string {
+		var out []string
+		for _, e := range picked {
+			name := e.Branch
+			if e.Remote {
+				name = "origin/" + name
+			}
+			out = append(out, name+"@"+e.Run)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		entries     []journal.Entry
+		opts        UndoOptions
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name: "last run, recorded one at a time",
+			opts: UndoOptions{Last: true},
+			want: []string{"feature/z@c", "feature/y@c"},
+		},
+		{
+			name:    "last run recorded before runs",
+			entries: entries[:2],
+			opts:    UndoOptions{Last: true},
+			want:    []string{"old-too@", "old@"},
+		},
+		{
+			name: "named branch at its latest deletion, local and remote",
+			opts: UndoOptions{Branches: []string{"feature/x"}},
+			want: []string{"origin/feature/x@b", "feature/x@b"},
+		},
+		{
+			name:        "named branch missing",
+			opts:        UndoOptions{Branches: []string{"feature/y", "nope"}},
+			want:        []string{"feature/y@c"},
+			wantMissing: []string{"nope"},
+		},
+		{
+			name: "all, each branch once",
+			opts: UndoOptions{All: true},
+			want: []string{"feature/z@c", "feature/y@c", "origin/feature/x@b", "feature/x@b", "old-too@", "old@"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.entries
+			if in == nil {
+				in = entries
+			}
+			picked, missing := pickUndo(in, tt.opts)
+			assert.Equal(t, tt.want, key(picked))
+			assert.Equal(t, tt.wantMissing, missing)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchNoFetch  bool
+	watchNotify   bool
+)
+
+// Branch states watch reports
+const (
+	watchMerged = "merged"
+	watchStale  = "stale"
+)
+
+// WatchOptions controls the behavior of Watch
+type WatchOptions struct {
+	Interval time.Duration // Time between checks
+	Fetch    bool          // Fetch origin with pruning before each check
+
+	// Changed receives the local branches that became merged or stale
+	// since the previous check, by state, along with every deletable branch
+	Changed func(changed map[string][]string, deletable int)
+}
+
+func init() {
+	watchCmd := newWatchCmd()
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 15*time.Minute, "Time between checks, e.g. 5m")
+	watchCmd.Flags().BoolVar(&watchNoFetch, "no-fetch", false, "Don't fetch origin before each check")
+	watchCmd.Flags().BoolVar(&watchNotify, "notify", true, "Send desktop notifications about changes")
+}
+
+func newWatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch",
+		Short: "Notify when branches become merged or stale",
+		Long: `Keep running and check the local branches every --interval, fetching origin
+with pruning first. When branches become merged or their upstream is
+deleted, say so and send a desktop notification (Notification Center on
+macOS, a toast on Windows, notify-send elsewhere) suggesting to run prune.
+
+Nothing is deleted. Branches that were already deletable when watch started
+are counted, not announced. Stop with Ctrl-C.`,
+		Example: `  git-branch-delete watch
+  git-branch-delete watch --interval 5m --no-fetch
+  git-branch-delete watch --notify=false`,
+		Args: cobra.NoArgs,
+		RunE: runWatch,
+	}
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval < time.Minute {
+		return fmt.Errorf("--interval must be at least 1m")
+	}
+
+	lockWholeRun = false
+	g, err := openRepo()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	repo := filepath.Base(g.WorkDir())
+	notify := watchNotify
+	err = Watch(ctx, g, WatchOptions{
+		Interval: watchInterval,
+		Fetch:    !watchNoFetch,
+		Changed: func(changed map[string][]string, deletable int) {
+			for _, state := range []string{watchMerged, watchStale} {
+				for _, name := range changed[state] {
+					log.Info("%s is now %s", name, state)
+				}
+			}
+			log.Info("%d branch(es) can be pruned; run 'git-branch-delete prune'", deletable)
+
+			if !notify {
+				return
+			}
+			err := utils.Notify("git-branch-delete: "+repo, watchMessage(changed, deletable))
+			if errors.Is(err, utils.ErrNoNotifier) {
+				log.Warn("Desktop notifications aren't available here; only logging changes")
+				notify = false
+			} else if err != nil {
+				log.Warn("%v", err)
+			}
+		},
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// Watch checks the local branches every opts.Interval until ctx is done,
+// passing those that became merged or stale to opts.Changed. The first
+// check only records the current states.
+func Watch(ctx context.Context, g *git.Git, opts WatchOptions) error {
+	var previous map[string]string
+	for {
+		if opts.Fetch {
+			if err := g.FetchPrune(); err != nil {
+				log.Warn("%v", err)
+			}
+		}
+
+		current, err := deletableStates(g)
+		if err != nil {
+			log.Warn("%v", err)
+		} else {
+			if previous == nil {
+				log.Info("Watching %s: %d branch(es) can already be pruned; checking every %s", g.WorkDir(), len(current), opts.Interval)
+			} else if changed := stateChanges(previous, current); len(changed) > 0 {
+				opts.Changed(changed, len(current))
+			}
+			previous = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// deletableStates returns the local branches prune would consider, keyed
+// by name, with whether they are merged or stale
+func deletableStates(g *git.Git) (map[string]string, error) {
+	branches, err := g.ListLocalBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	states := make(map[string]string)
+	for _, b := range branches {
+		if b.IsCurrent || b.IsDefault || g.IsProtected(b.Name, false) {
+			continue
+		}
+		switch {
+		case b.IsMerged:
+			states[b.Name] = watchMerged
+		case b.IsStale:
+			states[b.Name] = watchStale
+		}
+	}
+	return states, nil
+}
+
+// stateChanges returns the branches whose state in current is new since
+// previous, by state, sorted by name
+func stateChanges(previous, current map[string]string) map[string][]string {
+	changed := make(map[string][]string)
+	for name, state := range current {
+		if previous[name] != state {
+			changed[state] = append(changed[state], name)
+		}
+	}
+	for _, names := range changed {
+		slices.Sort(names)
+	}
+	return changed
+}
+
+// watchMessage sums changes up for a notification, e.g. "feature/x was
+// merged. 3 branches can be pruned."
+func watchMessage(changed map[string][]string, deletable int) string {
+	var parts []string
+	for _, state := range []string{watchMerged, watchStale} {
+		names := changed[state]
+		switch {
+		case len(names) == 1 && state == watchMerged:
+			parts = append(parts, names[0]+" was merged.")
+		case len(names) == 1:
+			parts = append(parts, names[0]+" lost its upstream.")
+		case len(names) > 1 && state == watchMerged:
+			parts = append(parts, fmt.Sprintf("%d branches were merged.", len(names)))
+		case len(names) > 1:
+			parts = append(parts, fmt.Sprintf("%d branches lost their upstream.", len(names)))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d can be pruned: run git-branch-delete prune", deletable))
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/log"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signalWriter closes seen once something containing want is written
+type signalWriter struct {
+	want string
+	seen chan struct{}
+	once sync.Once
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), w.want) {
+		w.once.Do(func() { close(w.seen) })
+	}
+	return len(p), nil
+}
+
+func TestWatch(t *testing.T) {
+	r, g := newTestRepo(t, testutil.Stale)
+	r.Tracked("feature/x")
+
+	// The first check is over once watch says what it's watching
+	started := &signalWriter{want: "Watching", seen: make(chan struct{})}
+	log.SetConsole(started)
+	defer log.SetConsole(os.Stdout)
+
+	type change struct {
+		changed   map[string][]string
+		deletable int
+	}
+	changes := make(chan change, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, g, WatchOptions{
+			Interval: 10 * time.Millisecond,
+			Fetch:    true,
+			Changed: func(changed map[string][]string, deletable int) {
+				select {
+				case changes <- change{changed, deletable}:
+				default:
+				}
+			},
+		})
+	}()
+
+	<-started.seen
+	// Watch's own fetch notices the upstream is gone
+	r.DeleteOnOrigin("feature/x")
+
+	select {
+	case c := <-changes:
+		// The branch that was already stale isn't announced again
+		assert.Equal(t, map[string][]string{watchStale: {"feature/x"}}, c.changed)
+		assert.Equal(t, 2, c.deletable)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no change was reported")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestDeletableStates(t *testing.T) {
+	r, g := newTestRepo(t, testutil.Merged, testutil.Stale, testutil.Unmerged)
+	r.Tracked("feature/x")
+
+	states, err := deletableStates(g)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		testutil.Merged: watchMerged,
+		testutil.Stale:  watchStale,
+	}, states)
+}
+
+func TestStateChanges(t *testing.T) {
+	previous := map[string]string{"a": watchMerged, "b": watchStale, "c": watchStale}
+	current := map[string]string{"a": watchMerged, "b": watchMerged, "d": watchStale, "c": watchStale, "e": watchStale}
+
+	assert.Equal(t, map[string][]string{
+		watchMerged: {"b"},
+		watchStale:  {"d", "e"},
+	}, stateChanges(previous, current))
+	assert.Empty(t, stateChanges(current, current))
+	// Branches that stopped being deletable aren't changes to announce
+	assert.Empty(t, stateChanges(current, map[string]string{"a": watchMerged}))
+}
+
+func TestWatchMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		changed   map[string][]string
+		deletable int
+		want      string
+	}{
+		{"one merged", map[string][]string{watchMerged: {"feature/x"}}, 1,
+			"feature/x was merged. 1 can be pruned: run git-branch-delete prune"},
+		{"one stale", map[string][]string{watchStale: {"feature/x"}}, 3,
+			"feature/x lost its upstream. 3 can be pruned: run git-branch-delete prune"},
+		{"several of both", map[string][]string{watchMerged: {"a", "b"}, watchStale: {"c", "d", "e"}}, 5,
+			"2 branches were merged. 3 branches lost their upstream. 5 can be pruned: run git-branch-delete prune"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, watchMessage(tt.changed, tt.deletable))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Command prune deletes the merged and stale branches of a repository using
+// the gbd package. It's an example of embedding branch cleanup in another
+// tool, not a replacement for git-branch-delete.
+//
+//	go run ./examples/prune -C ~/src/project -n
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/bral/git-branch-delete-go/pkg/gbd"
+)
+
+func main() {
+	dir := flag.String("C", ".", "Repository to clean up")
+	dryRun := flag.Bool("n", false, "Print the plan without deleting anything")
+	remote := flag.Bool("remote", false, "Also delete merged branches from origin")
+	protect := flag.String("protect", "", "Comma-separated branch patterns to keep, e.g. 'release/*'")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, *dir, *dryRun, *remote, *protect); err != nil {
+		fmt.Fprintln(os.Stderr, "prune:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dir string, dryRun, remote bool, protect string) error {
+	opts := gbd.Options{Remote: remote}
+	if protect != "" {
+		opts.Protected = strings.Split(protect, ",")
+	}
+
+	scan, err := gbd.ScanRepo(ctx, dir, opts)
+	if err != nil {
+		return err
+	}
+	plan := gbd.PlanPrune(scan, gbd.PruneOptions{})
+
+	for _, s := range plan.Skipped {
+		fmt.Printf("keep    %s (%s)\n", name(s.Branch), s.Why)
+	}
+	for _, b := range plan.Delete {
+		fmt.Printf("delete  %s (%s)\n", name(b), b.Reason)
+	}
+	if len(plan.Delete) == 0 {
+		fmt.Println("Nothing to delete")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+
+	res, err := gbd.Execute(ctx, plan)
+	if err != nil {
+		return err
+	}
+	for _, f := range res.Failed {
+		fmt.Fprintln(os.Stderr, "failed ", f)
+	}
+	fmt.Printf("Deleted %d of %d branches\n", len(res.Deleted), len(plan.Delete))
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("%d branches could not be deleted", len(res.Failed))
+	}
+	return nil
+}
+
+// name returns a branch's name, with "origin/" for remote branches
+func name(b gbd.Branch) string {
+	if b.Remote {
+		return "origin/" + b.Name
+	}
+	return b.Name
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// aliasNamePattern matches alias names, which are used as commands
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Alias returns the arguments the alias called name expands to, and whether
+// it's configured
+func (c *Config) Alias(name string) ([]string, bool) {
+	expansion, ok := c.Aliases[name]
+	if !ok {
+		return nil, false
+	}
+	args, err := splitArgs(expansion)
+	if err != nil {
+		return nil, false
+	}
+	return args, true
+}
+
+// validateAliases checks that every alias has a usable name and expands to
+// at least one argument
+func (c *Config) validateAliases() error {
+	for name, expansion := range c.Aliases {
+		if !aliasNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid alias name %q", name)
+		}
+		args, err := splitArgs(expansion)
+		if err != nil {
+			return fmt.Errorf("alias %q: %w", name, err)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("alias %q expands to nothing", name)
+		}
+	}
+	return nil
+}
+
+// splitArgs splits s into arguments at unquoted whitespace, like a shell
+// without expansions: single quotes keep everything literal, and double
+// quotes and backslashes escape whitespace and quotes
+func splitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
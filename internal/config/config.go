@@ -3,42 +3,359 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
 )
 
 // Config holds the application configuration
 type Config struct {
-	DefaultBranch     string   `json:"defaultBranch"`
+	Version int `json:"version"` // Schema version, see CurrentVersion
+
+	DefaultBranch     string       `json:"defaultBranch"`
+	ProtectedBranches []string     `json:"protectedBranches"`
+	DefaultRemote     string       `json:"defaultRemote"`
+	AutoConfirm       bool         `json:"autoConfirm"`
+	MaxBranchLength   int          `json:"maxBranchLength"`
+	Confirmation      Confirmation `json:"confirmation"`
+	LogFile           string       `json:"logFile"` // Also write logs here when set
+
+	// ProtectedRemoteBranches replaces ProtectedBranches for remote
+	// deletions when set, e.g. to allow deleting local release/* copies
+	// while keeping the remote ones
+	ProtectedRemoteBranches []string `json:"protectedRemoteBranches"`
+
+	// SharedProtection is where the protected branch list a team shares is
+	// read from. Its patterns protect local and remote branches on top of
+	// the configured ones.
+	SharedProtection SharedProtection `json:"sharedProtection"`
+
+	// Deployments protects the branches deployed to an environment of
+	// origin's GitHub repository, until their deployment is inactive
+	Deployments Deployments `json:"deployments"`
+
+	// IncludeRefs and ExcludeRefs limit which refs count as branches, e.g.
+	// to ignore Gerrit's refs/for/* and change refs. Patterns are full ref
+	// names, matched literally up to a slash or as globs.
+	IncludeRefs []string `json:"includeRefs"`
+	ExcludeRefs []string `json:"excludeRefs"`
+
+	// MergedTargets are branch name patterns (e.g. "main" and "release/*")
+	// a branch counts as merged into; empty means HEAD. Useful for release
+	// trains where a branch merged into any release line is done. Patterns
+	// qualified with a remote (e.g. "upstream/main") match that remote's
+	// branches, for forks whose real default branch lives upstream.
+	MergedTargets []string `json:"mergedTargets"`
+
+	// MergeStrategies are the ways a branch counts as merged into a target,
+	// of the MergeStrategy* strategies. Ancestry always applies; cherry adds
+	// branches whose every commit was cherry-picked into a target.
+	MergeStrategies []string `json:"mergeStrategies"`
+
+	// ForceConfirmation is how forced bulk deletions confirm unmerged
+	// branches, one of the ForceConfirm* modes; empty means none
+	ForceConfirmation string `json:"forceConfirmation"`
+
+	// WithRemote deletes the remote branch of each deleted local branch
+	// without asking, like --with-remote
+	WithRemote bool `json:"withRemote"`
+
+	// TrashDays is how many days the bundles backing up deleted local
+	// branches are kept; 0 means DefaultTrashDays and a negative value
+	// turns the backups off
+	TrashDays int `json:"trashDays"`
+
+	// ExtraEnvAllowlist are environment variables passed to git on top of
+	// the built-in allowlist, e.g. HTTPS_PROXY or SSH_ASKPASS. Names ending
+	// in "*" match every variable with that prefix.
+	ExtraEnvAllowlist []string `json:"extraEnvAllowlist"`
+
+	// SSHCommands are the SSH commands git uses to contact each remote, by
+	// remote name, e.g. "ssh -i ~/.ssh/id_work" for multiple identities
+	SSHCommands map[string]string `json:"sshCommands"`
+
+	// RemoteAuth is how git authenticates to each remote, by remote name,
+	// for repositories whose remotes need different credentials, e.g.
+	// origin on GitHub and a mirror on GitLab
+	RemoteAuth map[string]RemoteAuth `json:"remoteAuth"`
+
+	// PRURLTemplate is the web page of a branch, e.g.
+	// "https://github.com/org/repo/compare/{branch}", opened from the
+	// interactive selector and linked in reports. It replaces the page
+	// guessed from origin's URL, so navigation works with any host and
+	// without API tokens. {host} and {repo} stand for origin's host and
+	// repository path.
+	PRURLTemplate string `json:"prUrlTemplate"`
+
+	// MessageDisplay is the commit text listings show, one of the Message*
+	// modes; empty means MessageSubject
+	MessageDisplay string `json:"messageDisplay"`
+
+	// AgeBuckets tag branches by the age of their tip commit, youngest
+	// bucket first; empty means DefaultAgeBuckets
+	AgeBuckets []AgeBucket `json:"ageBuckets"`
+
+	// BranchPaths maps branch name patterns (e.g. "payments/*") to the
+	// repository subpath they belong to (e.g. "services/payments")
+	BranchPaths map[string]string `json:"branchPaths"`
+
+	// RepoGroups are named sets of repositories sweep cleans together,
+	// e.g. "work" and "oss", each with its own policy
+	RepoGroups map[string]RepoGroup `json:"repoGroups"`
+
+	// LearnIgnoresAfter hides a merged or stale branch from the interactive
+	// selector once it was left unselected in this many runs; 0 never
+	// learns
+	LearnIgnoresAfter int `json:"learnIgnoresAfter"`
+
+	// TicketTrailers are the tip commit trailer keys naming a branch's
+	// tickets, e.g. "Closes" for "Closes: PROJ-123"
+	TicketTrailers []string `json:"ticketTrailers"`
+
+	// TicketTracker looks the tickets' statuses up, for --ticket-state and
+	// prune --tickets-done
+	TicketTracker TicketTracker `json:"ticketTracker"`
+
+	// APIRateLimit is how many requests a second are sent to hosting
+	// provider APIs at most, for pull request states and deployments of
+	// repositories with hundreds of branches; 0 means no limit. Rate
+	// limited responses are waited out either way.
+	APIRateLimit float64 `json:"apiRateLimit"`
+
+	// SharedClone is when the safeguards for shared clones are on, one of
+	// the SharedClone* modes; empty means SharedCloneAuto
+	SharedClone string `json:"sharedClone"`
+
+	// Display customizes the status indicators of listings and the
+	// interactive selector
+	Display Display `json:"display"`
+
+	// Aliases are user-defined commands, by name, expanding to the
+	// arguments they stand for, e.g. "nuke": "prune --force"
+	Aliases map[string]string `json:"aliases"`
+}
+
+// Display customizes how branches are shown
+type Display struct {
+	// Indicators override the label and color of status indicators, by
+	// Indicator* name, e.g. "stale": {"label": "gone"}
+	Indicators map[string]IndicatorStyle `json:"indicators"`
+
+	// NoEmoji replaces check marks and other symbols with plain text, for
+	// terminals and fonts that can't show them
+	NoEmoji bool `json:"noEmoji"`
+}
+
+// SharedProtection locates a team's protected branch list
+type SharedProtection struct {
+	// URL is where the list is fetched from, on top of RemoteFile; the
+	// last copy is kept for when it can't be reached. Its token is the one
+	// stored for its host with auth login, sent only over https.
+	URL string `json:"url"`
+
+	// RemoteFile is the list's path on the default remote's default
+	// branch; empty means DefaultSharedProtectionFile and SharedProtectionOff
+	// reads no file
+	RemoteFile string `json:"remoteFile"`
+
+	// Required refuses to run when the list at URL can't be read and no
+	// copy of it is kept, or the remote file can't be looked for, rather
+	// than going on without it
+	Required bool `json:"required"`
+}
+
+// Deployments configures protecting deployed branches. They are looked up
+// on every run, with the token of GITHUB_TOKEN, GH_TOKEN or auth login.
+type Deployments struct {
+	Protect bool `json:"protect"`
+
+	// Environments are the environments whose branches are protected;
+	// empty means all
+	Environments []string `json:"environments"`
+}
+
+// RemoteAuth is how git authenticates to one remote. Unset fields leave
+// git's own configuration in charge.
+type RemoteAuth struct {
+	// SSHKey is the private key to connect with, alone rather than with
+	// the agent's keys; "~" and environment variables are expanded
+	SSHKey string `json:"sshKey"`
+
+	// TokenEnv is the environment variable holding an HTTPS access token,
+	// sent with TokenUser (default "x-access-token")
+	TokenEnv  string `json:"tokenEnv"`
+	TokenUser string `json:"tokenUser"`
+
+	// CredentialHelper replaces the configured credential helpers, e.g.
+	// "store" or "!gh auth git-credential"
+	CredentialHelper string `json:"credentialHelper"`
+
+	// URLRewrites replace URL prefixes (keys) with others (values) when
+	// contacting the remote, like git's url.<base>.insteadOf
+	URLRewrites map[string]string `json:"urlRewrites"`
+}
+
+// DefaultSharedProtectionFile is the shared protected branch list read from
+// the default branch when no other file is configured
+const DefaultSharedProtectionFile = ".gbd-protected.yaml"
+
+// SharedProtectionOff turns reading the shared list from the default branch off
+const SharedProtectionOff = "off"
+
+// IndicatorStyle is how a status indicator is shown. Empty fields keep
+// the built-in label and color.
+type IndicatorStyle struct {
+	Label string `json:"label"`
+	Color string `json:"color"` // One of DisplayColors
+}
+
+// TicketTracker is an issue tracker's JSON API for ticket statuses. Its
+// token is the one stored for its host with auth login.
+type TicketTracker struct {
+	// URL is the API URL of a ticket, with {ticket} standing for its ID
+	URL string `json:"url"`
+
+	// StatusField is the dot-separated path of the status in the
+	// response, e.g. "fields.status.name"
+	StatusField string `json:"statusField"`
+
+	// DoneStatuses are the statuses of finished tickets, compared without
+	// case
+	DoneStatuses []string `json:"doneStatuses"`
+}
+
+// RepoGroup is a set of repositories swept with the same policy
+type RepoGroup struct {
+	// Repos are the repository paths; "~" and environment variables are
+	// expanded
+	Repos []string `json:"repos"`
+
+	// ProtectedBranches are protected in these repositories on top of the
+	// global patterns
 	ProtectedBranches []string `json:"protectedBranches"`
-	DefaultRemote     string   `json:"defaultRemote"`
-	AutoConfirm       bool     `json:"autoConfirm"`
-	MaxBranchLength   int      `json:"maxBranchLength"`
+
+	// MinAgeDays keeps branches whose tip commit is younger than this many
+	// days; 0 sweeps every stale branch
+	MinAgeDays int `json:"minAgeDays"`
+}
+
+// Confirmation modes for destructive operations
+const (
+	ConfirmYesNo  = "yesno"  // Answer a y/N question
+	ConfirmCount  = "count"  // Type the number of affected branches
+	ConfirmPhrase = "phrase" // Type the configured phrase
+)
+
+// Confirmation modes for unmerged branches in forced bulk deletions
+const (
+	ForceConfirmPerBranch = "per-branch" // Show each branch's unique commits and ask y/N/all
+	ForceConfirmOnce      = "once"       // Ask once for all unmerged branches
+	ForceConfirmNone      = "none"       // Don't ask beyond the usual confirmation
+)
+
+// When the safeguards for shared clones (no gc, a lock around changes) are on
+const (
+	SharedCloneAuto   = "auto"   // When the repository borrows objects or is shared
+	SharedCloneAlways = "always" // E.g. in the cache other clones borrow from
+	SharedCloneNever  = "never"  // Never
+)
+
+// Status indicators that can be restyled in the display config
+const (
+	IndicatorCurrent      = "current"
+	IndicatorDefault      = "default"
+	IndicatorMerged       = "merged"
+	IndicatorUnmerged     = "unmerged"
+	IndicatorStale        = "stale"
+	IndicatorRecentlyUsed = "recentlyUsed"
+	IndicatorLocal        = "local"  // Marks local branches in the selector
+	IndicatorRemote       = "remote" // Marks remote branches in the selector
+)
+
+// Indicators are the status indicators, for validation
+var Indicators = []string{IndicatorCurrent, IndicatorDefault, IndicatorMerged, IndicatorUnmerged,
+	IndicatorStale, IndicatorRecentlyUsed, IndicatorLocal, IndicatorRemote}
+
+// DisplayColors are the colors indicators can have; "none" leaves them
+// uncolored
+var DisplayColors = []string{"red", "green", "yellow", "blue", "magenta", "cyan", "white", "gray", "none"}
+
+// Ways of detecting merged branches
+const (
+	MergeStrategyAncestry = "ancestry" // The target contains the branch tip
+	MergeStrategyCherry   = "cherry"   // Each commit has a patch-equivalent commit in the target
+)
+
+// Commit text shown in branch listings
+const (
+	MessageSubject          = "subject"           // The subject line
+	MessageSubjectTruncated = "subject-truncated" // The subject line, shortened to fit
+	MessageFull             = "full"              // Subject and body on one line
+)
+
+// DefaultTrashDays is how many days deleted branches stay in the trash
+const DefaultTrashDays = 30
+
+// AgeBucket tags the branches whose tip commit is less than Days days old
+// and too old for the buckets before it
+type AgeBucket struct {
+	Name string `json:"name"`
+	Days int    `json:"days"`
+}
+
+// DefaultAgeBuckets are the age buckets used when none are configured
+var DefaultAgeBuckets = []AgeBucket{
+	{Name: "today", Days: 1},
+	{Name: "<1w", Days: 7},
+	{Name: "<1m", Days: 30},
+	{Name: "<3m", Days: 90},
+}
+
+// OlderBucket tags branches older than every age bucket
+const OlderBucket = "older"
+
+// Confirmation controls how destructive operations are confirmed
+type Confirmation struct {
+	Mode   string `json:"mode"`   // One of the Confirm* modes; empty means yesno
+	Phrase string `json:"phrase"` // Text to type in phrase mode
+	Banner string `json:"banner"` // Shown before every destructive operation
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Version:           CurrentVersion,
 		DefaultBranch:     "main",
 		ProtectedBranches: []string{"main", "master", "develop"},
 		DefaultRemote:     "origin",
 		AutoConfirm:       false,
 		MaxBranchLength:   255, // Git's limit
+		Confirmation:      Confirmation{Mode: ConfirmYesNo},
 	}
 }
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	// Validate protected branches
-	for _, branch := range c.ProtectedBranches {
+	for _, branch := range append(c.ProtectedBranches, c.ProtectedRemoteBranches...) {
 		if strings.TrimSpace(branch) == "" {
 			return fmt.Errorf("protected branch name cannot be empty")
 		}
 		if len(branch) > c.MaxBranchLength {
 			return fmt.Errorf("protected branch name too long: %s", branch)
 		}
+		if _, err := path.Match(branch, ""); err != nil {
+			return fmt.Errorf("invalid protected branch pattern %q: %w", branch, err)
+		}
 	}
 
 	// Validate remote name
@@ -54,12 +371,414 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid max branch length: %d", c.MaxBranchLength)
 	}
 
+	// Validate branch path templates
+	for pattern, p := range c.BranchPaths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+		if strings.TrimSpace(p) == "" || strings.HasPrefix(p, "/") {
+			return fmt.Errorf("branch pattern %q must map to a repository-relative path", pattern)
+		}
+	}
+
+	// Validate ref filters
+	for _, pattern := range append(c.IncludeRefs, c.ExcludeRefs...) {
+		if !strings.HasPrefix(pattern, "refs/") {
+			return fmt.Errorf("ref pattern %q must start with refs/", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ref pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Validate merge strategies
+	for _, strategy := range c.MergeStrategies {
+		if strategy != MergeStrategyAncestry && strategy != MergeStrategyCherry {
+			return fmt.Errorf("invalid merge strategy: %s", strategy)
+		}
+	}
+
+	// Validate merge targets
+	for _, pattern := range c.MergedTargets {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("merge target cannot be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid merge target pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Validate environment allowlist
+	for _, name := range c.ExtraEnvAllowlist {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+	}
+
+	// Validate SSH commands
+	for remote, command := range c.SSHCommands {
+		if !remoteNamePattern.MatchString(remote) {
+			return fmt.Errorf("invalid remote name in sshCommands: %q", remote)
+		}
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("empty SSH command for remote %s", remote)
+		}
+	}
+	for remote, auth := range c.RemoteAuth {
+		if err := auth.validate(remote); err != nil {
+			return err
+		}
+		if auth.SSHKey != "" && c.SSHCommands[remote] != "" {
+			return fmt.Errorf("remote %s has both an sshKey in remoteAuth and an SSH command in sshCommands", remote)
+		}
+	}
+
+	if c.LearnIgnoresAfter < 0 {
+		return fmt.Errorf("learnIgnoresAfter can't be negative")
+	}
+	if c.APIRateLimit < 0 {
+		return fmt.Errorf("apiRateLimit can't be negative")
+	}
+
+	for _, key := range c.TicketTrailers {
+		if err := git.ValidateTrailerKey(key); err != nil {
+			return fmt.Errorf("ticketTrailers: %w", err)
+		}
+	}
+	if err := c.TicketTracker.validate(); err != nil {
+		return err
+	}
+	if err := c.SharedProtection.validate(); err != nil {
+		return err
+	}
+	for _, env := range c.Deployments.Environments {
+		if strings.TrimSpace(env) == "" {
+			return fmt.Errorf("deployment environment cannot be empty")
+		}
+	}
+	if len(c.Deployments.Environments) > 0 && !c.Deployments.Protect {
+		return fmt.Errorf("deployments environments need protect: true")
+	}
+
+	switch c.SharedClone {
+	case "", SharedCloneAuto, SharedCloneAlways, SharedCloneNever:
+	default:
+		return fmt.Errorf("invalid sharedClone mode: %s", c.SharedClone)
+	}
+
+	if err := c.Display.validate(); err != nil {
+		return err
+	}
+
+	if err := validateURLTemplate(c.PRURLTemplate); err != nil {
+		return err
+	}
+
+	// Validate message display
+	switch c.MessageDisplay {
+	case "", MessageSubject, MessageSubjectTruncated, MessageFull:
+	default:
+		return fmt.Errorf("invalid message display: %s", c.MessageDisplay)
+	}
+
+	// Validate age buckets
+	for i, b := range c.AgeBuckets {
+		if strings.TrimSpace(b.Name) == "" {
+			return fmt.Errorf("age bucket name cannot be empty")
+		}
+		if b.Days <= 0 {
+			return fmt.Errorf("age bucket %q must span a positive number of days", b.Name)
+		}
+		if i > 0 && b.Days <= c.AgeBuckets[i-1].Days {
+			return fmt.Errorf("age bucket %q must be older than %q", b.Name, c.AgeBuckets[i-1].Name)
+		}
+	}
+
+	// Validate confirmation mode
+	switch c.Confirmation.Mode {
+	case "", ConfirmYesNo, ConfirmCount:
+	case ConfirmPhrase:
+		if strings.TrimSpace(c.Confirmation.Phrase) == "" {
+			return fmt.Errorf("confirmation phrase cannot be empty in phrase mode")
+		}
+	default:
+		return fmt.Errorf("invalid confirmation mode: %s", c.Confirmation.Mode)
+	}
+
+	// Validate force confirmation mode
+	switch c.ForceConfirmation {
+	case "", ForceConfirmPerBranch, ForceConfirmOnce, ForceConfirmNone:
+	default:
+		return fmt.Errorf("invalid force confirmation mode: %s", c.ForceConfirmation)
+	}
+
+	for name, group := range c.RepoGroups {
+		if err := group.validate(name); err != nil {
+			return err
+		}
+	}
+
+	return c.validateAliases()
+}
+
+// groupNamePattern matches repository group names, which are passed as
+// sweep --group
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// validate checks the repository group called name
+func (g RepoGroup) validate(name string) error {
+	if !groupNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid repository group name %q", name)
+	}
+	if len(g.Repos) == 0 {
+		return fmt.Errorf("repository group %q has no repos", name)
+	}
+	for _, repo := range g.Repos {
+		if strings.TrimSpace(repo) == "" {
+			return fmt.Errorf("repository group %q has an empty repo path", name)
+		}
+	}
+	for _, branch := range g.ProtectedBranches {
+		if _, err := path.Match(branch, ""); err != nil || strings.TrimSpace(branch) == "" {
+			return fmt.Errorf("invalid protected branch pattern %q in repository group %q", branch, name)
+		}
+	}
+	if g.MinAgeDays < 0 {
+		return fmt.Errorf("repository group %q: minAgeDays can't be negative", name)
+	}
+	return nil
+}
+
+// remoteNamePattern matches git remote names
+var remoteNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// urlPlaceholderPattern matches the placeholders of PRURLTemplate
+var urlPlaceholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// validateURLTemplate checks a PRURLTemplate: an http(s) URL naming the
+// branch, with no placeholders but {branch}, {host} and {repo}
+func validateURLTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.HasPrefix(template, "https://") && !strings.HasPrefix(template, "http://") {
+		return fmt.Errorf("prUrlTemplate must be an http or https URL: %s", template)
+	}
+	for _, placeholder := range urlPlaceholderPattern.FindAllString(template, -1) {
+		switch placeholder {
+		case "{branch}", "{host}", "{repo}":
+		default:
+			return fmt.Errorf("unknown placeholder %s in prUrlTemplate, want {branch}, {host} or {repo}", placeholder)
+		}
+	}
+	if !strings.Contains(template, "{branch}") {
+		return fmt.Errorf("prUrlTemplate must contain {branch}: %s", template)
+	}
+	return nil
+}
+
+// validate checks indicators and colors exist and labels fit on a line
+func (d Display) validate() error {
+	for name, style := range d.Indicators {
+		if !slices.Contains(Indicators, name) {
+			return fmt.Errorf("unknown indicator in display: %q (valid: %s)", name, strings.Join(Indicators, ", "))
+		}
+		if strings.ContainsAny(style.Label, "\r\n\t") {
+			return fmt.Errorf("display label of %s must fit on one line", name)
+		}
+		if style.Color != "" && !slices.Contains(DisplayColors, style.Color) {
+			return fmt.Errorf("invalid display color of %s: %s (valid: %s)", name, style.Color, strings.Join(DisplayColors, ", "))
+		}
+	}
 	return nil
 }
 
+// validate checks the list's URL and file are usable
+func (s SharedProtection) validate() error {
+	if s.URL != "" && !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://") {
+		return fmt.Errorf("sharedProtection url must be an http or https URL: %s", s.URL)
+	}
+	if s.RemoteFile != "" && s.RemoteFile != SharedProtectionOff {
+		if strings.HasPrefix(s.RemoteFile, "/") || slices.Contains(strings.Split(s.RemoteFile, "/"), "..") {
+			return fmt.Errorf("sharedProtection remoteFile must be a repository-relative path: %s", s.RemoteFile)
+		}
+	}
+	if s.Required && s.URL == "" {
+		return fmt.Errorf("sharedProtection required needs a url")
+	}
+	return nil
+}
+
+// validate checks the authentication of remote is complete and unambiguous
+func (a RemoteAuth) validate(remote string) error {
+	if !remoteNamePattern.MatchString(remote) {
+		return fmt.Errorf("invalid remote name in remoteAuth: %q", remote)
+	}
+	if a.TokenEnv != "" && !envNamePattern.MatchString(a.TokenEnv) || strings.HasSuffix(a.TokenEnv, "*") {
+		return fmt.Errorf("invalid tokenEnv of remote %s: %q", remote, a.TokenEnv)
+	}
+	if a.TokenUser != "" && a.TokenEnv == "" {
+		return fmt.Errorf("tokenUser of remote %s needs a tokenEnv", remote)
+	}
+	if strings.Contains(a.TokenUser, ":") {
+		return fmt.Errorf("tokenUser of remote %s can't contain a colon", remote)
+	}
+	if a.TokenEnv != "" && a.CredentialHelper != "" {
+		return fmt.Errorf("remote %s has both a tokenEnv and a credentialHelper; pick one", remote)
+	}
+	for from, to := range a.URLRewrites {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return fmt.Errorf("urlRewrites of remote %s can't have empty URLs", remote)
+		}
+	}
+	return nil
+}
+
+// SharedProtectionFile returns the path of the shared protected branch list
+// on the default branch, or "" when none is read
+func (c *Config) SharedProtectionFile() string {
+	switch c.SharedProtection.RemoteFile {
+	case "":
+		return DefaultSharedProtectionFile
+	case SharedProtectionOff:
+		return ""
+	}
+	return c.SharedProtection.RemoteFile
+}
+
+// validate checks the tracker is complete when configured
+func (t TicketTracker) validate() error {
+	if t.URL == "" {
+		if t.StatusField != "" || len(t.DoneStatuses) > 0 {
+			return fmt.Errorf("ticketTracker needs a url")
+		}
+		return nil
+	}
+	if !strings.HasPrefix(t.URL, "https://") && !strings.HasPrefix(t.URL, "http://") {
+		return fmt.Errorf("ticketTracker url must be an http or https URL: %s", t.URL)
+	}
+	if !strings.Contains(t.URL, "{ticket}") {
+		return fmt.Errorf("ticketTracker url must contain {ticket}: %s", t.URL)
+	}
+	if strings.TrimSpace(t.StatusField) == "" {
+		return fmt.Errorf("ticketTracker needs a statusField, e.g. fields.status.name")
+	}
+	if len(t.DoneStatuses) == 0 {
+		return fmt.Errorf("ticketTracker needs doneStatuses, e.g. [\"Done\"]")
+	}
+	return nil
+}
+
+// envNamePattern matches environment variable names, optionally ending in "*"
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\*?$|^\*$`)
+
+// suspiciousEnv are environment variables, in ExtraEnvAllowlist syntax, that
+// change what git runs or which repository it works on, with why allowing
+// them is risky
+var suspiciousEnv = []struct{ name, reason string }{
+	{"LD_*", "loads libraries into git and its helpers"},
+	{"DYLD_*", "loads libraries into git and its helpers"},
+	{"BASH_ENV", "runs code in git's shell helpers"},
+	{"ENV", "runs code in git's shell helpers"},
+	{"GIT_CONFIG*", "changes git's configuration"},
+	{"GIT_EXEC_PATH", "changes the programs git runs"},
+	{"GIT_SSL_NO_VERIFY", "turns off TLS certificate checks"},
+	{"GIT_SSH", "changes the programs git runs"},
+	{"GIT_SSH_COMMAND", "changes the programs git runs"},
+	{"GIT_PROXY_COMMAND", "changes the programs git runs"},
+	{"GIT_ASKPASS", "changes the programs git runs"},
+	{"GIT_EDITOR", "changes the programs git runs"},
+	{"GIT_DIR", "points git at another repository"},
+	{"GIT_WORK_TREE", "points git at another repository"},
+	{"GIT_COMMON_DIR", "points git at another repository"},
+	{"GIT_INDEX_FILE", "points git at another repository"},
+	{"GIT_OBJECT_DIRECTORY", "points git at another repository"},
+	{"GIT_ALTERNATE_OBJECT_DIRECTORIES", "points git at another repository"},
+	{"GIT_NAMESPACE", "points git at another repository"},
+}
+
+// envWarnings returns warnings for the entries of ExtraEnvAllowlist that
+// are risky to pass to git. They are still allowed, as they can be
+// legitimate.
+func (c *Config) envWarnings() []string {
+	var warnings []string
+	for _, name := range c.ExtraEnvAllowlist {
+		if name == "*" || name == "GIT_*" {
+			warnings = append(warnings, fmt.Sprintf("extraEnvAllowlist entry %q matches too many variables", name))
+			continue
+		}
+		for _, s := range suspiciousEnv {
+			if envNamesOverlap(name, s.name) {
+				warnings = append(warnings, fmt.Sprintf("extraEnvAllowlist entry %q %s", name, s.reason))
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// envNamesOverlap reports whether two environment variable names, either of
+// which may be a prefix ending in "*", can match the same variable
+func envNamesOverlap(a, b string) bool {
+	pa, wa := strings.CutSuffix(a, "*")
+	pb, wb := strings.CutSuffix(b, "*")
+	switch {
+	case wa && wb:
+		return strings.HasPrefix(pa, pb) || strings.HasPrefix(pb, pa)
+	case wa:
+		return strings.HasPrefix(pb, pa)
+	case wb:
+		return strings.HasPrefix(pa, pb)
+	}
+	return a == b
+}
+
+// RemoteProtectedBranches returns the patterns protecting remote branches,
+// which default to ProtectedBranches
+func (c *Config) RemoteProtectedBranches() []string {
+	if c.ProtectedRemoteBranches != nil {
+		return c.ProtectedRemoteBranches
+	}
+	return c.ProtectedBranches
+}
+
+// UsesMergeStrategy reports whether merged branches are detected with
+// strategy, one of the MergeStrategy* strategies
+func (c *Config) UsesMergeStrategy(strategy string) bool {
+	return strategy == MergeStrategyAncestry || slices.Contains(c.MergeStrategies, strategy)
+}
+
+// TrashRetention returns how long deleted branches are kept in the trash, or
+// 0 when they aren't backed up
+func (c *Config) TrashRetention() time.Duration {
+	switch {
+	case c.TrashDays < 0:
+		return 0
+	case c.TrashDays == 0:
+		return DefaultTrashDays * 24 * time.Hour
+	}
+	return time.Duration(c.TrashDays) * 24 * time.Hour
+}
+
+// AgeBucket returns the name of the age bucket a commit of the given age
+// falls in, and its position: 0 for the youngest bucket, up to the number
+// of buckets for OlderBucket
+func (c *Config) AgeBucket(age time.Duration) (string, int) {
+	buckets := c.AgeBuckets
+	if len(buckets) == 0 {
+		buckets = DefaultAgeBuckets
+	}
+	for i, b := range buckets {
+		if age < time.Duration(b.Days)*24*time.Hour {
+			return b.Name, i
+		}
+	}
+	return OlderBucket, len(buckets)
+}
+
 // Load loads the configuration from disk
 func Load() (*Config, error) {
-	configPath, err := getConfigPath()
+	configPath, err := Path()
 	if err != nil {
 		return DefaultConfig(), nil
 	}
@@ -70,8 +789,9 @@ func Load() (*Config, error) {
 		return DefaultConfig(), nil
 	}
 
-	// Check file permissions
-	if info.Mode().Perm()&0077 != 0 {
+	// Check file permissions. Windows protects files with ACLs and reports
+	// every writable file as 0666.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
 		return nil, fmt.Errorf("config file has unsafe permissions: %s", configPath)
 	}
 
@@ -82,27 +802,38 @@ func Load() (*Config, error) {
 	}
 	defer f.Close()
 
-	var config Config
-	if err := json.NewDecoder(f).Decode(&config); err != nil {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return DefaultConfig(), fmt.Errorf("failed to read config: %w", err)
+	}
+
+	config, warnings, err := parse(data)
+	if err != nil {
 		return DefaultConfig(), fmt.Errorf("failed to decode config: %w", err)
 	}
+	for _, w := range warnings {
+		log.Warn("%s: %s", configPath, w)
+	}
 
 	// Validate loaded config
 	if err := config.Validate(); err != nil {
 		return DefaultConfig(), fmt.Errorf("invalid config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // Save saves the configuration to disk
 func (c *Config) Save() error {
 	// Validate before saving
+	if c.Version < CurrentVersion {
+		c.Version = CurrentVersion
+	}
 	if err := c.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	configPath, err := getConfigPath()
+	configPath, err := Path()
 	if err != nil {
 		return err
 	}
@@ -150,8 +881,10 @@ func (c *Config) Save() error {
 	return nil
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
+// Path returns the path to the config file: .git-branch-delete/config.json
+// in the user config directory, or git-branch-delete\config.json in
+// %AppData% on Windows
+func Path() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get config directory: %w", err)
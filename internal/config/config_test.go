@@ -0,0 +1,536 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantRemote   string
+		wantLength   int
+		wantWarnings []string
+	}{
+		{
+			name:       "unversioned config gets required defaults",
+			data:       `{"protectedBranches": ["main"]}`,
+			wantRemote: "origin",
+			wantLength: 255,
+		},
+		{
+			name:       "current version is taken as is",
+			data:       `{"version": 1, "defaultRemote": "upstream", "maxBranchLength": 100}`,
+			wantRemote: "upstream",
+			wantLength: 100,
+		},
+		{
+			name:         "unknown keys are ignored with a warning",
+			data:         `{"version": 1, "defaultRemote": "origin", "maxBranchLength": 255, "zeta": 1, "alpha": true}`,
+			wantRemote:   "origin",
+			wantLength:   255,
+			wantWarnings: []string{"ignoring unknown config keys: alpha, zeta"},
+		},
+		{
+			name:       "newer version still loads",
+			data:       `{"version": 99, "defaultRemote": "origin", "maxBranchLength": 255, "futureSetting": "x"}`,
+			wantRemote: "origin",
+			wantLength: 255,
+			wantWarnings: []string{
+				"config version 99 is newer than this binary supports (1); newer settings are ignored",
+				"ignoring unknown config keys: futureSetting",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, warnings, err := parse([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRemote, config.DefaultRemote)
+			assert.Equal(t, tt.wantLength, config.MaxBranchLength)
+			assert.Equal(t, tt.wantWarnings, warnings)
+			assert.GreaterOrEqual(t, config.Version, CurrentVersion)
+			assert.NoError(t, config.Validate())
+		})
+	}
+}
+
+func TestEnvAllowlist(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowlist    []string
+		wantErr      bool
+		wantWarnings []string
+	}{
+		{
+			name:      "proxies and askpass are fine",
+			allowlist: []string{"HTTPS_PROXY", "https_proxy", "NO_PROXY", "SSH_ASKPASS", "CORP_*"},
+		},
+		{
+			name:      "invalid name",
+			allowlist: []string{"HTTPS PROXY"},
+			wantErr:   true,
+		},
+		{
+			name:      "wildcard in the middle",
+			allowlist: []string{"GIT_*_PATH"},
+			wantErr:   true,
+		},
+		{
+			name:         "loader variables",
+			allowlist:    []string{"LD_PRELOAD"},
+			wantWarnings: []string{`extraEnvAllowlist entry "LD_PRELOAD" loads libraries into git and its helpers`},
+		},
+		{
+			name:         "wildcard covering a risky variable",
+			allowlist:    []string{"GIT_SSL_*"},
+			wantWarnings: []string{`extraEnvAllowlist entry "GIT_SSL_*" turns off TLS certificate checks`},
+		},
+		{
+			name:      "programs git runs",
+			allowlist: []string{"GIT_SSH_COMMAND", "GIT_SSH", "GIT_PROXY_COMMAND", "GIT_ASKPASS", "GIT_EDITOR"},
+			wantWarnings: []string{
+				`extraEnvAllowlist entry "GIT_SSH_COMMAND" changes the programs git runs`,
+				`extraEnvAllowlist entry "GIT_SSH" changes the programs git runs`,
+				`extraEnvAllowlist entry "GIT_PROXY_COMMAND" changes the programs git runs`,
+				`extraEnvAllowlist entry "GIT_ASKPASS" changes the programs git runs`,
+				`extraEnvAllowlist entry "GIT_EDITOR" changes the programs git runs`,
+			},
+		},
+		{
+			name:      "prefix of a risky name is not a match",
+			allowlist: []string{"ENVIRONMENT", "GIT_DIRECTORY_HINT"},
+		},
+		{
+			name:         "everything",
+			allowlist:    []string{"*", "GIT_*"},
+			wantWarnings: []string{`extraEnvAllowlist entry "*" matches too many variables`, `extraEnvAllowlist entry "GIT_*" matches too many variables`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.ExtraEnvAllowlist = tt.allowlist
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+				return
+			}
+			require.NoError(t, c.Validate())
+			assert.Equal(t, tt.wantWarnings, c.envWarnings())
+		})
+	}
+}
+
+func TestAgeBucket(t *testing.T) {
+	day := 24 * time.Hour
+	custom := []AgeBucket{{Name: "fresh", Days: 14}, {Name: "aging", Days: 60}}
+
+	tests := []struct {
+		name      string
+		buckets   []AgeBucket
+		age       time.Duration
+		wantName  string
+		wantIndex int
+	}{
+		{"default today", nil, time.Hour, "today", 0},
+		{"default week", nil, 3 * day, "<1w", 1},
+		{"default month", nil, 7 * day, "<1m", 2},
+		{"default quarter", nil, 89 * day, "<3m", 3},
+		{"default older", nil, 90 * day, OlderBucket, 4},
+		{"custom first", custom, 13 * day, "fresh", 0},
+		{"custom second", custom, 14 * day, "aging", 1},
+		{"custom older", custom, 365 * day, OlderBucket, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.AgeBuckets = tt.buckets
+			require.NoError(t, c.Validate())
+			name, index := c.AgeBucket(tt.age)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantIndex, index)
+		})
+	}
+}
+
+func TestAgeBucketsInvalid(t *testing.T) {
+	for _, buckets := range [][]AgeBucket{
+		{{Name: "", Days: 1}},
+		{{Name: "never", Days: 0}},
+		{{Name: "month", Days: 30}, {Name: "week", Days: 7}},
+	} {
+		c := DefaultConfig()
+		c.AgeBuckets = buckets
+		assert.Error(t, c.Validate(), "%v", buckets)
+	}
+}
+
+func TestMessageDisplay(t *testing.T) {
+	for _, mode := range []string{"", MessageSubject, MessageSubjectTruncated, MessageFull} {
+		c := DefaultConfig()
+		c.MessageDisplay = mode
+		assert.NoError(t, c.Validate(), mode)
+	}
+
+	c := DefaultConfig()
+	c.MessageDisplay = "body"
+	assert.Error(t, c.Validate())
+}
+
+func TestSSHCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands map[string]string
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"identity per remote", map[string]string{"origin": "ssh -i ~/.ssh/id_work", "fork": "ssh -i ~/.ssh/id_personal"}, false},
+		{"empty command", map[string]string{"origin": " "}, true},
+		{"option as remote", map[string]string{"--upload-pack": "ssh"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.SSHCommands = tt.commands
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLearnIgnoresAfter(t *testing.T) {
+	c := DefaultConfig()
+	c.LearnIgnoresAfter = 3
+	assert.NoError(t, c.Validate())
+
+	c.LearnIgnoresAfter = -1
+	assert.Error(t, c.Validate())
+}
+
+func TestAPIRateLimit(t *testing.T) {
+	c := DefaultConfig()
+	c.APIRateLimit = 2.5
+	assert.NoError(t, c.Validate())
+
+	c.APIRateLimit = -1
+	assert.Error(t, c.Validate())
+}
+
+func TestTickets(t *testing.T) {
+	jira := TicketTracker{
+		URL:          "https://jira.example.com/rest/api/2/issue/{ticket}?fields=status",
+		StatusField:  "fields.status.name",
+		DoneStatuses: []string{"Done"},
+	}
+	tests := []struct {
+		name     string
+		trailers []string
+		tracker  TicketTracker
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "jira", trailers: []string{"Closes", "Jira-Ticket"}, tracker: jira},
+		{name: "trailers only", trailers: []string{"Refs"}},
+		{name: "bad trailer key", trailers: []string{"Closes:"}, wantErr: true},
+		{name: "missing url", tracker: TicketTracker{StatusField: "state"}, wantErr: true},
+		{name: "not http", tracker: TicketTracker{URL: "ftp://x/{ticket}", StatusField: "state", DoneStatuses: []string{"closed"}}, wantErr: true},
+		{name: "no placeholder", tracker: TicketTracker{URL: "https://x/issue", StatusField: "state", DoneStatuses: []string{"closed"}}, wantErr: true},
+		{name: "no status field", tracker: TicketTracker{URL: jira.URL, DoneStatuses: []string{"Done"}}, wantErr: true},
+		{name: "no done statuses", tracker: TicketTracker{URL: jira.URL, StatusField: "state"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.TicketTrailers = tt.trailers
+			c.TicketTracker = tt.tracker
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+			} else {
+				assert.NoError(t, c.Validate())
+			}
+		})
+	}
+}
+
+func TestSharedProtection(t *testing.T) {
+	tests := []struct {
+		name     string
+		shared   SharedProtection
+		wantFile string
+		wantErr  bool
+	}{
+		{name: "default", wantFile: DefaultSharedProtectionFile},
+		{name: "url", shared: SharedProtection{URL: "https://example.com/protected.yaml", Required: true}, wantFile: DefaultSharedProtectionFile},
+		{name: "other file", shared: SharedProtection{RemoteFile: "ci/protected.yaml"}, wantFile: "ci/protected.yaml"},
+		{name: "file off", shared: SharedProtection{RemoteFile: SharedProtectionOff}},
+		{name: "not http", shared: SharedProtection{URL: "file:///etc/protected"}, wantErr: true},
+		{name: "absolute file", shared: SharedProtection{RemoteFile: "/etc/protected"}, wantErr: true},
+		{name: "file outside", shared: SharedProtection{RemoteFile: "../protected.yaml"}, wantErr: true},
+		{name: "required without url", shared: SharedProtection{Required: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.SharedProtection = tt.shared
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+				return
+			}
+			assert.NoError(t, c.Validate())
+			assert.Equal(t, tt.wantFile, c.SharedProtectionFile())
+		})
+	}
+}
+
+func TestRemoteAuth(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    map[string]RemoteAuth
+		ssh     map[string]string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "per remote", auth: map[string]RemoteAuth{
+			"origin": {SSHKey: "~/.ssh/id_work"},
+			"mirror": {TokenEnv: "GITLAB_TOKEN", TokenUser: "oauth2", URLRewrites: map[string]string{"git@gitlab.com:": "https://gitlab.com/"}},
+			"backup": {CredentialHelper: "store"},
+		}},
+		{name: "ssh command elsewhere", auth: map[string]RemoteAuth{"mirror": {SSHKey: "~/.ssh/id_mirror"}}, ssh: map[string]string{"origin": "ssh -v"}},
+		{name: "invalid remote", auth: map[string]RemoteAuth{"../x": {SSHKey: "key"}}, wantErr: true},
+		{name: "invalid token env", auth: map[string]RemoteAuth{"origin": {TokenEnv: "GH-TOKEN"}}, wantErr: true},
+		{name: "token env prefix", auth: map[string]RemoteAuth{"origin": {TokenEnv: "GH_*"}}, wantErr: true},
+		{name: "user without token", auth: map[string]RemoteAuth{"origin": {TokenUser: "oauth2"}}, wantErr: true},
+		{name: "token and helper", auth: map[string]RemoteAuth{"origin": {TokenEnv: "TOKEN", CredentialHelper: "store"}}, wantErr: true},
+		{name: "empty rewrite", auth: map[string]RemoteAuth{"origin": {URLRewrites: map[string]string{"https://x/": ""}}}, wantErr: true},
+		{name: "key and ssh command", auth: map[string]RemoteAuth{"origin": {SSHKey: "key"}}, ssh: map[string]string{"origin": "ssh -v"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.RemoteAuth = tt.auth
+			c.SSHCommands = tt.ssh
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+			} else {
+				assert.NoError(t, c.Validate())
+			}
+		})
+	}
+}
+
+func TestDeployments(t *testing.T) {
+	c := DefaultConfig()
+	c.Deployments = Deployments{Protect: true, Environments: []string{"production"}}
+	assert.NoError(t, c.Validate())
+
+	c.Deployments.Environments = []string{" "}
+	assert.Error(t, c.Validate())
+
+	c.Deployments = Deployments{Environments: []string{"production"}}
+	assert.Error(t, c.Validate(), "environments without protect do nothing")
+}
+
+func TestSharedClone(t *testing.T) {
+	c := DefaultConfig()
+	for _, mode := range []string{"", SharedCloneAuto, SharedCloneAlways, SharedCloneNever} {
+		c.SharedClone = mode
+		assert.NoError(t, c.Validate(), mode)
+	}
+
+	c.SharedClone = "sometimes"
+	assert.Error(t, c.Validate())
+}
+
+func TestPRURLTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{"none", "", false},
+		{"compare page", "https://github.com/org/repo/compare/{branch}", false},
+		{"from origin", "https://{host}/{repo}/-/merge_requests/new?merge_request[source_branch]={branch}", false},
+		{"no branch", "https://github.com/org/repo/pulls", true},
+		{"unknown placeholder", "https://github.com/{owner}/repo/compare/{branch}", true},
+		{"not http", "javascript:alert('{branch}')", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.PRURLTemplate = tt.template
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMergeStrategies(t *testing.T) {
+	c := DefaultConfig()
+	assert.True(t, c.UsesMergeStrategy(MergeStrategyAncestry))
+	assert.False(t, c.UsesMergeStrategy(MergeStrategyCherry))
+
+	c.MergeStrategies = []string{MergeStrategyCherry}
+	assert.NoError(t, c.Validate())
+	assert.True(t, c.UsesMergeStrategy(MergeStrategyAncestry), "ancestry always applies")
+	assert.True(t, c.UsesMergeStrategy(MergeStrategyCherry))
+
+	c.MergeStrategies = []string{"squash"}
+	assert.Error(t, c.Validate())
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, _, err := parse([]byte(`{"version": "one"}`))
+	assert.Error(t, err)
+
+	_, _, err = parse([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestTrashRetention(t *testing.T) {
+	tests := []struct {
+		name string
+		days int
+		want time.Duration
+	}{
+		{"default", 0, DefaultTrashDays * 24 * time.Hour},
+		{"configured", 7, 7 * 24 * time.Hour},
+		{"disabled", -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{TrashDays: tt.days}
+			assert.Equal(t, tt.want, c.TrashRetention())
+		})
+	}
+}
+
+func TestRepoGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  map[string]RepoGroup
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"work and oss", map[string]RepoGroup{
+			"work": {Repos: []string{"~/src/api", "~/src/web"}, ProtectedBranches: []string{"release/*"}, MinAgeDays: 14},
+			"oss":  {Repos: []string{"$HOME/oss/tool"}},
+		}, false},
+		{"no repos", map[string]RepoGroup{"work": {}}, true},
+		{"empty repo path", map[string]RepoGroup{"work": {Repos: []string{" "}}}, true},
+		{"option as name", map[string]RepoGroup{"--all": {Repos: []string{"~/src/api"}}}, true},
+		{"bad pattern", map[string]RepoGroup{"work": {Repos: []string{"~/src/api"}, ProtectedBranches: []string{"[release"}}}, true},
+		{"negative age", map[string]RepoGroup{"work": {Repos: []string{"~/src/api"}, MinAgeDays: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.RepoGroups = tt.groups
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAliases(t *testing.T) {
+	c := DefaultConfig()
+	c.Aliases = map[string]string{
+		"nuke": "prune --force",
+		"mine": `list --touches "docs/user guide" --pr-state='!draft'`,
+	}
+	require.NoError(t, c.Validate())
+
+	args, ok := c.Alias("nuke")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"prune", "--force"}, args)
+
+	args, ok = c.Alias("mine")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"list", "--touches", "docs/user guide", "--pr-state=!draft"}, args)
+
+	_, ok = c.Alias("prune")
+	assert.False(t, ok)
+
+	invalid := map[string]map[string]string{
+		"option as name":   {"--nuke": "prune"},
+		"empty expansion":  {"nuke": "  "},
+		"unbalanced quote": {"nuke": `prune --author "Jane`},
+		"trailing escape":  {"nuke": `prune \`},
+	}
+	for name, aliases := range invalid {
+		t.Run(name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.Aliases = aliases
+			assert.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"prune  --force\t-m", []string{"prune", "--force", "-m"}},
+		{`a "b c" 'd e'`, []string{"a", "b c", "d e"}},
+		{`a\ b "c\"d" 'e\f'`, []string{"a b", `c"d`, `e\f`}},
+		{`"" x`, []string{"", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := splitArgs(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		name       string
+		indicators map[string]IndicatorStyle
+		wantErr    bool
+	}{
+		{name: "none"},
+		{name: "relabel", indicators: map[string]IndicatorStyle{IndicatorStale: {Label: "gone"}}},
+		{name: "recolor", indicators: map[string]IndicatorStyle{IndicatorMerged: {Color: "gray"}, IndicatorRemote: {Color: "none"}}},
+		{name: "unknown indicator", indicators: map[string]IndicatorStyle{"gone": {Label: "gone"}}, wantErr: true},
+		{name: "unknown color", indicators: map[string]IndicatorStyle{IndicatorStale: {Color: "orange"}}, wantErr: true},
+		{name: "multiline label", indicators: map[string]IndicatorStyle{IndicatorStale: {Label: "st\nale"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			c.Display.Indicators = tt.indicators
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+			} else {
+				assert.NoError(t, c.Validate())
+			}
+		})
+	}
+}
@@ -10,14 +10,76 @@ Configuration File:
 
 The default configuration file is located at ~/.config/git-branch-delete.yaml:
 
+	version: 1 # schema version; older files are migrated on load
 	default_branch: main
 	protected_branches:
 	  - main
 	  - master
 	  - develop
+	protected_remote_branches: # defaults to protected_branches
+	  - main
+	  - release/*
+	shared_protection: # team-wide protected patterns, on top of the ones above
+	  remote_file: .gbd-protected.yaml # on default_remote's default branch (the default); "off" reads none
+	  url: https://git.example.com/policies/protected.yaml # cached; its host's auth login token is sent
+	  required: false # refuse to run when the url can't be read and no copy is cached
+	deployments: # protect branches deployed to origin's GitHub environments
+	  protect: true
+	  environments: [production] # default: all
 	default_remote: origin
 	auto_confirm: false
 	dry_run: false
+	log_file: ~/.cache/git-branch-delete.log # same as --log-file; ~ and $VAR (%VAR% on Windows) expand
+	exclude_refs: # refs that are never branches, e.g. Gerrit's
+	  - refs/remotes/origin/for
+	  - refs/remotes/origin/changes
+	merged_targets: # merged means merged into any of these; default HEAD
+	  - main
+	  - release/*
+	  - upstream/main # <remote>/<branch> uses that remote's branch, e.g. in forks
+	merge_strategies: # ancestry (always on); cherry adds branches cherry-picked commit by commit
+	  - cherry
+	with_remote: false # delete remote counterparts without asking
+	force_confirmation: per-branch # per-branch, once or none (default) for unmerged branches with --force
+	trash_days: 30 # days deleted local branches stay restorable; -1 disables backups
+	message_display: subject # subject, subject-truncated or full commit text in listings
+	age_buckets: # tags in the interactive selector; default today, <1w, <1m, <3m, then older
+	  - {name: fresh, days: 14}
+	  - {name: aging, days: 90}
+	extra_env_allowlist: # passed to git on top of the built-in allowlist; risky entries warn
+	  - HTTPS_PROXY
+	  - SSH_ASKPASS
+	ssh_commands:           # GIT_SSH_COMMAND per remote, for multiple identities
+	  origin: ssh -i ~/.ssh/id_work
+	remote_auth:            # credentials per remote; needs git 2.31+
+	  mirror:
+	    ssh_key: ~/.ssh/id_mirror   # or token_env (+ token_user), or credential_helper
+	    url_rewrites: {"https://gitlab.com/": "git@gitlab.com:"}
+	pr_url_template: https://{host}/{repo}/compare/{branch} # branch page for o and reports
+	repo_groups:            # repositories swept together by sweep --group
+	  work:
+	    repos: [~/src/api, ~/src/web]
+	    protected_branches: [release/*] # on top of protected_branches
+	    min_age_days: 14                # keep branches younger than this
+	learn_ignores_after: 3  # hide branches left unselected in this many interactive runs
+	shared_clone: auto      # no gc and a lock around changes: auto (default), always or never
+	api_rate_limit: 5       # GitHub API requests a second at most (default: 0, no limit)
+	ticket_trailers: [Closes] # trailer keys naming tickets, e.g. "Closes: PROJ-123"
+	ticket_tracker:           # where prune --tickets-done reads ticket statuses
+	  url: https://jira.example.com/rest/api/2/issue/{ticket}
+	  status_field: fields.status.name # path of the status in the response
+	  done_statuses: [Done, Closed]
+	display:                # status indicators of list and interactive output
+	  indicators:
+	    stale: {label: gone, color: magenta} # colors: red, green, yellow, blue, magenta, cyan, white, gray, none
+	  no_emoji: true        # plain text instead of check marks and other symbols
+	aliases:                # commands of your own; built-in commands win
+	  nuke: prune --force
+	  gone: list --tracking=gone --output "table"
+	confirmation:
+	  mode: phrase            # yesno (default), count or phrase
+	  phrase: delete branches # required in phrase mode
+	  banner: Branches are not backed up
 
 Environment Variables:
 
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CurrentVersion is the config schema version this binary understands and
+// writes. Bump it together with a new entry in migrations.
+const CurrentVersion = 1
+
+// migrations upgrade a raw config from the version they are keyed by to the
+// next one
+var migrations = map[int]func(raw map[string]json.RawMessage) error{
+	0: migrateV0,
+}
+
+// migrateV0 upgrades unversioned configs, which could omit settings that
+// later became required
+func migrateV0(raw map[string]json.RawMessage) error {
+	defaults := DefaultConfig()
+	if _, ok := raw["defaultRemote"]; !ok {
+		raw["defaultRemote"], _ = json.Marshal(defaults.DefaultRemote)
+	}
+	if _, ok := raw["maxBranchLength"]; !ok {
+		raw["maxBranchLength"], _ = json.Marshal(defaults.MaxBranchLength)
+	}
+	return nil
+}
+
+// parse decodes a config file, migrating it to CurrentVersion. Unknown keys
+// and versions newer than CurrentVersion don't fail parsing; they are
+// returned as warnings so older and newer binaries can share a config.
+func parse(data []byte) (*Config, []string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	var warnings []string
+	version := 0
+	if v, ok := raw["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, nil, fmt.Errorf("invalid config version: %w", err)
+		}
+	}
+	if version > CurrentVersion {
+		warnings = append(warnings, fmt.Sprintf("config version %d is newer than this binary supports (%d); newer settings are ignored", version, CurrentVersion))
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return nil, nil, fmt.Errorf("no migration from config version %d", v)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate config from version %d: %w", v, err)
+		}
+	}
+
+	if unknown := unknownKeys(raw); len(unknown) > 0 {
+		warnings = append(warnings, fmt.Sprintf("ignoring unknown config keys: %s", strings.Join(unknown, ", ")))
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, err
+	}
+	if config.Version < CurrentVersion {
+		config.Version = CurrentVersion
+	}
+	warnings = append(warnings, config.envWarnings()...)
+	return &config, warnings, nil
+}
+
+// unknownKeys returns the sorted top-level keys of raw that Config has no
+// field for
+func unknownKeys(raw map[string]json.RawMessage) []string {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	var unknown []string
+	for key := range raw {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchivePrefix is the namespace soft-deleted remote branches are moved into
+const ArchivePrefix = "archived/"
+
+// ArchivedBranch is a soft-deleted branch living under ArchivePrefix on the remote
+type ArchivedBranch struct {
+	Name       string    `json:"name"` // Original branch name, without the prefix
+	CommitHash string    `json:"commitHash"`
+	CommitDate time.Time `json:"commitDate"` // Zero when the commit is not available locally
+
+	// ArchivedAt is when this clone archived the branch, or first fetched
+	// the archive, from the reflog of its remote-tracking branch. Zero when
+	// neither was recorded.
+	ArchivedAt time.Time `json:"archivedAt,omitempty"`
+}
+
+// remoteBranchHash returns the commit a remote branch points at
+func (g *Git) remoteBranchHash(name string) (string, error) {
+	out, err := g.execGit("ls-remote", "origin", "refs/heads/"+name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve remote branch: %w", err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("remote branch '%s' does not exist", name)
+	}
+	return fields[0], nil
+}
+
+// ArchiveRemoteBranch soft-deletes a remote branch by moving it to
+// refs/heads/archived/<name> on the remote
+func (g *Git) ArchiveRemoteBranch(name string) error {
+	if g.IsProtected(name, true) {
+		return newProtectedBranchError(name, g.protection.Pinned[name])
+	}
+	if strings.HasPrefix(name, ArchivePrefix) {
+		return fmt.Errorf("branch '%s' is already archived", name)
+	}
+
+	hash, err := g.remoteBranchHash(name)
+	if err != nil {
+		return err
+	}
+
+	// Record the archive time in the reflog of its remote-tracking branch;
+	// pushing doesn't create one when the branch is already there
+	tracking := "refs/remotes/origin/" + ArchivePrefix + name
+	_, _ = g.execGit("update-ref", "--create-reflog", tracking, hash)
+
+	// Push the archive ref first so the commit is never unreferenced
+	if _, err := g.execGit("push", "origin", hash+":refs/heads/"+ArchivePrefix+name); err != nil {
+		_, _ = g.execGit("update-ref", "-d", tracking)
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		return fmt.Errorf("failed to archive branch: %w", err)
+	}
+
+	// Only delete the original while it's still what was archived
+	if _, err := g.execGit("push", leaseArg(name, hash), "origin", "--delete", name); err != nil {
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		if isLeaseRejection(err.Error()) {
+			err = newRemoteBranchMovedError(name, hash, "")
+		}
+		return fmt.Errorf("branch archived but failed to delete original: %w", err)
+	}
+
+	return nil
+}
+
+// ListArchivedBranches lists the soft-deleted branches on the remote
+func (g *Git) ListArchivedBranches() ([]ArchivedBranch, error) {
+	out, err := g.execGit("ls-remote", "origin", "refs/heads/"+ArchivePrefix+"*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived branches: %w", err)
+	}
+
+	// Archives are aged from when they were archived, not their last commit
+	created, err := g.BranchCreations()
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []ArchivedBranch
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		b := ArchivedBranch{
+			Name:       strings.TrimPrefix(fields[1], "refs/heads/"+ArchivePrefix),
+			CommitHash: fields[0],
+		}
+
+		b.ArchivedAt = created["refs/remotes/origin/"+ArchivePrefix+b.Name]
+
+		// The commit date is only known if the object has been fetched
+		if ts, err := g.execGit("rev-list", "--no-walk", "--timestamp", b.CommitHash); err == nil {
+			if fields := strings.Fields(ts); len(fields) > 0 {
+				if unix, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+					b.CommitDate = time.Unix(unix, 0)
+				}
+			}
+		}
+
+		archived = append(archived, b)
+	}
+
+	return archived, nil
+}
+
+// DeleteArchivedBranch permanently removes an archived branch from the remote
+func (g *Git) DeleteArchivedBranch(name string) error {
+	if _, err := g.execGit("push", "origin", "--delete", ArchivePrefix+name); err != nil {
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		return fmt.Errorf("failed to purge archived branch: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveRemoteBranch(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Tracked("feature/old")
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+	require.NoError(t, g.ArchiveRemoteBranch("feature/old"))
+	assert.Equal(t, []string{ArchivePrefix + "feature/old", "main"}, r.RemoteBranches())
+
+	archived, err := g.ListArchivedBranches()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	b := archived[0]
+	assert.Equal(t, "feature/old", b.Name)
+	assert.False(t, b.CommitDate.IsZero())
+	// Aged from the archiving, not the fixture's old commit date
+	assert.WithinDuration(t, time.Now(), b.ArchivedAt, time.Minute)
+	assert.True(t, b.CommitDate.Before(b.ArchivedAt.Add(-24*time.Hour)))
+
+	require.NoError(t, g.DeleteArchivedBranch("feature/old"))
+	assert.Equal(t, []string{"main"}, r.RemoteBranches())
+}
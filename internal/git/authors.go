@@ -0,0 +1,36 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Author identifies who made a branch's most recent commit
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// BranchAuthors returns the author of the tip commit of every local branch
+func (g *Git) BranchAuthors() (map[string]Author, error) {
+	patterns := g.refPatterns("refs/heads")
+	if len(patterns) == 0 {
+		return map[string]Author{}, nil
+	}
+
+	args := append([]string{"for-each-ref", "--format", "%(refname:short)%09%(authorname)%09%(authoremail:trim)"}, patterns...)
+	out, err := g.execGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch authors: %w", err)
+	}
+
+	authors := make(map[string]Author)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 || parts[0] == "" || !g.allowsRef("refs/heads/"+parts[0]) {
+			continue
+		}
+		authors[parts[0]] = Author{Name: parts[1], Email: parts[2]}
+	}
+	return authors, nil
+}
@@ -3,72 +3,138 @@ package git
 import (
 	"context"
 	"sync"
+
+	"github.com/bral/git-branch-delete-go/internal/progress"
 )
 
-const batchSize = 10
+// DefaultBatchWorkers is how many branches a BatchProcessor works on at
+// once by default
+const DefaultBatchWorkers = 4
+
+// BatchOptions controls a BatchProcessor
+type BatchOptions struct {
+	// Workers is how many branches are processed at once; 0 means
+	// DefaultBatchWorkers
+	Workers int
+
+	// StopOnError skips the branches not started yet after the first
+	// failure. Otherwise every branch is processed whatever fails.
+	StopOnError bool
 
-type batchOperation struct {
-	changes  []change
-	rollback func() error
+	// Progress is called after each branch, one call at a time and in the
+	// order branches finish. Workers wait while it runs, so a slow consumer
+	// such as a terminal UI slows processing down rather than piling up
+	// results.
+	Progress func(BatchProgress)
+
+	// Events, when set, receives the same progress as events, for UIs
+	// rendering it elsewhere. Skipped branches are counted in Total but
+	// never reported.
+	Events chan<- progress.Event[BatchResult]
 }
 
-type change struct {
-	branch   GitBranch
-	action   string
-	rollback func() error
+// BatchResult is the outcome of processing one branch
+type BatchResult struct {
+	Branch  GitBranch
+	Err     error
+	Skipped bool // Not processed, after a failure with StopOnError or cancellation
 }
 
+// BatchProgress reports how far a batch got
+type BatchProgress struct {
+	Done   int // Branches finished so far
+	Failed int
+	Total  int
+	Last   BatchResult // The branch that just finished
+}
+
+// BatchProcessor runs an operation on many branches with a bounded number
+// of workers
 type BatchProcessor struct {
-	git *Git
+	git  *Git
+	opts BatchOptions
 }
 
-func NewBatchProcessor(g *Git) *BatchProcessor {
+// NewBatchProcessor returns a BatchProcessor for the branches of g
+func NewBatchProcessor(g *Git, opts BatchOptions) *BatchProcessor {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultBatchWorkers
+	}
 	return &BatchProcessor{
-		git: g,
+		git:  g,
+		opts: opts,
 	}
 }
 
-func (bp *BatchProcessor) ProcessBranches(ctx context.Context, branches []GitBranch, fn func(GitBranch) error) error {
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(branches))
+// ProcessBranches calls fn for each branch and returns a result per branch,
+// in the order of branches. Branches not started when ctx is done are
+// skipped, and ctx's error is returned with the results.
+func (bp *BatchProcessor) ProcessBranches(ctx context.Context, branches []GitBranch, fn func(GitBranch) error) ([]BatchResult, error) {
+	results := make([]BatchResult, len(branches))
+	for i, b := range branches {
+		results[i] = BatchResult{Branch: b, Skipped: true}
+	}
 
-	for i := 0; i < len(branches); i += batchSize {
-		end := min(i+batchSize, len(branches))
-		batch := branches[i:end]
+	type outcome struct {
+		index int
+		err   error
+	}
+	jobs := make(chan int)
+	outcomes := make(chan outcome)
 
+	var wg sync.WaitGroup
+	for range min(bp.opts.Workers, len(branches)) {
 		wg.Add(1)
-		go func(batch []GitBranch) {
+		go func() {
 			defer wg.Done()
-			for _, branch := range batch {
-				if err := fn(branch); err != nil {
-					errChan <- err
-					return
-				}
+			for i := range jobs {
+				outcomes <- outcome{index: i, err: fn(branches[i])}
 			}
-		}(batch)
+		}()
 	}
 
-	// Wait for all goroutines to finish
-	done := make(chan struct{})
+	// stop closes when no more branches should be started
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	go func() {
+		defer close(jobs)
+		for i := range branches {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 	go func() {
 		wg.Wait()
-		close(done)
+		close(outcomes)
 	}()
 
-	// Wait for completion or context cancellation
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
-		return err
-	case <-done:
-		return nil
-	}
-}
+	rep := progress.Start(bp.opts.Events, len(branches))
+	defer rep.Finish()
 
-func min(a, b int) int {
-	if a < b {
-		return a
+	status := BatchProgress{Total: len(branches)}
+	for o := range outcomes {
+		results[o.index] = BatchResult{Branch: branches[o.index], Err: o.err}
+		status.Done++
+		if o.err != nil {
+			rep.Failed(results[o.index])
+			status.Failed++
+			if bp.opts.StopOnError {
+				stopOnce.Do(func() { close(stop) })
+			}
+		} else {
+			rep.Completed(results[o.index])
+		}
+		if bp.opts.Progress != nil {
+			status.Last = results[o.index]
+			bp.opts.Progress(status)
+		}
 	}
-	return b
+	stopOnce.Do(func() { close(stop) })
+
+	return results, ctx.Err()
 }
@@ -0,0 +1,152 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchBranches(n int) []GitBranch {
+	branches := make([]GitBranch, n)
+	for i := range branches {
+		branches[i] = GitBranch{Name: fmt.Sprintf("feature/%02d", i)}
+	}
+	return branches
+}
+
+func TestBatchProcessorResults(t *testing.T) {
+	branches := batchBranches(25)
+	var running, peak atomic.Int32
+	var updates []BatchProgress
+
+	bp := NewBatchProcessor(nil, BatchOptions{
+		Workers:  3,
+		Progress: func(p BatchProgress) { updates = append(updates, p) },
+	})
+	results, err := bp.ProcessBranches(context.Background(), branches, func(b GitBranch) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if b.Name == "feature/03" || b.Name == "feature/17" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Every branch is processed despite failures, results in input order
+	require.Len(t, results, 25)
+	for i, r := range results {
+		assert.Equal(t, branches[i].Name, r.Branch.Name)
+		assert.False(t, r.Skipped)
+		assert.Equal(t, i == 3 || i == 17, r.Err != nil, r.Branch.Name)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3), "at most Workers branches at once")
+
+	require.Len(t, updates, 25)
+	last := updates[len(updates)-1]
+	assert.Equal(t, BatchProgress{Done: 25, Failed: 2, Total: 25, Last: last.Last}, last)
+	for i, u := range updates {
+		assert.Equal(t, i+1, u.Done)
+	}
+}
+
+func TestBatchProcessorEvents(t *testing.T) {
+	events := make(chan progress.Event[BatchResult])
+	var got []progress.Event[BatchResult]
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		for ev := range events {
+			got = append(got, ev)
+		}
+	}()
+
+	bp := NewBatchProcessor(nil, BatchOptions{Workers: 2, Events: events})
+	_, err := bp.ProcessBranches(context.Background(), batchBranches(5), func(b GitBranch) error {
+		if b.Name == "feature/02" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	close(events)
+	<-received
+
+	require.Len(t, got, 7)
+	assert.Equal(t, progress.Event[BatchResult]{Kind: progress.Started, Total: 5}, got[0])
+	assert.Equal(t, progress.Event[BatchResult]{Kind: progress.Finished, Done: 5, Failed: 1, Total: 5}, got[6])
+	for _, ev := range got[1:6] {
+		require.NotNil(t, ev.Item)
+		want := progress.Completed
+		if ev.Item.Branch.Name == "feature/02" {
+			want = progress.Failed
+		}
+		assert.Equal(t, want, ev.Kind, ev.Item.Branch.Name)
+	}
+}
+
+func TestBatchProcessorStopOnError(t *testing.T) {
+	branches := batchBranches(20)
+	bp := NewBatchProcessor(nil, BatchOptions{Workers: 1, StopOnError: true})
+	results, err := bp.ProcessBranches(context.Background(), branches, func(b GitBranch) error {
+		if b.Name == "feature/05" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, results[5].Err)
+	for _, r := range results[:5] {
+		assert.NoError(t, r.Err)
+		assert.False(t, r.Skipped)
+	}
+	// One worker may already have taken the next branch
+	skipped := 0
+	for _, r := range results[6:] {
+		if r.Skipped {
+			skipped++
+		}
+	}
+	assert.GreaterOrEqual(t, skipped, 13)
+}
+
+func TestBatchProcessorCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var processed atomic.Int32
+
+	bp := NewBatchProcessor(nil, BatchOptions{Workers: 2})
+	results, err := bp.ProcessBranches(ctx, batchBranches(50), func(b GitBranch) error {
+		if processed.Add(1) == 4 {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	require.Len(t, results, 50)
+	assert.True(t, results[49].Skipped)
+	assert.Less(t, int(processed.Load()), 50)
+}
+
+func TestBatchProcessorEmpty(t *testing.T) {
+	results, err := NewBatchProcessor(nil, BatchOptions{}).ProcessBranches(context.Background(), nil, func(GitBranch) error {
+		t.Fatal("fn called without branches")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
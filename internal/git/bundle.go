@@ -0,0 +1,135 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+)
+
+// BundleRef is a branch stored in a bundle
+type BundleRef struct {
+	Name       string `json:"name"`
+	CommitHash string `json:"commit"`
+}
+
+// CreateBundle writes the history of the named local branches to a bundle
+// file at path, from which they can be restored after being deleted, and
+// returns the tips of all of them. Commits reachable from the base branch
+// (e.g. the default branch) are left out; the bundle then needs that history
+// to be unbundled. Branches holding nothing beyond base can't be stored in
+// such a bundle at all, so no file is written when every branch is merged:
+// the returned tips are all it takes to restore them.
+func (g *Git) CreateBundle(path string, names []string, base string) ([]BundleRef, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no branches to bundle")
+	}
+
+	baseRef := ""
+	if base != "" {
+		// Without a base the whole history is bundled
+		if ref, err := g.ResolveBase(base); err == nil {
+			baseRef = ref
+		}
+	}
+
+	tips := make([]BundleRef, 0, len(names))
+	var refs []string
+	for _, name := range names {
+		if err := pkggit.ValidateBranchName(name); err != nil {
+			return nil, err
+		}
+		ref := "refs/heads/" + name
+		commit, err := g.ResolveRef(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bundle: %w", err)
+		}
+		tips = append(tips, BundleRef{Name: name, CommitHash: commit})
+
+		if baseRef != "" {
+			merged, err := g.ContainedIn(ref, baseRef)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create bundle: %w", err)
+			}
+			if merged {
+				continue
+			}
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) == 0 {
+		return tips, nil
+	}
+
+	if baseRef != "" {
+		refs = append(refs, "--not", baseRef)
+	}
+	if _, err := g.execBundle([]string{"bundle", "create", "--quiet"}, path, refs...); err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	return tips, nil
+}
+
+// BundleBranches returns the branches stored in the bundle at path
+func (g *Git) BundleBranches(path string) ([]BundleRef, error) {
+	out, err := g.execBundle([]string{"bundle", "list-heads"}, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	return parseBundleHeads(out), nil
+}
+
+// Unbundle copies the objects of the bundle at path into the repository and
+// returns the branches it stores, ready for RestoreBranch
+func (g *Git) Unbundle(path string) ([]BundleRef, error) {
+	out, err := g.execBundle([]string{"bundle", "unbundle"}, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unbundle: %w", err)
+	}
+	return parseBundleHeads(out), nil
+}
+
+// RestoreBranch recreates a branch at the commit it pointed at. It fails
+// rather than overwrite a branch of the same name.
+func (g *Git) RestoreBranch(ref BundleRef) error {
+	if err := pkggit.ValidateBranchName(ref.Name); err != nil {
+		return err
+	}
+
+	// An empty old value makes update-ref refuse existing branches
+	if _, err := g.execGit("update-ref", "refs/heads/"+ref.Name, ref.CommitHash, ""); err != nil {
+		return fmt.Errorf("failed to restore branch %s: %w", ref.Name, err)
+	}
+	return nil
+}
+
+// execBundle runs a git bundle command on the bundle file at path. File
+// arguments are rejected by the usual argument validation, so the path is
+// held to ValidateBundlePath instead.
+func (g *Git) execBundle(command []string, path string, refs ...string) (string, error) {
+	if err := ValidateBundlePath(path); err != nil {
+		return "", err
+	}
+	if err := validateGitArgs(append(command, refs...)); err != nil {
+		return "", err
+	}
+
+	args := append(append(command, path), refs...)
+	return g.runGit(nil, args...)
+}
+
+// parseBundleHeads parses the "<hash> <ref>" lines git prints for a bundle,
+// keeping only branches
+func parseBundleHeads(out string) []BundleRef {
+	var refs []BundleRef
+	for _, line := range strings.Split(out, "\n") {
+		hash, ref, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			refs = append(refs, BundleRef{Name: name, CommitHash: hash})
+		}
+	}
+	return refs
+}
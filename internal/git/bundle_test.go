@@ -0,0 +1,91 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	run("checkout", "-b", "feature/unmerged")
+	run("commit", "--allow-empty", "-m", "only here")
+	run("checkout", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	tip, err := g.ResolveRef("refs/heads/feature/unmerged")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "backup.bundle")
+	tips, err := g.CreateBundle(path, []string{"feature/unmerged", "feature/test"}, "")
+	require.NoError(t, err)
+	assert.Len(t, tips, 2)
+
+	refs, err := g.BundleBranches(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"feature/unmerged", "feature/test"}, []string{refs[0].Name, refs[1].Name})
+
+	// Drop the branch and its commit, then bring both back
+	run("branch", "-D", "feature/unmerged")
+	run("reflog", "expire", "--expire=now", "--all")
+	run("gc", "--prune=now", "--quiet")
+
+	refs, err = g.Unbundle(path)
+	require.NoError(t, err)
+	for _, ref := range refs {
+		if ref.Name == "feature/unmerged" {
+			assert.Equal(t, tip, ref.CommitHash)
+			require.NoError(t, g.RestoreBranch(ref))
+		}
+	}
+
+	restored, err := g.ResolveRef("refs/heads/feature/unmerged")
+	require.NoError(t, err)
+	assert.Equal(t, tip, restored)
+
+	// Existing branches are never overwritten
+	assert.Error(t, g.RestoreBranch(BundleRef{Name: "feature/test", CommitHash: tip}))
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := g.CreateBundle("backup.bundle", []string{"feature/test"}, "")
+		assert.Error(t, err)
+		_, err = g.BundleBranches(filepath.Join(t.TempDir(), "backup.txt"))
+		assert.Error(t, err)
+	})
+
+	t.Run("thin bundle", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "thin.bundle")
+		tips, err := g.CreateBundle(path, []string{"feature/unmerged", "feature/test"}, "main")
+		require.NoError(t, err)
+		mainTip, err := g.ResolveRef("refs/heads/main")
+		require.NoError(t, err)
+		assert.Equal(t, []BundleRef{{Name: "feature/unmerged", CommitHash: tip}, {Name: "feature/test", CommitHash: mainTip}}, tips)
+
+		// Merged branches are only in the returned tips
+		refs, err := g.BundleBranches(path)
+		require.NoError(t, err)
+		assert.Equal(t, []BundleRef{{Name: "feature/unmerged", CommitHash: tip}}, refs)
+	})
+
+	t.Run("all merged", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "merged.bundle")
+		tips, err := g.CreateBundle(path, []string{"feature/test", "feature/test2"}, "main")
+		require.NoError(t, err)
+		assert.Len(t, tips, 2)
+		assert.NoFileExists(t, path)
+	})
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetCherryMerged sets whether branches whose every commit was
+// cherry-picked into a merge target count as merged, even without a merge
+// or squash commit. Commits are matched by patch ID, as `git cherry` does.
+func (g *Git) SetCherryMerged(enabled bool) {
+	g.cherryMerged = enabled
+	g.invalidateMerged()
+}
+
+// CherryPickedBranches returns the local (or, with remote set,
+// remote-tracking) branches not merged into target whose every commit has
+// a patch-equivalent commit in target. Results are cached like
+// MergedBranches.
+func (g *Git) CherryPickedBranches(target string, remote bool) (map[string]bool, error) {
+	key := mergedKey{target: target, remote: remote, cherry: true}
+	if picked, ok := g.cachedMerged(key); ok {
+		return picked, nil
+	}
+
+	merged, err := g.MergedBranches(target, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := "refs/heads/"
+	if remote {
+		namespace = "refs/remotes/"
+	}
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)", namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	picked := make(map[string]bool)
+	for _, ref := range strings.Split(out, "\n") {
+		name := strings.TrimPrefix(ref, namespace)
+		if ref == "" || merged[name] || strings.HasSuffix(ref, "/HEAD") {
+			continue
+		}
+		ok, err := g.cherryPicked(ref, target)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			picked[name] = true
+		}
+	}
+
+	g.cacheMerged(key, picked)
+	return picked, nil
+}
+
+// cherryPicked reports whether every commit of ref missing from target has
+// an equivalent change in target
+func (g *Git) cherryPicked(ref, target string) (bool, error) {
+	out, err := g.execGit("cherry", target, ref)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare %s with %s: %w", ref, target, err)
+	}
+	return parseCherry(out), nil
+}
+
+// parseCherry reports whether `git cherry` output lists no commit without
+// an equivalent upstream, i.e. no line starting with "+"
+func parseCherry(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "+") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCherry(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want bool
+	}{
+		{"nothing missing", "", true},
+		{"all picked", "- 1111111\n- 2222222", true},
+		{"one not picked", "- 1111111\n+ 2222222", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseCherry(tt.out))
+		})
+	}
+}
+
+func TestCherryMerged(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(file string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file), 0644))
+		run("add", file)
+		run("commit", "-q", "-m", file)
+		return run("rev-parse", "HEAD")
+	}
+
+	// feature/picked graduated commit by commit; only half of
+	// feature/partial did
+	run("checkout", "-q", "-b", "feature/picked")
+	a, b := commit("a.txt"), commit("b.txt")
+	run("checkout", "-q", "-b", "feature/partial", "main")
+	c := commit("c.txt")
+	commit("d.txt")
+	run("checkout", "-q", "main")
+	commit("main.txt")
+	run("cherry-pick", a, b, c)
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	merged, err := g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.False(t, merged["feature/picked"], "cherry-picks aren't merges by default")
+
+	g.SetCherryMerged(true)
+	merged, err = g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.True(t, merged["feature/picked"])
+	assert.False(t, merged["feature/partial"])
+	assert.True(t, merged["feature/test"], "merged branches stay merged")
+
+	require.NoError(t, g.DeleteBranch("feature/picked", false, false))
+	assert.Error(t, g.DeleteBranch("feature/partial", false, false))
+}
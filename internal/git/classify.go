@@ -0,0 +1,123 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Failure classes of ClassifyError
+const (
+	FailureAuth      = "auth"      // Credentials missing or rejected
+	FailureProtected = "protected" // Protected locally or on the server
+	FailureNotMerged = "not-merged"
+	FailureNetwork   = "network" // Remote unreachable or too slow
+	FailureNotFound  = "not-found"
+	FailureMoved     = "moved" // Changed on the remote since it was listed
+	FailureOther     = "other"
+)
+
+// failurePatterns map git and server messages to failure classes, checked in
+// order against the lowercased error. They match whole phrases in the shape
+// git and the servers print them, so a branch or host name containing e.g.
+// "403" or "not-found" doesn't decide the class. Auth comes first since ssh
+// reports refused keys next to the generic "Could not read from remote
+// repository".
+var failurePatterns = []struct {
+	class    string
+	patterns []*regexp.Regexp
+}{
+	{FailureAuth, []*regexp.Regexp{
+		regexp.MustCompile(`authentication failed for '`),
+		regexp.MustCompile(`could not read (username|password) for '`),
+		regexp.MustCompile(`permission denied \((publickey|password|keyboard-interactive)`),
+		regexp.MustCompile(`permission to \S+ denied to `),
+		regexp.MustCompile(`the requested url returned error: 40[13]\b`),
+	}},
+	{FailureProtected, []*regexp.Regexp{
+		regexp.MustCompile(`gh006: protected branch update failed`),
+		regexp.MustCompile(`\((protected branch hook|pre-receive hook) declined\)`),
+		regexp.MustCompile(`not allowed to (delete|push code to|force push code to) protected branches`),
+	}},
+	{FailureNotMerged, []*regexp.Regexp{
+		regexp.MustCompile(`the branch '[^']+' is not fully merged`),
+	}},
+	{FailureNotFound, []*regexp.Regexp{
+		regexp.MustCompile(`unable to delete '[^']+': remote ref does not exist`),
+		regexp.MustCompile(`branch '[^']+' (not found|does not exist)`),
+	}},
+	{FailureNetwork, []*regexp.Regexp{
+		regexp.MustCompile(`could not resolve host(name)?\b`),
+		regexp.MustCompile(`(connection|operation) timed out`),
+		regexp.MustCompile(`connection refused`),
+		regexp.MustCompile(`unable to access '[^']+': `),
+		regexp.MustCompile(`could not read from remote repository`),
+	}},
+}
+
+// ClassifyError returns the failure class of a branch operation error, one
+// of the Failure* constants, so failures can be reported by cause
+func ClassifyError(err error) string {
+	var (
+		protected *ErrProtectedBranch
+		unmerged  *ErrUnmergedBranch
+		unpushed  *ErrUnpushedCommits
+		timeout   *ErrTimeout
+		notFound  *ErrBranchNotFound
+		moved     *ErrRemoteBranchMoved
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &protected):
+		return FailureProtected
+	case errors.As(err, &unmerged), errors.As(err, &unpushed):
+		return FailureNotMerged
+	case errors.As(err, &timeout):
+		return FailureNetwork
+	case errors.As(err, &notFound):
+		return FailureNotFound
+	case errors.As(err, &moved):
+		return FailureMoved
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fp := range failurePatterns {
+		for _, p := range fp.patterns {
+			if p.MatchString(msg) {
+				return fp.class
+			}
+		}
+	}
+	return FailureOther
+}
+
+// recoveryHints are the likely cause of a failure of each class and what to
+// do next, for RecoveryHint
+var recoveryHints = map[string]string{
+	FailureAuth:      "the remote rejected your credentials; check them with `git-branch-delete auth status` and retry",
+	FailureProtected: "the branch is protected by your configuration or the server; unprotect it there if it should go",
+	FailureNotMerged: "the branch has work no other branch has; merge or push it first, or delete it anyway with --force",
+	FailureNetwork:   "the remote didn't answer; check your connection, then run `git-branch-delete retry`",
+	FailureNotFound:  "the branch is already gone; run `git fetch --prune` to drop a stale tracking ref",
+	FailureMoved:     "someone pushed to the branch since it was listed; fetch, review the new commits and delete it again",
+}
+
+// RecoveryHint returns the likely cause of a failed branch operation and
+// the next step, or "" when there's nothing specific to suggest
+func RecoveryHint(err error) string {
+	var (
+		current *ErrCurrentBranch
+		inUse   *ErrBranchInUse
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &current):
+		return "the branch is checked out; switch to another branch first"
+	case errors.As(err, &inUse):
+		return fmt.Sprintf("the branch is in use (%s); finish or clean that up first", inUse.Reason)
+	}
+	return recoveryHints[ClassifyError(err)]
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "no error", err: nil, want: ""},
+		{name: "protected locally", err: newProtectedBranchError("main", ""), want: FailureProtected},
+		{name: "unpushed", err: newUnpushedCommitsError(TrackingStatus{Branch: "x", Upstream: "origin/x", Ahead: 2}), want: FailureNotMerged},
+		{name: "wrapped unmerged", err: fmt.Errorf("delete: %w", newUnmergedBranchError("x")), want: FailureNotMerged},
+		{name: "timeout", err: newTimeoutError("push", "30s"), want: FailureNetwork},
+		{name: "missing remote branch", err: newBranchNotFoundError("x", true), want: FailureNotFound},
+		{name: "checked out branch", err: newCurrentBranchError("main"), want: FailureOther},
+		{
+			name: "git -d refusal",
+			err:  errors.New("failed to delete branch: error: The branch 'x' is not fully merged."),
+			want: FailureNotMerged,
+		},
+		{
+			name: "https credentials",
+			err:  errors.New("fatal: could not read Username for 'https://github.com': terminal prompts disabled"),
+			want: FailureAuth,
+		},
+		{
+			name: "ssh key refused",
+			err:  errors.New("git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository."),
+			want: FailureAuth,
+		},
+		{
+			name: "github protected branch",
+			err:  errors.New("remote: error: GH006: Protected branch update failed for refs/heads/release."),
+			want: FailureProtected,
+		},
+		{
+			name: "server hook",
+			err:  errors.New("! [remote rejected] release (pre-receive hook declined)"),
+			want: FailureProtected,
+		},
+		{
+			name: "remote ref gone",
+			err:  errors.New("error: unable to delete 'x': remote ref does not exist"),
+			want: FailureNotFound,
+		},
+		{
+			name: "local branch gone",
+			err:  errors.New("branch 'x' does not exist"),
+			want: FailureNotFound,
+		},
+		{
+			name: "dns",
+			err:  errors.New("ssh: Could not resolve hostname github.com: Name or service not known"),
+			want: FailureNetwork,
+		},
+		{
+			name: "https unreachable",
+			err:  errors.New("fatal: unable to access 'https://github.com/a/b.git/': Failed to connect"),
+			want: FailureNetwork,
+		},
+		{
+			name: "gitlab protected branch",
+			err:  errors.New("remote: GitLab: You are not allowed to delete protected branches from this project."),
+			want: FailureProtected,
+		},
+		{
+			name: "https forbidden",
+			err:  errors.New("fatal: unable to access 'https://github.com/a/b.git/': The requested URL returned error: 403"),
+			want: FailureAuth,
+		},
+		{name: "unknown", err: errors.New("cannot lock ref"), want: FailureOther},
+
+		// Branch and host names aren't messages
+		{name: "403 in a name", err: errors.New("cannot lock ref 'refs/heads/fix/403-page'"), want: FailureOther},
+		{name: "not-found in a name", err: errors.New("cannot lock ref 'refs/heads/feature/not-found-page'"), want: FailureOther},
+		{name: "timed out in a name", err: errors.New("cannot lock ref 'refs/heads/fix/request-timed-out'"), want: FailureOther},
+		{name: "protected branch in a name", err: errors.New("cannot lock ref 'refs/heads/docs/protected-branch-rules'"), want: FailureOther},
+		{name: "permission denied in a name", err: errors.New("cannot lock ref 'refs/heads/fix/permission-denied'"), want: FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestRecoveryHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "no error", err: nil, want: ""},
+		{name: "checked out", err: newCurrentBranchError("main"), want: "the branch is checked out; switch to another branch first"},
+		{name: "in use", err: newBranchInUseError("x", "rebase in progress"), want: "the branch is in use (rebase in progress); finish or clean that up first"},
+		{name: "moved", err: fmt.Errorf("delete: %w", newRemoteBranchMovedError("x", "abc1234", "")), want: recoveryHints[FailureMoved]},
+		{name: "auth", err: errors.New("fatal: Authentication failed for 'https://example.com/'"), want: recoveryHints[FailureAuth]},
+		{name: "unknown", err: errors.New("something odd"), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RecoveryHint(tt.err))
+		})
+	}
+}
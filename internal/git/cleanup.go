@@ -0,0 +1,37 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// CleanupRefs performs repository cleanup and optimization. In shared
+// clones only loose refs are packed.
+func (g *Git) CleanupRefs(ctx context.Context) error {
+	// Run cleanup operations in sequence
+	ops := []struct {
+		name string
+		args []string
+	}{
+		{"Pruning unreachable objects", []string{"prune"}},
+		{"Cleaning up loose refs", []string{"pack-refs", "--all", "--prune"}},
+		{"Running garbage collection", []string{"gc", "--auto", "--prune=now"}},
+	}
+
+	for _, op := range ops {
+		// Objects unreachable here may be borrowed by other clones
+		if g.IsShared() && op.args[0] != "pack-refs" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			if _, err := g.execGit(op.args...); err != nil {
+				return fmt.Errorf("%s failed: %w", op.name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Comparison is how a branch relates to a base branch
+type Comparison struct {
+	Branch string `json:"branch"`
+	Ahead  int    `json:"ahead"`  // Commits on the branch the base lacks
+	Behind int    `json:"behind"` // Commits on the base the branch lacks
+
+	// The tip commit's message, set by callers showing it
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+
+	// The history unique to the branch, set by callers asking for it
+	Weight *BranchWeight `json:"weight,omitempty"`
+}
+
+// Merged reports whether the base has every commit of the branch
+func (c Comparison) Merged() bool {
+	return c.Ahead == 0
+}
+
+// ResolveBase returns the ref a base branch name refers to, matched like a
+// merge target: "develop" is the local develop or else origin's, while
+// "upstream/main" is that remote's main
+func (g *Git) ResolveBase(name string) (string, error) {
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return "", fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	// for-each-ref sorts by name, so local branches come before remote ones
+	for _, ref := range strings.Split(out, "\n") {
+		if ref != "" && slices.Contains(targetNames(ref), name) {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("branch '%s' does not exist", name)
+}
+
+// CompareBranch counts the commits ref and base don't have in common
+func (g *Git) CompareBranch(ref, base string) (Comparison, error) {
+	c := Comparison{Branch: strings.TrimPrefix(ref, "refs/heads/")}
+
+	out, err := g.execGit("rev-list", "--left-right", "--count", ref+"..."+base)
+	if err != nil {
+		return c, fmt.Errorf("failed to compare %s with %s: %w", ref, base, err)
+	}
+	ahead, behind, ok := strings.Cut(out, "\t")
+	if !ok {
+		return c, fmt.Errorf("invalid commit counts %q", out)
+	}
+	if c.Ahead, err = strconv.Atoi(ahead); err != nil {
+		return c, fmt.Errorf("invalid commit count %q: %w", ahead, err)
+	}
+	if c.Behind, err = strconv.Atoi(behind); err != nil {
+		return c, fmt.Errorf("invalid commit count %q: %w", behind, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBase(t *testing.T) {
+	repo, err := scenario.Build(t.TempDir(), scenario.RemoteOnly)
+	require.NoError(t, err)
+	g, err := New(repo.Dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		base    string
+		want    string
+		wantErr bool
+	}{
+		{"local first", "main", "refs/heads/main", false},
+		{"origin's branch by name", scenario.RemoteOnly, "refs/remotes/origin/" + scenario.RemoteOnly, false},
+		{"remote-qualified", "origin/main", "refs/remotes/origin/main", false},
+		{"missing", "develop", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.ResolveBase(tt.base)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompareBranch(t *testing.T) {
+	repo, err := scenario.Build(t.TempDir(), scenario.Merged, scenario.Unmerged)
+	require.NoError(t, err)
+	g, err := New(repo.Dir)
+	require.NoError(t, err)
+
+	merged, err := g.CompareBranch("refs/heads/"+scenario.Merged, "refs/heads/main")
+	require.NoError(t, err)
+	assert.Equal(t, Comparison{Branch: scenario.Merged, Ahead: 0, Behind: 1}, merged)
+	assert.True(t, merged.Merged())
+
+	unmerged, err := g.CompareBranch("refs/heads/"+scenario.Unmerged, "refs/heads/main")
+	require.NoError(t, err)
+	assert.Equal(t, Comparison{Branch: scenario.Unmerged, Ahead: 1, Behind: 0}, unmerged)
+	assert.False(t, unmerged.Merged())
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBranch returns the repository's default branch. It is resolved on
+// first use from the local origin/HEAD symref and cached afterwards; use
+// ResolveDefaultBranch to pick up a change on the remote.
+func (g *Git) DefaultBranch() (string, error) {
+	g.mu.Lock()
+	cached := g.defaultBranch
+	g.mu.Unlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	name, err := g.LocalDefaultBranch()
+	if err != nil {
+		return "", err
+	}
+	g.cacheDefaultBranch(name)
+	return name, nil
+}
+
+// cacheDefaultBranch replaces the cached default branch
+func (g *Git) cacheDefaultBranch(name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.defaultBranch = name
+}
+
+// LocalDefaultBranch returns the branch the local origin/HEAD symref points at
+func (g *Git) LocalDefaultBranch() (string, error) {
+	out, err := g.execGit("symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("origin/HEAD is not set: %w", err)
+	}
+	return strings.TrimPrefix(out, "origin/"), nil
+}
+
+// RemoteDefaultBranch asks origin which branch its HEAD points at
+func (g *Git) RemoteDefaultBranch() (string, error) {
+	out, err := g.execGit("ls-remote", "--symref", "origin", "HEAD")
+	if err != nil {
+		if isAuthError(err.Error()) {
+			return "", g.handleAuthError(err.Error())
+		}
+		return "", fmt.Errorf("failed to query remote HEAD: %w", err)
+	}
+
+	name, ok := parseSymrefHead(out)
+	if !ok {
+		return "", fmt.Errorf("remote HEAD is not a branch")
+	}
+	return name, nil
+}
+
+// ResolveDefaultBranch re-resolves the default branch from origin and
+// replaces the cached value. previous is the value assumed before, which
+// differs from current when origin's HEAD moved (e.g. master → main).
+func (g *Git) ResolveDefaultBranch() (current, previous string, err error) {
+	previous, _ = g.DefaultBranch()
+
+	current, err = g.RemoteDefaultBranch()
+	if err != nil {
+		return previous, previous, err
+	}
+	g.cacheDefaultBranch(current)
+	return current, previous, nil
+}
+
+// SetDefaultBranch points the local origin/HEAD symref at origin/<name>
+func (g *Git) SetDefaultBranch(name string) error {
+	if _, err := g.execGit("remote", "set-head", "origin", name); err != nil {
+		return fmt.Errorf("failed to update origin/HEAD: %w", err)
+	}
+	g.cacheDefaultBranch(name)
+	return nil
+}
+
+// parseSymrefHead extracts the branch from `git ls-remote --symref <remote> HEAD`
+// output, whose first line reads "ref: refs/heads/<branch>\tHEAD"
+func parseSymrefHead(out string) (string, bool) {
+	for _, line := range strings.Split(out, "\n") {
+		ref, target, ok := strings.Cut(line, "\t")
+		if !ok || target != "HEAD" || !strings.HasPrefix(ref, "ref: refs/heads/") {
+			continue
+		}
+		return strings.TrimPrefix(ref, "ref: refs/heads/"), true
+	}
+	return "", false
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymrefHead(t *testing.T) {
+	tests := []struct {
+		name   string
+		out    string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "symref",
+			out:    "ref: refs/heads/main\tHEAD\n9d118ddc26cf2f0068fb393191f3df337ba75550\tHEAD",
+			want:   "main",
+			wantOK: true,
+		},
+		{
+			name:   "nested branch",
+			out:    "ref: refs/heads/release/2024\tHEAD",
+			want:   "release/2024",
+			wantOK: true,
+		},
+		{
+			name: "detached remote HEAD",
+			out:  "9d118ddc26cf2f0068fb393191f3df337ba75550\tHEAD",
+		},
+		{
+			name: "empty",
+			out:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseSymrefHead(tt.out)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveDefaultBranch(t *testing.T) {
+	originDir, cleanupOrigin := setupTestRepo(t)
+	defer cleanupOrigin()
+
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		require.NoError(t, c.Run(), "git %v", args)
+	}
+	run(dir, "remote", "add", "origin", originDir)
+	run(dir, "fetch", "origin")
+	run(dir, "remote", "set-head", "origin", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	name, err := g.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "main", name)
+
+	// Simulate a default branch migration on the remote
+	run(originDir, "symbolic-ref", "HEAD", "refs/heads/feature/test")
+	run(dir, "fetch", "origin")
+
+	current, previous, err := g.ResolveDefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "feature/test", current)
+	assert.Equal(t, "main", previous)
+
+	// The cached value follows the remote; origin/HEAD is left alone
+	name, err = g.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "feature/test", name)
+
+	local, err := g.LocalDefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "main", local)
+
+	require.NoError(t, g.SetDefaultBranch("feature/test"))
+	local, err = g.LocalDefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "feature/test", local)
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BranchDetail holds commit metadata of a branch tip
+type BranchDetail struct {
+	CommitDate time.Time `json:"commitDate"`
+	Author     string    `json:"author"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body,omitempty"` // The message after the subject
+}
+
+// BranchDetails returns the tip commit metadata of every local and remote
+// branch, keyed by full ref name (e.g. refs/heads/main)
+func (g *Git) BranchDetails() (map[string]BranchDetail, error) {
+	patterns := append(g.refPatterns("refs/heads"), g.refPatterns("refs/remotes")...)
+	details := make(map[string]BranchDetail)
+	if len(patterns) == 0 {
+		return details, nil
+	}
+
+	// Bodies span lines, so records end with a record separator (0x1e) and
+	// the body follows a unit separator (0x1f)
+	args := append([]string{"for-each-ref", "--format", "%(refname)%09%(committerdate:iso-strict)%09%(authorname)%09%(contents:subject)%1f%(contents:body)%1e"}, patterns...)
+	out, err := g.execGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch details: %w", err)
+	}
+
+	for _, record := range strings.Split(out, "\x1e") {
+		line, body, _ := strings.Cut(strings.TrimLeft(record, "\n"), "\x1f")
+		// The subject is last so tabs inside it don't shift the fields
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) != 4 || !g.allowsRef(parts[0]) {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit date %q: %w", parts[1], err)
+		}
+		details[parts[0]] = BranchDetail{CommitDate: date, Author: parts[2], Subject: parts[3], Body: strings.TrimSpace(body)}
+	}
+	return details, nil
+}
+
+// WithSubjects sets Message to the subject of each branch's tip commit, and
+// CommitDate to its committer date
+func (g *Git) WithSubjects(branches []GitBranch) error {
+	details, err := g.BranchDetails()
+	if err != nil {
+		return err
+	}
+	for i := range branches {
+		if d, ok := details[branches[i].Reference]; ok {
+			branches[i].Message = d.Subject
+			branches[i].CommitDate = d.CommitDate
+		}
+	}
+	return nil
+}
+
+// CommitMessage returns the full message, subject and body, of the commit
+// a branch ref (e.g. refs/heads/main) points at
+func (g *Git) CommitMessage(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "refs/heads/") && !strings.HasPrefix(ref, "refs/remotes/") {
+		return "", fmt.Errorf("not a branch ref: %s", ref)
+	}
+	out, err := g.execGit("for-each-ref", "--format", "%(contents)", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message of %s: %w", ref, err)
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// DiffStat is the volume of work on a branch that its base doesn't have
+type DiffStat struct {
+	Commits    int `json:"commits"`    // Commits not reachable from the base
+	Files      int `json:"files"`      // Files changed since the merge base
+	Insertions int `json:"insertions"` // Lines added since the merge base
+	Deletions  int `json:"deletions"`  // Lines removed since the merge base
+}
+
+// String formats the stat as "N commits, +X/-Y lines"
+func (d DiffStat) String() string {
+	commits := "commits"
+	if d.Commits == 1 {
+		commits = "commit"
+	}
+	return fmt.Sprintf("%d %s, +%d/-%d lines", d.Commits, commits, d.Insertions, d.Deletions)
+}
+
+// shortstatPattern matches the counts in git diff --shortstat output, e.g.
+// " 3 files changed, 10 insertions(+), 2 deletions(-)"; either line count
+// is left out when zero
+var shortstatPattern = regexp.MustCompile(`(\d+) (files? changed|insertions?\(\+\)|deletions?\(-\))`)
+
+// BranchDiffStat computes the commits on ref that base doesn't have, and the
+// lines they change compared to where ref forked from base
+func (g *Git) BranchDiffStat(ref, base string) (DiffStat, error) {
+	var d DiffStat
+
+	out, err := g.execGit("rev-list", "--count", ref, "--not", base)
+	if err != nil {
+		return d, fmt.Errorf("failed to count commits: %w", err)
+	}
+	if d.Commits, err = strconv.Atoi(out); err != nil {
+		return d, fmt.Errorf("invalid commit count %q: %w", out, err)
+	}
+	if d.Commits == 0 {
+		return d, nil
+	}
+
+	mergeBase, err := g.execGit("merge-base", base, ref)
+	if err != nil {
+		return d, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	out, err = g.execGit("diff", "--shortstat", mergeBase, ref)
+	if err != nil {
+		return d, fmt.Errorf("failed to compute diff stat: %w", err)
+	}
+	parseShortstat(out, &d)
+	return d, nil
+}
+
+// parseShortstat fills the file and line counts of d from git diff
+// --shortstat output
+func parseShortstat(out string, d *DiffStat) {
+	for _, m := range shortstatPattern.FindAllStringSubmatch(out, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2][0] {
+		case 'f':
+			d.Files = n
+		case 'i':
+			d.Insertions = n
+		case 'd':
+			d.Deletions = n
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShortstat(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want DiffStat
+	}{
+		{"both", " 3 files changed, 10 insertions(+), 2 deletions(-)", DiffStat{Files: 3, Insertions: 10, Deletions: 2}},
+		{"insertions only", " 1 file changed, 1 insertion(+)", DiffStat{Files: 1, Insertions: 1}},
+		{"deletions only", " 2 files changed, 5 deletions(-)", DiffStat{Files: 2, Deletions: 5}},
+		{"empty", "", DiffStat{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DiffStat
+			parseShortstat(tt.out, &got)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBranchDiffStat(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	run("checkout", "-b", "feature/work")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\nthree\n"), 0644))
+	run("add", "a.txt")
+	run("commit", "-m", "add a")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0644))
+	run("commit", "-am", "shrink a")
+	run("checkout", "main")
+
+	// Work on main after the fork doesn't count against the branch
+	run("commit", "--allow-empty", "-m", "main moves on")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	got, err := g.BranchDiffStat("refs/heads/feature/work", "main")
+	require.NoError(t, err)
+	assert.Equal(t, DiffStat{Commits: 2, Files: 1, Insertions: 1}, got)
+	assert.Equal(t, "2 commits, +1/-0 lines", got.String())
+
+	merged, err := g.BranchDiffStat("refs/heads/feature/test", "main")
+	require.NoError(t, err)
+	assert.Equal(t, DiffStat{}, merged)
+}
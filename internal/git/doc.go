@@ -44,6 +44,7 @@ The package implements several safety measures:
   - Protection for default branches
   - Current branch deletion prevention
   - Stale branch detection
+  - Opt-in expiry with GBD-TTL in branch descriptions or commit trailers
   - Merged branch tracking
   - Remote branch handling
 
@@ -54,6 +55,8 @@ Custom error types are provided for common scenarios:
   - ErrProtectedBranch
   - ErrCurrentBranch
   - ErrUnmergedBranch
+  - ErrUnpushedCommits (local commits the upstream doesn't have; use force)
+  - ErrRemoteBranchMoved (pushed to since it was listed; fetch and review)
   - ErrNotGitRepo
 
 These can be used for specific error handling:
@@ -62,9 +65,9 @@ These can be used for specific error handling:
 	if err != nil {
 		switch e := err.(type) {
 		case *git.ErrProtectedBranch:
-			fmt.Printf("Cannot delete protected branch: %s\n", e.Branch)
+			fmt.Printf("Cannot delete protected branch: %s\n", e.Name)
 		case *git.ErrCurrentBranch:
-			fmt.Printf("Cannot delete current branch: %s\n", e.Branch)
+			fmt.Printf("Cannot delete current branch: %s\n", e.Name)
 		default:
 			fmt.Printf("Error: %v\n", err)
 		}
@@ -0,0 +1,88 @@
+package git
+
+import "sort"
+
+// DuplicateGroup is a set of branches on the same side (local or remote)
+// whose tips are the same commit
+type DuplicateGroup struct {
+	Commit string
+	Remote bool
+	// Keep holds the canonical branch first, then any other branch that
+	// must not be deleted (default, current, protected or in use)
+	Keep []GitBranch
+	// Extra holds the branches that can be deleted without losing commits,
+	// since the canonical branch points at the same commit
+	Extra []GitBranch
+}
+
+// DuplicateBranches groups branches whose tips are the same commit. Local
+// and remote branches are grouped separately, since a local branch matching
+// its remote counterpart is expected.
+func (g *Git) DuplicateBranches(branches []GitBranch) []DuplicateGroup {
+	type key struct {
+		commit string
+		remote bool
+	}
+	byTip := make(map[key][]GitBranch)
+	var order []key
+	for _, b := range branches {
+		if b.CommitHash == "" {
+			continue
+		}
+		k := key{b.CommitHash, b.IsRemote}
+		if _, ok := byTip[k]; !ok {
+			order = append(order, k)
+		}
+		byTip[k] = append(byTip[k], b)
+	}
+
+	var groups []DuplicateGroup
+	for _, k := range order {
+		members := byTip[k]
+		if len(members) < 2 {
+			continue
+		}
+
+		sort.SliceStable(members, func(i, j int) bool {
+			ri, rj := g.canonicalRank(members[i]), g.canonicalRank(members[j])
+			if ri != rj {
+				return ri < rj
+			}
+			return members[i].Name < members[j].Name
+		})
+
+		group := DuplicateGroup{Commit: k.commit, Remote: k.remote, Keep: members[:1:1]}
+		for _, b := range members[1:] {
+			if g.mustKeep(b) {
+				group.Keep = append(group.Keep, b)
+			} else {
+				group.Extra = append(group.Extra, b)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// canonicalRank orders the branches of a duplicate group by how likely each
+// is to be the one to keep: the default branch, the current branch,
+// protected branches, branches with an upstream, then the rest
+func (g *Git) canonicalRank(b GitBranch) int {
+	switch {
+	case b.IsDefault:
+		return 0
+	case b.IsCurrent:
+		return 1
+	case g.IsProtected(b.Name, b.IsRemote):
+		return 2
+	case b.TrackingBranch != "":
+		return 3
+	default:
+		return 4
+	}
+}
+
+// mustKeep reports whether a duplicate branch can't be deleted
+func (g *Git) mustKeep(b GitBranch) bool {
+	return b.IsDefault || b.IsCurrent || b.InUse != "" || g.IsProtected(b.Name, b.IsRemote)
+}
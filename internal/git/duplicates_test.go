@@ -0,0 +1,58 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateBranches(t *testing.T) {
+	g := &Git{}
+	g.SetProtection(Protection{Local: []string{"release/*"}})
+
+	branches := []GitBranch{
+		{Name: "main", CommitHash: "aaa", IsDefault: true, IsCurrent: true},
+		{Name: "old-main", CommitHash: "aaa"},
+		{Name: "feature/new", CommitHash: "bbb", TrackingBranch: "origin/feature/new"},
+		{Name: "feature/old", CommitHash: "bbb"},
+		{Name: "feature/older", CommitHash: "bbb", InUse: "rebase in progress"},
+		{Name: "release/v1", CommitHash: "ccc"},
+		{Name: "release/v1-copy", CommitHash: "ccc"},
+		{Name: "hotfix", CommitHash: "ccc"},
+		{Name: "alone", CommitHash: "ddd"},
+		{Name: "feature/new", CommitHash: "bbb", IsRemote: true},
+		{Name: "unresolved"},
+		{Name: "also-unresolved"},
+	}
+
+	names := func(bs []GitBranch) []string {
+		var out []string
+		for _, b := range bs {
+			out = append(out, b.Name)
+		}
+		return out
+	}
+
+	groups := g.DuplicateBranches(branches)
+	if !assert.Len(t, groups, 3) {
+		return
+	}
+
+	tests := []struct {
+		commit string
+		keep   []string
+		extra  []string
+	}{
+		{"aaa", []string{"main"}, []string{"old-main"}},
+		{"bbb", []string{"feature/new", "feature/older"}, []string{"feature/old"}},
+		{"ccc", []string{"release/v1", "release/v1-copy"}, []string{"hotfix"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.commit, func(t *testing.T) {
+			assert.Equal(t, tt.commit, groups[i].Commit)
+			assert.False(t, groups[i].Remote)
+			assert.Equal(t, tt.keep, names(groups[i].Keep))
+			assert.Equal(t, tt.extra, names(groups[i].Extra))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults for EnrichOptions
+const (
+	DefaultEnrichWorkers = 4
+	DefaultEnrichTimeout = 10 * time.Second
+)
+
+// Enricher names of the built-in enrichers
+const (
+	EnrichAheadBehind = "ahead-behind"
+	EnrichWeight      = "weight"
+)
+
+// Enricher computes one piece of expensive metadata for a branch, such as
+// its distance from upstream or the history unique to it. Fn must give up
+// and return once ctx is done.
+type Enricher struct {
+	Name string
+	Fn   func(ctx context.Context, b GitBranch) (interface{}, error)
+}
+
+// Enrichment is the metadata one enricher computed for one branch. Value is
+// nil when the metadata doesn't apply, e.g. ahead/behind of a branch without
+// an upstream; otherwise its type depends on the enricher (TrackingStatus
+// for EnrichAheadBehind, BranchWeight for EnrichWeight).
+type Enrichment struct {
+	Ref      string // Full ref of the branch, e.g. refs/heads/main
+	Enricher string
+	Value    interface{}
+	Err      error
+}
+
+// EnrichOptions bounds an enrichment run
+type EnrichOptions struct {
+	Workers int           // Enrichers running at once, DefaultEnrichWorkers when 0
+	Timeout time.Duration // Per branch and enricher, DefaultEnrichTimeout when 0
+}
+
+// Enrich runs every enricher on every branch with a bounded number of
+// workers and sends each result as soon as it is ready, so callers can show
+// the branches right away and fill in details as they arrive. Branches are
+// processed in order, so the first ones (usually the visible ones) come
+// first. The channel is closed once every result is sent or ctx is done.
+//
+// An enricher that outlives its timeout is cancelled and reported with an
+// ErrTimeout, and its result is dropped.
+func Enrich(ctx context.Context, branches []GitBranch, enrichers []Enricher, opts EnrichOptions) <-chan Enrichment {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultEnrichWorkers
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultEnrichTimeout
+	}
+
+	type job struct {
+		branch   GitBranch
+		enricher Enricher
+	}
+	jobs := make(chan job)
+	results := make(chan Enrichment)
+
+	go func() {
+		defer close(jobs)
+		for _, b := range branches {
+			for _, e := range enrichers {
+				select {
+				case jobs <- job{b, e}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := runEnricher(ctx, j.branch, j.enricher, opts.Timeout)
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// runEnricher runs e on b, cancelling it after timeout
+func runEnricher(ctx context.Context, b GitBranch, e Enricher, timeout time.Duration) Enrichment {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	value, err := e.Fn(ctx, b)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Enrichment{
+			Ref:      b.Reference,
+			Enricher: e.Name,
+			Err:      newTimeoutError(e.Name+" "+b.Name, timeout.String()),
+		}
+	}
+	return Enrichment{Ref: b.Reference, Enricher: e.Name, Value: value, Err: err}
+}
+
+// AheadBehindEnricher reports the TrackingStatus of local branches with
+// an upstream
+func (g *Git) AheadBehindEnricher() Enricher {
+	return Enricher{Name: EnrichAheadBehind, Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		if b.IsRemote {
+			return nil, nil
+		}
+		status, err := g.trackingStatusContext(ctx, b.Name)
+		if err != nil || status.Upstream == "" {
+			return nil, err
+		}
+		return status, nil
+	}}
+}
+
+// WeightEnricher computes the history unique to each branch compared to base
+func (g *Git) WeightEnricher(base string) Enricher {
+	return Enricher{Name: EnrichWeight, Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		return g.branchWeight(ctx, b.Reference, base)
+	}}
+}
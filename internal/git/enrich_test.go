@@ -0,0 +1,147 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(ch <-chan Enrichment) map[string]Enrichment {
+	got := make(map[string]Enrichment)
+	for e := range ch {
+		got[e.Ref+" "+e.Enricher] = e
+	}
+	return got
+}
+
+func TestEnrich(t *testing.T) {
+	branches := []GitBranch{
+		{Name: "a", Reference: "refs/heads/a"},
+		{Name: "b", Reference: "refs/heads/b"},
+		{Name: "c", Reference: "refs/heads/c"},
+	}
+
+	var running, peak int32
+	length := Enricher{Name: "length", Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return len(b.Reference), nil
+	}}
+	var slowRunning int32
+	slow := Enricher{Name: "slow", Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		atomic.AddInt32(&slowRunning, 1)
+		defer atomic.AddInt32(&slowRunning, -1)
+		if b.Name == "b" {
+			select {
+			case <-time.After(time.Minute):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return "done", nil
+	}}
+	failing := Enricher{Name: "failing", Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		return nil, errors.New("boom")
+	}}
+
+	got := collect(Enrich(context.Background(), branches, []Enricher{length, slow, failing}, EnrichOptions{Workers: 2, Timeout: 100 * time.Millisecond}))
+	require.Len(t, got, 9)
+	assert.LessOrEqual(t, peak, int32(2))
+
+	assert.Equal(t, 12, got["refs/heads/a length"].Value)
+	assert.Equal(t, "done", got["refs/heads/c slow"].Value)
+
+	var timeout *ErrTimeout
+	assert.ErrorAs(t, got["refs/heads/b slow"].Err, &timeout)
+	assert.Nil(t, got["refs/heads/b slow"].Value)
+	assert.EqualError(t, got["refs/heads/a failing"].Err, "boom")
+
+	// The timed out enricher was cancelled rather than left running
+	assert.Zero(t, atomic.LoadInt32(&slowRunning))
+}
+
+func TestEnrichCancel(t *testing.T) {
+	branches := make([]GitBranch, 100)
+	for i := range branches {
+		branches[i] = GitBranch{Reference: "refs/heads/x"}
+	}
+	block := Enricher{Name: "block", Fn: func(ctx context.Context, b GitBranch) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Enrich(ctx, branches, []Enricher{block}, EnrichOptions{Workers: 2})
+	cancel()
+
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Less(t, n, len(branches))
+}
+
+func TestBuiltinEnrichers(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"checkout", "-q", "feature/test"},
+		{"commit", "-q", "--allow-empty", "-m", "Feature work"},
+		{"branch", "--set-upstream-to", "main"},
+		{"checkout", "-q", "main"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		require.NoError(t, c.Run())
+	}
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	branches := []GitBranch{
+		{Name: "feature/test", Reference: "refs/heads/feature/test"},
+		{Name: "feature/test2", Reference: "refs/heads/feature/test2"},
+	}
+	got := collect(Enrich(context.Background(), branches, []Enricher{g.AheadBehindEnricher(), g.WeightEnricher("main")}, EnrichOptions{}))
+
+	ab := got["refs/heads/feature/test "+EnrichAheadBehind]
+	require.NoError(t, ab.Err)
+	assert.Equal(t, TrackingStatus{Branch: "feature/test", Upstream: "main", Ahead: 1}, ab.Value)
+
+	untracked := got["refs/heads/feature/test2 "+EnrichAheadBehind]
+	assert.NoError(t, untracked.Err)
+	assert.Nil(t, untracked.Value, "branch has no upstream")
+
+	w := got["refs/heads/feature/test "+EnrichWeight]
+	require.NoError(t, w.Err)
+	assert.Equal(t, 1, w.Value.(BranchWeight).Commits)
+}
+
+func TestBuiltinEnrichersCancel(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := GitBranch{Name: "feature/test", Reference: "refs/heads/feature/test"}
+	_, err = g.WeightEnricher("main").Fn(ctx, b)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = g.AheadBehindEnricher().Fn(ctx, b)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,31 @@
+package git
+
+import "strings"
+
+// SetExtraEnv sets the environment variables passed to git on top of the
+// built-in allowlist, e.g. HTTPS_PROXY or SSH_ASKPASS. A name ending in "*"
+// matches every variable starting with the rest of it.
+func (g *Git) SetExtraEnv(names []string) {
+	g.extraEnv = names
+}
+
+// extraEnvAllowed reports whether the "NAME=value" environment entry is
+// allowed by the extra environment names
+func (g *Git) extraEnvAllowed(entry string) bool {
+	name, _, _ := strings.Cut(entry, "=")
+	for _, pattern := range g.extraEnv {
+		if matchEnvName(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchEnvName matches an environment variable name against a name or a
+// prefix ending in "*". Names are case-sensitive, as they are on Unix.
+func matchEnvName(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return name == pattern
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtraEnvAllowed(t *testing.T) {
+	g := &Git{}
+	g.SetExtraEnv([]string{"HTTPS_PROXY", "no_proxy", "CORP_ASKPASS_*"})
+
+	tests := []struct {
+		entry string
+		want  bool
+	}{
+		{"HTTPS_PROXY=http://proxy:3128", true},
+		{"HTTPS_PROXY=", true},
+		{"HTTP_PROXY=http://proxy:3128", false},
+		{"no_proxy=localhost", true},
+		{"NO_PROXY=localhost", false},
+		{"CORP_ASKPASS_HELPER=/usr/bin/helper", true},
+		{"CORP_ASKPASS=/usr/bin/helper", false},
+		{"HTTPS_PROXY_EXTRA=x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.extraEnvAllowed(tt.entry))
+		})
+	}
+}
@@ -14,14 +14,56 @@ type (
 
 	// ErrProtectedBranch indicates an operation on a protected branch
 	ErrProtectedBranch struct {
+		Name   string
+		Reason string // Why it's pinned, e.g. "deployed to production"; empty for patterns
+	}
+
+	// ErrCurrentBranch indicates an operation on the checked out branch
+	ErrCurrentBranch struct {
 		Name string
 	}
 
+	// ErrBranchNotFound indicates a branch that doesn't exist locally or,
+	// with Remote, on origin
+	ErrBranchNotFound struct {
+		Name   string
+		Remote bool
+	}
+
+	// ErrRemoteBranchMoved indicates a remote branch points at another
+	// commit than when it was listed, so deleting it could lose new work
+	ErrRemoteBranchMoved struct {
+		Name     string
+		Expected string
+		Actual   string // Empty when the server rejected the deletion
+	}
+
 	// ErrUnmergedBranch indicates an operation on an unmerged branch
 	ErrUnmergedBranch struct {
 		Name string
 	}
 
+	// ErrUnpushedCommits indicates a local branch has commits its upstream
+	// doesn't have
+	ErrUnpushedCommits struct {
+		Name     string
+		Upstream string
+		Ahead    int
+	}
+
+	// ErrBranchInUse indicates a branch is involved in an ongoing operation
+	ErrBranchInUse struct {
+		Name   string
+		Reason string
+	}
+
+	// ErrBranchConfig indicates a deleted branch whose configuration
+	// (branch.<name>.*) couldn't be removed; the branch itself is gone
+	ErrBranchConfig struct {
+		Name string
+		Err  error
+	}
+
 	// ErrGitCommand indicates a git command failure
 	ErrGitCommand struct {
 		Command string
@@ -29,6 +71,13 @@ type (
 		Err     error
 	}
 
+	// ErrReadOnly indicates a command that would change a repository set
+	// read-only
+	ErrReadOnly struct {
+		Command string
+		Reason  string
+	}
+
 	// ErrTimeout indicates a git command timeout
 	ErrTimeout struct {
 		Command string
@@ -42,13 +91,50 @@ func (e *ErrInvalidBranch) Error() string {
 }
 
 func (e *ErrProtectedBranch) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("cannot modify protected branch '%s': %s", e.Name, e.Reason)
+	}
 	return fmt.Sprintf("cannot modify protected branch '%s'", e.Name)
 }
 
+func (e *ErrCurrentBranch) Error() string {
+	return fmt.Sprintf("cannot delete the checked out branch '%s'", e.Name)
+}
+
+func (e *ErrBranchNotFound) Error() string {
+	if e.Remote {
+		return fmt.Sprintf("remote branch '%s' does not exist", e.Name)
+	}
+	return fmt.Sprintf("branch '%s' does not exist", e.Name)
+}
+
+func (e *ErrRemoteBranchMoved) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("remote branch '%s' moved since it was listed at %s", e.Name, shortHash(e.Expected))
+	}
+	return fmt.Sprintf("remote branch '%s' moved since it was listed: expected %s, found %s", e.Name, shortHash(e.Expected), shortHash(e.Actual))
+}
+
 func (e *ErrUnmergedBranch) Error() string {
 	return fmt.Sprintf("branch '%s' is not fully merged", e.Name)
 }
 
+func (e *ErrUnpushedCommits) Error() string {
+	return fmt.Sprintf("branch '%s' has %d commit(s) not pushed to '%s'", e.Name, e.Ahead, e.Upstream)
+}
+
+func (e *ErrBranchInUse) Error() string {
+	return fmt.Sprintf("branch '%s' is in use: %s", e.Name, e.Reason)
+}
+
+func (e *ErrBranchConfig) Error() string {
+	return fmt.Sprintf("branch '%s' deleted, but failed to remove its configuration: %v", e.Name, e.Err)
+}
+
+func (e *ErrBranchConfig) Unwrap() error {
+	return e.Err
+}
+
 func (e *ErrGitCommand) Error() string {
 	if e.Output != "" {
 		return fmt.Sprintf("git command '%s' failed: %s\nOutput: %s", e.Command, e.Err, e.Output)
@@ -56,6 +142,10 @@ func (e *ErrGitCommand) Error() string {
 	return fmt.Sprintf("git command '%s' failed: %s", e.Command, e.Err)
 }
 
+func (e *ErrReadOnly) Error() string {
+	return fmt.Sprintf("refusing to run 'git %s': %s", e.Command, e.Reason)
+}
+
 func (e *ErrTimeout) Error() string {
 	return fmt.Sprintf("git command '%s' timed out after %s", e.Command, e.Timeout)
 }
@@ -65,14 +155,34 @@ func newInvalidBranchError(name, reason string) error {
 	return &ErrInvalidBranch{Name: name, Reason: reason}
 }
 
-func newProtectedBranchError(name string) error {
-	return &ErrProtectedBranch{Name: name}
+func newProtectedBranchError(name, reason string) error {
+	return &ErrProtectedBranch{Name: name, Reason: reason}
+}
+
+func newCurrentBranchError(name string) error {
+	return &ErrCurrentBranch{Name: name}
+}
+
+func newBranchNotFoundError(name string, remote bool) error {
+	return &ErrBranchNotFound{Name: name, Remote: remote}
+}
+
+func newRemoteBranchMovedError(name, expected, actual string) error {
+	return &ErrRemoteBranchMoved{Name: name, Expected: expected, Actual: actual}
 }
 
 func newUnmergedBranchError(name string) error {
 	return &ErrUnmergedBranch{Name: name}
 }
 
+func newUnpushedCommitsError(status TrackingStatus) error {
+	return &ErrUnpushedCommits{Name: status.Branch, Upstream: status.Upstream, Ahead: status.Ahead}
+}
+
+func newBranchInUseError(name, reason string) error {
+	return &ErrBranchInUse{Name: name, Reason: reason}
+}
+
 func newGitCommandError(cmd string, output string, err error) error {
 	return &ErrGitCommand{Command: cmd, Output: output, Err: err}
 }
@@ -0,0 +1,14 @@
+package git
+
+import "fmt"
+
+// FetchPrune fetches origin, removing the remote-tracking branches of
+// branches deleted there, so upstreams that are gone show as such. It's
+// watch's refresh; fetch isn't among the commands callers may run, so the
+// fixed arguments go to git as they are.
+func (g *Git) FetchPrune() error {
+	if _, err := g.runGit(nil, "fetch", "--quiet", "--prune", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch origin: %w", err)
+	}
+	return nil
+}
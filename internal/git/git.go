@@ -3,13 +3,17 @@ package git
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
 )
 
 const (
@@ -17,11 +21,68 @@ const (
 	DefaultTimeout = 30 * time.Second
 )
 
-// Git represents a git repository
+// Git represents a git repository. It is safe for concurrent use once
+// configured: the Set methods must be called before g is shared between
+// goroutines, while the caches filled as commands run are guarded by mu.
 type Git struct {
 	workDir   string
+	gitDir    string
 	gitPath   string
 	timeout   time.Duration
+
+	// mu guards the caches: defaultBranch, merged and version, and lockHeld
+	mu sync.Mutex
+
+	// defaultBranch caches the resolved default branch
+	defaultBranch string
+
+	// protection holds the branch patterns DeleteBranch refuses to delete
+	protection Protection
+
+	// merged caches MergedBranches results per target
+	merged map[mergedKey]map[string]bool
+
+	// mergedTargets are the branch patterns branches count as merged into
+	mergedTargets []string
+
+	// refFilter limits which refs are listed as branches
+	refFilter RefFilter
+
+	// extraEnv are environment variable names passed to git on top of the
+	// built-in allowlist
+	extraEnv []string
+
+	// sshCommands are the SSH commands of remotes, by remote name
+	sshCommands map[string]string
+
+	// remoteAuth is how each remote is authenticated, by remote name
+	remoteAuth map[string]RemoteAuth
+
+	// cherryMerged counts branches cherry-picked into a merge target as
+	// merged
+	cherryMerged bool
+
+	// webURLTemplate builds branch web pages instead of guessing them from
+	// origin's URL, when set
+	webURLTemplate string
+
+	// version caches the git version as [major, minor]
+	version []int
+
+	// transcript records the commands run, when set
+	transcript *Transcript
+
+	// sharedLock is the lock file of a shared clone, set when its
+	// safeguards are on
+	sharedLock string
+
+	// lockHeld is set while HoldSharedLock holds sharedLock for a whole
+	// run; guarded by mu
+	lockHeld bool
+
+	// readOnly is why commands changing the repository are refused, when
+	// set
+	readOnly string
 }
 
 // New creates a new Git instance
@@ -32,25 +93,26 @@ func New(workDir string) (*Git, error) {
 		return nil, fmt.Errorf("git executable not found: %w", err)
 	}
 
-	// Verify workDir exists and is absolute
-	workDir, err = filepath.Abs(workDir)
+	// Find the repository workDir is in; it may be a subdirectory, a
+	// linked worktree or a submodule
+	root, gitDir, err := pkggit.DiscoverRepo(workDir)
 	if err != nil {
-		return nil, fmt.Errorf("invalid working directory: %w", err)
-	}
-
-	// Verify workDir is a git repository
-	gitDir := filepath.Join(workDir, ".git")
-	if fi, err := os.Stat(gitDir); err != nil || !fi.IsDir() {
-		return nil, fmt.Errorf("not a git repository: %s", workDir)
+		return nil, err
 	}
 
 	return &Git{
-		workDir: workDir,
+		workDir: root,
+		gitDir:  gitDir,
 		gitPath: gitPath,
 		timeout: DefaultTimeout,
 	}, nil
 }
 
+// WorkDir returns the root of the repository's working tree
+func (g *Git) WorkDir() string {
+	return g.workDir
+}
+
 // SetTimeout sets the timeout for git commands
 func (g *Git) SetTimeout(timeout time.Duration) {
 	if timeout > 0 {
@@ -60,21 +122,79 @@ func (g *Git) SetTimeout(timeout time.Duration) {
 
 // execGit executes a git command securely with timeout
 func (g *Git) execGit(args ...string) (string, error) {
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
-	defer cancel()
+	return g.execGitInput(os.Stdin, args...)
+}
+
+// execGitInput is execGit with the command reading stdin from input
+func (g *Git) execGitInput(input io.Reader, args ...string) (string, error) {
+	if err := validateGitArgs(args); err != nil {
+		return "", err
+	}
+	return g.runGit(input, args...)
+}
 
-	// Validate all arguments
+// execGitContext is execGit with the command killed once ctx is done
+func (g *Git) execGitContext(ctx context.Context, args ...string) (string, error) {
+	if err := validateGitArgs(args); err != nil {
+		return "", err
+	}
+	return g.runGitContext(ctx, os.Stdin, args...)
+}
+
+// validateGitArgs validates the arguments of a git command
+func validateGitArgs(args []string) error {
+	// Validate all arguments; everything after "--" is a pathspec
+	pathspecs := false
 	for _, arg := range args {
+		if pathspecs {
+			if err := ValidatePathspec(arg); err != nil {
+				return err
+			}
+			continue
+		}
+		if arg == "--" {
+			pathspecs = true
+			continue
+		}
+
 		// Skip format strings and ref paths
 		if strings.HasPrefix(arg, "%(") || strings.HasPrefix(arg, "refs/") {
 			continue
 		}
+		if commandFlags[args[0]][arg] {
+			continue
+		}
 		if err := ValidateGitArg(arg); err != nil {
-			return "", newInvalidBranchError(arg, err.Error())
+			return newInvalidBranchError(arg, err.Error())
+		}
+	}
+	return nil
+}
+
+// runGit runs git with already validated arguments
+func (g *Git) runGit(input io.Reader, args ...string) (string, error) {
+	return g.runGitContext(context.Background(), input, args...)
+}
+
+// runGitContext is runGit with the command killed once parent is done
+func (g *Git) runGitContext(parent context.Context, input io.Reader, args ...string) (string, error) {
+	if g.readOnly != "" && ChangesRepository(args) {
+		return "", &ErrReadOnly{Command: strings.Join(args, " "), Reason: g.readOnly}
+	}
+
+	// Concurrent runs in a shared clone take turns changing it
+	if ChangesRepository(args) {
+		release, err := g.lockShared()
+		if err != nil {
+			return "", err
 		}
+		defer release()
 	}
 
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(parent, g.timeout)
+	defer cancel()
+
 	// Use absolute path to git executable
 	cmd := exec.CommandContext(ctx, g.gitPath, args...)
 	cmd.Dir = g.workDir
@@ -85,8 +205,36 @@ func (g *Git) execGit(args ...string) (string, error) {
 	cmd.Stderr = &stderr
 
 	// Always set stdin to prevent hanging
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = input
+
+	cmd.Env = g.commandEnv(args)
 
+	// Execute command with timeout
+	started := time.Now()
+	err := cmd.Run()
+	g.record(args, started, stdout.String(), stderr.String(), err)
+	if err != nil {
+		if parent.Err() != nil {
+			return "", parent.Err()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", newTimeoutError(strings.Join(args, " "), g.timeout.String())
+		}
+		return "", newGitCommandError(strings.Join(args, " "), stderr.String(), err)
+	}
+
+	// Validate output for potential command injection
+	output := stdout.String()
+	if strings.ContainsAny(output, "\x00\x07\x1B\x9B") {
+		return "", newGitCommandError(strings.Join(args, " "), output, fmt.Errorf("output contains invalid characters"))
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// commandEnv returns the environment git runs with for args: the allowed
+// variables of ours, and the settings that keep git's behavior predictable
+func (g *Git) commandEnv(args []string) []string {
 	// Get existing environment
 	env := os.Environ()
 
@@ -111,6 +259,7 @@ func (g *Git) execGit(args ...string) (string, error) {
 		"GIT_ASKPASS":        true,
 		"GIT_SSH":            true,
 		"GIT_SSH_COMMAND":    true,
+		"GIT_SSH_VARIANT":    true,
 		"GIT_CONFIG_NOSYSTEM": true,
 		"GIT_AUTHOR_NAME":    true,
 		"GIT_AUTHOR_EMAIL":   true,
@@ -139,6 +288,11 @@ func (g *Git) execGit(args ...string) (string, error) {
 			}
 		}
 
+		// Check if the configuration allows it
+		if !allowed && g.extraEnvAllowed(e) {
+			allowed = true
+		}
+
 		if allowed {
 			filteredEnv = append(filteredEnv, e)
 		}
@@ -151,24 +305,23 @@ func (g *Git) execGit(args ...string) (string, error) {
 		"LC_ALL=C",                  // Use consistent locale
 	}
 
-	cmd.Env = append(filteredEnv, gitEnv...)
-
-	// Execute command with timeout
-	err := cmd.Run()
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return "", newTimeoutError(strings.Join(args, " "), g.timeout.String())
-		}
-		return "", newGitCommandError(strings.Join(args, " "), stderr.String(), err)
+	// Point git at the repository New discovered, whatever the environment
+	// or working directory say
+	if g.gitDir != "" {
+		gitEnv = append(gitEnv, "GIT_DIR="+g.gitDir, "GIT_WORK_TREE="+g.workDir)
 	}
 
-	// Validate output for potential command injection
-	output := stdout.String()
-	if strings.ContainsAny(output, "\x00\x07\x1B\x9B") {
-		return "", newGitCommandError(strings.Join(args, " "), output, fmt.Errorf("output contains invalid characters"))
-	}
+	// Use the SSH command configured for the remote, if any
+	gitEnv = append(gitEnv, g.sshEnv(args)...)
+	gitEnv = append(gitEnv, g.remoteAuthEnv(args)...)
 
-	return strings.TrimSpace(output), nil
+	// Authenticate to the remote, and keep git from pruning objects other
+	// clones borrow. Git reads a single GIT_CONFIG_COUNT, so the entries
+	// go in one list.
+	config := append(g.remoteAuthConfig(args), g.sharedConfig()...)
+	gitEnv = append(gitEnv, configEnv(config)...)
+
+	return append(filteredEnv, gitEnv...)
 }
 
 // execGitQuiet executes a git command without validation for internal use
@@ -176,7 +329,14 @@ func (g *Git) execGitQuiet(args ...string) (string, error) {
 	cmd := exec.Command(g.gitPath, args...)
 	cmd.Dir = g.workDir
 	cmd.Stdin = os.Stdin  // Prevent hanging
+	started := time.Now()
 	out, err := cmd.Output()
+	var stderr string
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		stderr = string(exitErr.Stderr)
+	}
+	g.record(args, started, string(out), stderr, err)
 	if err != nil {
 		return "", err
 	}
@@ -195,29 +355,48 @@ type GitBranch struct {
 	IsStale        bool
 	IsBehind       bool
 	Message        string
-	TrackingBranch string // Add tracking branch info
+	TrackingBranch string        // Add tracking branch info
+	Weight         *BranchWeight // Unique history, only set when requested
+	InUse          string        // Why the branch can't be deleted right now, if anything
+	LastUsed       time.Time     // Last checkout or merge recorded by the usage hooks, if any
+	CommitDate     time.Time     // Committer date of the tip commit, set by WithSubjects
+	TTL            *BranchTTL    // Declared time to live, set by WithTTLs
+	Tickets        []Ticket      // Tickets named by trailers of the tip commit, set by WithTickets
 }
 
-// execGitWithStdout executes a git command and returns its stdout pipe
-func (g *Git) execGitWithStdout(args ...string) (*exec.Cmd, io.ReadCloser, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, g.gitPath, args...)
-	cmd.Dir = g.workDir
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin  // Prevent hanging
-
-	stdout, err := cmd.StdoutPipe()
+// GitPath returns the absolute path of name inside the repository's git
+// directory, resolving worktree and GIT_DIR layouts the way git does
+func (g *Git) GitPath(name string) (string, error) {
+	path, err := g.execGit("rev-parse", "--git-path", name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+		return "", fmt.Errorf("failed to resolve git path: %w", err)
 	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.workDir, path)
+	}
+	return path, nil
+}
 
-	if err := cmd.Start(); err != nil {
-		return nil, nil, fmt.Errorf("failed to start command: %w", err)
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees, where tools keep repository-wide state
+func (g *Git) CommonDir() (string, error) {
+	path, err := g.execGit("rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.workDir, path)
 	}
+	return path, nil
+}
 
-	return cmd, stdout, nil
+// ResolveRef returns the full object name ref points at
+func (g *Git) ResolveRef(ref string) (string, error) {
+	out, err := g.execGit("rev-parse", "--verify", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return out, nil
 }
 
 // ParseBranchLine parses a line of branch information from git for-each-ref
@@ -271,29 +450,37 @@ func (g *Git) branchExists(name string, remote bool) (bool, error) {
 	if remote {
 		args = []string{"ls-remote", "origin", "refs/heads/" + name}
 	} else {
-		args = []string{"show-ref", "--verify", "--quiet", "refs/heads/" + name}
+		args = []string{"show-ref", "--verify", "refs/heads/" + name}
 	}
 
-	_, err := g.execGit(args...)
+	out, err := g.execGit(args...)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "unknown revision") {
+		if strings.Contains(err.Error(), "not a valid ref") || strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "unknown revision") {
 			return false, nil
 		}
 		return false, err
 	}
-	return true, nil
+	// ls-remote succeeds without output when the ref isn't there
+	return out != "", nil
 }
 
 // handleAuthError provides interactive help for authentication errors
 func (g *Git) handleAuthError(errStr string) error {
 	// Check if this is an HTTPS URL
-	remoteURL, err := g.execGitQuiet("config", "--get", "remote.origin.url")
+	// get-url applies url.insteadOf rewrites, so aliases are resolved
+	remoteURL, err := g.RemoteURL()
 	if err != nil {
 		return fmt.Errorf("failed to get remote URL: %w", err)
 	}
 
 	isHTTPS := strings.HasPrefix(remoteURL, "https://")
-	isSSH := strings.HasPrefix(remoteURL, "git@")
+	isSSH := isSSHURL(remoteURL)
+
+	if summary := g.authSummary("origin"); summary != "" {
+		return fmt.Errorf("authentication failed with the credentials configured for origin (%s). Please check them:\n"+
+			"1. Show them: remoteAuth.origin in the configuration\n"+
+			"2. Check the token's environment variable is set and the token is still valid", summary)
+	}
 
 	if isHTTPS {
 		return fmt.Errorf("authentication failed. Please ensure your git credentials are configured:\n" +
@@ -302,6 +489,12 @@ func (g *Git) handleAuthError(errStr string) error {
 			"3. For other systems, see: https://git-scm.com/docs/gitcredentials")
 	}
 
+	if isSSH && g.customSSHCommand() {
+		return fmt.Errorf("SSH authentication failed with the configured SSH command. Please check it selects the right identity:\n" +
+			"1. Show the command: git config --get core.sshCommand, or sshCommands in the configuration\n" +
+			"2. Test it: ssh -T with the same identity and host")
+	}
+
 	if isSSH {
 		// For SSH, check if SSH agent is running and has keys
 		sshAdd := exec.Command("ssh-add", "-l")
@@ -322,23 +515,67 @@ func (g *Git) handleAuthError(errStr string) error {
 		"For SSH: ensure your SSH key is added to GitHub")
 }
 
-// DeleteBranch deletes a branch locally and/or remotely
+// DeleteBranch deletes a branch locally and/or remotely. Protected branches
+// fail with ErrProtectedBranch, the checked out branch with ErrCurrentBranch,
+// one checked out in another worktree with ErrBranchInUse and missing ones
+// with ErrBranchNotFound. Without force, a local branch
+// ahead of its upstream fails with ErrUnpushedCommits. A remote branch is
+// only deleted while it points at its remote-tracking branch, what listings
+// showed, and fails with ErrRemoteBranchMoved after new pushes.
 func (g *Git) DeleteBranch(name string, force bool, remote bool) error {
-	// Check if branch exists
-	exists, err := g.branchExists(name, remote)
+	return g.deleteBranch(name, force, remote, "")
+}
+
+// deleteBranch is DeleteBranch, leasing remote deletions on expected when
+// it's set
+func (g *Git) deleteBranch(name string, force, remote bool, expected string) error {
+	if g.IsProtected(name, remote) {
+		return newProtectedBranchError(name, g.protection.Pinned[name])
+	}
+	if !remote && name == g.checkedOutBranch() {
+		return newCurrentBranchError(name)
+	}
+
+	// Check if branch exists, and on origin at which commit
+	var exists bool
+	var current string
+	var err error
+	if remote {
+		current, err = g.remoteHead(name)
+		exists = current != ""
+	} else {
+		exists, err = g.branchExists(name, false)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to check if branch exists: %w", err)
 	}
 	if !exists {
-		return fmt.Errorf("branch '%s' does not exist", name)
+		if remote {
+			// Already gone from origin; don't leave origin/<name> listed
+			g.removeTrackingRef(name)
+		}
+		return newBranchNotFoundError(name, remote)
+	}
+
+	// Don't delete commits pushed to the remote branch since it was listed
+	var lease string
+	if remote {
+		if lease, err = g.checkLease(name, expected, current); err != nil {
+			return err
+		}
+	}
+
+	// Refuse to lose work that only exists locally
+	if !remote && !force {
+		if err := g.checkPushed(name); err != nil {
+			return err
+		}
 	}
 
 	// For remote operations, verify access first
 	if remote {
 		if err := g.verifyRemoteAccess(); err != nil {
-			if strings.Contains(err.Error(), "Authentication failed") ||
-				strings.Contains(err.Error(), "could not read Username") ||
-				strings.Contains(err.Error(), "Permission denied") {
+			if isAuthError(err.Error()) {
 				return g.handleAuthError(err.Error())
 			}
 			return err
@@ -348,9 +585,11 @@ func (g *Git) DeleteBranch(name string, force bool, remote bool) error {
 	// Delete branch
 	var args []string
 	if remote {
-		args = []string{"push", "origin", "--delete", name}
+		args = []string{"push", leaseArg(name, lease), "origin", "--delete", name}
 	} else {
-		if force {
+		// git -d only knows about HEAD and the upstream, so branches merged
+		// into a configured merge target are deleted with -D
+		if force || g.mergedIntoTarget(name) {
 			args = []string{"branch", "-D", name}
 		} else {
 			args = []string{"branch", "-d", name}
@@ -361,17 +600,46 @@ func (g *Git) DeleteBranch(name string, force bool, remote bool) error {
 	if err != nil {
 		// Handle authentication and permission errors
 		errStr := err.Error()
-		if strings.Contains(errStr, "Authentication failed") ||
-			strings.Contains(errStr, "could not read Username") ||
-			strings.Contains(errStr, "Permission denied") {
+		if isAuthError(errStr) {
 			return g.handleAuthError(errStr)
 		}
+		if remote && strings.Contains(errStr, "remote ref does not exist") {
+			g.removeTrackingRef(name)
+			return newBranchNotFoundError(name, true)
+		}
+		if remote && isLeaseRejection(errStr) {
+			return newRemoteBranchMovedError(name, lease, "")
+		}
+		if path, ok := worktreeRejection(errStr); ok && !remote {
+			return newBranchInUseError(name, "checked out in worktree "+path)
+		}
 		return fmt.Errorf("failed to delete branch: %w", err)
 	}
 
+	// git push removes the tracking ref itself, except with fetch refspecs
+	// not mapping to refs/remotes/origin
+	if remote {
+		g.removeTrackingRef(name)
+	}
 	return nil
 }
 
+// removeTrackingRef deletes refs/remotes/origin/<name> if it's there, so
+// listings stop showing a remote branch without waiting for a fetch --prune.
+// Failing is harmless since the next prune removes it.
+func (g *Git) removeTrackingRef(name string) {
+	_, _ = g.execGit("update-ref", "-d", "refs/remotes/origin/"+name)
+}
+
+// checkedOutBranch returns the branch HEAD points to, or "" when detached
+func (g *Git) checkedOutBranch() string {
+	name, err := g.execGit("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
 // verifyRemoteAccess checks if we can access the remote repository
 func (g *Git) verifyRemoteAccess() error {
 	// Try to list remote refs
@@ -389,64 +657,79 @@ func (g *Git) verifyRemoteAccess() error {
 	return nil
 }
 
-// isBranchMerged checks if a branch is fully merged into the current branch
-func (g *Git) isBranchMerged(name string) (bool, error) {
-	// Get the current branch first
-	currentBranch, err := g.execGit("rev-parse", "--abbrev-ref", "HEAD")
+// CheckRemoteDelete verifies that a remote branch could be deleted by doing a
+// dry-run push, without changing anything on the remote
+func (g *Git) CheckRemoteDelete(name string) error {
+	_, err := g.execGit("push", "--dry-run", "origin", "--delete", name)
 	if err != nil {
-		return false, fmt.Errorf("failed to get current branch: %w", err)
-	}
-
-	// Check if the branch is merged into the current branch
-	out, err := g.execGit("branch", "--merged", currentBranch)
-	if err != nil {
-		return false, fmt.Errorf("failed to check merged branches: %w", err)
+		errStr := err.Error()
+		if isAuthError(errStr) {
+			return g.handleAuthError(errStr)
+		}
+		if strings.Contains(errStr, "remote ref does not exist") {
+			return newBranchNotFoundError(name, true)
+		}
+		return fmt.Errorf("remote deletion check failed: %w", err)
 	}
+	return nil
+}
 
-	// Look for the branch in the merged list
-	for _, line := range strings.Split(out, "\n") {
-		// Remove leading whitespace and asterisk for current branch
-		branch := strings.TrimLeft(strings.TrimSpace(line), "* ")
-		if branch == name {
-			return true, nil
+// CheckRemoteDeletes runs CheckRemoteDelete for each branch and returns the
+// failures keyed by branch name
+func (g *Git) CheckRemoteDeletes(names []string) map[string]error {
+	failures := make(map[string]error)
+	for _, name := range names {
+		if err := g.CheckRemoteDelete(name); err != nil {
+			failures[name] = err
 		}
 	}
+	return failures
+}
 
-	return false, nil
+// isAuthError reports whether git's error output indicates an authentication problem
+func isAuthError(errStr string) bool {
+	return strings.Contains(errStr, "Authentication failed") ||
+		strings.Contains(errStr, "could not read Username") ||
+		strings.Contains(errStr, "Permission denied")
 }
 
-// ListBranches lists all git branches
-func (g *Git) ListBranches() ([]GitBranch, error) {
-	// Get current branch's tracking info
-	currentTrackingBranch, err := g.execGit("rev-parse", "--abbrev-ref", "@{u}")
+// isBranchMerged checks if a branch is fully merged into the merge targets
+// (the current branch by default)
+func (g *Git) isBranchMerged(name string) (bool, error) {
+	merged, err := g.MergedIntoTargets(false)
 	if err != nil {
-		// Don't fail if branch has no upstream
-		currentTrackingBranch = ""
+		return false, err
 	}
+	return merged[name], nil
+}
 
-	// Get merged branches for quick lookup
-	mergedOut, err := g.execGit("branch", "--merged")
+// ListBranches lists all git branches
+func (g *Git) ListBranches() ([]GitBranch, error) {
+	branches, err := g.ListLocalBranches()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merged branches: %w", err)
-	}
-	mergedBranches := make(map[string]bool)
-	for _, line := range strings.Split(mergedOut, "\n") {
-		branch := strings.TrimLeft(strings.TrimSpace(line), "* ")
-		if branch != "" {
-			mergedBranches[branch] = true
-		}
+		return nil, err
 	}
 
-	// Get remote merged branches
-	remoteMergedOut, err := g.execGit("branch", "--merged")
+	remote, err := g.ListRemoteBranches()
 	if err == nil { // Don't fail if remote check fails
-		for _, line := range strings.Split(remoteMergedOut, "\n") {
-			branch := strings.TrimSpace(line)
-			if branch != "" && !strings.HasSuffix(branch, "/HEAD") {
-				mergedBranches[branch] = true
-			}
-		}
+		branches = append(branches, remote...)
+	}
+
+	return branches, nil
+}
+
+// ListLocalBranches lists local branches with their merge, staleness and
+// usage status
+func (g *Git) ListLocalBranches() ([]GitBranch, error) {
+	// Get current branch's tracking info
+	currentTrackingBranch := g.currentTrackingBranch()
+
+	// Get merged branches for quick lookup
+	mergedBranches, err := g.MergedIntoTargets(false)
+	if err != nil {
+		return nil, err
 	}
+	isDefault := g.defaultMatcher()
 
 	var branches []GitBranch
 
@@ -462,13 +745,18 @@ func (g *Git) ListBranches() ([]GitBranch, error) {
 			continue
 		}
 
-		// Parse branch line: "* branch" or "  branch"
+		// Parse branch line: "* branch", "+ branch" (checked out in
+		// another worktree) or "  branch"
 		line = strings.TrimSpace(line)
 		isCurrent := strings.HasPrefix(line, "*")
 		if isCurrent {
 			line = strings.TrimPrefix(line, "*")
 		}
+		line = strings.TrimPrefix(line, "+")
 		name := strings.TrimSpace(line)
+		if !g.allowsRef("refs/heads/" + name) {
+			continue
+		}
 
 		// Get commit hash for branch
 		hash, err := g.execGit("rev-parse", "--short", name)
@@ -482,7 +770,7 @@ func (g *Git) ListBranches() ([]GitBranch, error) {
 			Reference:  "refs/heads/" + name,
 			IsCurrent:  isCurrent,
 			IsRemote:   false,
-			IsDefault:  isProtectedBranch(name),
+			IsDefault:  isDefault(name),
 			IsMerged:   mergedBranches[name],
 		}
 
@@ -499,41 +787,99 @@ func (g *Git) ListBranches() ([]GitBranch, error) {
 		branches = append(branches, branch)
 	}
 
+	// Mark branches whose upstream was deleted as stale (non-fatal)
+	if tracking, err := g.ListTracking(); err == nil {
+		gone := make(map[string]bool, len(tracking))
+		for _, t := range tracking {
+			gone[t.Branch] = t.Gone
+		}
+		for i := range branches {
+			branches[i].IsStale = gone[branches[i].Name]
+		}
+	}
+
+	// Mark branches involved in ongoing operations (non-fatal)
+	if inUse, err := g.ScanBranchReferences(); err == nil {
+		for i := range branches {
+			branches[i].InUse = inUse[branches[i].Name]
+		}
+	}
+
+	return branches, nil
+}
+
+// ListRemoteBranches lists the remote-tracking branches of origin
+func (g *Git) ListRemoteBranches() ([]GitBranch, error) {
+	currentTrackingBranch := g.currentTrackingBranch()
+
+	// Get remote merged branches
+	remoteMerged, err := g.MergedIntoTargets(true)
+	if err != nil { // Don't fail if remote check fails
+		remoteMerged = map[string]bool{}
+	}
+	isDefault := g.defaultMatcher()
+
 	// Get all remote branches
 	remoteOut, err := g.execGit("branch", "--remotes")
-	if err == nil { // Don't fail if remote check fails
-		for _, line := range strings.Split(remoteOut, "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasSuffix(line, "/HEAD") {
-				continue
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			fullName := line
-			name := strings.TrimPrefix(fullName, "origin/")
+	var branches []GitBranch
+	for _, line := range strings.Split(remoteOut, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, "/HEAD") || strings.Contains(line, " -> ") {
+			continue
+		}
 
-			// Get commit hash for remote branch
-			hash, err := g.execGit("rev-parse", "--short", fullName)
-			if err != nil {
-				continue // Skip if we can't get hash
-			}
+		fullName := line
+		name := strings.TrimPrefix(fullName, "origin/")
+		if !g.allowsRef("refs/remotes/" + fullName) {
+			continue
+		}
 
-			branch := GitBranch{
-				Name:       name,
-				CommitHash: hash,
-				Reference:  "refs/remotes/" + fullName,
-				IsCurrent:  fullName == currentTrackingBranch,
-				IsRemote:   true,
-				IsDefault:  isProtectedBranch(name),
-				IsMerged:   mergedBranches[fullName],
-			}
+		// Get commit hash for remote branch
+		hash, err := g.execGit("rev-parse", "--short", fullName)
+		if err != nil {
+			continue // Skip if we can't get hash
+		}
 
-			branches = append(branches, branch)
+		branch := GitBranch{
+			Name:       name,
+			CommitHash: hash,
+			Reference:  "refs/remotes/" + fullName,
+			IsCurrent:  fullName == currentTrackingBranch,
+			IsRemote:   true,
+			IsDefault:  isDefault(name),
+			IsMerged:   remoteMerged[fullName],
 		}
+
+		branches = append(branches, branch)
 	}
 
 	return branches, nil
 }
 
+// currentTrackingBranch returns the upstream of the current branch, or ""
+// when it has none
+func (g *Git) currentTrackingBranch() string {
+	upstream, err := g.execGit("rev-parse", "--abbrev-ref", "@{u}")
+	if err != nil {
+		return ""
+	}
+	return upstream
+}
+
+// defaultMatcher returns a func reporting whether a branch name is the
+// default branch or one of the usual protected names. The default branch is
+// protected even if it isn't one of the usual names.
+func (g *Git) defaultMatcher() func(name string) bool {
+	defaultBranch, _ := g.DefaultBranch()
+	return func(name string) bool {
+		return isProtectedBranch(name) || name == defaultBranch
+	}
+}
+
 // isProtectedBranch checks if a branch is protected
 func isProtectedBranch(name string) bool {
 	protected := []string{"main", "master", "develop", "release"}
@@ -576,11 +922,20 @@ func parseBranchLine(line string) GitBranch {
 
 // CreateBranch creates a new branch and optionally creates an empty commit
 func (g *Git) CreateBranch(name string, createCommit bool) error {
+	if err := pkggit.ValidateBranchName(name); err != nil {
+		var invalid *pkggit.ErrInvalidBranchName
+		if errors.As(err, &invalid) {
+			return newInvalidBranchError(name, invalid.Reason)
+		}
+		return err
+	}
+
 	// Create and checkout branch
 	_, err := g.execGit("checkout", "-b", name)
 	if err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
+	g.invalidateMerged()
 
 	if createCommit {
 		_, err = g.execGit("commit", "--allow-empty", "-m", fmt.Sprintf("Test commit for %s", name))
@@ -607,5 +962,6 @@ func (g *Git) CheckoutBranch(name string) error {
 	if err != nil {
 		return fmt.Errorf("failed to checkout branch: %w", err)
 	}
+	g.invalidateMerged()
 	return nil
 }
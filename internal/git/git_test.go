@@ -5,8 +5,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,6 +32,8 @@ func setupTestRepo(t *testing.T) (string, func()) {
 		{"git", "config", "user.email", "test@example.com"},
 		{"git", "config", "user.name", "Test User"},
 		{"git", "config", "init.defaultBranch", "main"},
+		// The setting only applies to new repositories
+		{"git", "symbolic-ref", "HEAD", "refs/heads/main"},
 		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
 		{"git", "branch", "feature/test"},
 		{"git", "branch", "feature/test2"},
@@ -43,16 +49,32 @@ func setupTestRepo(t *testing.T) (string, func()) {
 }
 
 func TestNew(t *testing.T) {
-	dir := "/test/dir"
-	g := New(dir)
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
 	assert.Equal(t, dir, g.workDir)
 }
 
+func TestGitPath(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	path, err := g.GitPath("git-branch-delete")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git", "git-branch-delete"), path)
+}
+
 func TestListBranches(t *testing.T) {
 	dir, cleanup := setupTestRepo(t)
 	defer cleanup()
 
-	g := New(dir)
+	g, err := New(dir)
+	require.NoError(t, err)
 	branches, err := g.ListBranches()
 	require.NoError(t, err)
 
@@ -82,28 +104,52 @@ func TestVerifyRepo(t *testing.T) {
 	dir, cleanup := setupTestRepo(t)
 	defer cleanup()
 
-	g := New(dir)
-	err := g.verifyRepo()
+	_, err := New(dir)
 	assert.NoError(t, err)
 
-	// Test invalid repo
-	invalidDir := filepath.Join(dir, "not-a-repo")
+	// Test invalid repo; New verifies the repository
+	invalidDir := filepath.Join(t.TempDir(), "not-a-repo")
 	require.NoError(t, os.Mkdir(invalidDir, 0755))
 
-	g = New(invalidDir)
-	err = g.verifyRepo()
+	_, err = New(invalidDir)
 	assert.Error(t, err)
-	assert.IsType(t, &ErrNotGitRepo{}, err)
+	assert.IsType(t, &pkggit.ErrNotGitRepo{}, err)
+}
+
+func TestListBranchesStale(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Track an upstream branch that doesn't exist (anymore)
+	for _, args := range [][]string{
+		{"remote", "add", "origin", dir},
+		{"config", "branch.feature/test.remote", "origin"},
+		{"config", "branch.feature/test.merge", "refs/heads/feature/test"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		require.NoError(t, c.Run())
+	}
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+
+	for _, b := range branches {
+		assert.Equal(t, b.Name == "feature/test", b.IsStale, b.Name)
+	}
 }
 
 func TestDeleteBranch(t *testing.T) {
 	dir, cleanup := setupTestRepo(t)
 	defer cleanup()
 
-	g := New(dir)
+	g, err := New(dir)
+	require.NoError(t, err)
 
 	// Try deleting a branch
-	err := g.DeleteBranch("feature/test", false, false)
+	err = g.DeleteBranch("feature/test", false, false)
 	require.NoError(t, err)
 
 	// Verify branch is gone
@@ -115,41 +161,119 @@ func TestDeleteBranch(t *testing.T) {
 	}
 }
 
+func TestCreateBranch(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, g.CreateBranch("feature/new", false))
+	out, err := exec.Command("git", "-C", dir, "branch", "--show-current").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "feature/new\n", string(out))
+
+	err = g.CreateBranch("bad..name", false)
+	var invalid *ErrInvalidBranch
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "bad..name", invalid.Name)
+	assert.Equal(t, "name cannot contain '..'", invalid.Reason)
+}
+
+func TestDeleteBranchInWorktree(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	worktree := filepath.Join(t.TempDir(), "wt")
+	require.NoError(t, exec.Command("git", "-C", dir, "worktree", "add", "-q", worktree, "feature/test").Run())
+
+	err = g.DeleteBranch("feature/test", true, false)
+	var inUse *ErrBranchInUse
+	require.ErrorAs(t, err, &inUse)
+	assert.Equal(t, "feature/test", inUse.Name)
+	assert.Contains(t, inUse.Reason, "checked out in worktree")
+}
+
 func TestDeleteBranchErrors(t *testing.T) {
 	dir, cleanup := setupTestRepo(t)
 	defer cleanup()
 
-	g := New(dir)
-
-	tests := []struct {
-		name        string
-		branchName  string
-		force       bool
-		remote      bool
-		shouldError bool
-	}{
-		{
-			name:        "non-existent branch",
-			branchName:  "does-not-exist",
-			shouldError: true,
-		},
-		{
-			name:        "delete main branch",
-			branchName:  "main",
-			shouldError: true,
-		},
+	g, err := New(dir)
+	require.NoError(t, err)
+	g.SetProtection(Protection{Local: []string{"release/*"}})
+
+	c := exec.Command("git", "branch", "release/1.0")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	t.Run("non-existent branch", func(t *testing.T) {
+		err := g.DeleteBranch("does-not-exist", false, false)
+		var notFound *ErrBranchNotFound
+		require.ErrorAs(t, err, &notFound)
+		assert.Equal(t, "does-not-exist", notFound.Name)
+		assert.False(t, notFound.Remote)
+		assert.EqualError(t, err, "branch 'does-not-exist' does not exist")
+	})
+
+	t.Run("checked out branch", func(t *testing.T) {
+		err := g.DeleteBranch("main", true, false)
+		var current *ErrCurrentBranch
+		require.ErrorAs(t, err, &current)
+		assert.Equal(t, "main", current.Name)
+		assert.EqualError(t, err, "cannot delete the checked out branch 'main'")
+	})
+
+	t.Run("protected branch", func(t *testing.T) {
+		err := g.DeleteBranch("release/1.0", true, false)
+		var protected *ErrProtectedBranch
+		require.ErrorAs(t, err, &protected)
+		assert.Equal(t, "release/1.0", protected.Name)
+	})
+
+	// Branches are still there
+	for _, name := range []string{"main", "release/1.0"} {
+		exists, err := g.branchExists(name, false)
+		require.NoError(t, err)
+		assert.True(t, exists, name)
+	}
+}
+
+func TestDeleteRemoteBranchTrackingRef(t *testing.T) {
+	originDir, cleanupOrigin := setupTestRepo(t)
+	defer cleanupOrigin()
+
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		require.NoError(t, c.Run(), "git %v", args)
 	}
+	run(dir, "remote", "add", "origin", originDir)
+	run(dir, "fetch", "origin")
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := g.DeleteBranch(tt.branchName, tt.force, tt.remote)
-			if tt.shouldError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
+	g, err := New(dir)
+	require.NoError(t, err)
+	tracked := func(name string) bool {
+		_, err := g.ResolveRef("refs/remotes/origin/" + name)
+		return err == nil
 	}
+
+	require.NoError(t, g.DeleteBranch("feature/test", false, true))
+	assert.False(t, tracked("feature/test"))
+
+	// The branch was deleted on origin by someone else
+	run(originDir, "branch", "-D", "feature/test2")
+	require.True(t, tracked("feature/test2"))
+	err = g.DeleteBranch("feature/test2", false, true)
+	var notFound *ErrBranchNotFound
+	require.ErrorAs(t, err, &notFound)
+	assert.True(t, notFound.Remote)
+	assert.False(t, tracked("feature/test2"), "stale tracking ref is removed")
 }
 
 func setupBenchmarkRepo(b *testing.B) (string, func()) {
@@ -167,6 +291,8 @@ func setupBenchmarkRepo(b *testing.B) (string, func()) {
 		{"git", "config", "user.email", "test@example.com"},
 		{"git", "config", "user.name", "Test User"},
 		{"git", "config", "init.defaultBranch", "main"},
+		// The setting only applies to new repositories
+		{"git", "symbolic-ref", "HEAD", "refs/heads/main"},
 		{"git", "commit", "--allow-empty", "-m", "Initial commit"},
 	}
 
@@ -190,7 +316,8 @@ func BenchmarkListBranches(b *testing.B) {
 	dir, cleanup := setupBenchmarkRepo(b)
 	defer cleanup()
 
-	g := New(dir)
+	g, err := New(dir)
+	require.NoError(b, err)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -204,12 +331,12 @@ func BenchmarkGetCurrentBranch(b *testing.B) {
 	dir, cleanup := setupBenchmarkRepo(b)
 	defer cleanup()
 
-	g := New(dir)
+	g, err := New(dir)
+	require.NoError(b, err)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		branch, err := g.getCurrentBranch()
-		require.NoError(b, err)
+		branch := g.checkedOutBranch()
 		require.NotEmpty(b, branch)
 	}
 }
@@ -218,11 +345,17 @@ func BenchmarkGetDefaultBranch(b *testing.B) {
 	dir, cleanup := setupBenchmarkRepo(b)
 	defer cleanup()
 
-	g := New(dir)
+	// The default branch comes from origin/HEAD
+	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	cmd.Dir = dir
+	require.NoError(b, cmd.Run())
+
+	g, err := New(dir)
+	require.NoError(b, err)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		branch, err := g.getDefaultBranch()
+		branch, err := g.LocalDefaultBranch()
 		require.NoError(b, err)
 		require.NotEmpty(b, branch)
 	}
@@ -232,14 +365,14 @@ func BenchmarkMarkStaleBranches(b *testing.B) {
 	dir, cleanup := setupBenchmarkRepo(b)
 	defer cleanup()
 
-	g := New(dir)
-	branches, err := g.ListBranches()
+	g, err := New(dir)
 	require.NoError(b, err)
 
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		err := g.markStaleBranches(branches)
+		// Staleness comes from the upstreams ListTracking reports
+		_, err := g.ListTracking()
 		require.NoError(b, err)
 	}
 }
@@ -248,7 +381,8 @@ func BenchmarkDeleteBranch(b *testing.B) {
 	dir, cleanup := setupBenchmarkRepo(b)
 	defer cleanup()
 
-	g := New(dir)
+	g, err := New(dir)
+	require.NoError(b, err)
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -264,3 +398,114 @@ func BenchmarkDeleteBranch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestBranchAuthors(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	authors, err := g.BranchAuthors()
+	require.NoError(t, err)
+
+	want := Author{Name: "Test User", Email: "test@example.com"}
+	assert.Equal(t, map[string]Author{
+		"main":          want,
+		"feature/test":  want,
+		"feature/test2": want,
+	}, authors)
+}
+
+func TestBranchDetails(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	c := exec.Command("git", "commit", "--allow-empty", "-m", "Subject, with \"quotes\"\tand a tab")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	details, err := g.BranchDetails()
+	require.NoError(t, err)
+	require.Contains(t, details, "refs/heads/main")
+	require.Contains(t, details, "refs/heads/feature/test")
+
+	main := details["refs/heads/main"]
+	assert.Equal(t, "Test User", main.Author)
+	assert.Equal(t, "Subject, with \"quotes\"\tand a tab", main.Subject)
+	assert.False(t, main.CommitDate.IsZero())
+	assert.Equal(t, "Initial commit", details["refs/heads/feature/test"].Subject)
+}
+
+func TestCommitMessages(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	c := exec.Command("git", "commit", "--allow-empty", "-m", "Fix the thing", "-m", "It was broken.\nNow it isn't.")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	branches := []GitBranch{
+		{Name: "main", Reference: "refs/heads/main"},
+		{Name: "feature/test", Reference: "refs/heads/feature/test"},
+	}
+	require.NoError(t, g.WithSubjects(branches))
+	assert.Equal(t, "Fix the thing", branches[0].Message)
+	assert.Equal(t, "Initial commit", branches[1].Message)
+	assert.WithinDuration(t, time.Now(), branches[0].CommitDate, time.Hour)
+
+	details, err := g.BranchDetails()
+	require.NoError(t, err)
+	assert.Equal(t, "Fix the thing", details["refs/heads/main"].Subject)
+	assert.Equal(t, "It was broken.\nNow it isn't.", details["refs/heads/main"].Body)
+	assert.Empty(t, details["refs/heads/feature/test"].Body)
+
+	msg, err := g.CommitMessage("refs/heads/main")
+	require.NoError(t, err)
+	assert.Equal(t, "Fix the thing\n\nIt was broken.\nNow it isn't.", msg)
+
+	_, err = g.CommitMessage("HEAD")
+	assert.Error(t, err)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Tracked("feature/a")
+	r.Merged("feature/b")
+	r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+	g.SetMergedTargets([]string{"main"})
+	g.SetCherryMerged(true)
+
+	// Run with -race to catch unguarded caches
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			branches, err := g.ListBranches()
+			assert.NoError(t, err)
+			assert.NotEmpty(t, branches)
+
+			merged, err := g.MergedIntoTargets(false)
+			assert.NoError(t, err)
+			assert.True(t, merged["feature/b"])
+
+			name, err := g.DefaultBranch()
+			assert.NoError(t, err)
+			assert.Equal(t, "main", name)
+
+			assert.True(t, g.SupportsRefTransactions())
+			g.invalidateMerged()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphChars are the characters git uses to draw commit graphs; "*" marks a
+// commit and "o" a boundary commit
+const graphChars = " *o|/\\_-."
+
+// GraphLine is one line of a commit graph drawing
+type GraphLine struct {
+	Graph    string `json:"graph"`            // The drawing left of the commit
+	Commit   string `json:"commit,omitempty"` // Abbreviated hash; empty on connector lines
+	Subject  string `json:"subject,omitempty"`
+	Boundary bool   `json:"boundary,omitempty"` // The commit stays reachable from other refs
+}
+
+// UnreachableGraph draws the commits that deleting refs would make
+// unreachable, i.e. the commits no other ref (including tags) reaches. The
+// boundary commits where that history attaches to the rest are included
+// and marked.
+func (g *Git) UnreachableGraph(refs []string) ([]GraphLine, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"rev-list", "--graph", "--oneline", "--boundary"}, refs...)
+	args = append(args, "--not")
+	for _, ref := range refs {
+		args = append(args, "--exclude", ref)
+	}
+	args = append(args, "--all")
+
+	out, err := g.execGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw commit graph: %w", err)
+	}
+	return parseGraph(out), nil
+}
+
+// parseGraph splits `rev-list --graph --oneline` output into the drawing,
+// the commit and its subject
+func parseGraph(out string) []GraphLine {
+	var lines []GraphLine
+	for _, raw := range strings.Split(out, "\n") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+
+		rest := strings.TrimLeft(raw, graphChars)
+		line := GraphLine{Graph: strings.TrimRight(raw[:len(raw)-len(rest)], " ")}
+		if rest != "" {
+			line.Commit, line.Subject, _ = strings.Cut(rest, " ")
+			line.Boundary = strings.Contains(line.Graph, "o")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGraph(t *testing.T) {
+	out := "* d9fc90c gone work\n| * cecaf31 u\n|/  \no 67aea98 merged work, again\n"
+	assert.Equal(t, []GraphLine{
+		{Graph: "*", Commit: "d9fc90c", Subject: "gone work"},
+		{Graph: "| *", Commit: "cecaf31", Subject: "u"},
+		{Graph: "|/"},
+		{Graph: "o", Commit: "67aea98", Subject: "merged work, again", Boundary: true},
+	}, parseGraph(out))
+}
+
+func TestUnreachableGraph(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("checkout", "-q", "-b", "feature/lost")
+	run("commit", "-q", "--allow-empty", "-m", "lost work")
+	run("checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	lines, err := g.UnreachableGraph([]string{"refs/heads/feature/lost"})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "lost work", lines[0].Subject)
+	assert.False(t, lines[0].Boundary)
+	assert.Equal(t, "Initial commit", lines[1].Subject)
+	assert.True(t, lines[1].Boundary)
+
+	// A merged branch loses no commits, only the boundary is left
+	lines, err = g.UnreachableGraph([]string{"refs/heads/feature/test"})
+	require.NoError(t, err)
+	for _, l := range lines {
+		assert.True(t, l.Boundary)
+	}
+}
@@ -0,0 +1,203 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Thresholds past which Recommendations suggests a remediation. The object
+// limits are git's own gc.auto and gc.autoPackLimit defaults.
+const (
+	looseObjectLimit = 6700
+	packLimit        = 50
+	looseRefLimit    = 1000
+	reflogEntryLimit = 10000
+)
+
+// Remediations Recommendations can suggest and Remediate applies
+const (
+	RemedyGC           = "gc"            // Pack loose objects and consolidate packs
+	RemedyExpireReflog = "expire-reflog" // Drop reflog entries past gc.reflogExpire
+	RemedyPackRefs     = "pack-refs"     // Move loose refs into packed-refs
+)
+
+// remedyArgs are the git commands applying each remediation
+var remedyArgs = map[string][]string{
+	RemedyGC:           {"gc", "--quiet"},
+	RemedyExpireReflog: {"reflog", "expire", "--all"},
+	RemedyPackRefs:     {"pack-refs", "--all"},
+}
+
+// RepoHealth holds repository metrics relevant to its performance
+type RepoHealth struct {
+	LooseObjects  int   `json:"looseObjects"`
+	LooseSize     int64 `json:"looseSize"` // Bytes
+	Packs         int   `json:"packs"`
+	PackSize      int64 `json:"packSize"` // Bytes
+	Garbage       int   `json:"garbage"`  // Files in the object directory git doesn't recognize
+	Refs          int   `json:"refs"`
+	LooseRefs     int   `json:"looseRefs"` // Refs stored as files instead of in packed-refs
+	ReflogEntries int   `json:"reflogEntries"`
+	ReflogSize    int64 `json:"reflogSize"` // Bytes
+}
+
+// Recommendation is a remediation suggested by a health check
+type Recommendation struct {
+	Remedy string `json:"remedy"` // One of the Remedy* constants
+	Reason string `json:"reason"`
+}
+
+// Health measures the repository's objects, refs and reflogs
+func (g *Git) Health() (RepoHealth, error) {
+	var h RepoHealth
+
+	out, err := g.execGit("count-objects", "-v")
+	if err != nil {
+		return h, fmt.Errorf("failed to count objects: %w", err)
+	}
+	if err := parseCountObjects(out, &h); err != nil {
+		return h, err
+	}
+
+	refs, err := g.execGit("for-each-ref", "--format", "%(refname)")
+	if err != nil {
+		return h, fmt.Errorf("failed to count refs: %w", err)
+	}
+	if refs != "" {
+		h.Refs = strings.Count(refs, "\n") + 1
+	}
+
+	refsDir, err := g.GitPath("refs")
+	if err != nil {
+		return h, err
+	}
+	if err := walkFiles(refsDir, func(string, fs.FileInfo) error {
+		h.LooseRefs++
+		return nil
+	}); err != nil {
+		return h, fmt.Errorf("failed to count loose refs: %w", err)
+	}
+
+	logsDir, err := g.GitPath("logs")
+	if err != nil {
+		return h, err
+	}
+	if err := walkFiles(logsDir, func(p string, info fs.FileInfo) error {
+		h.ReflogSize += info.Size()
+		n, err := countLines(p)
+		h.ReflogEntries += n
+		return err
+	}); err != nil {
+		return h, fmt.Errorf("failed to measure reflogs: %w", err)
+	}
+
+	return h, nil
+}
+
+// Recommendations returns the remediations the metrics call for
+func (h RepoHealth) Recommendations() []Recommendation {
+	var recs []Recommendation
+	switch {
+	case h.LooseObjects > looseObjectLimit:
+		recs = append(recs, Recommendation{RemedyGC, fmt.Sprintf("%d loose objects (more than %d)", h.LooseObjects, looseObjectLimit)})
+	case h.Packs > packLimit:
+		recs = append(recs, Recommendation{RemedyGC, fmt.Sprintf("%d packs (more than %d)", h.Packs, packLimit)})
+	}
+	if h.ReflogEntries > reflogEntryLimit {
+		recs = append(recs, Recommendation{RemedyExpireReflog, fmt.Sprintf("%d reflog entries (more than %d)", h.ReflogEntries, reflogEntryLimit)})
+	}
+	if h.LooseRefs > looseRefLimit {
+		recs = append(recs, Recommendation{RemedyPackRefs, fmt.Sprintf("%d loose refs (more than %d)", h.LooseRefs, looseRefLimit)})
+	}
+	return recs
+}
+
+// Remediate applies one of the Remedy* remediations
+func (g *Git) Remediate(remedy string) error {
+	args, ok := remedyArgs[remedy]
+	if !ok {
+		return fmt.Errorf("unknown remediation %q", remedy)
+	}
+	if remedy == RemedyGC && g.IsShared() {
+		return fmt.Errorf("gc is disabled in shared clones, where it can prune objects other clones borrow")
+	}
+	if _, err := g.execGit(args...); err != nil {
+		return fmt.Errorf("%s failed: %w", remedy, err)
+	}
+	return nil
+}
+
+// parseCountObjects parses `git count-objects -v` output, whose sizes are
+// in KiB
+func parseCountObjects(out string, h *RepoHealth) error {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		// Shared clones list the object directories they borrow from
+		if !ok || key == "alternate" {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid count-objects line %q: %w", line, err)
+		}
+		switch key {
+		case "count":
+			h.LooseObjects = int(n)
+		case "size":
+			h.LooseSize = n << 10
+		case "packs":
+			h.Packs = int(n)
+		case "size-pack":
+			h.PackSize = n << 10
+		case "garbage":
+			h.Garbage = int(n)
+		}
+	}
+	return nil
+}
+
+// walkFiles calls fn for every regular file below dir, which may not exist
+func walkFiles(dir string, fn func(path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(p, info)
+	})
+}
+
+// countLines returns the number of lines in the file at path
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n := 0
+	buf := make([]byte, 32*1024)
+	for {
+		c, err := f.Read(buf)
+		n += bytes.Count(buf[:c], []byte{'\n'})
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	out := "count: 12\nsize: 48\nin-pack: 100\npacks: 2\nsize-pack: 30\nprune-packable: 0\ngarbage: 1\nsize-garbage: 4\nalternate: /cache/repo.git/objects"
+
+	var h RepoHealth
+	require.NoError(t, parseCountObjects(out, &h))
+	assert.Equal(t, RepoHealth{LooseObjects: 12, LooseSize: 48 << 10, Packs: 2, PackSize: 30 << 10, Garbage: 1}, h)
+
+	assert.Error(t, parseCountObjects("count: many", &h))
+}
+
+func TestRecommendations(t *testing.T) {
+	tests := []struct {
+		name   string
+		health RepoHealth
+		want   []string
+	}{
+		{name: "healthy", health: RepoHealth{LooseObjects: 10, Packs: 1, Refs: 5, ReflogEntries: 20}},
+		{name: "loose objects", health: RepoHealth{LooseObjects: looseObjectLimit + 1}, want: []string{RemedyGC}},
+		{name: "too many packs", health: RepoHealth{Packs: packLimit + 1}, want: []string{RemedyGC}},
+		{name: "both need gc once", health: RepoHealth{LooseObjects: looseObjectLimit + 1, Packs: packLimit + 1}, want: []string{RemedyGC}},
+		{
+			name:   "reflogs and refs",
+			health: RepoHealth{ReflogEntries: reflogEntryLimit + 1, LooseRefs: looseRefLimit + 1},
+			want:   []string{RemedyExpireReflog, RemedyPackRefs},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range tt.health.Recommendations() {
+				got = append(got, r.Remedy)
+				assert.NotEmpty(t, r.Reason)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHealthAndRemediate(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	h, err := g.Health()
+	require.NoError(t, err)
+	assert.Positive(t, h.LooseObjects)
+	assert.Positive(t, h.Refs)
+	assert.Positive(t, h.LooseRefs)
+	refs := h.Refs
+	assert.Positive(t, h.ReflogEntries)
+
+	require.NoError(t, g.Remediate(RemedyPackRefs))
+	require.NoError(t, g.Remediate(RemedyExpireReflog))
+	require.NoError(t, g.Remediate(RemedyGC))
+	assert.Error(t, g.Remediate("bogus"))
+
+	h, err = g.Health()
+	require.NoError(t, err)
+	assert.Zero(t, h.LooseObjects)
+	assert.Zero(t, h.LooseRefs)
+	assert.Equal(t, refs, h.Refs)
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Risk levels of deleting a branch ref
+const (
+	RiskNone = "none" // No commits are referenced only by the branch
+	RiskLow  = "low"  // Commits only the branch reaches stay reachable through tags
+	RiskHigh = "high" // Commits, and any notes on them, become unreachable
+)
+
+// DeletionImpact describes what deleting a branch ref does to the commits
+// only it references
+type DeletionImpact struct {
+	UniqueCommits int `json:"uniqueCommits"` // Commits no other branch, remote branch or stash reaches
+	// Tags point at unique commits and keep them (and their history) reachable
+	Tags          []string `json:"tags,omitempty"`
+	LostCommits   int      `json:"lostCommits"`   // Unique commits not kept reachable by tags
+	OrphanedNotes int      `json:"orphanedNotes"` // Git notes attached to lost commits
+}
+
+// Risk classifies the impact as one of the Risk* levels
+func (i DeletionImpact) Risk() string {
+	switch {
+	case i.LostCommits > 0:
+		return RiskHigh
+	case i.UniqueCommits > 0:
+		return RiskLow
+	default:
+		return RiskNone
+	}
+}
+
+// CommitSummary is the abbreviated hash and subject of a commit
+type CommitSummary struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+}
+
+// UniqueCommits lists the commits no other branch, remote branch or stash
+// reaches, newest first, like DeletionImpact's UniqueCommits
+func (g *Git) UniqueCommits(ref string) ([]CommitSummary, error) {
+	out, err := g.execGit("rev-list", "--oneline", ref, "--not", "--exclude", ref, "--exclude", "refs/tags/*", "--all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unique commits: %w", err)
+	}
+
+	var commits []CommitSummary
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, CommitSummary{Hash: hash, Subject: subject})
+	}
+	return commits, nil
+}
+
+// DeletionImpact checks which tags and git notes refer to commits that only
+// ref (e.g. refs/heads/feature) reaches, and whether those commits stay
+// reachable once ref is deleted
+func (g *Git) DeletionImpact(ref string) (DeletionImpact, error) {
+	var impact DeletionImpact
+
+	// Commits reachable from ref but from no other ref except tags
+	out, err := g.execGit("rev-list", ref, "--not", "--exclude", ref, "--exclude", "refs/tags/*", "--all")
+	if err != nil {
+		return impact, fmt.Errorf("failed to list unique commits: %w", err)
+	}
+	unique := lineSet(out)
+	impact.UniqueCommits = len(unique)
+	if len(unique) == 0 {
+		return impact, nil
+	}
+
+	// Of those, the commits tags don't keep reachable either
+	out, err = g.execGit("rev-list", ref, "--not", "--exclude", ref, "--all")
+	if err != nil {
+		return impact, fmt.Errorf("failed to list unreachable commits: %w", err)
+	}
+	lost := lineSet(out)
+	impact.LostCommits = len(lost)
+
+	out, err = g.execGit("for-each-ref", "--format", "%(refname:short) %(objectname) %(*objectname)", "refs/tags")
+	if err != nil {
+		return impact, fmt.Errorf("failed to list tags: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// Annotated tags point at the commit through the peeled object
+		target := fields[len(fields)-1]
+		if unique[target] {
+			impact.Tags = append(impact.Tags, fields[0])
+		}
+	}
+
+	// Notes don't keep the commits they annotate reachable
+	if out, err := g.execGit("notes", "list"); err == nil {
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && lost[fields[1]] {
+				impact.OrphanedNotes++
+			}
+		}
+	}
+
+	return impact, nil
+}
+
+// lineSet returns the non-empty lines of out as a set
+func lineSet(out string) map[string]bool {
+	set := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = true
+		}
+	}
+	return set
+}
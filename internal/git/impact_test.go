@@ -0,0 +1,104 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionImpact(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// feature/tagged: two commits, the first one tagged
+	run("checkout", "-b", "feature/tagged")
+	run("commit", "--allow-empty", "-m", "tagged")
+	run("tag", "-a", "v1", "-m", "v1")
+	run("commit", "--allow-empty", "-m", "untagged")
+	run("notes", "add", "-m", "review notes")
+
+	// feature/fully-tagged: one commit, kept by a lightweight tag
+	run("checkout", "-b", "feature/fully-tagged", "main")
+	run("commit", "--allow-empty", "-m", "kept")
+	run("tag", "kept")
+	run("checkout", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		ref  string
+		want DeletionImpact
+		risk string
+	}{
+		{
+			name: "merged branch",
+			ref:  "refs/heads/feature/test",
+			want: DeletionImpact{},
+			risk: RiskNone,
+		},
+		{
+			name: "partially tagged with notes",
+			ref:  "refs/heads/feature/tagged",
+			want: DeletionImpact{UniqueCommits: 2, Tags: []string{"v1"}, LostCommits: 1, OrphanedNotes: 1},
+			risk: RiskHigh,
+		},
+		{
+			name: "kept by tag",
+			ref:  "refs/heads/feature/fully-tagged",
+			want: DeletionImpact{UniqueCommits: 1, Tags: []string{"kept"}},
+			risk: RiskLow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impact, err := g.DeletionImpact(tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, impact)
+			assert.Equal(t, tt.risk, impact.Risk())
+		})
+	}
+}
+
+func TestUniqueCommits(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for _, args := range [][]string{
+		{"checkout", "-q", "-b", "feature/work"},
+		{"commit", "--allow-empty", "-m", "first"},
+		{"tag", "v1"},
+		{"commit", "--allow-empty", "-m", "second"},
+		{"checkout", "-q", "main"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	commits, err := g.UniqueCommits("refs/heads/feature/work")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "second", commits[0].Subject)
+	assert.Equal(t, "first", commits[1].Subject)
+	assert.NotEmpty(t, commits[0].Hash)
+
+	commits, err = g.UniqueCommits("refs/heads/feature/test")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// leaseCommitPattern matches the commit of a --force-with-lease argument
+var leaseCommitPattern = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// DeleteRemoteBranchAt deletes a branch from origin only while it still
+// points at expected, the commit it had when it was listed. When someone
+// pushed to it since, nothing is deleted and ErrRemoteBranchMoved is
+// returned. Abbreviated hashes are fine.
+func (g *Git) DeleteRemoteBranchAt(name, expected string) error {
+	return g.deleteBranch(name, false, true, expected)
+}
+
+// remoteHead returns the commit origin has for a branch, or "" when origin
+// doesn't have it
+func (g *Git) remoteHead(name string) (string, error) {
+	out, err := g.execGit("ls-remote", "origin", "refs/heads/"+name)
+	if err != nil {
+		return "", err
+	}
+	hash, _, _ := strings.Cut(out, "\t")
+	return strings.TrimSpace(hash), nil
+}
+
+// checkLease compares the commit origin has for a branch with the one the
+// caller listed, or else with its remote-tracking branch, which is what
+// listings showed. It returns the full commit to lease the deletion on.
+func (g *Git) checkLease(name, expected, current string) (string, error) {
+	if expected == "" {
+		expected, _ = g.execGit("rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+name)
+	}
+	if expected != "" && !strings.HasPrefix(current, expected) {
+		return "", newRemoteBranchMovedError(name, expected, current)
+	}
+	return current, nil
+}
+
+// leaseArg returns the push option that makes updating or deleting a
+// branch on origin fail unless it still points at commit
+func leaseArg(name, commit string) string {
+	return "--force-with-lease=refs/heads/" + name + ":" + commit
+}
+
+// isLeaseRejection reports whether a push was rejected because a leased
+// ref moved in the meantime
+func isLeaseRejection(msg string) bool {
+	return strings.Contains(msg, "stale info")
+}
+
+// shortHash abbreviates a commit hash for messages
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
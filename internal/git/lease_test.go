@@ -0,0 +1,67 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteDeleteLease(t *testing.T) {
+	r := testutil.NewRemote(t)
+	for _, name := range []string{"feature/listed", "feature/pushed", "feature/expected", "feature/other"} {
+		r.Tracked(name)
+	}
+	main := r.Git("rev-parse", "main")
+	// Someone else moves a branch on origin; this clone hasn't fetched
+	moveOnOrigin := func(name string) {
+		r.Git("--git-dir", r.Origin, "update-ref", "refs/heads/"+name, main)
+	}
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	t.Run("unchanged since fetch", func(t *testing.T) {
+		require.NoError(t, g.DeleteBranch("feature/listed", false, true))
+		assert.False(t, r.HasRemoteBranch("feature/listed"))
+	})
+
+	t.Run("pushed to since fetch", func(t *testing.T) {
+		tracked := r.Git("rev-parse", "origin/feature/pushed")
+		moveOnOrigin("feature/pushed")
+
+		err := g.DeleteBranch("feature/pushed", false, true)
+		var moved *ErrRemoteBranchMoved
+		require.ErrorAs(t, err, &moved)
+		assert.Equal(t, tracked, moved.Expected)
+		assert.Equal(t, main, moved.Actual)
+		assert.Equal(t, FailureMoved, ClassifyError(err))
+		assert.True(t, r.HasRemoteBranch("feature/pushed"))
+	})
+
+	t.Run("explicit expected commit", func(t *testing.T) {
+		listed := r.Git("rev-parse", "--short", "origin/feature/expected")
+		require.NoError(t, g.DeleteRemoteBranchAt("feature/expected", listed))
+		assert.False(t, r.HasRemoteBranch("feature/expected"))
+	})
+
+	t.Run("explicit commit moved", func(t *testing.T) {
+		listed := r.Git("rev-parse", "origin/feature/other")
+		moveOnOrigin("feature/other")
+		// Even after fetching, the commit the caller listed counts
+		r.Git("fetch", "--quiet", "origin")
+
+		err := g.DeleteRemoteBranchAt("feature/other", listed)
+		var moved *ErrRemoteBranchMoved
+		require.ErrorAs(t, err, &moved)
+		assert.True(t, r.HasRemoteBranch("feature/other"))
+	})
+}
+
+func TestLeaseArg(t *testing.T) {
+	arg := leaseArg("feature/x", "0a1b2c3d4e5f")
+	assert.Equal(t, "--force-with-lease=refs/heads/feature/x:0a1b2c3d4e5f", arg)
+	assert.NoError(t, ValidateGitArg(arg))
+	assert.True(t, isLeaseRejection(" ! [rejected]        feature/x (stale info)"))
+}
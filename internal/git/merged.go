@@ -0,0 +1,306 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// mergedKey identifies one `git branch --merged` computation, or with
+// cherry set one CherryPickedBranches computation
+type mergedKey struct {
+	target string
+	remote bool
+	cherry bool
+}
+
+// MergedBranches returns the local (or, with remote set, remote-tracking)
+// branches merged into target. Results are cached per target for the
+// lifetime of g, so commands that check merges repeatedly in one run only
+// ask git once; checking out or creating a branch clears the cache. The
+// result is shared and must not be modified.
+func (g *Git) MergedBranches(target string, remote bool) (map[string]bool, error) {
+	key := mergedKey{target: target, remote: remote}
+	if merged, ok := g.cachedMerged(key); ok {
+		return merged, nil
+	}
+
+	args := []string{"branch", "--merged", target}
+	if remote {
+		args = []string{"branch", "-r", "--merged", target}
+	}
+	out, err := g.execGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merged branches: %w", err)
+	}
+
+	merged := parseMergedBranches(out)
+	g.cacheMerged(key, merged)
+	return merged, nil
+}
+
+// cachedMerged returns the cached result of a merge computation, if any
+func (g *Git) cachedMerged(key mergedKey) (map[string]bool, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	merged, ok := g.merged[key]
+	return merged, ok
+}
+
+// cacheMerged caches the result of a merge computation
+func (g *Git) cacheMerged(key mergedKey, merged map[string]bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.merged == nil {
+		g.merged = make(map[mergedKey]map[string]bool)
+	}
+	g.merged[key] = merged
+}
+
+// SetMergedTargets sets the branch name patterns (e.g. "main" and
+// "release/*") that branches count as merged into. Patterns qualified with a
+// remote (e.g. "upstream/main") match that remote's branches, for forked
+// workflows where another remote than origin has the real default branch.
+// Without targets, branches count as merged when they are merged into HEAD,
+// and remote branches also when they are merged into origin's default
+// branch, which HEAD may not have caught up with.
+func (g *Git) SetMergedTargets(patterns []string) {
+	g.mergedTargets = patterns
+}
+
+// MergedIntoTargets returns the local (or, with remote set, remote-tracking)
+// branches merged into any branch matching the merge targets, or into HEAD
+// (and, for remote branches, origin's default branch) when no targets are
+// set. Both local and origin's branches matching a
+// target are used, as are other remotes' branches matching a
+// remote-qualified target; the target branches themselves are never
+// reported.
+//
+// With cherry-pick detection on, branches whose every commit was
+// cherry-picked into a target count as merged too.
+func (g *Git) MergedIntoTargets(remote bool) (map[string]bool, error) {
+	if len(g.mergedTargets) == 0 {
+		targets := []string{"HEAD"}
+		if remote {
+			targets = append(targets, g.remoteDefaultRefs()...)
+		}
+		if !g.cherryMerged && len(targets) == 1 {
+			return g.MergedBranches("HEAD", remote)
+		}
+		return g.mergedInto(targets, remote)
+	}
+
+	targets, err := g.mergeTargetRefs()
+	if err != nil {
+		return nil, err
+	}
+	merged, err := g.mergedInto(targets, remote)
+	if err != nil {
+		return nil, err
+	}
+	for name := range merged {
+		if g.isMergeTarget(name) || remote && g.isMergeTarget(strings.TrimPrefix(name, "origin/")) {
+			delete(merged, name)
+		}
+	}
+	return merged, nil
+}
+
+// MergeTargetsOf returns the merge targets each of branches is merged (or
+// cherry-picked) into, by branch reference, in the order of the patterns
+// they match, e.g. ["main", "develop"]. Targets are named as in the
+// configuration: local and origin's branches by name, other remotes'
+// branches qualified with the remote. Branches merged into no target are
+// left out; a target can be merged into another one, but never into
+// itself. It fails when no merge targets are set.
+func (g *Git) MergeTargetsOf(branches []GitBranch) (map[string][]string, error) {
+	if len(g.mergedTargets) == 0 {
+		return nil, fmt.Errorf("no merge targets set")
+	}
+	refs, err := g.mergeTargetRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string][]string)
+	for _, ref := range refs {
+		target := targetNames(ref)[0]
+		for _, remote := range []bool{false, true} {
+			var merged map[string]bool
+			for _, b := range branches {
+				if b.IsRemote != remote || slices.Contains(targets[b.Reference], target) || slices.Contains(targetNames(b.Reference), target) {
+					continue
+				}
+				if merged == nil {
+					if merged, err = g.mergedInto([]string{ref}, remote); err != nil {
+						return nil, err
+					}
+				}
+				name := b.Name
+				if remote {
+					name = strings.TrimPrefix(b.Reference, "refs/remotes/")
+				}
+				if merged[name] {
+					targets[b.Reference] = append(targets[b.Reference], target)
+				}
+			}
+		}
+	}
+	for _, names := range targets {
+		sort.SliceStable(names, func(i, j int) bool {
+			return g.mergeTargetIndex(names[i]) < g.mergeTargetIndex(names[j])
+		})
+	}
+	return targets, nil
+}
+
+// mergeTargetRefs returns the full refs of the local and remote-tracking
+// branches that are merge targets
+func (g *Git) mergeTargetRefs() ([]string, error) {
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge targets: %w", err)
+	}
+
+	var targets []string
+	for _, ref := range strings.Split(out, "\n") {
+		if ref != "" && g.isMergeTargetRef(ref) {
+			targets = append(targets, ref)
+		}
+	}
+	return targets, nil
+}
+
+// mergeTargetIndex returns the position of the first merge target pattern
+// a target name matches
+func (g *Git) mergeTargetIndex(name string) int {
+	for i, pattern := range g.mergedTargets {
+		if ok, _ := path.Match(pattern, name); ok {
+			return i
+		}
+	}
+	return len(g.mergedTargets)
+}
+
+// mergedInto returns the local (or remote-tracking) branches merged into
+// any of targets, including cherry-picked ones when detection is on
+func (g *Git) mergedInto(targets []string, remote bool) (map[string]bool, error) {
+	merged := make(map[string]bool)
+	for _, target := range targets {
+		targetMerged, err := g.MergedBranches(target, remote)
+		if err != nil {
+			return nil, err
+		}
+		for name := range targetMerged {
+			merged[name] = true
+		}
+
+		if !g.cherryMerged {
+			continue
+		}
+		picked, err := g.CherryPickedBranches(target, remote)
+		if err != nil {
+			return nil, err
+		}
+		for name := range picked {
+			merged[name] = true
+		}
+	}
+	return merged, nil
+}
+
+// remoteDefaultRefs returns origin's default branch, e.g.
+// refs/remotes/origin/main, when it has been fetched
+func (g *Git) remoteDefaultRefs() []string {
+	name, err := g.DefaultBranch()
+	if err != nil {
+		return nil
+	}
+	ref := "refs/remotes/origin/" + name
+	if _, err := g.execGitQuiet("rev-parse", "--verify", ref); err != nil {
+		return nil
+	}
+	return []string{ref}
+}
+
+// isMergeTarget reports whether a branch name matches a merge target
+func (g *Git) isMergeTarget(name string) bool {
+	for _, pattern := range g.mergedTargets {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isMergeTargetRef reports whether a ref is a merge target
+func (g *Git) isMergeTargetRef(ref string) bool {
+	for _, name := range targetNames(ref) {
+		if g.isMergeTarget(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetNames returns the names a branch ref matches as a target. Local
+// branches and origin's branches match by name; every remote branch also
+// matches by its remote-qualified name (e.g. "upstream/main").
+func targetNames(ref string) []string {
+	if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+		return []string{name}
+	}
+	qualified, ok := strings.CutPrefix(ref, "refs/remotes/")
+	if !ok {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(qualified, "origin/"); ok {
+		return []string{name, qualified}
+	}
+	return []string{qualified}
+}
+
+// mergedIntoTarget reports whether a local branch is merged into a
+// configured merge target, or cherry-picked into one. It is false when
+// neither targets nor cherry-pick detection are set, as git knows the rest.
+func (g *Git) mergedIntoTarget(name string) bool {
+	if len(g.mergedTargets) == 0 && !g.cherryMerged {
+		return false
+	}
+	merged, err := g.MergedIntoTargets(false)
+	return err == nil && merged[name]
+}
+
+// ContainedIn reports whether every commit of ref is reachable from commit,
+// e.g. whether a local branch holds nothing beyond the head a pull request
+// was merged at. Commits missing from the repository are an error.
+func (g *Git) ContainedIn(ref, commit string) (bool, error) {
+	out, err := g.execGit("rev-list", "--count", ref, "--not", commit)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare %s with %s: %w", ref, commit, err)
+	}
+	return strings.TrimSpace(out) == "0", nil
+}
+
+// invalidateMerged drops cached merge results after HEAD or history changed
+func (g *Git) invalidateMerged() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.merged = nil
+}
+
+// parseMergedBranches parses `git branch [-r] --merged` output, skipping
+// symbolic refs like "origin/HEAD -> origin/main"
+func parseMergedBranches(out string) map[string]bool {
+	merged := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		branch := strings.TrimLeft(strings.TrimSpace(line), "*+ ")
+		if branch == "" || strings.Contains(branch, " -> ") {
+			continue
+		}
+		merged[branch] = true
+	}
+	return merged
+}
@@ -0,0 +1,234 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergedBranches(t *testing.T) {
+	out := "* main\n+ feature/worktree\n  feature/test\n  origin/HEAD -> origin/main\n"
+	assert.Equal(t, map[string]bool{
+		"main":             true,
+		"feature/worktree": true,
+		"feature/test":     true,
+	}, parseMergedBranches(out))
+}
+
+func TestMergedBranchesCache(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	merged, err := g.MergedBranches("HEAD", false)
+	require.NoError(t, err)
+	assert.True(t, merged["feature/test"])
+
+	// A branch created behind g's back isn't seen until the cache is cleared
+	c := exec.Command("git", "branch", "feature/new")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	merged, err = g.MergedBranches("HEAD", false)
+	require.NoError(t, err)
+	assert.False(t, merged["feature/new"])
+
+	require.NoError(t, g.CheckoutBranch("main"))
+	merged, err = g.MergedBranches("HEAD", false)
+	require.NoError(t, err)
+	assert.True(t, merged["feature/new"])
+}
+
+func TestMergedIntoTargets(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// release/v1 gets a fix that never reaches main
+	run("checkout", "-q", "-b", "release/v1")
+	run("checkout", "-q", "-b", "fix/v1")
+	run("commit", "-q", "--allow-empty", "-m", "fix")
+	run("checkout", "-q", "release/v1")
+	run("merge", "-q", "--ff-only", "fix/v1")
+	run("checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	merged, err := g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.False(t, merged["fix/v1"], "not merged into HEAD")
+
+	g.SetMergedTargets([]string{"main", "release/*"})
+	merged, err = g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.True(t, merged["fix/v1"])
+	assert.True(t, merged["feature/test"])
+	assert.False(t, merged["main"], "targets are never merged themselves")
+	assert.False(t, merged["release/v1"])
+
+	// -d would refuse, but the branch is merged into a target
+	require.NoError(t, g.DeleteBranch("fix/v1", false, false))
+}
+
+func TestMergeTargetsOf(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// feature/dev landed on develop only; feature/wip nowhere
+	run("checkout", "-q", "-b", "develop")
+	run("checkout", "-q", "-b", "feature/dev")
+	run("commit", "-q", "--allow-empty", "-m", "dev")
+	run("checkout", "-q", "develop")
+	run("merge", "-q", "--ff-only", "feature/dev")
+	run("checkout", "-q", "-b", "feature/wip")
+	run("commit", "-q", "--allow-empty", "-m", "wip")
+	run("checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+
+	_, err = g.MergeTargetsOf(branches)
+	assert.Error(t, err, "no merge targets")
+
+	g.SetMergedTargets([]string{"develop", "main"})
+	targets, err := g.MergeTargetsOf(branches)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"refs/heads/feature/test":  {"develop", "main"},
+		"refs/heads/feature/test2": {"develop", "main"},
+		"refs/heads/feature/dev":   {"develop"},
+		"refs/heads/main":          {"develop"},
+	}, targets)
+}
+
+func TestMergedIntoRemoteTarget(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	// feature/pr was merged upstream, which origin and main haven't caught
+	// up with yet
+	run("checkout", "-q", "-b", "feature/pr")
+	run("commit", "-q", "--allow-empty", "-m", "pr")
+	run("checkout", "-q", "main")
+	run("update-ref", "refs/remotes/upstream/main", run("rev-parse", "feature/pr"))
+	run("update-ref", "refs/remotes/origin/main", run("rev-parse", "main"))
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	g.SetMergedTargets([]string{"main"})
+	merged, err := g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.False(t, merged["feature/pr"])
+
+	g.SetMergedTargets([]string{"upstream/main"})
+	merged, err = g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.True(t, merged["feature/pr"])
+	assert.True(t, merged["main"], "a lagging local default is merged too")
+
+	remote, err := g.MergedIntoTargets(true)
+	require.NoError(t, err)
+	assert.True(t, remote["origin/main"])
+	assert.False(t, remote["upstream/main"], "targets are never merged themselves")
+}
+
+func TestMergedIntoRemoteDefault(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	// feature/pr was merged on origin and fetched, but main wasn't pulled
+	run("checkout", "-q", "-b", "feature/pr")
+	run("commit", "-q", "--allow-empty", "-m", "pr")
+	run("checkout", "-q", "main")
+	run("update-ref", "refs/remotes/origin/feature/pr", run("rev-parse", "feature/pr"))
+	run("update-ref", "refs/remotes/origin/main", run("rev-parse", "feature/pr"))
+	run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	local, err := g.MergedIntoTargets(false)
+	require.NoError(t, err)
+	assert.False(t, local["feature/pr"], "local branches are still compared with HEAD")
+
+	remote, err := g.MergedIntoTargets(true)
+	require.NoError(t, err)
+	assert.True(t, remote["origin/feature/pr"])
+
+	branches, err := g.ListRemoteBranches()
+	require.NoError(t, err)
+	for _, b := range branches {
+		assert.True(t, b.IsMerged, b.Name)
+	}
+}
+
+func TestContainedIn(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	base := run("rev-parse", "main")
+	run("checkout", "-q", "feature/test")
+	run("commit", "-q", "--allow-empty", "-m", "pr head")
+	head := run("rev-parse", "HEAD")
+	run("checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	ok, err := g.ContainedIn("refs/heads/feature/test", head)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = g.ContainedIn("refs/heads/feature/test", base)
+	require.NoError(t, err)
+	assert.False(t, ok, "the branch has a commit beyond base")
+
+	_, err = g.ContainedIn("refs/heads/feature/test", strings.Repeat("0", 40))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OrphanedRef is a remote-tracking ref whose branch no longer exists on its
+// remote, left behind because nobody fetched with --prune since
+type OrphanedRef struct {
+	Remote string `json:"remote"`
+	Name   string `json:"name"` // Branch name, without the remote
+	Hash   string `json:"hash"` // Full hash of the commit the ref points at
+}
+
+// Ref returns the full name of the tracking ref, e.g.
+// "refs/remotes/origin/feature/x"
+func (o OrphanedRef) Ref() string {
+	return "refs/remotes/" + o.Remote + "/" + o.Name
+}
+
+// Remotes returns the names of the configured remotes, sorted
+func (g *Git) Remotes() ([]string, error) {
+	out, err := g.execGit("remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	remotes := strings.Fields(out)
+	sort.Strings(remotes)
+	return remotes, nil
+}
+
+// OrphanedTrackingRefs compares the tracking refs of remote with the
+// branches the remote advertises right now, returning the refs whose
+// branch is gone, sorted by name. Only remotes fetched with the standard
+// refspec can be compared; for others, which map branches to tracking refs
+// some other way, an error is returned rather than guessing.
+func (g *Git) OrphanedTrackingRefs(remote string) ([]OrphanedRef, error) {
+	if err := validateRemoteName(remote); err != nil {
+		return nil, err
+	}
+	if err := g.checkStandardRefspec(remote); err != nil {
+		return nil, err
+	}
+
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)%09%(objectname)", "refs/remotes/"+remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracking refs of %s: %w", remote, err)
+	}
+	tracking := make(map[string]string)
+	prefix := "refs/remotes/" + remote + "/"
+	for _, line := range strings.Split(out, "\n") {
+		ref, hash, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		name, ok := strings.CutPrefix(ref, prefix)
+		// HEAD is a symbolic ref to one of the others, not a branch
+		if !ok || name == "HEAD" || !g.allowsRef(ref) {
+			continue
+		}
+		tracking[name] = hash
+	}
+	if len(tracking) == 0 {
+		return nil, nil
+	}
+
+	// A failed ls-remote must never read as "every branch is gone"
+	out, err = g.execGit("ls-remote", "--heads", remote)
+	if err != nil {
+		if isAuthError(err.Error()) {
+			return nil, g.handleAuthError(err.Error())
+		}
+		return nil, fmt.Errorf("failed to list branches of %s: %w", remote, err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if ref, ok := parseLsRemoteLine(line); ok {
+			delete(tracking, ref.Name)
+		}
+	}
+
+	var orphans []OrphanedRef
+	for name, hash := range tracking {
+		orphans = append(orphans, OrphanedRef{Remote: remote, Name: name, Hash: hash})
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Name < orphans[j].Name })
+	return orphans, nil
+}
+
+// DeleteTrackingRef deletes an orphaned tracking ref, only while it still
+// points at the commit that was audited, so a fetch in between that brought
+// the branch back is never undone. Local branches are left alone; those
+// tracking the ref show their upstream as gone afterwards.
+func (g *Git) DeleteTrackingRef(o OrphanedRef) error {
+	if err := validateRemoteName(o.Remote); err != nil {
+		return err
+	}
+	if _, err := g.execGit("update-ref", "-d", o.Ref(), o.Hash); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", o.Ref(), err)
+	}
+	return nil
+}
+
+// validateRemoteName checks a remote name passed to git
+func validateRemoteName(remote string) error {
+	if remote == "" || strings.Contains(remote, "/") || ValidateGitArg(remote) != nil {
+		return fmt.Errorf("invalid remote name: %q", remote)
+	}
+	return nil
+}
+
+// checkStandardRefspec fails unless remote's fetch refspecs all map
+// refs/heads/* to refs/remotes/<remote>/*
+func (g *Git) checkStandardRefspec(remote string) error {
+	out, _ := g.execGitQuiet("config", "--get-all", "remote."+remote+".fetch")
+	want := "refs/heads/*:refs/remotes/" + remote + "/*"
+	for _, spec := range strings.Fields(out) {
+		if strings.TrimPrefix(spec, "+") != want {
+			return fmt.Errorf("remote %s is fetched with a custom refspec (%s), so its tracking refs can't be compared with its branches", remote, spec)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedTrackingRefs(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged)
+	r.Tracked("feature/gone")
+	r.Tracked("feature/kept")
+	r.DeleteOnOrigin("feature/gone")
+	r.DeleteOnOrigin("merged")
+	r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	orphans, err := g.OrphanedTrackingRefs("origin")
+	require.NoError(t, err)
+	gone := r.Git("rev-parse", "origin/feature/gone")
+	assert.Equal(t, []OrphanedRef{
+		{Remote: "origin", Name: "feature/gone", Hash: gone},
+		{Remote: "origin", Name: "merged", Hash: r.Git("rev-parse", "origin/merged")},
+	}, orphans)
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, g.DeleteTrackingRef(orphans[0]))
+		assert.Empty(t, r.Git("for-each-ref", "refs/remotes/origin/feature/gone"))
+		assert.True(t, r.HasBranch("feature/gone"), "local branches stay")
+	})
+
+	t.Run("moved since audit", func(t *testing.T) {
+		r.Git("update-ref", "refs/remotes/origin/merged", "main")
+		assert.Error(t, g.DeleteTrackingRef(orphans[1]))
+		assert.NotEmpty(t, r.Git("for-each-ref", "refs/remotes/origin/merged"))
+	})
+
+	t.Run("unreachable remote", func(t *testing.T) {
+		r.Git("remote", "add", "broken", r.Dir+"/does-not-exist")
+		r.Git("update-ref", "refs/remotes/broken/main", "main")
+		_, err := g.OrphanedTrackingRefs("broken")
+		assert.Error(t, err)
+	})
+
+	t.Run("custom refspec", func(t *testing.T) {
+		r.Git("remote", "add", "mirror", r.Origin)
+		r.Git("config", "remote.mirror.fetch", "+refs/heads/*:refs/remotes/elsewhere/*")
+		_, err := g.OrphanedTrackingRefs("mirror")
+		assert.ErrorContains(t, err, "custom refspec")
+	})
+
+	t.Run("invalid remote", func(t *testing.T) {
+		_, err := g.OrphanedTrackingRefs("../x")
+		assert.Error(t, err)
+	})
+}
+
+func TestRemotes(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Git("remote", "add", "upstream", r.Origin)
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+	remotes, err := g.Remotes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"origin", "upstream"}, remotes)
+}
@@ -0,0 +1,52 @@
+package git
+
+import "path"
+
+// Protection lists the branch name patterns (path.Match syntax, e.g.
+// "release/*") that must never be deleted. Local and remote deletions are
+// checked against separate lists, so local copies of a branch can be cleaned
+// up while the remote one stays protected.
+type Protection struct {
+	Local  []string
+	Remote []string
+
+	// Pinned are branches protected locally and remotely for a reason found
+	// at run time rather than configured, e.g. "deployed to production",
+	// by exact name
+	Pinned map[string]string
+}
+
+// SetProtection sets the patterns DeleteBranch refuses to delete
+func (g *Git) SetProtection(p Protection) {
+	g.protection = p
+}
+
+// Protection returns the patterns set with SetProtection
+func (g *Git) Protection() Protection {
+	return g.protection
+}
+
+// IsProtected reports whether deleting the local or remote branch name is
+// forbidden by the configured protection
+func (g *Git) IsProtected(name string, remote bool) bool {
+	return g.ProtectionReason(name, remote) != ""
+}
+
+// ProtectionReason returns why deleting the local or remote branch name is
+// forbidden: the reason it's pinned, "protected" when a pattern matches, or
+// "" when it isn't protected
+func (g *Git) ProtectionReason(name string, remote bool) string {
+	if reason, ok := g.protection.Pinned[name]; ok {
+		return reason
+	}
+	patterns := g.protection.Local
+	if remote {
+		patterns = g.protection.Remote
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return "protected"
+		}
+	}
+	return ""
+}
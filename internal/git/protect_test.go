@@ -0,0 +1,65 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsProtected(t *testing.T) {
+	g := &Git{}
+	g.SetProtection(Protection{
+		Local:  []string{"main"},
+		Remote: []string{"main", "release/*"},
+		Pinned: map[string]string{"feature/live": "deployed to production"},
+	})
+
+	tests := []struct {
+		name   string
+		branch string
+		remote bool
+		want   bool
+	}{
+		{"local exact", "main", false, true},
+		{"remote exact", "main", true, true},
+		{"local copy of release", "release/1.0", false, false},
+		{"remote release", "release/1.0", true, true},
+		{"pattern does not cross slashes", "release/1.0/hotfix", true, false},
+		{"unprotected", "feature/x", true, false},
+		{"pinned locally", "feature/live", false, true},
+		{"pinned remotely", "feature/live", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.IsProtected(tt.branch, tt.remote))
+		})
+	}
+
+	assert.Equal(t, "deployed to production", g.ProtectionReason("feature/live", true))
+	assert.Equal(t, "protected", g.ProtectionReason("release/1.0", true))
+	assert.Empty(t, g.ProtectionReason("release/1.0", false))
+}
+
+func TestDeleteBranchProtected(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	g.SetProtection(Protection{Local: []string{"feature/*"}})
+	err = g.DeleteBranch("feature/test", true, false)
+	var protectedErr *ErrProtectedBranch
+	assert.ErrorAs(t, err, &protectedErr)
+
+	g.SetProtection(Protection{Pinned: map[string]string{"feature/test": "deployed to staging"}})
+	err = g.DeleteBranch("feature/test", true, false)
+	require.ErrorAs(t, err, &protectedErr)
+	assert.Equal(t, "deployed to staging", protectedErr.Reason)
+
+	// Remote patterns don't protect local branches
+	g.SetProtection(Protection{Remote: []string{"feature/*"}})
+	assert.NoError(t, g.DeleteBranch("feature/test", true, false))
+}
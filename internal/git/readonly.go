@@ -0,0 +1,14 @@
+package git
+
+// SetReadOnly refuses every git command that changes the repository from
+// now on with ErrReadOnly, giving reason, e.g. "running as root". An empty
+// reason allows changes again. Reading commands run as usual, so dry runs
+// still work.
+func (g *Git) SetReadOnly(reason string) {
+	g.readOnly = reason
+}
+
+// ReadOnly returns why changes are refused, or "" when they are allowed
+func (g *Git) ReadOnly() string {
+	return g.readOnly
+}
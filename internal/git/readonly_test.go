@@ -0,0 +1,36 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnly(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged)
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	g.SetReadOnly("running as root")
+	assert.Equal(t, "running as root", g.ReadOnly())
+
+	err = g.DeleteBranch("merged", false, false)
+	var readOnly *ErrReadOnly
+	require.ErrorAs(t, err, &readOnly)
+	assert.Equal(t, "running as root", readOnly.Reason)
+	assert.True(t, r.HasBranch("merged"))
+
+	assert.Error(t, g.DeleteBranch("merged", false, true))
+	assert.True(t, r.HasRemoteBranch("merged"))
+
+	branches, err := g.ListLocalBranches()
+	require.NoError(t, err, "reading still works")
+	assert.NotEmpty(t, branches)
+	assert.NoError(t, g.CheckRemoteDelete("merged"), "push --dry-run changes nothing")
+
+	g.SetReadOnly("")
+	require.NoError(t, g.DeleteBranch("merged", false, false))
+	assert.False(t, r.HasBranch("merged"))
+}
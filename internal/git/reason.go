@@ -0,0 +1,133 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeletableReason explains why b can be deleted, e.g. "merged into main 42
+// days ago" or "upstream origin/x gone since fetch 3 days ago", joining the
+// reasons when there are several. It's empty for branches that are neither
+// merged, stale, past their TTL nor done with their tickets. Details that
+// can't be read are left out.
+func (g *Git) DeletableReason(b GitBranch, now time.Time) string {
+	var reasons []string
+	if b.IsMerged {
+		reasons = append(reasons, g.mergedReason(b, now))
+	}
+	if b.IsStale {
+		reasons = append(reasons, g.staleReason(b, now))
+	}
+	if b.TTL.Expired(now) {
+		reasons = append(reasons, ttlReason(b.TTL, now))
+	}
+	if b.TicketsDone() {
+		reasons = append(reasons, ticketsReason(b))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// mergedReason describes where and when a merged branch was merged. The
+// date is that of the first merge commit bringing the branch into the
+// target, or of the branch tip when it was fast-forwarded.
+func (g *Git) mergedReason(b GitBranch, now time.Time) string {
+	target := g.mergeTargetOf(b.Reference)
+	reason := "merged"
+	if target != "" {
+		reason += " into " + strings.TrimPrefix(strings.TrimPrefix(target, "refs/heads/"), "refs/remotes/")
+	}
+
+	var when time.Time
+	if target != "" {
+		if out, err := g.execGit("rev-list", "--ancestry-path", "--merges", "--timestamp", target, "--not", b.Reference); err == nil && out != "" {
+			lines := strings.Split(out, "\n")
+			when = parseTimestamp(lines[len(lines)-1])
+		}
+	}
+	if when.IsZero() {
+		if out, err := g.execGit("rev-list", "--no-walk", "--timestamp", b.Reference); err == nil {
+			when = parseTimestamp(out)
+		}
+	}
+	if !when.IsZero() {
+		reason += " " + daysAgo(when, now)
+	}
+	return reason
+}
+
+// mergeTargetOf returns the full ref of the merge target containing ref,
+// preferring local branches, or HEAD's branch without merge targets. It's
+// empty when none contains it, e.g. for cherry-picked branches.
+func (g *Git) mergeTargetOf(ref string) string {
+	if len(g.mergedTargets) == 0 {
+		head, err := g.execGit("symbolic-ref", "HEAD")
+		if err != nil {
+			return ""
+		}
+		return head
+	}
+
+	out, err := g.execGit("for-each-ref", "--contains", ref, "--format", "%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return ""
+	}
+	var found string
+	for _, candidate := range strings.Split(out, "\n") {
+		if candidate == "" || candidate == ref || !g.isMergeTargetRef(candidate) {
+			continue
+		}
+		if strings.HasPrefix(candidate, "refs/heads/") {
+			return candidate
+		}
+		if found == "" {
+			found = candidate
+		}
+	}
+	return found
+}
+
+// staleReason describes a branch whose upstream was deleted, dated by the
+// last fetch, which is when its removal was noticed
+func (g *Git) staleReason(b GitBranch, now time.Time) string {
+	reason := "upstream gone"
+	if upstream, err := g.execGit("for-each-ref", "--format", "%(upstream:short)", b.Reference); err == nil && upstream != "" {
+		reason = "upstream " + upstream + " gone"
+	}
+
+	path, err := g.GitPath("FETCH_HEAD")
+	if err != nil {
+		return reason
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return reason
+	}
+	return reason + " since fetch " + daysAgo(info.ModTime(), now)
+}
+
+// parseTimestamp parses the "<unix time> <hash>" lines of rev-list
+// --timestamp, returning the zero time for anything else
+func parseTimestamp(line string) time.Time {
+	ts, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
+// daysAgo returns "today", "1 day ago" or "N days ago"
+func daysAgo(t, now time.Time) string {
+	days := int(now.Sub(t).Hours() / 24)
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
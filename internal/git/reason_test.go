@@ -0,0 +1,58 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletableReason(t *testing.T) {
+	repo, err := scenario.Build(t.TempDir(), scenario.Merged, scenario.Stale, scenario.Unmerged)
+	require.NoError(t, err)
+	g, err := New(repo.Dir)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 2, 12, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		branch GitBranch
+		want   string
+	}{
+		{
+			name:   "merged",
+			branch: GitBranch{Name: scenario.Merged, Reference: "refs/heads/" + scenario.Merged, IsMerged: true},
+			want:   "merged into main 42 days ago",
+		},
+		{
+			name:   "stale without a fetch",
+			branch: GitBranch{Name: scenario.Stale, Reference: "refs/heads/" + scenario.Stale, IsStale: true},
+			want:   "upstream origin/stale gone",
+		},
+		{
+			name:   "neither",
+			branch: GitBranch{Name: scenario.Unmerged, Reference: "refs/heads/" + scenario.Unmerged},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.DeletableReason(tt.branch, now))
+		})
+	}
+
+	g.SetMergedTargets([]string{"main"})
+	merged := GitBranch{Name: scenario.Merged, Reference: "refs/heads/" + scenario.Merged, IsMerged: true, IsStale: true}
+	assert.Equal(t, "merged into main 42 days ago; upstream origin/merged gone", g.DeletableReason(merged, now))
+}
+
+func TestDaysAgo(t *testing.T) {
+	now := time.Date(2024, 2, 12, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "today", daysAgo(now.Add(-time.Hour), now))
+	assert.Equal(t, "today", daysAgo(now.Add(time.Hour), now))
+	assert.Equal(t, "1 day ago", daysAgo(now.Add(-36*time.Hour), now))
+	assert.Equal(t, "3 days ago", daysAgo(now.Add(-72*time.Hour), now))
+}
@@ -0,0 +1,137 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// worktreeRejectionPattern matches git refusing to delete a branch checked
+// out in a worktree, capturing the worktree's path
+var worktreeRejectionPattern = regexp.MustCompile(`(?:checked out|used by worktree) at '([^']+)'`)
+
+// ScanBranchReferences finds local branches involved in an ongoing operation
+// (checked out in a worktree, referenced by a stash entry, the starting point
+// of a bisect or being rebased) and returns the reason keyed by branch name.
+// Such branches must not be deleted while the operation is in progress.
+func (g *Git) ScanBranchReferences() (map[string]string, error) {
+	inUse := make(map[string]string)
+	add := func(branch, reason string) {
+		if branch == "" {
+			return
+		}
+		if existing, ok := inUse[branch]; ok {
+			reason = existing + "; " + reason
+		}
+		inUse[branch] = reason
+	}
+
+	worktrees, err := g.execGit("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	for branch, path := range parseWorktrees(worktrees) {
+		if path != g.workDir {
+			add(branch, "checked out in worktree "+path)
+		}
+	}
+
+	stashes, err := g.execGit("stash", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	for branch, entries := range parseStashes(stashes) {
+		add(branch, fmt.Sprintf("referenced by %s", strings.Join(entries, ", ")))
+	}
+
+	// Bisect records the branch it started from
+	if branch, ok := g.readGitFile("BISECT_START"); ok {
+		add(strings.TrimPrefix(branch, "refs/heads/"), "bisect in progress")
+	}
+
+	// Rebase records the branch being rebased
+	for _, file := range []string{"rebase-merge/head-name", "rebase-apply/head-name"} {
+		if ref, ok := g.readGitFile(file); ok && strings.HasPrefix(ref, "refs/heads/") {
+			add(strings.TrimPrefix(ref, "refs/heads/"), "rebase in progress")
+		}
+	}
+
+	return inUse, nil
+}
+
+// CheckNotInUse returns an ErrBranchInUse when the local branch name is in
+// inUse, as returned by ScanBranchReferences
+func CheckNotInUse(inUse map[string]string, name string) error {
+	if reason, ok := inUse[name]; ok {
+		return newBranchInUseError(name, reason)
+	}
+	return nil
+}
+
+// worktreeRejection returns the worktree git named when it refused to
+// delete a branch checked out there, e.g. "Cannot delete branch 'x'
+// checked out at '/wt'", or "used by worktree at" in newer versions
+func worktreeRejection(msg string) (string, bool) {
+	m := worktreeRejectionPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// readGitFile reads a state file from the repository's git directory
+func (g *Git) readGitFile(name string) (string, bool) {
+	path, err := g.GitPath(name)
+	if err != nil {
+		return "", false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// parseWorktrees parses `git worktree list --porcelain` into branch → worktree path
+func parseWorktrees(out string) map[string]string {
+	branches := make(map[string]string)
+	var path string
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case strings.HasPrefix(line, "branch refs/heads/"):
+			branches[strings.TrimPrefix(line, "branch refs/heads/")] = path
+		}
+	}
+	return branches
+}
+
+// parseStashes parses `git stash list` into branch → stash entries, using the
+// "WIP on <branch>:" / "On <branch>:" subjects git writes
+func parseStashes(out string) map[string][]string {
+	stashes := make(map[string][]string)
+	for _, line := range strings.Split(out, "\n") {
+		entry, subject, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		var rest string
+		switch {
+		case strings.HasPrefix(subject, "WIP on "):
+			rest = strings.TrimPrefix(subject, "WIP on ")
+		case strings.HasPrefix(subject, "On "):
+			rest = strings.TrimPrefix(subject, "On ")
+		default:
+			continue
+		}
+		branch, _, ok := strings.Cut(rest, ":")
+		if !ok || branch == "(no branch)" {
+			continue
+		}
+		stashes[branch] = append(stashes[branch], entry)
+	}
+	return stashes
+}
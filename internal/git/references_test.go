@@ -0,0 +1,79 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	out := `worktree /repo
+HEAD 9d118ddc26cf2f0068fb393191f3df337ba75550
+branch refs/heads/main
+
+worktree /repo-wt
+HEAD 9d118ddc26cf2f0068fb393191f3df337ba75550
+branch refs/heads/feature/x
+
+worktree /repo-detached
+HEAD 9d118ddc26cf2f0068fb393191f3df337ba75550
+detached`
+
+	assert.Equal(t, map[string]string{
+		"main":      "/repo",
+		"feature/x": "/repo-wt",
+	}, parseWorktrees(out))
+}
+
+func TestParseStashes(t *testing.T) {
+	out := `stash@{0}: WIP on feature/x: c201c26 work
+stash@{1}: On main: custom message
+stash@{2}: WIP on feature/x: c201c26 more work
+stash@{3}: WIP on (no branch): c201c26 detached`
+
+	assert.Equal(t, map[string][]string{
+		"feature/x": {"stash@{0}", "stash@{2}"},
+		"main":      {"stash@{1}"},
+	}, parseStashes(out))
+}
+
+func TestScanBranchReferences(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	assert.NoError(t, err)
+
+	inUse, err := g.ScanBranchReferences()
+	assert.NoError(t, err)
+	assert.Empty(t, inUse, "the main worktree's own branch is not reported")
+}
+
+func TestCheckNotInUse(t *testing.T) {
+	inUse := map[string]string{"feature/wip": "rebase in progress"}
+
+	assert.NoError(t, CheckNotInUse(inUse, "feature/done"))
+	assert.NoError(t, CheckNotInUse(nil, "feature/wip"))
+
+	err := CheckNotInUse(inUse, "feature/wip")
+	var target *ErrBranchInUse
+	assert.ErrorAs(t, err, &target)
+	assert.Equal(t, &ErrBranchInUse{Name: "feature/wip", Reason: "rebase in progress"}, target)
+}
+
+func TestWorktreeRejection(t *testing.T) {
+	tests := []struct {
+		msg    string
+		path   string
+		wantOK bool
+	}{
+		{msg: "error: Cannot delete branch 'feature/x' checked out at '/src/wt'", path: "/src/wt", wantOK: true},
+		{msg: "error: cannot delete branch 'feature/x' used by worktree at '/src/wt'", path: "/src/wt", wantOK: true},
+		{msg: "error: branch 'feature/x' not found."},
+	}
+	for _, tt := range tests {
+		path, ok := worktreeRejection(tt.msg)
+		assert.Equal(t, tt.wantOK, ok, tt.msg)
+		assert.Equal(t, tt.path, path, tt.msg)
+	}
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BranchCreations returns when each local and origin remote-tracking branch
+// was created, keyed by full ref name, as recorded in the branches'
+// reflogs. Branches whose creation already expired from the reflog are
+// left out.
+func (g *Git) BranchCreations() (map[string]time.Time, error) {
+	logs, err := g.GitPath("logs")
+	if err != nil {
+		return nil, err
+	}
+
+	created := make(map[string]time.Time)
+	for _, namespace := range []string{"refs/heads", "refs/remotes/origin"} {
+		root := filepath.Join(logs, filepath.FromSlash(namespace))
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(logs, p)
+			if err != nil {
+				return err
+			}
+			ref := filepath.ToSlash(rel)
+			if !g.allowsRef(ref) {
+				return nil
+			}
+
+			t, ok, err := reflogCreation(p)
+			if err != nil {
+				return err
+			}
+			if ok {
+				created[ref] = t
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reflogs: %w", err)
+		}
+	}
+	return created, nil
+}
+
+// reflogCreation returns the time of the entry that created the ref of the
+// reflog at path, if the reflog still has it
+func reflogCreation(path string) (time.Time, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if t, ok := parseReflogCreation(scanner.Text()); ok {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, scanner.Err()
+}
+
+// parseReflogCreation parses a reflog line
+// "<old> <new> <name> <email> <timestamp> <tz>\t<message>" and returns its
+// time when it created the ref, i.e. when the old value is all zeros
+func parseReflogCreation(line string) (time.Time, bool) {
+	entry, _, _ := strings.Cut(line, "\t")
+	fields := strings.Fields(entry)
+	if len(fields) < 4 || strings.Trim(fields[0], "0") != "" {
+		return time.Time{}, false
+	}
+
+	ts, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(ts, 0), true
+}
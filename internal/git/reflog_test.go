@@ -0,0 +1,62 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReflogCreation(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+		ok   bool
+	}{
+		{
+			name: "creation",
+			line: "0000000000000000000000000000000000000000 1f3e2d4c5b6a79881f3e2d4c5b6a79881f3e2d4c Test User <test@example.com> 1700000000 +0100\tbranch: Created from HEAD",
+			want: time.Unix(1700000000, 0),
+			ok:   true,
+		},
+		{
+			name: "update",
+			line: "1f3e2d4c5b6a79881f3e2d4c5b6a79881f3e2d4c 2f3e2d4c5b6a79881f3e2d4c5b6a79881f3e2d4c Test User <test@example.com> 1700000100 +0100\tcommit: more",
+		},
+		{
+			name: "malformed",
+			line: "garbage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseReflogCreation(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.True(t, tt.want.Equal(got))
+			}
+		})
+	}
+}
+
+func TestBranchCreations(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	before := time.Now().Add(-time.Minute)
+	c := exec.Command("git", "branch", "feature/new")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	created, err := g.BranchCreations()
+	require.NoError(t, err)
+	require.Contains(t, created, "refs/heads/feature/new")
+	assert.True(t, created["refs/heads/feature/new"].After(before))
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"path"
+	"strings"
+)
+
+// RefFilter limits which refs are listed and considered as branches, e.g. to
+// hide Gerrit's refs/for/* and change refs fetched into refs/remotes.
+// Patterns are full ref names matched like for-each-ref patterns: literally
+// up to a slash ("refs/remotes/origin/changes" matches everything below it)
+// or as a glob in path.Match syntax, where * doesn't match a slash.
+type RefFilter struct {
+	Include []string // Only these refs are branches; empty means all of refs/heads and refs/remotes
+	Exclude []string // Refs that are never branches, even if included
+}
+
+// SetRefFilter sets the refs that are treated as branches
+func (g *Git) SetRefFilter(f RefFilter) {
+	g.refFilter = f
+}
+
+// refPatterns returns the for-each-ref patterns selecting branches under
+// namespace (e.g. "refs/heads"), or nil when the filter includes none
+func (g *Git) refPatterns(namespace string) []string {
+	if len(g.refFilter.Include) == 0 {
+		return []string{namespace}
+	}
+
+	var patterns []string
+	for _, p := range g.refFilter.Include {
+		switch {
+		case strings.HasPrefix(p, namespace+"/"):
+			patterns = append(patterns, p)
+		case matchRef(p, namespace):
+			// The pattern includes the whole namespace
+			return []string{namespace}
+		}
+	}
+	return patterns
+}
+
+// allowsRef reports whether the full ref name passes the filter
+func (g *Git) allowsRef(ref string) bool {
+	for _, p := range g.refFilter.Exclude {
+		if matchRef(p, ref) {
+			return false
+		}
+	}
+	if len(g.refFilter.Include) == 0 {
+		return true
+	}
+	for _, p := range g.refFilter.Include {
+		if matchRef(p, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRef reports whether ref matches pattern literally, below it, or as a glob
+func matchRef(pattern, ref string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if ref == pattern || strings.HasPrefix(ref, pattern+"/") {
+		return true
+	}
+	ok, _ := path.Match(pattern, ref)
+	return ok
+}
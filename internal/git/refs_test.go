@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowsRef(t *testing.T) {
+	g := &Git{}
+	g.SetRefFilter(RefFilter{
+		Include: []string{"refs/heads", "refs/remotes/origin/*"},
+		Exclude: []string{"refs/remotes/origin/for", "refs/heads/changes/*"},
+	})
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"refs/heads/main", true},
+		{"refs/heads/feature/x", true},
+		{"refs/heads/changes/42", false},
+		{"refs/heads/changes/42/1", true}, // Globs don't cross slashes
+		{"refs/remotes/origin/main", true},
+		{"refs/remotes/origin/for/main", false},
+		{"refs/remotes/origin/team/x", false}, // Not included
+		{"refs/remotes/upstream/main", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.allowsRef(tt.ref))
+		})
+	}
+}
+
+func TestRefPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		want    []string
+	}{
+		{"no filter", nil, []string{"refs/heads"}},
+		{"whole namespace", []string{"refs"}, []string{"refs/heads"}},
+		{"narrowed", []string{"refs/heads/team-*", "refs/remotes/origin"}, []string{"refs/heads/team-*"}},
+		{"other namespace only", []string{"refs/remotes/origin"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Git{}
+			g.SetRefFilter(RefFilter{Include: tt.include})
+			assert.Equal(t, tt.want, g.refPatterns("refs/heads"))
+		})
+	}
+}
+
+func TestListBranchesRefFilter(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	c := exec.Command("git", "branch", "changes/42")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	g.SetRefFilter(RefFilter{Exclude: []string{"refs/heads/changes"}})
+
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+	var names []string
+	for _, b := range branches {
+		names = append(names, b.Name)
+	}
+	assert.ElementsMatch(t, []string{"main", "feature/test", "feature/test2"}, names)
+
+	tracking, err := g.ListTracking()
+	require.NoError(t, err)
+	assert.Len(t, tracking, 3)
+}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTokenUser is the user name sent with an access token when none is
+// configured. GitHub, GitLab and Gitea accept any name with a token.
+const DefaultTokenUser = "x-access-token"
+
+// RemoteAuth is how git authenticates to one remote, for repositories whose
+// remotes live on different hosts, e.g. origin on GitHub and a GitLab
+// mirror. Unset fields leave git's own configuration in charge.
+type RemoteAuth struct {
+	// SSHCommand is run as GIT_SSH_COMMAND, e.g. to select a key
+	SSHCommand string
+
+	// Token is an access token sent over HTTPS with TokenUser, or
+	// DefaultTokenUser when empty
+	Token     string
+	TokenUser string
+
+	// CredentialHelper replaces the configured credential helpers
+	CredentialHelper string
+
+	// URLRewrites replace URL prefixes (keys) with others (values) when
+	// contacting the remote, like git's url.<base>.insteadOf, e.g. to reach
+	// an https:// remote over SSH
+	URLRewrites map[string]string
+}
+
+// SetRemoteAuth sets how each remote is authenticated, by remote name. It's
+// applied to the commands contacting that remote through the environment,
+// so tokens never show up in command lines; this needs git 2.31 or newer.
+func (g *Git) SetRemoteAuth(auth map[string]RemoteAuth) {
+	g.remoteAuth = auth
+}
+
+// remoteAuthEnv returns the environment entries selecting the configured
+// SSH command of the remote the git command args contact, if any
+func (g *Git) remoteAuthEnv(args []string) []string {
+	if auth := g.remoteAuth[remoteOf(args)]; auth.SSHCommand != "" {
+		return []string{"GIT_SSH_COMMAND=" + auth.SSHCommand}
+	}
+	return nil
+}
+
+// remoteAuthConfig returns the git configuration entries applying the
+// configured authentication of the remote the git command args contact, if
+// any. They're passed with configEnv, together with the other entries.
+func (g *Git) remoteAuthConfig(args []string) [][2]string {
+	auth, ok := g.remoteAuth[remoteOf(args)]
+	if !ok {
+		return nil
+	}
+	return auth.config()
+}
+
+// config returns the git configuration entries applying auth, in order
+func (auth RemoteAuth) config() [][2]string {
+	var entries [][2]string
+	if auth.CredentialHelper != "" {
+		// An empty value clears the helpers configured so far
+		entries = append(entries, [2]string{"credential.helper", ""}, [2]string{"credential.helper", auth.CredentialHelper})
+	}
+	if auth.Token != "" {
+		user := auth.TokenUser
+		if user == "" {
+			user = DefaultTokenUser
+		}
+		basic := base64.StdEncoding.EncodeToString([]byte(user + ":" + auth.Token))
+		entries = append(entries, [2]string{"http.extraHeader", "Authorization: Basic " + basic})
+	}
+
+	prefixes := make([]string, 0, len(auth.URLRewrites))
+	for prefix := range auth.URLRewrites {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		entries = append(entries, [2]string{"url." + auth.URLRewrites[prefix] + ".insteadOf", prefix})
+	}
+	return entries
+}
+
+// configEnv passes git configuration entries in GIT_CONFIG_COUNT,
+// GIT_CONFIG_KEY_<n> and GIT_CONFIG_VALUE_<n>. Only one set of them reaches
+// git, so every entry of a command has to be passed in one call.
+func configEnv(entries [][2]string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	env := []string{fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(entries))}
+	for i, e := range entries {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, e[0]), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, e[1]))
+	}
+	return env
+}
+
+// authSummary describes the authentication configured for remote, without
+// secrets, e.g. "token, url rewrites", or "" when there is none
+func (g *Git) authSummary(remote string) string {
+	auth, ok := g.remoteAuth[remote]
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if auth.SSHCommand != "" {
+		parts = append(parts, "ssh command")
+	}
+	if auth.Token != "" {
+		parts = append(parts, "token")
+	}
+	if auth.CredentialHelper != "" {
+		parts = append(parts, "credential helper "+auth.CredentialHelper)
+	}
+	if len(auth.URLRewrites) > 0 {
+		parts = append(parts, "url rewrites")
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+)
+
+// RemoteFile reads a file from the default branch of remote, as of the last
+// fetch, e.g. a branch list a team keeps in its repository. ok is false
+// when the branch has no such file. Nothing is contacted over the network,
+// so the file is only as fresh as the tracking refs.
+func (g *Git) RemoteFile(remote, file string) (content string, ok bool, err error) {
+	if err := validateRemoteName(remote); err != nil {
+		return "", false, err
+	}
+	if err := ValidatePathspec(file); err != nil {
+		return "", false, err
+	}
+
+	head := "refs/remotes/" + remote + "/HEAD"
+	out, err := g.execGit("symbolic-ref", head)
+	if err != nil {
+		return "", false, fmt.Errorf("%s/HEAD is not set, so %s's default branch is unknown: %w", remote, remote, err)
+	}
+	branch := strings.TrimSpace(out)
+	if err := ValidateGitArg(branch); err != nil || !strings.HasPrefix(branch, "refs/remotes/"+remote+"/") {
+		return "", false, fmt.Errorf("unexpected default branch of %s: %s", remote, branch)
+	}
+
+	content, ok, err = g.trackedFile(branch, file)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s from %s: %w", file, branch, err)
+	}
+	return content, ok, nil
+}
+
+// RemoteBranchFile reads a file from branch of remote, as of the last
+// fetch, for when remote/HEAD isn't set and the default branch is known
+// otherwise. ok is false when the branch or the file doesn't exist.
+func (g *Git) RemoteBranchFile(remote, branch, file string) (content string, ok bool, err error) {
+	if err := validateRemoteName(remote); err != nil {
+		return "", false, err
+	}
+	if err := pkggit.ValidateBranchName(branch); err != nil {
+		return "", false, err
+	}
+	if err := ValidatePathspec(file); err != nil {
+		return "", false, err
+	}
+
+	ref := "refs/remotes/" + remote + "/" + branch
+	content, ok, err = g.trackedFile(ref, file)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s from %s/%s: %w", file, remote, branch, err)
+	}
+	return content, ok, nil
+}
+
+// trackedFile reads file from the tracking ref, both already validated
+func (g *Git) trackedFile(ref, file string) (string, bool, error) {
+	// <ref>:<path> is validated by the callers, piecewise, so it skips the
+	// argument checks, which don't know object names
+	object := ref + ":" + file
+	if _, err := g.execGitQuiet("cat-file", "-e", object); err != nil {
+		return "", false, nil
+	}
+	content, err := g.execGitQuiet("cat-file", "blob", object)
+	if err != nil {
+		return "", false, err
+	}
+	return content, true, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteFile(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	_, ok, err := g.RemoteFile("origin", ".gbd-protected.yaml")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(r.Dir, ".gbd-protected.yaml"), []byte("protected:\n  - release/*\n"), 0644))
+	r.Git("add", ".gbd-protected.yaml")
+	r.Git("commit", "--quiet", "-m", "Share protected branches")
+
+	_, ok, err = g.RemoteFile("origin", ".gbd-protected.yaml")
+	require.NoError(t, err)
+	assert.False(t, ok, "only pushed files count")
+
+	r.Git("push", "--quiet", "origin", "main")
+	content, ok, err := g.RemoteFile("origin", ".gbd-protected.yaml")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "protected:\n  - release/*", content)
+
+	// Naming the default branch, for when origin/HEAD isn't set
+	content, ok, err = g.RemoteBranchFile("origin", "main", ".gbd-protected.yaml")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "protected:\n  - release/*", content)
+	_, ok, err = g.RemoteBranchFile("origin", "missing", ".gbd-protected.yaml")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, _, err = g.RemoteBranchFile("origin", "-main", ".gbd-protected.yaml")
+	assert.Error(t, err)
+
+	for name, tt := range map[string]struct{ remote, file string }{
+		"remote without HEAD": {"upstream", ".gbd-protected.yaml"},
+		"invalid remote":      {"../x", ".gbd-protected.yaml"},
+		"leaving the repo":    {"origin", "../secrets"},
+		"absolute path":       {"origin", "/etc/passwd"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := g.RemoteFile(tt.remote, tt.file)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultRemoteScanPage is how many branches ScanRemoteBranches passes to
+// each call of its page func by default
+const DefaultRemoteScanPage = 1000
+
+// ErrStopScan can be returned by the page func of ScanRemoteBranches to end
+// the scan early without an error
+var ErrStopScan = errors.New("stop scan")
+
+// RemoteRef is a branch as origin advertises it
+type RemoteRef struct {
+	Name string // Branch name, without refs/heads/
+	Hash string // Full commit hash
+}
+
+// RemoteScanOptions controls ScanRemoteBranches
+type RemoteScanOptions struct {
+	// Prefixes restrict the scan to branch names starting with one of
+	// them, e.g. "feature/", each asked for with its own ls-remote. Empty
+	// scans every branch.
+	Prefixes []string
+
+	// After resumes a scan after this branch name, the last one a previous
+	// scan passed on
+	After string
+
+	// PageSize is how many branches each page holds; 0 means
+	// DefaultRemoteScanPage
+	PageSize int
+
+	// Interval is the least time between two ls-remote requests, to spare
+	// rate-limited hosts
+	Interval time.Duration
+}
+
+// ScanRemoteBranches lists origin's branches straight from the remote,
+// in name order, calling page with at most PageSize of them at a time. The
+// output of ls-remote is read as it arrives, so memory stays bounded by
+// the page size however many branches the remote has. A page error stops
+// the scan and is returned, except ErrStopScan which ends it early.
+func (g *Git) ScanRemoteBranches(opts RemoteScanOptions, page func([]RemoteRef) error) error {
+	for _, p := range opts.Prefixes {
+		if err := validateScanPrefix(p); err != nil {
+			return err
+		}
+	}
+	size := opts.PageSize
+	if size <= 0 {
+		size = DefaultRemoteScanPage
+	}
+
+	// Sorted prefixes yield names in order; a prefix inside another one
+	// only repeats names already passed on, which the cursor skips
+	prefixes := slices.Clone(opts.Prefixes)
+	slices.Sort(prefixes)
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+
+	last := opts.After
+	buf := make([]RemoteRef, 0, size)
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		err := page(buf)
+		buf = buf[:0]
+		return err
+	}
+
+	var lastRequest time.Time
+	for _, prefix := range prefixes {
+		if last != "" && prefix != "" && !strings.HasPrefix(last, prefix) && last > prefix {
+			continue // Scanned before the resumed scan stopped
+		}
+		if wait := opts.Interval - time.Since(lastRequest); !lastRequest.IsZero() && wait > 0 {
+			time.Sleep(wait)
+		}
+		lastRequest = time.Now()
+
+		args := []string{"ls-remote", "--heads", "--sort=refname", "origin"}
+		if prefix != "" {
+			args = append(args, "refs/heads/"+prefix+"*")
+		}
+		err := g.streamGit(args, func(line string) error {
+			ref, ok := parseLsRemoteLine(line)
+			if !ok || ref.Name <= last || !g.allowsRef("refs/remotes/origin/"+ref.Name) {
+				return nil
+			}
+			last = ref.Name
+			buf = append(buf, ref)
+			if len(buf) == size {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, ErrStopScan) {
+				return nil
+			}
+			return err
+		}
+	}
+	if err := flush(); err != nil && !errors.Is(err, ErrStopScan) {
+		return err
+	}
+	return nil
+}
+
+// validateScanPrefix checks a branch name prefix of a remote scan, which
+// becomes part of an ls-remote pattern
+func validateScanPrefix(prefix string) error {
+	switch {
+	case prefix == "":
+		return fmt.Errorf("scan prefix cannot be empty")
+	case strings.HasPrefix(prefix, "-"), strings.HasPrefix(prefix, "/"):
+		return fmt.Errorf("invalid scan prefix: %s", prefix)
+	case strings.ContainsAny(prefix, "*?[\\: \t\n"), strings.Contains(prefix, ".."):
+		return fmt.Errorf("scan prefix cannot contain wildcards or special characters: %s", prefix)
+	}
+	return nil
+}
+
+// parseLsRemoteLine parses a "<hash>\trefs/heads/<name>" line of ls-remote
+func parseLsRemoteLine(line string) (RemoteRef, bool) {
+	hash, ref, ok := strings.Cut(line, "\t")
+	if !ok {
+		return RemoteRef{}, false
+	}
+	name, ok := strings.CutPrefix(strings.TrimSpace(ref), "refs/heads/")
+	if !ok || name == "" {
+		return RemoteRef{}, false
+	}
+	return RemoteRef{Name: name, Hash: strings.TrimSpace(hash)}, true
+}
+
+// streamGit runs git like execGit, passing each line of its output to fn
+// as it arrives instead of collecting it. An error from fn stops git and is
+// returned.
+func (g *Git) streamGit(args []string, fn func(line string) error) error {
+	if err := validateGitArgs(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, g.gitPath, args...)
+	cmd.Dir = g.workDir
+	cmd.Env = g.commandEnv(args)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to read git output: %w", err)
+	}
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		g.record(args, started, "", "", err)
+		return newGitCommandError(strings.Join(args, " "), "", err)
+	}
+
+	// Output is only kept for the transcript
+	var output strings.Builder
+	var fnErr error
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if g.transcript != nil {
+			output.WriteString(line + "\n")
+		}
+		if strings.ContainsAny(line, "\x00\x07\x1B\x9B") {
+			fnErr = newGitCommandError(strings.Join(args, " "), line, fmt.Errorf("output contains invalid characters"))
+			break
+		}
+		if fnErr = fn(line); fnErr != nil {
+			break
+		}
+	}
+	if fnErr != nil {
+		cancel()
+		g.record(args, started, output.String(), stderr.String(), cmd.Wait())
+		return fnErr
+	}
+
+	err = cmd.Wait()
+	if err == nil {
+		err = scanner.Err()
+	}
+	g.record(args, started, output.String(), stderr.String(), err)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return newTimeoutError(strings.Join(args, " "), g.timeout.String())
+		}
+		return newGitCommandError(strings.Join(args, " "), stderr.String(), err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanRemoteBranches(t *testing.T) {
+	originDir, cleanupOrigin := setupTestRepo(t)
+	defer cleanupOrigin()
+
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		require.NoError(t, c.Run(), "git %v", args)
+	}
+	for i := 0; i < 5; i++ {
+		run(originDir, "branch", fmt.Sprintf("bot/update-%d", i))
+	}
+	run(originDir, "branch", "feature/x")
+	run(dir, "remote", "add", "origin", originDir)
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	scan := func(opts RemoteScanOptions) ([]string, []int) {
+		var names []string
+		var pages []int
+		err := g.ScanRemoteBranches(opts, func(refs []RemoteRef) error {
+			pages = append(pages, len(refs))
+			for _, r := range refs {
+				assert.Len(t, r.Hash, 40)
+				names = append(names, r.Name)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return names, pages
+	}
+
+	names, pages := scan(RemoteScanOptions{PageSize: 3})
+	assert.Equal(t, []string{
+		"bot/update-0", "bot/update-1", "bot/update-2", "bot/update-3", "bot/update-4",
+		"feature/test", "feature/test2", "feature/x", "main",
+	}, names)
+	assert.Equal(t, []int{3, 3, 3}, pages)
+
+	// Overlapping prefixes don't repeat branches
+	names, _ = scan(RemoteScanOptions{Prefixes: []string{"feature/", "bot/", "feature/te"}})
+	assert.Equal(t, []string{
+		"bot/update-0", "bot/update-1", "bot/update-2", "bot/update-3", "bot/update-4",
+		"feature/test", "feature/test2", "feature/x",
+	}, names)
+
+	// Resuming continues after the cursor
+	names, _ = scan(RemoteScanOptions{Prefixes: []string{"bot/", "feature/"}, After: "bot/update-3"})
+	assert.Equal(t, []string{"bot/update-4", "feature/test", "feature/test2", "feature/x"}, names)
+
+	// A page can stop the scan
+	var seen int
+	err = g.ScanRemoteBranches(RemoteScanOptions{PageSize: 2}, func(refs []RemoteRef) error {
+		seen += len(refs)
+		return ErrStopScan
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, seen)
+
+	for _, prefix := range []string{"feature/*", "--upload-pack=x", "a..b", ""} {
+		err := g.ScanRemoteBranches(RemoteScanOptions{Prefixes: []string{prefix}}, func([]RemoteRef) error { return nil })
+		assert.Error(t, err, prefix)
+	}
+}
@@ -0,0 +1,184 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+)
+
+// BranchRename is one branch to rename, locally or on origin
+type BranchRename struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Remote bool   `json:"remote"`
+}
+
+// ValidateRenamePattern checks a --from/--to pair. Each may hold at most one
+// "*", and either both or neither do.
+func ValidateRenamePattern(from, to string) error {
+	if from == "" || to == "" {
+		return fmt.Errorf("both the source and the target pattern are required")
+	}
+	if strings.Count(from, "*") > 1 || strings.Count(to, "*") > 1 {
+		return fmt.Errorf("rename patterns may contain at most one '*'")
+	}
+	if strings.Contains(from, "*") != strings.Contains(to, "*") {
+		return fmt.Errorf("either both or neither of %q and %q must contain '*'", from, to)
+	}
+	return nil
+}
+
+// renameTarget maps name from the from pattern to the to pattern. The "*"
+// matches any text, including slashes, and is carried over as is.
+func renameTarget(from, to, name string) (string, bool) {
+	prefix, suffix, wildcard := strings.Cut(from, "*")
+	if !wildcard {
+		if name != from {
+			return "", false
+		}
+		return to, true
+	}
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	middle := name[len(prefix) : len(name)-len(suffix)]
+	if middle == "" {
+		return "", false
+	}
+	return strings.Replace(to, "*", middle, 1), true
+}
+
+// PlanRenames returns the local branches (and, with remote set, origin's
+// branches) matching the from pattern with their new names. It fails when a
+// new name is invalid, already taken or produced twice, or when a matching
+// branch is protected. New names on origin are checked against origin
+// itself, since tracking refs miss branches pushed since the last fetch.
+func (g *Git) PlanRenames(from, to string, remote bool) ([]BranchRename, error) {
+	if err := ValidateRenamePattern(from, to); err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{"refs/heads"}
+	if remote {
+		namespaces = append(namespaces, "refs/remotes/origin")
+	}
+
+	var renames []BranchRename
+	for _, namespace := range namespaces {
+		isRemote := namespace != "refs/heads"
+		out, err := g.execGit("for-each-ref", "--format", "%(refname)", namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+
+		existing := make(map[string]bool)
+		var names []string
+		for _, ref := range strings.Split(out, "\n") {
+			name := strings.TrimPrefix(ref, namespace+"/")
+			if ref == "" || name == "HEAD" {
+				continue
+			}
+			existing[name] = true
+			if g.allowsRef(ref) {
+				names = append(names, name)
+			}
+		}
+		if isRemote {
+			live, err := g.originBranches()
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range live {
+				existing[name] = true
+			}
+		}
+
+		targets := make(map[string]string)
+		for _, name := range names {
+			target, ok := renameTarget(from, to, name)
+			if !ok || target == name {
+				continue
+			}
+			if g.IsProtected(name, isRemote) {
+				return nil, newProtectedBranchError(name, g.protection.Pinned[name])
+			}
+			if err := pkggit.ValidateBranchName(target); err != nil {
+				return nil, err
+			}
+			if existing[target] {
+				return nil, fmt.Errorf("cannot rename %s: branch '%s' already exists", name, target)
+			}
+			if other, ok := targets[target]; ok {
+				return nil, fmt.Errorf("cannot rename both %s and %s to %s", other, name, target)
+			}
+			targets[target] = name
+			renames = append(renames, BranchRename{From: name, To: target, Remote: isRemote})
+		}
+	}
+	return renames, nil
+}
+
+// originBranches returns the names of the branches on origin, asking origin
+func (g *Git) originBranches() ([]string, error) {
+	out, err := g.execGit("ls-remote", "--heads", "origin")
+	if err != nil {
+		if isAuthError(err.Error()) {
+			return nil, g.handleAuthError(err.Error())
+		}
+		return nil, fmt.Errorf("failed to list branches of origin: %w", err)
+	}
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if ref, ok := parseLsRemoteLine(line); ok {
+			names = append(names, ref.Name)
+		}
+	}
+	return names, nil
+}
+
+// RenameBranch applies a rename. Local branches keep their reflog and
+// configuration. Remote branches are pushed under the new name before the
+// old one is deleted, so the commit is never unreferenced.
+func (g *Git) RenameBranch(r BranchRename) error {
+	if g.IsProtected(r.From, r.Remote) {
+		return newProtectedBranchError(r.From, g.protection.Pinned[r.From])
+	}
+
+	if !r.Remote {
+		if _, err := g.execGit("branch", "-m", r.From, r.To); err != nil {
+			return fmt.Errorf("failed to rename branch: %w", err)
+		}
+		g.invalidateMerged()
+		return nil
+	}
+
+	hash, err := g.remoteBranchHash(r.From)
+	if err != nil {
+		return err
+	}
+	if _, err := g.execGit("push", "origin", hash+":refs/heads/"+r.To); err != nil {
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		return fmt.Errorf("failed to push renamed branch: %w", err)
+	}
+	if _, err := g.execGit("push", leaseArg(r.From, hash), "origin", "--delete", r.From); err != nil {
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		if isLeaseRejection(err.Error()) {
+			err = newRemoteBranchMovedError(r.From, hash, "")
+		}
+		return fmt.Errorf("branch pushed as %s but failed to delete %s: %w", r.To, r.From, err)
+	}
+	return nil
+}
+
+// SetUpstream makes origin/<upstream> the upstream of a local branch
+func (g *Git) SetUpstream(name, upstream string) error {
+	if _, err := g.execGit("branch", "--set-upstream-to", "origin/"+upstream, name); err != nil {
+		return fmt.Errorf("failed to set upstream of %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameTarget(t *testing.T) {
+	tests := []struct {
+		from, to, name string
+		want           string
+		ok             bool
+	}{
+		{"feature/*", "feat/*", "feature/login", "feat/login", true},
+		{"feature/*", "feat/*", "feature/auth/login", "feat/auth/login", true},
+		{"feature/*", "feat/*", "bugfix/login", "", false},
+		{"feature/*", "feat/*", "feature/", "", false},
+		{"*-wip", "wip/*", "login-wip", "wip/login", true},
+		{"old", "new", "old", "new", true},
+		{"old", "new", "older", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := renameTarget(tt.from, tt.to, tt.name)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateRenamePattern(t *testing.T) {
+	assert.NoError(t, ValidateRenamePattern("feature/*", "feat/*"))
+	assert.NoError(t, ValidateRenamePattern("old", "new"))
+	assert.Error(t, ValidateRenamePattern("feature/*", "feat"))
+	assert.Error(t, ValidateRenamePattern("*/*", "x/*"))
+	assert.Error(t, ValidateRenamePattern("", "x"))
+}
+
+func TestPlanAndRenameBranches(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	c := exec.Command("git", "branch", "feature/other")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	renames, err := g.PlanRenames("feature/*", "feat/*", false)
+	require.NoError(t, err)
+	assert.Equal(t, []BranchRename{
+		{From: "feature/other", To: "feat/other"},
+		{From: "feature/test", To: "feat/test"},
+		{From: "feature/test2", To: "feat/test2"},
+	}, renames)
+
+	_, err = g.PlanRenames("feature/*", "main", false)
+	assert.Error(t, err, "patterns must agree on the wildcard")
+	_, err = g.PlanRenames("feature/test", "feature/other", false)
+	assert.Error(t, err, "target exists")
+
+	g.SetProtection(Protection{Local: []string{"feature/*"}})
+	_, err = g.PlanRenames("feature/*", "feat/*", false)
+	var protected *ErrProtectedBranch
+	assert.ErrorAs(t, err, &protected)
+	g.SetProtection(Protection{})
+
+	for _, r := range renames {
+		require.NoError(t, g.RenameBranch(r))
+	}
+	_, err = g.ResolveRef("refs/heads/feat/test")
+	assert.NoError(t, err)
+	_, err = g.ResolveRef("refs/heads/feature/test")
+	assert.Error(t, err)
+}
+
+func TestPlanRenamesRemote(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Tracked("feature/a")
+	r.Tracked("feature/b")
+
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	renames, err := g.PlanRenames("feature/*", "feat/*", true)
+	require.NoError(t, err)
+	assert.Contains(t, renames, BranchRename{From: "feature/a", To: "feat/a", Remote: true})
+
+	// Pushed since the last fetch: only origin knows about it
+	r.Git("--git-dir", r.Origin, "branch", "feat/b", "main")
+	_, err = g.PlanRenames("feature/*", "feat/*", true)
+	assert.ErrorContains(t, err, "branch 'feat/b' already exists")
+
+	// Names are held to git's branch name rules
+	_, err = g.PlanRenames("feature/a", "feat/a.lock", false)
+	var invalid *pkggit.ErrInvalidBranchName
+	assert.ErrorAs(t, err, &invalid)
+	_, err = g.PlanRenames("feature/*", "feat..*", false)
+	assert.ErrorAs(t, err, &invalid)
+}
@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// IsBranchPattern reports whether a branch argument is a glob, such as
+// "backup/*" or "release/*-rc*", rather than a branch name
+func IsBranchPattern(arg string) bool {
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// MatchBranches returns the names of the branches matching pattern, newest
+// first by committer date. Patterns use path.Match syntax, where * doesn't
+// match a slash. Remote matches the branches of origin, by their name on
+// origin.
+func (g *Git) MatchBranches(pattern string, remote bool) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+	}
+	details, err := g.BranchDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := "refs/heads/"
+	if remote {
+		prefix = "refs/remotes/origin/"
+	}
+	var names []string
+	for ref := range details {
+		name, ok := strings.CutPrefix(ref, prefix)
+		if !ok || name == "HEAD" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := details[prefix+names[i]].CommitDate, details[prefix+names[j]].CommitDate
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
+}
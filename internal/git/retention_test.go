@@ -0,0 +1,63 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBranchPattern(t *testing.T) {
+	assert.True(t, IsBranchPattern("backup/*"))
+	assert.True(t, IsBranchPattern("release/*-rc?"))
+	assert.True(t, IsBranchPattern("v[12]"))
+	assert.False(t, IsBranchPattern("feature/123"))
+}
+
+func TestMatchBranches(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	commit := func(branch, date string) {
+		for _, args := range [][]string{
+			{"checkout", "-q", "-b", branch, "main"},
+			{"commit", "-q", "--allow-empty", "-m", branch},
+		} {
+			c := exec.Command("git", args...)
+			c.Dir = dir
+			c.Env = append(c.Environ(), "GIT_COMMITTER_DATE="+date)
+			out, err := c.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+	commit("backup/b", "2024-03-01T00:00:00Z")
+	commit("backup/a", "2024-01-01T00:00:00Z")
+	commit("backup/c", "2024-02-01T00:00:00Z")
+	commit("backup/nested/d", "2024-04-01T00:00:00Z")
+	commit("release/1.0-rc1", "2024-01-01T00:00:00Z")
+	commit("release/1.0", "2024-01-01T00:00:00Z")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "backup/*", want: []string{"backup/b", "backup/c", "backup/a"}},
+		{pattern: "backup/*/*", want: []string{"backup/nested/d"}},
+		{pattern: "release/*-rc*", want: []string{"release/1.0-rc1"}},
+		{pattern: "hotfix/*", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := g.MatchBranches(tt.pattern, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = g.MatchBranches("backup/[", false)
+	assert.ErrorContains(t, err, "invalid branch pattern")
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RunNotesRef is the notes ref runs are recorded in. Notes travel with
+// pushes and fetches of this ref, so teammates can share the records.
+const RunNotesRef = "refs/notes/git-branch-delete"
+
+// AddRunNote records note on commit in RunNotesRef, replacing an earlier
+// record on the same commit
+func (g *Git) AddRunNote(commit, note string) error {
+	if _, err := g.execGitInput(strings.NewReader(note), "notes", "--ref", RunNotesRef, "add", "-f", "-F", "-", commit); err != nil {
+		return fmt.Errorf("failed to record run note: %w", err)
+	}
+	return nil
+}
+
+// RunNotes returns the notes recorded in RunNotesRef, keyed by the commit
+// they're attached to. It's empty when nothing was recorded yet.
+func (g *Git) RunNotes() (map[string]string, error) {
+	if _, err := g.ResolveRef(RunNotesRef); err != nil {
+		return map[string]string{}, nil
+	}
+
+	out, err := g.execGit("notes", "--ref", RunNotesRef, "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run notes: %w", err)
+	}
+
+	// Each line is "<note blob> <annotated object>"
+	var blobs, targets []string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		blobs = append(blobs, fields[0])
+		targets = append(targets, fields[1])
+	}
+	notes := make(map[string]string, len(blobs))
+	if len(blobs) == 0 {
+		return notes, nil
+	}
+
+	// One cat-file reads every note, however many runs were recorded
+	out, err = g.execGitInput(strings.NewReader(strings.Join(blobs, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run notes: %w", err)
+	}
+	contents, err := parseBlobBatch(out, len(blobs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run notes: %w", err)
+	}
+	for i, target := range targets {
+		notes[target] = strings.TrimSpace(contents[i])
+	}
+	return notes, nil
+}
+
+// parseBlobBatch splits the output of 'git cat-file --batch' into the
+// contents of its n blobs. Each is a "<hash> blob <size>" line, the
+// content and a newline; the output's last newline may be trimmed.
+func parseBlobBatch(out string, n int) ([]string, error) {
+	contents := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, rest, _ := strings.Cut(out, "\n")
+		fields := strings.Fields(header)
+		if len(fields) != 3 || fields[1] != "blob" {
+			return nil, fmt.Errorf("unexpected object header: %q", header)
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected object header: %q", header)
+		}
+		if size > len(rest) {
+			// The trimmed output may have lost the last blob's trailing
+			// whitespace, but nothing else
+			if i != n-1 {
+				return nil, fmt.Errorf("truncated object: %q", header)
+			}
+			size = len(rest)
+		}
+		contents = append(contents, rest[:size])
+		out = strings.TrimPrefix(rest[size:], "\n")
+	}
+	return contents, nil
+}
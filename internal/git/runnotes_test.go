@@ -0,0 +1,72 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunNotes(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	notes, err := g.RunNotes()
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	head, err := g.ResolveRef("HEAD")
+	require.NoError(t, err)
+	require.NoError(t, g.AddRunNote(head, `{"candidates": ["a"]}`))
+	require.NoError(t, g.AddRunNote(head, `{"candidates": ["a", "b"]}`))
+
+	notes, err = g.RunNotes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{head: `{"candidates": ["a", "b"]}`}, notes)
+
+	// The default notes ref is left alone
+	_, err = g.ResolveRef("refs/notes/commits")
+	assert.Error(t, err)
+}
+
+func TestRunNotesMany(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	want := map[string]string{}
+	for _, ref := range []string{"main", "feature/test", "feature/test2"} {
+		commit, err := g.ResolveRef(ref)
+		require.NoError(t, err)
+		// Notes of several lines, the last one ending the output
+		note := "{\n  \"branch\": \"" + ref + "\"\n}"
+		require.NoError(t, g.AddRunNote(commit, note))
+		want[commit] = note
+	}
+
+	notes, err := g.RunNotes()
+	require.NoError(t, err)
+	assert.Equal(t, want, notes)
+}
+
+func TestParseBlobBatch(t *testing.T) {
+	out := "aaaa blob 6\nfirst\n\nbbbb blob 7\nsecond\n"
+	contents, err := parseBlobBatch(out, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first\n", "second\n"}, contents)
+
+	// Trimmed output loses the trailing newline of the last blob only
+	contents, err = parseBlobBatch("aaaa blob 6\nfirst\n\nbbbb blob 7\nsecond", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first\n", "second"}, contents)
+
+	_, err = parseBlobBatch("aaaa blob 60\nfirst\n\nbbbb blob 7\nsecond", 2)
+	assert.Error(t, err)
+	_, err = parseBlobBatch("aaaa missing", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioStates(t *testing.T) {
+	repo, err := scenario.Build(t.TempDir(), scenario.All...)
+	require.NoError(t, err)
+
+	g, err := New(repo.Dir)
+	require.NoError(t, err)
+
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+	local := make(map[string]GitBranch)
+	for _, b := range branches {
+		if !b.IsRemote {
+			local[b.Name] = b
+		}
+	}
+
+	assert.True(t, local[scenario.Merged].IsMerged)
+	assert.True(t, local[scenario.Stale].IsStale)
+	assert.False(t, local[scenario.Unmerged].IsMerged)
+	assert.NotEmpty(t, local[scenario.WorktreeCheckedOut].InUse)
+	assert.NotContains(t, local, scenario.RemoteOnly)
+
+	var missing []string
+	for _, b := range RemoteWithoutLocal(branches) {
+		missing = append(missing, b.Name)
+	}
+	assert.Equal(t, []string{scenario.RemoteOnly}, missing)
+
+	tracking, err := g.ListTracking()
+	require.NoError(t, err)
+	states := make(map[string]string)
+	for _, s := range tracking {
+		states[s.Branch] = s.State()
+	}
+	assert.Equal(t, "diverged", states[scenario.Diverged])
+	assert.Equal(t, "gone", states[scenario.Stale])
+	assert.Equal(t, "synced", states["main"])
+}
@@ -0,0 +1,277 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SharedLockFile is the lock taken in the common git directory of a shared
+// clone around every command that changes the repository
+const SharedLockFile = "git-branch-delete.lock"
+
+// staleLockAge is how old a lock must be before it's taken to be left over
+// from a run that was killed. Git commands changing the repository finish or
+// time out well within it.
+const staleLockAge = 10 * time.Minute
+
+// lockPollInterval is how often a held lock is retried
+const lockPollInterval = 100 * time.Millisecond
+
+// Sharing describes how a repository shares objects or access with others,
+// as on build agents that clone from a cache with --reference or --shared
+type Sharing struct {
+	// Alternates are the object directories the repository borrows objects
+	// from. Pruning them, e.g. with gc in the cache, corrupts this clone.
+	Alternates []string `json:"alternates,omitempty"`
+
+	// SharedRepository is core.sharedRepository, when it lets several
+	// users, and so concurrent jobs, write to the repository
+	SharedRepository string `json:"sharedRepository,omitempty"`
+
+	// PreciousObjects is set by extensions.preciousObjects, which marks a
+	// repository others borrow objects from
+	PreciousObjects bool `json:"preciousObjects,omitempty"`
+}
+
+// Shared reports whether any form of sharing was found
+func (s Sharing) Shared() bool {
+	return len(s.Alternates) > 0 || s.SharedRepository != "" || s.PreciousObjects
+}
+
+// String describes the sharing, e.g. "borrows objects from
+// /cache/repo.git/objects; core.sharedRepository=group"
+func (s Sharing) String() string {
+	var parts []string
+	if len(s.Alternates) > 0 {
+		parts = append(parts, "borrows objects from "+strings.Join(s.Alternates, ", "))
+	}
+	if s.SharedRepository != "" {
+		parts = append(parts, "core.sharedRepository="+s.SharedRepository)
+	}
+	if s.PreciousObjects {
+		parts = append(parts, "extensions.preciousObjects is set")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DetectSharing reports how the repository is shared
+func (g *Git) DetectSharing() (Sharing, error) {
+	var s Sharing
+
+	dir, err := g.CommonDir()
+	if err != nil {
+		return s, err
+	}
+	s.Alternates, err = readAlternates(filepath.Join(dir, "objects", "info", "alternates"))
+	if err != nil {
+		return s, err
+	}
+
+	// Fails when neither key is set
+	out, _ := g.execGitQuiet("config", "--get-regexp", `^(core\.sharedrepository|extensions\.preciousobjects)$`)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, hasValue := strings.Cut(line, " ")
+		switch strings.ToLower(key) {
+		case "core.sharedrepository":
+			if sharesRepository(value) {
+				s.SharedRepository = value
+			}
+		case "extensions.preciousobjects":
+			// A key without a value is true
+			s.PreciousObjects = !hasValue || isTrue(value)
+		}
+	}
+	return s, nil
+}
+
+// SetShared turns the safeguards for shared clones on or off. With them on,
+// git never runs garbage collection or automatic maintenance, which would
+// prune objects other clones borrow, and commands changing the repository
+// wait for SharedLockFile so concurrent runs don't interleave.
+func (g *Git) SetShared(shared bool) error {
+	if !shared {
+		g.sharedLock = ""
+		return nil
+	}
+	dir, err := g.CommonDir()
+	if err != nil {
+		return err
+	}
+	g.sharedLock = filepath.Join(dir, SharedLockFile)
+	return nil
+}
+
+// IsShared reports whether the shared clone safeguards are on
+func (g *Git) IsShared() bool {
+	return g.sharedLock != ""
+}
+
+// sharedConfig returns the git configuration entries turning garbage
+// collection and automatic maintenance off for shared clones, whatever the
+// repository's configuration says
+func (g *Git) sharedConfig() [][2]string {
+	if !g.IsShared() {
+		return nil
+	}
+	return [][2]string{{"gc.auto", "0"}, {"maintenance.auto", "false"}}
+}
+
+// HoldSharedLock takes the shared clone lock until the returned function is
+// called, so a whole run, from reading the branches to changing them,
+// doesn't interleave with another one. Commands changing the repository
+// don't take it one by one in the meantime. Without the safeguards on,
+// there is nothing to lock.
+func (g *Git) HoldSharedLock() (func(), error) {
+	release, err := g.lockShared()
+	if err != nil || !g.IsShared() {
+		return release, err
+	}
+	g.mu.Lock()
+	g.lockHeld = true
+	g.mu.Unlock()
+
+	// Keep the lock from looking left over however long the run takes
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(staleLockAge / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				_ = os.Chtimes(g.sharedLock, now, now)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+			g.mu.Lock()
+			g.lockHeld = false
+			g.mu.Unlock()
+			release()
+		})
+	}, nil
+}
+
+// lockShared waits up to the command timeout for the shared clone lock,
+// returning the function releasing it. Without the safeguards on, or while
+// HoldSharedLock holds it, there is nothing to lock.
+func (g *Git) lockShared() (func(), error) {
+	g.mu.Lock()
+	held := g.lockHeld
+	g.mu.Unlock()
+	if !g.IsShared() || held {
+		return func() {}, nil
+	}
+
+	host, _ := os.Hostname()
+	holder := fmt.Sprintf("pid %d on %s since %s", os.Getpid(), host, time.Now().UTC().Format(time.RFC3339Nano))
+	deadline := time.Now().Add(g.timeout)
+	for {
+		f, err := os.OpenFile(g.sharedLock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, _ = f.WriteString(holder + "\n")
+			f.Close()
+			return func() {
+				// Only remove the lock while it's still ours
+				if owner, err := os.ReadFile(g.sharedLock); err == nil && strings.TrimSpace(string(owner)) == holder {
+					os.Remove(g.sharedLock)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock shared clone: %w", err)
+		}
+
+		owner, _ := os.ReadFile(g.sharedLock)
+		if info, err := os.Stat(g.sharedLock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			// Left over from a run that was killed
+			g.takeOverLock(owner)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s, held by %s; remove it if no other git-branch-delete is running",
+				g.sharedLock, orUnknown(strings.TrimSpace(string(owner))))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// takeOverLock removes a left over lock whose content was owner. It's
+// renamed aside first, so of several runs taking it over at once only one
+// gets it; when what was renamed is no longer owner's lock, another run
+// took it over in the meantime and it's put back.
+func (g *Git) takeOverLock(owner []byte) {
+	aside := fmt.Sprintf("%s.%d.stale", g.sharedLock, os.Getpid())
+	if err := os.Rename(g.sharedLock, aside); err != nil {
+		return
+	}
+	if moved, err := os.ReadFile(aside); err == nil && !bytes.Equal(moved, owner) {
+		// Fails if yet another run locked it since; it holds the lock then
+		_ = os.Link(aside, g.sharedLock)
+	}
+	os.Remove(aside)
+}
+
+// readAlternates returns the object directories listed in an alternates
+// file, which may not exist
+func readAlternates(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alternates: %w", err)
+	}
+	defer f.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, scanner.Err()
+}
+
+// sharesRepository reports whether a core.sharedRepository value shares
+// the repository beyond the umask
+func sharesRepository(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "umask", "false", "no", "off", "0":
+		return false
+	}
+	return true
+}
+
+// isTrue reports whether a git config boolean is true
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+// orUnknown returns s, or "unknown" when s is empty
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSharing(t *testing.T) {
+	cache, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	out, err := exec.Command("git", "clone", "-q", "--shared", cache, clone).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	g, err := New(clone)
+	require.NoError(t, err)
+	s, err := g.DetectSharing()
+	require.NoError(t, err)
+	require.Len(t, s.Alternates, 1)
+	assert.True(t, strings.HasSuffix(s.Alternates[0], filepath.Join(".git", "objects")))
+	assert.True(t, s.Shared())
+	assert.Contains(t, s.String(), "borrows objects from")
+
+	g, err = New(cache)
+	require.NoError(t, err)
+	s, err = g.DetectSharing()
+	require.NoError(t, err)
+	assert.False(t, s.Shared())
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = cache
+		require.NoError(t, c.Run())
+	}
+	run("config", "core.sharedRepository", "umask")
+	s, err = g.DetectSharing()
+	require.NoError(t, err)
+	assert.False(t, s.Shared())
+
+	run("config", "core.sharedRepository", "group")
+	run("config", "extensions.preciousObjects", "true")
+	s, err = g.DetectSharing()
+	require.NoError(t, err)
+	assert.Equal(t, Sharing{SharedRepository: "group", PreciousObjects: true}, s)
+	assert.Equal(t, "core.sharedRepository=group; extensions.preciousObjects is set", s.String())
+}
+
+func TestSharedSafeguards(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	assert.Empty(t, g.sharedConfig())
+	require.NoError(t, g.SetShared(true))
+	assert.True(t, g.IsShared())
+	assert.Contains(t, g.commandEnv(nil), "GIT_CONFIG_KEY_0=gc.auto")
+
+	// The setting reaches git over the repository's own
+	c := exec.Command("git", "config", "gc.auto", "100")
+	c.Dir = dir
+	require.NoError(t, c.Run())
+	value, err := g.runGit(nil, "config", "gc.auto")
+	require.NoError(t, err)
+	assert.Equal(t, "0", value)
+
+	err = g.Remediate(RemedyGC)
+	assert.ErrorContains(t, err, "disabled in shared clones")
+
+	// The lock is released after each command
+	_, err = g.execGit("branch", "-d", "feature/test")
+	require.NoError(t, err)
+	assert.NoFileExists(t, g.sharedLock)
+
+	require.NoError(t, g.SetShared(false))
+	assert.False(t, g.IsShared())
+}
+
+func TestSharedLock(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	require.NoError(t, g.SetShared(true))
+	g.SetTimeout(300 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(g.sharedLock, []byte("pid 1 on ci-agent\n"), 0644))
+	_, err = g.execGit("branch", "-d", "feature/test")
+	assert.ErrorContains(t, err, "held by pid 1 on ci-agent")
+
+	// Reading doesn't wait for the lock
+	_, err = g.execGit("rev-parse", "--verify", "refs/heads/feature/test")
+	require.NoError(t, err)
+
+	// A lock left over from a killed run is taken over
+	old := time.Now().Add(-2 * staleLockAge)
+	require.NoError(t, os.Chtimes(g.sharedLock, old, old))
+	_, err = g.execGit("branch", "-d", "feature/test")
+	require.NoError(t, err)
+	assert.NoFileExists(t, g.sharedLock)
+}
+
+func TestHoldSharedLock(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	require.NoError(t, g.SetShared(true))
+	release, err := g.HoldSharedLock()
+	require.NoError(t, err)
+	assert.FileExists(t, g.sharedLock)
+
+	// Our own commands go ahead while it's held, and leave it held
+	_, err = g.execGit("branch", "-d", "feature/test")
+	require.NoError(t, err)
+	assert.FileExists(t, g.sharedLock)
+
+	// Another run waits for the end of this one
+	other, err := New(dir)
+	require.NoError(t, err)
+	require.NoError(t, other.SetShared(true))
+	other.SetTimeout(300 * time.Millisecond)
+	_, err = other.execGit("branch", "-D", "feature/test2")
+	assert.ErrorContains(t, err, fmt.Sprintf("held by pid %d", os.Getpid()))
+
+	release()
+	release()
+	assert.NoFileExists(t, g.sharedLock)
+	_, err = other.execGit("branch", "-D", "feature/test2")
+	require.NoError(t, err)
+}
+
+func TestTakeOverLock(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	require.NoError(t, g.SetShared(true))
+
+	// Another run took the left over lock over first; its lock stays
+	require.NoError(t, os.WriteFile(g.sharedLock, []byte("pid 2 on ci-agent\n"), 0644))
+	g.takeOverLock([]byte("pid 1 on ci-agent\n"))
+	owner, err := os.ReadFile(g.sharedLock)
+	require.NoError(t, err)
+	assert.Equal(t, "pid 2 on ci-agent\n", string(owner))
+
+	g.takeOverLock(owner)
+	assert.NoFileExists(t, g.sharedLock)
+	matches, _ := filepath.Glob(g.sharedLock + "*")
+	assert.Empty(t, matches)
+}
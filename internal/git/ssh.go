@@ -0,0 +1,121 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// remoteCommands are the git commands that contact a remote, named by their
+// first argument that isn't a flag
+var remoteCommands = map[string]bool{
+	"push":      true,
+	"fetch":     true,
+	"ls-remote": true,
+}
+
+// SetSSHCommands sets the SSH command used for each remote, by remote name,
+// e.g. "ssh -i ~/.ssh/id_work". Git runs it as GIT_SSH_COMMAND for commands
+// contacting that remote, taking precedence over core.sshCommand.
+func (g *Git) SetSSHCommands(commands map[string]string) {
+	g.sshCommands = commands
+}
+
+// sshEnv returns the environment entries selecting the configured SSH
+// command for the remote the git command args contact, if any
+func (g *Git) sshEnv(args []string) []string {
+	if len(g.sshCommands) == 0 {
+		return nil
+	}
+	if command := g.sshCommands[remoteOf(args)]; command != "" {
+		return []string{"GIT_SSH_COMMAND=" + command}
+	}
+	return nil
+}
+
+// customSSHCommand reports whether origin is contacted with an SSH command
+// other than plain ssh, which may select its own identity instead of the
+// agent's keys
+func (g *Git) customSSHCommand() bool {
+	if g.sshCommands["origin"] != "" || g.remoteAuth["origin"].SSHCommand != "" || os.Getenv("GIT_SSH_COMMAND") != "" || os.Getenv("GIT_SSH") != "" {
+		return true
+	}
+	command, _ := g.execGitQuiet("config", "--get", "core.sshCommand")
+	return command != ""
+}
+
+// remoteOf returns the remote a git command contacts, or "" when it doesn't
+// contact one by name
+func remoteOf(args []string) string {
+	if len(args) == 0 || !remoteCommands[args[0]] {
+		return ""
+	}
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// sshHostName resolves an SSH host alias to the host it connects to, as
+// configured in ~/.ssh/config. It's a variable so tests can fake ssh.
+var sshHostName = func(alias string) string {
+	if strings.HasPrefix(alias, "-") {
+		return alias
+	}
+	out, err := exec.Command("ssh", "-G", alias).Output()
+	if err != nil {
+		return alias
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if host, ok := strings.CutPrefix(scanner.Text(), "hostname "); ok && host != "" {
+			return host
+		}
+	}
+	return alias
+}
+
+// isSSHURL reports whether a remote URL is served over SSH, either as an
+// ssh:// URL or in scp-like syntax such as git@github.com:owner/repo.git
+func isSSHURL(remote string) bool {
+	if scheme, _, ok := strings.Cut(remote, "://"); ok {
+		return scheme == "ssh" || scheme == "git+ssh" || scheme == "ssh+git"
+	}
+	// scp-like syntax needs a colon before the first slash
+	colon := strings.Index(remote, ":")
+	return colon > 0 && !strings.Contains(remote[:colon], "/")
+}
+
+// resolveSSHAlias replaces an SSH host alias in a remote URL with the host
+// it stands for, so e.g. "work-github:owner/repo.git" is recognized as a
+// github.com remote. Other URLs are returned unchanged.
+func resolveSSHAlias(remote string) string {
+	if !isSSHURL(remote) {
+		return remote
+	}
+
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Hostname() == "" {
+			return remote
+		}
+		host := sshHostName(u.Hostname())
+		if port := u.Port(); port != "" {
+			host += ":" + port
+		}
+		u.Host = host
+		return u.String()
+	}
+
+	hostPart, path, _ := strings.Cut(remote, ":")
+	user := ""
+	if i := strings.LastIndex(hostPart, "@"); i >= 0 {
+		user, hostPart = hostPart[:i+1], hostPart[i+1:]
+	}
+	return user + sshHostName(hostPart) + ":" + path
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHEnv(t *testing.T) {
+	g := &Git{}
+	g.SetSSHCommands(map[string]string{"origin": "ssh -i ~/.ssh/id_work"})
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"push", []string{"push", "origin", "--delete", "x"}, []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}},
+		{"flags before remote", []string{"push", "--dry-run", "origin", "--delete", "x"}, []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}},
+		{"ls-remote", []string{"ls-remote", "--symref", "origin", "HEAD"}, []string{"GIT_SSH_COMMAND=ssh -i ~/.ssh/id_work"}},
+		{"other remote", []string{"push", "upstream", "--delete", "x"}, nil},
+		{"local command", []string{"branch", "-D", "origin"}, nil},
+		{"no remote", []string{"fetch", "--all"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, g.sshEnv(tt.args))
+		})
+	}
+}
+
+func TestResolveSSHAlias(t *testing.T) {
+	lookup := sshHostName
+	defer func() { sshHostName = lookup }()
+	sshHostName = func(alias string) string {
+		if alias == "work-github" {
+			return "github.com"
+		}
+		return alias
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		want   string
+	}{
+		{"scp-like alias", "work-github:acme/app.git", "github.com:acme/app.git"},
+		{"scp-like alias with user", "git@work-github:acme/app.git", "git@github.com:acme/app.git"},
+		{"ssh URL alias", "ssh://git@work-github:2222/acme/app.git", "ssh://git@github.com:2222/acme/app.git"},
+		{"not an alias", "git@gitlab.com:acme/app.git", "git@gitlab.com:acme/app.git"},
+		{"https", "https://work-github/acme/app.git", "https://work-github/acme/app.git"},
+		{"local path", "/srv/git/app.git", "/srv/git/app.git"},
+		{"relative path with colon", "./a:b/app.git", "./a:b/app.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveSSHAlias(tt.remote))
+		})
+	}
+}
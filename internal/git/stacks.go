@@ -0,0 +1,158 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Stacked-diff tools whose branches StackedBranches finds
+const (
+	StackGraphite   = "graphite"
+	StackGhstack    = "ghstack"
+	StackBranchless = "git-branchless"
+)
+
+// ghstackBranch matches the branches ghstack pushes for each commit of a
+// stack, e.g. gh/alice/12/head
+var ghstackBranch = regexp.MustCompile(`^gh/[^/]+/[0-9]+/(base|head|orig|next)$`)
+
+// Stacks maps branch names to the stacked-diff tool tracking them
+type Stacks map[string]string
+
+// Tool returns the stacked-diff tool tracking a branch, if any. ghstack
+// branches are recognized by name, since ghstack keeps no local metadata
+// about them.
+func (s Stacks) Tool(name string) string {
+	if tool, ok := s[name]; ok {
+		return tool
+	}
+	if ghstackBranch.MatchString(name) {
+		return StackGhstack
+	}
+	return ""
+}
+
+// StackedBranches returns the branches tracked by stacked-diff tools.
+// Deleting them behind the tool's back corrupts the stack state. Tools that
+// aren't in use find nothing.
+func (g *Git) StackedBranches() (Stacks, error) {
+	stacked := make(Stacks)
+
+	graphite, err := g.graphiteBranches()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range graphite {
+		stacked[name] = StackGraphite
+	}
+
+	branchless, err := g.branchlessBranches()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range branchless {
+		stacked[name] = StackBranchless
+	}
+
+	return stacked, nil
+}
+
+// graphiteBranches returns the branches Graphite tracks, from its metadata
+// refs and, for older versions, its cache file
+func (g *Git) graphiteBranches() ([]string, error) {
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)", "refs/branch-metadata")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Graphite metadata: %w", err)
+	}
+
+	var names []string
+	for _, ref := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(ref, "refs/branch-metadata/"); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+
+	dir, err := g.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".graphite_cache_persist"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Graphite cache: %w", err)
+	}
+	cached, err := parseGraphiteCache(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(names, cached...), nil
+}
+
+// parseGraphiteCache returns the branches in Graphite's cache, which holds
+// [name, metadata] pairs under "branches"
+func parseGraphiteCache(data []byte) ([]string, error) {
+	var cache struct {
+		Branches [][]json.RawMessage `json:"branches"`
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to decode Graphite cache: %w", err)
+	}
+
+	var names []string
+	for _, entry := range cache.Branches {
+		if len(entry) == 0 {
+			continue
+		}
+		var name string
+		if err := json.Unmarshal(entry[0], &name); err == nil && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// branchlessBranches returns the local branches whose tips git-branchless
+// keeps in its commit graph. It marks those commits with refs under
+// refs/branchless/ so they aren't garbage collected.
+func (g *Git) branchlessBranches() ([]string, error) {
+	dir, err := g.GitPath("branchless")
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return nil, nil
+	}
+
+	out, err := g.execGit("for-each-ref", "--format", "%(objectname)", "refs/branchless")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list git-branchless refs: %w", err)
+	}
+	tracked := make(map[string]bool)
+	for _, hash := range strings.Fields(out) {
+		tracked[hash] = true
+	}
+	if len(tracked) == 0 {
+		return nil, nil
+	}
+
+	out, err = g.execGit("for-each-ref", "--format", "%(refname:short)%09%(objectname)", "refs/heads")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		name, hash, ok := strings.Cut(line, "\t")
+		if ok && tracked[hash] {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStacksTool(t *testing.T) {
+	stacks := Stacks{"feature/a": StackGraphite}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"feature/a", StackGraphite},
+		{"gh/alice/12/head", StackGhstack},
+		{"gh/alice/12/base", StackGhstack},
+		{"gh/alice/head", ""},
+		{"feature/b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stacks.Tool(tt.name))
+		})
+	}
+}
+
+func TestParseGraphiteCache(t *testing.T) {
+	names, err := parseGraphiteCache([]byte(`{"sha":"abc","branches":[["main",{"validationResult":"TRUNK"}],["feature/a",{"parentBranchName":"main"}],[]]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main", "feature/a"}, names)
+
+	_, err = parseGraphiteCache([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestStackedBranches(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	stacks, err := g.StackedBranches()
+	require.NoError(t, err)
+	assert.Empty(t, stacks)
+
+	// Graphite keeps a metadata blob per branch
+	blob := run("hash-object", "-w", "--stdin")
+	run("update-ref", "refs/branch-metadata/feature/test", blob)
+
+	// Older Graphite versions keep a cache file instead
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", ".graphite_cache_persist"),
+		[]byte(`{"branches":[["feature/test2",{"parentBranchName":"main"}]]}`), 0644))
+
+	// git-branchless keeps the commits of its graph alive with refs
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git", "branchless"), 0755))
+	run("checkout", "-q", "-b", "draft")
+	run("commit", "-q", "--allow-empty", "-m", "Draft")
+	head := run("rev-parse", "HEAD")
+	run("checkout", "-q", "main")
+	run("update-ref", "refs/branchless/"+head, head)
+
+	stacks, err = g.StackedBranches()
+	require.NoError(t, err)
+	assert.Equal(t, Stacks{"feature/test": StackGraphite, "feature/test2": StackGraphite, "draft": StackBranchless}, stacks)
+}
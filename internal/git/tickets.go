@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// trailerKeyPattern matches the trailer keys WithTickets accepts
+var trailerKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// Ticket is an issue tracker ticket a trailer of a branch's tip commit
+// names, e.g. "Closes: PROJ-123"
+type Ticket struct {
+	ID     string `json:"id"`
+	Status string `json:"status,omitempty"` // From the tracker, when looked up
+	Done   bool   `json:"done,omitempty"`   // Status is a done status
+}
+
+// TicketIDs returns the IDs of b's tickets
+func (b GitBranch) TicketIDs() []string {
+	ids := make([]string, len(b.Tickets))
+	for i, t := range b.Tickets {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// TicketsDone reports whether b names tickets and every one is done
+func (b GitBranch) TicketsDone() bool {
+	if len(b.Tickets) == 0 {
+		return false
+	}
+	for _, t := range b.Tickets {
+		if !t.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTrailerKey reports whether key can name a commit trailer
+func ValidateTrailerKey(key string) error {
+	if !trailerKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid trailer key %q: use letters, digits and '-'", key)
+	}
+	return nil
+}
+
+// WithTickets sets Tickets on the local branches whose tip commit has
+// trailers with one of keys, e.g. "Closes" for "Closes: PROJ-123". A
+// trailer may name several tickets separated by commas or spaces.
+func (g *Git) WithTickets(branches []GitBranch, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	format := "%(refname)"
+	for _, key := range keys {
+		if err := ValidateTrailerKey(key); err != nil {
+			return err
+		}
+		format += "%09%(trailers:key=" + key + ",valueonly,separator=%x2C)"
+	}
+	out, err := g.execGit("for-each-ref", "--format", format, "refs/heads")
+	if err != nil {
+		return fmt.Errorf("failed to read ticket trailers: %w", err)
+	}
+
+	tickets := make(map[string][]string)
+	for _, line := range strings.Split(out, "\n") {
+		ref, values, ok := strings.Cut(line, "\t")
+		if ok {
+			tickets[ref] = parseTicketIDs(values)
+		}
+	}
+
+	for i := range branches {
+		b := &branches[i]
+		b.Tickets = nil
+		if b.IsRemote {
+			continue
+		}
+		for _, id := range tickets[b.Reference] {
+			b.Tickets = append(b.Tickets, Ticket{ID: id})
+		}
+	}
+	return nil
+}
+
+// parseTicketIDs splits trailer values into ticket IDs, in order and
+// without duplicates
+func parseTicketIDs(values string) []string {
+	var ids []string
+	for _, id := range strings.FieldsFunc(values, func(r rune) bool {
+		return r == ',' || r == '\t' || r == ' '
+	}) {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ticketsReason describes a branch whose tickets are done, e.g. "tickets
+// PROJ-1, PROJ-2 done"
+func ticketsReason(b GitBranch) string {
+	noun := "ticket"
+	if len(b.Tickets) > 1 {
+		noun = "tickets"
+	}
+	return noun + " " + strings.Join(b.TicketIDs(), ", ") + " done"
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTicketIDs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "PROJ-1", want: []string{"PROJ-1"}},
+		{in: "PROJ-1, PROJ-2", want: []string{"PROJ-1", "PROJ-2"}},
+		{in: "PROJ-1 PROJ-2,PROJ-1", want: []string{"PROJ-1", "PROJ-2"}},
+		{in: " , ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTicketIDs(tt.in))
+		})
+	}
+}
+
+func TestWithTickets(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("checkout", "-q", "-b", "tickets/one")
+	run("commit", "-q", "--allow-empty", "-m", "fix", "-m", "Closes: PROJ-1")
+	run("checkout", "-q", "-b", "tickets/several", "main")
+	run("commit", "-q", "--allow-empty", "-m", "fix", "-m", "Closes: PROJ-2, PROJ-3\nRefs: OPS-9\nSigned-off-by: Test User <test@example.com>")
+	run("checkout", "-q", "-b", "tickets/body", "main")
+	run("commit", "-q", "--allow-empty", "-m", "fix", "-m", "Closes: PROJ-4 is mentioned in the body", "-m", "Signed-off-by: Test User <test@example.com>")
+	run("checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+	require.NoError(t, g.WithTickets(branches, []string{"Closes", "Refs"}))
+
+	tickets := make(map[string][]string)
+	for _, b := range branches {
+		tickets[b.Name] = b.TicketIDs()
+	}
+	assert.Equal(t, []string{"PROJ-1"}, tickets["tickets/one"])
+	assert.Equal(t, []string{"PROJ-2", "PROJ-3", "OPS-9"}, tickets["tickets/several"])
+	assert.Empty(t, tickets["tickets/body"])
+	assert.Empty(t, tickets["feature/test"])
+
+	assert.Error(t, g.WithTickets(branches, []string{"Closes:"}))
+}
+
+func TestTicketsDone(t *testing.T) {
+	b := GitBranch{Name: "fix"}
+	assert.False(t, b.TicketsDone())
+
+	b.Tickets = []Ticket{{ID: "PROJ-1", Status: "Done", Done: true}, {ID: "PROJ-2", Status: "In Progress"}}
+	assert.False(t, b.TicketsDone())
+
+	b.Tickets[1].Done = true
+	assert.True(t, b.TicketsDone())
+
+	g := &Git{}
+	assert.Equal(t, "tickets PROJ-1, PROJ-2 done", g.DeletableReason(b, time.Now()))
+}
@@ -0,0 +1,21 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// BranchTouches reports whether any commit reachable from ref but not from
+// base modifies path
+func (g *Git) BranchTouches(ref, base, path string) (bool, error) {
+	out, err := g.execGit("rev-list", "--count", ref, "--not", base, "--", path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check changes to %s: %w", path, err)
+	}
+
+	count, err := strconv.Atoi(out)
+	if err != nil {
+		return false, fmt.Errorf("invalid commit count %q: %w", out, err)
+	}
+	return count > 0, nil
+}
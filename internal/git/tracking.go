@@ -0,0 +1,168 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TrackingStatus describes a local branch's relationship with its upstream
+type TrackingStatus struct {
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream,omitempty"` // Empty when the branch has no upstream
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	Gone     bool   `json:"gone"` // Upstream is configured but no longer exists
+}
+
+// State summarizes the tracking status as a single word
+func (t TrackingStatus) State() string {
+	switch {
+	case t.Upstream == "":
+		return "untracked"
+	case t.Gone:
+		return "gone"
+	case t.Ahead > 0 && t.Behind > 0:
+		return "diverged"
+	case t.Ahead > 0:
+		return "ahead"
+	case t.Behind > 0:
+		return "behind"
+	default:
+		return "synced"
+	}
+}
+
+// ListTracking returns the upstream relationship of every local branch
+func (g *Git) ListTracking() ([]TrackingStatus, error) {
+	patterns := g.refPatterns("refs/heads")
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"for-each-ref", "--format", "%(refname:short)%09%(upstream:short)%09%(upstream:track,nobracket)"}, patterns...)
+	out, err := g.execGit(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracking branches: %w", err)
+	}
+
+	var statuses []TrackingStatus
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		status, err := parseTrackingLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if !g.allowsRef("refs/heads/" + status.Branch) {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// checkPushed fails with ErrUnpushedCommits when the local branch is ahead
+// of its upstream. Branches without an upstream, or whose upstream is gone,
+// can't be compared and pass.
+func (g *Git) checkPushed(name string) error {
+	status, err := g.trackingStatus(name)
+	if err != nil {
+		return err
+	}
+	if status.Ahead > 0 {
+		return newUnpushedCommitsError(status)
+	}
+	return nil
+}
+
+// trackingStatus returns the upstream relationship of one local branch. A
+// missing branch has an empty status.
+func (g *Git) trackingStatus(name string) (TrackingStatus, error) {
+	return g.trackingStatusContext(context.Background(), name)
+}
+
+// trackingStatusContext is trackingStatus giving up once ctx is done
+func (g *Git) trackingStatusContext(ctx context.Context, name string) (TrackingStatus, error) {
+	out, err := g.execGitContext(ctx, "for-each-ref", "--format", "%(refname:short)%09%(upstream:short)%09%(upstream:track,nobracket)", "refs/heads/"+name)
+	if err != nil {
+		return TrackingStatus{}, fmt.Errorf("failed to check upstream of %s: %w", name, err)
+	}
+	if out == "" {
+		return TrackingStatus{}, nil
+	}
+	return parseTrackingLine(out)
+}
+
+// parseTrackingLine parses "<branch>\t<upstream>\t<track>" where track is
+// e.g. "ahead 1, behind 2" or "gone"
+func parseTrackingLine(line string) (TrackingStatus, error) {
+	parts := strings.Split(line, "\t")
+	if parts[0] == "" {
+		return TrackingStatus{}, fmt.Errorf("invalid tracking line format: %s", line)
+	}
+
+	status := TrackingStatus{Branch: parts[0]}
+	if len(parts) > 1 {
+		status.Upstream = parts[1]
+	}
+	if len(parts) < 3 {
+		return status, nil
+	}
+
+	track := strings.TrimSpace(parts[2])
+	if track == "gone" {
+		status.Gone = true
+		return status, nil
+	}
+
+	for _, field := range strings.Split(track, ",") {
+		kv := strings.Fields(field)
+		if len(kv) != 2 {
+			continue
+		}
+		n, err := strconv.Atoi(kv[1])
+		if err != nil {
+			return TrackingStatus{}, fmt.Errorf("invalid tracking count in %q: %w", line, err)
+		}
+		switch kv[0] {
+		case "ahead":
+			status.Ahead = n
+		case "behind":
+			status.Behind = n
+		}
+	}
+
+	return status, nil
+}
+
+// RemoteWithoutLocal returns the remote branches among branches that no
+// local branch among them shares a name with or tracks, such as teammates'
+// leftovers. Cleaning those up only involves the remote.
+func RemoteWithoutLocal(branches []GitBranch) []GitBranch {
+	local := make(map[string]bool)
+	for _, b := range branches {
+		if b.IsRemote {
+			continue
+		}
+		local[b.Name] = true
+		if b.TrackingBranch != "" {
+			local["refs/remotes/"+b.TrackingBranch] = true
+		}
+	}
+
+	var missing []GitBranch
+	for _, b := range branches {
+		if !b.IsRemote || local[b.Reference] {
+			continue
+		}
+		// Other remotes' branch names keep the remote, e.g. upstream/x
+		_, name, _ := strings.Cut(strings.TrimPrefix(b.Reference, "refs/remotes/"), "/")
+		if !local[name] {
+			missing = append(missing, b)
+		}
+	}
+	return missing
+}
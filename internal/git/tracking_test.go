@@ -0,0 +1,127 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrackingLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		want      TrackingStatus
+		wantState string
+		wantErr   bool
+	}{
+		{
+			name:      "untracked",
+			line:      "feature/x",
+			want:      TrackingStatus{Branch: "feature/x"},
+			wantState: "untracked",
+		},
+		{
+			name:      "synced",
+			line:      "main\torigin/main\t",
+			want:      TrackingStatus{Branch: "main", Upstream: "origin/main"},
+			wantState: "synced",
+		},
+		{
+			name:      "ahead",
+			line:      "main\torigin/main\tahead 3",
+			want:      TrackingStatus{Branch: "main", Upstream: "origin/main", Ahead: 3},
+			wantState: "ahead",
+		},
+		{
+			name:      "diverged",
+			line:      "dev\torigin/dev\tahead 1, behind 2",
+			want:      TrackingStatus{Branch: "dev", Upstream: "origin/dev", Ahead: 1, Behind: 2},
+			wantState: "diverged",
+		},
+		{
+			name:      "gone",
+			line:      "old\torigin/old\tgone",
+			want:      TrackingStatus{Branch: "old", Upstream: "origin/old", Gone: true},
+			wantState: "gone",
+		},
+		{
+			name:    "empty branch",
+			line:    "\torigin/main\t",
+			wantErr: true,
+		},
+		{
+			name:    "bad count",
+			line:    "main\torigin/main\tahead x",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTrackingLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantState, got.State())
+		})
+	}
+}
+
+func TestDeleteBranchUnpushedCommits(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// feature/local is merged into main but its upstream never got the commit
+	run("branch", "base")
+	run("checkout", "-q", "-b", "feature/local", "--track", "base")
+	run("commit", "-q", "--allow-empty", "-m", "local only")
+	run("checkout", "-q", "main")
+	run("merge", "-q", "--ff-only", "feature/local")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	g.SetMergedTargets([]string{"main"})
+
+	err = g.DeleteBranch("feature/local", false, false)
+	var unpushed *ErrUnpushedCommits
+	require.ErrorAs(t, err, &unpushed)
+	assert.Equal(t, "base", unpushed.Upstream)
+	assert.Equal(t, 1, unpushed.Ahead)
+
+	err = g.DeleteBranchesAtomic([]string{"feature/local", "feature/test"}, false)
+	require.ErrorAs(t, err, &unpushed)
+
+	require.NoError(t, g.DeleteBranch("feature/local", true, false))
+}
+
+func TestRemoteWithoutLocal(t *testing.T) {
+	branches := []GitBranch{
+		{Name: "main", Reference: "refs/heads/main", TrackingBranch: "origin/main"},
+		{Name: "mine", Reference: "refs/heads/mine", TrackingBranch: "origin/renamed"},
+		{Name: "wip", Reference: "refs/heads/wip"},
+		{Name: "main", Reference: "refs/remotes/origin/main", IsRemote: true},
+		{Name: "renamed", Reference: "refs/remotes/origin/renamed", IsRemote: true},
+		{Name: "wip", Reference: "refs/remotes/origin/wip", IsRemote: true},
+		{Name: "teammate/old", Reference: "refs/remotes/origin/teammate/old", IsRemote: true},
+		{Name: "upstream/main", Reference: "refs/remotes/upstream/main", IsRemote: true},
+		{Name: "upstream/release", Reference: "refs/remotes/upstream/release", IsRemote: true},
+	}
+
+	var names []string
+	for _, b := range RemoteWithoutLocal(branches) {
+		names = append(names, b.Name)
+	}
+	assert.Equal(t, []string{"teammate/old", "upstream/release"}, names)
+}
@@ -0,0 +1,161 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// refTransactionVersion is the first git version whose update-ref --stdin
+// supports the start/prepare/commit transaction commands
+var refTransactionVersion = []int{2, 27}
+
+// Version returns git's [major, minor] version
+func (g *Git) Version() ([]int, error) {
+	g.mu.Lock()
+	cached := g.version
+	g.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	out, err := g.execGit("version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git version: %w", err)
+	}
+	v, err := parseVersion(out)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.version = v
+	g.mu.Unlock()
+	return v, nil
+}
+
+// SupportsRefTransactions reports whether DeleteBranchesAtomic can be used
+func (g *Git) SupportsRefTransactions() bool {
+	v, err := g.Version()
+	if err != nil {
+		return false
+	}
+	return v[0] > refTransactionVersion[0] ||
+		(v[0] == refTransactionVersion[0] && v[1] >= refTransactionVersion[1])
+}
+
+// DeleteBranchesAtomic deletes local branches in a single update-ref
+// transaction: either every branch is deleted or none is. Like `git branch
+// -d`, branches must be fully merged into their upstream or HEAD unless
+// force is set, and branches checked out here or in another worktree can't
+// be deleted. Requires SupportsRefTransactions; callers fall back to
+// DeleteBranch otherwise.
+func (g *Git) DeleteBranchesAtomic(names []string, force bool) error {
+	current := g.checkedOutBranch()
+	out, err := g.execGit("worktree", "list", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	worktrees := parseWorktrees(out)
+
+	var script strings.Builder
+	script.WriteString("start\n")
+	for _, name := range names {
+		if g.IsProtected(name, false) {
+			return newProtectedBranchError(name, g.protection.Pinned[name])
+		}
+		if name == current {
+			return newCurrentBranchError(name)
+		}
+		if path, ok := worktrees[name]; ok {
+			return newBranchInUseError(name, "checked out in worktree "+path)
+		}
+
+		oid, err := g.ResolveRef("refs/heads/" + name)
+		if err != nil {
+			return newBranchNotFoundError(name, false)
+		}
+
+		if !force {
+			if err := g.checkPushed(name); err != nil {
+				return err
+			}
+			merged, err := g.mergedIntoUpstreamOrHead(name)
+			if err != nil {
+				return err
+			}
+			if !merged {
+				return newUnmergedBranchError(name)
+			}
+		}
+
+		// The old value makes the transaction fail if the branch moved
+		fmt.Fprintf(&script, "delete refs/heads/%s %s\n", name, oid)
+	}
+	script.WriteString("prepare\ncommit\n")
+
+	if _, err := g.execGitInput(strings.NewReader(script.String()), "update-ref", "--stdin"); err != nil {
+		return fmt.Errorf("failed to delete branches: %w", err)
+	}
+
+	// update-ref leaves the branch configuration behind, unlike git branch.
+	// The branches are gone either way, so failures here are
+	// ErrBranchConfig.
+	var errs []error
+	for _, name := range names {
+		if err := g.removeBranchConfig(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removeBranchConfig removes the branch.<name> configuration section of a
+// deleted branch, its upstream among others. Branches without one are fine.
+func (g *Git) removeBranchConfig(name string) error {
+	_, err := g.execGit("config", "--remove-section", "branch."+name)
+	if err != nil && !strings.Contains(err.Error(), "no such section") {
+		return &ErrBranchConfig{Name: name, Err: err}
+	}
+	return nil
+}
+
+// mergedIntoUpstreamOrHead applies `git branch -d`'s merge check: a branch
+// must be merged into its upstream, or into HEAD when it has none. Branches
+// merged into a configured merge target pass as well.
+func (g *Git) mergedIntoUpstreamOrHead(name string) (bool, error) {
+	target := "HEAD"
+	upstream, err := g.execGit("for-each-ref", "--format", "%(upstream:short)", "refs/heads/"+name)
+	if err == nil && upstream != "" {
+		target = upstream
+	}
+
+	merged, err := g.MergedBranches(target, false)
+	if err != nil {
+		return false, err
+	}
+	return merged[name] || g.mergedIntoTarget(name), nil
+}
+
+// parseVersion parses `git version` output such as "git version 2.39.5" or
+// "git version 2.37.1 (Apple Git-137.1)" into [major, minor]
+func parseVersion(out string) ([]int, error) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("unexpected git version output: %q", out)
+	}
+
+	parts := strings.SplitN(fields[2], ".", 3)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("unexpected git version: %q", fields[2])
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git version: %q", fields[2])
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git version: %q", fields[2])
+	}
+	return []int{major, minor}, nil
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    []int
+		wantErr bool
+	}{
+		{"git version 2.39.5", []int{2, 39}, false},
+		{"git version 2.37.1 (Apple Git-137.1)", []int{2, 37}, false},
+		{"git version 2.45.2.windows.1", []int{2, 45}, false},
+		{"git version", nil, true},
+		{"git version two", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			got, err := parseVersion(tt.out)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeleteBranchesAtomic(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("checkout", "-b", "feature/unmerged")
+	run("commit", "--allow-empty", "-m", "unmerged")
+	run("checkout", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	if !g.SupportsRefTransactions() {
+		t.Skip("git is too old for update-ref transactions")
+	}
+
+	branchNames := func() []string {
+		branches, err := g.ListLocalBranches()
+		require.NoError(t, err)
+		var names []string
+		for _, b := range branches {
+			names = append(names, b.Name)
+		}
+		return names
+	}
+
+	// One unmerged branch aborts the whole deletion
+	err = g.DeleteBranchesAtomic([]string{"feature/test", "feature/unmerged"}, false)
+	var unmerged *ErrUnmergedBranch
+	assert.ErrorAs(t, err, &unmerged)
+	assert.ElementsMatch(t, []string{"main", "feature/test", "feature/test2", "feature/unmerged"}, branchNames())
+
+	// The checked out branch can't be deleted
+	err = g.DeleteBranchesAtomic([]string{"feature/test", "main"}, true)
+	var current *ErrCurrentBranch
+	assert.ErrorAs(t, err, &current)
+
+	var notFound *ErrBranchNotFound
+	assert.ErrorAs(t, g.DeleteBranchesAtomic([]string{"feature/test", "missing"}, true), &notFound)
+
+	// Neither can one checked out in another worktree
+	worktree := filepath.Join(t.TempDir(), "wt")
+	run("worktree", "add", "-q", worktree, "feature/test2")
+	var inUse *ErrBranchInUse
+	assert.ErrorAs(t, g.DeleteBranchesAtomic([]string{"feature/test", "feature/test2"}, true), &inUse)
+	assert.Contains(t, branchNames(), "feature/test")
+	run("worktree", "remove", worktree)
+
+	run("config", "branch.feature/test.remote", "origin")
+	run("config", "branch.feature/test.merge", "refs/heads/feature/test")
+	run("config", "branch.feature/test.description", "notes")
+	require.NoError(t, g.DeleteBranchesAtomic([]string{"feature/test", "feature/test2", "feature/unmerged"}, true))
+	assert.Equal(t, []string{"main"}, branchNames())
+
+	// The branch configuration goes with the branch
+	c := exec.Command("git", "config", "--get-regexp", `^branch\.feature/test\.`)
+	c.Dir = dir
+	out, _ := c.Output()
+	assert.Empty(t, string(out))
+}
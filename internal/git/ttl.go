@@ -0,0 +1,148 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TTLKey is the key a branch declares its time to live with, as a
+// "GBD-TTL: 30d" line in its description or a trailer of its tip commit
+const TTLKey = "GBD-TTL"
+
+// TTL sources
+const (
+	TTLFromDescription = "description"
+	TTLFromTrailer     = "trailer"
+)
+
+// BranchTTL is the time to live a branch declared. It counts from the tip
+// commit, so new commits keep the branch alive.
+type BranchTTL struct {
+	TTL     time.Duration
+	Value   string    // As written, e.g. "30d"
+	Source  string    // TTLFromDescription or TTLFromTrailer
+	Expires time.Time // Committer date of the tip commit plus TTL
+}
+
+// Expired reports whether the TTL ran out before now
+func (t *BranchTTL) Expired(now time.Time) bool {
+	return t != nil && !t.Expires.After(now)
+}
+
+// ParseTTL parses a TTL of a whole number of hours, days or weeks, e.g.
+// "12h", "30d" or "2w"
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid TTL %q: want a number and a unit (h, d or w), e.g. 30d", s)
+	}
+
+	var unit time.Duration
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = 24 * time.Hour
+	case "w":
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid TTL %q: unit must be h, d or w", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid TTL %q: want a positive whole number before the unit", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// WithTTLs sets TTL on the local branches that declare one. A TTL in the
+// branch description wins over one in the tip commit's trailers, since it
+// can be changed without rewriting the branch. Values that don't parse are
+// ignored, so a typo never makes a branch deletable.
+func (g *Git) WithTTLs(branches []GitBranch) error {
+	out, err := g.execGit("for-each-ref", "--format", "%(refname)%09%(committerdate:unix)%09%(trailers:key="+TTLKey+",valueonly,separator=%x2C)", "refs/heads")
+	if err != nil {
+		return fmt.Errorf("failed to read branch TTLs: %w", err)
+	}
+
+	type tip struct {
+		date    time.Time
+		trailer string
+	}
+	tips := make(map[string]tip)
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		unix, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		// With several trailers, the last one is the latest word
+		trailers := strings.Split(parts[2], ",")
+		tips[parts[0]] = tip{date: time.Unix(unix, 0), trailer: strings.TrimSpace(trailers[len(trailers)-1])}
+	}
+	descriptions := g.branchDescriptions()
+
+	for i := range branches {
+		b := &branches[i]
+		t, ok := tips[b.Reference]
+		if b.IsRemote || !ok {
+			continue
+		}
+		value, source := descriptionTTL(descriptions[b.Name]), TTLFromDescription
+		if value == "" {
+			value, source = t.trailer, TTLFromTrailer
+		}
+		if value == "" {
+			continue
+		}
+		ttl, err := ParseTTL(value)
+		if err != nil {
+			continue
+		}
+		b.TTL = &BranchTTL{TTL: ttl, Value: value, Source: source, Expires: t.date.Add(ttl)}
+	}
+	return nil
+}
+
+// branchDescriptions returns the descriptions set with 'git branch
+// --edit-description', keyed by branch name
+func (g *Git) branchDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	// Fails with nothing to show, which just means no descriptions
+	out, err := g.execGitQuiet("config", "-z", "--get-regexp", `^branch\..*\.description$`)
+	if err != nil {
+		return descriptions
+	}
+	for _, entry := range strings.Split(out, "\x00") {
+		key, value, _ := strings.Cut(entry, "\n")
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), ".description")
+		if name != "" && name != key {
+			descriptions[name] = value
+		}
+	}
+	return descriptions
+}
+
+// descriptionTTL returns the value of the last "GBD-TTL: <value>" line of a
+// branch description
+func descriptionTTL(description string) string {
+	var value string
+	for _, line := range strings.Split(description, "\n") {
+		key, v, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), TTLKey) {
+			value = strings.TrimSpace(v)
+		}
+	}
+	return value
+}
+
+// ttlReason describes an expired TTL, e.g. "TTL of 30d from trailer
+// expired 2 days ago"
+func ttlReason(t *BranchTTL, now time.Time) string {
+	return fmt.Sprintf("TTL of %s from %s expired %s", t.Value, t.Source, daysAgo(t.Expires, now))
+}
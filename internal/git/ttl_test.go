@@ -0,0 +1,109 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "12h", want: 12 * time.Hour},
+		{in: "30d", want: 30 * 24 * time.Hour},
+		{in: " 2W ", want: 14 * 24 * time.Hour},
+		{in: "", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "30", wantErr: true},
+		{in: "0d", wantErr: true},
+		{in: "-1d", wantErr: true},
+		{in: "1.5d", wantErr: true},
+		{in: "30m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTTL(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDescriptionTTL(t *testing.T) {
+	assert.Equal(t, "30d", descriptionTTL("scratch work\nGBD-TTL: 30d"))
+	assert.Equal(t, "1w", descriptionTTL("gbd-ttl:2d\nGBD-TTL: 1w\n"))
+	assert.Empty(t, descriptionTTL("TTL: 30d"))
+	assert.Empty(t, descriptionTTL(""))
+}
+
+func TestWithTTLs(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(env []string, args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		c.Env = append(c.Environ(), env...)
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	old := []string{"GIT_COMMITTER_DATE=2020-01-01T00:00:00Z"}
+	run(nil, "checkout", "-q", "-b", "ttl/trailer")
+	run(old, "commit", "-q", "--allow-empty", "-m", "scratch", "-m", "GBD-TTL: 2w")
+	run(nil, "checkout", "-q", "-b", "ttl/description", "main")
+	run(old, "commit", "-q", "--allow-empty", "-m", "scratch", "-m", "GBD-TTL: 1d")
+	run(nil, "config", "branch.ttl/description.description", "Spike\nGBD-TTL: 30d")
+	run(nil, "checkout", "-q", "-b", "ttl/invalid", "main")
+	run(old, "commit", "-q", "--allow-empty", "-m", "scratch", "-m", "GBD-TTL: soon")
+	run(nil, "checkout", "-q", "-b", "ttl/fresh", "main")
+	run(nil, "commit", "-q", "--allow-empty", "-m", "scratch", "-m", "GBD-TTL: 30d")
+	run(nil, "checkout", "-q", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+	require.NoError(t, g.WithTTLs(branches))
+
+	ttls := make(map[string]*BranchTTL)
+	for _, b := range branches {
+		ttls[b.Name] = b.TTL
+	}
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	require.NotNil(t, ttls["ttl/trailer"])
+	assert.Equal(t, TTLFromTrailer, ttls["ttl/trailer"].Source)
+	assert.True(t, epoch.Add(14*24*time.Hour).Equal(ttls["ttl/trailer"].Expires))
+	assert.True(t, ttls["ttl/trailer"].Expired(now))
+
+	// The description wins over the trailer
+	require.NotNil(t, ttls["ttl/description"])
+	assert.Equal(t, TTLFromDescription, ttls["ttl/description"].Source)
+	assert.Equal(t, "30d", ttls["ttl/description"].Value)
+
+	require.NotNil(t, ttls["ttl/fresh"])
+	assert.False(t, ttls["ttl/fresh"].Expired(now))
+
+	assert.Nil(t, ttls["ttl/invalid"])
+	assert.Nil(t, ttls["feature/test"])
+	assert.False(t, ttls["feature/test"].Expired(now))
+
+	var trailer GitBranch
+	for _, b := range branches {
+		if b.Name == "ttl/trailer" {
+			trailer = b
+		}
+	}
+	assert.Contains(t, g.DeletableReason(trailer, now), "TTL of 2w from trailer expired")
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
+)
+
+// HasCommit reports whether the commit is still in the repository. Commits
+// of deleted branches stay until garbage collection prunes them.
+func (g *Git) HasCommit(commit string) bool {
+	_, err := g.execGitQuiet("cat-file", "-e", commit+"^{commit}")
+	return err == nil
+}
+
+// ResolveCommit returns the full hash of commit, given as a possibly
+// abbreviated hash
+func (g *Git) ResolveCommit(commit string) (string, error) {
+	if !leaseCommitPattern.MatchString(commit) {
+		return "", fmt.Errorf("invalid commit: %q", commit)
+	}
+	// A hash with ^{commit} is validated above, so it skips the argument
+	// checks, which don't know object names
+	out, err := g.execGitQuiet("rev-parse", "--verify", "--quiet", commit+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %s: %w", commit, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RestoreRemoteBranch recreates a branch on origin at commit, which must be
+// in the repository. Like RestoreBranch, it fails rather than overwrite a
+// branch of the same name that was pushed in the meantime.
+func (g *Git) RestoreRemoteBranch(name, commit string) error {
+	if err := pkggit.ValidateBranchName(name); err != nil {
+		return err
+	}
+	if !leaseCommitPattern.MatchString(commit) || !g.HasCommit(commit) {
+		return fmt.Errorf("commit %s of %s is no longer in the repository", shortHash(commit), name)
+	}
+
+	// An empty lease makes the push fail when the branch exists
+	if _, err := g.execGit("push", leaseArg(name, ""), "origin", commit+":refs/heads/"+name); err != nil {
+		if isAuthError(err.Error()) {
+			return g.handleAuthError(err.Error())
+		}
+		if isLeaseRejection(err.Error()) {
+			return fmt.Errorf("branch %s exists on origin again", name)
+		}
+		return fmt.Errorf("failed to restore remote branch %s: %w", name, err)
+	}
+
+	// Show it in listings without waiting for a fetch
+	_, _ = g.execGit("update-ref", "refs/remotes/origin/"+name, commit)
+	return nil
+}
@@ -0,0 +1,34 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreRemoteBranch(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Tracked("feature/a")
+	g, err := New(r.Dir)
+	require.NoError(t, err)
+
+	commit := strings.TrimSpace(r.Git("rev-parse", "origin/feature/a"))
+	require.NoError(t, g.DeleteBranch("feature/a", false, true))
+	require.False(t, r.HasRemoteBranch("feature/a"))
+
+	require.NoError(t, g.RestoreRemoteBranch("feature/a", commit))
+	assert.True(t, r.HasRemoteBranch("feature/a"))
+	tracking, err := g.ResolveRef("refs/remotes/origin/feature/a")
+	require.NoError(t, err)
+	assert.Equal(t, commit, tracking)
+
+	main := strings.TrimSpace(r.Git("rev-parse", "main"))
+	err = g.RestoreRemoteBranch("feature/a", main)
+	assert.ErrorContains(t, err, "exists on origin again", "never overwritten")
+
+	err = g.RestoreRemoteBranch("feature/b", strings.Repeat("0", 40))
+	assert.ErrorContains(t, err, "no longer in the repository")
+}
@@ -0,0 +1,307 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageLog is where the usage hooks record branch checkouts and merges,
+// relative to the git directory shared by all worktrees
+const UsageLog = "git-branch-delete/usage.log"
+
+// maxUsageLogSize is the size beyond which the usage log, appended to on
+// every checkout, is compacted to the latest record of each branch
+const maxUsageLogSize = 256 << 10
+
+// shellInterpreters are the shells that run the POSIX usage block
+var shellInterpreters = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "ash": true, "ksh": true, "mksh": true, "zsh": true,
+}
+
+// UsageHooks are the git hooks that record branch usage
+var UsageHooks = []string{"post-checkout", "post-merge"}
+
+// Markers delimiting the usage block in a hook, so it can live next to
+// other hook code and be removed again
+const (
+	hookBegin = "# >>> git-branch-delete usage >>>"
+	hookEnd   = "# <<< git-branch-delete usage <<<"
+)
+
+// hookBlock returns the usage block of a hook. It appends "<unix time>
+// <branch>" to the usage log; post-checkout only records branch checkouts,
+// not file checkouts.
+func hookBlock(hook string) string {
+	cond := "true"
+	if hook == "post-checkout" {
+		cond = `[ "$3" = 1 ]`
+	}
+	return hookBegin + `
+# Records when branches are used, for git-branch-delete's "last used" dates
+if ` + cond + `; then
+	gbd_branch=$(git symbolic-ref --quiet --short HEAD) &&
+	gbd_log="$(git rev-parse --git-common-dir)/` + UsageLog + `" &&
+	mkdir -p "$(dirname "$gbd_log")" &&
+	printf '%s %s\n' "$(date +%s)" "$gbd_branch" >> "$gbd_log" ||
+	true # Never stop the rest of the hook, even under set -e
+fi
+` + hookEnd + "\n"
+}
+
+// addHookBlock adds the usage block of hook to the hook script content,
+// which is empty for a new hook. The block goes right after the shebang:
+// hooks often end in exec or exit, which would skip anything after them.
+// Scripts that aren't shell scripts can't be extended and are an error.
+func addHookBlock(content, hook string) (string, error) {
+	if strings.Contains(content, hookBegin) {
+		content, _ = removeHookBlock(content)
+	}
+	if strings.TrimSpace(content) == "" {
+		return "#!/bin/sh\n" + hookBlock(hook), nil
+	}
+
+	if !isShellScript(content) {
+		return "", fmt.Errorf("existing %s hook is not a shell script; add the git-branch-delete block by hand", hook)
+	}
+	shebang, body, _ := strings.Cut(content, "\n")
+	return shebang + "\n" + hookBlock(hook) + body, nil
+}
+
+// isShellScript reports whether a script's shebang runs a POSIX shell, such
+// as "#!/bin/sh", "#!/bin/bash -e" or "#!/usr/bin/env bash". Shells with
+// another syntax, like fish or csh, aren't.
+func isShellScript(content string) bool {
+	shebang, _, _ := strings.Cut(content, "\n")
+	if !strings.HasPrefix(shebang, "#!") {
+		return false
+	}
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return false
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		// The command is the first argument that isn't an option or a
+		// variable assignment, e.g. "env -S bash -e"
+		interpreter = ""
+		for _, arg := range fields[1:] {
+			if !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+				interpreter = filepath.Base(arg)
+				break
+			}
+		}
+	}
+	return shellInterpreters[interpreter]
+}
+
+// removeHookBlock removes the usage block from the hook script content and
+// reports whether there was one
+func removeHookBlock(content string) (string, bool) {
+	start := strings.Index(content, hookBegin)
+	if start < 0 {
+		return content, false
+	}
+	end := strings.Index(content[start:], hookEnd)
+	if end < 0 {
+		return content, false
+	}
+	end += start + len(hookEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:], true
+}
+
+// hookPath returns the path of a hook, honoring core.hooksPath
+func (g *Git) hookPath(hook string) (string, error) {
+	dir, err := g.GitPath("hooks")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, hook), nil
+}
+
+// InstallUsageHooks adds the usage block to the UsageHooks, creating them
+// when needed and keeping what existing hooks already do
+func (g *Git) InstallUsageHooks() error {
+	for _, hook := range UsageHooks {
+		path, err := g.hookPath(hook)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s hook: %w", hook, err)
+		}
+		updated, err := addHookBlock(string(content), hook)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create hooks directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(updated), 0755); err != nil {
+			return fmt.Errorf("failed to write %s hook: %w", hook, err)
+		}
+		// WriteFile keeps the mode of existing files
+		if err := os.Chmod(path, 0755); err != nil {
+			return fmt.Errorf("failed to make %s hook executable: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// UninstallUsageHooks removes the usage block from the UsageHooks, deleting
+// hooks that are left without any code
+func (g *Git) UninstallUsageHooks() error {
+	for _, hook := range UsageHooks {
+		path, err := g.hookPath(hook)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s hook: %w", hook, err)
+		}
+		updated, ok := removeHookBlock(string(content))
+		if !ok {
+			continue
+		}
+
+		if strings.TrimSpace(updated) == "#!/bin/sh" || strings.TrimSpace(updated) == "" {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, []byte(updated), 0755)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update %s hook: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// UsageHooksInstalled reports, per hook in UsageHooks, whether its usage
+// block is installed
+func (g *Git) UsageHooksInstalled() (map[string]bool, error) {
+	installed := make(map[string]bool, len(UsageHooks))
+	for _, hook := range UsageHooks {
+		path, err := g.hookPath(hook)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s hook: %w", hook, err)
+		}
+		installed[hook] = strings.Contains(string(content), hookBegin)
+	}
+	return installed, nil
+}
+
+// BranchUsage returns when each branch was last checked out or merged into,
+// as recorded by the usage hooks. Branches without a record are missing.
+func (g *Git) BranchUsage() (map[string]time.Time, error) {
+	dir, err := g.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, UsageLog)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage log: %w", err)
+	}
+	defer f.Close()
+
+	used, err := parseUsageLog(f)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > maxUsageLogSize && g.ReadOnly() == "" {
+		// Failing only leaves the log growing until the next try
+		_ = compactUsageLog(path, used)
+	}
+	return used, nil
+}
+
+// compactUsageLog rewrites the usage log at path with only the latest record
+// of each branch. Records a hook appends while it is rewritten are lost,
+// which only makes those dates older.
+func compactUsageLog(path string, used map[string]time.Time) error {
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%d %s\n", used[name].Unix(), name)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to compact usage log: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to compact usage log: %w", err)
+	}
+	return nil
+}
+
+// WithUsage sets LastUsed on the local branches that have a usage record
+func (g *Git) WithUsage(branches []GitBranch) error {
+	used, err := g.BranchUsage()
+	if err != nil {
+		return err
+	}
+	for i := range branches {
+		if !branches[i].IsRemote {
+			branches[i].LastUsed = used[branches[i].Name]
+		}
+	}
+	return nil
+}
+
+// parseUsageLog reads "<unix time> <branch>" lines, keeping the latest time
+// of each branch. Malformed lines are skipped.
+func parseUsageLog(r io.Reader) (map[string]time.Time, error) {
+	used := make(map[string]time.Time)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		stamp, branch, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || branch == "" {
+			continue
+		}
+		secs, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		if t := time.Unix(secs, 0); t.After(used[branch]) {
+			used[branch] = t
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage log: %w", err)
+	}
+	return used, nil
+}
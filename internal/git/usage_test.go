@@ -0,0 +1,183 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddHookBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"new hook", "", false},
+		{"shell hook", "#!/bin/sh\necho hi\n", false},
+		{"env bash hook", "#!/usr/bin/env bash\necho hi", false},
+		{"exec hook", "#!/bin/sh\nexec pre-commit hook-impl \"$@\"\n", false},
+		{"already installed", "#!/bin/sh\n" + hookBlock("post-merge"), false},
+		{"python hook", "#!/usr/bin/env python3\nprint('hi')\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addHookBlock(tt.content, "post-merge")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(got, hookBegin))
+			assert.True(t, isShellScript(got))
+			// Right after the shebang, so an exec or exit can't skip it
+			_, rest, _ := strings.Cut(got, "\n")
+			assert.True(t, strings.HasPrefix(rest, hookBegin), got)
+
+			// Removing the block restores what was there
+			removed, ok := removeHookBlock(got)
+			assert.True(t, ok)
+			if strings.Contains(tt.content, hookBegin) || tt.content == "" {
+				assert.Equal(t, "#!/bin/sh\n", removed)
+			} else {
+				assert.Equal(t, strings.TrimSuffix(tt.content, "\n"), strings.TrimSuffix(removed, "\n"))
+			}
+		})
+	}
+}
+
+func TestIsShellScript(t *testing.T) {
+	tests := []struct {
+		shebang string
+		want    bool
+	}{
+		{"#!/bin/sh", true},
+		{"#!/bin/bash -e", true},
+		{"#! /usr/local/bin/zsh", true},
+		{"#!/usr/bin/env bash", true},
+		{"#!/usr/bin/env -S bash -eu", true},
+		{"#!/usr/bin/env LANG=C dash", true},
+		{"#!/usr/bin/fish", false},
+		{"#!/usr/bin/env fish", false},
+		{"#!/bin/csh -f", false},
+		{"#!/usr/bin/env python3", false},
+		{"#!/usr/bin/env", false},
+		{"#!", false},
+		{"echo no shebang", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shebang, func(t *testing.T) {
+			assert.Equal(t, tt.want, isShellScript(tt.shebang+"\necho hi\n"))
+		})
+	}
+}
+
+func TestCompactUsageLog(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	path := filepath.Join(dir, ".git", UsageLog)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+
+	// Enough checkouts to pass the limit
+	var b strings.Builder
+	for i := 0; b.Len() <= maxUsageLogSize; i++ {
+		fmt.Fprintf(&b, "%d feature/test\n%d main\n", 1000+i, 500+i)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(b.String()), 0644))
+
+	used, err := g.BranchUsage()
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%d feature/test\n%d main\n", used["feature/test"].Unix(), used["main"].Unix()), string(data))
+
+	// Compacting keeps what the log says
+	again, err := g.BranchUsage()
+	require.NoError(t, err)
+	assert.Equal(t, used, again)
+}
+
+func TestParseUsageLog(t *testing.T) {
+	used, err := parseUsageLog(strings.NewReader("100 main\n300 feature/a\n200 feature/a\nbogus\nx feature/b\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"main":      time.Unix(100, 0),
+		"feature/a": time.Unix(300, 0),
+	}, used)
+}
+
+func TestUsageHooks(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, g.InstallUsageHooks())
+	installed, err := g.UsageHooksInstalled()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"post-checkout": true, "post-merge": true}, installed)
+
+	before := time.Now().Add(-time.Second)
+	run("checkout", "feature/test")
+	run("checkout", "main")
+
+	branches := []GitBranch{{Name: "feature/test"}, {Name: "feature/test2"}}
+	require.NoError(t, g.WithUsage(branches))
+	assert.False(t, branches[0].LastUsed.Before(before))
+	assert.True(t, branches[1].LastUsed.IsZero())
+
+	require.NoError(t, g.UninstallUsageHooks())
+	_, err = os.Stat(filepath.Join(dir, ".git", "hooks", "post-checkout"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUsageHooksBeforeExec(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// Hooks generated by tools like pre-commit end by exec'ing them
+	hooks := filepath.Join(dir, ".git", "hooks")
+	require.NoError(t, os.MkdirAll(hooks, 0755))
+	marker := filepath.Join(t.TempDir(), "ran")
+	existing := fmt.Sprintf("#!/bin/sh -e\nexec touch %q\n", marker)
+	require.NoError(t, os.WriteFile(filepath.Join(hooks, "post-checkout"), []byte(existing), 0755))
+
+	g, err := New(dir)
+	require.NoError(t, err)
+	require.NoError(t, g.InstallUsageHooks())
+
+	before := time.Now().Add(-time.Second)
+	c := exec.Command("git", "checkout", "feature/test")
+	c.Dir = dir
+	out, err := c.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	// Both the usage block and the existing hook ran
+	branches := []GitBranch{{Name: "feature/test"}}
+	require.NoError(t, g.WithUsage(branches))
+	assert.False(t, branches[0].LastUsed.Before(before))
+	assert.FileExists(t, marker)
+
+	require.NoError(t, g.UninstallUsageHooks())
+	content, err := os.ReadFile(filepath.Join(hooks, "post-checkout"))
+	require.NoError(t, err)
+	assert.Equal(t, existing, string(content))
+}
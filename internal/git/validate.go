@@ -2,38 +2,43 @@ package git
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
-	"unicode"
+
+	pkggit "github.com/bral/git-branch-delete-go/pkg/git"
 )
 
 var (
-	// Git branch naming rules:
-	// - Cannot start with '.'
-	// - Cannot have double dots '..'
-	// - Cannot have ASCII control characters
-	// - Cannot contain: space, ~, ^, :, ?, *, [, \
-	// - Cannot end with '/'
-	// - Cannot end with '.lock'
-	// Using multiple regexes instead of negative lookahead
-	branchStartDotRegex = regexp.MustCompile(`^\.`)
-	doubleDotRegex     = regexp.MustCompile(`\.\.`)
-	endSlashRegex      = regexp.MustCompile(`/$`)
-	endLockRegex       = regexp.MustCompile(`\.lock$`)
-	// More restrictive valid chars regex
-	validCharsRegex    = regexp.MustCompile(`^[a-zA-Z0-9][-a-zA-Z0-9/_]+$`)
-
 	// Consolidated git command validation
 	allowedGitCommands = map[string]bool{
 		// Core commands we use
 		"branch":        true,
-		"push":         true,
-		"rev-parse":    true,
-		"show-ref":     true,
-		"ls-remote":    true,
-		"for-each-ref": true,
-		"checkout":     true,  // For branch creation and switching
-		"commit":       true,  // For creating test commits
+		"push":          true,
+		"rev-parse":     true,
+		"show-ref":      true,
+		"ls-remote":     true,
+		"for-each-ref":  true,
+		"checkout":      true, // For branch creation and switching
+		"commit":        true, // For creating test commits
+		"rev-list":      true, // For branch weight estimation
+		"worktree":      true, // For finding branches checked out elsewhere
+		"stash":         true, // For finding branches referenced by stashes
+		"symbolic-ref":  true, // For reading origin/HEAD
+		"remote":        true, // For updating origin/HEAD
+		"notes":         true, // For finding notes on commits a deletion orphans
+		"cherry":        true, // For finding branches cherry-picked into a merge target
+		"update-ref":    true, // For atomic bulk deletion
+		"version":       true, // For detecting supported features
+		"count-objects": true, // For repository health metrics
+		"gc":            true, // For repository maintenance
+		"reflog":        true, // For expiring old reflog entries
+		"pack-refs":     true, // For packing loose refs
+		"bundle":        true, // For backing up branches before deleting them
+		"merge-base":    true, // For finding where a branch forked
+		"diff":          true, // For the line counts of unmerged work
+		"cat-file":      true, // For reading run notes in one go
+		"config":        true, // For removing the configuration of deleted branches
 	}
 
 	// Allowed git flags with descriptions for security audit
@@ -41,51 +46,83 @@ var (
 		// Branch operations
 		"-d":            true, // Delete branch
 		"-D":            true, // Force delete branch
+		"-m":            true, // Rename branch
 		"-b":            true, // Create and checkout branch
 		"--delete":      true, // Delete branch (long form)
 		"--force":       true, // Force operation
+		"--dry-run":     true, // Check a push without sending anything
 		"--allow-empty": true, // Allow empty commits
 
 		// Branch listing and info
-		"-r":            true, // Remote branches
-		"--remotes":     true, // Remote branches (long form)
-		"--merged":      true, // List merged branches
-		"--no-merged":   true, // List unmerged branches
-		"--format":      true, // Custom format
-		"--abbrev-ref": true,  // Short ref names
-		"--verify":     true,  // Verify ref exists
-		"--quiet":      true,  // Suppress output
-		"--porcelain":  true,  // Machine-readable output
-		"-v":           true,  // Verbose
-		"-vv":          true,  // Very verbose
-		"--short":      true,  // Short SHA
+		"-r":               true, // Remote branches
+		"--remotes":        true, // Remote branches (long form)
+		"--merged":         true, // List merged branches
+		"--no-merged":      true, // List unmerged branches
+		"--format":         true, // Custom format
+		"--abbrev-ref":     true, // Short ref names
+		"--verify":         true, // Verify ref exists
+		"--quiet":          true, // Suppress output
+		"--porcelain":      true, // Machine-readable output
+		"-v":               true, // Verbose
+		"-vv":              true, // Very verbose
+		"--short":          true, // Short SHA
+		"--count":          true, // Count revisions
+		"--left-right":     true, // Count both sides of a symmetric difference
+		"--objects":        true, // Include trees and blobs
+		"--disk-usage":     true, // Report on-disk size
+		"--not":            true, // Exclude revisions reachable from the following refs
+		"--no-walk":        true, // Only show the given commits
+		"--timestamp":      true, // Print commit timestamps
+		"--git-path":       true, // Resolve paths inside the git directory
+		"--git-common-dir": true, // Resolve the git directory shared by worktrees
+		"--symref":         true, // Show what symbolic refs point at
+		"--heads":          true, // Only list a remote's branches
+		"--sort=refname":   true, // List refs in name order
+		"--exclude":        true, // Exclude matching refs from the following --all
+		"--stdin":          true, // Read update-ref commands from stdin
+		"--create-reflog":  true, // Record when a ref was created
+		"--graph":          true, // Draw the commit graph
+		"--oneline":        true, // Abbreviated hash and subject per commit
+		"--boundary":       true, // Show where excluded history begins
+		"--shortstat":      true, // Summarize changed files and lines
+		"--ancestry-path":  true, // Only commits between the given revisions
+		"--merges":         true, // Only merge commits
+		"--contains":       true, // Only refs containing a commit
+		"--ref":            true, // The notes ref to work on
+
+		// Branch configuration
+		"--unset-upstream":  true, // Remove a branch's upstream configuration
+		"--set-upstream-to": true, // Point a branch at a new upstream
+		"--remove-section":  true, // Remove a branch's configuration section
 
 		// Remote operations
-		"origin":       true,  // Default remote name
-		"--progress":   true,  // Show progress
-		"--all":        true,  // All refs
+		"origin":     true, // Default remote name
+		"--progress": true, // Show progress
+		"--all":      true, // All refs
 
 		// Special refs
-		"HEAD":         true,  // Current HEAD
-		"refs/heads":   true,  // Local branches
-		"refs/remotes": true,  // Remote branches
+		"HEAD":         true, // Current HEAD
+		"refs/heads":   true, // Local branches
+		"refs/remotes": true, // Remote branches
 
 		// Git config
-		"-c":           true,  // Set config
-	}
-
-	// Dangerous patterns that could be used for command injection
-	dangerousPatterns = []string{
-		";", "&", "|", "`", "$", "(", ")", "<", ">", "\\",
-		"\n", "\r", "\t", "\v", "\f",
-		"../", ".../", "~", "%", "@{",
-		":", "?", "*", "[", "]", "{", "}", "'", "\"",
+		"-c": true, // Set config
 	}
 
-	// More comprehensive invalid sequences
-	invalidSequences = []string{
-		"..", "//", "@{", ".lock/", "/.git/", ".git/",
-		"../", "..\\", ".\\", "\\", "./../", "/..",
+	// commandFlags are allowed only as arguments of one command, where
+	// their meaning is known; elsewhere "-f" forces and "-" reads stdin
+	commandFlags = map[string]map[string]bool{
+		"notes": {
+			"-f": true, // Overwrite an existing note
+			"-F": true, // Read the note from a file
+			"-":  true, // The file is stdin
+		},
+		"cat-file": {
+			"--batch": true, // Read the objects named on stdin
+		},
+		"pack-refs": {
+			"--prune": true, // Remove the loose refs once packed
+		},
 	}
 
 	// More restrictive branch name pattern
@@ -120,7 +157,7 @@ func ValidateGitArg(arg string) error {
 
 	// Check if it's a ref path
 	if strings.HasPrefix(arg, "refs/") {
-		return ValidateBranchName(strings.TrimPrefix(arg, "refs/"))
+		return validateRefPath(strings.TrimPrefix(arg, "refs/"))
 	}
 
 	// Check if it's a branch name
@@ -128,51 +165,91 @@ func ValidateGitArg(arg string) error {
 		return nil
 	}
 
+	// Check if it's a branch's configuration section (branch.<name>)
+	if name, ok := strings.CutPrefix(arg, "branch."); ok && pkggit.ValidateBranchName(name) == nil && !strings.HasPrefix(name, "-") {
+		return nil
+	}
+
+	// Check if it's a lease on a branch (--force-with-lease=<ref>:<commit>),
+	// or on its absence with an empty commit
+	if lease, ok := strings.CutPrefix(arg, "--force-with-lease="); ok {
+		ref, commit, colon := strings.Cut(lease, ":")
+		if strings.HasPrefix(ref, "refs/heads/") && colon && (commit == "" || leaseCommitPattern.MatchString(commit)) && ValidateGitArg(ref) == nil {
+			return nil
+		}
+		return fmt.Errorf("unsupported git argument: %s", arg)
+	}
+
+	// Check if it's a push refspec (<src>:<dst>) with valid sides
+	if src, dst, ok := strings.Cut(arg, ":"); ok && src != "" && dst != "" && !strings.Contains(dst, ":") {
+		if ValidateGitArg(src) == nil && ValidateGitArg(dst) == nil {
+			return nil
+		}
+	}
+
 	return fmt.Errorf("unsupported git argument: %s", arg)
 }
 
-// ValidateBranchName validates a git branch name
-func ValidateBranchName(name string) error {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return fmt.Errorf("branch name cannot be empty")
+// ValidatePathspec validates a repository-relative path passed after "--".
+// Pathspec magic, absolute paths and paths leaving the repository are rejected.
+func ValidatePathspec(path string) error {
+	switch {
+	case path == "":
+		return fmt.Errorf("path cannot be empty")
+	case strings.HasPrefix(path, ":"):
+		return fmt.Errorf("pathspec magic is not supported: %s", path)
+	case strings.HasPrefix(path, "/"), filepath.IsAbs(path):
+		return fmt.Errorf("path must be relative to the repository: %s", path)
+	case strings.ContainsAny(path, "*?[\\"):
+		return fmt.Errorf("path cannot contain wildcards: %s", path)
+	}
+
+	for _, r := range path {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("path contains control characters: %q", path)
+		}
 	}
 
-	if !branchNamePattern.MatchString(name) {
-		return fmt.Errorf("invalid branch name format")
+	for _, component := range strings.Split(path, "/") {
+		if component == ".." {
+			return fmt.Errorf("path cannot leave the repository: %s", path)
+		}
 	}
 
 	return nil
 }
 
-// SanitizeBranchName removes any potentially dangerous characters from a branch name
-func SanitizeBranchName(name string) string {
-	// Remove any characters that could be used for command injection
-	for _, char := range dangerousPatterns {
-		name = strings.ReplaceAll(name, char, "")
+// ValidateBundlePath validates the location of a bundle file. Bundle paths
+// are the only file arguments passed to git, so they must be absolute, clean
+// and end in .bundle.
+func ValidateBundlePath(path string) error {
+	switch {
+	case !filepath.IsAbs(path):
+		return fmt.Errorf("bundle path must be absolute: %s", path)
+	case filepath.Clean(path) != path:
+		return fmt.Errorf("bundle path must be clean: %s", path)
+	case filepath.Ext(path) != ".bundle":
+		return fmt.Errorf("bundle path must end in .bundle: %s", path)
 	}
 
-	// Remove any control characters and spaces
-	name = strings.Map(func(r rune) rune {
-		if unicode.IsControl(r) || unicode.IsSpace(r) {
-			return -1
+	for _, r := range path {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("bundle path contains control characters: %q", path)
 		}
-		return r
-	}, name)
-
-	// Remove any invalid sequences
-	for _, seq := range invalidSequences {
-		name = strings.ReplaceAll(name, seq, "")
 	}
+	return nil
+}
 
-	// Remove leading dots and dashes
-	name = strings.TrimLeft(name, ".-")
-
-	// Remove trailing dots and slashes
-	name = strings.TrimRight(name, "./")
+// validateRefPath validates the part of a ref after "refs/". Ref arguments are
+// held to the stricter argument pattern on top of git's own naming rules.
+func validateRefPath(path string) error {
+	if err := pkggit.ValidateBranchName(path); err != nil {
+		return err
+	}
 
-	// Replace any remaining invalid characters with dashes
-	name = validCharsRegex.ReplaceAllString(name, "-")
+	if !branchNamePattern.MatchString(path) {
+		return fmt.Errorf("invalid ref format: %s", path)
+	}
 
-	return strings.TrimSpace(name)
+	return nil
 }
@@ -15,9 +15,19 @@ func TestValidateGitArg(t *testing.T) {
 		{"valid command", "branch", false},
 		{"valid flag", "--format", false},
 		{"valid ref", "refs/heads/main", false},
+		{"ref with empty component", "refs/heads//main", true},
+		{"ref with injection", "refs/heads/main;ls", true},
 		{"valid format", "%(refname)", false},
 		{"valid branch name", "feature/test-123", false},
 		{"empty string", "", false},
+		{"valid refspec", "0a1b2c3d:refs/heads/archived/feature-x", false},
+		{"refspec with injection", "main:refs/heads/x;ls", true},
+		{"refspec missing side", ":refs/heads/main", true},
+		{"lease", "--force-with-lease=refs/heads/feature/x:0a1b2c3d", false},
+		{"lease without commit", "--force-with-lease=refs/heads/feature/x", true},
+		{"lease on absence", "--force-with-lease=refs/heads/feature/x:", false},
+		{"lease on a ref name", "--force-with-lease=refs/heads/x:main", true},
+		{"lease outside branches", "--force-with-lease=refs/tags/v1:0a1b2c3d", true},
 		{"command injection ;", "branch;ls", true},
 		{"command injection &&", "branch&&ls", true},
 		{"command injection |", "branch|ls", true},
@@ -26,6 +36,8 @@ func TestValidateGitArg(t *testing.T) {
 		{"invalid characters", "branch\n", true},
 		{"path traversal", "../config", true},
 		{"unknown flag", "--unknown", true},
+		{"force flag", "-f", true},
+		{"stdin", "-", true},
 	}
 
 	for _, tt := range tests {
@@ -40,33 +52,81 @@ func TestValidateGitArg(t *testing.T) {
 	}
 }
 
-func TestValidateBranchName(t *testing.T) {
+func TestValidateGitArgsCommandFlags(t *testing.T) {
 	tests := []struct {
 		name    string
-		branch  string
+		args    []string
 		wantErr bool
 	}{
-		{"valid simple", "main", false},
-		{"valid with slash", "feature/test", false},
-		{"valid with dash", "fix-123", false},
-		{"valid with underscore", "feature_test", false},
+		{"note from stdin", []string{"notes", "--ref", RunNotesRef, "add", "-f", "-F", "-", "HEAD"}, false},
+		{"batch read", []string{"cat-file", "--batch"}, false},
+		{"force elsewhere", []string{"branch", "-f", "main", "HEAD"}, true},
+		{"file elsewhere", []string{"commit", "-F", "-"}, true},
+		{"batch elsewhere", []string{"notes", "--batch"}, true},
+		{"flag as command", []string{"-f"}, true},
+		{"packing refs", []string{"pack-refs", "--all", "--prune"}, false},
+		{"prune elsewhere", []string{"worktree", "list", "--prune"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGitArgs(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePathspec(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"directory", "services/payments", false},
+		{"file with dot", "services/payments/main.go", false},
+		{"current directory", ".", false},
 		{"empty", "", true},
-		{"too long", string(make([]byte, 256)), true},
-		{"starts with dot", ".hidden", true},
-		{"ends with dot", "branch.", true},
-		{"ends with slash", "branch/", true},
-		{"ends with .lock", "branch.lock", true},
-		{"contains space", "feature branch", true},
-		{"contains special chars", "feature*test", true},
-		{"contains control chars", "feature\ntest", true},
-		{"double dots", "feature..test", true},
-		{"command injection", "feature;ls", true},
-		{"path traversal", "../config", true},
+		{"absolute", "/etc/passwd", true},
+		{"parent directory", "../other", true},
+		{"nested parent", "services/../../other", true},
+		{"pathspec magic", ":(top)services", true},
+		{"wildcard", "services/*", true},
+		{"control chars", "services\npayments", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePathspec(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateBundlePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"absolute", "/home/me/.cache/trash/20240101-120000.bundle", false},
+		{"relative", "trash/20240101-120000.bundle", true},
+		{"unclean", "/home/me/../me/x.bundle", true},
+		{"wrong extension", "/home/me/x.pack", true},
+		{"option", "--upload-pack=x.bundle", true},
+		{"control chars", "/home/me/x\n.bundle", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateBranchName(tt.branch)
+			err := ValidateBundlePath(tt.path)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -83,7 +143,7 @@ func TestCustomErrors(t *testing.T) {
 	})
 
 	t.Run("ErrProtectedBranch", func(t *testing.T) {
-		err := newProtectedBranchError("main")
+		err := newProtectedBranchError("main", "")
 		assert.EqualError(t, err, "cannot modify protected branch 'main'")
 	})
 
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RemoteURL returns the URL of origin
+func (g *Git) RemoteURL() (string, error) {
+	out, err := g.execGit("remote", "get-url", "origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to get origin URL: %w", err)
+	}
+	return out, nil
+}
+
+// Placeholders of web URL templates
+const (
+	URLPlaceholderBranch = "{branch}" // The branch name, escaped for a URL path
+	URLPlaceholderHost   = "{host}"   // Origin's host, e.g. github.com
+	URLPlaceholderRepo   = "{repo}"   // Origin's repository path, e.g. owner/name
+)
+
+// SetWebURLTemplate sets the template branch web pages are built from, e.g.
+// "https://github.com/org/repo/compare/{branch}", for hosts the built-in
+// guess gets wrong. Empty guesses from origin's URL.
+func (g *Git) SetWebURLTemplate(template string) {
+	g.webURLTemplate = template
+}
+
+// BranchWebURL returns the web page of a branch on origin's hosting service
+func (g *Git) BranchWebURL(branch string) (string, error) {
+	if g.webURLTemplate != "" {
+		return g.templateWebURL(branch)
+	}
+	remote, err := g.RemoteURL()
+	if err != nil {
+		return "", err
+	}
+	return branchWebURL(resolveSSHAlias(remote), branch)
+}
+
+// templateWebURL fills the web URL template in for branch. Origin's URL is
+// only read when the template refers to it, so templates naming the
+// repository work with any remote.
+func (g *Git) templateWebURL(branch string) (string, error) {
+	var host, repoPath string
+	if strings.Contains(g.webURLTemplate, URLPlaceholderHost) || strings.Contains(g.webURLTemplate, URLPlaceholderRepo) {
+		remote, err := g.RemoteURL()
+		if err != nil {
+			return "", err
+		}
+		if host, repoPath, err = parseRemoteURL(resolveSSHAlias(remote)); err != nil {
+			return "", err
+		}
+	}
+	return expandWebURLTemplate(g.webURLTemplate, host, repoPath, branch), nil
+}
+
+// expandWebURLTemplate replaces the placeholders of template. Each segment
+// of the branch name is escaped, keeping its slashes.
+func expandWebURLTemplate(template, host, repoPath, branch string) string {
+	segments := strings.Split(branch, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.NewReplacer(
+		URLPlaceholderBranch, strings.Join(segments, "/"),
+		URLPlaceholderHost, host,
+		URLPlaceholderRepo, repoPath,
+	).Replace(template)
+}
+
+// RemoteHost returns the host origin is served from, e.g. github.com. SSH
+// host aliases are resolved through the SSH configuration.
+func (g *Git) RemoteHost() (string, error) {
+	remote, err := g.RemoteURL()
+	if err != nil {
+		return "", err
+	}
+	host, _, err := parseRemoteURL(resolveSSHAlias(remote))
+	return host, err
+}
+
+// RemoteRepoPath returns the path of origin's repository on its host, e.g.
+// owner/name
+func (g *Git) RemoteRepoPath() (string, error) {
+	remote, err := g.RemoteURL()
+	if err != nil {
+		return "", err
+	}
+	_, repoPath, err := parseRemoteURL(remote)
+	return repoPath, err
+}
+
+// parseRemoteURL splits a remote URL (https, ssh or scp-like) into the host
+// and the repository path without ".git"
+func parseRemoteURL(remote string) (host, repoPath string, err error) {
+	switch {
+	case strings.Contains(remote, "://"):
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid remote URL %q: %w", remote, err)
+		}
+		host, repoPath = u.Hostname(), u.Path
+	case strings.Contains(remote, ":"):
+		// scp-like syntax: [user@]host:owner/repo.git
+		hostPart, p, _ := strings.Cut(remote, ":")
+		if i := strings.LastIndex(hostPart, "@"); i >= 0 {
+			hostPart = hostPart[i+1:]
+		}
+		host, repoPath = hostPart, p
+	default:
+		return "", "", fmt.Errorf("remote %q is not hosted on a web service", remote)
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", "", fmt.Errorf("remote %q is not hosted on a web service", remote)
+	}
+	return host, repoPath, nil
+}
+
+// branchWebURL turns a remote URL into the web page of branch. The tree
+// path follows GitHub unless the host is recognized as GitLab or Bitbucket.
+func branchWebURL(remote, branch string) (string, error) {
+	host, repoPath, err := parseRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+
+	tree := "tree"
+	switch {
+	case strings.Contains(host, "gitlab"):
+		tree = "-/tree"
+	case strings.Contains(host, "bitbucket"):
+		tree = "branch"
+	}
+	return fmt.Sprintf("https://%s/%s/%s/%s", host, repoPath, tree, branch), nil
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchWebURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		remote  string
+		want    string
+		wantErr bool
+	}{
+		{"https", "https://github.com/bral/repo.git", "https://github.com/bral/repo/tree/feature/x", false},
+		{"https without suffix", "https://github.com/bral/repo", "https://github.com/bral/repo/tree/feature/x", false},
+		{"scp-like", "git@github.com:bral/repo.git", "https://github.com/bral/repo/tree/feature/x", false},
+		{"ssh", "ssh://git@github.com:22/bral/repo.git", "https://github.com/bral/repo/tree/feature/x", false},
+		{"gitlab", "git@gitlab.com:group/sub/repo.git", "https://gitlab.com/group/sub/repo/-/tree/feature/x", false},
+		{"bitbucket", "https://bitbucket.org/team/repo.git", "https://bitbucket.org/team/repo/branch/feature/x", false},
+		{"local path", "/srv/git/repo.git", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := branchWebURL(tt.remote, "feature/x")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpandWebURLTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		branch   string
+		want     string
+	}{
+		{"compare", "https://github.com/org/repo/compare/{branch}", "feature/x", "https://github.com/org/repo/compare/feature/x"},
+		{"from origin", "https://{host}/{repo}/src/{branch}", "main", "https://git.example.com/team/repo/src/main"},
+		{"escaped", "https://example.com/{branch}", "fix/50%#1", "https://example.com/fix/50%25%231"},
+		{"repeated", "https://example.com/{branch}?b={branch}", "x", "https://example.com/x?b=x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, expandWebURLTemplate(tt.template, "git.example.com", "team/repo", tt.branch))
+		})
+	}
+}
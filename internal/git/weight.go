@@ -0,0 +1,51 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// BranchWeight describes how much history is unique to a branch, i.e. what
+// deleting it (followed by gc) could actually reclaim
+type BranchWeight struct {
+	Commits  int   `json:"commits"`  // Commits not reachable from the base
+	Objects  int   `json:"objects"`  // Commits, trees and blobs not reachable from the base
+	DiskSize int64 `json:"diskSize"` // Approximate on-disk size of those objects in bytes
+}
+
+// BranchWeight computes the commits and objects reachable from ref but not from base
+func (g *Git) BranchWeight(ref, base string) (BranchWeight, error) {
+	return g.branchWeight(context.Background(), ref, base)
+}
+
+// branchWeight is BranchWeight giving up once ctx is done
+func (g *Git) branchWeight(ctx context.Context, ref, base string) (BranchWeight, error) {
+	var w BranchWeight
+
+	out, err := g.execGitContext(ctx, "rev-list", "--count", ref, "--not", base)
+	if err != nil {
+		return w, fmt.Errorf("failed to count commits: %w", err)
+	}
+	if w.Commits, err = strconv.Atoi(out); err != nil {
+		return w, fmt.Errorf("invalid commit count %q: %w", out, err)
+	}
+
+	out, err = g.execGitContext(ctx, "rev-list", "--count", "--objects", ref, "--not", base)
+	if err != nil {
+		return w, fmt.Errorf("failed to count objects: %w", err)
+	}
+	if w.Objects, err = strconv.Atoi(out); err != nil {
+		return w, fmt.Errorf("invalid object count %q: %w", out, err)
+	}
+
+	out, err = g.execGitContext(ctx, "rev-list", "--disk-usage", "--objects", ref, "--not", base)
+	if err != nil {
+		return w, fmt.Errorf("failed to compute disk usage: %w", err)
+	}
+	if w.DiskSize, err = strconv.ParseInt(out, 10, 64); err != nil {
+		return w, fmt.Errorf("invalid disk usage %q: %w", out, err)
+	}
+
+	return w, nil
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchWeight(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// Two commits adding one file each: two commits, two trees and two blobs
+	run("checkout", "-b", "feature/heavy")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat(name, 1000)), 0600))
+		run("add", name)
+		run("commit", "-m", "Add "+name)
+	}
+	run("checkout", "main")
+
+	g, err := New(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		ref     string
+		commits int
+		objects int
+	}{
+		{"unique history", "refs/heads/feature/heavy", 2, 6},
+		{"nothing unique", "refs/heads/feature/test", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := g.BranchWeight(tt.ref, "refs/heads/main")
+			require.NoError(t, err)
+			assert.Equal(t, tt.commits, w.Commits)
+			assert.Equal(t, tt.objects, w.Objects)
+			if tt.objects > 0 {
+				assert.Positive(t, w.DiskSize)
+			} else {
+				assert.Zero(t, w.DiskSize)
+			}
+		})
+	}
+
+	_, err = g.BranchWeight("refs/heads/missing", "refs/heads/main")
+	assert.Error(t, err)
+}
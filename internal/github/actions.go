@@ -0,0 +1,64 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ActionsEnv holds what a GitHub Actions run knows about its context
+type ActionsEnv struct {
+	Repository string // owner/name, from GITHUB_REPOSITORY
+	APIURL     string // From GITHUB_API_URL
+	Token      string // From GITHUB_TOKEN or GH_TOKEN
+	Number     int    // Pull request or issue of the triggering event, 0 if none
+}
+
+// ActionsEnvFromOS reads the GitHub Actions environment. getenv is usually
+// os.Getenv.
+func ActionsEnvFromOS(getenv func(string) string) (ActionsEnv, error) {
+	env := ActionsEnv{
+		Repository: getenv("GITHUB_REPOSITORY"),
+		APIURL:     getenv("GITHUB_API_URL"),
+		Token:      getenv("GITHUB_TOKEN"),
+	}
+	if env.Token == "" {
+		env.Token = getenv("GH_TOKEN")
+	}
+
+	if path := getenv("GITHUB_EVENT_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return env, fmt.Errorf("failed to read event payload: %w", err)
+		}
+		if env.Number, err = eventNumber(data); err != nil {
+			return env, err
+		}
+	}
+	return env, nil
+}
+
+// eventNumber returns the pull request or issue number of an event payload
+func eventNumber(data []byte) (int, error) {
+	var event struct {
+		Number      int `json:"number"`
+		PullRequest *struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+		Issue *struct {
+			Number int `json:"number"`
+		} `json:"issue"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return 0, fmt.Errorf("failed to decode event payload: %w", err)
+	}
+
+	switch {
+	case event.PullRequest != nil:
+		return event.PullRequest.Number, nil
+	case event.Issue != nil:
+		return event.Issue.Number, nil
+	default:
+		return event.Number, nil
+	}
+}
@@ -0,0 +1,113 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCacheMaxAge is how long cached responses are used. A commit's pull
+// requests can still change, e.g. when one is merged, so entries don't
+// live forever even though the commit doesn't move.
+const DefaultCacheMaxAge = 15 * time.Minute
+
+// Cache keeps API responses about commits in a file, keyed by repository
+// and commit SHA, usually a branch's head. Checking hundreds of branches
+// again only asks about the ones that moved since. It is safe for
+// concurrent use.
+type Cache struct {
+	MaxAge time.Duration // DefaultCacheMaxAge when 0
+
+	path    string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	changed bool
+}
+
+// cacheEntry is one cached response
+type cacheEntry struct {
+	Fetched time.Time       `json:"fetched"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// OpenCache reads the cache kept at path. A missing or unreadable file
+// starts an empty cache, since it is only a cache.
+func OpenCache(path string) *Cache {
+	c := &Cache{path: path, entries: make(map[string]cacheEntry)}
+	if data, err := os.ReadFile(path); err == nil {
+		if json.Unmarshal(data, &c.entries) != nil {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	return c
+}
+
+// get decodes the entry for key into out, reporting whether there was a
+// fresh one
+func (c *Cache) get(key string, out interface{}, now time.Time) bool {
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || now.Sub(e.Fetched) > maxAge {
+		return false
+	}
+	return json.Unmarshal(e.Data, out) == nil
+}
+
+// put stores v as the entry for key
+func (c *Cache) put(key string, v interface{}, now time.Time) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{Fetched: now, Data: data}
+	c.changed = true
+}
+
+// Save writes the cache back to its file, dropping expired entries. It
+// does nothing when nothing was added.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.changed {
+		return nil
+	}
+
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultCacheMaxAge
+	}
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.Sub(e.Fetched) > maxAge {
+			delete(c.entries, key)
+		}
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	c.changed = false
+	return nil
+}
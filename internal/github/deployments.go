@@ -0,0 +1,131 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// deploymentsPerPage is the largest page size the deployments API allows
+const deploymentsPerPage = 100
+
+// maxDeploymentPages bounds how many pages of deployments are read, newest
+// first; what is live is nearly always among the latest ones
+const maxDeploymentPages = 3
+
+// maxStateLookups bounds how many deployments' states are read, one
+// request each
+const maxStateLookups = 100
+
+// Deployment is a deployment of a ref to an environment
+type Deployment struct {
+	ID          int64     `json:"id"`
+	Ref         string    `json:"ref"` // Branch, tag or commit
+	SHA         string    `json:"sha"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DeploymentStatus is a state a deployment went through
+type DeploymentStatus struct {
+	State string `json:"state"`
+}
+
+// liveStates are the deployment states of a ref that is, or is about to
+// be, running in its environment; the others are error, failure and
+// inactive
+var liveStates = []string{"success", "in_progress", "queued", "pending"}
+
+// ListDeployments returns the deployments of repo ("owner/name"), newest
+// first. When repo has more than maxDeploymentPages pages of them, the ones
+// read are returned with an error wrapping ErrIncomplete.
+func (c *Client) ListDeployments(ctx context.Context, repo string) ([]Deployment, error) {
+	var all []Deployment
+	for page := 1; page <= maxDeploymentPages; page++ {
+		var deployments []Deployment
+		path := fmt.Sprintf("/repos/%s/deployments?per_page=%d&page=%d", repo, deploymentsPerPage, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &deployments); err != nil {
+			return nil, err
+		}
+		all = append(all, deployments...)
+		if len(deployments) < deploymentsPerPage {
+			return all, nil
+		}
+	}
+	return all, fmt.Errorf("%w: only the %d most recent deployments of %s were read", ErrIncomplete, len(all), repo)
+}
+
+// DeploymentState returns the latest state of deployment id of repo, or ""
+// when it has none yet
+func (c *Client) DeploymentState(ctx context.Context, repo string, id int64) (string, error) {
+	var statuses []DeploymentStatus
+	path := fmt.Sprintf("/repos/%s/deployments/%d/statuses?per_page=1", repo, id)
+	if err := c.do(ctx, http.MethodGet, path, nil, &statuses); err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return "", nil
+	}
+	return statuses[0].State, nil
+}
+
+// DeployedRefs returns the environments each ref of repo is deployed to,
+// keyed by ref. In each environment, the newest successful deployment is
+// what runs there, and newer ones still in progress are about to; older
+// deployments were replaced even when nobody marked them inactive. Only
+// environments in envs count, or all when envs is empty.
+//
+// When not every deployment that matters could be read, within
+// maxDeploymentPages and maxStateLookups, the refs found are returned with
+// an error wrapping ErrIncomplete.
+func (c *Client) DeployedRefs(ctx context.Context, repo string, envs []string) (map[string][]string, error) {
+	deployments, incomplete := c.ListDeployments(ctx, repo)
+	if incomplete != nil && !errors.Is(incomplete, ErrIncomplete) {
+		return nil, incomplete
+	}
+
+	wanted := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		wanted[env] = true
+	}
+	deployed := make(map[string][]string)
+	settled := make(map[string]bool) // Environments whose running deployment was found
+	lookups := 0
+	for _, d := range deployments {
+		if len(wanted) > 0 && len(settled) == len(wanted) {
+			break
+		}
+		if settled[d.Environment] || (len(wanted) > 0 && !wanted[d.Environment]) {
+			continue
+		}
+		if lookups == maxStateLookups {
+			incomplete = fmt.Errorf("%w: only the states of %d deployments of %s were read", ErrIncomplete, lookups, repo)
+			break
+		}
+		lookups++
+		state, err := c.DeploymentState(ctx, repo, d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(liveStates, state) {
+			continue
+		}
+		if !slices.Contains(deployed[d.Ref], d.Environment) {
+			deployed[d.Ref] = append(deployed[d.Ref], d.Environment)
+		}
+		if state == "success" {
+			settled[d.Environment] = true
+		}
+	}
+	if len(wanted) > 0 && len(settled) == len(wanted) {
+		// Whatever wasn't read was replaced
+		incomplete = nil
+	}
+	for ref := range deployed {
+		slices.Sort(deployed[ref])
+	}
+	return deployed, incomplete
+}
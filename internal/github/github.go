@@ -0,0 +1,240 @@
+// Package github is a minimal GitHub REST API client for the few calls the
+// tool makes, such as commenting on pull requests from GitHub Actions.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the API of github.com; GitHub Enterprise and Actions
+// runners provide theirs in GITHUB_API_URL
+const DefaultBaseURL = "https://api.github.com"
+
+// commentsPerPage is the largest page size the comments API allows
+const commentsPerPage = 100
+
+// maxRateLimitRetries is how many times a rate limited request is retried
+const maxRateLimitRetries = 3
+
+// maxRateLimitWait is the longest wait for a rate limit to lift; requests
+// limited for longer fail right away
+const maxRateLimitWait = time.Minute
+
+// ErrIncomplete is returned, wrapped, along with what was read when a
+// listing stopped at its page limit before reaching the end
+var ErrIncomplete = errors.New("listing is incomplete")
+
+// Client calls the GitHub REST API with a token. It is safe for concurrent
+// use.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+
+	// RateLimit is how many requests a second are sent at most; 0 means no
+	// limit. Rate limited responses hold every request back until the
+	// limit lifts either way.
+	RateLimit float64
+
+	// Cache keeps the pull requests of commits, when set
+	Cache *Cache
+
+	mu   sync.Mutex
+	next time.Time // When the next request may be sent
+}
+
+// NewClient returns a client for baseURL, or DefaultBaseURL when empty
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Comment is an issue or pull request comment
+type Comment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+}
+
+// UpsertComment posts body as a comment on issue or pull request number of
+// repo ("owner/name"), or updates the earlier comment containing marker so
+// repeated runs keep a single comment up to date
+func (c *Client) UpsertComment(ctx context.Context, repo string, number int, marker, body string) (*Comment, error) {
+	if !strings.Contains(body, marker) {
+		body = marker + "\n" + body
+	}
+
+	existing, err := c.findComment(ctx, repo, number, marker)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]string{"body": body}
+	var comment Comment
+	if existing != nil {
+		err = c.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", repo, existing.ID), payload, &comment)
+	} else {
+		err = c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), payload, &comment)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// findComment returns the first comment containing marker, or nil
+func (c *Client) findComment(ctx context.Context, repo string, number int, marker string) (*Comment, error) {
+	for page := 1; ; page++ {
+		var comments []Comment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=%d&page=%d", repo, number, commentsPerPage, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return nil, err
+		}
+		for i := range comments {
+			if strings.Contains(comments[i].Body, marker) {
+				return &comments[i], nil
+			}
+		}
+		if len(comments) < commentsPerPage {
+			return nil, nil
+		}
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out. Rate
+// limited requests are retried once the limit lifts, when that's soon.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if in != nil {
+			body = bytes.NewReader(data)
+		}
+		if err := c.throttle(ctx); err != nil {
+			return fmt.Errorf("GitHub API request failed: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err = c.HTTP.Do(req)
+		if err != nil {
+			return fmt.Errorf("GitHub API request failed: %w", err)
+		}
+		wait, limited := rateLimitWait(resp, attempt, time.Now())
+		if !limited || attempt == maxRateLimitRetries || wait > maxRateLimitWait {
+			break
+		}
+		resp.Body.Close()
+		c.holdBack(wait)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GitHub API %s %s: %s: %s", method, strings.SplitN(path, "?", 2)[0], resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	return nil
+}
+
+// throttle waits until the next request may be sent, keeping to RateLimit
+// and to the waits of rate limited responses
+func (c *Client) throttle(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next
+	if at.Before(now) {
+		at = now
+	}
+	if c.RateLimit > 0 {
+		c.next = at.Add(time.Duration(float64(time.Second) / c.RateLimit))
+	}
+	c.mu.Unlock()
+
+	if !at.After(now) {
+		return nil
+	}
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// holdBack keeps every request from being sent for wait
+func (c *Client) holdBack(wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if at := time.Now().Add(wait); at.After(c.next) {
+		c.next = at
+	}
+}
+
+// rateLimitWait reports whether resp is a rate limit response and how long
+// to wait before retrying, from Retry-After or X-RateLimit-Reset, or else
+// doubling with each attempt. GitHub answers 429, or 403 for primary and
+// secondary rate limits.
+func rateLimitWait(resp *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	retryAfter := resp.Header.Get("Retry-After")
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && (retryAfter != "" || remaining == "0"):
+	default:
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && remaining == "0" {
+		wait := time.Unix(reset, 0).Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return time.Second << attempt, true
+}
@@ -0,0 +1,416 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const marker = "<!-- test-marker -->"
+
+// fakeIssue serves the comments API of one issue
+func fakeIssue(t *testing.T, comments []Comment) (*httptest.Server, *[]Comment) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Get("page") != "1" {
+				json.NewEncoder(w).Encode([]Comment{})
+				return
+			}
+			json.NewEncoder(w).Encode(comments)
+		case http.MethodPost:
+			var in Comment
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+			in.ID = int64(len(comments) + 1)
+			comments = append(comments, in)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(in)
+		}
+	})
+	mux.HandleFunc("/repos/o/r/issues/comments/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		var in Comment
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		for i := range comments {
+			if r.URL.Path == fmt.Sprintf("/repos/o/r/issues/comments/%d", comments[i].ID) {
+				comments[i].Body = in.Body
+				json.NewEncoder(w).Encode(comments[i])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &comments
+}
+
+func TestUpsertComment(t *testing.T) {
+	srv, comments := fakeIssue(t, []Comment{{ID: 1, Body: "unrelated"}})
+	c := NewClient(srv.URL, "token")
+
+	created, err := c.UpsertComment(context.Background(), "o/r", 7, marker, "first report")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), created.ID)
+	assert.Equal(t, marker+"\nfirst report", created.Body)
+
+	updated, err := c.UpsertComment(context.Background(), "o/r", 7, marker, "second report")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Len(t, *comments, 2)
+	assert.Equal(t, marker+"\nsecond report", (*comments)[1].Body)
+}
+
+func TestUpsertCommentError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "token").UpsertComment(context.Background(), "o/r", 7, marker, "report")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Bad credentials")
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message":"rate limited"}`, http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	prs, err := NewClient(srv.URL, "token").ListPullRequests(context.Background(), "o/r")
+	require.NoError(t, err)
+	assert.Empty(t, prs)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		attempt int
+		want    time.Duration
+		limited bool
+	}{
+		{"ok", http.StatusOK, nil, 0, 0, false},
+		{"forbidden", http.StatusForbidden, nil, 0, 0, false},
+		{"retry after", http.StatusTooManyRequests, map[string]string{"Retry-After": "3"}, 0, 3 * time.Second, true},
+		{"secondary limit", http.StatusForbidden, map[string]string{"Retry-After": "60"}, 0, time.Minute, true},
+		{"primary limit", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1030"}, 0, 30 * time.Second, true},
+		{"reset passed", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "900"}, 0, 0, true},
+		{"backoff", http.StatusTooManyRequests, nil, 2, 4 * time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+			wait, limited := rateLimitWait(resp, tt.attempt, now)
+			assert.Equal(t, tt.limited, limited)
+			assert.Equal(t, tt.want, wait)
+		})
+	}
+}
+
+func TestRateLimitGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, `{"message":"rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "token").ListPullRequests(context.Background(), "o/r")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	var sent []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, time.Now())
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "token")
+	c.RateLimit = 20
+	for i := 0; i < 3; i++ {
+		_, err := c.ListPullRequests(context.Background(), "o/r")
+		require.NoError(t, err)
+	}
+	require.Len(t, sent, 3)
+	assert.GreaterOrEqual(t, sent[2].Sub(sent[0]), 90*time.Millisecond)
+}
+
+func TestRateLimitHoldsBackOtherRequests(t *testing.T) {
+	c := NewClient("http://unused", "token")
+	c.holdBack(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.throttle(ctx), context.DeadlineExceeded)
+}
+
+func TestActionsEnvFromOS(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  int
+	}{
+		{name: "pull request", event: `{"number": 3, "pull_request": {"number": 3}}`, want: 3},
+		{name: "issue comment", event: `{"issue": {"number": 5}}`, want: 5},
+		{name: "push", event: `{"ref": "refs/heads/main"}`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "event.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.event), 0600))
+
+			vars := map[string]string{
+				"GITHUB_REPOSITORY": "o/r",
+				"GH_TOKEN":          "token",
+				"GITHUB_EVENT_PATH": path,
+			}
+			env, err := ActionsEnvFromOS(func(k string) string { return vars[k] })
+			require.NoError(t, err)
+			assert.Equal(t, ActionsEnv{Repository: "o/r", Token: "token", Number: tt.want}, env)
+		})
+	}
+}
+
+func TestListPullRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/o/r/pulls", r.URL.Path)
+		assert.Equal(t, "all", r.URL.Query().Get("state"))
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number": 2, "state": "open", "draft": true, "head": {"ref": "wip", "repo": {"full_name": "o/r"}}},
+			{"number": 1, "state": "closed", "merged_at": "2024-01-02T03:04:05Z", "head": {"ref": "done", "repo": {"full_name": "o/r"}}}]`)
+	}))
+	defer srv.Close()
+
+	prs, err := NewClient(srv.URL, "token").ListPullRequests(context.Background(), "o/r")
+	require.NoError(t, err)
+	require.Len(t, prs, 2)
+	assert.Equal(t, PRDraft, prs[0].Status())
+	assert.Equal(t, PRMerged, prs[1].Status())
+}
+
+func TestListPullRequestsIncomplete(t *testing.T) {
+	page := make([]string, pullsPerPage)
+	for i := range page {
+		page[i] = fmt.Sprintf(`{"number": %d, "state": "open", "head": {"ref": "b%d", "repo": {"full_name": "o/r"}}}`, i, i)
+	}
+	var pages int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		fmt.Fprint(w, "["+strings.Join(page, ",")+"]")
+	}))
+	defer srv.Close()
+
+	prs, err := NewClient(srv.URL, "token").ListPullRequests(context.Background(), "o/r")
+	require.ErrorIs(t, err, ErrIncomplete)
+	assert.Len(t, prs, maxPullPages*pullsPerPage)
+	assert.Equal(t, maxPullPages, pages)
+}
+
+func TestBranchPRStates(t *testing.T) {
+	pr := func(number int, state string, draft, merged bool, branch, repo string) PullRequest {
+		p := PullRequest{Number: number, State: state, Draft: draft}
+		if merged {
+			at := time.Now()
+			p.MergedAt = &at
+		}
+		p.Head.Ref = branch
+		if repo != "" {
+			p.Head.Repo = &struct {
+				FullName string `json:"full_name"`
+			}{FullName: repo}
+		}
+		return p
+	}
+
+	states := BranchPRStates([]PullRequest{
+		pr(1, "closed", false, false, "reopened", "o/r"),
+		pr(5, "open", false, false, "reopened", "o/r"),
+		pr(2, "closed", false, true, "retried", "o/r"),
+		pr(6, "closed", false, false, "retried", "o/r"),
+		pr(3, "open", true, false, "wip", "O/R"),
+		pr(4, "open", false, false, "fork", "someone/r"),
+		pr(7, "open", false, false, "deleted-fork", ""),
+	}, "o/r")
+
+	assert.Equal(t, map[string]string{
+		"reopened": PRReady,
+		"retried":  PRClosed,
+		"wip":      PRDraft,
+	}, states)
+}
+
+func TestBranchPRState(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/repos/o/r/commits/abc123/pulls", r.URL.Path)
+		fmt.Fprint(w, `[{"number": 1, "state": "closed", "merged_at": "2024-01-02T03:04:05Z", "head": {"ref": "feature", "sha": "abc123", "repo": {"full_name": "o/r"}}},
+			{"number": 2, "state": "open", "head": {"ref": "other", "sha": "def456", "repo": {"full_name": "o/r"}}}]`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "github", "cache.json")
+	c := NewClient(srv.URL, "token")
+	c.Cache = OpenCache(path)
+
+	state, err := c.BranchPRState(context.Background(), "o/r", "feature", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, PRMerged, state)
+
+	// A pull request containing the commit from another branch isn't the
+	// branch's own
+	state, err = c.BranchPRState(context.Background(), "o/r", "other", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, PRNone, state)
+	assert.Equal(t, 1, calls)
+
+	// The saved cache answers the next run
+	require.NoError(t, c.Cache.Save())
+	c.Cache = OpenCache(path)
+	state, err = c.BranchPRState(context.Background(), "o/r", "feature", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, PRMerged, state)
+	assert.Equal(t, 1, calls)
+
+	// Until it expires
+	c.Cache.MaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, err = c.BranchPRState(context.Background(), "o/r", "feature", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestOpenCacheCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+
+	c := OpenCache(path)
+	var prs []PullRequest
+	assert.False(t, c.get("o/r@abc123", &prs, time.Now()))
+	c.put("o/r@abc123", []PullRequest{{Number: 1}}, time.Now())
+	require.NoError(t, c.Save())
+	assert.True(t, OpenCache(path).get("o/r@abc123", &prs, time.Now()))
+	assert.Equal(t, 1, prs[0].Number)
+}
+
+func TestDeployedRefs(t *testing.T) {
+	// Newest first, like the API
+	deployments := `[
+		{"id": 6, "ref": "feature/next", "environment": "production"},
+		{"id": 5, "ref": "feature/preview", "environment": "preview"},
+		{"id": 4, "ref": "main", "environment": "production"},
+		{"id": 3, "ref": "feature/broken", "environment": "staging"},
+		{"id": 2, "ref": "feature/old", "environment": "production"},
+		{"id": 1, "ref": "feature/staged", "environment": "staging"}
+	]`
+	states := map[string]string{
+		"6": `[{"state": "in_progress"}]`,
+		"5": `[{"state": "inactive"}]`,
+		"4": `[{"state": "success"}]`,
+		"3": `[{"state": "failure"}]`,
+		"2": `[{"state": "success"}]`, // Replaced by 4 without being marked inactive
+		"1": `[{"state": "success"}]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/o/r/deployments" {
+			fmt.Fprint(w, deployments)
+			return
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/o/r/deployments/"), "/statuses")
+		if id == "2" {
+			t.Errorf("read the state of a replaced deployment")
+		}
+		fmt.Fprint(w, states[id])
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "token")
+
+	deployed, err := c.DeployedRefs(context.Background(), "o/r", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"feature/next":   {"production"},
+		"main":           {"production"},
+		"feature/staged": {"staging"},
+	}, deployed)
+
+	deployed, err = c.DeployedRefs(context.Background(), "o/r", []string{"staging"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"feature/staged": {"staging"}}, deployed)
+}
+
+func TestDeployedRefsIncomplete(t *testing.T) {
+	page := make([]string, deploymentsPerPage)
+	for i := range page {
+		page[i] = fmt.Sprintf(`{"id": %d, "ref": "b%d", "environment": "preview-%d"}`, i, i, i)
+	}
+	var pages, lookups int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/o/r/deployments" {
+			pages++
+			if r.URL.Query().Get("page") == "1" {
+				// Production is settled by the newest deployment
+				fmt.Fprint(w, `[{"id": 1000, "ref": "main", "environment": "production"},`+strings.Join(page[1:], ",")+"]")
+				return
+			}
+			fmt.Fprint(w, "["+strings.Join(page, ",")+"]")
+			return
+		}
+		lookups++
+		fmt.Fprint(w, `[{"state": "success"}]`)
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "token")
+
+	deployments, err := c.ListDeployments(context.Background(), "o/r")
+	require.ErrorIs(t, err, ErrIncomplete)
+	assert.Len(t, deployments, maxDeploymentPages*deploymentsPerPage)
+	assert.Equal(t, maxDeploymentPages, pages)
+
+	// State lookups stop at their limit, keeping what was found
+	lookups = 0
+	deployed, err := c.DeployedRefs(context.Background(), "o/r", nil)
+	require.ErrorIs(t, err, ErrIncomplete)
+	assert.Len(t, deployed, maxStateLookups)
+	assert.Equal(t, []string{"production"}, deployed["main"])
+	assert.Equal(t, maxStateLookups, lookups)
+
+	// Once every wanted environment is settled, the rest doesn't matter
+	lookups = 0
+	deployed, err = c.DeployedRefs(context.Background(), "o/r", []string{"production"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"main": {"production"}}, deployed)
+	assert.Equal(t, 1, lookups)
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// pullsPerPage is the largest page size the pulls API allows
+const pullsPerPage = 100
+
+// maxPullPages bounds how many pages of pull requests are read, most
+// recently updated first, so huge repositories don't take minutes
+const maxPullPages = 10
+
+// Pull request states of PullRequest.Status
+const (
+	PRDraft  = "draft"  // Open and marked as draft
+	PRReady  = "ready"  // Open and ready for review
+	PRMerged = "merged" // Closed by merging
+	PRClosed = "closed" // Closed without merging
+	PRNone   = "none"   // No pull request for the branch
+)
+
+// PRStates are the states a branch's pull request can be in, including
+// PRNone
+var PRStates = []string{PRDraft, PRReady, PRMerged, PRClosed, PRNone}
+
+// PullRequest is a pull request, with the fields the tool uses
+type PullRequest struct {
+	Number   int        `json:"number"`
+	State    string     `json:"state"` // open or closed
+	Draft    bool       `json:"draft"`
+	MergedAt *time.Time `json:"merged_at"`
+	Head     struct {
+		Ref  string `json:"ref"`
+		SHA  string `json:"sha"`
+		Repo *struct {
+			FullName string `json:"full_name"`
+		} `json:"repo"`
+	} `json:"head"`
+}
+
+// Status returns the state of pr, one of PRDraft, PRReady, PRMerged or
+// PRClosed
+func (pr PullRequest) Status() string {
+	switch {
+	case pr.State == "open" && pr.Draft:
+		return PRDraft
+	case pr.State == "open":
+		return PRReady
+	case pr.MergedAt != nil:
+		return PRMerged
+	default:
+		return PRClosed
+	}
+}
+
+// ListPullRequests returns the open and closed pull requests of repo
+// ("owner/name"), most recently updated first. When repo has more than
+// maxPullPages pages of them, the ones read are returned with an error
+// wrapping ErrIncomplete.
+func (c *Client) ListPullRequests(ctx context.Context, repo string) ([]PullRequest, error) {
+	var all []PullRequest
+	for page := 1; page <= maxPullPages; page++ {
+		var prs []PullRequest
+		path := fmt.Sprintf("/repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=%d&page=%d", repo, pullsPerPage, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &prs); err != nil {
+			return nil, err
+		}
+		all = append(all, prs...)
+		if len(prs) < pullsPerPage {
+			return all, nil
+		}
+	}
+	return all, fmt.Errorf("%w: only the %d most recently updated pull requests of %s were read", ErrIncomplete, len(all), repo)
+}
+
+// CommitPullRequests returns the pull requests of repo containing commit
+// sha, from the client's Cache when it has them
+func (c *Client) CommitPullRequests(ctx context.Context, repo, sha string) ([]PullRequest, error) {
+	key := repo + "@" + sha
+	var prs []PullRequest
+	if c.Cache != nil && c.Cache.get(key, &prs, time.Now()) {
+		return prs, nil
+	}
+
+	path := fmt.Sprintf("/repos/%s/commits/%s/pulls?per_page=%d", repo, sha, pullsPerPage)
+	if err := c.do(ctx, http.MethodGet, path, nil, &prs); err != nil {
+		return nil, err
+	}
+	if c.Cache != nil {
+		c.Cache.put(key, prs, time.Now())
+	}
+	return prs, nil
+}
+
+// BranchPRState returns the state of the pull request of branch of repo,
+// whose head is commit sha, or PRNone. Only pull requests with that head
+// count, so the cached answer holds until the branch moves.
+func (c *Client) BranchPRState(ctx context.Context, repo, branch, sha string) (string, error) {
+	prs, err := c.CommitPullRequests(ctx, repo, sha)
+	if err != nil {
+		return "", err
+	}
+	var heads []PullRequest
+	for _, pr := range prs {
+		if pr.Head.Ref == branch && pr.Head.SHA == sha {
+			heads = append(heads, pr)
+		}
+	}
+	if state, ok := BranchPRStates(heads, repo)[branch]; ok {
+		return state, nil
+	}
+	return PRNone, nil
+}
+
+// BranchPRStates returns the pull request state of each branch of repo
+// that has one, keyed by branch name. An open pull request wins over closed
+// ones; otherwise the newest one counts. Pull requests from forks are
+// ignored since their branches aren't in repo.
+func BranchPRStates(prs []PullRequest, repo string) map[string]string {
+	states := make(map[string]string)
+	numbers := make(map[string]int)
+	for _, pr := range prs {
+		if pr.Head.Repo == nil || !strings.EqualFold(pr.Head.Repo.FullName, repo) {
+			continue
+		}
+		branch, status := pr.Head.Ref, pr.Status()
+		open := status == PRDraft || status == PRReady
+		current, seen := states[branch]
+		currentOpen := current == PRDraft || current == PRReady
+		if !seen || (open && !currentOpen) || (open == currentOpen && pr.Number > numbers[branch]) {
+			states[branch] = status
+			numbers[branch] = pr.Number
+		}
+	}
+	return states
+}
@@ -0,0 +1,154 @@
+// Package journal records deleted branches with their last commit, so undo
+// can recreate them. The journal is a file of JSON lines in the git
+// directory shared by all worktrees, like the branches themselves.
+package journal
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/log"
+)
+
+// File is the name of the journal in the git directory
+const File = "gbd-journal"
+
+// Entry is a deletion undo can take back
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Run    string    `json:"run,omitempty"` // Shared by the deletions of one run
+	Branch string    `json:"branch"`
+	Remote bool      `json:"remote"`
+	Commit string    `json:"commit"` // Full hash of the deleted tip
+}
+
+// SameRun reports whether e and other were deleted by the same run.
+// Entries written before runs were recorded fall back to their time, which
+// a run used to share.
+func (e Entry) SameRun(other Entry) bool {
+	if e.Run != "" || other.Run != "" {
+		return e.Run == other.Run
+	}
+	return e.Time.Equal(other.Time)
+}
+
+// NewRun returns a new run ID, to group the deletions of one run even when
+// they are recorded one at a time
+func NewRun() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// Unique enough within one repository's journal
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Path returns the location of the journal of the repository
+func Path(g *git.Git) (string, error) {
+	dir, err := g.CommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, File), nil
+}
+
+// Append records the deletions of run, stamped with the current time.
+// Entries without a known commit can't be undone and are left out.
+func Append(g *git.Git, run string, deleted []Entry) error {
+	path, err := Path(g)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open deletion journal: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	enc := json.NewEncoder(f)
+	for _, e := range deleted {
+		if e.Commit == "" {
+			continue
+		}
+		// Callers often have abbreviated hashes; the commits outlive the refs
+		if commit, err := g.ResolveCommit(e.Commit); err == nil {
+			e.Commit = commit
+		}
+		e.Time, e.Run = now, run
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to write deletion journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load reads the journal, oldest deletion first, returning nil if there is
+// none. Unreadable lines are skipped.
+func Load(g *git.Git) ([]Entry, error) {
+	path, err := Path(g)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deletion journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Debug("Skipping unreadable journal line: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read deletion journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Save replaces the journal with entries
+func Save(g *git.Git, entries []Entry) error {
+	path, err := Path(g)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write deletion journal: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write deletion journal: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write deletion journal: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write deletion journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package journal
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRoundTrip(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Local("feature/x")
+	full := r.Git("rev-parse", "feature/x")
+	g, err := git.New(r.Dir)
+	require.NoError(t, err)
+
+	entries, err := Load(g)
+	require.NoError(t, err)
+	assert.Nil(t, entries, "no journal yet")
+
+	require.NoError(t, Append(g, "run-1", []Entry{
+		{Branch: "feature/x", Commit: full[:7]},
+		{Branch: "feature/x", Remote: true, Commit: full[:7]},
+		{Branch: "unknown"}, // No commit to restore
+	}))
+	require.NoError(t, Append(g, "run-2", []Entry{{Branch: "feature/y", Commit: full}}))
+
+	// A line from a newer version or a crash is skipped
+	path, err := Path(g)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("{not json\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err = Load(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, full, entries[0].Commit, "abbreviated hashes are resolved")
+	assert.Equal(t, "run-1", entries[1].Run)
+	assert.True(t, entries[1].Remote)
+	assert.Equal(t, "feature/y", entries[2].Branch)
+	assert.WithinDuration(t, time.Now(), entries[2].Time, time.Minute)
+
+	require.NoError(t, Save(g, entries[2:]))
+	entries, err = Load(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "run-2", entries[0].Run)
+}
+
+func TestSameRun(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		a, b Entry
+		want bool
+	}{
+		{name: "same run", a: Entry{Run: "a", Time: now}, b: Entry{Run: "a", Time: now.Add(time.Minute)}, want: true},
+		{name: "other run", a: Entry{Run: "a", Time: now}, b: Entry{Run: "b", Time: now}},
+		{name: "recorded before runs", a: Entry{Time: now}, b: Entry{Time: now}, want: true},
+		{name: "recorded at other times", a: Entry{Time: now}, b: Entry{Time: now.Add(time.Second)}},
+		{name: "old and new", a: Entry{Time: now}, b: Entry{Run: "a", Time: now}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.a.SameRun(tt.b))
+		})
+	}
+	assert.NotEqual(t, NewRun(), NewRun())
+}
@@ -1,6 +1,7 @@
 package log
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -8,20 +9,27 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// MaxFileSize is the size a log file may reach before Init rotates it
+const MaxFileSize = 10 << 20
+
 var (
 	globalLogger zerolog.Logger
+	console      io.Writer
+	consoleOut   io.Writer = os.Stdout
+	noColor      bool
+	logFile      *os.File
 )
 
 func init() {
 	// Set up console writer with color support
-	output := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
+	console = zerolog.ConsoleWriter{
+		Out:        consoleOut,
 		TimeFormat: time.RFC3339,
-		NoColor:    false,
+		NoColor:    noColor,
 	}
 
 	// Initialize logger with console writer
-	globalLogger = zerolog.New(output).With().Timestamp().Logger()
+	globalLogger = zerolog.New(console).With().Timestamp().Logger()
 
 	// Set default level to info
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
@@ -72,6 +80,83 @@ func SetOutput(w io.Writer) {
 	globalLogger = zerolog.New(w).With().Timestamp().Logger()
 }
 
+// SetConsole moves console output to w, e.g. to stderr while stdout carries
+// a protocol. A log file set up by Init keeps receiving entries.
+func SetConsole(w io.Writer) {
+	consoleOut = w
+	console = zerolog.ConsoleWriter{
+		Out:        w,
+		TimeFormat: time.RFC3339,
+		NoColor:    noColor,
+	}
+
+	if logFile != nil {
+		globalLogger = zerolog.New(zerolog.MultiLevelWriter(console, logFile)).With().Timestamp().Logger()
+		return
+	}
+	globalLogger = zerolog.New(console).With().Timestamp().Logger()
+}
+
+// Console returns the writer console output goes to
+func Console() io.Writer {
+	return consoleOut
+}
+
+// SetNoColor turns colors in console output off or back on, e.g. for
+// consoles that don't interpret escape sequences
+func SetNoColor(off bool) {
+	noColor = off
+	SetConsole(consoleOut)
+}
+
+// Init additionally writes log entries as JSON lines to the file at path.
+// A file larger than MaxFileSize is first rotated to path + ".1", replacing
+// any earlier rotation. Call Close before exiting.
+func Init(path string) error {
+	if err := rotate(path, MaxFileSize); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	Close()
+	logFile = f
+	globalLogger = zerolog.New(zerolog.MultiLevelWriter(console, f)).With().Timestamp().Logger()
+	return nil
+}
+
+// Close stops writing to the log file set up by Init, if any
+func Close() error {
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	globalLogger = zerolog.New(console).With().Timestamp().Logger()
+	return err
+}
+
+// rotate moves the file at path to path + ".1" once it exceeds maxSize
+func rotate(path string, maxSize int64) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	if info.Size() <= maxSize {
+		return nil
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return nil
+}
+
 // Trace logs a trace message
 func Trace(msg string, args ...interface{}) {
 	globalLogger.Trace().Msgf(msg, args...)
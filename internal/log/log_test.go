@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		rotated bool
+	}{
+		{"below threshold", 10, false},
+		{"at threshold", 16, false},
+		{"above threshold", 17, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gbd.log")
+			require.NoError(t, os.WriteFile(path, make([]byte, tt.size), 0600))
+
+			require.NoError(t, rotate(path, 16))
+
+			_, err := os.Stat(path + ".1")
+			assert.Equal(t, tt.rotated, err == nil)
+			_, err = os.Stat(path)
+			assert.Equal(t, tt.rotated, os.IsNotExist(err))
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		assert.NoError(t, rotate(filepath.Join(t.TempDir(), "gbd.log"), 16))
+	})
+}
+
+func TestInit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gbd.log")
+	require.NoError(t, Init(path))
+
+	Info("hello %s", "file")
+	require.NoError(t, Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"message":"hello file"`)
+
+	// Entries after Close no longer reach the file
+	Info("not logged")
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestSetConsole(t *testing.T) {
+	var buf bytes.Buffer
+	SetConsole(&buf)
+	defer SetConsole(os.Stdout)
+
+	Info("hello %s", "console")
+	assert.Contains(t, buf.String(), "hello console")
+}
@@ -0,0 +1,94 @@
+// Package progress carries the progress of bulk operations to whatever
+// renders it, a terminal UI or an RPC client, as events on a channel, so
+// the operations themselves never print.
+//
+// An operation sends Started with the number of items, Completed or Failed
+// as each item finishes, and Finished last, whether or not it succeeded:
+//
+//	events := make(chan progress.Event[BranchResult])
+//	go func() {
+//		for ev := range events {
+//			render(ev)
+//		}
+//	}()
+//	res, err := Delete(g, DeleteOptions{Branches: names, Events: events})
+//	close(events)
+package progress
+
+// Kind is what an Event reports
+type Kind string
+
+// Kinds of events, in the order an operation sends them
+const (
+	Started   Kind = "started"   // Before the first item
+	Completed Kind = "completed" // An item succeeded
+	Failed    Kind = "failed"    // An item failed
+	Finished  Kind = "finished"  // After the last item, also when the operation fails
+)
+
+// Event is a step of an operation on items of type T
+type Event[T any] struct {
+	Kind Kind `json:"kind"`
+
+	// Done counts the items finished so far, Failed the ones of them that
+	// failed. Total is how many items the operation expects, which may be
+	// an upper bound when later items depend on earlier ones.
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+	Total  int `json:"total"`
+
+	// Item is the item that just finished, for Completed and Failed
+	Item *T `json:"item,omitempty"`
+}
+
+// Reporter sends the events of one operation, keeping count. Sends block
+// until the event is received, so a slow consumer slows the operation down
+// rather than events being dropped or piling up. The channel is never
+// closed; Finished is the last event. A Reporter is used from one
+// goroutine at a time.
+type Reporter[T any] struct {
+	events   chan<- Event[T]
+	counts   Event[T]
+	finished bool
+}
+
+// Start sends Started and returns the Reporter of the operation. With a
+// nil channel, nothing is sent.
+func Start[T any](events chan<- Event[T], total int) *Reporter[T] {
+	r := &Reporter[T]{events: events, counts: Event[T]{Total: total}}
+	r.send(Started, nil)
+	return r
+}
+
+// Completed reports an item that succeeded
+func (r *Reporter[T]) Completed(item T) {
+	r.counts.Done++
+	r.send(Completed, &item)
+}
+
+// Failed reports an item that failed
+func (r *Reporter[T]) Failed(item T) {
+	r.counts.Done++
+	r.counts.Failed++
+	r.send(Failed, &item)
+}
+
+// Finish sends Finished, once; later calls do nothing, so it can be
+// deferred and also called early
+func (r *Reporter[T]) Finish() {
+	if r.finished {
+		return
+	}
+	r.finished = true
+	r.send(Finished, nil)
+}
+
+func (r *Reporter[T]) send(kind Kind, item *T) {
+	if r.events == nil {
+		return
+	}
+	ev := r.counts
+	ev.Kind = kind
+	ev.Item = item
+	r.events <- ev
+}
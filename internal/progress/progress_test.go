@@ -0,0 +1,40 @@
+package progress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporter(t *testing.T) {
+	events := make(chan Event[string], 10)
+	r := Start(events, 3)
+	r.Completed("a")
+	r.Failed("b")
+	r.Completed("c")
+	r.Finish()
+	r.Finish()
+	close(events)
+
+	a, b, c := "a", "b", "c"
+	var got []Event[string]
+	for ev := range events {
+		got = append(got, ev)
+	}
+	assert.Equal(t, []Event[string]{
+		{Kind: Started, Total: 3},
+		{Kind: Completed, Done: 1, Total: 3, Item: &a},
+		{Kind: Failed, Done: 2, Failed: 1, Total: 3, Item: &b},
+		{Kind: Completed, Done: 3, Failed: 1, Total: 3, Item: &c},
+		{Kind: Finished, Done: 3, Failed: 1, Total: 3},
+	}, got)
+}
+
+func TestReporterWithoutChannel(t *testing.T) {
+	r := Start[string](nil, 2)
+	assert.NotPanics(t, func() {
+		r.Completed("a")
+		r.Failed("b")
+		r.Finish()
+	})
+}
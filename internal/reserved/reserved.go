@@ -0,0 +1,204 @@
+// Package reserved reads the protected branch lists teams share, so an
+// organization can keep the branches that must never be deleted in one
+// place: a file at the root of the repository's default branch, or a URL.
+//
+// A list is a YAML (or JSON) document with the patterns under "protected",
+// in path.Match syntax like the configured protected branches:
+//
+//	protected:
+//	  - main
+//	  - release/*
+//	  - hotfix/*
+//
+// A plain list of patterns works too.
+package reserved
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxSize is the largest list accepted, to keep a misconfigured URL from
+// filling memory
+const maxSize = 1 << 20
+
+// document is the structure of a list
+type document struct {
+	Protected []string `yaml:"protected"`
+}
+
+// Parse returns the patterns of a list, checking each is a valid pattern
+func Parse(data string) ([]string, error) {
+	var patterns []string
+	if err := yaml.Unmarshal([]byte(data), &patterns); err != nil {
+		var doc document
+		if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+			return nil, fmt.Errorf("invalid protected branch list: %w", err)
+		}
+		patterns = doc.Protected
+	}
+
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("protected branch list has an empty pattern")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in protected branch list: %w", p, err)
+		}
+	}
+	return patterns, nil
+}
+
+// List is a list read from a URL
+type List struct {
+	Patterns []string
+
+	// Stale is why the list couldn't be refreshed, when the copy kept from
+	// an earlier fetch is used instead
+	Stale error
+}
+
+// Client fetches lists from URLs. The last copy of each is kept in
+// CacheDir, used for MaxAge without asking again and after that whenever
+// the URL can't be reached, so a flaky server never drops the protection.
+type Client struct {
+	CacheDir string
+	MaxAge   time.Duration
+	Token    string // Sent as a bearer token, when set
+	HTTP     *http.Client
+}
+
+// NewClient returns a client keeping lists in cacheDir for an hour
+func NewClient(cacheDir, token string) *Client {
+	return &Client{
+		CacheDir: cacheDir,
+		MaxAge:   time.Hour,
+		Token:    token,
+		HTTP:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Fetch returns the list at url. It fails only when the list can't be
+// read and no earlier copy exists.
+func (c *Client) Fetch(ctx context.Context, url string) (List, error) {
+	cache := filepath.Join(c.CacheDir, fmt.Sprintf("%x.yaml", sha256.Sum256([]byte(url))))
+	cached, fetched, cacheErr := readCache(cache)
+	if cacheErr == nil && time.Since(fetched) < c.MaxAge {
+		return List{Patterns: cached}, nil
+	}
+
+	patterns, err := c.get(ctx, url)
+	if err == nil {
+		if err := writeCache(cache, patterns); err != nil {
+			return List{Patterns: patterns, Stale: err}, nil
+		}
+		return List{Patterns: patterns}, nil
+	}
+	if cacheErr != nil {
+		return List{}, err
+	}
+	return List{
+		Patterns: cached,
+		Stale:    fmt.Errorf("%w; using the copy from %s", err, fetched.Format(time.DateTime)),
+	}, nil
+}
+
+// get downloads and parses the list at url
+func (c *Client) get(ctx context.Context, url string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := c.HTTP
+	if c.Token != "" {
+		if req.URL.Scheme != "https" {
+			return nil, fmt.Errorf("refusing to send a token to %s over %s; use https", req.URL.Host, req.URL.Scheme)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+
+		// Nor over a redirect away from https
+		redirecting := *c.HTTP
+		redirecting.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow a redirect to %s over %s with a token", req.URL.Host, req.URL.Scheme)
+			}
+			if len(via) >= 10 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			return nil
+		}
+		client = &redirecting
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch protected branch list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to fetch protected branch list from %s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read protected branch list: %w", err)
+	}
+	if len(body) > maxSize {
+		return nil, fmt.Errorf("protected branch list at %s is larger than %d bytes", url, maxSize)
+	}
+	return Parse(string(body))
+}
+
+// readCache returns the patterns kept in file and when they were fetched
+func readCache(file string) ([]string, time.Time, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	patterns, err := Parse(string(data))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return patterns, info.ModTime(), nil
+}
+
+// writeCache keeps patterns in file, replacing it atomically
+func writeCache(file string, patterns []string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("failed to keep protected branch list: %w", err)
+	}
+	data, err := yaml.Marshal(document{Protected: patterns})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), "list.*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to keep protected branch list: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to keep protected branch list: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to keep protected branch list: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to keep protected branch list: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package reserved
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "document", data: "protected:\n  - main\n  - release/*\n", want: []string{"main", "release/*"}},
+		{name: "plain list", data: "- main\n- hotfix/*\n", want: []string{"main", "hotfix/*"}},
+		{name: "json", data: `{"protected": ["main"]}`, want: []string{"main"}},
+		{name: "empty", data: ""},
+		{name: "empty pattern", data: "protected: ['']", wantErr: true},
+		{name: "invalid pattern", data: "protected: ['release/[']", wantErr: true},
+		{name: "not a list", data: "protected: main", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+	list := "protected: [main, release/*]"
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if list == "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(list))
+	}))
+	defer server.Close()
+
+	c := NewClient(t.TempDir(), "secret")
+	c.HTTP = server.Client()
+	ctx := context.Background()
+
+	got, err := c.Fetch(ctx, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, List{Patterns: []string{"main", "release/*"}}, got)
+
+	t.Run("cached", func(t *testing.T) {
+		list = "protected: [main]"
+		got, err := c.Fetch(ctx, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main", "release/*"}, got.Patterns)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("refreshed", func(t *testing.T) {
+		c.MaxAge = 0
+		got, err := c.Fetch(ctx, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, List{Patterns: []string{"main"}}, got)
+	})
+
+	t.Run("unreachable uses the last copy", func(t *testing.T) {
+		list = ""
+		got, err := c.Fetch(ctx, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"main"}, got.Patterns)
+		assert.ErrorContains(t, got.Stale, "503")
+	})
+
+	t.Run("unreachable without a copy", func(t *testing.T) {
+		_, err := c.Fetch(ctx, server.URL+"/other")
+		assert.Error(t, err)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		anonymous := NewClient(t.TempDir(), "")
+		anonymous.HTTP = server.Client()
+		_, err := anonymous.Fetch(ctx, server.URL)
+		assert.ErrorContains(t, err, "401")
+	})
+}
+
+func TestFetchTokenNeedsHTTPS(t *testing.T) {
+	var sent []string
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("- main"))
+	}))
+	defer plain.Close()
+	redirect := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	c := NewClient(t.TempDir(), "secret")
+	c.HTTP = redirect.Client()
+	_, err := c.Fetch(context.Background(), plain.URL)
+	assert.ErrorContains(t, err, "use https")
+	_, err = c.Fetch(context.Background(), redirect.URL)
+	assert.ErrorContains(t, err, "refusing to follow a redirect")
+	assert.Empty(t, sent)
+
+	// Lists that need no token can be read over http
+	c.Token = ""
+	got, err := c.Fetch(context.Background(), plain.URL)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main"}, got.Patterns)
+}
+
+func TestFetchKeepsCacheFresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("- main"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	c := NewClient(dir, "")
+	_, err := c.Fetch(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+
+	_, err = c.Fetch(context.Background(), server.URL)
+	require.NoError(t, err)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute)
+}
@@ -0,0 +1,220 @@
+// Package rpc is a minimal JSON-RPC 2.0 server for editor integrations.
+// Messages are framed with Content-Length headers like the Language Server
+// Protocol, so editors can reuse their LSP client libraries.
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the JSON-RPC version spoken
+const Version = "2.0"
+
+// Error codes defined by JSON-RPC 2.0
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// maxMessageSize bounds the Content-Length a client may announce
+const maxMessageSize = 16 << 20
+
+// Error is a JSON-RPC error. Handlers return it to choose the code sent;
+// any other error is sent as an internal error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Handler answers a request. Its result is sent back as JSON; for
+// notifications, which carry no ID, it is dropped.
+type Handler func(ctx context.Context, conn *Conn, params json.RawMessage) (interface{}, error)
+
+// request is an incoming request or notification
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response answers a request; exactly one of Result and Error is set
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a message the server sends without expecting an answer
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Server dispatches requests to the handlers registered for their method
+type Server struct {
+	handlers map[string]Handler
+}
+
+// NewServer returns a server without any methods
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]Handler)}
+}
+
+// Handle registers the handler for a method
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+// Serve answers the requests read from r on w, one at a time, until r is
+// exhausted or ctx is cancelled
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn := &Conn{w: w}
+	reader := bufio.NewReader(r)
+
+	for ctx.Err() == nil {
+		data, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			conn.reply(nil, nil, &Error{Code: CodeParseError, Message: err.Error()})
+			continue
+		}
+		if req.JSONRPC != Version || req.Method == "" {
+			conn.reply(req.ID, nil, &Error{Code: CodeInvalidRequest, Message: "not a JSON-RPC 2.0 request"})
+			continue
+		}
+
+		result, err := s.dispatch(ctx, conn, &req)
+		if req.ID == nil {
+			continue // Notifications get no answer, not even errors
+		}
+		if err := conn.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// dispatch runs the handler of a request
+func (s *Server) dispatch(ctx context.Context, conn *Conn, req *request) (interface{}, error) {
+	h, ok := s.handlers[req.Method]
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+	return h(ctx, conn, req.Params)
+}
+
+// DecodeParams decodes request parameters into v. Missing parameters leave
+// v unchanged; malformed ones are an invalid params error.
+func DecodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// Conn writes messages to the client. It is safe for concurrent use, so
+// handlers may send notifications from other goroutines.
+type Conn struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Notify sends a notification to the client
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.write(notification{JSONRPC: Version, Method: method, Params: params})
+}
+
+// reply answers the request with the given ID
+func (c *Conn) reply(id json.RawMessage, result interface{}, err error) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	res := response{JSONRPC: Version, ID: id}
+
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		res.Error = rpcErr
+		return c.write(res)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		res.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		return c.write(res)
+	}
+	res.Result = data
+	return c.write(res)
+}
+
+// write sends one framed message
+func (c *Conn) write(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads the body of one framed message. Headers other than
+// Content-Length, such as Content-Type, are ignored.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", header.Get("Content-Length"))
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the %d byte limit", length, maxMessageSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read message body: %w", err)
+	}
+	return body, nil
+}
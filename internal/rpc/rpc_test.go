@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame wraps message bodies in Content-Length headers
+func frame(bodies ...string) string {
+	var b strings.Builder
+	for _, body := range bodies {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	return b.String()
+}
+
+// readAll decodes every message the server wrote
+func readAll(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var msgs []map[string]interface{}
+	r := bufio.NewReader(out)
+	for {
+		data, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &msg))
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestServe(t *testing.T) {
+	s := NewServer()
+	s.Handle("echo", func(ctx context.Context, conn *Conn, params json.RawMessage) (interface{}, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		require.NoError(t, conn.Notify("progress", map[string]string{"text": p.Text}))
+		return p, nil
+	})
+	s.Handle("fail", func(ctx context.Context, conn *Conn, params json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("boom")
+	})
+
+	tests := []struct {
+		name string
+		in   string
+		want []string // JSON of the expected messages
+	}{
+		{
+			name: "request with notification",
+			in:   `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+			want: []string{
+				`{"jsonrpc":"2.0","method":"progress","params":{"text":"hi"}}`,
+				`{"jsonrpc":"2.0","id":1,"result":{"text":"hi"}}`,
+			},
+		},
+		{
+			name: "unknown method",
+			in:   `{"jsonrpc":"2.0","id":"a","method":"nope"}`,
+			want: []string{`{"jsonrpc":"2.0","id":"a","error":{"code":-32601,"message":"method not found: nope"}}`},
+		},
+		{
+			name: "invalid params",
+			in:   `{"jsonrpc":"2.0","id":2,"method":"echo","params":[1]}`,
+			want: []string{`{"jsonrpc":"2.0","id":2,"error":{"code":-32602}}`},
+		},
+		{
+			name: "handler error",
+			in:   `{"jsonrpc":"2.0","id":3,"method":"fail"}`,
+			want: []string{`{"jsonrpc":"2.0","id":3,"error":{"code":-32603,"message":"boom"}}`},
+		},
+		{
+			name: "notification gets no answer",
+			in:   `{"jsonrpc":"2.0","method":"fail"}`,
+		},
+		{
+			name: "parse error",
+			in:   `{`,
+			want: []string{`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"unexpected end of JSON input"}}`},
+		},
+		{
+			name: "wrong version",
+			in:   `{"jsonrpc":"1.0","id":4,"method":"echo"}`,
+			want: []string{`{"jsonrpc":"2.0","id":4,"error":{"code":-32600,"message":"not a JSON-RPC 2.0 request"}}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, s.Serve(context.Background(), strings.NewReader(frame(tt.in)), &out))
+
+			got := readAll(t, &out)
+			require.Len(t, got, len(tt.want))
+			for i, want := range tt.want {
+				var msg map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(want), &msg))
+				// Without an expected message, only the error code matters
+				if e, ok := msg["error"].(map[string]interface{}); ok && e["message"] == nil {
+					delete(got[i]["error"].(map[string]interface{}), "message")
+				}
+				assert.Equal(t, msg, got[i])
+			}
+		})
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"with content type", "Content-Length: 2\r\nContent-Type: application/vscode-jsonrpc\r\n\r\n{}", "{}", false},
+		{"missing length", "Content-Type: x\r\n\r\n{}", "", true},
+		{"short body", "Content-Length: 10\r\n\r\n{}", "", true},
+		{"too large", "Content-Length: 999999999\r\n\r\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := readMessage(bufio.NewReader(strings.NewReader(tt.in)))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package scenario builds repositories whose branches are in known states,
+// such as merged, stale or checked out in a worktree. They serve as demos
+// and as fixtures for integration tests.
+//
+// Builds are deterministic: names, emails and dates are fixed, so the same
+// scenarios always produce the same commit hashes.
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Scenarios, each creating a branch of the same name
+const (
+	Merged             = "merged"               // Merged into main and pushed
+	Stale              = "stale"                // Upstream deleted from origin
+	Unmerged           = "unmerged"             // Has a commit main lacks, never pushed
+	RemoteOnly         = "remote-only"          // Only on origin
+	Diverged           = "diverged"             // Ahead of and behind its upstream
+	WorktreeCheckedOut = "worktree-checked-out" // Checked out in a linked worktree
+)
+
+// All lists every scenario in the order Build applies them
+var All = []string{Merged, Stale, Unmerged, RemoteOnly, Diverged, WorktreeCheckedOut}
+
+// epoch is the date of the first commit; each later one is a minute newer
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Repo is a built scenario repository
+type Repo struct {
+	Dir      string // Working clone, on main
+	Origin   string // Bare repository origin points at
+	Worktree string // Linked worktree, when WorktreeCheckedOut was built
+
+	commits int // Commits made so far, which dates the next one
+}
+
+// builder runs the git commands of a build
+type builder struct {
+	repo *Repo
+}
+
+// Build creates a repository exhibiting the given scenarios under root,
+// which must not exist yet or be empty: the clone in root/work, origin in
+// root/origin.git and any worktree in root/worktree.
+func Build(root string, scenarios ...string) (*Repo, error) {
+	steps := map[string]func(*builder) error{
+		Merged:             (*builder).merged,
+		Stale:              (*builder).stale,
+		Unmerged:           (*builder).unmerged,
+		RemoteOnly:         (*builder).remoteOnly,
+		Diverged:           (*builder).diverged,
+		WorktreeCheckedOut: (*builder).worktreeCheckedOut,
+	}
+	wanted := make(map[string]bool, len(scenarios))
+	for _, s := range scenarios {
+		if steps[s] == nil {
+			return nil, fmt.Errorf("unknown scenario %q (expected one of: %s)", s, strings.Join(All, ", "))
+		}
+		wanted[s] = true
+	}
+
+	if entries, err := os.ReadDir(root); err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s is not empty", root)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	b := &builder{repo: &Repo{
+		Dir:    filepath.Join(root, "work"),
+		Origin: filepath.Join(root, "origin.git"),
+	}}
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+
+	// Apply in a fixed order so hashes don't depend on the argument order
+	for _, s := range All {
+		if !wanted[s] {
+			continue
+		}
+		if err := steps[s](b); err != nil {
+			return nil, fmt.Errorf("failed to build scenario %s: %w", s, err)
+		}
+	}
+	return b.repo, nil
+}
+
+// init creates origin and the clone with an initial commit on main
+func (b *builder) init() error {
+	if err := os.MkdirAll(b.repo.Dir, 0755); err != nil {
+		return err
+	}
+	if err := b.run("", "init", "--quiet", "--bare", "--initial-branch=main", b.repo.Origin); err != nil {
+		return err
+	}
+	return b.script(
+		[]string{"init", "--quiet", "--initial-branch=main"},
+		[]string{"remote", "add", "origin", b.repo.Origin},
+		b.commit("Initial commit"),
+		[]string{"push", "--quiet", "--set-upstream", "origin", "main"},
+	)
+}
+
+func (b *builder) merged() error {
+	return b.script(
+		[]string{"checkout", "--quiet", "-b", Merged},
+		b.commit("Add merged feature"),
+		[]string{"push", "--quiet", "--set-upstream", "origin", Merged},
+		[]string{"checkout", "--quiet", "main"},
+		b.commit("Merge branch 'merged'", "--no-ff", Merged),
+		[]string{"push", "--quiet", "origin", "main"},
+	)
+}
+
+func (b *builder) stale() error {
+	return b.script(
+		[]string{"checkout", "--quiet", "-b", Stale},
+		b.commit("Add stale feature"),
+		[]string{"push", "--quiet", "--set-upstream", "origin", Stale},
+		[]string{"checkout", "--quiet", "main"},
+		[]string{"push", "--quiet", "origin", "--delete", Stale},
+	)
+}
+
+func (b *builder) unmerged() error {
+	return b.script(
+		[]string{"checkout", "--quiet", "-b", Unmerged},
+		b.commit("Add unmerged work"),
+		[]string{"checkout", "--quiet", "main"},
+	)
+}
+
+func (b *builder) remoteOnly() error {
+	return b.script(
+		[]string{"checkout", "--quiet", "-b", RemoteOnly},
+		b.commit("Add a teammate's work"),
+		[]string{"push", "--quiet", "origin", RemoteOnly},
+		[]string{"checkout", "--quiet", "main"},
+		[]string{"branch", "--quiet", "-D", RemoteOnly},
+	)
+}
+
+func (b *builder) diverged() error {
+	return b.script(
+		[]string{"checkout", "--quiet", "-b", Diverged},
+		b.commit("Add diverged feature"),
+		b.commit("Push a commit from elsewhere"),
+		[]string{"push", "--quiet", "--set-upstream", "origin", Diverged},
+		[]string{"reset", "--quiet", "--hard", "HEAD~1"},
+		b.commit("Add local work"),
+		[]string{"checkout", "--quiet", "main"},
+	)
+}
+
+func (b *builder) worktreeCheckedOut() error {
+	b.repo.Worktree = filepath.Join(filepath.Dir(b.repo.Dir), "worktree")
+	return b.script(
+		[]string{"branch", WorktreeCheckedOut},
+		[]string{"worktree", "add", "--quiet", b.repo.Worktree, WorktreeCheckedOut},
+	)
+}
+
+// commit returns the arguments of an empty commit, or of a merge when args
+// are given. Each commit is dated a minute after the previous one.
+func (b *builder) commit(message string, args ...string) []string {
+	if len(args) > 0 {
+		return append([]string{"merge", "--quiet", "-m", message}, args...)
+	}
+	return []string{"commit", "--quiet", "--allow-empty", "-m", message}
+}
+
+// script runs git commands in the clone, stopping at the first failure
+func (b *builder) script(commands ...[]string) error {
+	for _, args := range commands {
+		if err := b.run(b.repo.Dir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs git in dir for the build
+func (b *builder) run(dir string, args ...string) error {
+	_, err := b.repo.git(dir, args...)
+	return err
+}
+
+// Git runs git in the clone the way builds do, with a fixed identity and
+// commit dates continuing the build's, and returns its trimmed output.
+// Tests use it to take a scenario further while staying deterministic.
+func (r *Repo) Git(args ...string) (string, error) {
+	return r.git(r.Dir, args...)
+}
+
+// git runs git in dir with a fixed identity and date, ignoring the user's
+// configuration and GIT_ environment variables
+func (r *Repo) git(dir string, args ...string) (string, error) {
+	if args[0] == "commit" || args[0] == "merge" {
+		r.commits++
+	}
+	date := epoch.Add(time.Duration(r.commits) * time.Minute).Format(time.RFC3339)
+
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=Scenario",
+		"-c", "user.email=scenario@example.com",
+		"-c", "commit.gpgsign=false",
+	}, args...)...)
+	cmd.Dir = dir
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, "GIT_") {
+			cmd.Env = append(cmd.Env, e)
+		}
+	}
+	cmd.Env = append(cmd.Env,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_DATE="+date,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refs returns the branches of a repository with their commits
+func refs(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "for-each-ref", "--format=%(refname) %(objectname)", "refs/heads", "refs/remotes").Output()
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestBuildDeterministic(t *testing.T) {
+	a, err := Build(filepath.Join(t.TempDir(), "a"), All...)
+	require.NoError(t, err)
+	b, err := Build(filepath.Join(t.TempDir(), "b"), Diverged, Merged, Stale, Unmerged, RemoteOnly, WorktreeCheckedOut)
+	require.NoError(t, err)
+
+	assert.Equal(t, refs(t, a.Dir), refs(t, b.Dir))
+	assert.NotEmpty(t, a.Worktree)
+}
+
+func TestBuildStates(t *testing.T) {
+	repo, err := Build(t.TempDir(), All...)
+	require.NoError(t, err)
+
+	out := refs(t, repo.Dir)
+	assert.Contains(t, out, "refs/heads/merged ")
+	assert.Contains(t, out, "refs/remotes/origin/remote-only ")
+	assert.NotContains(t, out, "refs/heads/remote-only ")
+	assert.NotContains(t, out, "refs/remotes/origin/stale ")
+
+	track, err := exec.Command("git", "-C", repo.Dir, "for-each-ref", "--format=%(refname:short) %(upstream:track)", "refs/heads").Output()
+	require.NoError(t, err)
+	lines := strings.Split(string(track), "\n")
+	assert.Contains(t, lines, "diverged [ahead 1, behind 1]")
+	assert.Contains(t, lines, "stale [gone]")
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := Build(t.TempDir(), "nope")
+	assert.ErrorContains(t, err, `unknown scenario "nope"`)
+
+	dir := t.TempDir()
+	_, err = Build(dir)
+	require.NoError(t, err)
+	_, err = Build(dir)
+	assert.ErrorContains(t, err, "not empty")
+}
@@ -0,0 +1,117 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// commandKeychain stores secrets through the platform's keychain tool:
+// security on macOS and secret-tool (libsecret) elsewhere
+type commandKeychain struct {
+	// run executes a tool with input on stdin and returns its stdout
+	run func(input string, name string, args ...string) (string, error)
+	// macOS selects the security tool instead of secret-tool
+	macOS bool
+}
+
+func newKeychain() keychain {
+	return &commandKeychain{run: runTool, macOS: runtime.GOOS == "darwin"}
+}
+
+// runTool runs a keychain tool, reporting ErrUnsupported when it isn't
+// installed
+func runTool(input string, name string, args ...string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.Output()
+	return strings.TrimRight(string(out), "\n"), err
+}
+
+// notFound reports whether err is the tool's exit status for a missing
+// item: 44 for security, 1 for secret-tool
+func (k *commandKeychain) notFound(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	if k.macOS {
+		return exitErr.ExitCode() == 44
+	}
+	return exitErr.ExitCode() == 1
+}
+
+func (k *commandKeychain) get(account string) (string, error) {
+	var out string
+	var err error
+	if k.macOS {
+		out, err = k.run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	} else {
+		out, err = k.run("", "secret-tool", "lookup", "service", Service, "account", account)
+	}
+	if k.notFound(err) || (err == nil && out == "") {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (k *commandKeychain) set(account, secret string) error {
+	if k.macOS {
+		// security only takes the password as an argument, which other
+		// users can read in the process list. In interactive mode the
+		// command line is read from stdin instead; -U replaces an existing
+		// item.
+		if strings.ContainsAny(secret, "\r\n") {
+			return errors.New("token cannot contain line breaks")
+		}
+		command := strings.Join([]string{"add-generic-password", "-U", "-s", quoteArg(Service), "-a", quoteArg(account), "-w", quoteArg(secret)}, " ")
+		if _, err := k.run(command+"\n", "security", "-i"); err != nil {
+			return err
+		}
+		// security -i succeeds whatever its commands do, so check the
+		// item is there
+		stored, err := k.get(account)
+		if err != nil {
+			return fmt.Errorf("failed to store token in the keychain: %w", err)
+		}
+		if stored != secret {
+			return errors.New("failed to store token in the keychain")
+		}
+		return nil
+	}
+	_, err := k.run(secret, "secret-tool", "store", "--label", Service+" token for "+account, "service", Service, "account", account)
+	return err
+}
+
+func (k *commandKeychain) delete(account string) error {
+	var err error
+	if k.macOS {
+		_, err = k.run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+	} else {
+		// secret-tool clear succeeds even when nothing matched
+		if _, err = k.get(account); err != nil {
+			return err
+		}
+		_, err = k.run("", "secret-tool", "clear", "service", Service, "account", account)
+	}
+	if k.notFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// quoteArg quotes an argument of a security -i command line
+func quoteArg(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
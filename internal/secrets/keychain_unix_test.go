@@ -0,0 +1,95 @@
+//go:build !windows
+
+package secrets
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandKeychain(t *testing.T) {
+	tests := []struct {
+		name  string
+		macOS bool
+		want  []string // Commands run by set, get and delete
+	}{
+		{
+			name: "secret-tool",
+			want: []string{
+				"secret-tool store --label git-branch-delete token for github.com service git-branch-delete account github.com <- token",
+				"secret-tool lookup service git-branch-delete account github.com",
+				"secret-tool lookup service git-branch-delete account github.com",
+				"secret-tool clear service git-branch-delete account github.com",
+			},
+		},
+		{
+			name:  "security",
+			macOS: true,
+			want: []string{
+				"security -i <- add-generic-password -U -s \"git-branch-delete\" -a \"github.com\" -w \"token\"\n",
+				"security find-generic-password -s git-branch-delete -a github.com -w",
+				"security find-generic-password -s git-branch-delete -a github.com -w",
+				"security delete-generic-password -s git-branch-delete -a github.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			k := &commandKeychain{macOS: tt.macOS, run: func(input, name string, args ...string) (string, error) {
+				cmd := strings.Join(append([]string{name}, args...), " ")
+				if input != "" {
+					cmd += " <- " + input
+				}
+				ran = append(ran, cmd)
+				return "token", nil
+			}}
+
+			require.NoError(t, k.set("github.com", "token"))
+			secret, err := k.get("github.com")
+			require.NoError(t, err)
+			assert.Equal(t, "token", secret)
+			require.NoError(t, k.delete("github.com"))
+			assert.Equal(t, tt.want, ran)
+		})
+	}
+}
+
+func TestCommandKeychainSecretOffArgv(t *testing.T) {
+	var stdin string
+	k := &commandKeychain{macOS: true, run: func(input, name string, args ...string) (string, error) {
+		if len(args) > 0 && args[0] == "-i" {
+			stdin = input
+			return "", nil
+		}
+		assert.NotContains(t, strings.Join(args, " "), `to"k\en`)
+		return `to"k\en`, nil
+	}}
+	require.NoError(t, k.set("github.com", `to"k\en`))
+	assert.Contains(t, stdin, `-w "to\"k\\en"`)
+
+	assert.Error(t, k.set("github.com", "two\nlines"))
+
+	// The token read back differs: security -i failed silently
+	k.run = func(string, string, ...string) (string, error) { return "other", nil }
+	assert.Error(t, k.set("github.com", "token"))
+}
+
+func TestCommandKeychainNotFound(t *testing.T) {
+	// secret-tool lookup prints nothing and exits 1 when nothing matches
+	k := &commandKeychain{run: func(string, string, ...string) (string, error) {
+		return "", exec.Command("false").Run()
+	}}
+	_, err := k.get("github.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, k.delete("github.com"), ErrNotFound)
+
+	k.run = func(string, string, ...string) (string, error) { return "", ErrUnsupported }
+	_, err = k.get("github.com")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,110 @@
+//go:build windows
+
+package secrets
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+// Windows Credential Manager constants, see wincred.h
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+var (
+	advapi32     = syscall.NewLazyDLL("advapi32.dll")
+	procRead     = advapi32.NewProc("CredReadW")
+	procWrite    = advapi32.NewProc("CredWriteW")
+	procDelete   = advapi32.NewProc("CredDeleteW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+// credential mirrors CREDENTIALW
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager stores secrets as generic Windows credentials named
+// "<Service>:<account>"
+type credentialManager struct{}
+
+func newKeychain() keychain {
+	return credentialManager{}
+}
+
+func target(account string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(Service + ":" + account)
+}
+
+func (credentialManager) get(account string) (string, error) {
+	name, err := target(account)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	ok, _, err := procRead.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		if errors.Is(err, errorNotFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (credentialManager) set(account, secret string) error {
+	name, err := target(account)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         name,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     &blob[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if ok, _, err := procWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); ok == 0 {
+		return err
+	}
+	return nil
+}
+
+func (credentialManager) delete(account string) error {
+	name, err := target(account)
+	if err != nil {
+		return err
+	}
+	if ok, _, err := procDelete.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0); ok == 0 {
+		if errors.Is(err, errorNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
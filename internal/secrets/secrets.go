@@ -0,0 +1,80 @@
+// Package secrets stores hosting provider API tokens in the OS keychain
+// (macOS Keychain, the Secret Service on Linux and Windows Credential
+// Manager) so they never end up in plaintext configuration files.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Service is the keychain service tokens are stored under
+const Service = "git-branch-delete"
+
+var (
+	// ErrNotFound is returned when no token is stored for a host
+	ErrNotFound = errors.New("no token stored")
+
+	// ErrUnsupported is returned when the OS keychain can't be used, e.g.
+	// on Linux without secret-tool
+	ErrUnsupported = errors.New("no OS keychain available")
+)
+
+// keychain is an OS credential store holding one secret per account
+type keychain interface {
+	get(account string) (string, error)
+	set(account, secret string) error
+	delete(account string) error
+}
+
+// store is the keychain of the current OS
+var store keychain = newKeychain()
+
+// Get returns the token stored for a provider host such as github.com
+func Get(host string) (string, error) {
+	account, err := accountFor(host)
+	if err != nil {
+		return "", err
+	}
+	return store.get(account)
+}
+
+// Set stores the token for a provider host, replacing any previous one
+func Set(host, token string) error {
+	account, err := accountFor(host)
+	if err != nil {
+		return err
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("token cannot be empty")
+	}
+	return store.set(account, token)
+}
+
+// Delete removes the token stored for a provider host
+func Delete(host string) error {
+	account, err := accountFor(host)
+	if err != nil {
+		return err
+	}
+	return store.delete(account)
+}
+
+// Mask hides all but the last four characters of a token
+func Mask(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", 8) + token[len(token)-4:]
+}
+
+// accountFor normalizes a host into the keychain account name
+func accountFor(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" || strings.ContainsAny(host, " /\\\t\n") {
+		return "", fmt.Errorf("invalid host %q", host)
+	}
+	return host, nil
+}
@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryKeychain is an in-memory keychain for tests
+type memoryKeychain map[string]string
+
+func (m memoryKeychain) get(account string) (string, error) {
+	secret, ok := m[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m memoryKeychain) set(account, secret string) error {
+	m[account] = secret
+	return nil
+}
+
+func (m memoryKeychain) delete(account string) error {
+	if _, ok := m[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m, account)
+	return nil
+}
+
+func TestTokens(t *testing.T) {
+	saved := store
+	defer func() { store = saved }()
+	store = memoryKeychain{}
+
+	_, err := Get("github.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, Set(" GitHub.com ", "ghp_secret\n"))
+	token, err := Get("github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_secret", token)
+
+	assert.Error(t, Set("github.com", "  "))
+	assert.Error(t, Set("github.com/org", "token"))
+
+	require.NoError(t, Delete("github.com"))
+	assert.ErrorIs(t, Delete("github.com"), ErrNotFound)
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "********cret", Mask("ghp_secret"))
+	assert.Equal(t, "***", Mask("abc"))
+}
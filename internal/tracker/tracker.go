@@ -0,0 +1,160 @@
+// Package tracker reads the status of tickets from an issue tracker's JSON
+// API, such as Jira's or GitHub's issues, configured by a URL template and
+// the path of the status field in the response.
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TicketPlaceholder stands for the ticket ID in URL templates
+const TicketPlaceholder = "{ticket}"
+
+// concurrency is how many tickets are looked up at once
+const concurrency = 4
+
+// Client reads ticket statuses
+type Client struct {
+	// URL is the API URL of a ticket, with TicketPlaceholder, e.g.
+	// https://jira.example.com/rest/api/2/issue/{ticket}?fields=status
+	URL string
+
+	// StatusField is the dot-separated path of the status in the JSON
+	// response, e.g. "fields.status.name"
+	StatusField string
+
+	Token string // Sent as a bearer token, when set
+	HTTP  *http.Client
+}
+
+// NewClient returns a client for the API at urlTemplate
+func NewClient(urlTemplate, statusField, token string) *Client {
+	return &Client{
+		URL:         urlTemplate,
+		StatusField: statusField,
+		Token:       token,
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Status returns the status of a ticket
+func (c *Client) Status(ctx context.Context, ticket string) (string, error) {
+	u := strings.ReplaceAll(c.URL, TicketPlaceholder, url.PathEscape(ticket))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("tracker request for %s failed: %w", ticket, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("tracker lookup of %s: %s%s", ticket, resp.Status, summary(msg))
+	}
+	var body interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode tracker response for %s: %w", ticket, err)
+	}
+	status, err := Field(body, c.StatusField)
+	if err != nil {
+		return "", fmt.Errorf("tracker response for %s: %w", ticket, err)
+	}
+	return status, nil
+}
+
+// Statuses looks up several tickets, a few at a time, returning the status
+// of each. Tickets that fail are left out and their errors joined.
+func (c *Client) Statuses(ctx context.Context, tickets []string) (map[string]string, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]string, len(tickets))
+		errs     []string
+		slots    = make(chan struct{}, concurrency)
+	)
+	for _, ticket := range tickets {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(ticket string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			status, err := c.Status(ctx, ticket)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err.Error())
+				return
+			}
+			statuses[ticket] = status
+		}(ticket)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return statuses, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return statuses, nil
+}
+
+// Field returns the string at a dot-separated path of a decoded JSON
+// value, e.g. "fields.status.name". Numbers index arrays.
+func Field(v interface{}, path string) (string, error) {
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return "", fmt.Errorf("no %q in %s", key, path)
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("no element %q in %s", key, path)
+			}
+			v = node[i]
+		default:
+			return "", fmt.Errorf("%s doesn't lead to a value", path)
+		}
+	}
+
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case bool, float64:
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("%s is not a string", path)
+	}
+}
+
+// summary returns the start of an error response on one line, prefixed by
+// ": ", or nothing for empty and HTML responses
+func summary(body []byte) string {
+	msg := strings.Join(strings.Fields(string(body)), " ")
+	if msg == "" || strings.HasPrefix(msg, "<") {
+		return ""
+	}
+	if len(msg) > 200 {
+		msg = msg[:200] + "..."
+	}
+	return ": " + msg
+}
@@ -0,0 +1,76 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestField(t *testing.T) {
+	var body interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"fields": {"status": {"name": "Done"}, "labels": ["a", "b"], "resolved": true, "points": 3}
+	}`), &body))
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "fields.status.name", want: "Done"},
+		{path: "fields.labels.1", want: "b"},
+		{path: "fields.resolved", want: "true"},
+		{path: "fields.points", want: "3"},
+		{path: "fields.status", wantErr: true},
+		{path: "fields.missing", wantErr: true},
+		{path: "fields.labels.2", wantErr: true},
+		{path: "fields.status.name.more", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Field(body, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	statuses := map[string]string{"PROJ-1": "Done", "PROJ 2": "In Progress"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		status, ok := statuses[strings.TrimPrefix(r.URL.Path, "/issue/")]
+		if !ok {
+			http.Error(w, "no such issue", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"state": map[string]string{"name": status}})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL+"/issue/"+TicketPlaceholder, "state.name", "secret")
+	status, err := c.Status(context.Background(), "PROJ-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Done", status)
+
+	got, err := c.Statuses(context.Background(), []string{"PROJ-1", "PROJ 2", "PROJ-3"})
+	assert.ErrorContains(t, err, "404 Not Found: no such issue")
+	assert.Equal(t, map[string]string{"PROJ-1": "Done", "PROJ 2": "In Progress"}, got)
+
+	c.Token = ""
+	_, err = c.Status(context.Background(), "PROJ-1")
+	assert.ErrorContains(t, err, "401 Unauthorized")
+}
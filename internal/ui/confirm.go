@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+
+	"github.com/fatih/color"
+)
+
+// ShowBanner prints the configured banner, if any, before a destructive operation
+func ShowBanner(c config.Confirmation) {
+	if c.Banner != "" {
+		color.Yellow(c.Banner)
+		fmt.Println()
+	}
+}
+
+// ConfirmDestructive shows the banner and asks for confirmation of an
+// operation affecting count branches, using the configured mode
+func ConfirmDestructive(p Prompter, c config.Confirmation, message string, count int) (bool, error) {
+	ShowBanner(c)
+
+	switch c.Mode {
+	case "", config.ConfirmYesNo:
+		return p.Confirm(message, false)
+	case config.ConfirmCount:
+		answer, err := p.Input(fmt.Sprintf("%s Type %d to confirm", message, count))
+		if err != nil {
+			return false, err
+		}
+		return answer == strconv.Itoa(count), nil
+	case config.ConfirmPhrase:
+		answer, err := p.Input(fmt.Sprintf("%s Type %q to confirm", message, c.Phrase))
+		if err != nil {
+			return false, err
+		}
+		return answer == c.Phrase, nil
+	default:
+		return false, fmt.Errorf("invalid confirmation mode: %s", c.Mode)
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmDestructive(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    config.Confirmation
+		input   string
+		want    bool
+		wantErr bool
+	}{
+		{"default mode yes", config.Confirmation{}, "y\n", true, false},
+		{"yesno no", config.Confirmation{Mode: config.ConfirmYesNo}, "\n", false, false},
+		{"count match", config.Confirmation{Mode: config.ConfirmCount}, "3\n", true, false},
+		{"count mismatch", config.Confirmation{Mode: config.ConfirmCount}, "y\n", false, false},
+		{"phrase match", config.Confirmation{Mode: config.ConfirmPhrase, Phrase: "delete them"}, "delete them\n", true, false},
+		{"phrase mismatch", config.Confirmation{Mode: config.ConfirmPhrase, Phrase: "delete them"}, "Delete Them\n", false, false},
+		{"unknown mode", config.Confirmation{Mode: "shout"}, "y\n", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewLinePrompter(strings.NewReader(tt.input), &out)
+			got, err := ConfirmDestructive(p, tt.conf, "Delete 3 branches?", 3)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, out.String(), "Delete 3 branches?")
+		})
+	}
+}
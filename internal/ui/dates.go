@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// isoDateLayout is used when the locale doesn't suggest another date order
+const isoDateLayout = "2006-01-02"
+
+// dateLayouts are the numeric date layouts of locales, by region or, when
+// the region isn't listed, by language
+var dateLayouts = map[string]string{
+	// Month first
+	"US": "01/02/2006",
+	"PH": "01/02/2006",
+	"BZ": "01/02/2006",
+	// Year first
+	"ja": "2006/01/02",
+	"zh": "2006/01/02",
+	"ko": "2006.01.02.",
+	"hu": "2006.01.02.",
+	"lt": "2006-01-02",
+	"sv": "2006-01-02",
+	// Day first, dotted
+	"de": "02.01.2006",
+	"ru": "02.01.2006",
+	"pl": "02.01.2006",
+	"cs": "02.01.2006",
+	"fi": "02.01.2006",
+	"nb": "02.01.2006",
+	"tr": "02.01.2006",
+	"uk": "02.01.2006",
+	// Day first, dashed
+	"nl": "02-01-2006",
+	"da": "02-01-2006",
+	// Day first, slashed
+	"en": "02/01/2006",
+	"fr": "02/01/2006",
+	"es": "02/01/2006",
+	"it": "02/01/2006",
+	"pt": "02/01/2006",
+	"el": "02/01/2006",
+}
+
+// Locale returns the locale dates are formatted for, from LC_ALL, LC_TIME or
+// LANG in the order the C library checks them, e.g. "de_DE.UTF-8"
+func Locale() string {
+	for _, name := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// DateLayout returns the time layout of numeric dates in locale, e.g.
+// "02.01.2006" for de_DE.UTF-8. The C and POSIX locales and unknown ones
+// use ISO 8601 dates.
+func DateLayout(locale string) string {
+	// Strip the encoding and modifier: language_REGION.codeset@modifier
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	lang, region, _ := strings.Cut(locale, "_")
+
+	if layout, ok := dateLayouts[region]; ok && region != "" {
+		return layout
+	}
+	if layout, ok := dateLayouts[lang]; ok {
+		return layout
+	}
+	return isoDateLayout
+}
+
+// RelativeTime describes how long before now t was, the way git does, e.g.
+// "3 weeks ago"
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	if d < 0 {
+		return "in the future"
+	}
+
+	days := int(d.Hours() / 24)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return ago(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return ago(int(d.Hours()), "hour")
+	case days < 14:
+		return ago(days, "day")
+	case days < 70:
+		return ago(days/7, "week")
+	case days < 365:
+		return ago(days/30, "month")
+	default:
+		return ago(days/365, "year")
+	}
+}
+
+// ago returns e.g. "1 day ago" or "2 days ago"
+func ago(n int, unit string) string {
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"", "2006-01-02"},
+		{"C", "2006-01-02"},
+		{"POSIX", "2006-01-02"},
+		{"en_US.UTF-8", "01/02/2006"},
+		{"en_GB.UTF-8", "02/01/2006"},
+		{"de_DE.UTF-8", "02.01.2006"},
+		{"de_CH@euro", "02.01.2006"},
+		{"ja_JP.eucJP", "2006/01/02"},
+		{"sv_SE", "2006-01-02"},
+		{"xx_YY", "2006-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			assert.Equal(t, tt.want, DateLayout(tt.locale))
+		})
+	}
+}
+
+func TestLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "de_DE.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, "de_DE.UTF-8", Locale())
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, "C", Locale())
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		ago  time.Duration
+		want string
+	}{
+		{"future", -time.Hour, "in the future"},
+		{"seconds", 30 * time.Second, "just now"},
+		{"one minute", time.Minute, "1 minute ago"},
+		{"minutes", 45 * time.Minute, "45 minutes ago"},
+		{"hours", 5 * time.Hour, "5 hours ago"},
+		{"one day", 30 * time.Hour, "1 day ago"},
+		{"days", 13 * 24 * time.Hour, "13 days ago"},
+		{"weeks", 21 * 24 * time.Hour, "3 weeks ago"},
+		{"months", 100 * 24 * time.Hour, "3 months ago"},
+		{"one year", 400 * 24 * time.Hour, "1 year ago"},
+		{"years", 800 * 24 * time.Hour, "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RelativeTime(now.Add(-tt.ago), now))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/fatih/color"
+)
+
+// Status indicators of branches in listings and the interactive selector
+const (
+	IndicatorCurrent      = config.IndicatorCurrent
+	IndicatorDefault      = config.IndicatorDefault
+	IndicatorMerged       = config.IndicatorMerged
+	IndicatorUnmerged     = config.IndicatorUnmerged
+	IndicatorStale        = config.IndicatorStale
+	IndicatorRecentlyUsed = config.IndicatorRecentlyUsed
+	IndicatorLocal        = config.IndicatorLocal
+	IndicatorRemote       = config.IndicatorRemote
+)
+
+// defaultIndicators are the labels and colors of the indicators unless the
+// display config says otherwise
+var defaultIndicators = map[string]config.IndicatorStyle{
+	IndicatorCurrent:      {Label: "current", Color: "green"},
+	IndicatorDefault:      {Label: "default", Color: "blue"},
+	IndicatorMerged:       {Label: "merged", Color: "green"},
+	IndicatorUnmerged:     {Label: "unmerged", Color: "yellow"},
+	IndicatorStale:        {Label: "stale", Color: "red"},
+	IndicatorRecentlyUsed: {Label: "recently used", Color: "cyan"},
+	IndicatorLocal:        {Label: "[local]", Color: "green"},
+	IndicatorRemote:       {Label: "[remote]", Color: "blue"},
+}
+
+// colors are the color names the display config accepts
+var colors = map[string]color.Attribute{
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+	"gray":    color.FgHiBlack,
+}
+
+// display is the configured display, applied by every formatting function
+// here
+var display config.Display
+
+// SetDisplay applies the display section of the config to all output
+func SetDisplay(d config.Display) {
+	display = d
+}
+
+// Indicator returns the colored label of an Indicator* status, as
+// configured
+func Indicator(name string) string {
+	style := defaultIndicators[name]
+	if custom, ok := display.Indicators[name]; ok {
+		if custom.Label != "" {
+			style.Label = custom.Label
+		}
+		if custom.Color != "" {
+			style.Color = custom.Color
+		}
+	}
+	attr, ok := colors[style.Color]
+	if !ok {
+		// "none"
+		return style.Label
+	}
+	return color.New(attr).Sprint(style.Label)
+}
+
+// IndicatorLabel returns the plain label of an Indicator* status, for
+// padding and matching
+func IndicatorLabel(name string) string {
+	if custom := display.Indicators[name].Label; custom != "" {
+		return custom
+	}
+	return defaultIndicators[name].Label
+}
+
+// Check returns the mark of a successful or selected item, "✓" or, without
+// emoji, "ok"
+func Check() string {
+	if display.NoEmoji {
+		return color.GreenString("ok")
+	}
+	return color.GreenString("✓")
+}
+
+// Cross returns the mark of a failed item, "✗" or, without emoji, "x"
+func Cross() string {
+	if display.NoEmoji {
+		return color.RedString("x")
+	}
+	return color.RedString("✗")
+}
+
+// Emoji returns s, or nothing when emoji are turned off
+func Emoji(s string) string {
+	if display.NoEmoji {
+		return ""
+	}
+	return s
+}
+
+// NoEmoji reports whether emoji are turned off
+func NoEmoji() bool {
+	return display.NoEmoji
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/config"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndicator(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() {
+		color.NoColor = noColor
+		SetDisplay(config.Display{})
+	}()
+
+	assert.Equal(t, color.RedString("stale"), Indicator(IndicatorStale))
+	assert.Equal(t, "[remote]", IndicatorLabel(IndicatorRemote))
+	assert.Equal(t, color.GreenString("✓"), Check())
+	assert.Equal(t, " 🚀", Emoji(" 🚀"))
+
+	SetDisplay(config.Display{
+		Indicators: map[string]config.IndicatorStyle{
+			IndicatorStale:  {Label: "gone"},
+			IndicatorMerged: {Color: "gray"},
+			IndicatorRemote: {Label: "[origin]", Color: "none"},
+		},
+		NoEmoji: true,
+	})
+	assert.Equal(t, color.RedString("gone"), Indicator(IndicatorStale))
+	assert.Equal(t, color.HiBlackString("merged"), Indicator(IndicatorMerged))
+	assert.Equal(t, "[origin]", Indicator(IndicatorRemote))
+	assert.Equal(t, "[origin]", IndicatorLabel(IndicatorRemote))
+	assert.Equal(t, color.GreenString("ok"), Check())
+	assert.Equal(t, color.RedString("x"), Cross())
+	assert.Empty(t, Emoji(" 🚀"))
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// linePrompter asks questions one line at a time. It is used when stdin or
+// stdout is not a terminal (pipes, CI, tests).
+type linePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewLinePrompter returns a Prompter that reads answers line by line from r
+func NewLinePrompter(r io.Reader, w io.Writer) Prompter {
+	return &linePrompter{in: bufio.NewReader(r), out: w}
+}
+
+func (p *linePrompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", ErrInterrupted
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm asks a yes/no question
+func (p *linePrompter) Confirm(message string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(p.out, "%s [%s]: ", message, hint)
+
+	answer, err := p.readLine()
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(answer) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Input asks for a single line of text
+func (p *linePrompter) Input(message string) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", message)
+	return p.readLine()
+}
+
+// MultiSelect prints numbered options and reads a selection such as
+// "1,3-5", "all" or an empty line for none
+func (p *linePrompter) MultiSelect(message string, options []string, cfg SelectConfig) ([]string, error) {
+	fmt.Fprintln(p.out, message)
+	for i, opt := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, opt)
+		if cfg.Description != nil {
+			if desc := cfg.Description(opt, i); desc != "" {
+				for _, line := range strings.Split(desc, "\n") {
+					fmt.Fprintf(p.out, "     %s\n", line)
+				}
+			}
+		}
+	}
+	fmt.Fprint(p.out, "Enter numbers (e.g. 1,3-5), 'all' or nothing for none: ")
+
+	answer, err := p.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	checked, err := parseSelection(answer, len(options))
+	if err != nil {
+		return nil, err
+	}
+	return selectedOptions(options, checked), nil
+}
+
+// parseSelection parses a comma separated list of 1-based indexes and ranges
+func parseSelection(answer string, count int) ([]bool, error) {
+	checked := make([]bool, count)
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return checked, nil
+	}
+	if strings.EqualFold(answer, "all") {
+		for i := range checked {
+			checked[i] = true
+		}
+		return checked, nil
+	}
+
+	for _, part := range strings.Split(answer, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		lo, hi := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			lo, hi = part[:i], part[i+1:]
+		}
+
+		start, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		end, err := strconv.Atoi(strings.TrimSpace(hi))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		if start < 1 || end > count || start > end {
+			return nil, fmt.Errorf("selection %q out of range 1-%d", part, count)
+		}
+
+		for i := start; i <= end; i++ {
+			checked[i-1] = true
+		}
+	}
+
+	return checked, nil
+}
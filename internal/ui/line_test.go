@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinePrompterConfirm(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		def   bool
+		want  bool
+	}{
+		{"yes", "y\n", false, true},
+		{"long yes", "YES\n", false, true},
+		{"no", "n\n", true, false},
+		{"empty uses default true", "\n", true, true},
+		{"empty uses default false", "\n", false, false},
+		{"garbage is no", "maybe\n", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewLinePrompter(strings.NewReader(tt.input), &out)
+			got, err := p.Confirm("Delete?", tt.def)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, out.String(), "Delete?")
+		})
+	}
+}
+
+func TestLinePrompterEOF(t *testing.T) {
+	p := NewLinePrompter(strings.NewReader(""), &bytes.Buffer{})
+	_, err := p.Confirm("Delete?", false)
+	assert.ErrorIs(t, err, ErrInterrupted)
+}
+
+func TestLinePrompterMultiSelect(t *testing.T) {
+	options := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"none", "\n", nil, false},
+		{"all", "all\n", options, false},
+		{"single", "2\n", []string{"b"}, false},
+		{"list and range", "1, 3-4\n", []string{"a", "c", "d"}, false},
+		{"out of range", "6\n", nil, true},
+		{"reversed range", "4-2\n", nil, true},
+		{"not a number", "x\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewLinePrompter(strings.NewReader(tt.input), &bytes.Buffer{})
+			got, err := p.MultiSelect("Pick:", options, SelectConfig{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLinePrompterMultiSelectDescription(t *testing.T) {
+	var out bytes.Buffer
+	p := NewLinePrompter(strings.NewReader("\n"), &out)
+	_, err := p.MultiSelect("Pick:", []string{"a"}, SelectConfig{
+		Description: func(option string, index int) string { return "subject\nbody" },
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "  1) a\n     subject\n     body\n")
+}
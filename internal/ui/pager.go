@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// defaultPager is the pager used when neither GIT_PAGER nor PAGER is set
+const defaultPager = "less"
+
+// PagerCommand returns the pager command configured in the environment,
+// like git picks it: GIT_PAGER, then PAGER, then less. It returns "" when
+// paging is turned off with an empty value or "cat". lookupEnv is
+// os.LookupEnv outside of tests.
+func PagerCommand(lookupEnv func(string) (string, bool)) string {
+	cmd := defaultPager
+	for _, name := range []string{"GIT_PAGER", "PAGER"} {
+		if v, ok := lookupEnv(name); ok {
+			cmd = v
+			break
+		}
+	}
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "cat" {
+		return ""
+	}
+	return cmd
+}
+
+// Pager is output going through the user's pager
+type Pager struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+}
+
+// StartPager starts the configured pager showing what is written to the
+// returned writer, when out is a terminal. Otherwise, or when paging is off
+// or the pager can't start, it returns out itself. Call Close on the Pager
+// once done writing; a nil Pager does nothing.
+func StartPager(out *os.File) (io.Writer, *Pager) {
+	if !term.IsTerminal(int(out.Fd())) {
+		return out, nil
+	}
+	command := PagerCommand(os.LookupEnv)
+	if command == "" {
+		return out, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(command)
+		cmd = exec.Command(fields[0], fields[1:]...)
+	} else {
+		// Like git, so pagers can be configured with arguments and pipes
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	// Like git: quit when everything fits on one screen, keep colors
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return out, nil
+	}
+	if err := cmd.Start(); err != nil {
+		return out, nil
+	}
+	p := &Pager{cmd: cmd, in: in}
+	return p, p
+}
+
+// Write passes output to the pager. Output after the pager quit, e.g. when
+// the user pressed q early, is discarded rather than failing.
+func (p *Pager) Write(b []byte) (int, error) {
+	n, err := p.in.Write(b)
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed) {
+		return len(b), nil
+	}
+	return n, err
+}
+
+// Close ends the output and waits for the user to quit the pager
+func (p *Pager) Close() error {
+	if p == nil {
+		return nil
+	}
+	p.in.Close()
+	return p.cmd.Wait()
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{name: "default", env: map[string]string{}, want: "less"},
+		{name: "pager", env: map[string]string{"PAGER": "more"}, want: "more"},
+		{name: "git pager first", env: map[string]string{"GIT_PAGER": "delta --paging=always", "PAGER": "more"}, want: "delta --paging=always"},
+		{name: "empty turns it off", env: map[string]string{"GIT_PAGER": "", "PAGER": "more"}, want: ""},
+		{name: "cat turns it off", env: map[string]string{"PAGER": "cat"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup := func(name string) (string, bool) {
+				v, ok := tt.env[name]
+				return v, ok
+			}
+			assert.Equal(t, tt.want, PagerCommand(lookup))
+		})
+	}
+}
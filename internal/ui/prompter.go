@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned when the user aborts a prompt (e.g. Ctrl+C)
+var ErrInterrupted = errors.New("interrupted")
+
+// Prompter asks the user questions. Commands depend on this interface rather
+// than on a specific prompt library so that behavior is consistent across
+// terminals, pipes and tests.
+type Prompter interface {
+	// Confirm asks a yes/no question, returning def on empty input
+	Confirm(message string, def bool) (bool, error)
+	// Input asks for a single line of text
+	Input(message string) (string, error)
+	// MultiSelect lets the user pick any number of options and returns
+	// the selected options in their original order
+	MultiSelect(message string, options []string, cfg SelectConfig) ([]string, error)
+}
+
+// SelectConfig holds optional settings for MultiSelect
+type SelectConfig struct {
+	Help        string                                // Short usage hint shown next to the message
+	PageSize    int                                   // Number of options visible at once
+	Description func(option string, index int) string // Extra detail shown for the highlighted option, possibly on several lines
+
+	// Keys binds extra keys to functions returning a replacement option
+	// list, e.g. to show more options on demand. Checked options that are
+	// still present stay checked. Only the terminal selector supports them.
+	Keys map[rune]func() ([]string, error)
+
+	// Actions binds extra keys to functions run on the highlighted option,
+	// e.g. to show more context about it. An error is shown below the list
+	// without ending the selection.
+	Actions map[rune]func(option string) error
+
+	// Edits binds extra keys to functions changing the highlighted option,
+	// e.g. deleting it right away. They run once the user answers a
+	// one-line confirmation with y, and return a replacement option list as
+	// with Keys. An error is shown below the list without ending the
+	// selection. Only the terminal selector supports them.
+	Edits map[rune]Edit
+
+	// Summary describes the checked options, e.g. with counts by kind. It
+	// is shown below the message and updated as the selection changes.
+	// Only the terminal selector supports it.
+	Summary func(selected []string) string
+
+	// Refresh redraws the list whenever it receives, e.g. as details shown
+	// by Description arrive in the background. Only the terminal selector
+	// supports it.
+	Refresh <-chan struct{}
+}
+
+// Edit is a confirmed change to the highlighted option of a selector
+type Edit struct {
+	Prompt func(option string) string            // Confirmation question, e.g. "Delete x now?"
+	Run    func(option string) ([]string, error) // Applies the change and returns the new options
+}
+
+// NewPrompter returns a terminal prompter when in and out are attached to a
+// TTY and a line-based prompter otherwise
+func NewPrompter(in *os.File, out *os.File) Prompter {
+	if term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd())) {
+		return &ttyPrompter{in: in, out: out}
+	}
+	return NewLinePrompter(in, out)
+}
+
+// selectedOptions returns the options whose indexes are checked
+func selectedOptions(options []string, checked []bool) []string {
+	var selected []string
+	for i, opt := range options {
+		if checked[i] {
+			selected = append(selected, opt)
+		}
+	}
+	return selected
+}
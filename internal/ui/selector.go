@@ -0,0 +1,337 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const defaultPageSize = 15
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// selector is a raw-mode multi-select list used by the terminal prompter
+type selector struct {
+	mu sync.Mutex // Serializes input handling and redraws from cfg.Refresh
+
+	in      *os.File
+	out     *os.File
+	message string
+	options []string
+	checked []bool
+	cfg     SelectConfig
+
+	cursor   int    // Index of the highlighted option
+	offset   int    // Index of the first visible option
+	rendered int    // Terminal rows written by the previous render
+	status   string // Result of the last action, shown below the list
+	pending  rune   // Key of the edit awaiting confirmation, if any
+}
+
+func newSelector(in, out *os.File, message string, options []string, cfg SelectConfig) *selector {
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = defaultPageSize
+	}
+	return &selector{
+		in:      in,
+		out:     out,
+		message: message,
+		options: options,
+		checked: make([]bool, len(options)),
+		cfg:     cfg,
+	}
+}
+
+// run shows the selector until the user confirms or aborts
+func (s *selector) run() ([]string, error) {
+	if len(s.options) == 0 && len(s.cfg.Keys) == 0 {
+		return nil, nil
+	}
+
+	// Put terminal in raw mode for the entire selection process
+	fd := int(s.in.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("could not set terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	fmt.Fprint(s.out, "\033[?25l")       // Hide cursor
+	defer fmt.Fprint(s.out, "\033[?25h") // Show cursor when done
+
+	if s.cfg.Refresh != nil {
+		stop := s.refreshOn(s.cfg.Refresh)
+		defer stop()
+	}
+
+	for {
+		s.mu.Lock()
+		s.render()
+		s.mu.Unlock()
+
+		// Read input
+		b := make([]byte, 3) // Buffer for escape sequences
+		n, err := s.in.Read(b)
+		if err != nil {
+			return nil, fmt.Errorf("error reading input: %w", err)
+		}
+
+		s.mu.Lock()
+		s.status = ""
+		selected, done, err := s.handle(b[:n])
+		s.mu.Unlock()
+		if done {
+			return selected, err
+		}
+	}
+}
+
+// refreshOn redraws the selector whenever refresh receives, until the
+// returned function is called or refresh is closed
+func (s *selector) refreshOn(refresh <-chan struct{}) (stop func()) {
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-quit:
+				return
+			case _, ok := <-refresh:
+				if !ok {
+					return
+				}
+				s.mu.Lock()
+				s.render()
+				s.mu.Unlock()
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		wg.Wait()
+	}
+}
+
+// handle applies a key press. done is set when the selection ended, with
+// the selected options or the reason it was aborted.
+func (s *selector) handle(b []byte) (selected []string, done bool, err error) {
+	n := len(b)
+	if s.pending != 0 {
+		key := s.pending
+		s.pending = 0
+		if n == 1 && (b[0] == 'y' || b[0] == 'Y') && len(s.options) > 0 {
+			s.edit(s.cfg.Edits[key])
+		}
+		return nil, false, nil
+	}
+
+	switch {
+	case n == 1 && (b[0] == 3 || b[0] == 'q'): // Ctrl+C or q
+		fmt.Fprint(s.out, "\r\n")
+		return nil, true, ErrInterrupted
+	case n == 1 && b[0] == 13: // Enter
+		fmt.Fprint(s.out, "\r\n")
+		return selectedOptions(s.options, s.checked), true, nil
+	case n == 1 && s.cfg.Keys[rune(b[0])] != nil:
+		options, err := s.cfg.Keys[rune(b[0])]()
+		if err != nil {
+			fmt.Fprint(s.out, "\r\n")
+			return nil, true, err
+		}
+		s.replace(options)
+	case n == 1 && s.cfg.Actions[rune(b[0])] != nil && len(s.options) > 0:
+		if err := s.cfg.Actions[rune(b[0])](s.options[s.cursor]); err != nil {
+			s.status = err.Error()
+		}
+	case n == 1 && s.cfg.Edits[rune(b[0])].Run != nil && len(s.options) > 0:
+		s.pending = rune(b[0])
+	case n == 1 && b[0] == ' ' && len(s.options) > 0:
+		s.checked[s.cursor] = !s.checked[s.cursor]
+	case n == 1 && b[0] == 'a':
+		s.setAll(true)
+	case n == 1 && b[0] == 'n':
+		s.setAll(false)
+	case n == 1 && (b[0] == 14 || b[0] == 'j'): // Ctrl+N (next)
+		s.move(1)
+	case n == 1 && (b[0] == 16 || b[0] == 'k'): // Ctrl+P (previous)
+		s.move(-1)
+	case n == 3 && b[0] == 27 && b[1] == 91: // Arrow keys
+		switch b[2] {
+		case 65: // Up arrow (27,91,65)
+			s.move(-1)
+		case 66: // Down arrow (27,91,66)
+			s.move(1)
+		}
+	}
+	return nil, false, nil
+}
+
+// edit applies a confirmed edit to the highlighted option. When the option
+// goes away the cursor stays in place, on the option that followed it.
+func (s *selector) edit(e Edit) {
+	cursor := s.cursor
+	options, err := e.Run(s.options[cursor])
+	if err != nil {
+		s.status = err.Error()
+		return
+	}
+	removed := !slices.Contains(options, s.options[cursor])
+	s.replace(options)
+	if removed {
+		s.cursor = cursor
+		s.move(0)
+	}
+}
+
+// move shifts the cursor and keeps it within the visible page
+func (s *selector) move(delta int) {
+	s.cursor += delta
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor >= len(s.options) {
+		s.cursor = len(s.options) - 1
+	}
+
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+
+	// Adjust scroll position if needed
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+s.cfg.PageSize {
+		s.offset = s.cursor - s.cfg.PageSize + 1
+	}
+}
+
+// replace swaps in a new option list, keeping checked options checked and
+// the cursor on the same option when they are still present
+func (s *selector) replace(options []string) {
+	checked := make(map[string]bool)
+	for i, opt := range s.options {
+		if s.checked[i] {
+			checked[opt] = true
+		}
+	}
+	var current string
+	if s.cursor < len(s.options) {
+		current = s.options[s.cursor]
+	}
+
+	s.options = options
+	s.checked = make([]bool, len(options))
+	s.cursor = 0
+	for i, opt := range options {
+		s.checked[i] = checked[opt]
+		if opt == current {
+			s.cursor = i
+		}
+	}
+	s.offset = 0
+	s.move(0)
+}
+
+func (s *selector) setAll(checked bool) {
+	for i := range s.checked {
+		s.checked[i] = checked
+	}
+}
+
+// render redraws the selector in place
+func (s *selector) render() {
+	var lines []string
+
+	header := color.New(color.Bold).Sprint(s.message)
+	if s.cfg.Help != "" {
+		header += " " + color.CyanString("[%s]", s.cfg.Help)
+	}
+	lines = append(lines, header)
+	if summary := s.summary(); summary != "" {
+		lines = append(lines, "  "+summary)
+	}
+
+	end := s.offset + s.cfg.PageSize
+	if end > len(s.options) {
+		end = len(s.options)
+	}
+	for i := s.offset; i < end; i++ {
+		pointer := " "
+		if i == s.cursor {
+			pointer = color.CyanString("❯")
+		}
+		check, checked := "○", Check()
+		if NoEmoji() {
+			check, checked = "[ ]", "["+color.GreenString("x")+"]"
+		}
+		if s.checked[i] {
+			check = checked
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s", pointer, check, s.options[i]))
+	}
+
+	if len(s.options) == 0 {
+		lines = append(lines, color.HiBlackString("  (nothing to show)"))
+	} else if s.cfg.Description != nil {
+		if desc := s.cfg.Description(s.options[s.cursor], s.cursor); desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				lines = append(lines, "  "+line)
+			}
+		}
+	}
+	if len(s.options) > s.cfg.PageSize {
+		lines = append(lines, color.HiBlackString("  (%d-%d of %d)", s.offset+1, end, len(s.options)))
+	}
+	if s.status != "" {
+		for _, line := range strings.Split(strings.TrimSpace(s.status), "\n") {
+			lines = append(lines, color.RedString("  %s", line))
+		}
+	}
+	if e := s.cfg.Edits[s.pending]; s.pending != 0 && e.Prompt != nil && len(s.options) > 0 {
+		lines = append(lines, color.YellowString("  %s [y/N]", e.Prompt(s.options[s.cursor])))
+	}
+
+	// Move back to the first row of the previous render and clear below
+	var buf strings.Builder
+	if s.rendered > 1 {
+		fmt.Fprintf(&buf, "\033[%dA", s.rendered-1)
+	}
+	buf.WriteString("\r\033[J")
+	buf.WriteString(strings.Join(lines, "\r\n"))
+	fmt.Fprint(s.out, buf.String())
+
+	s.rendered = s.rows(lines)
+}
+
+// summary returns the configured summary of the checked options
+func (s *selector) summary() string {
+	if s.cfg.Summary == nil {
+		return ""
+	}
+	return s.cfg.Summary(selectedOptions(s.options, s.checked))
+}
+
+// rows returns how many terminal rows lines occupy, accounting for wrapping
+func (s *selector) rows(lines []string) int {
+	width, _, err := term.GetSize(int(s.out.Fd()))
+	if err != nil || width <= 0 {
+		return len(lines)
+	}
+
+	rows := 0
+	for _, line := range lines {
+		n := utf8.RuneCountInString(ansiRegex.ReplaceAllString(line, ""))
+		rows += 1 + max(n-1, 0)/width
+	}
+	return rows
+}
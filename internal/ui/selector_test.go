@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorReplace(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     []string
+		wantChecked []bool
+		wantCursor  int
+	}{
+		{
+			name:        "options added",
+			options:     []string{"a", "remote-a", "b", "c"},
+			wantChecked: []bool{false, false, true, false},
+			wantCursor:  3,
+		},
+		{
+			name:        "cursor option removed",
+			options:     []string{"a", "b"},
+			wantChecked: []bool{false, true},
+			wantCursor:  0,
+		},
+		{
+			name:        "all options removed",
+			options:     nil,
+			wantChecked: []bool{},
+			wantCursor:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSelector(nil, nil, "Select:", []string{"a", "b", "c"}, SelectConfig{PageSize: 2})
+			s.checked[1] = true
+			s.move(2)
+
+			s.replace(tt.options)
+
+			assert.Equal(t, tt.wantChecked, s.checked)
+			assert.Equal(t, tt.wantCursor, s.cursor)
+			assert.LessOrEqual(t, s.offset, s.cursor)
+		})
+	}
+}
+
+func TestSelectorSummary(t *testing.T) {
+	var got []string
+	s := newSelector(nil, nil, "Select:", []string{"a", "b", "c"}, SelectConfig{
+		Summary: func(selected []string) string {
+			got = selected
+			return "summary"
+		},
+	})
+
+	assert.Equal(t, "summary", s.summary())
+	assert.Empty(t, got)
+
+	s.checked[0] = true
+	s.checked[2] = true
+	s.summary()
+	assert.Equal(t, []string{"a", "c"}, got)
+
+	s.replace([]string{"c", "d"})
+	s.summary()
+	assert.Equal(t, []string{"c"}, got)
+}
+
+func TestSelectorEdit(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        string
+		runErr      error
+		wantOptions []string
+		wantCursor  int
+		wantStatus  string
+	}{
+		{name: "confirmed", keys: "dy", wantOptions: []string{"a", "c"}, wantCursor: 1},
+		{name: "declined", keys: "dn", wantOptions: []string{"a", "b", "c"}, wantCursor: 1},
+		{name: "other key declines", keys: "d ", wantOptions: []string{"a", "b", "c"}, wantCursor: 1},
+		{name: "failed", keys: "dy", runErr: errors.New("not fully merged"), wantOptions: []string{"a", "b", "c"}, wantCursor: 1, wantStatus: "not fully merged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := []string{"a", "b", "c"}
+			var ran []string
+			s := newSelector(nil, nil, "Select:", options, SelectConfig{
+				Edits: map[rune]Edit{'d': {
+					Prompt: func(option string) string { return "Delete " + option + "?" },
+					Run: func(option string) ([]string, error) {
+						ran = append(ran, option)
+						if tt.runErr != nil {
+							return nil, tt.runErr
+						}
+						return slices.DeleteFunc(slices.Clone(options), func(o string) bool { return o == option }), nil
+					},
+				}},
+			})
+			s.move(1)
+
+			for _, key := range tt.keys {
+				_, done, err := s.handle([]byte{byte(key)})
+				require.NoError(t, err)
+				assert.False(t, done)
+			}
+
+			assert.Equal(t, tt.wantOptions, s.options)
+			assert.Equal(t, tt.wantCursor, s.cursor)
+			assert.Equal(t, tt.wantStatus, s.status)
+			assert.Zero(t, s.pending)
+			if tt.keys == "dy" {
+				assert.Equal(t, []string{"b"}, ran)
+			} else {
+				assert.Empty(t, ran)
+			}
+		})
+	}
+}
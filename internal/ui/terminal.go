@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// minTerminalWidth is the narrowest terminal lists fit in without cutting
+const minTerminalWidth = 60
+
+// Terminal describes what the terminal the tool runs in supports
+type Terminal struct {
+	Interactive     bool   `json:"interactive"`     // Input and output are terminals
+	VirtualTerminal bool   `json:"virtualTerminal"` // ANSI escape sequences are interpreted
+	Color           bool   `json:"color"`           // Output is colored
+	Width           int    `json:"width,omitempty"` // Columns, when known
+	Term            string `json:"term,omitempty"`  // The TERM variable
+}
+
+// DetectTerminal reports the capabilities of the terminal in reads from and
+// out writes to
+func DetectTerminal(in, out *os.File) Terminal {
+	t := Terminal{
+		Interactive: term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd())),
+		Color:       !color.NoColor,
+		Term:        os.Getenv("TERM"),
+	}
+	if t.Interactive {
+		t.VirtualTerminal = virtualTerminal(out)
+		if width, _, err := term.GetSize(int(out.Fd())); err == nil {
+			t.Width = width
+		}
+	}
+	return t
+}
+
+// Problems explains what doesn't work in the terminal, and how to fix it
+func (t Terminal) Problems() []string {
+	if !t.Interactive {
+		return []string{"input or output is not a terminal, so interactive mode and confirmations are unavailable"}
+	}
+
+	var problems []string
+	if !t.VirtualTerminal {
+		problems = append(problems, "the terminal doesn't interpret escape sequences, so the selector can't redraw; use Windows Terminal or a terminal with TERM other than dumb")
+	}
+	if !t.Color {
+		problems = append(problems, "colors are off; unset NO_COLOR to turn them on")
+	}
+	if t.Width > 0 && t.Width < minTerminalWidth {
+		problems = append(problems, fmt.Sprintf("the terminal is narrower than %d columns, so long lines are cut", minTerminalWidth))
+	}
+	return problems
+}
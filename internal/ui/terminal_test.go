@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminalProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal Terminal
+		want     int
+	}{
+		{"capable", Terminal{Interactive: true, VirtualTerminal: true, Color: true, Width: 120}, 0},
+		{"unknown width", Terminal{Interactive: true, VirtualTerminal: true, Color: true}, 0},
+		{"piped", Terminal{Color: true}, 1},
+		{"legacy console", Terminal{Interactive: true, Color: true, Width: 80}, 1},
+		{"no color and narrow", Terminal{Interactive: true, VirtualTerminal: true, Width: 40}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, tt.terminal.Problems(), tt.want)
+		})
+	}
+}
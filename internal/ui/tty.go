@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"errors"
+	"os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// ttyPrompter asks questions on an interactive terminal
+type ttyPrompter struct {
+	in  *os.File
+	out *os.File
+}
+
+func (p *ttyPrompter) stdio() survey.AskOpt {
+	return survey.WithStdio(p.in, p.out, os.Stderr)
+}
+
+// Confirm asks a yes/no question
+func (p *ttyPrompter) Confirm(message string, def bool) (bool, error) {
+	var answer bool
+	prompt := &survey.Confirm{
+		Message: message,
+		Default: def,
+	}
+	if err := survey.AskOne(prompt, &answer, p.stdio()); err != nil {
+		return false, translateSurveyError(err)
+	}
+	return answer, nil
+}
+
+// Input asks for a single line of text
+func (p *ttyPrompter) Input(message string) (string, error) {
+	var answer string
+	prompt := &survey.Input{Message: message}
+	if err := survey.AskOne(prompt, &answer, p.stdio()); err != nil {
+		return "", translateSurveyError(err)
+	}
+	return answer, nil
+}
+
+// MultiSelect shows a scrollable list with checkboxes
+func (p *ttyPrompter) MultiSelect(message string, options []string, cfg SelectConfig) ([]string, error) {
+	return newSelector(p.in, p.out, message, options, cfg).run()
+}
+
+func translateSurveyError(err error) error {
+	if errors.Is(err, terminal.InterruptErr) {
+		return ErrInterrupted
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package ui
+
+import "os"
+
+// EnableVirtualTerminal makes the console f writes to interpret ANSI escape
+// sequences. Terminals outside Windows always do.
+func EnableVirtualTerminal(f *os.File) error {
+	return nil
+}
+
+// virtualTerminal reports whether f interprets ANSI escape sequences, which
+// only dumb terminals don't
+func virtualTerminal(f *os.File) bool {
+	return os.Getenv("TERM") != "dumb"
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+	"syscall"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes the
+// console interpret ANSI escape sequences, see SetConsoleMode
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+// EnableVirtualTerminal makes the console f writes to interpret ANSI escape
+// sequences, which colors and the selector rely on. Files that aren't a
+// console, such as pipes and the terminals of Git Bash, are left alone. It
+// fails on consoles that predate escape sequence support.
+func EnableVirtualTerminal(f *os.File) error {
+	var mode uint32
+	h := syscall.Handle(f.Fd())
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return nil
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return nil
+	}
+	if r, _, err := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// virtualTerminal reports whether f, when it is a console, interprets ANSI
+// escape sequences
+func virtualTerminal(f *os.File) bool {
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode); err != nil {
+		return true
+	}
+	return mode&enableVirtualTerminalProcessing != 0
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool is available
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// clipboardCommands lists the clipboard tools to try on Linux and BSDs, in
+// order of preference
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// CopyToClipboard places text on the system clipboard using the platform's
+// clipboard tool, returning ErrNoClipboard when there is none
+func CopyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		candidates = clipboardCommands
+	}
+
+	for _, args := range candidates {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			// e.g. xclip without a display; try the next tool
+			continue
+		}
+		return nil
+	}
+	return ErrNoClipboard
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ErrNoNotifier is returned when desktop notifications aren't available
+var ErrNoNotifier = errors.New("no desktop notifications available")
+
+// windowsToast shows a toast with the title and message passed in the
+// environment, so neither needs quoting for PowerShell
+const windowsToast = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:GBD_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:GBD_NOTIFY_MESSAGE)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('git-branch-delete').Show($toast)`
+
+// Notify shows a desktop notification: Notification Center on macOS, a
+// toast on Windows and libnotify's notify-send elsewhere. It returns
+// ErrNoNotifier when the platform's tool is missing.
+func Notify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Pass the texts as arguments rather than splicing them into the script
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToast)
+		cmd.Env = append(os.Environ(), "GBD_NOTIFY_TITLE="+title, "GBD_NOTIFY_MESSAGE="+message)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=git-branch-delete", title, message)
+	}
+
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return ErrNoNotifier
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to send notification: %w: %s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package utils
+
+// FileOwner returns the user ID owning path. ok is false where files have
+// no numeric owner, e.g. on Windows, or when path can't be read.
+func FileOwner(path string) (uid int, ok bool) {
+	return 0, false
+}
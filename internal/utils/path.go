@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// windowsEnvRef matches %VAR% references, as in %LOCALAPPDATA%\logs
+var windowsEnvRef = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandPath expands a leading ~ to the home directory and environment
+// variable references, written %VAR% on Windows and $VAR elsewhere, so
+// configured paths can follow the platform's conventions
+func ExpandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	if runtime.GOOS == "windows" {
+		path = windowsEnvRef.ReplaceAllStringFunc(path, func(ref string) string {
+			if value, ok := os.LookupEnv(strings.Trim(ref, "%")); ok {
+				return value
+			}
+			return ref
+		})
+	} else {
+		path = os.ExpandEnv(path)
+	}
+	return filepath.Clean(path)
+}
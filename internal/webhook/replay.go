@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultReplayWindow is how long Deliveries remembers a delivery
+const DefaultReplayWindow = 24 * time.Hour
+
+// Deliveries remembers handled deliveries by a key the secret vouches for,
+// such as Event.Payload, so a captured delivery sent again is rejected
+// instead of acted on twice. Keys are forgotten after the window, and when
+// the process restarts. It is safe for concurrent use.
+type Deliveries struct {
+	Window time.Duration // DefaultReplayWindow when 0
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// Seen reports whether the delivery id was added within the window. An
+// empty id can't be told apart from others and always counts as seen.
+func (d *Deliveries) Seen(id string) bool {
+	if id == "" {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	at, ok := d.seen[id]
+	return ok && time.Since(at) <= d.window()
+}
+
+// Add remembers the delivery id, forgetting those outside the window. An
+// empty id isn't remembered.
+func (d *Deliveries) Add(id string) {
+	if id == "" {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.seen == nil {
+		d.seen = make(map[string]time.Time)
+	}
+	for seen, at := range d.seen {
+		if time.Since(at) > d.window() {
+			delete(d.seen, seen)
+		}
+	}
+	d.seen[id] = time.Now()
+}
+
+// window returns the time IDs are remembered
+func (d *Deliveries) window() time.Duration {
+	if d.Window <= 0 {
+		return DefaultReplayWindow
+	}
+	return d.Window
+}
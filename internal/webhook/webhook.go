@@ -0,0 +1,176 @@
+// Package webhook parses the pull request events GitHub and GitLab deliver
+// to webhooks, so merged branches can be cleaned up as soon as they land.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Providers delivering webhooks
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// maxPayloadSize bounds the payloads read; GitHub caps them at 25 MB
+const maxPayloadSize = 25 << 20
+
+// ErrUnauthorized is returned for deliveries not authenticated by the secret
+var ErrUnauthorized = errors.New("webhook signature or token does not match the secret")
+
+// Event is a pull request (or GitLab merge request) that was merged
+type Event struct {
+	Provider   string `json:"provider"`   // GitHub or GitLab
+	Repository string `json:"repository"` // Path of the target repository, e.g. owner/name
+	Number     int    `json:"number"`
+	Branch     string `json:"branch"`   // Source branch
+	Head       string `json:"head"`     // Commit the source branch was merged at
+	Delivery   string `json:"delivery"` // ID the provider gave the delivery
+
+	// Payload is the SHA-256 of the delivered body, which the GitHub
+	// signature covers and the delivery ID doesn't. Replays are told apart
+	// by it, since a captured body can be resent under any delivery ID.
+	Payload string `json:"-"`
+}
+
+// Parse authenticates a webhook delivery with secret and returns the merged
+// request it reports. Deliveries about anything else, such as opened or
+// closed but unmerged requests, return a nil event and no error.
+//
+// GitHub signs payloads with the secret (X-Hub-Signature-256); GitLab sends
+// the secret itself as X-Gitlab-Token.
+func Parse(r *http.Request, secret string) (*Event, error) {
+	if secret == "" {
+		return nil, ErrUnauthorized
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	if len(body) > maxPayloadSize {
+		return nil, fmt.Errorf("payload exceeds %d bytes", maxPayloadSize)
+	}
+
+	var ev *Event
+	var delivery string
+	switch {
+	case r.Header.Get("X-GitHub-Event") != "":
+		if !validSignature(body, r.Header.Get("X-Hub-Signature-256"), secret) {
+			return nil, ErrUnauthorized
+		}
+		if r.Header.Get("X-GitHub-Event") != "pull_request" {
+			return nil, nil
+		}
+		delivery = r.Header.Get("X-GitHub-Delivery")
+		ev, err = parseGitHub(body)
+	case r.Header.Get("X-Gitlab-Event") != "":
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			return nil, ErrUnauthorized
+		}
+		if r.Header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+			return nil, nil
+		}
+		delivery = r.Header.Get("X-Gitlab-Event-UUID")
+		ev, err = parseGitLab(body)
+	default:
+		return nil, fmt.Errorf("not a GitHub or GitLab webhook delivery")
+	}
+	if ev == nil || err != nil {
+		return nil, err
+	}
+
+	// Providers always send an ID; a delivery without one wasn't theirs
+	if delivery == "" {
+		return nil, fmt.Errorf("merged request delivery has no delivery ID")
+	}
+	sum := sha256.Sum256(body)
+	ev.Delivery, ev.Payload = delivery, hex.EncodeToString(sum[:])
+	return ev, nil
+}
+
+// validSignature checks a GitHub "sha256=<hex>" payload signature
+func validSignature(body []byte, signature, secret string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// parseGitHub reads a pull_request event, which reports merges as closed
+// pull requests with merged set
+func parseGitHub(body []byte) (*Event, error) {
+	var payload struct {
+		Action      string `json:"action"`
+		PullRequest struct {
+			Number int  `json:"number"`
+			Merged bool `json:"merged"`
+			Head   struct {
+				Ref string `json:"ref"`
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+		Repository struct {
+			FullName string `json:"full_name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid pull_request payload: %w", err)
+	}
+	if payload.Action != "closed" || !payload.PullRequest.Merged {
+		return nil, nil
+	}
+
+	return newEvent(GitHub, payload.Repository.FullName, payload.PullRequest.Number,
+		payload.PullRequest.Head.Ref, payload.PullRequest.Head.SHA)
+}
+
+// parseGitLab reads a Merge Request Hook event
+func parseGitLab(body []byte) (*Event, error) {
+	var payload struct {
+		Project struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"project"`
+		ObjectAttributes struct {
+			IID          int    `json:"iid"`
+			Action       string `json:"action"`
+			SourceBranch string `json:"source_branch"`
+			LastCommit   struct {
+				ID string `json:"id"`
+			} `json:"last_commit"`
+		} `json:"object_attributes"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid merge request payload: %w", err)
+	}
+	attrs := payload.ObjectAttributes
+	if attrs.Action != "merge" {
+		return nil, nil
+	}
+
+	return newEvent(GitLab, payload.Project.PathWithNamespace, attrs.IID, attrs.SourceBranch, attrs.LastCommit.ID)
+}
+
+// newEvent checks that a merged request carries what cleaning up needs
+func newEvent(provider, repo string, number int, branch, head string) (*Event, error) {
+	if repo == "" || branch == "" || head == "" {
+		return nil, fmt.Errorf("merged request payload lacks the repository, branch or head commit")
+	}
+	return &Event{Provider: provider, Repository: repo, Number: number, Branch: branch, Head: head}, nil
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "s3cret"
+
+// sign returns the GitHub signature header of body
+func sign(body, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParse(t *testing.T) {
+	githubMerged := `{"action":"closed","pull_request":{"number":7,"merged":true,"head":{"ref":"feature/x","sha":"abc123"}},"repository":{"full_name":"acme/app"}}`
+	githubClosed := `{"action":"closed","pull_request":{"number":7,"merged":false,"head":{"ref":"feature/x","sha":"abc123"}},"repository":{"full_name":"acme/app"}}`
+	gitlabMerged := `{"project":{"path_with_namespace":"group/sub/app"},"object_attributes":{"iid":3,"action":"merge","source_branch":"fix/y","last_commit":{"id":"def456"}}}`
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		want    *Event
+		wantErr error
+		anyErr  bool
+	}{
+		{
+			name:    "github merged",
+			headers: map[string]string{"X-GitHub-Event": "pull_request", "X-GitHub-Delivery": "d-1", "X-Hub-Signature-256": sign(githubMerged, secret)},
+			body:    githubMerged,
+			want:    &Event{Provider: GitHub, Repository: "acme/app", Number: 7, Branch: "feature/x", Head: "abc123", Delivery: "d-1"},
+		},
+		{
+			name:    "github merged without delivery ID",
+			headers: map[string]string{"X-GitHub-Event": "pull_request", "X-Hub-Signature-256": sign(githubMerged, secret)},
+			body:    githubMerged,
+			anyErr:  true,
+		},
+		{
+			name:    "github closed without merging",
+			headers: map[string]string{"X-GitHub-Event": "pull_request", "X-Hub-Signature-256": sign(githubClosed, secret)},
+			body:    githubClosed,
+		},
+		{
+			name:    "github other event",
+			headers: map[string]string{"X-GitHub-Event": "push", "X-Hub-Signature-256": sign(`{}`, secret)},
+			body:    `{}`,
+		},
+		{
+			name:    "github wrong signature",
+			headers: map[string]string{"X-GitHub-Event": "pull_request", "X-Hub-Signature-256": sign(githubMerged, "other")},
+			body:    githubMerged,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "github unsigned",
+			headers: map[string]string{"X-GitHub-Event": "pull_request"},
+			body:    githubMerged,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "gitlab merged",
+			headers: map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Event-UUID": "u-1", "X-Gitlab-Token": secret},
+			body:    gitlabMerged,
+			want:    &Event{Provider: GitLab, Repository: "group/sub/app", Number: 3, Branch: "fix/y", Head: "def456", Delivery: "u-1"},
+		},
+		{
+			name:    "gitlab wrong token",
+			headers: map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": "nope"},
+			body:    gitlabMerged,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "gitlab payload without branch",
+			headers: map[string]string{"X-Gitlab-Event": "Merge Request Hook", "X-Gitlab-Token": secret},
+			body:    `{"project":{"path_with_namespace":"app"},"object_attributes":{"action":"merge"}}`,
+			anyErr:  true,
+		},
+		{
+			name:   "unknown sender",
+			body:   githubMerged,
+			anyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/webhook", strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			got, err := Parse(r, secret)
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			case tt.anyErr:
+				assert.Error(t, err)
+			default:
+				require.NoError(t, err)
+				if tt.want != nil {
+					// The payload key is the hash of the body, whatever the ID
+					sum := sha256.Sum256([]byte(tt.body))
+					tt.want.Payload = hex.EncodeToString(sum[:])
+				}
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWithoutSecret(t *testing.T) {
+	r := httptest.NewRequest("POST", "/webhook", strings.NewReader(`{}`))
+	r.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	_, err := Parse(r, "")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestDeliveries(t *testing.T) {
+	var d Deliveries
+	assert.False(t, d.Seen("d-1"))
+	d.Add("d-1")
+	assert.True(t, d.Seen("d-1"))
+	assert.False(t, d.Seen("d-2"))
+
+	// IDs outside the window are forgotten
+	d = Deliveries{Window: time.Millisecond}
+	d.Add("d-1")
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, d.Seen("d-1"))
+	d.Add("d-2")
+	assert.NotContains(t, d.seen, "d-1")
+
+	// An empty key is never remembered, nor taken for a new delivery
+	d.Add("")
+	assert.NotContains(t, d.seen, "")
+	assert.True(t, d.Seen(""))
+}
@@ -0,0 +1,48 @@
+package gbd_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bral/git-branch-delete-go/pkg/gbd"
+)
+
+// Deletes the merged and stale branches of the repository in the current
+// directory, except release branches
+func Example() {
+	ctx := context.Background()
+
+	scan, err := gbd.ScanRepo(ctx, ".", gbd.Options{Protected: []string{"release/*"}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan := gbd.PlanPrune(scan, gbd.PruneOptions{})
+	for _, s := range plan.Skipped {
+		fmt.Printf("keeping %s: %s\n", s.Name, s.Why)
+	}
+
+	res, err := gbd.Execute(ctx, plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, b := range res.Deleted {
+		fmt.Printf("deleted %s (%s)\n", b.Name, b.Reason)
+	}
+	for _, f := range res.Failed {
+		fmt.Println(f)
+	}
+}
+
+// Lists the branches that could be deleted, without deleting any
+func ExampleScanRepo() {
+	scan, err := gbd.ScanRepo(context.Background(), ".", gbd.Options{Remote: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, b := range scan.Branches {
+		if b.Reason != "" {
+			fmt.Printf("%s: %s\n", b.Name, b.Reason)
+		}
+	}
+}
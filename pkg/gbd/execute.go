@@ -0,0 +1,115 @@
+package gbd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/journal"
+)
+
+// Result is the outcome of executing a plan
+type Result struct {
+	Deleted []Branch
+	Failed  []Failure
+
+	// Warnings are problems that didn't stop a deletion, such as branch
+	// configuration left behind
+	Warnings []error
+}
+
+// Failure is a branch that couldn't be deleted
+type Failure struct {
+	Branch
+	Err error
+}
+
+// Error describes the failure, e.g. "feature/x: branch is protected"
+func (f Failure) Error() string {
+	return f.Name + ": " + f.Err.Error()
+}
+
+// Execute deletes the branches of a plan. Local branches are deleted in
+// one ref transaction when git supports it, so either all of them go or
+// none do. Remote branches are only deleted while origin still has them at
+// the commit that was scanned, so work pushed since is never lost. A
+// cancelled ctx stops before the next branch; the branches deleted so far
+// are reported along with ctx's error.
+//
+// The deleted branches are recorded in the deletion journal, so
+// 'git-branch-delete undo --last' recreates them.
+func Execute(ctx context.Context, plan *Plan) (*Result, error) {
+	g := plan.scan.git
+	res := &Result{}
+	defer recordDeletions(g, res)
+
+	var local, remote []Branch
+	for _, b := range plan.Delete {
+		if b.Remote {
+			remote = append(remote, b)
+		} else {
+			local = append(local, b)
+		}
+	}
+
+	if len(local) > 1 && g.SupportsRefTransactions() {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		names := make([]string, len(local))
+		for i, b := range local {
+			names[i] = b.Name
+		}
+		err := g.DeleteBranchesAtomic(names, true)
+		var leftover *git.ErrBranchConfig
+		if errors.As(err, &leftover) {
+			res.Warnings = append(res.Warnings, err)
+			err = nil
+		}
+		if err != nil {
+			for _, b := range local {
+				res.Failed = append(res.Failed, Failure{Branch: b, Err: err})
+			}
+		} else {
+			res.Deleted = append(res.Deleted, local...)
+		}
+		local = nil
+	}
+	for _, b := range local {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if err := g.DeleteBranch(b.Name, true, false); err != nil {
+			res.Failed = append(res.Failed, Failure{Branch: b, Err: err})
+			continue
+		}
+		res.Deleted = append(res.Deleted, b)
+	}
+
+	for _, b := range remote {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		if err := g.DeleteRemoteBranchAt(b.Name, b.Commit); err != nil {
+			res.Failed = append(res.Failed, Failure{Branch: b, Err: err})
+			continue
+		}
+		res.Deleted = append(res.Deleted, b)
+	}
+	return res, nil
+}
+
+// recordDeletions adds the deleted branches of res to the deletion journal
+// as one run, adding a warning when it can't be written
+func recordDeletions(g *git.Git, res *Result) {
+	if len(res.Deleted) == 0 {
+		return
+	}
+	entries := make([]journal.Entry, len(res.Deleted))
+	for i, b := range res.Deleted {
+		entries[i] = journal.Entry{Branch: b.Name, Remote: b.Remote, Commit: b.Commit}
+	}
+	if err := journal.Append(g, journal.NewRun(), entries); err != nil {
+		res.Warnings = append(res.Warnings, err)
+	}
+}
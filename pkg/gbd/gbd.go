@@ -0,0 +1,134 @@
+// Package gbd is the supported API for embedding branch cleanup in other Go
+// tools. Cleaning up a repository takes three calls: ScanRepo lists its
+// branches and why each could go, PlanPrune picks the ones to delete, and
+// Execute deletes them.
+//
+//	scan, err := gbd.ScanRepo(ctx, ".", gbd.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	plan := gbd.PlanPrune(scan, gbd.PruneOptions{})
+//	res, err := gbd.Execute(ctx, plan)
+//
+// Plans can be shown to the user, filtered or discarded before executing
+// them; nothing is changed until Execute. The same safety rules as the
+// git-branch-delete command apply: the current and default branches,
+// protected branches, branches in an ongoing rebase or merge, and branches
+// managed by stacked-diff tools are never deleted.
+//
+// This package is kept compatible across releases. pkg/git is a lower-level
+// library kept for existing users; everything else in the module may
+// change.
+package gbd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+)
+
+// Options configures how a repository is scanned
+type Options struct {
+	// Protected are branch name patterns (path.Match syntax, e.g.
+	// "release/*") never deleted, on top of the default branch
+	Protected []string
+
+	// MergedTargets are branch name patterns a branch counts as merged
+	// into; empty means HEAD
+	MergedTargets []string
+
+	// Remote includes origin's branches besides the local ones
+	Remote bool
+
+	// Timeout bounds each git command; zero means a default of a few
+	// minutes
+	Timeout time.Duration
+}
+
+// Branch is a branch found by ScanRepo
+type Branch struct {
+	Name       string    // Without "origin/" for remote branches
+	Commit     string    // Abbreviated hash of the tip commit
+	CommitDate time.Time // Committer date of the tip commit
+	Remote     bool      // On origin rather than local
+	Current    bool      // Checked out, or the upstream of the checked out branch
+	Default    bool      // The default branch
+	Merged     bool      // Merged into HEAD or a merge target
+	Stale      bool      // Its upstream was deleted from origin
+
+	// InUse is why the branch can't be deleted right now, e.g. an ongoing
+	// rebase, if anything
+	InUse string
+
+	// Reason is why the branch can be deleted, e.g. "merged into main 42
+	// days ago"; empty when it's neither merged nor stale
+	Reason string
+}
+
+// Scan is the state of a repository's branches at one point in time
+type Scan struct {
+	Dir      string    // Root of the repository's working tree
+	Time     time.Time // When the scan was taken
+	Branches []Branch
+
+	git  *git.Git
+	opts Options
+}
+
+// ScanRepo lists the branches of the repository dir is in, with their merge
+// status and why each could be deleted. Shared clones, which borrow
+// objects from another repository, are detected and protected from
+// garbage collection.
+func ScanRepo(ctx context.Context, dir string, opts Options) (*Scan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	g, err := git.New(dir)
+	if err != nil {
+		return nil, err
+	}
+	g.SetTimeout(opts.Timeout)
+	g.SetProtection(git.Protection{Local: opts.Protected, Remote: opts.Protected})
+	g.SetMergedTargets(opts.MergedTargets)
+	if sharing, err := g.DetectSharing(); err == nil && sharing.Shared() {
+		if err := g.SetShared(true); err != nil {
+			return nil, err
+		}
+	}
+
+	var branches []git.GitBranch
+	if opts.Remote {
+		branches, err = g.ListBranches()
+	} else {
+		branches, err = g.ListLocalBranches()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	if err := g.WithSubjects(branches); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scan := &Scan{Dir: g.WorkDir(), Time: time.Now(), git: g, opts: opts}
+	for _, b := range branches {
+		scan.Branches = append(scan.Branches, Branch{
+			Name:       b.Name,
+			Commit:     b.CommitHash,
+			CommitDate: b.CommitDate,
+			Remote:     b.IsRemote,
+			Current:    b.IsCurrent,
+			Default:    b.IsDefault,
+			Merged:     b.IsMerged,
+			Stale:      b.IsStale,
+			InUse:      b.InUse,
+			Reason:     g.DeletableReason(b, scan.Time),
+		})
+	}
+	return scan, nil
+}
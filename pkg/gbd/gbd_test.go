@@ -0,0 +1,210 @@
+package gbd
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/bral/git-branch-delete-go/internal/journal"
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// names returns the names of branches, for comparing plans
+func names(branches []Branch) []string {
+	var out []string
+	for _, b := range branches {
+		out = append(out, b.Name)
+	}
+	return out
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		prune   PruneOptions
+		deleted []string
+		skipped map[string]string
+		kept    []string
+	}{
+		{
+			name:    "merged and stale",
+			deleted: []string{"merged", "release/old", "stale"},
+			kept:    []string{"unmerged"},
+		},
+		{
+			name:    "merged only",
+			prune:   PruneOptions{Merged: true},
+			deleted: []string{"merged", "release/old"},
+			kept:    []string{"stale", "unmerged"},
+		},
+		{
+			name:    "stale only",
+			prune:   PruneOptions{Stale: true},
+			deleted: []string{"stale"},
+			kept:    []string{"merged", "release/old"},
+		},
+		{
+			name:    "protected",
+			opts:    Options{Protected: []string{"release/*"}},
+			deleted: []string{"merged", "stale"},
+			skipped: map[string]string{"release/old": "protected"},
+			kept:    []string{"release/old"},
+		},
+		{
+			name:    "too young",
+			prune:   PruneOptions{MinAge: 100 * 365 * 24 * time.Hour},
+			skipped: map[string]string{"merged": "last commit is younger than 876000h0m0s", "release/old": "last commit is younger than 876000h0m0s", "stale": "last commit is younger than 876000h0m0s"},
+			kept:    []string{"merged", "release/old", "stale"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testutil.NewRemote(t, testutil.Merged, testutil.Stale, testutil.Unmerged)
+			r.Merged("release/old")
+
+			scan, err := ScanRepo(context.Background(), r.Dir, tt.opts)
+			require.NoError(t, err)
+			plan := PlanPrune(scan, tt.prune)
+
+			assert.Equal(t, tt.deleted, names(plan.Delete))
+			skipped := map[string]string{}
+			for _, s := range plan.Skipped {
+				skipped[s.Name] = s.Why
+			}
+			if tt.skipped == nil {
+				tt.skipped = map[string]string{}
+			}
+			assert.Equal(t, tt.skipped, skipped)
+
+			res, err := Execute(context.Background(), plan)
+			require.NoError(t, err)
+			assert.Empty(t, res.Failed)
+			assert.Equal(t, tt.deleted, names(res.Deleted))
+			for _, name := range tt.deleted {
+				assert.False(t, r.HasBranch(name), name)
+			}
+			for _, name := range tt.kept {
+				assert.True(t, r.HasBranch(name), name)
+			}
+			assert.True(t, r.HasBranch("main"))
+		})
+	}
+}
+
+func TestPruneRemote(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged)
+	r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	scan, err := ScanRepo(context.Background(), r.Dir, Options{Remote: true})
+	require.NoError(t, err)
+	plan := PlanPrune(scan, PruneOptions{Merged: true})
+
+	var remote []string
+	for _, b := range plan.Delete {
+		if b.Remote {
+			remote = append(remote, b.Name)
+		}
+	}
+	assert.Equal(t, []string{"merged"}, remote)
+
+	res, err := Execute(context.Background(), plan)
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed)
+	assert.False(t, r.HasBranch("merged"))
+	assert.False(t, r.HasRemoteBranch("merged"))
+	assert.True(t, r.HasRemoteBranch("main"))
+
+	// Both deletions are journaled as one run for undo --last
+	g, err := git.New(r.Dir)
+	require.NoError(t, err)
+	entries, err := journal.Load(g)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.NotEmpty(t, entries[0].Run)
+	assert.True(t, entries[0].SameRun(entries[1]))
+	assert.Equal(t, "merged", entries[1].Branch)
+	assert.Len(t, entries[1].Commit, 40)
+}
+
+func TestPruneRemoteMoved(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged)
+	r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	scan, err := ScanRepo(context.Background(), r.Dir, Options{Remote: true})
+	require.NoError(t, err)
+	plan := PlanPrune(scan, PruneOptions{Merged: true})
+
+	// Someone pushes to the branch after the scan
+	r.Git("checkout", "--quiet", "merged")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "More work")
+	r.Git("push", "--quiet", "origin", "merged")
+	r.Git("checkout", "--quiet", "main")
+
+	res, err := Execute(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, res.Failed, 1)
+	assert.Equal(t, "merged", res.Failed[0].Name)
+	assert.True(t, res.Failed[0].Remote)
+	assert.True(t, r.HasRemoteBranch("merged"))
+}
+
+func TestScanCancelled(t *testing.T) {
+	r := testutil.NewRemote(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ScanRepo(ctx, r.Dir, Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExecuteCancelled(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged, testutil.Stale)
+	scan, err := ScanRepo(context.Background(), r.Dir, Options{})
+	require.NoError(t, err)
+	plan := PlanPrune(scan, PruneOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := Execute(ctx, plan)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, res.Deleted)
+	assert.True(t, r.HasBranch("merged"))
+	assert.True(t, r.HasBranch("stale"))
+}
+
+func TestExecuteKeepsWorktreeBranches(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged, testutil.Stale)
+	r.Merged("release/old")
+	scan, err := ScanRepo(context.Background(), r.Dir, Options{})
+	require.NoError(t, err)
+	plan := PlanPrune(scan, PruneOptions{})
+	require.Contains(t, names(plan.Delete), "merged")
+
+	// Checked out elsewhere after the scan
+	r.Git("worktree", "add", "--quiet", filepath.Join(t.TempDir(), "wt"), "merged")
+	res, err := Execute(context.Background(), plan)
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.Failed)
+	assert.True(t, r.HasBranch("merged"))
+}
+
+func TestExecuteRemovesBranchConfig(t *testing.T) {
+	r := testutil.NewRemote(t, testutil.Merged, testutil.Stale)
+	scan, err := ScanRepo(context.Background(), r.Dir, Options{})
+	require.NoError(t, err)
+
+	res, err := Execute(context.Background(), PlanPrune(scan, PruneOptions{}))
+	require.NoError(t, err)
+	assert.Empty(t, res.Failed)
+	assert.Empty(t, res.Warnings)
+	c := exec.Command("git", "config", "--get-regexp", `^branch\.(merged|stale)\.`)
+	c.Dir = r.Dir
+	out, _ := c.Output()
+	assert.Empty(t, string(out))
+}
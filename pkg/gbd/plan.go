@@ -0,0 +1,66 @@
+package gbd
+
+import (
+	"fmt"
+	"time"
+)
+
+// PruneOptions selects the branches PlanPrune deletes
+type PruneOptions struct {
+	// Merged and Stale select merged branches and branches whose upstream
+	// is gone. With neither set, both are selected.
+	Merged bool
+	Stale  bool
+
+	// MinAge keeps branches whose last commit is younger than this
+	MinAge time.Duration
+}
+
+// Plan is what Execute deletes, and what it leaves alone and why
+type Plan struct {
+	Delete  []Branch
+	Skipped []Skipped
+
+	scan *Scan
+}
+
+// Skipped is a branch that was selected but won't be deleted
+type Skipped struct {
+	Branch
+	Why string // e.g. "protected" or "tracked by Graphite"
+}
+
+// PlanPrune picks the branches of a scan to delete. Local branches are
+// deleted even when their upstream is gone with unpushed commits, like
+// prune does, so check the plan before executing it.
+func PlanPrune(scan *Scan, opts PruneOptions) *Plan {
+	if !opts.Merged && !opts.Stale {
+		opts.Merged, opts.Stale = true, true
+	}
+
+	plan := &Plan{scan: scan}
+	// Failing to read stacked-diff metadata protects nothing extra, like prune
+	stacks, _ := scan.git.StackedBranches()
+	for _, b := range scan.Branches {
+		if b.Current || b.Default || !(opts.Merged && b.Merged || opts.Stale && b.Stale) {
+			continue
+		}
+
+		why := b.InUse
+		if tool := stacks.Tool(b.Name); why == "" && !b.Remote && tool != "" {
+			why = "tracked by " + tool
+		}
+		if why == "" && scan.git.IsProtected(b.Name, b.Remote) {
+			why = "protected"
+		}
+		if why == "" && opts.MinAge > 0 && !b.CommitDate.IsZero() && scan.Time.Sub(b.CommitDate) < opts.MinAge {
+			why = fmt.Sprintf("last commit is younger than %s", opts.MinAge)
+		}
+		if why != "" {
+			plan.Skipped = append(plan.Skipped, Skipped{Branch: b, Why: why})
+			continue
+		}
+		plan.Delete = append(plan.Delete, b)
+	}
+	return plan
+}
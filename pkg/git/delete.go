@@ -0,0 +1,139 @@
+package git
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+)
+
+// DeleteOptions controls how Delete removes a branch. The zero value
+// safely deletes a merged local branch and nothing else.
+type DeleteOptions struct {
+	// Force deletes the branch even if it isn't merged
+	Force bool
+
+	// Remote also deletes the branch of the same name from this remote,
+	// e.g. "origin". Empty leaves remotes alone.
+	Remote string
+
+	// DryRun runs every check Delete would, without deleting anything
+	DryRun bool
+
+	// IgnoreProtection allows deleting the default branch
+	IgnoreProtection bool
+}
+
+// DeleteOption sets a field of DeleteOptions; see Delete
+type DeleteOption func(*DeleteOptions)
+
+// WithForce deletes the branch even if it isn't merged
+func WithForce() DeleteOption {
+	return func(o *DeleteOptions) { o.Force = true }
+}
+
+// WithRemote also deletes the branch from the named remote
+func WithRemote(name string) DeleteOption {
+	return func(o *DeleteOptions) { o.Remote = name }
+}
+
+// WithDryRun checks the branch could be deleted, without deleting it
+func WithDryRun() DeleteOption {
+	return func(o *DeleteOptions) { o.DryRun = true }
+}
+
+// WithProtectionOverride allows deleting the default branch
+func WithProtectionOverride() DeleteOption {
+	return func(o *DeleteOptions) { o.IgnoreProtection = true }
+}
+
+// Delete deletes the local branch name, and with WithRemote the branch of
+// the same name on that remote:
+//
+//	err := g.Delete("feature/login", git.WithForce(), git.WithRemote("origin"))
+//
+// It returns *ErrInvalidBranchName, *ErrBranchNotFound, *ErrCurrentBranch,
+// *ErrProtectedBranch for the default branch, *ErrUnmergedBranch without
+// WithForce, or *ErrGitCommand when git fails.
+func (g *Git) Delete(name string, opts ...DeleteOption) error {
+	var o DeleteOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return g.DeleteWithOptions(name, o)
+}
+
+// DeleteWithOptions is Delete taking the options as a struct, for callers
+// that build them from their own configuration
+func (g *Git) DeleteWithOptions(name string, opts DeleteOptions) error {
+	if err := g.verifyRepo(); err != nil {
+		return err
+	}
+	if err := ValidateBranchName(name); err != nil {
+		return err
+	}
+	// Remote names follow the ref name rules, which also keep an option
+	// such as "--force" from reaching git push
+	if opts.Remote != "" {
+		if err := ValidateBranchName(opts.Remote); err != nil {
+			return err
+		}
+	}
+	if !g.branchExists("refs/heads/" + name) {
+		return &ErrBranchNotFound{Branch: name}
+	}
+	if current, err := g.getCurrentBranch(); err == nil && current == name {
+		return &ErrCurrentBranch{Branch: name}
+	}
+	if !opts.IgnoreProtection {
+		if def, err := g.getDefaultBranch(); err == nil && def == name {
+			return &ErrProtectedBranch{Branch: name}
+		}
+	}
+	if !opts.Force && !g.isFullyMerged(name) {
+		return &ErrUnmergedBranch{Branch: name}
+	}
+	if opts.Remote != "" && !g.branchExists("refs/remotes/"+opts.Remote+"/"+name) {
+		return &ErrBranchNotFound{Branch: opts.Remote + "/" + name}
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	if opts.Remote != "" {
+		if err := g.run("push", opts.Remote, "--delete", name); err != nil {
+			return err
+		}
+	}
+
+	flag := "-d"
+	if opts.Force {
+		flag = "-D"
+	}
+	return g.run("branch", flag, name)
+}
+
+// isFullyMerged reports whether 'git branch -d' would delete name: it must
+// be merged into its upstream, or into HEAD if it has none
+func (g *Git) isFullyMerged(name string) bool {
+	target := "HEAD"
+	if g.branchExists(name + "@{upstream}") {
+		target = name + "@{upstream}"
+	}
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", "refs/heads/"+name, target)
+	cmd.Dir = g.workDir
+	return cmd.Run() == nil
+}
+
+// run runs a git command, returning *ErrGitCommand with its stderr if it
+// fails
+func (g *Git) run(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.workDir
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return &ErrGitCommand{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package git
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteOptions(t *testing.T) {
+	var o DeleteOptions
+	for _, opt := range []DeleteOption{WithForce(), WithRemote("upstream"), WithDryRun(), WithProtectionOverride()} {
+		opt(&o)
+	}
+	assert.Equal(t, DeleteOptions{Force: true, Remote: "upstream", DryRun: true, IgnoreProtection: true}, o)
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		opts    []DeleteOption
+		wantErr error
+		kept    bool
+	}{
+		{name: "merged", branch: "feature/test"},
+		{name: "dry run", branch: "feature/test", opts: []DeleteOption{WithDryRun()}, kept: true},
+		{name: "unmerged", branch: "feature/unmerged", wantErr: &ErrUnmergedBranch{Branch: "feature/unmerged"}, kept: true},
+		{name: "unmerged forced", branch: "feature/unmerged", opts: []DeleteOption{WithForce()}},
+		{name: "unmerged dry run", branch: "feature/unmerged", opts: []DeleteOption{WithDryRun()}, wantErr: &ErrUnmergedBranch{Branch: "feature/unmerged"}, kept: true},
+		{name: "current", branch: "main", opts: []DeleteOption{WithForce()}, wantErr: &ErrCurrentBranch{Branch: "main"}, kept: true},
+		{name: "missing", branch: "does-not-exist", wantErr: &ErrBranchNotFound{Branch: "does-not-exist"}},
+		{name: "invalid", branch: "bad..name", wantErr: &ErrInvalidBranchName{Branch: "bad..name", Reason: "name cannot contain '..'"}},
+		{name: "option as remote", branch: "feature/test", opts: []DeleteOption{WithRemote("--force")}, wantErr: &ErrInvalidBranchName{Branch: "--force", Reason: "name cannot start with '-'"}, kept: true},
+		{name: "missing on remote", branch: "feature/test", opts: []DeleteOption{WithRemote("origin")}, wantErr: &ErrBranchNotFound{Branch: "origin/feature/test"}, kept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, cleanup := setupTestRepo(t)
+			defer cleanup()
+			for _, args := range [][]string{
+				{"checkout", "-q", "-b", "feature/unmerged"},
+				{"commit", "-q", "--allow-empty", "-m", "Unmerged work"},
+				{"checkout", "-q", "main"},
+			} {
+				require.NoError(t, exec.Command("git", append([]string{"-C", dir}, args...)...).Run())
+			}
+
+			g := New(dir)
+			err := g.Delete(tt.branch, tt.opts...)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.kept, g.branchExists("refs/heads/"+tt.branch))
+		})
+	}
+}
+
+func TestDeleteProtected(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	require.NoError(t, exec.Command("git", "-C", dir, "checkout", "-q", "feature/test").Run())
+
+	g := New(dir)
+	err := g.Delete("main")
+	assert.Equal(t, &ErrProtectedBranch{Branch: "main"}, err)
+	require.NoError(t, g.Delete("main", WithProtectionOverride(), WithDryRun()))
+	assert.True(t, g.branchExists("refs/heads/main"))
+}
+
+func TestDeleteRemote(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Merged("feature/merged")
+
+	g := New(r.Dir)
+	require.NoError(t, g.Delete("feature/merged", WithRemote("origin"), WithDryRun()))
+	assert.True(t, r.HasRemoteBranch("feature/merged"))
+
+	require.NoError(t, g.Delete("feature/merged", WithRemote("origin")))
+	assert.False(t, r.HasRemoteBranch("feature/merged"))
+	assert.False(t, r.HasBranch("feature/merged"))
+}
+
+func TestDeleteBranchShim(t *testing.T) {
+	r := testutil.NewRemote(t)
+	r.Merged("feature/both")
+	r.Tracked("feature/remote-only")
+	r.Git("branch", "-D", "feature/remote-only")
+	r.Local("feature/unmerged")
+
+	g := New(r.Dir)
+
+	// The deprecated form names the remote branch, and deletes the local
+	// one along with it
+	require.NoError(t, g.DeleteBranch("origin/feature/both", false, true))
+	assert.False(t, r.HasRemoteBranch("feature/both"))
+	assert.False(t, r.HasBranch("feature/both"))
+
+	// A remote branch without a local one
+	require.NoError(t, g.DeleteBranch("origin/feature/remote-only", false, true))
+	assert.False(t, r.HasRemoteBranch("feature/remote-only"))
+
+	// Without force, git decides whether an unmerged branch goes
+	err := g.DeleteBranch("feature/unmerged", false, false)
+	var gitErr *ErrGitCommand
+	require.ErrorAs(t, err, &gitErr)
+	assert.Contains(t, gitErr.Stderr, "not fully merged")
+	assert.True(t, r.HasBranch("feature/unmerged"))
+	require.NoError(t, g.DeleteBranch("feature/unmerged", true, false))
+	assert.False(t, r.HasBranch("feature/unmerged"))
+
+	assert.Error(t, g.DeleteBranch("--force/feature/x", false, true))
+}
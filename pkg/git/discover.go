@@ -0,0 +1,103 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiscoverRepo finds the repository containing start the way git does and
+// returns its working tree root and git directory, both absolute.
+//
+// GIT_DIR (with GIT_WORK_TREE, or start as the working tree) takes
+// precedence. Otherwise the directories from start upwards are searched for
+// a .git directory, or a .git file pointing at the git directory as in
+// linked worktrees and submodules, stopping at GIT_CEILING_DIRECTORIES.
+// When nothing is found, the error is *ErrNotGitRepo.
+func DiscoverRepo(start string) (root string, gitDir string, err error) {
+	start, err = filepath.Abs(start)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid directory %s: %w", start, err)
+	}
+
+	if env := os.Getenv("GIT_DIR"); env != "" {
+		gitDir, err = filepath.Abs(env)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid GIT_DIR %s: %w", env, err)
+		}
+		if !isGitDir(gitDir) {
+			return "", "", &ErrNotGitRepo{Dir: gitDir}
+		}
+		root = start
+		if tree := os.Getenv("GIT_WORK_TREE"); tree != "" {
+			if root, err = filepath.Abs(tree); err != nil {
+				return "", "", fmt.Errorf("invalid GIT_WORK_TREE %s: %w", tree, err)
+			}
+		}
+		return root, gitDir, nil
+	}
+
+	ceilings := ceilingDirs()
+	for dir := start; ; dir = filepath.Dir(dir) {
+		if gitDir, ok, err := dotGit(dir); err != nil {
+			return "", "", err
+		} else if ok {
+			return dir, gitDir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || ceilings[parent] {
+			return "", "", &ErrNotGitRepo{Dir: start}
+		}
+	}
+}
+
+// dotGit resolves dir/.git: a git directory, or a file holding
+// "gitdir: <path>" with the path relative to dir
+func dotGit(dir string) (string, bool, error) {
+	path := filepath.Join(dir, ".git")
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", false, nil
+	}
+	if fi.IsDir() {
+		return path, isGitDir(path), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", false, fmt.Errorf("invalid %s: expected \"gitdir: <path>\"", path)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	target = filepath.Clean(target)
+	if !isGitDir(target) {
+		return "", false, &ErrNotGitRepo{Dir: target}
+	}
+	return target, true, nil
+}
+
+// isGitDir reports whether dir looks like a git directory
+func isGitDir(dir string) bool {
+	fi, err := os.Stat(filepath.Join(dir, "HEAD"))
+	return err == nil && !fi.IsDir()
+}
+
+// ceilingDirs returns GIT_CEILING_DIRECTORIES, which discovery doesn't
+// enter
+func ceilingDirs() map[string]bool {
+	ceilings := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("GIT_CEILING_DIRECTORIES")) {
+		if dir != "" && filepath.IsAbs(dir) {
+			ceilings[filepath.Clean(dir)] = true
+		}
+	}
+	return ceilings
+}
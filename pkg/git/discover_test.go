@@ -0,0 +1,96 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverRepo(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	dir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	// A linked worktree has a .git file pointing into the main git directory
+	worktree := filepath.Join(t.TempDir(), "wt")
+	run("worktree", "add", "-q", worktree, "feature/test")
+	worktree, err = filepath.EvalSymlinks(worktree)
+	require.NoError(t, err)
+
+	// Submodules keep their git directory under the superproject's
+	// .git/modules, with a relative .git file
+	sub := filepath.Join(dir, "sub")
+	modules := filepath.Join(dir, ".git", "modules", "sub")
+	require.NoError(t, os.MkdirAll(filepath.Dir(modules), 0755))
+	run("init", "-q", "--separate-git-dir", modules, sub)
+	require.NoError(t, os.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../.git/modules/sub\n"), 0644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+
+	tests := []struct {
+		name   string
+		start  string
+		root   string
+		gitDir string
+	}{
+		{"root", dir, dir, filepath.Join(dir, ".git")},
+		{"subdirectory", filepath.Join(dir, "a", "b"), dir, filepath.Join(dir, ".git")},
+		{"worktree", worktree, worktree, filepath.Join(dir, ".git", "worktrees", "wt")},
+		{"submodule", sub, sub, modules},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, gitDir, err := DiscoverRepo(tt.start)
+			require.NoError(t, err)
+			assert.Equal(t, tt.root, root)
+			assert.Equal(t, tt.gitDir, gitDir)
+		})
+	}
+}
+
+func TestDiscoverRepoEnv(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	elsewhere := t.TempDir()
+
+	t.Setenv("GIT_DIR", filepath.Join(dir, ".git"))
+	root, gitDir, err := DiscoverRepo(elsewhere)
+	require.NoError(t, err)
+	assert.Equal(t, elsewhere, root)
+	assert.Equal(t, filepath.Join(dir, ".git"), gitDir)
+
+	t.Setenv("GIT_WORK_TREE", dir)
+	root, _, err = DiscoverRepo(elsewhere)
+	require.NoError(t, err)
+	assert.Equal(t, dir, root)
+
+	t.Setenv("GIT_DIR", elsewhere)
+	_, _, err = DiscoverRepo(dir)
+	var notRepo *ErrNotGitRepo
+	assert.ErrorAs(t, err, &notRepo)
+}
+
+func TestDiscoverRepoNotFound(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+
+	start := filepath.Join(dir, "x")
+	require.NoError(t, os.Mkdir(start, 0755))
+
+	_, _, err := DiscoverRepo(start)
+	var notRepo *ErrNotGitRepo
+	require.ErrorAs(t, err, &notRepo)
+	assert.Equal(t, start, notRepo.Dir)
+}
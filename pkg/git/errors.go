@@ -1,6 +1,9 @@
 package git
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ErrBranchNotFound indicates the branch doesn't exist
 type ErrBranchNotFound struct {
@@ -46,3 +49,40 @@ type ErrNotGitRepo struct {
 func (e *ErrNotGitRepo) Error() string {
 	return fmt.Sprintf("not a git repository: %s", e.Dir)
 }
+
+// ErrInvalidBranchName indicates a name git would not accept as a branch
+type ErrInvalidBranchName struct {
+	Branch string
+	Reason string
+}
+
+func (e *ErrInvalidBranchName) Error() string {
+	return fmt.Sprintf("invalid branch name %q: %s", e.Branch, e.Reason)
+}
+
+// ErrGitCommand indicates a git command failed
+type ErrGitCommand struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *ErrGitCommand) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("git %s failed: %v: %s", strings.Join(e.Args, " "), e.Err, e.Stderr)
+	}
+	return fmt.Sprintf("git %s failed: %v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *ErrGitCommand) Unwrap() error {
+	return e.Err
+}
+
+// ErrParse indicates git output that couldn't be parsed
+type ErrParse struct {
+	Line string
+}
+
+func (e *ErrParse) Error() string {
+	return fmt.Sprintf("unexpected git output: %q", e.Line)
+}
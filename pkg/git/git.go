@@ -24,7 +24,7 @@ func (g *Git) ListBranches() ([]Branch, error) {
 	}
 
 	// Get all branches with their commit info
-	cmd := exec.Command("git", "for-each-ref", "--sort=-committerdate", "refs/heads/", "refs/remotes/", "--format=%(if)%(HEAD)%(then)*%(else) %(end)%(refname:short):::%(objectname:short):::%(subject)")
+	cmd := exec.Command("git", "for-each-ref", "--sort=-committerdate", "refs/heads/", "refs/remotes/", "--format=%(if)%(HEAD)%(then)*%(else) %(end)%(refname):::%(objectname:short):::%(subject)")
 	cmd.Dir = g.workDir
 	output, err := cmd.Output()
 	if err != nil {
@@ -63,16 +63,15 @@ func (g *Git) ListBranches() ([]Branch, error) {
 		}
 
 		prefix := parts[0]
-		name := strings.TrimSpace(prefix)
-		isCurrent := strings.HasPrefix(prefix, "*") || name == currentBranch
-		if isCurrent {
-			name = strings.TrimLeft(name, "* ")
-		}
-
-		isRemote := strings.HasPrefix(name, "remotes/")
-		if isRemote {
-			name = strings.TrimPrefix(name, "remotes/")
+		ref := strings.TrimLeft(prefix, "* ")
+		name, isRemote := strings.CutPrefix(ref, "refs/remotes/")
+		if !isRemote {
+			name = strings.TrimPrefix(ref, "refs/heads/")
+		} else if strings.HasSuffix(name, "/HEAD") {
+			// The symbolic ref naming the remote's default branch
+			continue
 		}
+		isCurrent := strings.HasPrefix(prefix, "*") || !isRemote && name == currentBranch
 
 		branch := Branch{
 			Name:       name,
@@ -94,37 +93,46 @@ func (g *Git) ListBranches() ([]Branch, error) {
 	return branches, nil
 }
 
-// DeleteBranch deletes a branch locally and/or remotely
+// DeleteBranch deletes a branch locally and/or remotely. With remote, name
+// is a remote branch such as "origin/feature/x"; it is deleted from the
+// remote, and the local branch "feature/x" too if there is one. Whether an
+// unmerged local branch may be deleted without force is left to git.
+//
+// Deprecated: use Delete, e.g. g.Delete("feature/x", WithForce(),
+// WithRemote("origin")), which can't mix up its flags and also reports
+// unmerged and protected branches before deleting anything. DeleteBranch
+// never refuses the default branch, as before.
 func (g *Git) DeleteBranch(name string, force, remote bool) error {
 	if err := g.verifyRepo(); err != nil {
 		return err
 	}
 
 	if remote {
-		remoteName := strings.Split(name, "/")[0]
-		branchName := strings.Join(strings.Split(name, "/")[1:], "/")
-
-		args := []string{"push", remoteName, "--delete", branchName}
-		cmd := exec.Command("git", args...)
-		cmd.Dir = g.workDir
-
-		if err := cmd.Run(); err != nil {
+		remoteName, branchName, _ := strings.Cut(name, "/")
+		if err := ValidateBranchName(remoteName); err != nil {
+			return err
+		}
+		if err := ValidateBranchName(branchName); err != nil {
+			return err
+		}
+		if err := g.run("push", remoteName, "--delete", branchName); err != nil {
 			return fmt.Errorf("failed to delete remote branch: %w", err)
 		}
+		if !g.branchExists("refs/heads/" + branchName) {
+			return nil
+		}
+		name = branchName
+	} else if err := ValidateBranchName(name); err != nil {
+		return err
 	}
 
 	flag := "-d"
 	if force {
 		flag = "-D"
 	}
-
-	cmd := exec.Command("git", "branch", flag, name)
-	cmd.Dir = g.workDir
-
-	if err := cmd.Run(); err != nil {
+	if err := g.run("branch", flag, name); err != nil {
 		return fmt.Errorf("failed to delete local branch: %w", err)
 	}
-
 	return nil
 }
 
@@ -159,27 +167,41 @@ func (g *Git) getDefaultBranch() (string, error) {
 	return strings.TrimPrefix(ref, "refs/remotes/origin/"), nil
 }
 
+// getMergedBranches returns the branches merged into the default branch,
+// main or else master. Remote branches are keyed like "origin/x" and count
+// as merged into either the local or the remote's default branch, so they
+// are found even when the local one is behind.
 func (g *Git) getMergedBranches() (map[string]bool, error) {
-	mergedBranches := make(map[string]bool)
-
-	// Try main first, then master
 	for _, base := range []string{"main", "master"} {
-		cmd := exec.Command("git", "branch", "--merged", base)
-		cmd.Dir = g.workDir
-		output, err := cmd.Output()
-		if err == nil {
+		if !g.branchExists("refs/heads/"+base) && !g.branchExists("refs/remotes/origin/"+base) {
+			continue
+		}
+
+		mergedBranches := make(map[string]bool)
+		targets := [][]string{{"--merged", base}, {"-r", "--merged", base}}
+		if g.branchExists("refs/remotes/origin/" + base) {
+			targets = append(targets, []string{"-r", "--merged", "origin/" + base})
+		}
+		for _, args := range targets {
+			cmd := exec.Command("git", append([]string{"branch"}, args...)...)
+			cmd.Dir = g.workDir
+			output, err := cmd.Output()
+			if err != nil {
+				// The local base may not exist when only the remote's does
+				continue
+			}
 			scanner := bufio.NewScanner(strings.NewReader(string(output)))
 			for scanner.Scan() {
-				branch := strings.TrimSpace(scanner.Text())
-				branch = strings.TrimPrefix(branch, "*") // Remove current branch marker
-				branch = strings.TrimSpace(branch)       // Remove any remaining whitespace
-				mergedBranches[branch] = true
+				branch := strings.TrimLeft(strings.TrimSpace(scanner.Text()), "*+ ") // Remove current and worktree markers
+				if branch != "" && !strings.Contains(branch, " -> ") {
+					mergedBranches[branch] = true
+				}
 			}
-			return mergedBranches, nil
 		}
+		return mergedBranches, nil
 	}
 
-	return mergedBranches, fmt.Errorf("failed to get merged branches")
+	return make(map[string]bool), fmt.Errorf("failed to get merged branches")
 }
 
 func (g *Git) branchExists(name string) bool {
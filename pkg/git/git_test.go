@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func setupTestRepo(t *testing.T) (string, func()) {
 
 	// Initialize git repo
 	cmds := [][]string{
-		{"git", "init"},
+		{"git", "init", "-b", "main"},
 		{"git", "config", "user.email", "test@example.com"},
 		{"git", "config", "user.name", "Test User"},
 		{"git", "config", "--local", "init.defaultBranch", "main"},
@@ -104,6 +105,48 @@ func TestListBranches(t *testing.T) {
 	assert.True(t, hasFeature2, "feature/test2 branch not found")
 }
 
+func TestListBranchesRemoteMerged(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	run := func(args ...string) string {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		out, err := c.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	// origin/feature/pr was merged into origin/main, which main is behind
+	run("checkout", "-q", "-b", "feature/pr")
+	run("commit", "-q", "--allow-empty", "-m", "pr")
+	run("checkout", "-q", "main")
+	run("update-ref", "refs/remotes/origin/feature/pr", run("rev-parse", "feature/pr"))
+	run("update-ref", "refs/remotes/origin/main", run("rev-parse", "feature/pr"))
+	run("update-ref", "refs/remotes/origin/unmerged", run("rev-parse", "feature/pr"))
+	run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	run("commit", "-q", "--allow-empty", "-m", "more")
+	run("update-ref", "refs/remotes/origin/unmerged", run("rev-parse", "HEAD"))
+	run("reset", "-q", "--hard", "HEAD~1")
+
+	g := New(dir)
+	branches, err := g.ListBranches()
+	require.NoError(t, err)
+
+	byName := make(map[string]Branch)
+	for _, b := range branches {
+		byName[b.Name] = b
+	}
+	assert.NotContains(t, byName, "origin/HEAD")
+	assert.True(t, byName["origin/feature/pr"].IsRemote)
+	assert.True(t, byName["origin/feature/pr"].IsMerged)
+	assert.False(t, byName["origin/unmerged"].IsMerged)
+	assert.True(t, byName["origin/main"].IsDefault)
+	assert.False(t, byName["feature/pr"].IsMerged, "local branches are compared with main")
+	assert.True(t, byName["feature/test"].IsLocal)
+	assert.True(t, byName["main"].IsCurrent)
+	assert.False(t, byName["origin/main"].IsCurrent)
+}
+
 func TestVerifyRepo(t *testing.T) {
 	// Test valid repo
 	dir, cleanup := setupTestRepo(t)
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// branchStreamFormat separates fields with NUL so subjects can't break parsing
+const branchStreamFormat = "%(refname)%00%(objectname:short)%00%(HEAD)%00%(upstream:track)%00%(subject)"
+
+// BranchStream iterates over a repository's branches one at a time, for
+// repositories too large to load every branch into memory at once
+type BranchStream struct {
+	git *Git
+}
+
+// NewBranchStream creates a BranchStream for g
+func NewBranchStream(g *Git) *BranchStream {
+	return &BranchStream{git: g}
+}
+
+// Branches streams local and remote branches until the repository is
+// exhausted or ctx is done. The error channel receives at most one error:
+// ctx.Err(), *ErrNotGitRepo, *ErrGitCommand or *ErrParse. Both channels are
+// closed when streaming stops; stop reading early by cancelling ctx.
+//
+// IsMerged is not populated, as it requires comparing against every branch.
+func (bs *BranchStream) Branches(ctx context.Context) (<-chan Branch, <-chan error) {
+	branches := make(chan Branch)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(branches)
+
+		if err := bs.stream(ctx, branches); err != nil {
+			errs <- err
+		}
+	}()
+
+	return branches, errs
+}
+
+func (bs *BranchStream) stream(ctx context.Context, branches chan<- Branch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := bs.git.verifyRepo(); err != nil {
+		return err
+	}
+
+	// Don't fail if we can't determine the default branch
+	defaultBranch, _ := bs.git.getDefaultBranch()
+
+	args := []string{"for-each-ref", "--format=" + branchStreamFormat, "refs/heads/", "refs/remotes/"}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = bs.git.workDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return &ErrGitCommand{Args: args, Err: err}
+	}
+	if err := cmd.Start(); err != nil {
+		return &ErrGitCommand{Args: args, Err: err}
+	}
+	// Reap the process on every path; after an early return the error is moot
+	waited := false
+	defer func() {
+		if !waited {
+			_ = cmd.Wait()
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		branch, ok, err := parseStreamLine(scanner.Text(), defaultBranch)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		select {
+		case branches <- branch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Cancellation surfaces as a read or wait error; report it as such
+	scanErr := scanner.Err()
+	waited = true
+	waitErr := cmd.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if scanErr != nil {
+		return &ErrGitCommand{Args: args, Err: scanErr}
+	}
+	if waitErr != nil {
+		return &ErrGitCommand{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: waitErr}
+	}
+	return nil
+}
+
+// parseStreamLine parses one line of branchStreamFormat output. ok is false
+// for refs that aren't branches, such as refs/remotes/origin/HEAD.
+func parseStreamLine(line, defaultBranch string) (branch Branch, ok bool, err error) {
+	parts := strings.Split(line, "\x00")
+	if len(parts) != 5 {
+		return Branch{}, false, &ErrParse{Line: line}
+	}
+	ref, hash, head, track, subject := parts[0], parts[1], parts[2], parts[3], parts[4]
+
+	var name string
+	var isRemote bool
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		name = strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/remotes/"):
+		name = strings.TrimPrefix(ref, "refs/remotes/")
+		isRemote = true
+		if strings.HasSuffix(name, "/HEAD") {
+			return Branch{}, false, nil
+		}
+	default:
+		return Branch{}, false, &ErrParse{Line: line}
+	}
+
+	return Branch{
+		Name:       name,
+		CommitHash: hash,
+		Message:    subject,
+		IsLocal:    !isRemote,
+		IsRemote:   isRemote,
+		IsCurrent:  head == "*",
+		IsDefault:  defaultBranch != "" && (name == defaultBranch || name == "origin/"+defaultBranch),
+		IsStale:    strings.Contains(track, "gone"),
+	}, true, nil
+}
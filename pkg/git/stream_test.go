@@ -0,0 +1,104 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchStream(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	branches, errs := NewBranchStream(New(dir)).Branches(context.Background())
+
+	var names []string
+	for b := range branches {
+		names = append(names, b.Name)
+		assert.True(t, b.IsLocal)
+		if b.Name == "main" {
+			assert.True(t, b.IsCurrent)
+			assert.True(t, b.IsDefault)
+			assert.Equal(t, "Initial commit", b.Message)
+		}
+	}
+	require.NoError(t, <-errs)
+	assert.ElementsMatch(t, []string{"main", "feature/test", "feature/test2"}, names)
+}
+
+func TestBranchStreamCancel(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	branches, errs := NewBranchStream(New(dir)).Branches(ctx)
+
+	// Stop after the first branch; the stream must shut down cleanly
+	<-branches
+	cancel()
+	for range branches {
+	}
+	assert.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestBranchStreamNotGitRepo(t *testing.T) {
+	branches, errs := NewBranchStream(New(t.TempDir())).Branches(context.Background())
+
+	for range branches {
+		t.Fatal("no branches expected")
+	}
+	assert.IsType(t, &ErrNotGitRepo{}, <-errs)
+}
+
+func TestParseStreamLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Branch
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "current local branch",
+			line:   "refs/heads/main\x00abc1234\x00*\x00\x00Initial commit",
+			want:   Branch{Name: "main", CommitHash: "abc1234", Message: "Initial commit", IsLocal: true, IsCurrent: true, IsDefault: true},
+			wantOK: true,
+		},
+		{
+			name:   "stale local branch",
+			line:   "refs/heads/old\x00abc1234\x00 \x00[gone]\x00Old work",
+			want:   Branch{Name: "old", CommitHash: "abc1234", Message: "Old work", IsLocal: true, IsStale: true},
+			wantOK: true,
+		},
+		{
+			name:   "remote branch",
+			line:   "refs/remotes/origin/main\x00abc1234\x00 \x00\x00Initial commit",
+			want:   Branch{Name: "origin/main", CommitHash: "abc1234", Message: "Initial commit", IsRemote: true, IsDefault: true},
+			wantOK: true,
+		},
+		{
+			name: "remote HEAD",
+			line: "refs/remotes/origin/HEAD\x00abc1234\x00 \x00\x00Initial commit",
+		},
+		{
+			name:    "malformed",
+			line:    "refs/heads/main abc1234",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseStreamLine(tt.line, "main")
+			if tt.wantErr {
+				assert.IsType(t, &ErrParse{}, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Characters git never allows in a ref name
+	invalidRefChars = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]`)
+
+	// Runs of characters collapsed by SanitizeBranchName
+	repeatedDots    = regexp.MustCompile(`\.\.+`)
+	repeatedSlashes = regexp.MustCompile(`//+`)
+)
+
+// ValidateBranchName reports whether name is a valid branch name, following
+// the rules of `git check-ref-format --branch`:
+//   - no ASCII control characters, space, ~, ^, :, ?, *, [ or \
+//   - no "..", "@{" or "//" and not the single character "@"
+//   - no component may start with '.' or end with ".lock"
+//   - may not start with '-' or '/', or end with '/' or '.'
+//   - may not be "HEAD"
+func ValidateBranchName(name string) error {
+	invalid := func(reason string) error {
+		return &ErrInvalidBranchName{Branch: name, Reason: reason}
+	}
+
+	switch {
+	case name == "":
+		return invalid("name cannot be empty")
+	case name == "@":
+		return invalid("name cannot be '@'")
+	case name == "HEAD":
+		return invalid("name cannot be 'HEAD'")
+	case strings.HasPrefix(name, "-"):
+		return invalid("name cannot start with '-'")
+	case strings.HasPrefix(name, "/"), strings.HasSuffix(name, "/"):
+		return invalid("name cannot start or end with '/'")
+	case strings.HasSuffix(name, "."):
+		return invalid("name cannot end with '.'")
+	case invalidRefChars.MatchString(name):
+		return invalid("name contains a forbidden character")
+	case strings.Contains(name, ".."):
+		return invalid("name cannot contain '..'")
+	case strings.Contains(name, "@{"):
+		return invalid("name cannot contain '@{'")
+	case strings.Contains(name, "//"):
+		return invalid("name cannot contain '//'")
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") {
+			return invalid("path components cannot start with '.'")
+		}
+		if strings.HasSuffix(component, ".lock") {
+			return invalid("path components cannot end with '.lock'")
+		}
+	}
+
+	return nil
+}
+
+// SanitizeBranchName turns name into a valid branch name by replacing
+// forbidden characters with '-' and dropping forbidden sequences. It returns
+// an empty string if nothing usable is left.
+func SanitizeBranchName(name string) string {
+	name = invalidRefChars.ReplaceAllString(name, "-")
+	name = strings.ReplaceAll(name, "@{", "-")
+	name = repeatedDots.ReplaceAllString(name, ".")
+	name = repeatedSlashes.ReplaceAllString(name, "/")
+
+	var components []string
+	for _, component := range strings.Split(name, "/") {
+		leading := "."
+		if len(components) == 0 {
+			leading = ".-"
+		}
+		// Trimming one rule can expose another ("x.lock." or "-.x")
+		for prev := ""; prev != component; {
+			prev = component
+			component = strings.TrimLeft(component, leading)
+			component = strings.TrimRight(component, ".")
+			component = strings.TrimSuffix(component, ".lock")
+		}
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	name = strings.Join(components, "/")
+	if name == "@" || name == "HEAD" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr bool
+	}{
+		{"valid simple", "main", false},
+		{"valid with slash", "feature/test", false},
+		{"valid with dash", "fix-123", false},
+		{"valid with underscore", "feature_test", false},
+		{"valid with dot", "release/v1.2", false},
+		{"valid with at", "user@host", false},
+		{"valid with unicode", "feature/café", false},
+		{"empty", "", true},
+		{"at sign", "@", true},
+		{"HEAD", "HEAD", true},
+		{"starts with dash", "-branch", true},
+		{"starts with dot", ".hidden", true},
+		{"component starts with dot", "feature/.hidden", true},
+		{"ends with dot", "branch.", true},
+		{"starts with slash", "/branch", true},
+		{"ends with slash", "branch/", true},
+		{"double slash", "feature//test", true},
+		{"ends with .lock", "branch.lock", true},
+		{"component ends with .lock", "feature.lock/test", true},
+		{"contains space", "feature branch", true},
+		{"contains tilde", "feature~1", true},
+		{"contains caret", "feature^", true},
+		{"contains colon", "feature:test", true},
+		{"contains special chars", "feature*test", true},
+		{"contains bracket", "feature[1]", true},
+		{"contains backslash", "feature\\test", true},
+		{"contains control chars", "feature\ntest", true},
+		{"contains DEL", "feature\x7ftest", true},
+		{"double dots", "feature..test", true},
+		{"reflog syntax", "feature@{1}", true},
+		{"path traversal", "../config", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBranchName(tt.branch)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.IsType(t, &ErrInvalidBranchName{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateBranchNameMatchesGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// "@" is left out: --branch expands it to the current branch
+	names := []string{
+		"main", "feature/test", "release/v1.2", "user@host", "a.b/c.d",
+		".hidden", "feature/.hidden", "branch.", "branch/", "feature//test",
+		"branch.lock", "feature.lock/test", "feature..test", "feature@{1}",
+		"HEAD", "-branch", "feature branch", "feature~1", "feature:test",
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			gitErr := exec.Command("git", "check-ref-format", "--branch", name).Run()
+			err := ValidateBranchName(name)
+			assert.Equal(t, gitErr == nil, err == nil, "git check-ref-format disagrees")
+		})
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{"already valid", "feature/test", "feature/test"},
+		{"spaces", "my feature", "my-feature"},
+		{"forbidden chars", "fix:bug*1?", "fix-bug-1-"},
+		{"double dots", "a..b", "a.b"},
+		{"double slashes", "a//b", "a/b"},
+		{"leading dot and dash", "-.hidden", "hidden"},
+		{"component dot", "feature/.x", "feature/x"},
+		{"lock suffix", "feature.lock", "feature"},
+		{"lock then dot", "feature.lock.", "feature"},
+		{"trailing slash and dot", "feature/.", "feature"},
+		{"reflog syntax", "a@{1}", "a-1}"},
+		{"nothing left", "...", ""},
+		{"HEAD", "HEAD", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeBranchName(tt.branch)
+			assert.Equal(t, tt.want, got)
+			if got != "" {
+				assert.NoError(t, ValidateBranchName(got), "sanitized name %q must validate", got)
+			}
+		})
+	}
+
+	// Sanitizing is idempotent
+	for _, tt := range tests {
+		once := SanitizeBranchName(tt.branch)
+		assert.Equal(t, once, SanitizeBranchName(once), strings.TrimSpace(tt.branch))
+	}
+}
@@ -0,0 +1,134 @@
+// Package testutil provides end-to-end test fixtures for code working on
+// branches and their remotes: a working clone whose origin is a local bare
+// repository, with helpers to put branches into the states prune cares
+// about (tracked, merged, gone) without any network access.
+//
+// Fixtures live in the test's temporary directory and are removed with it.
+// Commits use a fixed identity and dates, so hashes are the same on every
+// run, and the user's git configuration is ignored.
+//
+//	r := testutil.NewRemote(t, testutil.Merged)
+//	r.Gone("feature/old")
+//	// ... run the code under test in r.Dir ...
+//	assert.False(t, r.HasRemoteBranch("feature/old"))
+package testutil
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/scenario"
+)
+
+// Prebuilt branch states, each creating a branch of the same name; see
+// NewRemote
+const (
+	Merged             = scenario.Merged             // Merged into main and pushed
+	Stale              = scenario.Stale              // Upstream deleted from origin
+	Unmerged           = scenario.Unmerged           // Has a commit main lacks, never pushed
+	RemoteOnly         = scenario.RemoteOnly         // Only on origin
+	Diverged           = scenario.Diverged           // Ahead of and behind its upstream
+	WorktreeCheckedOut = scenario.WorktreeCheckedOut // Checked out in a linked worktree
+)
+
+// Remote is a working clone, on main, whose origin is a local bare
+// repository. Its methods fail the test on errors.
+type Remote struct {
+	Dir    string // Working clone
+	Origin string // Bare repository origin points at
+
+	t    testing.TB
+	repo *scenario.Repo
+}
+
+// NewRemote builds a clone and its bare origin in a temporary directory,
+// with a branch for each of the given prebuilt states
+func NewRemote(t testing.TB, states ...string) *Remote {
+	t.Helper()
+	repo, err := scenario.Build(t.TempDir(), states...)
+	if err != nil {
+		t.Fatalf("failed to build test repository: %v", err)
+	}
+	return &Remote{Dir: repo.Dir, Origin: repo.Origin, t: t, repo: repo}
+}
+
+// Git runs git in the clone and returns its trimmed output
+func (r *Remote) Git(args ...string) string {
+	r.t.Helper()
+	out, err := r.repo.Git(args...)
+	if err != nil {
+		r.t.Fatalf("%v", err)
+	}
+	return out
+}
+
+// Local creates a branch off main with one commit, without pushing it
+func (r *Remote) Local(name string) {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", name, "main")
+	r.Git("commit", "--quiet", "--allow-empty", "-m", "Work on "+name)
+	r.Git("checkout", "--quiet", "main")
+}
+
+// Tracked creates a branch with one commit and pushes it to origin as its
+// upstream
+func (r *Remote) Tracked(name string) {
+	r.t.Helper()
+	r.Local(name)
+	r.Git("push", "--quiet", "--set-upstream", "origin", name)
+}
+
+// Merged creates a tracked branch and merges it into main on origin, the
+// way a merged pull request leaves it. The branch stays on origin.
+func (r *Remote) Merged(name string) {
+	r.t.Helper()
+	r.Tracked(name)
+	r.Git("merge", "--quiet", "--no-ff", "-m", "Merge branch '"+name+"'", name)
+	r.Git("push", "--quiet", "origin", "main")
+}
+
+// Gone creates a tracked branch, deletes it from origin and fetches with
+// pruning, so its upstream shows as gone
+func (r *Remote) Gone(name string) {
+	r.t.Helper()
+	r.Tracked(name)
+	r.DeleteOnOrigin(name)
+	r.Git("fetch", "--quiet", "--prune", "origin")
+}
+
+// DeleteOnOrigin deletes a branch straight from the bare origin, as a
+// teammate or the hosting service would, without fetching
+func (r *Remote) DeleteOnOrigin(name string) {
+	r.t.Helper()
+	r.Git("--git-dir", r.Origin, "update-ref", "-d", "refs/heads/"+name)
+}
+
+// HasBranch reports whether the clone has a local branch called name
+func (r *Remote) HasBranch(name string) bool {
+	r.t.Helper()
+	return slices.Contains(r.refNames("for-each-ref", "--format=%(refname:short)", "refs/heads"), name)
+}
+
+// HasRemoteBranch reports whether origin itself has a branch called name,
+// regardless of what the clone last fetched
+func (r *Remote) HasRemoteBranch(name string) bool {
+	r.t.Helper()
+	return slices.Contains(r.RemoteBranches(), name)
+}
+
+// RemoteBranches returns the branches origin has, sorted by name
+func (r *Remote) RemoteBranches() []string {
+	r.t.Helper()
+	return r.refNames("--git-dir", r.Origin, "for-each-ref", "--format=%(refname:short)", "refs/heads")
+}
+
+// refNames runs a git command listing one name per line
+func (r *Remote) refNames(args ...string) []string {
+	r.t.Helper()
+	out := r.Git(args...)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/bral/git-branch-delete-go/internal/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteStates(t *testing.T) {
+	r := NewRemote(t, RemoteOnly)
+	r.Local("feature/local")
+	r.Tracked("feature/tracked")
+	r.Merged("feature/merged")
+	r.Gone("feature/gone")
+
+	assert.Equal(t, []string{"feature/merged", "feature/tracked", "main", RemoteOnly}, r.RemoteBranches())
+	assert.True(t, r.HasBranch("feature/local"))
+	assert.False(t, r.HasRemoteBranch("feature/local"))
+	assert.Equal(t, "origin/feature/tracked", r.Git("rev-parse", "--abbrev-ref", "feature/tracked@{upstream}"))
+	assert.Equal(t, "[gone]", r.Git("for-each-ref", "--format=%(upstream:track)", "refs/heads/feature/gone"))
+
+	r.DeleteOnOrigin("feature/tracked")
+	assert.False(t, r.HasRemoteBranch("feature/tracked"))
+	// Not fetched yet, so the clone still remembers it
+	assert.NotEmpty(t, r.Git("for-each-ref", "refs/remotes/origin/feature/tracked"))
+}
+
+func TestRemoteDeterministic(t *testing.T) {
+	build := func() string {
+		r := NewRemote(t, Merged)
+		r.Merged("feature/x")
+		return r.Git("rev-parse", "main")
+	}
+	assert.Equal(t, build(), build())
+}
+
+func TestRemotePrunePaths(t *testing.T) {
+	r := NewRemote(t)
+	r.Merged("feature/merged")
+	r.Gone("feature/gone")
+
+	g, err := git.New(r.Dir)
+	require.NoError(t, err)
+	branches, err := g.ListLocalBranches()
+	require.NoError(t, err)
+	states := make(map[string]git.GitBranch)
+	for _, b := range branches {
+		states[b.Name] = b
+	}
+	assert.True(t, states["feature/gone"].IsStale)
+	assert.True(t, states["feature/merged"].IsMerged)
+
+	require.NoError(t, g.DeleteBranch("feature/merged", false, true))
+	assert.False(t, r.HasRemoteBranch("feature/merged"))
+	assert.Empty(t, r.Git("for-each-ref", "refs/remotes/origin/feature/merged"))
+
+	// prune force-deletes gone branches, which are usually squash-merged
+	require.NoError(t, g.DeleteBranch("feature/gone", true, false))
+	assert.False(t, r.HasBranch("feature/gone"))
+}